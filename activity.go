@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// activitySeries is the macro series the composite activity index is stored as.
+const activitySeries = "activity"
+
+// computeActivityIndex recomputes the composite activity index from the stored
+// ACTIVITY_INDEX_WEIGHTS components and stores it as the "activity" macro series. Components
+// in ACTIVITY_INDEX_LEVEL_SERIES are used as stored; the others as year-on-year growth. It
+// returns the number of months stored.
+func computeActivityIndex(ctx context.Context, s *AppState) (int, error) {
+	if len(s.cfg.ActivityIndexWeights) == 0 {
+		return 0, fmt.Errorf("ACTIVITY_INDEX_WEIGHTS is empty")
+	}
+	components := make(map[string][]analytics.Point, len(s.cfg.ActivityIndexWeights))
+	for code := range s.cfg.ActivityIndexWeights {
+		points, err := loadMacroSeries(ctx, s, code, returnsEpoch, markettime.Today())
+		if err != nil {
+			return 0, err
+		}
+		if !slices.Contains(s.cfg.ActivityIndexLevelSeries, code) {
+			points = analytics.YearOnYear(points)
+		}
+		if len(points) == 0 {
+			log.Printf("Warning: No stored observations for %s; it is left out of the activity index.", code)
+			continue
+		}
+		components[code] = points
+	}
+
+	index := analytics.ActivityIndex(components, s.cfg.ActivityIndexWeights)
+	if len(index) == 0 {
+		return 0, fmt.Errorf("no months with enough of the activity index components stored")
+	}
+
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	periods := make([]time.Time, 0, len(index))
+	for _, p := range index {
+		_, err := qtx.UpsertMacroObservation(ctx, database.UpsertMacroObservationParams{
+			Series: activitySeries,
+			Period: p.Date,
+			Value:  strconv.FormatFloat(p.Value, 'f', 6, 64),
+			Source: "derived:" + activitySeries,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to store activity index for %s: %w", p.Date.Format("2006-01"), err)
+		}
+		periods = append(periods, p.Date)
+	}
+	err = qtx.DeleteMacroObservationsExcept(ctx, database.DeleteMacroObservationsExceptParams{Series: activitySeries, Periods: periods})
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear stale activity index months: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit activity index: %w", err)
+	}
+	invalidateResponseCache(s)
+	return len(index), nil
+}
+
+// refreshActivityIndex recomputes the activity index when any of codes (macro series that
+// were just stored) is one of its components. Failures are logged, not returned, so they do
+// not fail the fetch or push that stored the data.
+func refreshActivityIndex(ctx context.Context, s *AppState, codes ...string) {
+	for _, code := range codes {
+		if s.cfg.ActivityIndexWeights[code] <= 0 {
+			continue
+		}
+		n, err := computeActivityIndex(ctx, s)
+		if err != nil {
+			log.Printf("Error recomputing the activity index after %s was stored: %v", code, err)
+			return
+		}
+		log.Printf("Recomputed the activity index (%d months) after %s was stored.", n, code)
+		return
+	}
+}
+
+// handlerMacroActivityCompute recomputes the composite activity index on demand.
+// Usage: macro:activity:compute
+func handlerMacroActivityCompute(s *AppState, cmd command) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+	n, err := computeActivityIndex(cmd.Context(), s)
+	if err != nil {
+		return fmt.Errorf("failed to compute the activity index: %w", err)
+	}
+	fmt.Printf("Stored %d months of the activity index.\n", n)
+	return nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// maxImportedAlertRules caps the rules one import may hold.
+const maxImportedAlertRules = 500
+
+// errInvalidAlertImport is returned when an imported document or one of its rules is invalid.
+var errInvalidAlertImport = errors.New("invalid alert rules")
+
+// alertRulesDocument is a user's full set of alert rules as exported and imported, in YAML or JSON:
+//
+//	rules:
+//	  - series: fx:USD
+//	    condition: ">"
+//	    threshold: 4.8
+//	  - series: macro:cpi
+//	    change: yoy
+//	    condition: ">"
+//	    threshold: 3
+type alertRulesDocument struct {
+	Rules []alertRuleRequest `json:"rules" yaml:"rules"`
+}
+
+// Structure for the outcome of an alert rule import
+type AlertImportResult struct {
+	Added   int `json:"added"`
+	Skipped int `json:"skipped"` // Already present, or repeated in the document
+	Removed int `json:"removed"` // Existing rules dropped by a replacing import
+}
+
+// parseAlertRulesDocument decodes an exported document. JSON is accepted as YAML; unknown keys
+// are rejected so a misspelt field does not silently fall back to its default.
+func parseAlertRulesDocument(data []byte) (alertRulesDocument, error) {
+	var doc alertRulesDocument
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil && err != io.EOF {
+		return doc, fmt.Errorf("%w: %v", errInvalidAlertImport, err)
+	}
+	return doc, nil
+}
+
+// encodeAlertRulesDocument writes doc as YAML.
+func encodeAlertRulesDocument(w io.Writer, doc alertRulesDocument) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// exportAlertRules returns a user's alert rules in the form importAlertRules reads back.
+// Trigger state is not exported; imported rules start armed.
+func exportAlertRules(ctx context.Context, s *AppState, userID uuid.UUID) (alertRulesDocument, error) {
+	rules, err := s.db.ListAlertRulesByUser(ctx, userID)
+	if err != nil {
+		return alertRulesDocument{}, fmt.Errorf("failed to load alert rules: %w", err)
+	}
+	doc := alertRulesDocument{Rules: make([]alertRuleRequest, 0, len(rules))}
+	for _, rule := range rules {
+		threshold, _ := strconv.ParseFloat(rule.Threshold, 64)
+		spec := alertRuleRequest{Series: rule.Series, Condition: rule.Condition, Threshold: threshold}
+		if rule.Change != "level" {
+			spec.Change = rule.Change
+		}
+		doc.Rules = append(doc.Rules, spec)
+	}
+	return doc, nil
+}
+
+// alertRuleKey identifies a rule by what it compares, so an import can skip rules the user
+// already has.
+func alertRuleKey(series, change, condition, threshold string) string {
+	value, _ := strconv.ParseFloat(threshold, 64)
+	return strings.Join([]string{series, change, condition, strconv.FormatFloat(value, 'f', -1, 64)}, " ")
+}
+
+// importAlertRules validates every rule of doc and then, in one transaction, adds those the user
+// does not have yet. With replace the user's existing rules are removed first, so the result
+// matches the document exactly. When any rule is invalid nothing is stored and the error lists
+// each problem by rule number.
+func importAlertRules(ctx context.Context, s *AppState, userID uuid.UUID, doc alertRulesDocument, replace bool) (AlertImportResult, error) {
+	var result AlertImportResult
+	if len(doc.Rules) > maxImportedAlertRules {
+		return result, fmt.Errorf("%w: %d rules (maximum %d)", errInvalidAlertImport, len(doc.Rules), maxImportedAlertRules)
+	}
+	var params []database.CreateAlertRuleParams
+	var problems []string
+	for i, spec := range doc.Rules {
+		p, err := newAlertRuleParams(ctx, s, userID, spec.Series, spec.Change, spec.Condition, strconv.FormatFloat(spec.Threshold, 'f', -1, 64))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("rule %d: %v", i+1, err))
+			continue
+		}
+		params = append(params, p)
+	}
+	if len(problems) > 0 {
+		return result, fmt.Errorf("%w: %s", errInvalidAlertImport, strings.Join(problems, "; "))
+	}
+
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	have := make(map[string]bool)
+	if replace {
+		n, err := qtx.DeleteAlertRulesByUser(ctx, userID)
+		if err != nil {
+			return result, fmt.Errorf("failed to remove existing alert rules: %w", err)
+		}
+		result.Removed = int(n)
+	} else {
+		existing, err := qtx.ListAlertRulesByUser(ctx, userID)
+		if err != nil {
+			return result, fmt.Errorf("failed to load alert rules: %w", err)
+		}
+		for _, rule := range existing {
+			have[alertRuleKey(rule.Series, rule.Change, rule.Condition, rule.Threshold)] = true
+		}
+	}
+	for _, p := range params {
+		key := alertRuleKey(p.Series, p.Change, p.Condition, p.Threshold)
+		if have[key] {
+			result.Skipped++
+			continue
+		}
+		if _, err := qtx.CreateAlertRule(ctx, p); err != nil {
+			return result, fmt.Errorf("failed to create alert rule on %s: %w", alertLabel(p.Series, p.Change), err)
+		}
+		have[key] = true
+		result.Added++
+	}
+	if err := tx.Commit(); err != nil {
+		return AlertImportResult{}, err
+	}
+	return result, nil
+}
+
+// updateAlertRule replaces the condition of one of a user's rules with raw fields validated as
+// for a new rule. It returns sql.ErrNoRows when the user has no rule with that ID.
+func updateAlertRule(ctx context.Context, s *AppState, userID, id uuid.UUID, series, change, condition, threshold string) (database.AlertRule, error) {
+	p, err := newAlertRuleParams(ctx, s, userID, series, change, condition, threshold)
+	if err != nil {
+		return database.AlertRule{}, err
+	}
+	return s.db.UpdateAlertRule(ctx, database.UpdateAlertRuleParams{
+		ID:        id,
+		UserID:    userID,
+		Series:    p.Series,
+		Condition: p.Condition,
+		Threshold: p.Threshold,
+		Change:    p.Change,
+	})
+}
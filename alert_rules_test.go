@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParseAlertRulesDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []alertRuleRequest
+		wantErr bool
+	}{
+		{
+			name: "yaml",
+			data: "rules:\n  - series: fx:USD\n    condition: \">\"\n    threshold: 4.8\n  - series: macro:cpi\n    change: yoy\n    condition: \">=\"\n    threshold: 3\n",
+			want: []alertRuleRequest{
+				{Series: "fx:USD", Condition: ">", Threshold: 4.8},
+				{Series: "macro:cpi", Change: "yoy", Condition: ">=", Threshold: 3},
+			},
+		},
+		{
+			name: "json",
+			data: `{"rules": [{"series": "stock:1155", "condition": "<", "threshold": 9.5}]}`,
+			want: []alertRuleRequest{{Series: "stock:1155", Condition: "<", Threshold: 9.5}},
+		},
+		{name: "empty document", data: ""},
+		{name: "no rules", data: "rules: []\n", want: []alertRuleRequest{}},
+		{name: "misspelt field", data: "rules:\n  - series: fx:USD\n    condtion: \">\"\n    threshold: 4.8\n", wantErr: true},
+		{name: "unknown top-level key", data: "alerts: []\n", wantErr: true},
+		{name: "threshold not a number", data: "rules:\n  - series: fx:USD\n    condition: \">\"\n    threshold: high\n", wantErr: true},
+		{name: "malformed", data: "rules: [", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseAlertRulesDocument([]byte(tt.data))
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidAlertImport) {
+					t.Fatalf("parseAlertRulesDocument error = %v, want errInvalidAlertImport", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAlertRulesDocument error = %v", err)
+			}
+			if !reflect.DeepEqual(doc.Rules, tt.want) {
+				t.Errorf("parseAlertRulesDocument rules = %+v, want %+v", doc.Rules, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertRulesDocumentRoundTrip(t *testing.T) {
+	doc := alertRulesDocument{Rules: []alertRuleRequest{
+		{Series: "fx:USD", Condition: ">", Threshold: 4.8},
+		{Series: "macro:cpi", Change: "mom", Condition: "<=", Threshold: -0.5},
+	}}
+	var buf bytes.Buffer
+	if err := encodeAlertRulesDocument(&buf, doc); err != nil {
+		t.Fatalf("encodeAlertRulesDocument error = %v", err)
+	}
+	got, err := parseAlertRulesDocument(buf.Bytes())
+	if err != nil {
+		t.Fatalf("parseAlertRulesDocument error = %v", err)
+	}
+	if !reflect.DeepEqual(got, doc) {
+		t.Errorf("round trip = %+v, want %+v", got, doc)
+	}
+}
+
+// The rejections below happen before importAlertRules opens a transaction, so no database is needed.
+func TestImportAlertRulesRejectsInvalidDocuments(t *testing.T) {
+	valid := alertRuleRequest{Series: "fx:USD", Condition: ">", Threshold: 4.8}
+	tests := []struct {
+		name  string
+		rules []alertRuleRequest
+		want  []string // Substrings of the error
+	}{
+		{
+			name:  "too many rules",
+			rules: make([]alertRuleRequest, maxImportedAlertRules+1),
+			want:  []string{"501 rules (maximum 500)"},
+		},
+		{
+			name:  "invalid condition",
+			rules: []alertRuleRequest{valid, {Series: "fx:USD", Condition: "=", Threshold: 4.8}},
+			want:  []string{`rule 2: invalid condition "="`},
+		},
+		{
+			name:  "unknown macro series",
+			rules: []alertRuleRequest{{Series: "macro:gdp", Condition: ">", Threshold: 1}},
+			want:  []string{`rule 1: unknown macro series "gdp"`},
+		},
+		{
+			name:  "change on a non-macro series",
+			rules: []alertRuleRequest{{Series: "fx:USD", Change: "yoy", Condition: ">", Threshold: 1}},
+			want:  []string{"rule 1: yoy alerts are only supported on macro series"},
+		},
+		{
+			name:  "threshold out of range",
+			rules: []alertRuleRequest{{Series: "fx:USD", Condition: ">", Threshold: 1e13}},
+			want:  []string{"rule 1: invalid threshold"},
+		},
+		{
+			name: "every problem is listed",
+			rules: []alertRuleRequest{
+				{Series: "fx:US", Condition: ">", Threshold: 1},
+				valid,
+				{Series: "macro:cpi", Change: "weekly", Condition: ">", Threshold: 1},
+			},
+			want: []string{"rule 1: invalid currency code", `rule 3: invalid change "weekly"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := importAlertRules(context.Background(), &AppState{}, uuid.New(), alertRulesDocument{Rules: tt.rules}, false)
+			if !errors.Is(err, errInvalidAlertImport) {
+				t.Fatalf("importAlertRules error = %v, want errInvalidAlertImport", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("importAlertRules error = %q, want it to contain %q", err, want)
+				}
+			}
+			if result != (AlertImportResult{}) {
+				t.Errorf("importAlertRules result = %+v, want nothing stored", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/google/uuid"
+)
+
+// alertConditions are the comparison operators an alert rule may use.
+var alertConditions = []string{">", ">=", "<", "<="}
+
+// maxAlertThreshold bounds alert thresholds to what alert_rules.threshold (DECIMAL(18, 6))
+// can store.
+const maxAlertThreshold = 1e12
+
+// alertChanges are the values an alert rule may compare: the level as stored, or for a macro
+// series its percent change from the same period a year earlier (yoy) or the previous one (mom).
+var alertChanges = []string{"level", "yoy", "mom"}
+
+// alertLabel names the value a rule compares, e.g. "fx:USD" or "macro:cpi yoy".
+func alertLabel(series, change string) string {
+	if change == "" || change == "level" {
+		return series
+	}
+	return series + " " + change
+}
+
+// alertConditionMet reports whether value satisfies "value <condition> threshold".
+func alertConditionMet(value float64, condition string, threshold float64) bool {
+	switch condition {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	}
+	return false
+}
+
+// newAlertRuleParams validates raw alert rule fields as entered by a user. A stock series must
+// be of a stored company; change (empty for level) may only be yoy or mom for a macro series.
+func newAlertRuleParams(ctx context.Context, s *AppState, userID uuid.UUID, series, change, condition, threshold string) (database.CreateAlertRuleParams, error) {
+	change = strings.ToLower(strings.TrimSpace(change))
+	if change == "" {
+		change = "level"
+	}
+	if !slices.Contains(alertChanges, change) {
+		return database.CreateAlertRuleParams{}, fmt.Errorf("invalid change %q (use %s)", change, strings.Join(alertChanges, ", "))
+	}
+	if kind, code, _ := strings.Cut(strings.TrimSpace(series), ":"); strings.EqualFold(kind, "macro") {
+		code = strings.ToLower(code)
+		if _, ok := macroSeries[code]; !ok {
+			return database.CreateAlertRuleParams{}, fmt.Errorf("unknown macro series %q", code)
+		}
+		series = "macro:" + code
+	} else {
+		key, err := parseSeriesKey(series)
+		if err != nil {
+			return database.CreateAlertRuleParams{}, err
+		}
+		if key.Kind == watchlistStock {
+			if _, err := lookupStockCode(ctx, s, key.Code); err != nil {
+				return database.CreateAlertRuleParams{}, err
+			}
+		}
+		if change != "level" {
+			return database.CreateAlertRuleParams{}, fmt.Errorf("%s alerts are only supported on macro series", change)
+		}
+		series = key.String()
+	}
+	condition = strings.TrimSpace(condition)
+	valid := false
+	for _, c := range alertConditions {
+		valid = valid || c == condition
+	}
+	if !valid {
+		return database.CreateAlertRuleParams{}, fmt.Errorf("invalid condition %q (use %s)", condition, strings.Join(alertConditions, ", "))
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(threshold), 64)
+	if err != nil || math.IsNaN(value) || math.Abs(value) >= maxAlertThreshold {
+		return database.CreateAlertRuleParams{}, fmt.Errorf("invalid threshold %q (must be a number below %g in magnitude)", threshold, maxAlertThreshold)
+	}
+	return database.CreateAlertRuleParams{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Series:    series,
+		Condition: condition,
+		Threshold: strconv.FormatFloat(value, 'f', -1, 64),
+		Change:    change,
+	}, nil
+}
+
+// errInvalidAlertSeries is returned for a rule whose stored series key no longer parses.
+var errInvalidAlertSeries = errors.New("invalid alert series")
+
+// latestAlertValue returns the latest value an alert rule on series compares, or sql.ErrNoRows
+// if there is none yet. Macro series are monthly, so their changes are taken between the latest
+// stored period and the one a year (yoy) or a month (mom) before it.
+func latestAlertValue(ctx context.Context, s *AppState, series, change string) (analytics.Point, error) {
+	if code, ok := strings.CutPrefix(series, "macro:"); ok {
+		today := markettime.Today()
+		points, err := loadMacroSeries(ctx, s, code, today.AddDate(-2, 0, 0), today)
+		if err != nil {
+			return analytics.Point{}, err
+		}
+		switch change {
+		case "yoy":
+			points = analytics.YearOnYear(points)
+		case "mom":
+			points = analytics.MonthOnMonth(points)
+		}
+		if len(points) == 0 {
+			return analytics.Point{}, sql.ErrNoRows
+		}
+		return points[len(points)-1], nil
+	}
+	key, err := parseSeriesKey(series)
+	if err != nil {
+		return analytics.Point{}, fmt.Errorf("%w: %v", errInvalidAlertSeries, err)
+	}
+	return latestSeriesValue(ctx, s, key)
+}
+
+// evaluateAlerts evaluates all alert rules and notifies the owners of any that fired.
+func evaluateAlerts(ctx context.Context, s *AppState) ([]database.AlertEvent, error) {
+	triggered, err := evaluateAlertRules(ctx, s)
+	notifyAlerts(ctx, s, triggered) // Empty after a failure, which records no events
+	return triggered, err
+}
+
+// evaluateAlertRules checks every alert rule against the latest value (or change) of its series.
+// A rule fires (recording an alert event) when its condition becomes true, and re-arms once it
+// is false again, so a level that stays past the threshold alerts only once. The rules stay
+// locked until the evaluation commits, so fetches finishing together cannot fire a rule twice.
+func evaluateAlertRules(ctx context.Context, s *AppState) ([]database.AlertEvent, error) {
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+	rules, err := qtx.ListAlertRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert rules: %w", err)
+	}
+
+	latest := make(map[string]*analytics.Point) // By alertLabel; nil entry: series has no data
+	var triggered []database.AlertEvent
+	for _, rule := range rules {
+		label := alertLabel(rule.Series, rule.Change)
+		point, seen := latest[label]
+		if !seen {
+			p, err := latestAlertValue(ctx, s, rule.Series, rule.Change)
+			if err != nil && err != sql.ErrNoRows {
+				if errors.Is(err, errInvalidAlertSeries) {
+					log.Printf("Alert rule %s has an invalid series: %v", rule.ID, err)
+					continue
+				}
+				return nil, err
+			}
+			if err == nil {
+				point = &p
+			}
+			latest[label] = point
+		}
+		if point == nil {
+			continue // No data yet
+		}
+
+		threshold, err := strconv.ParseFloat(rule.Threshold, 64)
+		if err != nil {
+			log.Printf("Alert rule %s has an invalid threshold '%s'", rule.ID, rule.Threshold)
+			continue
+		}
+		met := alertConditionMet(point.Value, rule.Condition, threshold)
+		if met == rule.IsTriggered {
+			continue // No change
+		}
+
+		if met {
+			event, err := qtx.CreateAlertEvent(ctx, database.CreateAlertEventParams{
+				ID:            uuid.New(),
+				RuleID:        rule.ID,
+				UserID:        rule.UserID,
+				Series:        rule.Series,
+				Condition:     rule.Condition,
+				Threshold:     rule.Threshold,
+				ObservedValue: strconv.FormatFloat(point.Value, 'f', -1, 64),
+				ObservedDate:  point.Date,
+				Change:        rule.Change,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to record alert for rule %s: %w", rule.ID, err)
+			}
+			log.Printf("Alert triggered: %s %s %s (observed %.4f on %s)", label, rule.Condition, rule.Threshold, point.Value, point.Date.Format("2006-01-02"))
+			triggered = append(triggered, event)
+		}
+		if err := qtx.SetAlertRuleTriggered(ctx, database.SetAlertRuleTriggeredParams{IsTriggered: met, ID: rule.ID}); err != nil {
+			return nil, fmt.Errorf("failed to update alert rule %s: %w", rule.ID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit alert evaluation: %w", err)
+	}
+	return triggered, nil
+}
+
+// runAlertsAfterFetch evaluates alerts at the end of a fetch cycle. Failures are logged rather
+// than returned so they do not mask the outcome of the fetch itself.
+func runAlertsAfterFetch(ctx context.Context, s *AppState) {
+	triggered, err := evaluateAlerts(ctx, s)
+	if err != nil {
+		log.Printf("Error evaluating alerts: %v", err)
+	}
+	if len(triggered) > 0 {
+		log.Printf("%d alert(s) triggered.", len(triggered))
+	}
+}
+
+// --- Alert Command Handlers ---
+
+// handlerAlerts lists the current user's alert rules and most recent triggered alerts.
+// Usage: alerts
+func handlerAlerts(s *AppState, cmd command) error {
+	user := cmd.user
+	ctx := cmd.Context()
+	rules, err := s.db.ListAlertRulesByUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load alert rules: %w", err)
+	}
+	if len(rules) == 0 {
+		fmt.Println("No alert rules. Add one with alerts:add <series> [yoy|mom] <condition> <threshold>, e.g. alerts:add fx:USD > 4.80 or alerts:add macro:cpi yoy > 3")
+	}
+	for _, r := range rules {
+		state := "armed"
+		if r.IsTriggered {
+			state = "triggered"
+		}
+		fmt.Printf("%s  %s %s %s  [%s]\n", r.ID, alertLabel(r.Series, r.Change), r.Condition, r.Threshold, state)
+	}
+
+	events, err := s.db.ListAlertEventsByUser(ctx, database.ListAlertEventsByUserParams{UserID: user.ID, MaxResults: 10})
+	if err != nil {
+		return fmt.Errorf("failed to load triggered alerts: %w", err)
+	}
+	if len(events) > 0 {
+		fmt.Println("Recent alerts:")
+	}
+	for _, e := range events {
+		fmt.Printf("  %s  %s %s %s (observed %s on %s)\n", e.TriggeredAt.Format("2006-01-02 15:04"), alertLabel(e.Series, e.Change), e.Condition, e.Threshold, e.ObservedValue, e.ObservedDate.Format("2006-01-02"))
+	}
+
+	announcementRules, err := s.db.ListAnnouncementAlertRulesByUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load announcement alert rules: %w", err)
+	}
+	if len(announcementRules) > 0 {
+		fmt.Println("Announcement alert rules:")
+	}
+	for _, r := range announcementRules {
+		fmt.Printf("%s  %s on %s\n", r.ID, r.Category, announcementRuleScope(r))
+	}
+	announcements, err := s.db.ListAnnouncementAlertEventsByUser(ctx, database.ListAnnouncementAlertEventsByUserParams{UserID: user.ID, MaxResults: 10})
+	if err != nil {
+		return fmt.Errorf("failed to load triggered announcement alerts: %w", err)
+	}
+	if len(announcements) > 0 {
+		fmt.Println("Recent announcements:")
+	}
+	for _, e := range announcements {
+		fmt.Printf("  %s  %s %s: %s\n", e.TriggeredAt.Format("2006-01-02 15:04"), e.StockCode, e.Category, e.Title)
+	}
+	return nil
+}
+
+// handlerAlertsAdd creates an alert rule for the current user. Macro series may be compared by
+// their year-on-year or month-on-month percent change instead of their level.
+// Usage: alerts:add <series> [yoy|mom] <condition> <threshold>   e.g. alerts:add stock:1155 < 9.00, alerts:add macro:cpi yoy > 3
+func handlerAlertsAdd(s *AppState, cmd command) error {
+	user := cmd.user
+	args := cmd.Args
+	change := ""
+	if len(args) == 4 {
+		change, args = args[1], []string{args[0], args[2], args[3]}
+	}
+	if len(args) != 3 {
+		return fmt.Errorf("usage: %s <stock:CODE|fx:CUR|macro:SERIES> [yoy|mom] <%s> <threshold>", cmd.Name, strings.Join(alertConditions, "|"))
+	}
+	params, err := newAlertRuleParams(cmd.Context(), s, user.ID, args[0], change, args[1], args[2])
+	if err != nil {
+		return err
+	}
+	rule, err := s.db.CreateAlertRule(cmd.Context(), params)
+	if err != nil {
+		return fmt.Errorf("failed to create alert rule: %w", err)
+	}
+	log.Printf("User %s added alert rule %s: %s %s %s.", user.Username, rule.ID, alertLabel(rule.Series, rule.Change), rule.Condition, rule.Threshold)
+	fmt.Printf("Added alert rule %s.\n", rule.ID)
+	return nil
+}
+
+// handlerAlertsRemove deletes one of the current user's alert rules.
+// Usage: alerts:remove <id>
+func handlerAlertsRemove(s *AppState, cmd command) error {
+	user := cmd.user
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <id>", cmd.Name)
+	}
+	id, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid alert rule ID %q: %w", cmd.Args[0], err)
+	}
+	n, err := s.db.DeleteAlertRule(cmd.Context(), database.DeleteAlertRuleParams{ID: id, UserID: user.ID})
+	if err != nil {
+		return fmt.Errorf("failed to remove alert rule %s: %w", id, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("alert rule %s not found", id)
+	}
+	log.Printf("User %s removed alert rule %s.", user.Username, id)
+	fmt.Printf("Removed alert rule %s.\n", id)
+	return nil
+}
+
+// handlerAlertsUpdate replaces the condition of one of the current user's alert rules and
+// re-arms it.
+// Usage: alerts:update <id> <series> [yoy|mom] <condition> <threshold>
+func handlerAlertsUpdate(s *AppState, cmd command) error {
+	user := cmd.user
+	usage := fmt.Errorf("usage: %s <id> <stock:CODE|fx:CUR|macro:SERIES> [yoy|mom] <%s> <threshold>", cmd.Name, strings.Join(alertConditions, "|"))
+	if len(cmd.Args) < 4 || len(cmd.Args) > 5 {
+		return usage
+	}
+	id, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid alert rule ID %q: %w", cmd.Args[0], err)
+	}
+	args := cmd.Args[1:]
+	change := ""
+	if len(args) == 4 {
+		change, args = args[1], []string{args[0], args[2], args[3]}
+	}
+	rule, err := updateAlertRule(cmd.Context(), s, user.ID, id, args[0], change, args[1], args[2])
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("alert rule %s not found", id)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("User %s updated alert rule %s: %s %s %s.", user.Username, rule.ID, alertLabel(rule.Series, rule.Change), rule.Condition, rule.Threshold)
+	fmt.Printf("Updated alert rule %s.\n", rule.ID)
+	return nil
+}
+
+// handlerAlertsExport writes the current user's alert rules as YAML to a file, or to standard
+// output without one, for alerts:import to restore.
+// Usage: alerts:export [file]
+func handlerAlertsExport(s *AppState, cmd command) error {
+	user := cmd.user
+	if len(cmd.Args) > 1 {
+		return fmt.Errorf("usage: %s [file]", cmd.Name)
+	}
+	doc, err := exportAlertRules(cmd.Context(), s, user.ID)
+	if err != nil {
+		return err
+	}
+	if len(cmd.Args) == 0 {
+		return encodeAlertRulesDocument(os.Stdout, doc)
+	}
+	var buf bytes.Buffer
+	if err := encodeAlertRulesDocument(&buf, doc); err != nil {
+		return fmt.Errorf("failed to encode alert rules: %w", err)
+	}
+	if err := os.WriteFile(cmd.Args[0], buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", cmd.Args[0], err)
+	}
+	fmt.Printf("Exported %d alert rule(s) to %s.\n", len(doc.Rules), cmd.Args[0])
+	return nil
+}
+
+// handlerAlertsImport adds the rules of a YAML (or JSON) file written by alerts:export to the
+// current user's alert rules, skipping those already present. --replace removes the user's
+// other rules first. Nothing is imported when any rule is invalid.
+// Usage: alerts:import <file> [--replace]
+func handlerAlertsImport(s *AppState, cmd command) error {
+	user := cmd.user
+	usage := fmt.Errorf("usage: %s <file> [--replace]", cmd.Name)
+	var file string
+	replace := false
+	for _, arg := range cmd.Args {
+		switch {
+		case arg == "--replace":
+			replace = true
+		case file == "" && !strings.HasPrefix(arg, "--"):
+			file = arg
+		default:
+			return usage
+		}
+	}
+	if file == "" {
+		return usage
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	doc, err := parseAlertRulesDocument(data)
+	if err != nil {
+		return err
+	}
+	result, err := importAlertRules(cmd.Context(), s, user.ID, doc, replace)
+	if err != nil {
+		return err
+	}
+	log.Printf("User %s imported alert rules from %s: %d added, %d skipped, %d removed.", user.Username, file, result.Added, result.Skipped, result.Removed)
+	fmt.Printf("Imported %s: %d rule(s) added, %d already present, %d removed.\n", file, result.Added, result.Skipped, result.Removed)
+	return nil
+}
+
+// handlerAlertsEvaluate evaluates all alert rules now, outside a fetch cycle.
+// Usage: alerts:evaluate
+func handlerAlertsEvaluate(s *AppState, cmd command) error {
+	triggered, err := evaluateAlerts(cmd.Context(), s)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d alert(s) triggered.\n", len(triggered))
+	return nil
+}
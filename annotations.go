@@ -0,0 +1,156 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/lib/pq"
+)
+
+// annotationCategories are the kinds of event an annotation can mark.
+var annotationCategories = []string{"opr", "budget", "election", "other"}
+
+// takeCountryFlag removes a --country=XX argument from args, returning the remaining
+// arguments and the normalized country ("" when the flag is absent).
+func takeCountryFlag(s *AppState, args []string) ([]string, string, error) {
+	rest := make([]string, 0, len(args))
+	country := ""
+	for _, arg := range args {
+		value, ok := strings.CutPrefix(arg, "--country=")
+		if !ok {
+			rest = append(rest, arg)
+			continue
+		}
+		if value == "" {
+			return nil, "", fmt.Errorf("--country needs a value, e.g. --country=SG")
+		}
+		var err error
+		if country, err = normalizeCountry(s, value); err != nil {
+			return nil, "", err
+		}
+	}
+	return rest, country, nil
+}
+
+// handlerAnnotations lists the stored chart annotations in a date range, optionally only
+// those of one country.
+// Usage: annotations [START_DATE END_DATE] [--country=XX]  (default: the last 12 months)
+func handlerAnnotations(s *AppState, cmd command) error {
+	args, country, err := takeCountryFlag(s, cmd.Args)
+	if err != nil {
+		return err
+	}
+	end := markettime.Today()
+	start := end.AddDate(-1, 0, 0)
+	switch len(args) {
+	case 0:
+	case 2:
+		if start, err = markettime.ParseDate(args[0]); err != nil {
+			return fmt.Errorf("invalid start date %q (use YYYY-MM-DD)", args[0])
+		}
+		if end, err = markettime.ParseDate(args[1]); err != nil {
+			return fmt.Errorf("invalid end date %q (use YYYY-MM-DD)", args[1])
+		}
+	default:
+		return fmt.Errorf("usage: %s [START_DATE END_DATE] [--country=XX]", cmd.Name)
+	}
+	rows, err := s.db.ListAnnotationsBetween(cmd.Context(), database.ListAnnotationsBetweenParams{
+		StartDate:   start,
+		EndDate:     end,
+		CountryCode: sql.NullString{String: country, Valid: country != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list annotations: %w", err)
+	}
+	if len(rows) == 0 {
+		fmt.Printf("No annotations between %s and %s.\n", start.Format("2006-01-02"), end.Format("2006-01-02"))
+		return nil
+	}
+	for _, row := range rows {
+		fmt.Printf("  %4d %s  %s %-8s %s\n", row.ID, row.EventDate.Format("2006-01-02"), row.CountryCode, row.Category, row.Title)
+	}
+	return nil
+}
+
+// handlerAnnotationsAdd stores a chart annotation (admin only). The event belongs to
+// DEFAULT_COUNTRY unless --country is given.
+// Usage: annotations:add [--country=XX] <YYYY-MM-DD> <opr|budget|election|other> <title> [-- description]
+func handlerAnnotationsAdd(s *AppState, cmd command) error {
+	user := cmd.user
+	usage := fmt.Errorf("usage: %s [--country=XX] <YYYY-MM-DD> <%s> <title> [-- description]", cmd.Name, strings.Join(annotationCategories, "|"))
+	args, country, err := takeCountryFlag(s, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if country == "" {
+		country = s.cfg.DefaultCountry
+	}
+	if len(args) < 3 {
+		return usage
+	}
+	date, err := markettime.ParseDate(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid date %q (use YYYY-MM-DD)", args[0])
+	}
+	category := strings.ToLower(args[1])
+	if !slices.Contains(annotationCategories, category) {
+		return fmt.Errorf("invalid category %q (use %s)", args[1], strings.Join(annotationCategories, ", "))
+	}
+	titleWords, descriptionWords := args[2:], []string(nil)
+	if i := slices.Index(titleWords, "--"); i >= 0 {
+		titleWords, descriptionWords = titleWords[:i], titleWords[i+1:]
+	}
+	title := strings.Join(titleWords, " ")
+	if title == "" || len(title) > 200 {
+		return usage
+	}
+
+	a, err := s.db.CreateAnnotation(cmd.Context(), database.CreateAnnotationParams{
+		EventDate:   date,
+		Category:    category,
+		Title:       title,
+		Description: strings.Join(descriptionWords, " "),
+		Source:      user.Username,
+		CountryCode: country,
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return fmt.Errorf("an annotation %q (%s, %s) already exists on %s", title, category, country, date.Format("2006-01-02"))
+		}
+		return fmt.Errorf("failed to store annotation: %w", err)
+	}
+	invalidateResponseCache(s)
+	log.Printf("User %s added annotation %d: %s %s %s %s.", user.Username, a.ID, date.Format("2006-01-02"), country, category, title)
+	fmt.Printf("Added annotation %d.\n", a.ID)
+	return nil
+}
+
+// handlerAnnotationsDelete removes a chart annotation by id (admin only).
+// Usage: annotations:delete <id>
+func handlerAnnotationsDelete(s *AppState, cmd command) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <id>", cmd.Name)
+	}
+	id, err := strconv.ParseInt(cmd.Args[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid annotation id %q", cmd.Args[0])
+	}
+	n, err := s.db.DeleteAnnotation(cmd.Context(), int32(id))
+	if err != nil {
+		return fmt.Errorf("failed to delete annotation: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("no annotation with id %d", id)
+	}
+	invalidateResponseCache(s)
+	fmt.Printf("Deleted annotation %d.\n", id)
+	return nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/google/uuid"
+)
+
+// Announcement categories an announcement alert rule may watch. "any" matches every category.
+const (
+	announcementAny             = "any"
+	announcementResults         = "results"
+	announcementDividend        = "dividend"
+	announcementCorporateAction = "corporate_action"
+	announcementMerger          = "merger"
+)
+
+var announcementCategories = []string{announcementAny, announcementResults, announcementDividend, announcementCorporateAction, announcementMerger}
+
+// announcementHeadlinePatterns classify headlines; a headline may fall into several categories.
+var announcementHeadlinePatterns = map[string]*regexp.Regexp{
+	announcementResults:         regexp.MustCompile(`(?i)\b(results?|earnings|quarterly|[1-4]Q(FY)?\d*|Q[1-4]|net profit|revenue|profit (rises|falls|jumps|drops|up|down))\b`),
+	announcementDividend:        regexp.MustCompile(`(?i)\b(dividends?|distribution|payout)\b`),
+	announcementCorporateAction: regexp.MustCompile(`(?i)\b(bonus issue|rights issue|share split|subdivision|share consolidation|private placement|warrants?)\b`),
+	announcementMerger:          regexp.MustCompile(`(?i)\b(merger|merge|acquisitions?|acquires?|takeover|disposals?|disposes?|privati[sz]ation)\b`),
+}
+
+// announcement is a newly stored headline or entitlement about a stock.
+type announcement struct {
+	StockCode  string
+	Categories []string
+	Title      string
+	Link       string
+	At         time.Time
+}
+
+// headlineCategories returns the categories a headline falls into (none if it is general news).
+func headlineCategories(title string) []string {
+	var categories []string
+	for _, category := range announcementCategories[1:] {
+		if announcementHeadlinePatterns[category].MatchString(title) {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// entitlementAnnouncement describes a newly stored entitlement as an announcement.
+func entitlementAnnouncement(e scrapedEntitlement, url string) announcement {
+	category := announcementCorporateAction
+	if e.Type == "dividend" {
+		category = announcementDividend
+	}
+	title := fmt.Sprintf("%s going ex on %s", e.Type, e.ExDate.Format("2006-01-02"))
+	if e.Description != "" {
+		title = fmt.Sprintf("%s, going ex on %s", e.Description, e.ExDate.Format("2006-01-02"))
+	}
+	return announcement{StockCode: e.StockCode, Categories: []string{category}, Title: title, Link: url, At: time.Now().UTC()}
+}
+
+// newAnnouncementAlertRuleParams validates raw announcement alert rule fields as entered by a
+// user. An empty stockCode makes the rule watch the user's watchlist.
+func newAnnouncementAlertRuleParams(ctx context.Context, s *AppState, userID uuid.UUID, category, stockCode string) (database.CreateAnnouncementAlertRuleParams, error) {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if !slices.Contains(announcementCategories, category) {
+		return database.CreateAnnouncementAlertRuleParams{}, fmt.Errorf("invalid category %q (use %s)", category, strings.Join(announcementCategories, ", "))
+	}
+	if strings.TrimSpace(stockCode) != "" {
+		var err error
+		if stockCode, err = lookupStockCode(ctx, s, stockCode); err != nil {
+			return database.CreateAnnouncementAlertRuleParams{}, err
+		}
+	}
+	return database.CreateAnnouncementAlertRuleParams{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Category:  category,
+		StockCode: sql.NullString{String: stockCode, Valid: stockCode != ""},
+	}, nil
+}
+
+// evaluateAnnouncementAlerts matches newly stored announcements against every announcement
+// alert rule, recording an event for each match. Rules without a stock code watch the stocks
+// on their owner's watchlist at the time of the announcement.
+func evaluateAnnouncementAlerts(ctx context.Context, s *AppState, items []announcement) ([]database.AnnouncementAlertEvent, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	rules, err := s.db.ListAnnouncementAlertRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load announcement alert rules: %w", err)
+	}
+
+	watchlists := make(map[uuid.UUID][]string)
+	var triggered []database.AnnouncementAlertEvent
+	for _, rule := range rules {
+		stocks := []string{rule.StockCode.String}
+		if !rule.StockCode.Valid {
+			var loaded bool
+			if stocks, loaded = watchlists[rule.UserID]; !loaded {
+				watchItems, err := s.db.ListWatchlistItemsByUser(ctx, rule.UserID)
+				if err != nil {
+					return triggered, fmt.Errorf("failed to load watchlist for announcement alert rule %s: %w", rule.ID, err)
+				}
+				for _, item := range watchItems {
+					if item.ItemType == watchlistStock {
+						stocks = append(stocks, item.Code)
+					}
+				}
+				watchlists[rule.UserID] = stocks
+			}
+		}
+
+		for _, item := range items {
+			if !slices.Contains(stocks, item.StockCode) || len(item.Categories) == 0 {
+				continue
+			}
+			category := item.Categories[0]
+			if rule.Category != announcementAny {
+				if !slices.Contains(item.Categories, rule.Category) {
+					continue
+				}
+				category = rule.Category
+			}
+			event, err := s.db.CreateAnnouncementAlertEvent(ctx, database.CreateAnnouncementAlertEventParams{
+				ID:          uuid.New(),
+				RuleID:      rule.ID,
+				UserID:      rule.UserID,
+				StockCode:   item.StockCode,
+				Category:    category,
+				Title:       item.Title,
+				Link:        item.Link,
+				AnnouncedAt: item.At,
+			})
+			if err != nil {
+				return triggered, fmt.Errorf("failed to record announcement alert for rule %s: %w", rule.ID, err)
+			}
+			log.Printf("Announcement alert triggered: %s %s for rule %s (%s)", item.StockCode, category, rule.ID, item.Title)
+			triggered = append(triggered, event)
+		}
+	}
+	return triggered, nil
+}
+
+// runAnnouncementAlerts evaluates the announcement alert rules against the announcements a
+// fetch just stored and notifies the owners of the rules that matched. Failures are logged
+// rather than returned so they do not mask the outcome of the fetch itself.
+func runAnnouncementAlerts(ctx context.Context, s *AppState, items []announcement) {
+	triggered, err := evaluateAnnouncementAlerts(ctx, s, items)
+	notifyAnnouncementAlerts(ctx, s, triggered) // Those recorded before a failure are still sent
+	if err != nil {
+		log.Printf("Error evaluating announcement alerts: %v", err)
+	}
+	if len(triggered) > 0 {
+		log.Printf("%d announcement alert(s) triggered.", len(triggered))
+	}
+}
+
+// --- Announcement Alert Command Handlers ---
+
+// handlerAlertsAnnouncementsAdd creates an announcement alert rule for the current user.
+// Usage: alerts:announcements:add <category> [CODE]  (without CODE: every stock on your watchlist)
+func handlerAlertsAnnouncementsAdd(s *AppState, cmd command) error {
+	user := cmd.user
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		return fmt.Errorf("usage: %s <%s> [stock_code]", cmd.Name, strings.Join(announcementCategories, "|"))
+	}
+	code := ""
+	if len(cmd.Args) == 2 {
+		code = cmd.Args[1]
+	}
+	params, err := newAnnouncementAlertRuleParams(cmd.Context(), s, user.ID, cmd.Args[0], code)
+	if err != nil {
+		return err
+	}
+	rule, err := s.db.CreateAnnouncementAlertRule(cmd.Context(), params)
+	if err != nil {
+		return fmt.Errorf("failed to create announcement alert rule: %w", err)
+	}
+	log.Printf("User %s added announcement alert rule %s: %s on %s.", user.Username, rule.ID, rule.Category, announcementRuleScope(rule))
+	fmt.Printf("Added announcement alert rule %s.\n", rule.ID)
+	return nil
+}
+
+// handlerAlertsAnnouncementsRemove deletes one of the current user's announcement alert rules.
+// Usage: alerts:announcements:remove <id>
+func handlerAlertsAnnouncementsRemove(s *AppState, cmd command) error {
+	user := cmd.user
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <id>", cmd.Name)
+	}
+	id, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid rule id %q", cmd.Args[0])
+	}
+	n, err := s.db.DeleteAnnouncementAlertRule(cmd.Context(), database.DeleteAnnouncementAlertRuleParams{ID: id, UserID: user.ID})
+	if err != nil {
+		return fmt.Errorf("failed to remove announcement alert rule: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("no announcement alert rule %s", id)
+	}
+	fmt.Printf("Removed announcement alert rule %s.\n", id)
+	return nil
+}
+
+// announcementRuleScope describes what a rule watches, for display.
+func announcementRuleScope(rule database.AnnouncementAlertRule) string {
+	if rule.StockCode.Valid {
+		return rule.StockCode.String
+	}
+	return "watchlist"
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/google/uuid"
+)
+
+// Sources recorded in audit_log.source.
+const (
+	auditSourceCLI = "cli"
+	auditSourceAPI = "api"
+)
+
+// auditRedactedArgs maps commands whose arguments include secrets to the index of the
+// first argument that must not be written to the audit log or the command log.
+var auditRedactedArgs = map[string]int{
+	"login":           1, // <password>
+	"register":        2, // <password>
+	"webhook:add":     2, // [secret]
+	"ingest:register": 2, // [secret]
+}
+
+// recordAudit writes an audit_log entry for an action by user. Failures are logged but do
+// not fail the action itself. user may be a bare database.User{Username: ...} when the
+// account is unknown (e.g. a failed login).
+func recordAudit(ctx context.Context, s *AppState, user database.User, source, action, details, remoteAddr string) {
+	err := s.db.CreateAuditLogEntry(ctx, database.CreateAuditLogEntryParams{
+		UserID:     uuid.NullUUID{UUID: user.ID, Valid: user.ID != uuid.Nil},
+		Username:   user.Username,
+		Action:     action,
+		Details:    details,
+		Source:     source,
+		RemoteAddr: remoteAddr,
+	})
+	if err != nil {
+		log.Printf("Error writing audit log entry (%s by %s): %v", action, user.Username, err)
+	}
+}
+
+// auditCommandDetails describes a CLI command invocation and its outcome, with any
+// secret arguments redacted.
+func auditCommandDetails(cmd command, cmdErr error) string {
+	args := cmd.Args
+	if from, ok := auditRedactedArgs[cmd.Name]; ok && len(args) > from {
+		args = append(append([]string{}, args[:from]...), "[redacted]")
+	}
+	details := strings.Join(args, " ")
+	if cmdErr != nil {
+		details = strings.TrimSpace(details + " (failed: " + cmdErr.Error() + ")")
+	}
+	return details
+}
+
+// handlerAudit prints the most recent audit log entries (admin only).
+// Usage: audit [limit] [--action=A] [--user=U]
+func handlerAudit(s *AppState, cmd command) error {
+	params := database.ListAuditLogParams{MaxResults: 50}
+	for _, arg := range cmd.Args {
+		if value, ok := strings.CutPrefix(arg, "--action="); ok {
+			params.Action = sql.NullString{String: value, Valid: value != ""}
+			continue
+		}
+		if value, ok := strings.CutPrefix(arg, "--user="); ok {
+			params.Username = sql.NullString{String: value, Valid: value != ""}
+			continue
+		}
+		limit, err := strconv.Atoi(arg)
+		if err != nil || limit < 1 || limit > 1000 {
+			return fmt.Errorf("usage: %s [limit 1-1000] [--action=A] [--user=U]", cmd.Name)
+		}
+		params.MaxResults = int32(limit)
+	}
+
+	entries, err := s.db.ListAuditLog(cmd.Context(), params)
+	if err != nil {
+		return fmt.Errorf("failed to load audit log: %w", err)
+	}
+	if len(entries) == 0 {
+		fmt.Println("No audit log entries.")
+		return nil
+	}
+	for _, e := range entries {
+		fmt.Printf("%s  %-3s %-16s %-24s %s\n", e.CreatedAt.Format("2006-01-02 15:04:05"), e.Source, e.Username, e.Action, e.Details)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// macroAuditYears is the least history audit:report checks of a monthly macro series, so
+// there are enough changes to judge outliers by.
+const macroAuditYears = 5
+
+// seriesAnomaly is an anomaly found in one series by audit:report.
+type seriesAnomaly struct {
+	Series string
+	analytics.Anomaly
+}
+
+// handlerAuditReport runs the statistical quality checks over every stock, currency and macro
+// series and prints the findings ranked by severity: gaps of missing trading days (months for
+// macro series), outlying changes, values stuck for several observations and suspicious zeros.
+// These catch scraper regressions that still store well-formed rows. Illiquid stocks may
+// legitimately show stuck closes. Daily series are checked over the last --days (default 365),
+// macro series over at least five years (admin only).
+// Usage: audit:report [--days=N] [--gap=N] [--z=X] [--stuck=N] [--top=N]
+func handlerAuditReport(s *AppState, cmd command) error {
+	usage := fmt.Errorf("usage: %s [--days=N] [--gap=N] [--z=X] [--stuck=N] [--top=N]", cmd.Name)
+	days, top := 365, 50
+	thresholds := analytics.QualityThresholds{MinGap: 3, OutlierZ: 6, MinStuckRun: 5}
+	for _, arg := range cmd.Args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return usage
+		}
+		var err error
+		switch name {
+		case "--days":
+			days, err = strconv.Atoi(value)
+			ok = err == nil && days >= 30
+		case "--gap":
+			thresholds.MinGap, err = strconv.Atoi(value)
+			ok = err == nil && thresholds.MinGap >= 1
+		case "--z":
+			thresholds.OutlierZ, err = strconv.ParseFloat(value, 64)
+			ok = err == nil && thresholds.OutlierZ > 0
+		case "--stuck":
+			thresholds.MinStuckRun, err = strconv.Atoi(value)
+			ok = err == nil && thresholds.MinStuckRun >= 2
+		case "--top":
+			top, err = strconv.Atoi(value)
+			ok = err == nil && top >= 1
+		default:
+			return usage
+		}
+		if !ok {
+			return fmt.Errorf("invalid %s value %q", name, value)
+		}
+	}
+
+	ctx := cmd.Context()
+	calendar, err := loadTradingCalendar(ctx, s)
+	if err != nil {
+		return err
+	}
+	stocks, err := s.db.ListStockCodesWithPrices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list stock codes: %w", err)
+	}
+	currencies, err := s.db.ListForeignExchangeCurrencies(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list currencies: %w", err)
+	}
+	end := markettime.Today()
+	start := end.AddDate(0, 0, -days)
+
+	var found []seriesAnomaly
+	checked := 0
+	check := func(series string, points []analytics.Point, next func(time.Time) time.Time, positive bool) {
+		checked++
+		for _, a := range analytics.QualityChecks(points, next, positive, thresholds) {
+			found = append(found, seriesAnomaly{Series: series, Anomaly: a})
+		}
+	}
+	var keys []seriesKey
+	for _, code := range stocks {
+		keys = append(keys, seriesKey{Kind: watchlistStock, Code: code})
+	}
+	for _, code := range currencies {
+		keys = append(keys, seriesKey{Kind: watchlistFx, Code: code})
+	}
+	for _, key := range keys {
+		points, err := loadSeries(ctx, s, key, start, end)
+		if err != nil {
+			log.Printf("Error loading %s for the audit report: %v", key, err)
+			continue
+		}
+		check(key.String(), points, calendar.NextTradingDay, true)
+	}
+
+	macroStart := start
+	if earliest := end.AddDate(-macroAuditYears, 0, 0); earliest.Before(macroStart) {
+		macroStart = earliest
+	}
+	nextMonth := func(d time.Time) time.Time { return d.AddDate(0, 1, 0) }
+	codes := make([]string, 0, len(macroSeries))
+	for code := range macroSeries {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		points, err := loadMacroSeries(ctx, s, code, macroStart, end)
+		if err != nil {
+			log.Printf("Error loading macro:%s for the audit report: %v", code, err)
+			continue
+		}
+		if len(points) > 0 {
+			check("macro:"+code, points, nextMonth, false) // Growth rates and the activity index may be negative
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Score > found[j].Score })
+	counts := make(map[string]int)
+	for _, f := range found {
+		counts[f.Check]++
+	}
+	fmt.Printf("Checked %d series from %s: %d gap(s), %d outlier(s), %d stuck value(s), %d zero(s).\n",
+		checked, start.Format("2006-01-02"), counts[analytics.CheckGap], counts[analytics.CheckOutlier],
+		counts[analytics.CheckStuck], counts[analytics.CheckZero])
+	if len(found) > top {
+		fmt.Printf("Showing the %d most severe:\n", top)
+		found = found[:top]
+	}
+	for i, f := range found {
+		fmt.Printf("%3d. %6.1f  %-16s %-8s %s\n", i+1, f.Score, f.Series, f.Check, describeAnomaly(f.Anomaly))
+	}
+	return nil
+}
+
+// describeAnomaly explains a finding in the terms of its check.
+func describeAnomaly(a analytics.Anomaly) string {
+	switch a.Check {
+	case analytics.CheckGap:
+		return fmt.Sprintf("%.0f expected observation(s) missing between %s and %s", a.Value, a.Start.Format("2006-01-02"), a.End.Format("2006-01-02"))
+	case analytics.CheckOutlier:
+		return fmt.Sprintf("%g on %s is an outlying change", a.Value, a.Start.Format("2006-01-02"))
+	case analytics.CheckStuck:
+		return fmt.Sprintf("%g unchanged from %s to %s", a.Value, a.Start.Format("2006-01-02"), a.End.Format("2006-01-02"))
+	case analytics.CheckZero:
+		return fmt.Sprintf("%g on %s", a.Value, a.Start.Format("2006-01-02"))
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestAuditCommandDetails(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  command
+		err  error
+		want string
+	}{
+		{name: "login password", cmd: command{Name: "login", Args: []string{"alice", "hunter2"}}, want: "alice [redacted]"},
+		{name: "register password", cmd: command{Name: "register", Args: []string{"alice", "alice@example.com", "hunter2"}}, want: "alice alice@example.com [redacted]"},
+		{name: "webhook secret", cmd: command{Name: "webhook:add", Args: []string{"https://example.com/hook", "fetch.failed", "s3cret"}}, want: "https://example.com/hook fetch.failed [redacted]"},
+		{name: "ingest secret", cmd: command{Name: "ingest:register", Args: []string{"bank", "macro:pmi", "s3cret"}}, want: "bank macro:pmi [redacted]"},
+		{name: "everything after the secret", cmd: command{Name: "login", Args: []string{"alice", "pass", "word"}}, want: "alice [redacted]"},
+		{name: "optional secret left out", cmd: command{Name: "webhook:add", Args: []string{"https://example.com/hook", "fetch.failed"}}, want: "https://example.com/hook fetch.failed"},
+		{name: "missing password", cmd: command{Name: "login", Args: []string{"alice"}}, want: "alice"},
+		{name: "no secrets", cmd: command{Name: "stock:fetch", Args: []string{"1155", "--force"}}, want: "1155 --force"},
+		{name: "no arguments", cmd: command{Name: "fx:fetch"}, want: ""},
+		{name: "failure", cmd: command{Name: "login", Args: []string{"alice", "hunter2"}}, err: errors.New("invalid credentials"), want: "alice [redacted] (failed: invalid credentials)"},
+		{name: "failure without arguments", cmd: command{Name: "fx:fetch"}, err: errors.New("timeout"), want: "(failed: timeout)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := slices.Clone(tt.cmd.Args)
+			if got := auditCommandDetails(tt.cmd, tt.err); got != tt.want {
+				t.Errorf("auditCommandDetails(%s %q) = %q, want %q", tt.cmd.Name, tt.cmd.Args, got, tt.want)
+			}
+			if !slices.Equal(tt.cmd.Args, args) {
+				t.Errorf("auditCommandDetails changed the command's arguments to %q", tt.cmd.Args)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// maxBasketComponents caps the number of stocks in one basket.
+const maxBasketComponents = 50
+
+// computeBasketValues recomputes the composite index of every basket. Failures are logged
+// per basket and reported together. It returns the number of values stored.
+func computeBasketValues(ctx context.Context, s *AppState) (int, error) {
+	baskets, err := s.db.ListBaskets(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list baskets: %w", err)
+	}
+	stored, failed := 0, 0
+	for _, basket := range baskets {
+		n, err := computeBasket(ctx, s, basket)
+		if err != nil {
+			log.Printf("Error computing basket %s (%s): %v", basket.ID, basket.Name, err)
+			failed++
+			continue
+		}
+		stored += n
+	}
+	if failed > 0 {
+		return stored, fmt.Errorf("failed to compute %d of %d baskets", failed, len(baskets))
+	}
+	return stored, nil
+}
+
+// computeBasket replaces the stored index values of one basket.
+func computeBasket(ctx context.Context, s *AppState, basket database.Basket) (int, error) {
+	components, err := s.db.ListBasketComponents(ctx, basket.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load components: %w", err)
+	}
+	baseValue, err := strconv.ParseFloat(basket.BaseValue, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid base value %q: %w", basket.BaseValue, err)
+	}
+
+	end := markettime.Today()
+	weights := make(map[string]float64, len(components))
+	prices := make(map[string][]analytics.Point, len(components))
+	for _, c := range components {
+		w, err := strconv.ParseFloat(c.Weight, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid weight for %s: %w", c.StockCode, err)
+		}
+		closes, err := loadStockCloses(ctx, s, c.StockCode, basket.BaseDate, end)
+		if err != nil {
+			return 0, err
+		}
+		// The close carried in from before the base date counts as the base-date price
+		if len(closes) > 0 && closes[0].Date.Before(basket.BaseDate) {
+			closes[0].Date = basket.BaseDate
+		}
+		weights[c.StockCode] = w
+		prices[c.StockCode] = closes
+	}
+
+	index := analytics.CompositeIndex(prices, weights, baseValue)
+
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+	if err := qtx.DeleteBasketValues(ctx, basket.ID); err != nil {
+		return 0, fmt.Errorf("failed to clear basket values: %w", err)
+	}
+	for _, p := range index {
+		err := qtx.UpsertBasketValue(ctx, database.UpsertBasketValueParams{
+			BasketID: basket.ID,
+			Date:     p.Date,
+			Value:    fmt.Sprintf("%.6f", p.Value),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to store value for %s: %w", p.Date.Format("2006-01-02"), err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit basket values: %w", err)
+	}
+	return len(index), nil
+}
+
+// handlerBasketsCompute recomputes every basket's composite index on demand.
+// Usage: baskets:compute
+func handlerBasketsCompute(s *AppState, cmd command) error {
+	n, err := computeBasketValues(cmd.Context(), s)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Stored %d basket index values.\n", n)
+	return nil
+}
@@ -11,6 +11,8 @@ import (
 	"os"
 	"strings"
 	"sync"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/auth"
 	// Keep these if handlers or the state struct reference them
 	// "github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
 	// "github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
@@ -20,7 +22,7 @@ import (
 
 // --- Interactive CLI Function ---
 // --- MODIFIED: Accept programState *state instead of cfg config.Config ---
-func runCli(cancelFunc context.CancelFunc, wg *sync.WaitGroup, shutdownChan chan struct{}, programState *AppState) {
+func runCli(ctx context.Context, cancelFunc context.CancelFunc, wg *sync.WaitGroup, shutdownChan chan struct{}, programState *AppState) {
 	defer wg.Done() // Signal WaitGroup when this goroutine exits
 	defer func() {
 		// Ensure shutdown is triggered if CLI exits for any reason
@@ -35,29 +37,116 @@ func runCli(cancelFunc context.CancelFunc, wg *sync.WaitGroup, shutdownChan chan
 
 	log.Println("Starting Interactive CLI. Type 'help' for commands, 'exit' or 'quit' to stop.")
 
+	scanner := bufio.NewReader(os.Stdin) // Reader for standard input, shared with confirmation prompts
+
 	// --- Command Registration ---
 	// Create commands struct and initialize empty map
 	cmds := commands{
-		registeredCommands: make(map[string]func(*AppState, command) error),
+		registeredCommands: make(map[string]commandHandler),
+		input:              scanner,
 	}
+	cmds.use(logCommandRuns)
 
 	// Register commands (This part remains the same, it uses the programState parameter)
 	cmds.register("help", handlerHelp)
 	cmds.register("login", handlerLogin)
 	cmds.register("register", handlerRegister)
-	cmds.register("reset", handlerResetDatabase)
-	cmds.register("users", handlerGetUsers)
+	cmds.register("logout", handlerLogout, withLogin)
+	cmds.register("whoami", handlerWhoami, withLogin)
+	cmds.register("reset", handlerResetDatabase, withRole(auth.RoleAdmin), cmds.withConfirmation("Reset the database? Every stored row will be deleted."))
+	cmds.register("users", handlerGetUsers, withRole(auth.RoleAdmin))
+	cmds.register("users:role", handlerUserRole, withRole(auth.RoleAdmin))
+	cmds.register("users:delete", handlerUserDelete, withRole(auth.RoleAdmin), cmds.withConfirmation("Delete the user? This cannot be undone."))
+	cmds.register("audit", handlerAudit, withRole(auth.RoleAdmin))
+	cmds.register("apikey:create", handlerAPIKeyCreate, withRole(auth.RoleAdmin))
+	cmds.register("apikey:list", handlerAPIKeyList, withRole(auth.RoleAdmin))
+	cmds.register("apikey:revoke", handlerAPIKeyRevoke, withRole(auth.RoleAdmin), cmds.withConfirmation("Revoke the API key? Clients using it stop working at once."))
+	cmds.register("watchlist", handlerWatchlist, withLogin)
+	cmds.register("watchlist:add", handlerWatchlistAdd, withRole(auth.RoleEditor))
+	cmds.register("watchlist:remove", handlerWatchlistRemove, withRole(auth.RoleEditor))
+	cmds.register("companies", handlerCompanies)
+	cmds.register("company:add", handlerCompanyAdd, withRole(auth.RoleAdmin))
+	cmds.register("sectors", handlerSectors)
+	cmds.register("sectors:add", handlerSectorsAdd, withRole(auth.RoleAdmin))
+	cmds.register("sectors:merge", handlerSectorsMerge, withRole(auth.RoleAdmin))
+	cmds.register("sectors:normalize", handlerSectorsNormalize, withRole(auth.RoleAdmin))
+	cmds.register("sectors:flows", handlerSectorsFlows)
+	cmds.register("sectors:flows:refresh", handlerSectorsFlowsRefresh, withRole(auth.RoleAdmin))
+	cmds.register("tracked", handlerTracked)
+	cmds.register("tracked:add", handlerTrackedAdd, withRole(auth.RoleAdmin))
+	cmds.register("tracked:remove", handlerTrackedRemove, withRole(auth.RoleAdmin))
+	cmds.register("portfolio", handlerPortfolio, withLogin)
+	cmds.register("portfolio:add", handlerPortfolioAdd, withRole(auth.RoleEditor))
+	cmds.register("portfolio:remove", handlerPortfolioRemove, withRole(auth.RoleEditor))
+	cmds.register("alerts", handlerAlerts, withLogin)
+	cmds.register("alerts:add", handlerAlertsAdd, withRole(auth.RoleEditor))
+	cmds.register("alerts:remove", handlerAlertsRemove, withRole(auth.RoleEditor))
+	cmds.register("alerts:update", handlerAlertsUpdate, withRole(auth.RoleEditor))
+	cmds.register("alerts:export", handlerAlertsExport, withLogin)
+	cmds.register("alerts:import", handlerAlertsImport, withRole(auth.RoleEditor))
+	cmds.register("alerts:announcements:add", handlerAlertsAnnouncementsAdd, withRole(auth.RoleEditor))
+	cmds.register("alerts:announcements:remove", handlerAlertsAnnouncementsRemove, withRole(auth.RoleEditor))
+	cmds.register("alerts:evaluate", handlerAlertsEvaluate, withRole(auth.RoleAdmin))
+	cmds.register("annotations", handlerAnnotations)
+	cmds.register("annotations:add", handlerAnnotationsAdd, withRole(auth.RoleAdmin))
+	cmds.register("annotations:delete", handlerAnnotationsDelete, withRole(auth.RoleAdmin))
+	cmds.register("report:generate", handlerReportGenerate, withLogin)
+	cmds.register("telegram:link", handlerTelegramLink, withLogin)
+	cmds.register("telegram:unlink", handlerTelegramUnlink, withLogin)
+	cmds.register("webhook:add", handlerWebhookAdd, withRole(auth.RoleAdmin))
+	cmds.register("webhook:list", handlerWebhookList, withRole(auth.RoleAdmin))
+	cmds.register("webhook:remove", handlerWebhookRemove, withRole(auth.RoleAdmin))
+	cmds.register("ingest:register", handlerIngestRegister, withRole(auth.RoleAdmin))
+	cmds.register("ingest:list", handlerIngestList, withRole(auth.RoleAdmin))
+	cmds.register("ingest:remove", handlerIngestRemove, withRole(auth.RoleAdmin))
 	cmds.register("testing", handlerTesting)
-	cmds.register("fx:fetch_all", handlerFxFetchAll)
-	cmds.register("fx:fetch:range", handlerFxFetchRange)
-	cmds.register("stock:fetch:price", handlerStockFetchPrice)
-	cmds.register("stock:fetch:price_all", handlerStockFetchPriceAll) // Renamed command key slightly for consistency
-	cmds.register("stock:fetch:profile", handlerStockFetchProfile)
-	cmds.register("stock:fetch:profile_all", handlerStockFetchPriceAllAndProfiles) // Renamed command key slightly for consistency
+	// Fetches write to the database and call external sources, so they are admin only
+	cmds.register("fx:fetch_all", handlerFxFetchAll, withRole(auth.RoleAdmin))
+	cmds.register("fx:fetch:range", handlerFxFetchRange, withRole(auth.RoleAdmin))
+	cmds.register("fx:eer:compute", handlerFxEerCompute, withRole(auth.RoleAdmin))
+	cmds.register("macro:fetch", handlerMacroFetch, withRole(auth.RoleAdmin))
+	cmds.register("macro:activity:compute", handlerMacroActivityCompute, withRole(auth.RoleAdmin))
+	cmds.register("market:holidays", handlerMarketHolidays)
+	cmds.register("market:holidays:fetch", handlerMarketHolidaysFetch, withRole(auth.RoleAdmin))
+	cmds.register("entitlements", handlerEntitlements)
+	cmds.register("entitlements:fetch", handlerEntitlementsFetch, withRole(auth.RoleAdmin))
+	cmds.register("news", handlerNews)
+	cmds.register("news:fetch", handlerNewsFetch, withRole(auth.RoleAdmin))
+	cmds.register("news:sentiment:rescore", handlerNewsSentimentRescore, withRole(auth.RoleAdmin))
+	cmds.register("documents", handlerDocuments)
+	cmds.register("documents:fetch", handlerDocumentsFetch, withRole(auth.RoleAdmin))
+	cmds.register("search", handlerSearch)
+	cmds.register("lineage", handlerLineage)
+	cmds.register("diff", handlerDiff)
+	cmds.register("screener", handlerScreener)
+	cmds.register("tui", handlerTUI)
+	cmds.register("returns:compute", handlerReturnsCompute, withRole(auth.RoleAdmin))
+	cmds.register("volatility:compute", handlerVolatilityCompute, withRole(auth.RoleAdmin))
+	cmds.register("correlation:compute", handlerCorrelationCompute, withRole(auth.RoleAdmin))
+	cmds.register("baskets:compute", handlerBasketsCompute, withRole(auth.RoleAdmin))
+	cmds.register("snapshot:export", handlerSnapshotExport, withRole(auth.RoleAdmin))
+	cmds.register("digest:send", handlerDigestSend, withRole(auth.RoleAdmin))
+	cmds.register("publish:run", handlerPublishRun, withRole(auth.RoleAdmin))
+	cmds.register("sheets:push", handlerSheetsPush, withRole(auth.RoleAdmin))
+	cmds.register("stock:fetch:price", handlerStockFetchPrice, withRole(auth.RoleAdmin))
+	cmds.register("stock:fetch:price_all", handlerStockFetchPriceAll, withRole(auth.RoleAdmin)) // Renamed command key slightly for consistency
+	cmds.register("stock:fetch:profile", handlerStockFetchProfile, withRole(auth.RoleAdmin))
+	cmds.register("stock:fetch:profile_all", handlerStockFetchPriceAllAndProfiles, withRole(auth.RoleAdmin)) // Renamed command key slightly for consistency
+	cmds.register("stock:fetch:ratios", handlerStockFetchRatios, withRole(auth.RoleAdmin))
+	cmds.register("stock:ranges:refresh", handlerStockRangesRefresh, withRole(auth.RoleAdmin))
+	cmds.register("stock:intraday", handlerStockIntraday)
+	cmds.register("stock:intraday:poll", handlerStockIntradayPoll, withRole(auth.RoleAdmin))
+	cmds.register("stock:lifecycle", handlerStockLifecycle)
+	cmds.register("stock:lifecycle:check", handlerStockLifecycleCheck, withRole(auth.RoleAdmin))
+	cmds.register("stock:status", handlerStockStatus, withRole(auth.RoleAdmin))
+	cmds.register("data:check", handlerDataCheck, withRole(auth.RoleAdmin))
+	cmds.register("audit:report", handlerAuditReport, withRole(auth.RoleAdmin))
+	cmds.register("data:dedupe", handlerDataDedupe, withRole(auth.RoleAdmin))
+	cmds.register("repair", handlerRepair, withRole(auth.RoleAdmin))
+	cmds.register("db:maintenance", handlerDbMaintenance, withRole(auth.RoleAdmin))
+	cmds.register("stock:repair:dates", handlerStockRepairDates, withRole(auth.RoleAdmin))
 
 	// --- Input Loop ---
-	scanner := bufio.NewReader(os.Stdin) // Reader for standard input
-
 	for {
 		fmt.Print("Malaysian Econ DB > ") // Display prompt
 
@@ -91,7 +180,8 @@ func runCli(cancelFunc context.CancelFunc, wg *sync.WaitGroup, shutdownChan chan
 		}
 
 		// --- Execute the command using the PASSED-IN programState ---
-		err = cmds.run(programState, cmdToRun)
+		// A command running when shutdown begins gets SHUTDOWN_GRACE_PERIOD to finish
+		err = cmds.run(programState.workCtx, programState, cmdToRun)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err) // Print execution errors
 		}
@@ -103,23 +193,107 @@ func runCli(cancelFunc context.CancelFunc, wg *sync.WaitGroup, shutdownChan chan
 func handlerHelp(s *AppState, cmd command) error {
 	fmt.Println("Available commands:")
 	fmt.Println("  help                   - Show this help message")
-	fmt.Println("  login <user>           - Log in (stub)")
-	fmt.Println("  register <user>        - Register a new user (stub)")
-	fmt.Println("  reset                  - Reset database (stub)")
-	fmt.Println("  users                  - List users (stub)")
-	fmt.Println("  fx:fetch_all           - Fetch latest FX rates for all currencies")
-	fmt.Println("  fx:fetch:range <CUR> <START> <END> - Fetch FX rates for CUR between dates (YYYY-MM-DD)")
+	fmt.Println("  login <user> <password> - Log in")
+	fmt.Println("  register <user> <email> <password> - Register a new user and log in")
+	fmt.Println("  logout                 - End the current session")
+	fmt.Println("  whoami                 - Show the logged in user")
+	fmt.Println("  reset [--yes]          - Reset database, after confirming (stub, admin)")
+	fmt.Println("  users                  - List registered users (admin)")
+	fmt.Println("  users:role <user> <admin|editor|viewer> - Change a user's role (admin)")
+	fmt.Println("  users:delete <user> [--yes] - Delete a user, after confirming (admin)")
+	fmt.Println("  audit [limit] [--action=A] [--user=U] - Show recent audited actions (admin)")
+	fmt.Println("  apikey:create <name> <admin|editor|viewer> - Issue an API key (admin)")
+	fmt.Println("  apikey:list            - List API keys (admin)")
+	fmt.Println("  apikey:revoke <id> [--yes] - Revoke an API key, after confirming (admin)")
+	fmt.Println("  watchlist              - Show your watchlist")
+	fmt.Println("  watchlist:add <stock|fx> <code> - Add a stock code or currency to your watchlist (editor)")
+	fmt.Println("  watchlist:remove <stock|fx> <code> - Remove an item from your watchlist (editor)")
+	fmt.Println("  companies [COUNTRY]    - List the stored companies")
+	fmt.Println("  company:add <code> <COUNTRY> <name...> - Register a company without a scraped profile, e.g. listed abroad (admin)")
+	fmt.Println("  sectors                - List canonical sectors and subsectors with company counts, and unrecognised scraped labels")
+	fmt.Println("  sectors:add <sector> [/ <subsector>] - Add a canonical sector or subsector, e.g. sectors:add Financial Services / Banking (admin)")
+	fmt.Println("  sectors:merge [--subsector] <variant> = <canonical> - Normalize a scraped label, e.g. sectors:merge Finance = Financial Services (admin)")
+	fmt.Println("  sectors:normalize      - Re-apply the sector taxonomy to every company (admin)")
+	fmt.Println("  sectors:flows [--days=N] - Rank sectors by traded value (close times volume) over the last N days (default 7)")
+	fmt.Println("  sectors:flows:refresh [start_date] - Re-derive the daily sector flows from the stored closes (admin)")
+	fmt.Println("  tracked [COUNTRY]      - List the stock codes and currencies covered by batch fetches")
+	fmt.Println("  tracked:add <stock|fx> <code> [COUNTRY] - Add a stock code or currency to batch fetches (admin)")
+	fmt.Println("  tracked:remove <stock|fx> <code> - Remove a stock code or currency added with tracked:add (admin)")
+	fmt.Println("  portfolio              - Show your holdings with latest value and P&L")
+	fmt.Println("  portfolio:add <code> <qty> <cost> <YYYY-MM-DD> - Record a purchase lot (cost per share, MYR; editor)")
+	fmt.Println("  portfolio:remove <id>  - Remove a purchase lot (editor)")
+	fmt.Println("  alerts                 - Show your alert rules (incl. announcement rules) and recent triggered alerts")
+	fmt.Println("  alerts:add <series> [yoy|mom] <op> <threshold> - Add an alert, e.g. alerts:add fx:USD > 4.80 or alerts:add macro:cpi yoy > 3 (editor)")
+	fmt.Println("  alerts:remove <id>     - Remove an alert rule (editor)")
+	fmt.Println("  alerts:update <id> <series> [yoy|mom] <op> <threshold> - Change an alert rule and re-arm it (editor)")
+	fmt.Println("  alerts:export [file]   - Write your alert rules as YAML to a file (or the screen), for alerts:import")
+	fmt.Println("  alerts:import <file> [--replace] - Add the alert rules of an exported YAML file; --replace drops your other rules (editor)")
+	fmt.Println("  alerts:announcements:add <category> [CODE] - Alert on new results, dividend, corporate_action, merger (or any) announcements for a stock or your watchlist (editor)")
+	fmt.Println("  alerts:announcements:remove <id> - Remove an announcement alert rule (editor)")
+	fmt.Println("  alerts:evaluate        - Evaluate all alert rules now (admin; also runs after each fetch)")
+	fmt.Println("  annotations [START END] [--country=XX] - List chart annotations (default: the last 12 months)")
+	fmt.Println("  annotations:add [--country=XX] <date> <opr|budget|election|other> <title> [-- description] - Add a chart annotation (admin)")
+	fmt.Println("  annotations:delete <id> - Delete a chart annotation (admin)")
+	fmt.Println("  report:generate <code|currency> <range> [--out=FILE] - Write a PDF report (range: 30d, 6m, 1y, ytd, max or START:END)")
+	fmt.Println("  telegram:link          - Send your alerts to a Telegram chat (prints a code to send to the bot)")
+	fmt.Println("  telegram:unlink        - Stop sending your alerts to Telegram")
+	fmt.Println("  webhook:add <url> <events> [secret] - Subscribe a URL to data.stored, alert.triggered and/or fetch.failed (admin)")
+	fmt.Println("  webhook:list           - List webhooks (admin)")
+	fmt.Println("  webhook:remove <id>    - Remove a webhook (admin)")
+	fmt.Println("  ingest:register <name> <series> [secret] - Let a script push a series (stock:CODE, fx:CUR or macro:SERIES) to POST /api/ingest (admin)")
+	fmt.Println("  ingest:list            - List ingest sources (admin)")
+	fmt.Println("  ingest:remove <name>   - Remove an ingest source (admin)")
+	fmt.Println("  (fx:* and stock:fetch:* commands require the admin role)")
+	fmt.Println("  fx:fetch_all [--session=S] - Fetch latest FX rates for all currencies (S: 0900, 1200, 1700 or all)")
+	fmt.Println("  fx:fetch:range <CUR> <START> <END> [--missing-only] [--force] [--session=S] - Fetch FX rates for CUR between dates (YYYY-MM-DD), optionally only gaps; months done earlier today are skipped unless --force")
+	fmt.Println("  fx:eer:compute         - Recompute the trade-weighted effective exchange rate index (NEER)")
+	fmt.Println("  returns:compute [--full] - Update stored daily returns for all stocks and currencies (admin)")
+	fmt.Println("  volatility:compute [--full] - Update stored 20/60/250-day rolling volatility from daily returns (admin)")
+	fmt.Println("  correlation:compute    - Recompute the stored correlation matrix of CORRELATION_SERIES (admin)")
+	fmt.Println("  baskets:compute        - Recompute the composite index of every user basket (admin)")
+	fmt.Println("  digest:send [--days=N] - Email the market and watchlist digests now (admin)")
+	fmt.Println("  publish:run            - Write static JSON files of every series to PUBLISH_DIR / PUBLISH_BUCKET (admin)")
+	fmt.Println("  sheets:push            - Write GSHEETS_SERIES to the configured Google Sheet (admin)")
+	fmt.Println("  snapshot:export        - Upload a gzipped CSV snapshot of every table to SNAPSHOT_BUCKET (admin)")
+	fmt.Println("  macro:fetch [SERIES...] - Fetch macro series (cpi, ipi, exports) from OpenDOSM, all of them by default")
+	fmt.Println("  macro:activity:compute - Recompute the composite activity index (also runs when a component is stored)")
+	fmt.Println("  market:holidays [YEAR] - List the Bursa market holidays of a year")
+	fmt.Println("  market:holidays:fetch  - Refresh market holidays from the Bursa calendar at SOURCE_BURSA_HOLIDAYS_BASE_URL (admin)")
+	fmt.Println("  entitlements [CODE] [--days=N] - List dividends, bonus and rights issues going ex in the next N days (default 30)")
+	fmt.Println("  entitlements:fetch     - Refresh entitlements from the pages in ENTITLEMENTS_URLS (admin)")
+	fmt.Println("  news <stock_code> [LIMIT] - Show the latest stored headlines about a stock")
+	fmt.Println("  news:fetch             - Scan the NEWS_FEED_URLS feeds for headlines about tracked companies (admin)")
+	fmt.Println("  news:sentiment:rescore - Score the sentiment of every stored headline again (admin)")
+	fmt.Println("  documents <stock_code>  - List the stored annual and quarterly reports of a company")
+	fmt.Println("  documents:fetch <stock_code> <year> <annual|q1-q4> <url> [title] - Download and store a report PDF (admin)")
+	fmt.Println("  search [--country=XX] <terms...> - Search companies, news headlines, events and report documents")
+	fmt.Println("  lineage <series> [DATE] - Show the source, fetch run, snapshot and transformations of an observation")
+	fmt.Println("  diff [FROM] [TO]        - Rank tracked series by their change between two dates (--kind, --order, --top)")
+	fmt.Println("  screener [FILTER...]   - Screen stocks, e.g. screener \"sector=Financial Services\" pe<15 dy>=4 off_high>=20 off_low<10 (--sort, --desc, --top)")
+	fmt.Println("  tui [--interval=DURATION] - Open a live dashboard of FX rates, watchlist prices and fetch runs (q to close)")
 	fmt.Println("  stock:fetch:price <CODE> - Fetch latest price for stock CODE")
-	fmt.Println("  stock:fetch:price_all  - Fetch latest price for all stocks in config list") // Corrected command name
+	fmt.Println("  stock:fetch:price_all [--force] - Fetch latest price for all stocks in config list, skipping those fetched earlier today") // Corrected command name
+	fmt.Println("  stock:fetch:profile <CODE> - Fetch company profile for stock CODE")
+	fmt.Println("  stock:fetch:profile_all [--force] - Fetch prices and stale profiles for all stocks in config list")
+	fmt.Println("  stock:fetch:ratios [CODE...] - Fetch ROE, NTA, dividend yield, P/B and P/E for the given or all tracked stocks")
+	fmt.Println("  stock:ranges:refresh   - Recompute every stock's 52-week high and low from the stored closes (admin)")
+	fmt.Println("  stock:intraday <CODE> [YYYY-MM-DD] - List the prices polled for stock CODE during a day's sessions (default today)")
+	fmt.Println("  stock:intraday:poll    - Poll the current price of every tracked stock once, as INTRADAY_INTERVAL does in market hours (admin)")
+	fmt.Println("  stock:lifecycle        - List companies that are missing, delisted or renamed")
+	fmt.Println("  stock:lifecycle:check  - Check every tracked stock page and mark those gone for DELIST_AFTER_DAYS delisted (admin)")
+	fmt.Println("  stock:status <CODE> <active|delisted> [YYYY-MM-DD] - Set a company's status by hand; delisted codes are not fetched (admin)")
+	fmt.Println("  stock:repair:dates [--apply] - List (or fix) prices filed under the previous day by the old UTC dating (admin)")
+	fmt.Println("  data:check             - Run data quality checks (duplicate FX rates and stock prices) (admin)")
+	fmt.Println("  audit:report [--days=N] [--gap=N] [--z=X] [--stuck=N] [--top=N] - Rank gaps, outliers, stuck values and zeros across all series (admin)")
+	fmt.Println("  data:dedupe [--apply]  - List (or remove) duplicate FX rates and stock prices (admin)")
+	fmt.Println("  repair <fx:CUR|macro:SERIES> <START> <END> [--session=S] [--apply] - Delete a range of stored observations and re-fetch it from the source in one transaction (admin)")
+	fmt.Println("  db:maintenance         - ANALYZE, refresh monthly views, create audit partitions and enforce retention (admin)")
 	fmt.Println("  testing                - Simple test command")
 	fmt.Println("  exit / quit            - Stop the application")
 	return nil
 }
 
-// --- Stub Functions (handlerLogin, handlerRegister, etc.) ---
+// --- Stub Functions (handlerResetDatabase, etc.) ---
 // (No changes needed here as they receive the state 's')
-func handlerLogin(s *AppState, cmd command) error         { /* ... */ return nil }
-func handlerRegister(s *AppState, cmd command) error      { /* ... */ return nil }
 func handlerResetDatabase(s *AppState, cmd command) error { /* ... */ return nil }
-func handlerGetUsers(s *AppState, cmd command) error      { /* ... */ return nil }
 func handlerTesting(s *AppState, cmd command) error       { /* ... */ return nil }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/auth"
+)
+
+// confirmFlag skips a command's confirmation prompt; it is removed from the arguments before
+// the handler runs.
+const confirmFlag = "--yes"
+
+// withLogin runs a handler only for a logged in user. It checks that the current session
+// still exists and has not expired, and reloads the user so role changes take effect
+// immediately; the handler finds the user in cmd.user.
+func withLogin(next commandHandler) commandHandler {
+	return func(s *AppState, cmd command) error {
+		if s.currentUser == nil || s.sessionToken == "" {
+			return fmt.Errorf("%s requires a logged in user (use login or register)", cmd.Name)
+		}
+		_, err := s.db.GetUserSessionByTokenHash(cmd.Context(), auth.HashToken(s.sessionToken))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				s.currentUser = nil
+				s.sessionToken = ""
+				return fmt.Errorf("session expired, please log in again")
+			}
+			return fmt.Errorf("failed to validate session: %w", err)
+		}
+		user, err := s.db.GetUserByID(cmd.Context(), s.currentUser.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load user %s: %w", s.currentUser.Username, err)
+		}
+		s.currentUser = &user
+		cmd.user = user
+		return next(s, cmd)
+	}
+}
+
+// withRole runs a handler only for a logged in user (see withLogin) holding at least the
+// required role. Every role-gated command (data changes, deletions, role and key management,
+// manual fetches) is recorded in the audit log along with its outcome.
+func withRole(required string) commandMiddleware {
+	return func(next commandHandler) commandHandler {
+		return withLogin(func(s *AppState, cmd command) error {
+			if !auth.RoleAllows(cmd.user.Role, required) {
+				return fmt.Errorf("%s requires the %s role (you are %s)", cmd.Name, required, cmd.user.Role)
+			}
+			err := next(s, cmd)
+			// Recorded even when the command was cancelled by a shutdown or timeout
+			recordAudit(context.WithoutCancel(cmd.Context()), s, cmd.user, auditSourceCLI, cmd.Name, auditCommandDetails(cmd, err), "")
+			return err
+		})
+	}
+}
+
+// logCommandRuns logs every command with its arguments (secrets redacted as in the audit log),
+// who ran it, how long it took and whether it failed.
+func logCommandRuns(next commandHandler) commandHandler {
+	return func(s *AppState, cmd command) error {
+		start := time.Now()
+		err := next(s, cmd)
+		user := "anonymous"
+		if s.currentUser != nil {
+			user = s.currentUser.Username
+		}
+		log.Printf("CLI: %s by %s in %s: %s", cmd.Name, user, time.Since(start).Round(time.Millisecond), auditCommandDetails(cmd, err))
+		return err
+	}
+}
+
+// withConfirmation asks before running a command that cannot be undone, showing prompt and
+// reading y/N from the REPL's input. --yes answers for the user; without an interactive input
+// the command only runs with --yes.
+func (c *commands) withConfirmation(prompt string) commandMiddleware {
+	return func(next commandHandler) commandHandler {
+		return func(s *AppState, cmd command) error {
+			confirmed := slices.Contains(cmd.Args, confirmFlag)
+			if confirmed {
+				cmd.Args = slices.DeleteFunc(slices.Clone(cmd.Args), func(arg string) bool { return arg == confirmFlag })
+			} else {
+				if c.input == nil {
+					return fmt.Errorf("%s asks for confirmation; add %s to run it", cmd.Name, confirmFlag)
+				}
+				fmt.Printf("%s [y/N] ", prompt)
+				answer, err := c.input.ReadString('\n')
+				if err != nil {
+					return fmt.Errorf("failed to read confirmation: %w", err)
+				}
+				switch strings.ToLower(strings.TrimSpace(answer)) {
+				case "y", "yes":
+				default:
+					return fmt.Errorf("%s not confirmed; nothing was changed", cmd.Name)
+				}
+			}
+			return next(s, cmd)
+		}
+	}
+}
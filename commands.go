@@ -1,24 +1,84 @@
 package main
 
-import "errors"
+import (
+	"bufio"
+	"context"
+	"errors"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+)
 
 type command struct {
 	Name string
 	Args []string
+	ctx  context.Context // Cancelled on shutdown or when the command times out
+	user database.User   // The logged in user, set by withLogin and withRole
+}
+
+// Context returns the context the command runs under; handlers pass it to every database
+// call, scraper and API client so a shutdown or timeout stops in-flight work.
+func (c command) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
 }
 
+// subcommand returns a command that runs name with args under the same context as c.
+func (c command) subcommand(name string, args ...string) command {
+	return command{Name: name, Args: args, ctx: c.ctx, user: c.user}
+}
+
+// commandHandler runs a CLI command.
+type commandHandler func(*AppState, command) error
+
+// commandMiddleware wraps a handler with behavior shared across commands (login, roles,
+// logging, confirmation); it returns the handler to run in its place.
+type commandMiddleware func(next commandHandler) commandHandler
+
 type commands struct {
-	registeredCommands map[string]func(*AppState, command) error
+	registeredCommands map[string]commandHandler
+	middleware         []commandMiddleware // Wraps every command, outside its own middleware
+	input              *bufio.Reader       // The REPL's input, for prompts; nil when not interactive
 }
 
-func (c *commands) register(name string, f func(*AppState, command) error) {
-	c.registeredCommands[name] = f
+// use adds middleware run around every registered command, the first added outermost.
+func (c *commands) use(middleware ...commandMiddleware) {
+	c.middleware = append(c.middleware, middleware...)
 }
 
-func (c *commands) run(s *AppState, cmd command) error {
+// register adds a command, wrapped in its middleware with the first listed outermost, e.g.
+// register("reset", handlerResetDatabase, withRole(auth.RoleAdmin), c.withConfirmation("...")).
+func (c *commands) register(name string, f commandHandler, middleware ...commandMiddleware) {
+	c.registeredCommands[name] = chainMiddleware(f, middleware)
+}
+
+// chainMiddleware wraps f in middleware so that middleware[0] runs first.
+func chainMiddleware(f commandHandler, middleware []commandMiddleware) commandHandler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		f = middleware[i](f)
+	}
+	return f
+}
+
+// run executes cmd under ctx, limited to COMMAND_TIMEOUT, through the global middleware.
+func (c *commands) run(ctx context.Context, s *AppState, cmd command) error {
 	f, ok := c.registeredCommands[cmd.Name]
 	if !ok {
 		return errors.New("command not found")
 	}
-	return f(s, cmd)
+	f = chainMiddleware(f, c.middleware)
+	ctx, cancel := withCommandTimeout(ctx, s)
+	defer cancel()
+	cmd.ctx = ctx
+	return runRecovered(cmd.Name, func() error { return f(s, cmd) })
+}
+
+// withCommandTimeout derives the context a CLI command, triggered fetch or scheduled job
+// runs under, cancelled after COMMAND_TIMEOUT when one is configured.
+func withCommandTimeout(ctx context.Context, s *AppState) (context.Context, context.CancelFunc) {
+	if s.cfg.CommandTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.cfg.CommandTimeout)
 }
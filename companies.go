@@ -0,0 +1,82 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+)
+
+// --- Company Command Handlers ---
+
+// handlerCompanies lists the stored companies, optionally only those of one country.
+// Usage: companies [COUNTRY]
+func handlerCompanies(s *AppState, cmd command) error {
+	if len(cmd.Args) > 1 {
+		return fmt.Errorf("usage: %s [COUNTRY]", cmd.Name)
+	}
+	country := ""
+	if len(cmd.Args) == 1 {
+		var err error
+		if country, err = normalizeCountry(s, cmd.Args[0]); err != nil {
+			return err
+		}
+	}
+	companies, err := s.db.ListCompanies(cmd.Context(), sql.NullString{String: country, Valid: country != ""})
+	if err != nil {
+		return fmt.Errorf("failed to list companies: %w", err)
+	}
+	if len(companies) == 0 {
+		fmt.Println("No companies stored.")
+		return nil
+	}
+	for _, c := range companies {
+		status := ""
+		if c.Status != companyActive {
+			status = " [" + c.Status + "]"
+		}
+		fmt.Printf("  %-8s %-2s %-40s %s%s\n", c.StockCode, c.CountryCode.String, c.CompanyName, c.Sector.String, status)
+	}
+	return nil
+}
+
+// handlerCompanyAdd registers a company the profile scraper cannot reach, such as one listed
+// outside DEFAULT_COUNTRY, so that its prices can be pushed through /api/ingest (admin only).
+// Usage: company:add <stock_code> <COUNTRY> <name...>
+func handlerCompanyAdd(s *AppState, cmd command) error {
+	if len(cmd.Args) < 3 {
+		return fmt.Errorf("usage: %s <stock_code> <COUNTRY> <name...>", cmd.Name)
+	}
+	code := normalizeStockCode(cmd.Args[0])
+	if !stockCodePattern.MatchString(code) {
+		return fmt.Errorf("invalid stock code %q", cmd.Args[0])
+	}
+	country, err := normalizeCountry(s, cmd.Args[1])
+	if err != nil {
+		return err
+	}
+	if country == s.cfg.DefaultCountry && !bursaStockCodePattern.MatchString(code) {
+		return fmt.Errorf("invalid stock code %q (%s)", cmd.Args[0], bursaCodeHint)
+	}
+	name := strings.Join(cmd.Args[2:], " ")
+	if len(name) > 255 {
+		return fmt.Errorf("company name is longer than 255 characters")
+	}
+	n, err := s.db.CreateCompany(cmd.Context(), database.CreateCompanyParams{
+		StockCode:   code,
+		CompanyName: name,
+		CountryCode: sql.NullString{String: country, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add company %s: %w", code, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("company %s already exists", code)
+	}
+	invalidateResponseCache(s)
+	log.Printf("Registered company %s (%s, %s).", code, name, country)
+	fmt.Printf("Added company %s. Track it with: tracked:add stock %s %s\n", code, code, country)
+	return nil
+}
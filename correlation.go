@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// maxCorrelationSeries caps the size of a requested matrix.
+const maxCorrelationSeries = 50
+
+// correlationMatrix holds pairwise correlations of daily returns. Matrix[i][j] is nil when the
+// two series overlap too little to be compared.
+type correlationMatrix struct {
+	Series       []string
+	Window       int
+	AsOf         time.Time
+	Matrix       [][]*float64
+	Observations [][]int
+}
+
+func newCorrelationMatrix(series []string, window int, asOf time.Time) correlationMatrix {
+	m := correlationMatrix{Series: series, Window: window, AsOf: asOf}
+	m.Matrix = make([][]*float64, len(series))
+	m.Observations = make([][]int, len(series))
+	for i := range series {
+		m.Matrix[i] = make([]*float64, len(series))
+		m.Observations[i] = make([]int, len(series))
+	}
+	return m
+}
+
+// set records the correlation of series i and j in both halves of the matrix.
+func (m correlationMatrix) set(i, j int, corr float64, observations int) {
+	m.Matrix[i][j], m.Matrix[j][i] = &corr, &corr
+	m.Observations[i][j], m.Observations[j][i] = observations, observations
+}
+
+// computeCorrelationMatrix correlates the daily returns of the given series over the last
+// window trading days up to end.
+func computeCorrelationMatrix(ctx context.Context, s *AppState, series []string, window int, end time.Time) (correlationMatrix, error) {
+	// Twice the window in calendar days covers weekends and holidays
+	start := end.AddDate(0, 0, -2*window)
+	returns := make([][]analytics.Point, len(series))
+	for i, key := range series {
+		points, err := loadDailyReturns(ctx, s, key, start, end)
+		if err != nil {
+			return correlationMatrix{}, fmt.Errorf("%s: %w", key, err)
+		}
+		returns[i] = points
+	}
+
+	m := newCorrelationMatrix(series, window, end)
+	for i := range series {
+		if len(returns[i]) > 0 {
+			m.set(i, i, 1, min(len(returns[i]), window))
+		}
+		for j := i + 1; j < len(series); j++ {
+			if corr, n, ok := analytics.Correlation(returns[i], returns[j], window); ok {
+				m.set(i, j, corr, n)
+			}
+		}
+	}
+	return m, nil
+}
+
+// refreshStoredCorrelations recomputes the configured correlation matrix and replaces the
+// stored pairs for its window. It returns the number of pairs stored.
+func refreshStoredCorrelations(ctx context.Context, s *AppState) (int, error) {
+	series := s.cfg.CorrelationSeries
+	if len(series) == 0 {
+		var err error
+		if series, err = s.db.ListDailyReturnSeries(ctx); err != nil {
+			return 0, fmt.Errorf("failed to list series with returns: %w", err)
+		}
+	}
+	series = append([]string(nil), series...)
+	sort.Strings(series) // Pairs are stored with series_a < series_b
+	series = slices.Compact(series)
+
+	m, err := computeCorrelationMatrix(ctx, s, series, s.cfg.CorrelationWindow, markettime.Today())
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+	if err := qtx.DeleteReturnCorrelationsByWindow(ctx, int32(m.Window)); err != nil {
+		return 0, fmt.Errorf("failed to clear stored correlations: %w", err)
+	}
+	stored := 0
+	for i := range series {
+		for j := i + 1; j < len(series); j++ {
+			if m.Matrix[i][j] == nil {
+				continue
+			}
+			err := qtx.CreateReturnCorrelation(ctx, database.CreateReturnCorrelationParams{
+				SeriesA:      series[i],
+				SeriesB:      series[j],
+				WindowDays:   int32(m.Window),
+				AsOf:         m.AsOf,
+				Correlation:  fmt.Sprintf("%.8f", *m.Matrix[i][j]),
+				Observations: int32(m.Observations[i][j]),
+			})
+			if err != nil {
+				return 0, fmt.Errorf("failed to store correlation of %s and %s: %w", series[i], series[j], err)
+			}
+			stored++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit correlations: %w", err)
+	}
+	invalidateResponseCache(s)
+	return stored, nil
+}
+
+// storedCorrelationMatrix rebuilds the matrix last stored by refreshStoredCorrelations.
+func storedCorrelationMatrix(ctx context.Context, s *AppState, window int) (correlationMatrix, error) {
+	rows, err := s.db.ListReturnCorrelationsByWindow(ctx, int32(window))
+	if err != nil {
+		return correlationMatrix{}, fmt.Errorf("failed to load stored correlations: %w", err)
+	}
+	index := map[string]int{}
+	var series []string
+	for _, row := range rows {
+		for _, key := range []string{row.SeriesA, row.SeriesB} {
+			if _, ok := index[key]; !ok {
+				index[key] = len(series)
+				series = append(series, key)
+			}
+		}
+	}
+	sort.Strings(series)
+	for i, key := range series {
+		index[key] = i
+	}
+
+	var asOf time.Time
+	if len(rows) > 0 {
+		asOf = rows[0].AsOf
+	}
+	m := newCorrelationMatrix(series, window, asOf)
+	for i := range series {
+		m.set(i, i, 1, 0)
+	}
+	for _, row := range rows {
+		corr, err := strconv.ParseFloat(row.Correlation, 64)
+		if err != nil {
+			return correlationMatrix{}, fmt.Errorf("invalid stored correlation for %s/%s: %w", row.SeriesA, row.SeriesB, err)
+		}
+		m.set(index[row.SeriesA], index[row.SeriesB], corr, int(row.Observations))
+	}
+	return m, nil
+}
+
+// handlerCorrelationCompute recomputes the stored correlation matrix on demand.
+// Usage: correlation:compute
+func handlerCorrelationCompute(s *AppState, cmd command) error {
+	n, err := refreshStoredCorrelations(cmd.Context(), s)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Stored %d correlation pairs over %d trading days.\n", n, s.cfg.CorrelationWindow)
+	return nil
+}
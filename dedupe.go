@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+)
+
+// duplicateReport lists the rows that would be removed to leave one row per stock and date
+// and per currency, date and session. Codes are compared ignoring case and surrounding spaces,
+// which the unique constraints do not.
+type duplicateReport struct {
+	FX          []database.ListForeignExchangeDuplicatesRow // All rows in duplicate groups, the one kept first
+	Stocks      []database.ListStockPriceDuplicatesRow      // Likewise
+	FXGroups    int
+	StockGroups int
+	FXExtra     int // Rows to delete
+	StocksExtra int
+	FXRenamed   int // Kept rows whose currency code is not canonical
+	fxKeep      map[int]bool
+	stockKeep   map[int]bool
+}
+
+// findDuplicates runs the duplicate quality check.
+func findDuplicates(ctx context.Context, db *database.Queries) (duplicateReport, error) {
+	var r duplicateReport
+	var err error
+	if r.FX, err = db.ListForeignExchangeDuplicates(ctx); err != nil {
+		return r, fmt.Errorf("failed to look for duplicate FX rates: %w", err)
+	}
+	if r.Stocks, err = db.ListStockPriceDuplicates(ctx); err != nil {
+		return r, fmt.Errorf("failed to look for duplicate stock prices: %w", err)
+	}
+
+	r.fxKeep = make(map[int]bool)
+	var lastKey string
+	for i, row := range r.FX {
+		key := fmt.Sprintf("%s|%s|%s", canonicalCode(row.CurrencyCode), row.Date.Format("2006-01-02"), row.Session)
+		if key == lastKey {
+			r.FXExtra++
+			continue
+		}
+		lastKey = key
+		r.FXGroups++
+		r.fxKeep[i] = true
+		if row.CurrencyCode != canonicalCode(row.CurrencyCode) {
+			r.FXRenamed++
+		}
+	}
+	r.stockKeep = make(map[int]bool)
+	lastKey = ""
+	for i, row := range r.Stocks {
+		key := canonicalCode(row.StockCode) + "|" + row.PriceDate.Format("2006-01-02")
+		if key == lastKey {
+			r.StocksExtra++
+			continue
+		}
+		lastKey = key
+		r.StockGroups++
+		r.stockKeep[i] = true
+	}
+	return r, nil
+}
+
+func canonicalCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// resolveDuplicates deletes every duplicate but the kept row of each group in one transaction
+// and gives kept FX rows the canonical currency code. Stock codes are left as they are, since
+// they reference companies.
+func resolveDuplicates(ctx context.Context, s *AppState, r duplicateReport) error {
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	// Deletes first, so a renamed row cannot collide with a row about to go
+	for i, row := range r.FX {
+		if r.fxKeep[i] {
+			continue
+		}
+		if err := qtx.DeleteForeignExchange(ctx, row.ID); err != nil {
+			return fmt.Errorf("failed to delete FX rate %s: %w", row.ID, err)
+		}
+	}
+	for i, row := range r.FX {
+		if r.fxKeep[i] && row.CurrencyCode != canonicalCode(row.CurrencyCode) {
+			err := qtx.SetForeignExchangeCurrencyCode(ctx, database.SetForeignExchangeCurrencyCodeParams{
+				CurrencyCode: canonicalCode(row.CurrencyCode),
+				ID:           row.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to rename FX rate %s: %w", row.ID, err)
+			}
+		}
+	}
+	for i, row := range r.Stocks {
+		if r.stockKeep[i] {
+			continue
+		}
+		if err := qtx.DeleteStockPrice(ctx, row.ID); err != nil {
+			return fmt.Errorf("failed to delete stock price %d: %w", row.ID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if r.StocksExtra > 0 {
+		refreshStockRanges(ctx, s, "")
+	}
+	invalidateResponseCache(s)
+	return nil
+}
+
+// handlerDataCheck runs the data quality checks and fails when any finds a problem, so it can
+// be run (or triggered through the admin API) for monitoring.
+// Usage: data:check
+func handlerDataCheck(s *AppState, cmd command) error {
+	r, err := findDuplicates(cmd.Context(), s.db)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Duplicate FX rates:     %d group(s), %d extra row(s)\n", r.FXGroups, r.FXExtra)
+	fmt.Printf("Duplicate stock prices: %d group(s), %d extra row(s)\n", r.StockGroups, r.StocksExtra)
+	if r.FXGroups > 0 || r.StockGroups > 0 {
+		return fmt.Errorf("found %d duplicate FX and %d duplicate stock price group(s); run data:dedupe", r.FXGroups, r.StockGroups)
+	}
+	return nil
+}
+
+// handlerDataDedupe reports duplicate FX rates and stock prices and, with --apply, removes
+// them, keeping BNM rates over third-party ones and otherwise the most recently fetched row
+// (admin only).
+// Usage: data:dedupe [--apply]
+func handlerDataDedupe(s *AppState, cmd command) error {
+	apply := false
+	for _, arg := range cmd.Args {
+		if arg != "--apply" {
+			return fmt.Errorf("usage: %s [--apply]", cmd.Name)
+		}
+		apply = true
+	}
+	ctx := cmd.Context()
+	r, err := findDuplicates(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	if r.FXGroups == 0 && r.StockGroups == 0 {
+		fmt.Println("No duplicate FX rates or stock prices found.")
+		return nil
+	}
+
+	for i, row := range r.FX {
+		action := "delete"
+		if r.fxKeep[i] {
+			action = "keep"
+			if row.CurrencyCode != canonicalCode(row.CurrencyCode) {
+				action = "keep as " + canonicalCode(row.CurrencyCode)
+			}
+		}
+		fmt.Printf("  fx    %-6q %s %s %-12s %10s  %s\n", row.CurrencyCode, row.Date.Format("2006-01-02"), row.Session, row.Source, row.MiddleRate, action)
+	}
+	for i, row := range r.Stocks {
+		action := "delete"
+		if r.stockKeep[i] {
+			action = "keep"
+		}
+		fmt.Printf("  stock %-8q %s %10s  extracted %s  %s\n", row.StockCode, row.PriceDate.Format("2006-01-02"), row.ClosingPrice, row.ExtractedAt.Format("2006-01-02 15:04"), action)
+	}
+	fmt.Printf("%d duplicate FX group(s) (%d extra row(s)), %d duplicate stock price group(s) (%d extra row(s)).\n",
+		r.FXGroups, r.FXExtra, r.StockGroups, r.StocksExtra)
+	if !apply {
+		fmt.Printf("Run %s --apply to resolve them.\n", cmd.Name)
+		return nil
+	}
+
+	if err := resolveDuplicates(ctx, s, r); err != nil {
+		return err
+	}
+	log.Printf("Deduplicated data: %d FX rate(s) and %d stock price(s) deleted, %d currency code(s) normalized.", r.FXExtra, r.StocksExtra, r.FXRenamed)
+	fmt.Printf("Deleted %d FX rate(s) and %d stock price(s); normalized %d currency code(s).\n", r.FXExtra, r.StocksExtra, r.FXRenamed)
+	// Returns and everything derived from them may have used a deleted value
+	if _, err := computeDailyReturns(ctx, s, true); err != nil {
+		return fmt.Errorf("failed to recompute daily returns: %w", err)
+	}
+	if _, err := computeRollingVolatility(ctx, s, true); err != nil {
+		return fmt.Errorf("failed to recompute rolling volatility: %w", err)
+	}
+	return nil
+}
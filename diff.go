@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// Diff orders: largest moves in either direction, biggest rises or biggest falls first.
+const (
+	diffOrderMovers  = "movers"
+	diffOrderGainers = "gainers"
+	diffOrderLosers  = "losers"
+)
+
+// Structure for the change of one series between two dates
+type SeriesDiff struct {
+	Rank          int     `json:"rank"`
+	Series        string  `json:"series"`    // stock:<code> or fx:<currency>
+	FromDate      string  `json:"from_date"` // Date of the observation in effect on the start date
+	FromValue     float64 `json:"from_value"`
+	ToDate        string  `json:"to_date"`
+	ToValue       float64 `json:"to_value"`
+	Change        float64 `json:"change"`
+	ChangePercent float64 `json:"change_percent"`
+}
+
+// Structure for the changes of all tracked series between two dates
+type DiffResponse struct {
+	From   string       `json:"from"`
+	To     string       `json:"to"`
+	Order  string       `json:"order"`
+	Series []SeriesDiff `json:"series"`
+}
+
+// diffSeries compares the values in effect on from and to of every tracked stock of country
+// (all countries when empty; kind "stock"), every stored currency (kind "fx") or both (kind
+// ""), ranked by order. Currencies are not tied to a country, so country leaves them be. Series
+// without an observation on or before from, or with a zero starting value, are left out.
+// Each kind takes one query per date, however many series there are.
+func diffSeries(ctx context.Context, s *AppState, from, to time.Time, kind, country, order string) ([]SeriesDiff, error) {
+	var keys []seriesKey
+	starts := make(map[seriesKey]analytics.Point)
+	ends := make(map[seriesKey]analytics.Point)
+	if kind == "" || kind == watchlistStock {
+		codes := trackedStocks(ctx, s, country)
+		for i, date := range []time.Time{from, to} {
+			rows, err := s.db.ListStockClosesOnOrBefore(ctx, database.ListStockClosesOnOrBeforeParams{StockCodes: codes, OnDate: date})
+			if err != nil {
+				return nil, fmt.Errorf("failed to load stock closes on %s: %w", date.Format("2006-01-02"), err)
+			}
+			for _, row := range rows {
+				v, err := strconv.ParseFloat(row.ClosingPrice, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid closing price '%s' for %s: %w", row.ClosingPrice, row.StockCode, err)
+				}
+				key := seriesKey{Kind: watchlistStock, Code: row.StockCode}
+				if i == 0 {
+					starts[key] = analytics.Point{Date: row.PriceDate, Value: v}
+				} else {
+					ends[key] = analytics.Point{Date: row.PriceDate, Value: v}
+				}
+			}
+		}
+		for _, code := range codes {
+			keys = append(keys, seriesKey{Kind: watchlistStock, Code: code})
+		}
+	}
+	if kind == "" || kind == watchlistFx {
+		var currencies []string
+		for i, date := range []time.Time{from, to} {
+			rows, err := s.db.ListForeignExchangeRatesOnOrBefore(ctx, database.ListForeignExchangeRatesOnOrBeforeParams{OnDate: date, Session: "1200"})
+			if err != nil {
+				return nil, fmt.Errorf("failed to load rates on %s: %w", date.Format("2006-01-02"), err)
+			}
+			for _, row := range rows {
+				v, err := strconv.ParseFloat(row.MiddleRatePerUnit, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid rate '%s' for %s: %w", row.MiddleRatePerUnit, row.CurrencyCode, err)
+				}
+				key := seriesKey{Kind: watchlistFx, Code: row.CurrencyCode}
+				if i == 0 {
+					starts[key] = analytics.Point{Date: row.Date, Value: v}
+					currencies = append(currencies, row.CurrencyCode)
+				} else {
+					ends[key] = analytics.Point{Date: row.Date, Value: v}
+				}
+			}
+		}
+		for _, code := range currencies {
+			keys = append(keys, seriesKey{Kind: watchlistFx, Code: code})
+		}
+	}
+
+	diffs := []SeriesDiff{}
+	for _, key := range keys {
+		start, ok := starts[key]
+		if !ok || start.Value == 0 {
+			continue
+		}
+		end := ends[key] // Present whenever start is: to is not before from
+		diffs = append(diffs, SeriesDiff{
+			Series:        key.String(),
+			FromDate:      start.Date.Format("2006-01-02"),
+			FromValue:     start.Value,
+			ToDate:        end.Date.Format("2006-01-02"),
+			ToValue:       end.Value,
+			Change:        end.Value - start.Value,
+			ChangePercent: (end.Value/start.Value - 1) * 100,
+		})
+	}
+
+	sort.SliceStable(diffs, func(i, j int) bool {
+		switch order {
+		case diffOrderGainers:
+			return diffs[i].ChangePercent > diffs[j].ChangePercent
+		case diffOrderLosers:
+			return diffs[i].ChangePercent < diffs[j].ChangePercent
+		}
+		return math.Abs(diffs[i].ChangePercent) > math.Abs(diffs[j].ChangePercent)
+	})
+	for i := range diffs {
+		diffs[i].Rank = i + 1
+	}
+	return diffs, nil
+}
+
+// --- Diff Command Handlers ---
+
+// handlerDiff prints what moved between two dates across all tracked series.
+// Usage: diff [from YYYY-MM-DD] [to YYYY-MM-DD] [--kind=stock|fx] [--order=movers|gainers|losers] [--top=N]
+// (default: the last 7 days, all series, top 20 movers)
+func handlerDiff(s *AppState, cmd command) error {
+	usage := fmt.Errorf("usage: %s [from YYYY-MM-DD] [to YYYY-MM-DD] [--kind=stock|fx] [--order=movers|gainers|losers] [--top=N]", cmd.Name)
+	to := markettime.Today()
+	from := to.AddDate(0, 0, -7)
+	kind, order, top := "", diffOrderMovers, 20
+	var dates []time.Time
+	for _, arg := range cmd.Args {
+		switch {
+		case strings.HasPrefix(arg, "--kind="):
+			kind = strings.TrimPrefix(arg, "--kind=")
+			if kind != watchlistStock && kind != watchlistFx {
+				return usage
+			}
+		case strings.HasPrefix(arg, "--order="):
+			order = strings.TrimPrefix(arg, "--order=")
+			if order != diffOrderMovers && order != diffOrderGainers && order != diffOrderLosers {
+				return usage
+			}
+		case strings.HasPrefix(arg, "--top="):
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "--top="))
+			if err != nil || n < 1 {
+				return usage
+			}
+			top = n
+		default:
+			date, err := markettime.ParseDate(arg)
+			if err != nil || len(dates) == 2 {
+				return usage
+			}
+			dates = append(dates, date)
+		}
+	}
+	if len(dates) > 0 {
+		from = dates[0]
+	}
+	if len(dates) > 1 {
+		to = dates[1]
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("start date %s must be before end date %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+
+	diffs, err := diffSeries(cmd.Context(), s, from, to, kind, "", order)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Changes from %s to %s (%d series):\n", from.Format("2006-01-02"), to.Format("2006-01-02"), len(diffs))
+	for _, d := range diffs {
+		if d.Rank > top {
+			break
+		}
+		fmt.Printf("  %3d. %-12s %12.4f -> %12.4f  %+10.4f  %+7.2f%%\n", d.Rank, d.Series, d.FromValue, d.ToValue, d.Change, d.ChangePercent)
+	}
+	return nil
+}
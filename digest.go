@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/notify"
+)
+
+// digestMove is the change of one series over the digest period.
+type digestMove struct {
+	Series string
+	From   float64
+	To     float64
+	Change float64 // Percent
+	Date   time.Time
+}
+
+// digestRelease is a macro value published or revised during the digest period.
+type digestRelease struct {
+	Series      string
+	Description string
+	Period      time.Time
+	Value       float64
+}
+
+// digestReport is the content of one digest email.
+type digestReport struct {
+	Title  string
+	Since  time.Time
+	Until  time.Time
+	FX     []digestMove
+	Stocks []digestMove // Top movers only
+	Macro  []digestRelease
+}
+
+func (r digestReport) empty() bool {
+	return len(r.FX) == 0 && len(r.Stocks) == 0 && len(r.Macro) == 0
+}
+
+// buildDigest collects the moves of the given currencies and stocks over [since, until] and
+// the macro releases fetched in that time.
+func buildDigest(ctx context.Context, s *AppState, title string, currencies, stocks []string, since, until time.Time) (digestReport, error) {
+	report := digestReport{Title: title, Since: since, Until: until}
+	var err error
+	if report.FX, err = seriesMoves(ctx, s, watchlistFx, currencies, since, until); err != nil {
+		return digestReport{}, err
+	}
+	if report.Stocks, err = seriesMoves(ctx, s, watchlistStock, stocks, since, until); err != nil {
+		return digestReport{}, err
+	}
+	if len(report.Stocks) > s.cfg.DigestTopMovers {
+		report.Stocks = report.Stocks[:s.cfg.DigestTopMovers]
+	}
+
+	rows, err := s.db.ListMacroObservationsFetchedSince(ctx, since)
+	if err != nil {
+		return digestReport{}, fmt.Errorf("failed to load macro releases: %w", err)
+	}
+	for _, row := range rows {
+		v, convErr := strconv.ParseFloat(row.Value, 64)
+		if convErr != nil {
+			continue
+		}
+		report.Macro = append(report.Macro, digestRelease{
+			Series:      row.Series,
+			Description: macroSeries[row.Series].Description,
+			Period:      row.Period,
+			Value:       v,
+		})
+	}
+	return report, nil
+}
+
+// seriesMoves returns the change of each series from its value at since to its latest value
+// up to until, largest moves (either direction) first. Series without a value inside the
+// period are left out.
+func seriesMoves(ctx context.Context, s *AppState, kind string, codes []string, since, until time.Time) ([]digestMove, error) {
+	var moves []digestMove
+	for _, code := range codes {
+		key := seriesKey{Kind: kind, Code: code}
+		points, err := loadSeries(ctx, s, key, since, until)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", key, err)
+		}
+		if len(points) < 2 || points[0].Value == 0 {
+			continue
+		}
+		first, last := points[0], points[len(points)-1]
+		moves = append(moves, digestMove{
+			Series: code,
+			From:   first.Value,
+			To:     last.Value,
+			Change: (last.Value/first.Value - 1) * 100,
+			Date:   last.Date,
+		})
+	}
+	sort.SliceStable(moves, func(i, j int) bool {
+		return math.Abs(moves[i].Change) > math.Abs(moves[j].Change)
+	})
+	return moves, nil
+}
+
+var digestTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"date":   func(t time.Time) string { return t.Format("2006-01-02") },
+	"month":  func(t time.Time) string { return t.Format("2006-01") },
+	"num":    func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) },
+	"change": func(v float64) string { return fmt.Sprintf("%+.2f%%", v) },
+	"color": func(v float64) string {
+		if v < 0 {
+			return "#b00020"
+		}
+		return "#00796b"
+	},
+}).Parse(`<!DOCTYPE html>
+<html><body style="font-family: sans-serif">
+<h2>{{.Title}}</h2>
+<p>{{date .Since}} to {{date .Until}}</p>
+{{if .FX}}<h3>FX (MYR per unit)</h3>
+<table cellpadding="4">
+<tr><th align="left">Currency</th><th align="right">From</th><th align="right">To</th><th align="right">Change</th></tr>
+{{range .FX}}<tr><td>{{.Series}}</td><td align="right">{{num .From}}</td><td align="right">{{num .To}}</td><td align="right" style="color: {{color .Change}}">{{change .Change}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Stocks}}<h3>Top stock movers</h3>
+<table cellpadding="4">
+<tr><th align="left">Stock</th><th align="right">From</th><th align="right">To</th><th align="right">Change</th></tr>
+{{range .Stocks}}<tr><td>{{.Series}}</td><td align="right">{{num .From}}</td><td align="right">{{num .To}}</td><td align="right" style="color: {{color .Change}}">{{change .Change}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Macro}}<h3>New macro releases</h3>
+<ul>
+{{range .Macro}}<li>{{.Series}} {{month .Period}}: {{num .Value}}{{if .Description}} ({{.Description}}){{end}}</li>
+{{end}}</ul>{{end}}
+{{if not (or .FX .Stocks .Macro)}}<p>No changes in this period.</p>{{end}}
+</body></html>
+`))
+
+// digestMessage renders a report as a plain-text email with an HTML alternative.
+func digestMessage(r digestReport) (notify.Message, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s, %s to %s\n", r.Title, r.Since.Format("2006-01-02"), r.Until.Format("2006-01-02"))
+	if len(r.FX) > 0 {
+		b.WriteString("\nFX (MYR per unit):\n")
+		for _, m := range r.FX {
+			fmt.Fprintf(&b, "  %-6s %12.4f -> %12.4f  %+.2f%%\n", m.Series, m.From, m.To, m.Change)
+		}
+	}
+	if len(r.Stocks) > 0 {
+		b.WriteString("\nTop stock movers:\n")
+		for _, m := range r.Stocks {
+			fmt.Fprintf(&b, "  %-6s %12.4f -> %12.4f  %+.2f%%\n", m.Series, m.From, m.To, m.Change)
+		}
+	}
+	if len(r.Macro) > 0 {
+		b.WriteString("\nNew macro releases:\n")
+		for _, m := range r.Macro {
+			fmt.Fprintf(&b, "  %s %s: %.4f\n", m.Series, m.Period.Format("2006-01"), m.Value)
+		}
+	}
+	if r.empty() {
+		b.WriteString("\nNo changes in this period.\n")
+	}
+
+	var html bytes.Buffer
+	if err := digestTemplate.Execute(&html, r); err != nil {
+		return notify.Message{}, fmt.Errorf("failed to render digest: %w", err)
+	}
+	subject := fmt.Sprintf("%s: %s to %s", r.Title, r.Since.Format("2 Jan"), r.Until.Format("2 Jan 2006"))
+	return notify.Message{Subject: subject, Body: b.String(), HTML: html.String()}, nil
+}
+
+// sendDigests emails the market digest for [since, until] to DIGEST_EMAIL_TO and, when
+// DIGEST_WATCHLIST_USERS is set, a digest of their own watchlist to every user who has one.
+// It returns the number of emails sent.
+func sendDigests(ctx context.Context, s *AppState, since, until time.Time) (int, error) {
+	if s.email == nil {
+		return 0, fmt.Errorf("email is not configured (SMTP_HOST is not set)")
+	}
+	sent, failed := 0, 0
+
+	if len(s.cfg.DigestEmailTo) > 0 {
+		currencies, err := s.db.ListForeignExchangeCurrencies(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list currencies: %w", err)
+		}
+		report, err := buildDigest(ctx, s, "Market digest", currencies, trackedStocks(ctx, s, ""), since, until)
+		if err != nil {
+			return 0, err
+		}
+		msg, err := digestMessage(report)
+		if err != nil {
+			return 0, err
+		}
+		if err := s.email.Send(s.cfg.DigestEmailTo, msg); err != nil {
+			log.Printf("Error emailing market digest: %v", err)
+			failed++
+		} else {
+			sent++
+		}
+	}
+
+	if s.cfg.DigestWatchlistUsers {
+		users, err := s.db.ListUsers(ctx)
+		if err != nil {
+			return sent, fmt.Errorf("failed to list users: %w", err)
+		}
+		for _, user := range users {
+			items, err := s.db.ListWatchlistItemsByUser(ctx, user.ID)
+			if err != nil {
+				log.Printf("Error loading watchlist of %s for digest: %v", user.Username, err)
+				failed++
+				continue
+			}
+			if len(items) == 0 {
+				continue
+			}
+			var currencies, stocks []string
+			for _, item := range items {
+				if item.ItemType == watchlistFx {
+					currencies = append(currencies, item.Code)
+				} else {
+					stocks = append(stocks, item.Code)
+				}
+			}
+			report, err := buildDigest(ctx, s, "Watchlist digest", currencies, stocks, since, until)
+			if err != nil {
+				log.Printf("Error building digest for %s: %v", user.Username, err)
+				failed++
+				continue
+			}
+			if report.empty() {
+				continue
+			}
+			msg, err := digestMessage(report)
+			if err == nil {
+				err = s.email.Send([]string{user.Email}, msg)
+			}
+			if err != nil {
+				log.Printf("Error emailing digest to %s: %v", user.Username, err)
+				failed++
+				continue
+			}
+			sent++
+		}
+	}
+
+	if failed > 0 {
+		return sent, fmt.Errorf("failed to send %d digest(s)", failed)
+	}
+	return sent, nil
+}
+
+// digestInterval is the digest interval, or 0 when there is nobody to send digests to.
+func digestInterval(s *AppState) time.Duration {
+	if s.email == nil || (len(s.cfg.DigestEmailTo) == 0 && !s.cfg.DigestWatchlistUsers) {
+		return 0
+	}
+	return s.cfg.DigestInterval
+}
+
+// handlerDigestSend emails the digests now.
+// Usage: digest:send [--days=N]  (default: the DIGEST_INTERVAL period)
+func handlerDigestSend(s *AppState, cmd command) error {
+	until := time.Now().UTC()
+	since := until.Add(-s.cfg.DigestInterval)
+	for _, arg := range cmd.Args {
+		value, ok := strings.CutPrefix(arg, "--days=")
+		days, err := strconv.Atoi(value)
+		if !ok || err != nil || days < 1 {
+			return fmt.Errorf("usage: digest:send [--days=N]")
+		}
+		since = until.AddDate(0, 0, -days)
+	}
+	if !since.Before(until) {
+		return fmt.Errorf("DIGEST_INTERVAL is 0; pass --days=N")
+	}
+	n, err := sendDigests(cmd.Context(), s, since, until)
+	fmt.Printf("Sent %d digest email(s).\n", n)
+	return err
+}
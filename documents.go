@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/objectstore"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/scraper"
+)
+
+// documentPeriods are the report periods a company document can cover.
+var documentPeriods = []string{"annual", "q1", "q2", "q3", "q4"}
+
+// documentStore holds report files by storage key.
+type documentStore interface {
+	put(ctx context.Context, key string, data []byte, contentType string) error
+	open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// dirDocumentStore keeps files under a local directory.
+type dirDocumentStore struct {
+	dir string
+}
+
+func (d dirDocumentStore) path(key string) string {
+	return filepath.Join(d.dir, filepath.FromSlash(key))
+}
+
+func (d dirDocumentStore) put(ctx context.Context, key string, data []byte, contentType string) error {
+	path := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (d dirDocumentStore) open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(d.path(key))
+}
+
+// bucketDocumentStore keeps files in object storage.
+type bucketDocumentStore struct {
+	store *objectstore.Store
+}
+
+func (b bucketDocumentStore) put(ctx context.Context, key string, data []byte, contentType string) error {
+	return b.store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType)
+}
+
+func (b bucketDocumentStore) open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return b.store.Get(ctx, key)
+}
+
+// documentStorage returns DOCUMENTS_BUCKET when it is set, otherwise DOCUMENTS_DIR.
+func documentStorage(s *AppState) (documentStore, error) {
+	if s.cfg.DocumentsBucket == "" {
+		return dirDocumentStore{dir: s.cfg.DocumentsDir}, nil
+	}
+	store, err := objectstore.New(objectstore.Options{
+		Endpoint:  s.cfg.SnapshotEndpoint,
+		Bucket:    s.cfg.DocumentsBucket,
+		Prefix:    s.cfg.DocumentsPrefix,
+		AccessKey: s.cfg.SnapshotAccessKey,
+		SecretKey: s.cfg.SnapshotSecretKey,
+		Region:    s.cfg.SnapshotRegion,
+		UseSSL:    s.cfg.SnapshotUseSSL,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bucketDocumentStore{store: store}, nil
+}
+
+// downloadDocument fetches a report PDF of at most maxBytes.
+func downloadDocument(ctx context.Context, source *scraper.Client, url string, maxBytes int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := source.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download of %s failed with status code: %d %s", url, resp.StatusCode, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("error downloading %s: %w", url, err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("%s is larger than DOCUMENT_MAX_MB (%d MB)", url, maxBytes>>20)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, fmt.Errorf("%s is not a PDF (got %s)", url, http.DetectContentType(data))
+	}
+	return data, nil
+}
+
+// fetchCompanyDocument downloads a report, stores the file under
+// <stock_code>/<year>-<period>-<checksum>.pdf and records its metadata, replacing an
+// earlier download of the same company and period.
+func fetchCompanyDocument(ctx context.Context, s *AppState, stockCode string, year int, period, title, url string) (database.CompanyDocument, error) {
+	if _, err := s.db.GetCompanyByStockCode(ctx, stockCode); err != nil {
+		if err == sql.ErrNoRows {
+			return database.CompanyDocument{}, fmt.Errorf("unknown stock code %s (fetch its profile first)", stockCode)
+		}
+		return database.CompanyDocument{}, fmt.Errorf("failed to look up %s: %w", stockCode, err)
+	}
+	store, err := documentStorage(s)
+	if err != nil {
+		return database.CompanyDocument{}, err
+	}
+	data, err := downloadDocument(ctx, s.sources.Source(config.SourceDocuments), url, int64(s.cfg.DocumentMaxMB)<<20)
+	if err != nil {
+		return database.CompanyDocument{}, err
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	key := fmt.Sprintf("%s/%d-%s-%s.pdf", stockCode, year, period, checksum[:12])
+	if err := store.put(ctx, key, data, "application/pdf"); err != nil {
+		return database.CompanyDocument{}, fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	doc, err := s.db.UpsertCompanyDocument(ctx, database.UpsertCompanyDocumentParams{
+		StockCode:   stockCode,
+		FiscalYear:  int32(year),
+		Period:      period,
+		Title:       title,
+		SourceUrl:   url,
+		StorageKey:  key,
+		Sha256:      checksum,
+		SizeBytes:   int64(len(data)),
+		ContentType: "application/pdf",
+	})
+	if err != nil {
+		return database.CompanyDocument{}, fmt.Errorf("failed to record document: %w", err)
+	}
+	invalidateResponseCache(s)
+	return doc, nil
+}
+
+// --- Document Command Handlers ---
+
+// handlerDocuments lists the stored report documents of a company.
+// Usage: documents <stock_code>
+func handlerDocuments(s *AppState, cmd command) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <stock_code>", cmd.Name)
+	}
+	code, err := lookupStockCode(cmd.Context(), s, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	docs, err := s.db.ListCompanyDocumentsByStockCode(cmd.Context(), code)
+	if err != nil {
+		return fmt.Errorf("failed to list documents: %w", err)
+	}
+	if len(docs) == 0 {
+		fmt.Printf("No documents stored for %s.\n", code)
+		return nil
+	}
+	for _, d := range docs {
+		fmt.Printf("  %4d %d %-6s %8.1f KB  %s\n", d.ID, d.FiscalYear, d.Period, float64(d.SizeBytes)/1024, d.Title)
+	}
+	return nil
+}
+
+// handlerDocumentsFetch downloads a company's annual or quarterly report PDF and stores it
+// (admin only).
+// Usage: documents:fetch <stock_code> <year> <annual|q1|q2|q3|q4> <url> [title...]
+func handlerDocumentsFetch(s *AppState, cmd command) error {
+	if len(cmd.Args) < 4 {
+		return fmt.Errorf("usage: %s <stock_code> <year> <%s> <url> [title...]", cmd.Name, strings.Join(documentPeriods, "|"))
+	}
+	code, err := lookupStockCode(cmd.Context(), s, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	year, err := strconv.Atoi(cmd.Args[1])
+	if err != nil || year < 1900 || year > 2200 {
+		return fmt.Errorf("invalid year %q", cmd.Args[1])
+	}
+	period := strings.ToLower(cmd.Args[2])
+	if !slices.Contains(documentPeriods, period) {
+		return fmt.Errorf("invalid period %q (use %s)", cmd.Args[2], strings.Join(documentPeriods, ", "))
+	}
+	url := cmd.Args[3]
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return fmt.Errorf("invalid url %q (use an http(s) URL)", url)
+	}
+	title := strings.Join(cmd.Args[4:], " ")
+	if len(title) > 255 {
+		return fmt.Errorf("title is longer than 255 characters")
+	}
+
+	doc, err := fetchCompanyDocument(cmd.Context(), s, code, year, period, title, url)
+	if err != nil {
+		return err
+	}
+	log.Printf("Stored %s %d %s report (%d bytes, sha256 %s) as %s.", code, year, period, doc.SizeBytes, doc.Sha256, doc.StorageKey)
+	fmt.Printf("Stored document %d (%s).\n", doc.ID, doc.StorageKey)
+	return nil
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/scraper"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// entitlementTypes are the values of entitlements.entitlement_type.
+var entitlementTypes = []string{"dividend", "bonus", "rights", "split", "other"}
+
+var (
+	// entitlementCodePattern matches a Bursa stock code (4 digits, optionally with a suffix such
+	// as PA or WB) in a cell or in the link to the stock's page.
+	entitlementCodePattern = regexp.MustCompile(`\b(\d{4}[A-Z0-9]{0,2})\b`)
+	// entitlementAmountPattern matches the first number in an amount cell such as "RM 0.0520" or "5.2 sen".
+	entitlementAmountPattern = regexp.MustCompile(`\d+(?:\.\d+)?`)
+)
+
+// scrapedEntitlement is one entitlement row read from an entitlements page.
+type scrapedEntitlement struct {
+	StockCode   string
+	Type        string
+	ExDate      time.Time
+	PaymentDate sql.NullTime
+	Description string
+	Amount      sql.NullString
+}
+
+// entitlementType classifies an entitlement by its description, falling back to the page
+// URL (e.g. .../entitlement/dividend/...) when the description does not say.
+func entitlementType(description, url string) string {
+	for _, text := range []string{strings.ToLower(description), strings.ToLower(url)} {
+		switch {
+		case strings.Contains(text, "dividend"), strings.Contains(text, "distribution"):
+			return "dividend"
+		case strings.Contains(text, "bonus"):
+			return "bonus"
+		case strings.Contains(text, "rights"):
+			return "rights"
+		case strings.Contains(text, "split"), strings.Contains(text, "subdivision"), strings.Contains(text, "consolidation"):
+			return "split"
+		}
+	}
+	return "other"
+}
+
+// entitlementColumns maps the header cells of an entitlements table to column indexes
+// (-1 when absent).
+type entitlementColumns struct {
+	Code, ExDate, PaymentDate, Description, Amount int
+}
+
+func parseEntitlementHeader(cells []string) (entitlementColumns, bool) {
+	cols := entitlementColumns{-1, -1, -1, -1, -1}
+	for i, cell := range cells {
+		h := strings.ToLower(cell)
+		switch {
+		case strings.Contains(h, "ex") && strings.Contains(h, "date"):
+			cols.ExDate = i
+		case strings.Contains(h, "pay") && strings.Contains(h, "date"):
+			cols.PaymentDate = i
+		case cols.Code < 0 && (strings.Contains(h, "code") || strings.Contains(h, "stock") || strings.Contains(h, "name")):
+			cols.Code = i
+		case strings.Contains(h, "amount") || strings.Contains(h, "rate"):
+			cols.Amount = i
+		case strings.Contains(h, "entitlement") || strings.Contains(h, "subject") || strings.Contains(h, "description") || strings.Contains(h, "particular") || strings.Contains(h, "type"):
+			cols.Description = i
+		}
+	}
+	return cols, cols.Code >= 0 && cols.ExDate >= 0
+}
+
+// scrapeEntitlements reads every table on the page at url that has a stock and an ex-date
+// column. Rows without a recognizable stock code or ex-date are skipped.
+func scrapeEntitlements(ctx context.Context, source *scraper.Client, url string) ([]scrapedEntitlement, error) {
+	resp, err := source.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML from %s: %w", url, err)
+	}
+
+	var entitlements []scrapedEntitlement
+	doc.Find("table").Each(func(i int, table *goquery.Selection) {
+		var headers []string
+		table.Find("tr").First().Find("th, td").Each(func(j int, cell *goquery.Selection) {
+			headers = append(headers, strings.Join(strings.Fields(cell.Text()), " "))
+		})
+		cols, ok := parseEntitlementHeader(headers)
+		if !ok {
+			return
+		}
+		table.Find("tr").Each(func(j int, row *goquery.Selection) {
+			cells := row.Find("td")
+			if cells.Length() <= cols.ExDate || cells.Length() <= cols.Code {
+				return
+			}
+			text := func(col int) string {
+				if col < 0 || col >= cells.Length() {
+					return ""
+				}
+				return strings.Join(strings.Fields(cells.Eq(col).Text()), " ")
+			}
+			codeCell := cells.Eq(cols.Code)
+			m := entitlementCodePattern.FindStringSubmatch(strings.ToUpper(codeCell.Find("a").AttrOr("href", "")))
+			if m == nil {
+				m = entitlementCodePattern.FindStringSubmatch(strings.ToUpper(text(cols.Code)))
+			}
+			exDate, err := parseProfileDate(text(cols.ExDate))
+			if m == nil || err != nil {
+				return
+			}
+			e := scrapedEntitlement{StockCode: m[1], ExDate: exDate, Description: text(cols.Description)}
+			if pay, err := parseProfileDate(text(cols.PaymentDate)); err == nil {
+				e.PaymentDate = sql.NullTime{Time: pay, Valid: true}
+			}
+			e.Type = entitlementType(e.Description, url)
+			if raw := text(cols.Amount); e.Type == "dividend" && raw != "" {
+				if num := entitlementAmountPattern.FindString(strings.ReplaceAll(raw, ",", "")); num != "" {
+					if v, err := strconv.ParseFloat(num, 64); err == nil {
+						if strings.Contains(strings.ToLower(raw), "sen") {
+							v /= 100
+						}
+						e.Amount = sql.NullString{String: fmt.Sprintf("%.6f", v), Valid: true}
+					}
+				}
+			}
+			entitlements = append(entitlements, e)
+		})
+	})
+	if len(entitlements) == 0 {
+		return nil, fmt.Errorf("no entitlements found on %s (has the page layout changed?)", url)
+	}
+	return entitlements, nil
+}
+
+// fetchEntitlements scrapes every page in ENTITLEMENTS_URLS and stores the entitlements found.
+// A page that fails does not stop the others; their errors are joined. It returns the number
+// stored. Entitlements seen for the first time are evaluated against the announcement alerts.
+func fetchEntitlements(ctx context.Context, s *AppState) (int, error) {
+	if len(s.cfg.EntitlementsURLs) == 0 {
+		return 0, fmt.Errorf("ENTITLEMENTS_URLS is not set")
+	}
+	stored := 0
+	var errs []error
+	var announced []announcement
+	defer func() { runAnnouncementAlerts(ctx, s, announced) }()
+	for _, url := range s.cfg.EntitlementsURLs {
+		entitlements, err := scrapeEntitlements(ctx, s.sources.Source(config.SourceEntitlements), url)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, e := range entitlements {
+			inserted, err := s.db.UpsertEntitlement(ctx, database.UpsertEntitlementParams{
+				StockCode:       e.StockCode,
+				EntitlementType: e.Type,
+				ExDate:          e.ExDate,
+				PaymentDate:     e.PaymentDate,
+				Description:     e.Description,
+				Amount:          e.Amount,
+				SourceUrl:       url,
+			})
+			if err != nil {
+				return stored, fmt.Errorf("failed to store %s %s entitlement going ex on %s: %w", e.StockCode, e.Type, e.ExDate.Format("2006-01-02"), err)
+			}
+			if inserted {
+				announced = append(announced, entitlementAnnouncement(e, url))
+			}
+			stored++
+		}
+	}
+	if stored > 0 {
+		invalidateResponseCache(s)
+	}
+	return stored, errors.Join(errs...)
+}
+
+// entitlementsInterval is the entitlements fetch interval, or 0 when no pages are configured.
+func entitlementsInterval(s *AppState) time.Duration {
+	if len(s.cfg.EntitlementsURLs) == 0 {
+		return 0
+	}
+	return s.cfg.EntitlementsInterval
+}
+
+// --- Entitlement Command Handlers ---
+
+// handlerEntitlements lists the stored entitlements going ex in the next days, optionally for
+// one stock.
+// Usage: entitlements [CODE] [--days=N]  (default: 30 days, every stock)
+func handlerEntitlements(s *AppState, cmd command) error {
+	usage := fmt.Errorf("usage: %s [CODE] [--days=N]", cmd.Name)
+	days := 30
+	var code sql.NullString
+	for _, arg := range cmd.Args {
+		if value, ok := strings.CutPrefix(arg, "--days="); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 3660 {
+				return usage
+			}
+			days = n
+			continue
+		}
+		c := normalizeStockCode(arg)
+		if !stockCodePattern.MatchString(c) || code.Valid {
+			return usage
+		}
+		code = sql.NullString{String: c, Valid: true}
+	}
+	start := markettime.Today()
+	rows, err := s.db.ListEntitlementsBetween(cmd.Context(), database.ListEntitlementsBetweenParams{
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, days),
+		StockCode: code,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list entitlements: %w", err)
+	}
+	if len(rows) == 0 {
+		fmt.Printf("No entitlements going ex in the next %d days.\n", days)
+		return nil
+	}
+	for _, row := range rows {
+		amount := ""
+		if row.Amount.Valid {
+			amount = "RM " + ratioText(row.Amount)
+		}
+		fmt.Printf("  %s  %-8s %-9s %-12s %s\n", row.ExDate.Format("2006-01-02"), row.StockCode, row.EntitlementType, amount, row.Description)
+	}
+	return nil
+}
+
+// handlerEntitlementsFetch refreshes the entitlements from ENTITLEMENTS_URLS (admin only).
+// Usage: entitlements:fetch
+func handlerEntitlementsFetch(s *AppState, cmd command) error {
+	n, err := fetchEntitlements(cmd.Context(), s)
+	notifyDataStored(s, cmd.Name, n)
+	fmt.Printf("Stored %d entitlement(s).\n", n)
+	return err
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// Payload of an observation event, published to <EVENT_TOPIC_PREFIX>.<kind>
+type observationEvent struct {
+	Series   string    `json:"series"` // Series key, e.g. fx:USD
+	Kind     string    `json:"kind"`   // stock, fx or macro
+	Code     string    `json:"code"`
+	Date     string    `json:"date"` // YYYY-MM-DD; first day of the period for macro series
+	Value    float64   `json:"value"`
+	Source   string    `json:"source"`
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// publishObservation emits an event for a stored observation when an event bus is configured.
+// Values use the same units as the API: closing price, MYR per one unit of the currency, or
+// the macro series value. Failures are logged and never fail the store.
+func publishObservation(s *AppState, kind, code string, date time.Time, value float64, source string) {
+	if s.events == nil {
+		return
+	}
+	series := kind + ":" + code
+	payload, err := json.Marshal(observationEvent{
+		Series:   series,
+		Kind:     kind,
+		Code:     code,
+		Date:     date.Format("2006-01-02"),
+		Value:    value,
+		Source:   source,
+		StoredAt: time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("Event bus: failed to encode event for %s: %v", series, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.events.Publish(ctx, s.cfg.EventTopicPrefix+"."+kind, series, payload); err != nil {
+		log.Printf("Event bus: failed to publish %s %s: %v", series, date.Format("2006-01-02"), err)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/google/uuid"
+)
+
+// maxFetchRunErrorSamples caps how many errors are stored with a fetch run.
+const maxFetchRunErrorSamples = 10
+
+// fetchCheckpointRetentionDays is how many days completed batch items are kept.
+const fetchCheckpointRetentionDays = 7
+
+// Fetch run statuses stored in fetch_runs.status.
+const (
+	fetchRunSucceeded = "succeeded"
+	fetchRunPartial   = "partial"
+	fetchRunFailed    = "failed"
+)
+
+// fetchStats counts the outcome of a batch fetch.
+type fetchStats struct {
+	SuccessfulFetches int
+	FailedFetches     int
+	SuccessfulStores  int
+	FailedStores      int
+	Errors            []string
+}
+
+// add accumulates other into st.
+func (st *fetchStats) add(other fetchStats) {
+	st.SuccessfulFetches += other.SuccessfulFetches
+	st.FailedFetches += other.FailedFetches
+	st.SuccessfulStores += other.SuccessfulStores
+	st.FailedStores += other.FailedStores
+	st.Errors = append(st.Errors, other.Errors...)
+}
+
+// fetchRunContextKey carries the ID of the batch run a command's stores belong to.
+const fetchRunContextKey contextKey = "fetch_run"
+
+// fetchRun is a batch run recorded in fetch_runs. A zero ID means the start could not be
+// recorded, in which case finish is a no-op.
+type fetchRun struct {
+	ID      uuid.UUID
+	Command string
+	ctx     context.Context
+}
+
+// startFetchRun records that cmd has started. Database errors are logged rather than
+// failing the fetch.
+func startFetchRun(s *AppState, cmd command) fetchRun {
+	run, err := s.db.CreateFetchRun(cmd.Context(), database.CreateFetchRunParams{
+		ID:        uuid.New(),
+		Command:   cmd.Name,
+		Args:      strings.Join(cmd.Args, " "),
+		StartedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("Error recording start of %s run: %v", cmd.Name, err)
+		return fetchRun{Command: cmd.Name}
+	}
+	return fetchRun{ID: run.ID, Command: run.Command, ctx: context.WithValue(cmd.Context(), fetchRunContextKey, run.ID)}
+}
+
+// attach returns cmd running under the run's context, so the values it stores record the run
+// as their lineage.
+func (r fetchRun) attach(cmd command) command {
+	if r.ctx != nil {
+		cmd.ctx = r.ctx
+	}
+	return cmd
+}
+
+// fetchRunFromContext returns the batch run ctx was attached to, if any.
+func fetchRunFromContext(ctx context.Context) uuid.NullUUID {
+	id, ok := ctx.Value(fetchRunContextKey).(uuid.UUID)
+	return uuid.NullUUID{UUID: id, Valid: ok}
+}
+
+// finish stores the run's counters and status. runErr is the error that aborted the run, if any.
+// It is recorded even when the command's context was cancelled.
+func (r fetchRun) finish(s *AppState, stats fetchStats, runErr error) {
+	if r.ID == uuid.Nil {
+		return
+	}
+	status := fetchRunSucceeded
+	switch {
+	case runErr != nil:
+		status = fetchRunFailed
+	case stats.FailedFetches > 0 || stats.FailedStores > 0:
+		status = fetchRunPartial
+		if stats.SuccessfulFetches == 0 && stats.SuccessfulStores == 0 {
+			status = fetchRunFailed
+		}
+	}
+
+	samples := stats.Errors
+	if runErr != nil {
+		samples = append([]string{runErr.Error()}, samples...)
+	}
+	if len(samples) > maxFetchRunErrorSamples {
+		samples = samples[:maxFetchRunErrorSamples]
+	}
+	if samples == nil {
+		samples = []string{} // error_samples is NOT NULL
+	}
+
+	err := s.db.FinishFetchRun(context.WithoutCancel(r.ctx), database.FinishFetchRunParams{
+		ID:                r.ID,
+		Status:            status,
+		FinishedAt:        sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		SuccessfulFetches: int32(stats.SuccessfulFetches),
+		FailedFetches:     int32(stats.FailedFetches),
+		SuccessfulStores:  int32(stats.SuccessfulStores),
+		FailedStores:      int32(stats.FailedStores),
+		ErrorSamples:      samples,
+	})
+	if err != nil {
+		log.Printf("Error recording end of %s run %s: %v", r.Command, r.ID, err)
+	}
+}
+
+// fetchCheckpoints tracks the items of a batch command completed on today's market date, so
+// re-running the batch after a crash skips them. Database errors are logged; the batch then
+// simply fetches those items again.
+type fetchCheckpoints struct {
+	command string
+	date    time.Time
+	done    map[string]bool
+}
+
+// loadFetchCheckpoints returns the checkpoints of command for today. With force nothing counts
+// as completed, though completions are still recorded.
+func loadFetchCheckpoints(ctx context.Context, s *AppState, command string, force bool) *fetchCheckpoints {
+	c := &fetchCheckpoints{command: command, date: markettime.Today(), done: make(map[string]bool)}
+	if _, err := s.db.DeleteFetchCheckpointsBefore(ctx, c.date.AddDate(0, 0, -fetchCheckpointRetentionDays)); err != nil {
+		log.Printf("Error pruning fetch checkpoints: %v", err)
+	}
+	if force {
+		return c
+	}
+	items, err := s.db.ListFetchCheckpoints(ctx, database.ListFetchCheckpointsParams{Command: command, RunDate: c.date})
+	if err != nil {
+		log.Printf("Error loading %s checkpoints: %v", command, err)
+		return c
+	}
+	for _, item := range items {
+		c.done[item] = true
+	}
+	if len(items) > 0 {
+		log.Printf("%s: %d item(s) already completed today will be skipped (use --force to refetch).", command, len(items))
+	}
+	return c
+}
+
+// completed reports whether item was completed earlier today.
+func (c *fetchCheckpoints) completed(item string) bool {
+	return c.done[item]
+}
+
+// complete records that item has been fetched and stored, even when ctx has just been
+// cancelled by shutdown.
+func (c *fetchCheckpoints) complete(ctx context.Context, s *AppState, item string) {
+	err := s.db.CreateFetchCheckpoint(context.WithoutCancel(ctx), database.CreateFetchCheckpointParams{
+		Command: c.command,
+		RunDate: c.date,
+		Item:    item,
+	})
+	if err != nil {
+		log.Printf("Error recording %s checkpoint %q: %v", c.command, item, err)
+		return
+	}
+	c.done[item] = true
+}
+
+// sharedFetch runs fetch unless one with the same key is already in flight, in which case it
+// waits for that one and returns its result. An admin-triggered refresh overlapping a batch
+// that is fetching the same stock then makes one request to the source and one store. The
+// shared run uses the context of the caller that started it.
+func sharedFetch(s *AppState, key string, fetch func() error) error {
+	_, err, shared := s.fetches.Do(key, func() (interface{}, error) {
+		return nil, fetch()
+	})
+	if shared {
+		log.Printf("Fetch %s was triggered more than once while running; the callers shared one run.", key)
+	}
+	return err
+}
+
+// fetchKey identifies a fetch command and its arguments for sharedFetch.
+func fetchKey(cmd command) string {
+	return strings.ToUpper(strings.Join(append([]string{cmd.Name}, cmd.Args...), " "))
+}
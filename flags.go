@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
+)
+
+// cliFlags are command line options. Except for --profile, which chooses the .env files to
+// load, each one overrides the corresponding environment/.env setting when given.
+type cliFlags struct {
+	Profile    string
+	DBURL      string
+	ServerAddr string
+	CertFile   string
+	KeyFile    string
+	FXProvider string
+	StockList  string
+	LogFile    string
+	NoServer   bool
+}
+
+// parseFlags parses os.Args into cliFlags.
+func parseFlags() cliFlags {
+	var f cliFlags
+	flag.StringVar(&f.Profile, "profile", "", "configuration profile to load (dev, staging or prod); reads .env.<profile>")
+	flag.StringVar(&f.DBURL, "db-url", "", "database connection URL (overrides DB_URL)")
+	flag.StringVar(&f.ServerAddr, "server-addr", "", "HTTPS listen address, e.g. :8443 (overrides SERVER_ADDR)")
+	flag.StringVar(&f.CertFile, "cert-file", "", "TLS certificate file (overrides CERT_FILE)")
+	flag.StringVar(&f.KeyFile, "key-file", "", "TLS key file (overrides KEY_FILE)")
+	flag.StringVar(&f.FXProvider, "fx-provider", "", "FX provider: bnm, frankfurter or exchangeratehost (overrides FX_PROVIDER)")
+	flag.StringVar(&f.StockList, "stock-list", "", "comma-separated stock codes (overrides STOCK_LIST)")
+	flag.StringVar(&f.LogFile, "log-file", "", "also write logs to this rotating file (overrides LOG_FILE)")
+	flag.BoolVar(&f.NoServer, "no-server", false, "run without the HTTPS server (CLI, scheduler and bot only)")
+	flag.Parse()
+	return f
+}
+
+// apply overrides cfg with the flags that were given.
+func (f cliFlags) apply(cfg *config.Config) {
+	if f.DBURL != "" {
+		cfg.DBURL = f.DBURL
+	}
+	if f.ServerAddr != "" {
+		cfg.ServerAddr = f.ServerAddr
+	}
+	if f.CertFile != "" {
+		cfg.CertFile = f.CertFile
+	}
+	if f.KeyFile != "" {
+		cfg.KeyFile = f.KeyFile
+	}
+	if f.FXProvider != "" {
+		cfg.FXProvider = f.FXProvider
+	}
+	if f.StockList != "" {
+		cfg.StockList = nil
+		for _, code := range strings.Split(f.StockList, ",") {
+			if code = strings.TrimSpace(code); code != "" {
+				cfg.StockList = append(cfg.StockList, code)
+			}
+		}
+	}
+	if f.LogFile != "" {
+		cfg.LogFile = f.LogFile
+	}
+	if f.NoServer {
+		cfg.ServerDisabled = true
+	}
+}
@@ -0,0 +1,234 @@
+// --- DOM Elements ---
+const messageDiv = document.getElementById('message');
+const loginSection = document.getElementById('loginSection');
+const loginForm = document.getElementById('loginForm');
+const usernameInput = document.getElementById('username');
+const passwordInput = document.getElementById('password');
+const sessionInfoDiv = document.getElementById('sessionInfo');
+const sessionUserSpan = document.getElementById('sessionUser');
+const logoutButton = document.getElementById('logoutButton');
+const adminContentDiv = document.getElementById('adminContent');
+const trackedForm = document.getElementById('trackedForm');
+const trackedTypeSelect = document.getElementById('trackedType');
+const trackedCodeInput = document.getElementById('trackedCode');
+const trackedCountryInput = document.getElementById('trackedCountry');
+const trackedTableBody = document.getElementById('trackedTable');
+const runsStatusSelect = document.getElementById('runsStatus');
+const refreshRunsButton = document.getElementById('refreshRunsButton');
+const runsTableBody = document.getElementById('runsTable');
+
+// The JWT from /api/auth/login is kept for the browser tab only
+const tokenStorageKey = 'econdb.adminToken';
+const runsLimit = 100;
+
+// --- API Helpers ---
+function showMessage(text, isError = false) {
+    messageDiv.textContent = text;
+    messageDiv.style.color = isError ? '#c0392b' : '#1e8449';
+}
+
+// apiRequest calls an admin endpoint with the stored token. A 401 signs the user out.
+async function apiRequest(method, path, body) {
+    const headers = { 'Authorization': `Bearer ${sessionStorage.getItem(tokenStorageKey)}` };
+    const options = { method, headers };
+    if (body !== undefined) {
+        headers['Content-Type'] = 'application/json';
+        options.body = JSON.stringify(body);
+    }
+    const response = await fetch(path, options);
+    if (response.status === 401) {
+        signOut();
+        throw new Error('Session expired, please sign in again.');
+    }
+    if (!response.ok) {
+        const text = (await response.text()).trim();
+        let message = text;
+        try {
+            message = JSON.parse(text).error.message; // Error envelope
+        } catch (e) { /* Plain-text error */ }
+        throw new Error(message || response.statusText);
+    }
+    return response.status === 204 ? null : response.json();
+}
+
+// --- Session ---
+function showSignedIn(user) {
+    sessionUserSpan.textContent = `${user.username} (${user.role})`;
+    sessionInfoDiv.classList.remove('hidden');
+    loginSection.classList.add('hidden');
+    adminContentDiv.classList.remove('hidden');
+    loadTracked();
+    loadRuns();
+}
+
+function signOut() {
+    sessionStorage.removeItem(tokenStorageKey);
+    sessionInfoDiv.classList.add('hidden');
+    adminContentDiv.classList.add('hidden');
+    loginSection.classList.remove('hidden');
+}
+
+loginForm.addEventListener('submit', async (event) => {
+    event.preventDefault();
+    try {
+        const response = await fetch('api/auth/login', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ username: usernameInput.value, password: passwordInput.value }),
+        });
+        if (!response.ok) {
+            throw new Error('Invalid username or password.');
+        }
+        const login = await response.json();
+        if (login.user.role !== 'admin') {
+            throw new Error('This page requires the admin role.');
+        }
+        sessionStorage.setItem(tokenStorageKey, login.token);
+        passwordInput.value = '';
+        showMessage('');
+        showSignedIn(login.user);
+    } catch (err) {
+        showMessage(err.message, true);
+    }
+});
+
+logoutButton.addEventListener('click', () => {
+    signOut();
+    showMessage('Signed out.');
+});
+
+// --- Tracked Instruments ---
+async function loadTracked() {
+    try {
+        const items = await apiRequest('GET', 'api/admin/tracked');
+        trackedTableBody.replaceChildren(...items.map(trackedRow));
+    } catch (err) {
+        showMessage(`Failed to load tracked instruments: ${err.message}`, true);
+    }
+}
+
+function trackedRow(item) {
+    const row = document.createElement('tr');
+    const source = item.source === 'config' ? 'STOCK_LIST' : 'added';
+    const addedBy = item.added_by ? `${item.added_by} on ${item.added_at.split('T')[0]}` : '';
+    for (const text of [item.type, item.code, item.country, source, addedBy]) {
+        const cell = document.createElement('td');
+        cell.textContent = text;
+        row.appendChild(cell);
+    }
+    const actionCell = document.createElement('td');
+    if (item.source !== 'config') {
+        const removeButton = document.createElement('button');
+        removeButton.className = 'secondary';
+        removeButton.textContent = 'Remove';
+        removeButton.addEventListener('click', () => removeTracked(item));
+        actionCell.appendChild(removeButton);
+    }
+    row.appendChild(actionCell);
+    return row;
+}
+
+async function removeTracked(item) {
+    if (!confirm(`Stop fetching ${item.type} ${item.code}? Stored data is kept.`)) {
+        return;
+    }
+    try {
+        const query = new URLSearchParams({ type: item.type, code: item.code });
+        await apiRequest('DELETE', `api/admin/tracked?${query}`);
+        showMessage(`Stopped tracking ${item.type} ${item.code}.`);
+        loadTracked();
+    } catch (err) {
+        showMessage(`Failed to remove ${item.code}: ${err.message}`, true);
+    }
+}
+
+trackedForm.addEventListener('submit', async (event) => {
+    event.preventDefault();
+    const type = trackedTypeSelect.value;
+    const code = trackedCodeInput.value.trim().toUpperCase();
+    const country = trackedCountryInput.value.trim().toUpperCase();
+    try {
+        await apiRequest('POST', 'api/admin/tracked', { type, code, country });
+        trackedCodeInput.value = '';
+        trackedCountryInput.value = '';
+        showMessage(`Now tracking ${type} ${code}. It is fetched with the next batch run.`);
+        loadTracked();
+    } catch (err) {
+        showMessage(`Failed to add ${code}: ${err.message}`, true);
+    }
+});
+
+// --- Fetch History ---
+async function loadRuns() {
+    try {
+        let runs = await apiRequest('GET', `api/admin/runs?limit=${runsLimit}`);
+        if (runsStatusSelect.value === 'failed') {
+            runs = runs.filter(run => run.status === 'failed' || run.status === 'partial');
+        }
+        runsTableBody.replaceChildren(...runs.map(runRow));
+    } catch (err) {
+        showMessage(`Failed to load fetch history: ${err.message}`, true);
+    }
+}
+
+function runRow(run) {
+    const row = document.createElement('tr');
+    const cells = [
+        new Date(run.started_at).toLocaleString(),
+        `${run.command} ${run.args || ''}`.trim(),
+        run.status,
+        `${run.successful_fetches}/${run.failed_fetches}`,
+        `${run.successful_stores}/${run.failed_stores}`,
+        (run.error_samples || []).join('\n'),
+    ];
+    cells.forEach((text, i) => {
+        const cell = document.createElement('td');
+        cell.textContent = text;
+        if (i === 2) {
+            cell.className = `status-${run.status}`;
+        }
+        if (i === 5) {
+            cell.style.whiteSpace = 'pre-wrap';
+        }
+        row.appendChild(cell);
+    });
+    const actionCell = document.createElement('td');
+    if (run.status === 'failed' || run.status === 'partial') {
+        const retryButton = document.createElement('button');
+        retryButton.textContent = 'Retry';
+        retryButton.addEventListener('click', () => retryRun(run));
+        actionCell.appendChild(retryButton);
+    }
+    row.appendChild(actionCell);
+    return row;
+}
+
+async function retryRun(run) {
+    try {
+        await apiRequest('POST', 'api/admin/runs/retry', { id: run.id });
+        showMessage(`Started ${run.command} again. Refresh the history to see its outcome.`);
+    } catch (err) {
+        showMessage(`Failed to retry ${run.command}: ${err.message}`, true);
+    }
+}
+
+runsStatusSelect.addEventListener('change', loadRuns);
+refreshRunsButton.addEventListener('click', loadRuns);
+
+// --- Initial Load ---
+// Resume a session from an earlier page load in this tab
+(async () => {
+    if (!sessionStorage.getItem(tokenStorageKey)) {
+        return;
+    }
+    try {
+        const user = await apiRequest('GET', 'api/auth/me');
+        if (user.role !== 'admin') {
+            signOut();
+            return;
+        }
+        showSignedIn(user);
+    } catch (err) {
+        showMessage(err.message, true);
+    }
+})();
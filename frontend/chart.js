@@ -155,11 +155,14 @@ function getNextPriceScaleId() {
 }
 
 const apiEndpoints = {
-    stock: (code) => `/api/stock/prices?code=${code}`,
-    fx: (code) => `/api/fx/rates?code=${code}`,
-    // loan: (code) => `/api/loans/sector?sector_id=${code}`, // Example for future
+    stock: (code) => `api/stock/prices?code=${code}`,
+    fx: (code) => `api/fx/rates?code=${code}`,
+    // loan: (code) => `api/loans/sector?sector_id=${code}`, // Example for future
 };
 
+// Series are downsampled server-side (LTTB) to about this many points, whatever the date range
+const chartPoints = 500;
+
 // --- Helper to format date as "MMM-DD-YY" ---
 function formatTooltipDate(timestamp) {
     const date = new Date(timestamp * 1000); // Lightweight Charts time is UNIX timestamp
@@ -172,7 +175,7 @@ function formatTooltipDate(timestamp) {
 async function fetchSeriesData(type, code, startDate, endDate) {
     const endpointBuilder = apiEndpoints[type];
     if (!endpointBuilder) throw new Error(`Unknown data type: ${type}`);
-    const apiUrl = `${endpointBuilder(code)}&start_date=${startDate}&end_date=${endDate}`;
+    const apiUrl = `${endpointBuilder(code)}&start_date=${startDate}&end_date=${endDate}&points=${chartPoints}`;
     console.log(`Fetching: ${apiUrl}`);
 
     const response = await fetch(apiUrl);
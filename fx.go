@@ -4,73 +4,203 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
-	fxclient "github.com/Ernestlph/Malaysia-Econ-DB/internal/BNMApiClient"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
 	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/fxprovider"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
 	"github.com/google/uuid"
 )
 
 // --- FX Command Handlers ---
 
-// handlerFxFetch fetches latest FX rates for all currencies from the API and stores them in the database.
-func handlerFxFetchAll(s *AppState, cmd command) error {
+// storeFxRate upserts a single provider rate into the foreign_exchange table.
+// Rates are stored as quoted; unit records how many foreign units the quote applies to.
+// Callers invalidate the response cache once per batch of stored rates.
+func storeFxRate(ctx context.Context, s *AppState, rate fxprovider.Rate) error {
+	params := fxRateParams(ctx, rate)
+	if err := s.db.UpsertForeignExchange(ctx, params); err != nil {
+		return err
+	}
+	publishFxRate(s, params, rate)
+	return nil
+}
+
+// fxRateParams returns the row storing rate, with its currency code normalized and the
+// session and unit defaulted.
+func fxRateParams(ctx context.Context, rate fxprovider.Rate) database.UpsertForeignExchangeParams {
+	unit := rate.Unit
+	if unit <= 0 {
+		unit = 1 // Providers that don't report a unit quote per 1 unit
+	}
+	session := rate.Session
+	if session == "" {
+		session = "1200" // Single daily rates are filed under BNM's reference (noon) session
+	}
+	return database.UpsertForeignExchangeParams{
+		// The unique key compares codes exactly, so "usd" would be stored beside "USD"
+		CurrencyCode: strings.ToUpper(strings.TrimSpace(rate.CurrencyCode)),
+		BuyingRate:   fmt.Sprintf("%.4f", rate.BuyingRate),
+		SellingRate:  fmt.Sprintf("%.4f", rate.SellingRate),
+		MiddleRate:   fmt.Sprintf("%.4f", rate.MiddleRate),
+		Unit:         int32(unit),
+		Source:       rate.Source,
+		CreatedAt:    time.Now(),
+		Date:         rate.Date,
+		Session:      session,
+		ID:           uuid.New(),
+		FetchRunID:   fetchRunFromContext(ctx),
+	}
+}
+
+// publishFxRate publishes a stored rate to the event bus.
+func publishFxRate(s *AppState, params database.UpsertForeignExchangeParams, rate fxprovider.Rate) {
+	// fx:<currency> events carry the noon rate, like the fx:<currency> series everywhere else
+	if params.Session == "1200" {
+		publishObservation(s, watchlistFx, params.CurrencyCode, rate.Date, rate.MiddleRate/float64(params.Unit), rate.Source)
+	}
+}
 
-	// Config checks remain the same
-	if s.cfg.FXAPIBaseURL == "" {
-		return fmt.Errorf("FX_API_BASE_URL is not configured")
+// parseFxSessionFlag extracts a --session=<0900|1200|1700|all> flag from args, returning the
+// sessions to fetch and the remaining arguments. Without the flag the configured FX_SESSION is used.
+func parseFxSessionFlag(s *AppState, args []string) ([]string, []string, error) {
+	sessions := []string{s.cfg.FXSession}
+	var rest []string
+	for _, arg := range args {
+		value, ok := strings.CutPrefix(arg, "--session=")
+		if !ok {
+			rest = append(rest, arg)
+			continue
+		}
+		switch {
+		case value == "all":
+			sessions = fxprovider.Sessions
+		case fxprovider.ValidSession(value):
+			sessions = []string{value}
+		default:
+			return nil, nil, fmt.Errorf("invalid session %q (use 0900, 1200, 1700 or all)", value)
+		}
+	}
+	return sessions, rest, nil
+}
+
+// newFxProviderForSession creates the configured provider pinned to a BNM session.
+// Third-party fallbacks only publish one daily rate, so they are used for the 1200 session only.
+func newFxProviderForSession(s *AppState, session string) (fxprovider.FXProvider, error) {
+	cfg := *s.cfg
+	cfg.FXSession = session
+	if session != "1200" {
+		cfg.FXFallbackProviders = nil
 	}
+	return fxprovider.New(cfg)
+}
 
-	// FX client creation remains the same
-	client := fxclient.New(*s.cfg, s.cfg.FXAPIBaseURL) // Assuming New takes base URL
+// handlerFxFetchAll fetches latest FX rates for all currencies from the configured provider and stores them in the database.
+// When currencies have been added with tracked:add, only those are stored.
+// Usage: fx:fetch_all [--session=0900|1200|1700|all]
+func handlerFxFetchAll(s *AppState, cmd command) error {
+	return sharedFetch(s, fetchKey(cmd), func() error { return fetchFxAll(s, cmd) })
+}
 
-	// Fetch rates from API (using the placeholder implementation for now)
-	rates, err := client.FetchLatestRatesAll()
+func fetchFxAll(s *AppState, cmd command) error {
+	sessions, args, err := parseFxSessionFlag(s, cmd.Args)
 	if err != nil {
-		return fmt.Errorf("failed to fetch FX rates: %w", err)
+		return err
 	}
-	for _, rate := range rates.Data {
-		date, err := time.Parse("2006-01-02", rate.Rate.Date)
-		if err != nil {
-			return fmt.Errorf("failed to parse date: %w", err)
-		}
-		err = s.db.UpsertForeignExchange(context.Background(), database.UpsertForeignExchangeParams{
-			CurrencyCode: rate.CurrencyCode,
-			BuyingRate:   fmt.Sprintf("%.4f", rate.Rate.BuyingRate),
-			SellingRate:  fmt.Sprint(rate.Rate.SellingRate),
-			MiddleRate:   fmt.Sprintf("%.4f", rate.Rate.MiddleRate),
-			CreatedAt:    time.Now(),
-			Date:         date,
-			ID:           uuid.New(),
-		})
+	if len(args) != 0 {
+		return fmt.Errorf("usage: %s [--session=0900|1200|1700|all]", cmd.Name)
+	}
+
+	tracked, err := trackedCurrencies(cmd.Context(), s)
+	if err != nil {
+		return err
+	}
+
+	run := startFetchRun(s, cmd)
+	cmd = run.attach(cmd)
+	var stats fetchStats
+	for _, session := range sessions {
+		provider, err := newFxProviderForSession(s, session)
 		if err != nil {
-			log.Printf("Error storing FX rate for %s on %s: %v", rate.CurrencyCode, rate.Rate.Date, err)
-			continue
+			run.finish(s, stats, err)
+			return err
 		}
-		log.Printf("Stored FX rate for %s with value of %.4f on %s", rate.CurrencyCode, rate.Rate.MiddleRate, rate.Rate.Date)
 
+		rates, err := provider.FetchLatestRates(cmd.Context())
+		if err != nil {
+			err = fmt.Errorf("failed to fetch FX rates (session %s) from %s: %w", session, provider.Name(), err)
+			stats.FailedFetches++
+			run.finish(s, stats, err)
+			notifyBatchFailures(s, cmd.Name, append(stats.Errors, err.Error()))
+			errreport.CaptureError(cmd.Context(), err, map[string]string{"command": cmd.Name, "session": session, "provider": provider.Name()})
+			return err
+		}
+		stats.SuccessfulFetches++
+		for _, rate := range rates {
+			if len(tracked) > 0 && !slices.Contains(tracked, rate.CurrencyCode) {
+				continue
+			}
+			date := rate.Date.Format("2006-01-02")
+			if err := storeFxRate(cmd.Context(), s, rate); err != nil {
+				log.Printf("Error storing FX rate for %s on %s: %v", rate.CurrencyCode, date, err)
+				stats.FailedStores++
+				stats.Errors = append(stats.Errors, fmt.Sprintf("store %s %s session %s: %v", rate.CurrencyCode, date, rate.Session, err))
+				continue
+			}
+			log.Printf("Stored FX rate for %s with value of %.4f on %s session %s (source: %s)", rate.CurrencyCode, rate.MiddleRate, date, rate.Session, rate.Source)
+			stats.SuccessfulStores++
+		}
 	}
 
+	if stats.SuccessfulStores > 0 {
+		invalidateResponseCache(s)
+	}
 	log.Printf("FX rates fetched and stored successfully")
+	run.finish(s, stats, nil)
+	notifyDataStored(s, cmd.Name, stats.SuccessfulStores)
+	notifyBatchFailures(s, cmd.Name, stats.Errors)
+	runPostFetchJobs(cmd.Context(), s)
 
 	return nil
 }
 
-// handlerFxFetchRange fetches FX rates for a specific currency and date range from the API and stores them in the database.
+// handlerFxFetchRange fetches FX rates for a specific currency and date range from the configured provider and stores them in the database.
+// With --missing-only, trading days already stored are skipped and only the gaps are requested.
+// Month windows completed earlier today are skipped unless --force is given.
+// Usage: fx:fetch:range <currency_code> <start_date> <end_date> [--missing-only] [--force] [--session=0900|1200|1700|all]
 func handlerFxFetchRange(s *AppState, cmd command) error {
+	return sharedFetch(s, fetchKey(cmd), func() error { return fetchFxRange(s, cmd) })
+}
 
-	// Config checks remain the same
-	if s.cfg.FXAPIBaseURL == "" {
-		return fmt.Errorf("FX_API_BASE_URL is not configured")
+func fetchFxRange(s *AppState, cmd command) error {
+	sessions, rest, err := parseFxSessionFlag(s, cmd.Args)
+	if err != nil {
+		return err
 	}
-	if len(cmd.Args) != 3 {
-		return fmt.Errorf("usage: %s <currency_code> <start_date YYYY-MM-DD> <end_date YYYY-MM-DD>", cmd.Name)
+	missingOnly, force := false, false
+	var args []string
+	for _, arg := range rest {
+		switch arg {
+		case "--missing-only":
+			missingOnly = true
+		case "--force":
+			force = true
+		default:
+			args = append(args, arg)
+		}
+	}
+	if len(args) != 3 {
+		return fmt.Errorf("usage: %s <currency_code> <start_date YYYY-MM-DD> <end_date YYYY-MM-DD> [--missing-only] [--force] [--session=0900|1200|1700|all]", cmd.Name)
 	}
 
-	targetCurrency := strings.ToUpper(cmd.Args[0])
-	startDate := cmd.Args[1]
-	endDate := cmd.Args[2]
+	targetCurrency := strings.ToUpper(args[0])
+	startDate := args[1]
+	endDate := args[2]
 
 	// Validate Currency Code (Example)
 	if len(targetCurrency) != 3 {
@@ -78,12 +208,12 @@ func handlerFxFetchRange(s *AppState, cmd command) error {
 	}
 
 	// Parse the start dates
-	start, err := time.Parse("2006-01-02", startDate)
+	start, err := markettime.ParseDate(startDate)
 	if err != nil {
 		return fmt.Errorf("failed to parse start date: %w", err)
 	}
 	// Parse the end date
-	end, err := time.Parse("2006-01-02", endDate)
+	end, err := markettime.ParseDate(endDate)
 	if err != nil {
 		return fmt.Errorf("failed to parse end date: %w", err)
 	}
@@ -93,72 +223,252 @@ func handlerFxFetchRange(s *AppState, cmd command) error {
 		return fmt.Errorf("end date must be after start date")
 	}
 
-	// Create slice that has all teh days from the start date to the end date
-	var dates []string
-
-	// Loop through the dates and add them to the slice
-	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
-		dates = append(dates, d.Format("2006-01-02"))
+	run := startFetchRun(s, cmd)
+	cmd = run.attach(cmd)
+	checkpoints := loadFetchCheckpoints(cmd.Context(), s, cmd.Name, force)
+	var total fetchStats
+	for _, session := range sessions {
+		stats, err := fetchFxRangeForSession(cmd.Context(), s, session, targetCurrency, start, end, missingOnly, checkpoints)
+		total.add(stats)
+		if err != nil {
+			run.finish(s, total, err)
+			notifyBatchFailures(s, cmd.Name, []string{err.Error()})
+			errreport.CaptureError(cmd.Context(), err, map[string]string{"command": cmd.Name, "currency": targetCurrency, "session": session})
+			return err
+		}
 	}
+	run.finish(s, total, nil)
 
-	if len(dates) == 0 {
-		return fmt.Errorf("no dates found in the specified range")
+	// Log summary
+	log.Printf("FX rate fetching complete for range %s to %s (sessions: %s).", startDate, endDate, strings.Join(sessions, ", "))
+	log.Printf("API Fetches: %d successful, %d failed.", total.SuccessfulFetches, total.FailedFetches)
+	log.Printf("Database Stores/Updates: %d successful, %d failed.", total.SuccessfulStores, total.FailedStores)
+	notifyDataStored(s, cmd.Name, total.SuccessfulStores)
+	if total.FailedFetches > 0 || total.FailedStores > 0 {
+		notifyBatchFailures(s, fmt.Sprintf("%s %s %s..%s", cmd.Name, targetCurrency, startDate, endDate), []string{
+			fmt.Sprintf("%d failed API fetches, %d failed database stores (see logs)", total.FailedFetches, total.FailedStores),
+		})
 	}
+	runPostFetchJobs(cmd.Context(), s)
 
-	log.Printf("Attempting to fetch FX rates for %s from %s to %s (%d days)", targetCurrency, startDate, endDate, len(dates))
+	return nil
+
+}
 
-	// Create API client
-	client := fxclient.New(*s.cfg, s.cfg.FXAPIBaseURL) // Assuming New takes base URL
+// fetchFxRangeForSession fetches and stores one currency's rates for one BNM session, one calendar month per request.
+// With missingOnly, each request covers only one run of missing trading days.
+// Windows recorded in checkpoints are skipped, and each window fetched and stored in full is recorded.
+func fetchFxRangeForSession(ctx context.Context, s *AppState, session, targetCurrency string, start, end time.Time, missingOnly bool, checkpoints *fetchCheckpoints) (fetchStats, error) {
+	var stats fetchStats
 
-	var successfulFetches, failedFetches, successfulStores, failedStores int
+	// Split the range into calendar-month windows; bulk providers serve one month per request
+	type window struct{ start, end time.Time }
+	var windows []window
+	for ws := start; !ws.After(end); {
+		we := time.Date(ws.Year(), ws.Month()+1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1) // Last day of ws's month
+		if we.After(end) {
+			we = end
+		}
+		windows = append(windows, window{start: ws, end: we})
+		ws = we.AddDate(0, 0, 1)
+	}
 
-	// Fetch rate from API for each date
-	for _, dateStr := range dates {
-		// Fetch rate for that date
-		rateResponse, err := client.FetchTargetCurrencyRates(targetCurrency, dateStr)
+	if missingOnly {
+		existing, err := s.db.ListForeignExchangeDates(ctx, database.ListForeignExchangeDatesParams{
+			CurrencyCode: targetCurrency,
+			StartDate:    start,
+			EndDate:      end,
+			Session:      session,
+		})
+		if err != nil {
+			return stats, fmt.Errorf("failed to list stored FX dates for %s: %w", targetCurrency, err)
+		}
+		stored := make(map[string]bool, len(existing))
+		for _, d := range existing {
+			stored[d.Format("2006-01-02")] = true
+		}
+
+		// Weekends and market holidays have no rates, so they are never gaps
+		cal, err := loadTradingCalendar(ctx, s)
 		if err != nil {
-			log.Printf("Failed to fetch FX rate for %s on %s: %v", targetCurrency, dateStr, err)
-			failedFetches++
-			continue // Continue to next date
+			log.Printf("Warning: %v; treating only weekends as non-trading days.", err)
 		}
-		successfulFetches++
 
-		// Assuming the first entry is the one we want for that date/currency
-		rateData := rateResponse.Data
+		// Replace each window with its runs of missing trading days; a stored trading day ends
+		// a run, while weekends and holidays inside a run are requested along with it
+		var gapWindows []window
+		monthsWithGaps := 0
+		for _, w := range windows {
+			var first, last time.Time
+			runs := len(gapWindows)
+			for d := w.start; !d.After(w.end); d = d.AddDate(0, 0, 1) {
+				if !cal.IsTradingDay(d) {
+					continue
+				}
+				if stored[d.Format("2006-01-02")] {
+					if !first.IsZero() {
+						gapWindows = append(gapWindows, window{start: first, end: last})
+						first = time.Time{}
+					}
+					continue
+				}
+				if first.IsZero() {
+					first = d
+				}
+				last = d
+			}
+			if !first.IsZero() {
+				gapWindows = append(gapWindows, window{start: first, end: last})
+			}
+			if len(gapWindows) > runs {
+				monthsWithGaps++
+			}
+		}
+		log.Printf("Missing-only mode (session %s): %d stored dates found, %d gaps in %d of %d months.", session, len(existing), len(gapWindows), monthsWithGaps, len(windows))
+		windows = gapWindows
+		if len(windows) == 0 {
+			log.Printf("No missing FX dates for %s (session %s) between %s and %s.", targetCurrency, session, start.Format("2006-01-02"), end.Format("2006-01-02"))
+			return stats, nil
+		}
+	}
+
+	// Create provider
+	provider, err := newFxProviderForSession(s, session)
+	if err != nil {
+		return stats, err
+	}
 
-		// Parse date
-		parsedDate, err := time.Parse("2006-01-02", rateData.Rate.Date)
+	log.Printf("Attempting to fetch FX rates for %s session %s from %s to %s (%d requests) via %s", targetCurrency, session, start.Format("2006-01-02"), end.Format("2006-01-02"), len(windows), provider.Name())
+
+	// Fetch rates from provider for each month window
+	for _, w := range windows {
+		item := fmt.Sprintf("%s %s %s..%s", targetCurrency, session, w.start.Format("2006-01-02"), w.end.Format("2006-01-02"))
+		if checkpoints.completed(item) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return stats, err // Shutting down or timed out; completed windows are checkpointed
+		}
+		rates, err := provider.FetchRange(ctx, targetCurrency, w.start, w.end)
 		if err != nil {
-			log.Printf("Failed to parse date %s: %v", rateData.Rate.Date, err)
-			failedStores++
-			continue // Try next date
+			log.Printf("Failed to fetch FX rates for %s from %s to %s: %v", targetCurrency, w.start.Format("2006-01-02"), w.end.Format("2006-01-02"), err)
+			stats.FailedFetches++
+			stats.Errors = append(stats.Errors, fmt.Sprintf("fetch %s %s..%s session %s: %v", targetCurrency, w.start.Format("2006-01-02"), w.end.Format("2006-01-02"), session, err))
+			continue // Continue to next month
 		}
+		stats.SuccessfulFetches++
 
-		// Call UPSERT function
-		err = s.db.UpsertForeignExchange(context.Background(), database.UpsertForeignExchangeParams{
-			CurrencyCode: targetCurrency,
-			BuyingRate:   fmt.Sprintf("%.4f", rateData.Rate.BuyingRate),
-			SellingRate:  fmt.Sprint(rateData.Rate.SellingRate),
-			MiddleRate:   fmt.Sprintf("%.4f", rateData.Rate.MiddleRate),
-			CreatedAt:    time.Now(),
-			Date:         parsedDate,
-			ID:           uuid.New(),
+		failedStores, successfulStores := stats.FailedStores, stats.SuccessfulStores
+		for _, rate := range rates {
+			// Call UPSERT function
+			if err := storeFxRate(ctx, s, rate); err != nil {
+				log.Printf("Error storing FX rate for %s on %s: %v", targetCurrency, rate.Date.Format("2006-01-02"), err)
+				stats.FailedStores++
+				stats.Errors = append(stats.Errors, fmt.Sprintf("store %s %s session %s: %v", targetCurrency, rate.Date.Format("2006-01-02"), rate.Session, err))
+				continue
+			}
+			stats.SuccessfulStores++
+			log.Printf("Stored FX rate for %s with value of %.4f on %s session %s (source: %s)", targetCurrency, rate.MiddleRate, rate.Date.Format("2006-01-02"), rate.Session, rate.Source)
+		}
+		if stats.SuccessfulStores > successfulStores {
+			invalidateResponseCache(s) // Once per window, so cached responses are not rebuilt rate by rate
+		}
+		// Windows reaching today may still gain rates later in the day, so they are never checkpointed
+		if stats.FailedStores == failedStores && w.end.Before(markettime.Today()) {
+			checkpoints.complete(ctx, s, item)
+		}
+	}
+
+	return stats, nil
+}
+
+// computeEffectiveExchangeRates recomputes the NEER index from stored bilateral rates and
+// replaces the stored series in a single transaction. It returns the number of points stored.
+//
+// Only the nominal index is produced: a real (REER) index additionally needs relative
+// price indices for Malaysia and each partner, which this database does not hold yet.
+func computeEffectiveExchangeRates(ctx context.Context, s *AppState) (int, error) {
+	if len(s.cfg.EERWeights) == 0 {
+		return 0, fmt.Errorf("EER_WEIGHTS is empty")
+	}
+
+	end := markettime.Today()
+	rates := make(map[string][]analytics.Point, len(s.cfg.EERWeights))
+	for code := range s.cfg.EERWeights {
+		rows, err := s.db.GetForeignExchangeByCurrencyAndDateRange(ctx, database.GetForeignExchangeByCurrencyAndDateRangeParams{
+			CurrencyCode: code,
+			StartDate:    s.cfg.EERStartDate,
+			EndDate:      end,
+			Session:      "1200",
 		})
 		if err != nil {
-			log.Printf("Error storing FX rate for %s on %s: %v", targetCurrency, parsedDate, err)
-			failedStores++
+			return 0, fmt.Errorf("failed to load FX rates for %s: %w", code, err)
+		}
+		if len(rows) == 0 {
+			log.Printf("Warning: No stored FX rates for %s; it is left out of the effective exchange rate basket.", code)
 			continue
 		}
-		successfulStores++
-		log.Printf("Stored FX rate for %s with value of %.4f on %s", targetCurrency, rateData.Rate.MiddleRate, parsedDate)
+		for _, row := range rows {
+			value, err := strconv.ParseFloat(row.MiddleRatePerUnit, 64)
+			if err != nil {
+				log.Printf("Error parsing middle rate for %s on %s: %v", code, row.Date.Format("2006-01-02"), err)
+				continue
+			}
+			rates[code] = append(rates[code], analytics.Point{Date: row.Date, Value: value})
+		}
+	}
 
+	index := analytics.EffectiveExchangeRate(rates, s.cfg.EERWeights)
+	if len(index) == 0 {
+		return 0, fmt.Errorf("no dates with rates for every basket currency since %s", s.cfg.EERStartDate.Format("2006-01-02"))
 	}
 
-	// Log summary
-	log.Printf("FX rate fetching complete for range %s to %s.", startDate, endDate)
-	log.Printf("API Fetches: %d successful, %d failed.", successfulFetches, failedFetches)
-	log.Printf("Database Stores/Updates: %d successful, %d failed.", successfulStores, failedStores)
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
 
-	return nil
+	if err := qtx.DeleteEffectiveExchangeRates(ctx, "neer"); err != nil {
+		return 0, fmt.Errorf("failed to clear NEER series: %w", err)
+	}
+	for _, p := range index {
+		err := qtx.UpsertEffectiveExchangeRate(ctx, database.UpsertEffectiveExchangeRateParams{
+			IndexType: "neer",
+			Date:      p.Date,
+			Value:     fmt.Sprintf("%.6f", p.Value),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to store NEER for %s: %w", p.Date.Format("2006-01-02"), err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit NEER series: %w", err)
+	}
+	invalidateResponseCache(s)
+	return len(index), nil
+}
 
+// runEffectiveExchangeRates recomputes the index as a recorded fx:eer:compute run, so its
+// successes and failures show in /api/status like the fetches'.
+func runEffectiveExchangeRates(ctx context.Context, s *AppState) (int, error) {
+	run := startFetchRun(s, command{Name: "fx:eer:compute", ctx: ctx})
+	n, err := computeEffectiveExchangeRates(ctx, s)
+	run.finish(s, fetchStats{SuccessfulStores: n}, err)
+	return n, err
+}
+
+// handlerFxEerCompute recomputes the effective exchange rate index on demand.
+// Usage: fx:eer:compute
+func handlerFxEerCompute(s *AppState, cmd command) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+	n, err := runEffectiveExchangeRates(cmd.Context(), s)
+	if err != nil {
+		return fmt.Errorf("failed to compute effective exchange rates: %w", err)
+	}
+	fmt.Printf("Stored %d NEER observations.\n", n)
+	return nil
 }
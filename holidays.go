@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/scraper"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// holidayDatePattern matches the dates on Bursa's holiday calendar, including day ranges:
+// "1 January 2025", "Wednesday, 29 Jan 2025" and "29 - 30 January 2025".
+var holidayDatePattern = regexp.MustCompile(`(\d{1,2})(?:\s*(?:-|–|&|and)\s*(\d{1,2}))?\s+([A-Za-z]{3,9})\.?,?\s+(\d{4})`)
+
+// scrapedHoliday is one closed market date read from the Bursa calendar.
+type scrapedHoliday struct {
+	Date time.Time
+	Name string
+}
+
+// loadTradingCalendar returns the trading calendar built from the stored market holidays.
+func loadTradingCalendar(ctx context.Context, s *AppState) (*markettime.Calendar, error) {
+	rows, err := s.db.ListMarketHolidays(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load market holidays: %w", err)
+	}
+	dates := make([]time.Time, 0, len(rows))
+	for _, row := range rows {
+		dates = append(dates, row.HolidayDate)
+	}
+	return markettime.NewCalendar(dates), nil
+}
+
+// parseHolidayDates returns the dates named in a calendar cell such as "29 - 30 January 2025".
+func parseHolidayDates(text string) []time.Time {
+	m := holidayDatePattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	month, err := time.Parse("January", m[3])
+	if err != nil {
+		if month, err = time.Parse("Jan", m[3]); err != nil {
+			return nil
+		}
+	}
+	year, _ := strconv.Atoi(m[4])
+	first, _ := strconv.Atoi(m[1])
+	last := first
+	if m[2] != "" {
+		last, _ = strconv.Atoi(m[2])
+	}
+	var dates []time.Time
+	for day := first; day <= last && day <= 31; day++ {
+		d := time.Date(year, month.Month(), day, 0, 0, 0, 0, time.UTC)
+		if d.Month() == month.Month() {
+			dates = append(dates, d)
+		}
+	}
+	return dates
+}
+
+// scrapeBursaHolidays reads the holiday table at url: every row with a date cell is a holiday,
+// named by the first other non-empty cell.
+func scrapeBursaHolidays(ctx context.Context, source *scraper.Client, url string) ([]scrapedHoliday, error) {
+	resp, err := source.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML from %s: %w", url, err)
+	}
+
+	var holidays []scrapedHoliday
+	doc.Find("table tr").Each(func(i int, row *goquery.Selection) {
+		var dates []time.Time
+		var name string
+		row.Find("td").Each(func(j int, cell *goquery.Selection) {
+			text := strings.Join(strings.Fields(cell.Text()), " ")
+			if text == "" {
+				return
+			}
+			if dates == nil {
+				if d := parseHolidayDates(text); d != nil {
+					dates = d
+					return
+				}
+			}
+			if name == "" && !strings.EqualFold(text, "closed") {
+				name = text
+			}
+		})
+		if name == "" {
+			name = "Market holiday"
+		}
+		for _, d := range dates {
+			holidays = append(holidays, scrapedHoliday{Date: d, Name: name})
+		}
+	})
+	if len(holidays) == 0 {
+		return nil, fmt.Errorf("no holidays found on %s (has the page layout changed?)", url)
+	}
+	return holidays, nil
+}
+
+// fetchMarketHolidays scrapes SOURCE_BURSA_HOLIDAYS_BASE_URL and stores the weekday holidays found.
+// It returns the number stored.
+func fetchMarketHolidays(ctx context.Context, s *AppState) (int, error) {
+	source := s.sources.Source(config.SourceBursaHolidays)
+	if source.BaseURL() == "" {
+		return 0, fmt.Errorf("SOURCE_BURSA_HOLIDAYS_BASE_URL is not set")
+	}
+	holidays, err := scrapeBursaHolidays(ctx, source, source.BaseURL())
+	if err != nil {
+		return 0, err
+	}
+	stored := 0
+	for _, h := range holidays {
+		if h.Date.Weekday() == time.Saturday || h.Date.Weekday() == time.Sunday {
+			continue
+		}
+		err := s.db.UpsertMarketHoliday(ctx, database.UpsertMarketHolidayParams{
+			HolidayDate: h.Date,
+			Name:        h.Name,
+			Source:      "bursa",
+		})
+		if err != nil {
+			return stored, fmt.Errorf("failed to store holiday %s: %w", h.Date.Format("2006-01-02"), err)
+		}
+		stored++
+	}
+	return stored, nil
+}
+
+// holidaysInterval is the holiday refresh interval, or 0 when no calendar URL is configured
+// or the source is disabled.
+func holidaysInterval(s *AppState) time.Duration {
+	if source := s.cfg.Source(config.SourceBursaHolidays); source.BaseURL == "" || !source.Enabled {
+		return 0
+	}
+	return s.cfg.HolidayRefreshInterval
+}
+
+// handlerMarketHolidays lists the stored market holidays of a year.
+// Usage: market:holidays [YEAR]  (default: the current year)
+func handlerMarketHolidays(s *AppState, cmd command) error {
+	year := markettime.Today().Year()
+	if len(cmd.Args) > 1 {
+		return fmt.Errorf("usage: %s [YEAR]", cmd.Name)
+	}
+	if len(cmd.Args) == 1 {
+		y, err := strconv.Atoi(cmd.Args[0])
+		if err != nil || y < 1900 || y > 2200 {
+			return fmt.Errorf("invalid year %q", cmd.Args[0])
+		}
+		year = y
+	}
+	rows, err := s.db.ListMarketHolidaysBetween(cmd.Context(), database.ListMarketHolidaysBetweenParams{
+		StartDate: time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list market holidays: %w", err)
+	}
+	if len(rows) == 0 {
+		fmt.Printf("No market holidays stored for %d.\n", year)
+		return nil
+	}
+	for _, row := range rows {
+		fmt.Printf("  %s %s  %-40s (%s)\n", row.HolidayDate.Format("2006-01-02"), row.HolidayDate.Format("Mon"), row.Name, row.Source)
+	}
+	return nil
+}
+
+// handlerMarketHolidaysFetch refreshes the market holidays from the Bursa calendar (admin only).
+// Usage: market:holidays:fetch
+func handlerMarketHolidaysFetch(s *AppState, cmd command) error {
+	n, err := fetchMarketHolidays(cmd.Context(), s)
+	if err != nil {
+		return err
+	}
+	log.Printf("Stored %d market holiday(s) from %s.", n, s.cfg.Source(config.SourceBursaHolidays).BaseURL)
+	fmt.Printf("Stored %d market holiday(s).\n", n)
+	return nil
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
 	"database/sql" // Import database/sql for sql.ErrNoRows
 	"encoding/json"
 	"fmt"
@@ -13,14 +12,19 @@ import (
 	"time"
 
 	// Assuming your sqlc generated code is in this package
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
 	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/fxprovider"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
 	// No longer need config directly here as it's in the state
 	// "github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
 )
 
 // apiServer holds dependencies for the HTTP handlers, like database access.
 type apiServer struct {
-	state *AppState // Holds db queries and config
+	state *AppState       // Holds db queries and config
+	ctx   context.Context // Application context, cancelled on shutdown; background work runs under it
 }
 
 // Structure for generic time-series API response expected by the frontend
@@ -29,6 +33,16 @@ type TimeSeriesDataPoint struct {
 	Value float64 `json:"value"` // Generic value (price, rate, amount)
 }
 
+func (d TimeSeriesDataPoint) point() analytics.Point {
+	return datedPoint(d.Date, d.Value)
+}
+
+// datedPoint converts a response item's YYYY-MM-DD date and value to a series point.
+func datedPoint(date string, value float64) analytics.Point {
+	d, _ := markettime.ParseDate(date)
+	return analytics.Point{Date: d, Value: value}
+}
+
 // runHttpsServer sets up and runs the HTTPS server.
 // It now accepts the application state (*state) containing db access and config.
 func runHttpsServer(ctx context.Context, wg *sync.WaitGroup, shutdownChan chan struct{}, appState *AppState) {
@@ -37,40 +51,99 @@ func runHttpsServer(ctx context.Context, wg *sync.WaitGroup, shutdownChan chan s
 	// Create the apiServer instance holding the application state
 	server := &apiServer{
 		state: appState,
+		ctx:   ctx,
 	}
 
 	// Create a new ServeMux to route requests
 	mux := http.NewServeMux()
 
 	// --- Register API Handlers ---
-	mux.HandleFunc("/api/stock/prices", server.handleGetStockPrices)
-	mux.HandleFunc("/api/fx/rates", server.handleGetFxRates)
+	// Public data endpoints are served through the response cache (see API_CACHE_TTL) and, like
+	// the other data reads wrapped in timed, cancelled after API_REQUEST_TIMEOUT
+	mux.HandleFunc("/api/stocks", server.cached(server.handleGetStocks))
+	mux.HandleFunc("/api/stocks/latest", server.cached(server.handleGetStocksLatest))
+	mux.HandleFunc("/api/sectors/flows", server.cached(server.handleGetSectorFlows))
+	mux.HandleFunc("/api/stock/prices", server.cached(server.handleGetStockPrices))
+	mux.HandleFunc("/api/stock/intraday", server.cached(server.handleGetStockIntraday))
+	mux.HandleFunc("/api/stock/beta", server.cached(server.handleGetStockBeta))
+	mux.HandleFunc("/api/stock/news", server.cached(server.handleGetStockNews))
+	mux.HandleFunc("/api/stock/documents", server.cached(server.handleGetStockDocuments))
+	mux.HandleFunc("/api/stock/documents/file", server.handleGetStockDocumentFile) // Streams the file; revalidated by ETag
+	mux.HandleFunc("/api/fx/rates", server.cached(server.handleGetFxRates))
+	mux.HandleFunc("/api/fx/reer", server.cached(server.handleGetFxEffectiveRates))
+	mux.HandleFunc("/api/fx/convert", server.cached(server.handleGetFxConvert))
+	mux.HandleFunc("/api/fx/fixings", server.cached(server.handleGetFxFixings))
+	mux.HandleFunc("/api/fx/spreads", server.cached(server.handleGetFxSpreads))
+	mux.HandleFunc("/api/analytics/returns", server.cached(server.handleGetReturns))
+	mux.HandleFunc("/api/analytics/sentiment", server.cached(server.handleGetSentiment))
+	mux.HandleFunc("/api/analytics/volatility", server.cached(server.handleGetVolatility))
+	mux.HandleFunc("/api/analytics/drawdown", server.cached(server.handleGetDrawdown))
+	mux.HandleFunc("/api/analytics/correlation", server.cached(server.handleGetCorrelation))
+	mux.HandleFunc("/api/backtest", server.cached(server.handleGetBacktest))
+	mux.HandleFunc("/api/screener", server.cached(server.handleGetScreener))
+	mux.HandleFunc("/api/macro/series", server.cached(server.handleGetMacroSeries))
+	mux.HandleFunc("/api/macro/decompose", server.cached(server.handleGetMacroDecomposition))
+	mux.HandleFunc("/api/annotations", server.cached(server.handleGetAnnotations))
+	mux.HandleFunc("/api/search", server.cached(server.handleSearch))
+	mux.HandleFunc("/api/lineage", server.cached(server.handleGetLineage))
+	mux.HandleFunc("/api/diff", server.cached(server.handleGetDiff))
+	mux.HandleFunc("/api/calendar/entitlements", server.cached(server.handleGetEntitlementsCalendar))
+	mux.HandleFunc("/api/status", server.handleGetStatus)
+	// Grafana JSON datasource; point the datasource URL at /api/grafana and send an API key header
+	mux.HandleFunc("/api/grafana/", server.requireAuth(server.handleGrafanaRoot))
+	mux.HandleFunc("/api/grafana/search", server.requireAuth(server.handleGrafanaSearch))
+	mux.HandleFunc("/api/grafana/query", server.requireAuth(server.timed(server.handleGrafanaQuery)))
+	mux.HandleFunc("/status", server.handleStatusPage)
+	mux.Handle("/metrics/economic", server.economicMetricsHandler())
+	mux.HandleFunc("/api/ingest", server.handleIngest) // Authenticated by the source's signature
+	mux.HandleFunc("/api/auth/login", server.handleAuthLogin)
+	mux.HandleFunc("/api/auth/me", server.requireAuth(server.handleAuthMe))
+	mux.HandleFunc("/api/watchlists", server.requireAuth(server.handleWatchlists))
+	mux.HandleFunc("/api/portfolio/holdings", server.requireAuth(server.handlePortfolioHoldings))
+	mux.HandleFunc("/api/portfolio/value", server.requireAuth(server.timed(server.handleGetPortfolioValue)))
+	mux.HandleFunc("/api/baskets", server.requireAuth(server.handleBaskets))
+	mux.HandleFunc("/api/baskets/values", server.requireAuth(server.timed(server.handleGetBasketValues)))
+	mux.HandleFunc("/api/query", server.requireAuth(server.handleQuery))
+	mux.HandleFunc("/api/charts", server.requireAuth(server.handleCharts))
+	mux.HandleFunc("/api/charts/{id}", server.handleChart) // Shared charts are public; others need their owner's auth
+	mux.HandleFunc("/api/alerts", server.requireAuth(server.handleGetAlerts))
+	mux.HandleFunc("/api/alerts/rules", server.requireAuth(server.handleAlertRules))
+	mux.HandleFunc("/api/alerts/rules/export", server.requireAuth(server.handleAlertRulesExport))
+	mux.HandleFunc("/api/alerts/rules/import", server.requireAuth(server.handleAlertRulesImport))
+	mux.HandleFunc("/api/alerts/announcements", server.requireAuth(server.handleGetAnnouncementAlerts))
+	mux.HandleFunc("/api/alerts/announcements/rules", server.requireAuth(server.handleAnnouncementAlertRules))
+	server.registerAdminRoutes(mux)
 	// Add more API handlers here as needed (e.g., for loans)
 	// mux.HandleFunc("/api/loans/sector", server.handleGetLoanData)
 
 	// --- Register Static File Server (must be general and often last) ---
-	// Serve files like index.html, chart.js from the "./frontend" directory
-	// Requests to "/" will serve "./frontend/index.html" if it exists
-	// Requests to "/chart.js" will serve "./frontend/chart.js" if it exists
-	fileServer := http.FileServer(http.Dir("./frontend"))
-	mux.Handle("/", fileServer)
+	// Serve files like index.html, chart.js from the frontend embedded in the binary (or FRONTEND_DIR)
+	// Requests to "/" will serve "index.html"
+	// Requests to "/chart.js" will serve "chart.js"
+	// Other paths without a file (client-side routes like /charts/1155) also serve "index.html"
+	mux.Handle("/", spaHandler(frontendFiles(appState), appState.cfg.BasePath))
 
 	// --- Configure TLS ---
-	tlsCfg := &tls.Config{
-		MinVersion:               tls.VersionTLS12,
-		CurvePreferences:         []tls.CurveID{tls.CurveP521, tls.CurveP384, tls.CurveP256},
-		PreferServerCipherSuites: true,
-		CipherSuites: []uint16{
-			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
-			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
-			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, // Required for HTTP/2
-		},
+	// Minimum version, cipher suites and client certificates come from the TLS_* settings
+	tlsCfg, err := appState.cfg.TLSConfig()
+	if err != nil {
+		log.Fatalf("FATAL: invalid TLS configuration: %v", err)
+	}
+
+	// Client addresses forwarded by the TRUSTED_PROXIES
+	trusted, err := appState.cfg.TrustedProxyPrefixes()
+	if err != nil {
+		log.Fatalf("FATAL: invalid proxy configuration: %v", err)
 	}
 
 	// --- Create the HTTP Server Instance ---
+	// All registered handlers (only the public ones with PUBLIC_READ_ONLY) under BASE_PATH;
+	// panics are reported and answered with a 500
+	handler := recoverPanics(errreport.Middleware(mountAt(appState.cfg.BasePath, publicReadOnly(appState, mux))))
+	handler = trustProxies(trusted, strictTransportSecurity(appState.cfg.HSTSHeader(), handler))
 	srv := &http.Server{
 		Addr:         appState.cfg.ServerAddr, // Get server address from config within state
-		Handler:      mux,                     // Use the mux with all registered handlers
+		Handler:      handler,
 		TLSConfig:    tlsCfg,
 		ReadTimeout:  10 * time.Second, // Reasonable timeouts
 		WriteTimeout: 10 * time.Second,
@@ -79,12 +152,15 @@ func runHttpsServer(ctx context.Context, wg *sync.WaitGroup, shutdownChan chan s
 
 	// --- Start Server Goroutine ---
 	go func() {
-		log.Printf("Starting HTTPS server on %s (serving API and frontend from ./frontend)", srv.Addr)
+		log.Printf("Starting HTTPS server on %s%s (serving API and %s)", srv.Addr, appState.cfg.BasePath, frontendSource(appState))
 		// Use CertFile and KeyFile from config within state
 		err := srv.ListenAndServeTLS(appState.cfg.CertFile, appState.cfg.KeyFile)
 		// ListenAndServeTLS always returns a non-nil error. After Shutdown or Close,
 		// the returned error is http.ErrServerClosed. We should not treat that as fatal.
 		if err != nil && err != http.ErrServerClosed {
+			errreport.CaptureError(context.Background(), err, map[string]string{"component": "https"})
+			// log.Fatalf exits without running deferred calls, so flush the report first
+			errreport.Flush()
 			log.Fatalf("FATAL: HTTPS server ListenAndServeTLS error: %v", err) // Use Fatalf to exit if server fails to start
 		}
 		log.Println("HTTPS server stopped listening.")
@@ -107,41 +183,83 @@ func runHttpsServer(ctx context.Context, wg *sync.WaitGroup, shutdownChan chan s
 	}
 }
 
+// strictTransportSecurity sends header as Strict-Transport-Security on every response, telling
+// browsers to use HTTPS only (see HSTS_MAX_AGE). It returns next unchanged when header is empty.
+func strictTransportSecurity(header string, next http.Handler) http.Handler {
+	if header == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Strict-Transport-Security", header)
+		next.ServeHTTP(w, r)
+	})
+}
+
 // --- API Handler Implementations ---
 
 type StockPriceDetailResponseItem struct {
 	Date        string  `json:"date"`
 	Value       float64 `json:"value"`
-	CompanyName string  `json:"company_name"` // NEW
-	StockCode   string  `json:"stock_code"`   // NEW (optional, good for frontend)
+	CompanyName string  `json:"company_name"`       // NEW
+	StockCode   string  `json:"stock_code"`         // NEW (optional, good for frontend)
+	Currency    string  `json:"currency,omitempty"` // Set with fx_adjust; Value is then in this currency
+	FxRate      float64 `json:"fx_rate,omitempty"`  // MYR per unit of Currency used for the conversion
+}
+
+func (d StockPriceDetailResponseItem) point() analytics.Point {
+	return datedPoint(d.Date, d.Value)
 }
 
 // handleGetStockPrices handles requests for stock price data, now including company name
+// fx_adjust=USD (any stored currency) converts the MYR closes using that day's noon rate, or the
+// last rate before it on days BNM did not publish; closes before the first stored rate are dropped.
+// transform=real deflates the closes by CPI, in prices of cpi_base=YYYY-MM (default: the index base).
+// points=N downsamples the closes to N points with LTTB for charting long ranges.
+// interval=monthly returns the close of the last trading day of each month, dated on that day,
+// from the monthly_stock_closes view (refreshed by db:maintenance).
+// fields=date,value returns only those fields of each close, e.g. to leave out the repeated
+// company_name and stock_code; new fields never reach clients that name the ones they read.
 func (s *apiServer) handleGetStockPrices(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	queryParams := r.URL.Query()
-	stockCode := queryParams.Get("code")
-	startDateStr := queryParams.Get("start_date")
-	endDateStr := queryParams.Get("end_date")
-
-	if stockCode == "" || startDateStr == "" || endDateStr == "" {
-		http.Error(w, "Missing required query parameters: code, start_date, end_date", http.StatusBadRequest)
+	p := s.params(r)
+	stockCode := p.stockCode("code", true)
+	startDate, endDate := p.dateRange(time.Time{}, true)
+	fxAdjust := p.currency("fx_adjust", false)
+	realValues, cpiBase := realTransform(p)
+	points := chartPoints(p)
+	interval := p.enum("interval", "daily", "daily", "monthly")
+	fields := p.fields("fields", StockPriceDetailResponseItem{})
+	if realValues && fxAdjust != "" && fxAdjust != baseCurrency {
+		p.fail("fx_adjust", "transform=real uses Malaysian CPI and cannot be combined with fx_adjust")
+	}
+	if !p.ok(w) {
 		return
 	}
+	startDateStr, endDateStr := startDate.Format("2006-01-02"), endDate.Format("2006-01-02")
 
-	startDate, err := time.Parse("2006-01-02", startDateStr)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid start_date format (use YYYY-MM-DD): %v", err), http.StatusBadRequest)
+	deflator, ok := s.loadRealTransform(w, r, realValues, cpiBase, startDate, endDate)
+	if !ok {
 		return
 	}
-	endDate, err := time.Parse("2006-01-02", endDateStr)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid end_date format (use YYYY-MM-DD): %v", err), http.StatusBadRequest)
-		return
+
+	var fxRates []analytics.Point
+	var err error
+	if fxAdjust != "" && fxAdjust != baseCurrency {
+		fxRates, err = loadFxPerUnit(r.Context(), s.state, fxAdjust, startDate, endDate)
+		if err != nil {
+			log.Printf("API Error: Database error fetching %s rates for fx_adjust: %v", fxAdjust, err)
+			errreport.CaptureError(r.Context(), err, map[string]string{"currency": fxAdjust})
+			sendInternalError(w)
+			return
+		}
+		if len(fxRates) == 0 {
+			sendNotFound(w, fmt.Sprintf("No %s exchange rates stored for the requested period", fxAdjust))
+			return
+		}
 	}
 
 	// --- Database Query ---
@@ -152,9 +270,14 @@ func (s *apiServer) handleGetStockPrices(w http.ResponseWriter, r *http.Request)
 		EndDate:   endDate,
 	}
 
-	log.Printf("API: Querying stock prices with details for %s from %s to %s", stockCode, startDateStr, endDateStr)
-	// Call the correct sqlc generated function
-	dbResults, err := s.state.db.GetStockPricesWithDetailsByCodeAndDateRange(r.Context(), dbParams)
+	log.Printf("API: Querying %s stock prices with details for %s from %s to %s", interval, stockCode, startDateStr, endDateStr)
+	var dbResults []database.GetStockPricesWithDetailsByCodeAndDateRangeRow
+	if interval == "monthly" {
+		dbResults, err = loadMonthlyStockCloses(r.Context(), s.state, stockCode, startDate, endDate)
+	} else {
+		// Call the correct sqlc generated function
+		dbResults, err = s.state.db.GetStockPricesWithDetailsByCodeAndDateRange(r.Context(), dbParams)
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("API: No stock price data found for %s between %s and %s", stockCode, startDateStr, endDateStr)
@@ -163,7 +286,8 @@ func (s *apiServer) handleGetStockPrices(w http.ResponseWriter, r *http.Request)
 			return
 		}
 		log.Printf("API Error: Database error fetching stock prices for %s: %v", stockCode, err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		errreport.CaptureError(r.Context(), err, map[string]string{"stock_code": stockCode})
+		sendInternalError(w)
 		return
 	}
 
@@ -179,49 +303,84 @@ func (s *apiServer) handleGetStockPrices(w http.ResponseWriter, r *http.Request)
 			continue // Skip this data point if conversion fails
 		}
 
-		response = append(response, StockPriceDetailResponseItem{
+		item := StockPriceDetailResponseItem{
 			Date:        dbRow.PriceDate.Format("2006-01-02"),
 			Value:       price, // Use the converted float64
 			CompanyName: dbRow.CompanyName,
 			StockCode:   dbRow.StockCode,
-		})
+		}
+		if fxRates != nil {
+			rate, ok := analytics.ValueAt(fxRates, dbRow.PriceDate)
+			if !ok || rate <= 0 {
+				continue // No rate yet on this date
+			}
+			item.Value, item.Currency, item.FxRate = price/rate, fxAdjust, rate
+		}
+		if deflator != nil {
+			if item.Value, ok = deflator.deflate(dbRow.PriceDate, price); !ok {
+				continue // Before the first CPI month
+			}
+		}
+		response = append(response, item)
 	}
 
 	log.Printf("API: Found %d stock price records (with details) for %s", len(response), stockCode)
-	sendJsonResponse(w, response)
+	sendJsonFields(w, r, downsample(response, points, StockPriceDetailResponseItem.point), fields)
+}
+
+// FxRateDataPoint extends TimeSeriesDataPoint with the quote unit reported by the source.
+type FxRateDataPoint struct {
+	Date   string  `json:"date"`
+	Value  float64 `json:"value"`
+	Unit   int32   `json:"unit"`             // 1 when Value is normalized per unit, otherwise the quoted unit (e.g. 100 for JPY)
+	Filled bool    `json:"filled,omitempty"` // True when the value was carried forward from an earlier trading day
+	Source string  `json:"source,omitempty"` // Provider the rate came from (bnm unless a fallback was used)
+}
+
+func (d FxRateDataPoint) point() analytics.Point {
+	return datedPoint(d.Date, d.Value)
 }
 
-// handleGetFxRates handles requests for foreign exchange rate data
+// handleGetFxRates handles requests for foreign exchange rate data.
+// Values are MYR per 1 unit of the currency unless normalize=false is given, in which case
+// the rate is returned as quoted by the source (e.g. MYR per 100 JPY).
+// fill=previous forward-fills weekends and holidays with the last available rate; fill=none (default) leaves gaps.
+// session selects the BNM publication session (0900, 1200 or 1700); it defaults to 1200.
+// transform=real deflates the MYR rates by CPI, in prices of cpi_base=YYYY-MM (default: the index base).
+// points=N downsamples the rates (after filling and deflating) to N points with LTTB.
+// interval=monthly returns the average per-unit rate of each month, dated on the first, from
+// the monthly_fx_rates view (refreshed by db:maintenance).
 func (s *apiServer) handleGetFxRates(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	queryParams := r.URL.Query()
-	currencyCode := queryParams.Get("code") // e.g., "USD"
-	startDateStr := queryParams.Get("start_date")
-	endDateStr := queryParams.Get("end_date")
-
-	if currencyCode == "" || startDateStr == "" || endDateStr == "" {
-		http.Error(w, "Missing required query parameters: code, start_date, end_date", http.StatusBadRequest)
-		return
+	p := s.params(r)
+	currencyCode := p.currency("code", true) // e.g., "USD"
+	if currencyCode == baseCurrency {
+		p.fail("code", "rates are quoted in MYR; use a foreign currency")
 	}
-
-	// Basic validation for currency code format (adjust as needed)
-	if len(currencyCode) != 3 {
-		http.Error(w, "Invalid currency code format (must be 3 letters)", http.StatusBadRequest)
-		return
+	startDate, endDate := p.dateRange(time.Time{}, true)
+	normalize := p.boolean("normalize", true)
+	session := p.enum("session", "1200", fxprovider.Sessions...)
+	fill := p.enum("fill", "none", "none", "previous")
+	realValues, cpiBase := realTransform(p)
+	points := chartPoints(p)
+	interval := p.enum("interval", "daily", "daily", "monthly")
+	if interval == "monthly" && !normalize {
+		p.fail("normalize", "interval=monthly averages are always per unit")
 	}
-
-	startDate, err := time.Parse("2006-01-02", startDateStr)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid start_date format (use YYYY-MM-DD): %v", err), http.StatusBadRequest)
+	if interval == "monthly" && fill != "none" {
+		p.fail("fill", "interval=monthly has no gaps to fill")
+	}
+	if !p.ok(w) {
 		return
 	}
-	endDate, err := time.Parse("2006-01-02", endDateStr)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid end_date format (use YYYY-MM-DD): %v", err), http.StatusBadRequest)
+	startDateStr, endDateStr := startDate.Format("2006-01-02"), endDate.Format("2006-01-02")
+
+	deflator, ok := s.loadRealTransform(w, r, realValues, cpiBase, startDate, endDate)
+	if !ok {
 		return
 	}
 
@@ -231,42 +390,163 @@ func (s *apiServer) handleGetFxRates(w http.ResponseWriter, r *http.Request) {
 		CurrencyCode: currencyCode,
 		StartDate:    startDate,
 		EndDate:      endDate,
+		Session:      session,
 	}
 
-	log.Printf("API: Querying FX rates for %s from %s to %s", currencyCode, startDateStr, endDateStr)
-	dbResults, err := s.state.db.GetForeignExchangeByCurrencyAndDateRange(r.Context(), dbParams)
+	log.Printf("API: Querying %s FX rates for %s (session %s) from %s to %s", interval, currencyCode, session, startDateStr, endDateStr)
+	var dbResults []database.GetForeignExchangeByCurrencyAndDateRangeRow
+	var err error
+	if interval == "monthly" {
+		dbResults, err = loadMonthlyFxRates(r.Context(), s.state, currencyCode, session, startDate, endDate)
+	} else {
+		dbResults, err = s.state.db.GetForeignExchangeByCurrencyAndDateRange(r.Context(), dbParams)
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("API: No FX rate data found for %s between %s and %s", currencyCode, startDateStr, endDateStr)
-			sendJsonResponse(w, []TimeSeriesDataPoint{}) // Send empty array
+			sendJsonResponse(w, []FxRateDataPoint{}) // Send empty array
 			return
 		}
 		log.Printf("API Error: Database error fetching FX rates for %s: %v", currencyCode, err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		errreport.CaptureError(r.Context(), err, map[string]string{"currency": currencyCode})
+		sendInternalError(w)
 		return
 	}
 
 	// --- Format Response ---
-	response := make([]TimeSeriesDataPoint, 0, len(dbResults))
+	response := make([]FxRateDataPoint, 0, len(dbResults))
 	for _, dbRow := range dbResults {
-		// *** CRUCIAL: Decide which rate to use (Middle, Buying, Selling?) ***
-		// Using MiddleRate as an example here. Adjust if needed.
+		// Using the middle rate; normalized per 1 unit by default so per-100 quotes (JPY, IDR...) line up with the rest
+		rateStr, unit := dbRow.MiddleRatePerUnit, int32(1)
+		if !normalize {
+			rateStr, unit = dbRow.MiddleRate, dbRow.Unit
+		}
 
-		value, err := strconv.ParseFloat(dbRow.MiddleRate, 64)
+		value, err := strconv.ParseFloat(rateStr, 64)
 		if err != nil {
-			log.Printf("Error parsing MiddleRate: %v", err)
+			log.Printf("Error parsing middle rate: %v", err)
 			// Handle the error, e.g., skip this row or return an error
 			continue
 		}
-		// Also assumes MiddleRate is float64 (or convertible) from sqlc
-		response = append(response, TimeSeriesDataPoint{
-			Date:  dbRow.Date.Format("2006-01-02"), // Use the 'date' column from foreign_exchange
-			Value: value,                           // Use the desired rate column
+		response = append(response, FxRateDataPoint{
+			Date:   dbRow.Date.Format("2006-01-02"), // Use the 'date' column from foreign_exchange
+			Value:  value,
+			Unit:   unit,
+			Source: dbRow.Source,
 		})
 	}
 
 	log.Printf("API: Found %d FX rate records for %s", len(response), currencyCode)
-	sendJsonResponse(w, response)
+
+	if fill == "previous" {
+		// Seed with the last rate before the range so leading non-trading days can be filled too
+		var seed *FxRateDataPoint
+		prior, err := s.state.db.GetLatestForeignExchangeOnOrBefore(r.Context(), database.GetLatestForeignExchangeOnOrBeforeParams{
+			CurrencyCode: currencyCode,
+			OnDate:       startDate.AddDate(0, 0, -1),
+			Session:      session,
+		})
+		if err == nil {
+			rateStr, unit := prior.MiddleRatePerUnit, int32(1)
+			if !normalize {
+				rateStr, unit = prior.MiddleRate, prior.Unit
+			}
+			if value, convErr := strconv.ParseFloat(rateStr, 64); convErr == nil {
+				seed = &FxRateDataPoint{Value: value, Unit: unit}
+			}
+		} else if err != sql.ErrNoRows {
+			log.Printf("API Error: Failed to look up FX rate before %s for %s: %v", startDateStr, currencyCode, err)
+			errreport.CaptureError(r.Context(), err, map[string]string{"currency": currencyCode})
+		}
+		response = forwardFillFxRates(response, seed, startDate, endDate)
+	}
+
+	if deflator != nil {
+		deflated := make([]FxRateDataPoint, 0, len(response))
+		for _, point := range response {
+			date, _ := markettime.ParseDate(point.Date)
+			if value, ok := deflator.deflate(date, point.Value); ok {
+				point.Value = value
+				deflated = append(deflated, point)
+			}
+		}
+		response = deflated
+	}
+
+	sendJsonResponse(w, downsample(response, points, FxRateDataPoint.point))
+}
+
+// forwardFillFxRates returns one point per calendar day from start to end, carrying the last
+// observed rate across days without data. Days before any observation (and without a seed) are omitted.
+func forwardFillFxRates(points []FxRateDataPoint, seed *FxRateDataPoint, start, end time.Time) []FxRateDataPoint {
+	byDate := make(map[string]FxRateDataPoint, len(points))
+	for _, p := range points {
+		byDate[p.Date] = p
+	}
+
+	last := seed
+	filled := make([]FxRateDataPoint, 0, int(end.Sub(start).Hours()/24)+1)
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		dateStr := d.Format("2006-01-02")
+		if p, ok := byDate[dateStr]; ok {
+			filled = append(filled, p)
+			last = &p
+			continue
+		}
+		if last != nil {
+			filled = append(filled, FxRateDataPoint{Date: dateStr, Value: last.Value, Unit: last.Unit, Filled: true, Source: last.Source})
+		}
+	}
+	return filled
+}
+
+// handleGetFxEffectiveRates serves the stored trade-weighted effective exchange rate index.
+// Only the nominal index is computed (see computeEffectiveExchangeRates), so type accepts neer
+// alone, the default; type=reer is rejected rather than answered with the nominal index.
+// points=N downsamples the index to N points with LTTB.
+func (s *apiServer) handleGetFxEffectiveRates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	p := s.params(r)
+	indexType := p.enum("type", "neer", "neer")
+	startDate, endDate := p.dateRange(time.Time{}, true)
+	points := chartPoints(p)
+	if !p.ok(w) {
+		return
+	}
+	startDateStr, endDateStr := startDate.Format("2006-01-02"), endDate.Format("2006-01-02")
+
+	log.Printf("API: Querying %s index from %s to %s", indexType, startDateStr, endDateStr)
+	dbResults, err := s.state.db.GetEffectiveExchangeRatesByDateRange(r.Context(), database.GetEffectiveExchangeRatesByDateRangeParams{
+		IndexType: indexType,
+		StartDate: startDate,
+		EndDate:   endDate,
+	})
+	if err != nil {
+		log.Printf("API Error: Database error fetching %s index: %v", indexType, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"index_type": indexType})
+		sendInternalError(w)
+		return
+	}
+
+	response := make([]TimeSeriesDataPoint, 0, len(dbResults))
+	for _, dbRow := range dbResults {
+		value, err := strconv.ParseFloat(dbRow.Value, 64)
+		if err != nil {
+			log.Printf("Error parsing %s value: %v", indexType, err)
+			continue
+		}
+		response = append(response, TimeSeriesDataPoint{
+			Date:  dbRow.Date.Format("2006-01-02"),
+			Value: value,
+		})
+	}
+
+	log.Printf("API: Found %d %s records", len(response), indexType)
+	sendJsonResponse(w, downsample(response, points, TimeSeriesDataPoint.point))
 }
 
 // --- Helper function to send JSON response ---
@@ -279,6 +559,6 @@ func sendJsonResponse(w http.ResponseWriter, data interface{}) {
 	if err != nil {
 		log.Printf("API Error: Failed to encode JSON response: %v", err)
 		// Attempt to send an internal error, though headers might be sent
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		sendInternalError(w)
 	}
 }
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/auth"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/google/uuid"
+)
+
+// adminFetchCommands are the CLI fetch handlers that may be triggered through POST /api/admin/fetch.
+var adminFetchCommands = map[string]func(*AppState, command) error{
+	"fx:fetch_all":            handlerFxFetchAll,
+	"fx:fetch:range":          handlerFxFetchRange,
+	"fx:eer:compute":          handlerFxEerCompute,
+	"macro:fetch":             handlerMacroFetch,
+	"market:holidays:fetch":   handlerMarketHolidaysFetch,
+	"news:fetch":              handlerNewsFetch,
+	"alerts:evaluate":         handlerAlertsEvaluate,
+	"returns:compute":         handlerReturnsCompute,
+	"volatility:compute":      handlerVolatilityCompute,
+	"correlation:compute":     handlerCorrelationCompute,
+	"baskets:compute":         handlerBasketsCompute,
+	"snapshot:export":         handlerSnapshotExport,
+	"data:check":              handlerDataCheck,
+	"documents:fetch":         handlerDocumentsFetch,
+	"entitlements:fetch":      handlerEntitlementsFetch,
+	"db:maintenance":          handlerDbMaintenance,
+	"digest:send":             handlerDigestSend,
+	"publish:run":             handlerPublishRun,
+	"sheets:push":             handlerSheetsPush,
+	"stock:fetch:price":       handlerStockFetchPrice,
+	"stock:fetch:price_all":   handlerStockFetchPriceAll,
+	"stock:fetch:profile":     handlerStockFetchProfile,
+	"stock:fetch:profile_all": handlerStockFetchPriceAllAndProfiles,
+	"stock:fetch:ratios":      handlerStockFetchRatios,
+}
+
+// Structure for an audit log entry returned to the frontend
+type AuditLogResponse struct {
+	ID         int64     `json:"id"`
+	Username   string    `json:"username"`
+	Action     string    `json:"action"`
+	Details    string    `json:"details,omitempty"`
+	Source     string    `json:"source"`
+	RemoteAddr string    `json:"remote_addr,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Structure for a batch fetch run returned to the frontend
+type FetchRunResponse struct {
+	ID                string     `json:"id"`
+	Command           string     `json:"command"`
+	Args              string     `json:"args,omitempty"`
+	Status            string     `json:"status"`
+	StartedAt         time.Time  `json:"started_at"`
+	FinishedAt        *time.Time `json:"finished_at,omitempty"`
+	SuccessfulFetches int32      `json:"successful_fetches"`
+	FailedFetches     int32      `json:"failed_fetches"`
+	SuccessfulStores  int32      `json:"successful_stores"`
+	FailedStores      int32      `json:"failed_stores"`
+	ErrorSamples      []string   `json:"error_samples"`
+}
+
+type adminFetchRequest struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+}
+
+// Structure for a tracked stock or currency returned to the admin page
+type TrackedInstrumentResponse struct {
+	Type    string     `json:"type"` // stock or fx
+	Code    string     `json:"code"`
+	Country string     `json:"country"`
+	Source  string     `json:"source"` // config (STOCK_LIST) or tracked (added at runtime)
+	AddedBy string     `json:"added_by,omitempty"`
+	AddedAt *time.Time `json:"added_at,omitempty"`
+}
+
+type adminTrackedRequest struct {
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+	Country string `json:"country"` // DEFAULT_COUNTRY when empty
+}
+
+type adminRetryRequest struct {
+	ID string `json:"id"`
+}
+
+type adminUserRoleRequest struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// registerAdminRoutes adds the /api/admin endpoints. All of them require the admin role.
+func (s *apiServer) registerAdminRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/api/admin/fetch", s.requireRole(auth.RoleAdmin, s.handleAdminFetch))
+	mux.HandleFunc("/api/admin/users", s.requireRole(auth.RoleAdmin, s.handleAdminUsers))
+	mux.HandleFunc("/api/admin/users/role", s.requireRole(auth.RoleAdmin, s.handleAdminUserRole))
+	mux.HandleFunc("/api/admin/audit", s.requireRole(auth.RoleAdmin, s.handleAdminAudit))
+	mux.HandleFunc("/api/admin/runs", s.requireRole(auth.RoleAdmin, s.handleAdminRuns))
+	mux.HandleFunc("/api/admin/runs/retry", s.requireRole(auth.RoleAdmin, s.handleAdminRunRetry))
+	mux.HandleFunc("/api/admin/tracked", s.requireRole(auth.RoleAdmin, s.handleAdminTracked))
+}
+
+// handleAdminFetch starts one of the CLI fetch commands in the background.
+// Request body: {"command": "fx:fetch:range", "args": ["USD", "2024-01-01", "2024-01-31"]}
+// Fetches can take much longer than the server's write timeout, so the request returns 202
+// immediately and the outcome is logged.
+func (s *apiServer) handleAdminFetch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req adminFetchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	handler, ok := adminFetchCommands[req.Command]
+	if !ok {
+		http.Error(w, "Unknown fetch command", http.StatusBadRequest)
+		return
+	}
+
+	user, _ := userFromContext(r.Context())
+	s.startAdminCommand(w, r, user, command{Name: req.Command, Args: req.Args}, handler)
+}
+
+// startAdminCommand runs a fetch command in the background on behalf of user and responds
+// with 202. Fetches can take much longer than the server's write timeout, so the outcome is
+// only logged (and recorded in fetch_runs by the batch commands).
+func (s *apiServer) startAdminCommand(w http.ResponseWriter, r *http.Request, user database.User, cmd command, handler func(*AppState, command) error) {
+	if s.ctx.Err() != nil {
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	// The fetch outlives the request, so it runs under the application's work context instead,
+	// and shutdown waits for it
+	ctx, cancel := withCommandTimeout(s.state.workCtx, s.state)
+	cmd.ctx = ctx
+	log.Printf("API: %s triggered %s %s", user.Username, cmd.Name, strings.Join(cmd.Args, " "))
+	recordAudit(r.Context(), s.state, user, auditSourceAPI, cmd.Name, strings.Join(cmd.Args, " "), r.RemoteAddr)
+	runBackground(s.state, func() {
+		defer cancel()
+		err := runRecovered(cmd.Name, func() error { return handler(s.state, cmd) })
+		if err != nil {
+			log.Printf("API Error: %s triggered by %s failed: %v", cmd.Name, user.Username, err)
+			return
+		}
+		log.Printf("API: %s triggered by %s finished", cmd.Name, user.Username)
+	})
+
+	w.Header().Set("Content-Type", "application/json") // Must be set before WriteHeader
+	w.WriteHeader(http.StatusAccepted)
+	sendJsonResponse(w, map[string]string{"status": "started", "command": cmd.Name})
+}
+
+// handleAdminUsers lists users (GET) or deletes one (DELETE ?username=).
+func (s *apiServer) handleAdminUsers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		users, err := s.state.db.ListUsers(r.Context())
+		if err != nil {
+			log.Printf("API Error: Failed to list users: %v", err)
+			sendInternalError(w)
+			return
+		}
+		response := make([]UserResponse, 0, len(users))
+		for _, u := range users {
+			response = append(response, userResponseFromDB(u))
+		}
+		sendJsonResponse(w, response)
+
+	case http.MethodDelete:
+		p := s.params(r)
+		username := p.str("username", true)
+		if !p.ok(w) {
+			return
+		}
+		admin, _ := userFromContext(r.Context())
+		if username == admin.Username {
+			http.Error(w, "Cannot delete the authenticated user", http.StatusBadRequest)
+			return
+		}
+		n, err := s.state.db.DeleteUserByUsername(r.Context(), username)
+		if err != nil {
+			log.Printf("API Error: Failed to delete user %s: %v", username, err)
+			sendInternalError(w)
+			return
+		}
+		if n == 0 {
+			sendNotFound(w, "User not found")
+			return
+		}
+		log.Printf("API: %s deleted user %s", admin.Username, username)
+		recordAudit(r.Context(), s.state, admin, auditSourceAPI, "users:delete", username, r.RemoteAddr)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAdminUserRole changes a user's role.
+// Request body: {"username": "...", "role": "admin|editor|viewer"}
+func (s *apiServer) handleAdminUserRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req adminUserRoleRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	req.Role = strings.ToLower(req.Role)
+	if req.Username == "" || !auth.ValidRole(req.Role) {
+		http.Error(w, "Invalid request: username and role (admin, editor, viewer) are required", http.StatusBadRequest)
+		return
+	}
+	admin, _ := userFromContext(r.Context())
+	if req.Username == admin.Username && req.Role != auth.RoleAdmin {
+		http.Error(w, "Cannot remove your own admin role", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.state.db.UpdateUserRole(r.Context(), database.UpdateUserRoleParams{
+		Role:     req.Role,
+		Username: req.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			sendNotFound(w, "User not found")
+			return
+		}
+		log.Printf("API Error: Failed to update role for %s: %v", req.Username, err)
+		sendInternalError(w)
+		return
+	}
+	log.Printf("API: %s changed role of %s to %s", admin.Username, user.Username, user.Role)
+	recordAudit(r.Context(), s.state, admin, auditSourceAPI, "users:role", user.Username+" "+user.Role, r.RemoteAddr)
+	sendJsonResponse(w, userResponseFromDB(user))
+}
+
+// handleAdminAudit returns audit log entries, newest first.
+// Query: optional limit (default 50, max 1000), action and username filters.
+func (s *apiServer) handleAdminAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	params := database.ListAuditLogParams{MaxResults: int32(p.intBetween("limit", 50, 1, 1000))}
+	if !p.ok(w) {
+		return
+	}
+	if action := p.str("action", false); action != "" {
+		params.Action = sql.NullString{String: action, Valid: true}
+	}
+	if username := p.str("username", false); username != "" {
+		params.Username = sql.NullString{String: username, Valid: true}
+	}
+
+	entries, err := s.state.db.ListAuditLog(r.Context(), params)
+	if err != nil {
+		log.Printf("API Error: Failed to load audit log: %v", err)
+		sendInternalError(w)
+		return
+	}
+	response := make([]AuditLogResponse, 0, len(entries))
+	for _, e := range entries {
+		response = append(response, AuditLogResponse{
+			ID:         e.ID,
+			Username:   e.Username,
+			Action:     e.Action,
+			Details:    e.Details,
+			Source:     e.Source,
+			RemoteAddr: e.RemoteAddr,
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+	sendJsonResponse(w, response)
+}
+
+// handleAdminRuns returns batch fetch run summaries, newest first.
+// Query: optional limit (default 50, max 1000) and command filter (e.g. fx:fetch_all).
+func (s *apiServer) handleAdminRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	params := database.ListFetchRunsParams{MaxResults: int32(p.intBetween("limit", 50, 1, 1000))}
+	if !p.ok(w) {
+		return
+	}
+	if command := p.str("command", false); command != "" {
+		params.Command = sql.NullString{String: command, Valid: true}
+	}
+
+	runs, err := s.state.db.ListFetchRuns(r.Context(), params)
+	if err != nil {
+		log.Printf("API Error: Failed to load fetch runs: %v", err)
+		sendInternalError(w)
+		return
+	}
+	response := make([]FetchRunResponse, 0, len(runs))
+	for _, run := range runs {
+		response = append(response, fetchRunResponseFromDB(run))
+	}
+	sendJsonResponse(w, response)
+}
+
+// handleAdminRunRetry re-runs a failed or partial batch fetch with its original arguments.
+// Request body: {"id": "<fetch run id>"}
+func (s *apiServer) handleAdminRunRetry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req adminRetryRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	id, err := uuid.Parse(req.ID)
+	if err != nil {
+		http.Error(w, "Invalid run id", http.StatusBadRequest)
+		return
+	}
+	run, err := s.state.db.GetFetchRun(r.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			sendNotFound(w, "Fetch run not found")
+			return
+		}
+		log.Printf("API Error: Failed to load fetch run %s: %v", id, err)
+		sendInternalError(w)
+		return
+	}
+	if run.Status != fetchRunFailed && run.Status != fetchRunPartial {
+		http.Error(w, "Only failed or partial runs can be retried", http.StatusConflict)
+		return
+	}
+	handler, ok := adminFetchCommands[run.Command]
+	if !ok {
+		http.Error(w, "Fetch command cannot be triggered through the API", http.StatusBadRequest)
+		return
+	}
+
+	user, _ := userFromContext(r.Context())
+	s.startAdminCommand(w, r, user, command{Name: run.Command, Args: strings.Fields(run.Args)}, handler)
+}
+
+// handleAdminTracked lists the tracked stocks and currencies (GET ?country=), adds one (POST
+// {"type": "stock|fx", "code": "...", "country": "..."}) or removes one (DELETE ?type=&code=).
+func (s *apiServer) handleAdminTracked(w http.ResponseWriter, r *http.Request) {
+	admin, _ := userFromContext(r.Context())
+	switch r.Method {
+	case http.MethodGet:
+		p := s.params(r)
+		country := p.country("country")
+		if !p.ok(w) {
+			return
+		}
+		items, err := s.state.db.ListTrackedInstruments(r.Context())
+		if err != nil {
+			log.Printf("API Error: Failed to list tracked instruments: %v", err)
+			sendInternalError(w)
+			return
+		}
+		response := make([]TrackedInstrumentResponse, 0, len(s.state.cfg.StockList)+len(items))
+		if country == "" || country == s.state.cfg.DefaultCountry {
+			for _, code := range s.state.cfg.StockList {
+				response = append(response, TrackedInstrumentResponse{Type: watchlistStock, Code: code, Country: s.state.cfg.DefaultCountry, Source: "config"})
+			}
+		}
+		for _, item := range items {
+			if country != "" && item.CountryCode != country {
+				continue
+			}
+			response = append(response, TrackedInstrumentResponse{
+				Type:    item.ItemType,
+				Code:    item.Code,
+				Country: item.CountryCode,
+				Source:  "tracked",
+				AddedBy: item.AddedBy,
+				AddedAt: &item.AddedAt,
+			})
+		}
+		sendJsonResponse(w, response)
+
+	case http.MethodPost:
+		var req adminTrackedRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		itemType, code, added, err := addTrackedInstrument(r.Context(), s.state, req.Type, req.Code, req.Country, admin.Username)
+		if err != nil {
+			if itemType == "" {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			log.Printf("API Error: %v", err)
+			sendInternalError(w)
+			return
+		}
+		if added {
+			log.Printf("API: %s started tracking %s %s", admin.Username, itemType, code)
+			recordAudit(r.Context(), s.state, admin, auditSourceAPI, "tracked:add", itemType+" "+code, r.RemoteAddr)
+			w.Header().Set("Content-Type", "application/json") // Must be set before WriteHeader
+			w.WriteHeader(http.StatusCreated)
+		}
+		sendJsonResponse(w, map[string]string{"type": itemType, "code": code})
+
+	case http.MethodDelete:
+		p := s.params(r)
+		itemType, code := p.str("type", true), p.str("code", true)
+		if !p.ok(w) {
+			return
+		}
+		itemType, code, removed, err := removeTrackedInstrument(r.Context(), s.state, itemType, code)
+		if err != nil {
+			if itemType == "" {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			log.Printf("API Error: %v", err)
+			sendInternalError(w)
+			return
+		}
+		if !removed {
+			sendNotFound(w, "Instrument is not tracked")
+			return
+		}
+		log.Printf("API: %s stopped tracking %s %s", admin.Username, itemType, code)
+		recordAudit(r.Context(), s.state, admin, auditSourceAPI, "tracked:remove", itemType+" "+code, r.RemoteAddr)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func fetchRunResponseFromDB(run database.FetchRun) FetchRunResponse {
+	resp := FetchRunResponse{
+		ID:                run.ID.String(),
+		Command:           run.Command,
+		Args:              run.Args,
+		Status:            run.Status,
+		StartedAt:         run.StartedAt,
+		SuccessfulFetches: run.SuccessfulFetches,
+		FailedFetches:     run.FailedFetches,
+		SuccessfulStores:  run.SuccessfulStores,
+		FailedStores:      run.FailedStores,
+		ErrorSamples:      run.ErrorSamples,
+	}
+	if run.FinishedAt.Valid {
+		resp.FinishedAt = &run.FinishedAt.Time
+	}
+	return resp
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/auth"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+)
+
+// Structure for an alert rule returned to the frontend
+type AlertRuleResponse struct {
+	ID              string     `json:"id"`
+	Series          string     `json:"series"`
+	Change          string     `json:"change"` // level, or yoy or mom for macro series
+	Condition       string     `json:"condition"`
+	Threshold       float64    `json:"threshold"`
+	IsTriggered     bool       `json:"is_triggered"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty"`
+}
+
+// Structure for a triggered alert returned to the frontend
+type AlertEventResponse struct {
+	ID            string    `json:"id"`
+	RuleID        string    `json:"rule_id"`
+	Series        string    `json:"series"`
+	Change        string    `json:"change"`
+	Condition     string    `json:"condition"`
+	Threshold     float64   `json:"threshold"`
+	ObservedValue float64   `json:"observed_value"`
+	ObservedDate  string    `json:"observed_date"`
+	TriggeredAt   time.Time `json:"triggered_at"`
+}
+
+type alertRuleRequest struct {
+	Series    string  `json:"series" yaml:"series"`                     // e.g. "fx:USD", "stock:1155" or "macro:cpi"
+	Change    string  `json:"change,omitempty" yaml:"change,omitempty"` // level (default), or yoy or mom for macro series
+	Condition string  `json:"condition" yaml:"condition"`               // >, >=, < or <=
+	Threshold float64 `json:"threshold" yaml:"threshold"`
+}
+
+// handleGetAlerts returns the authenticated user's triggered alerts, newest first.
+// Query: optional limit (default 50, max 500).
+func (s *apiServer) handleGetAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _ := userFromContext(r.Context())
+
+	p := s.params(r)
+	limit := p.intBetween("limit", 50, 1, 500)
+	if !p.ok(w) {
+		return
+	}
+
+	events, err := s.state.db.ListAlertEventsByUser(r.Context(), database.ListAlertEventsByUserParams{
+		UserID:     user.ID,
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		log.Printf("API Error: Failed to load alerts for %s: %v", user.Username, err)
+		sendInternalError(w)
+		return
+	}
+	response := make([]AlertEventResponse, 0, len(events))
+	for _, e := range events {
+		response = append(response, alertEventResponseFromDB(e))
+	}
+	sendJsonResponse(w, response)
+}
+
+// handleAlertRules serves the authenticated user's alert rules.
+// GET lists rules; POST {"series": "fx:USD", "condition": ">", "threshold": 4.8} adds one
+// (with "change": "yoy" or "mom", a macro series' percent change is compared instead of its level);
+// PUT ?id= with the same body replaces one's condition and re-arms it; DELETE ?id= removes one.
+// Changing rules requires the editor role.
+func (s *apiServer) handleAlertRules(w http.ResponseWriter, r *http.Request) {
+	user, _ := userFromContext(r.Context())
+
+	switch r.Method {
+	case http.MethodGet:
+		rules, err := s.state.db.ListAlertRulesByUser(r.Context(), user.ID)
+		if err != nil {
+			log.Printf("API Error: Failed to load alert rules for %s: %v", user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		response := make([]AlertRuleResponse, 0, len(rules))
+		for _, rule := range rules {
+			response = append(response, alertRuleResponseFromDB(rule))
+		}
+		sendJsonResponse(w, response)
+
+	case http.MethodPost:
+		if !auth.RoleAllows(roleFromContext(r.Context()), auth.RoleEditor) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		var req alertRuleRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		params, err := newAlertRuleParams(r.Context(), s.state, user.ID, req.Series, req.Change, req.Condition, strconv.FormatFloat(req.Threshold, 'f', -1, 64))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rule, err := s.state.db.CreateAlertRule(r.Context(), params)
+		if err != nil {
+			log.Printf("API Error: Failed to create alert rule for %s: %v", user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		log.Printf("API: %s added alert rule %s (%s %s %s)", user.Username, rule.ID, alertLabel(rule.Series, rule.Change), rule.Condition, rule.Threshold)
+		sendJsonResponse(w, alertRuleResponseFromDB(rule))
+
+	case http.MethodPut:
+		if !auth.RoleAllows(roleFromContext(r.Context()), auth.RoleEditor) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		p := s.params(r)
+		id := p.uuid("id")
+		if !p.ok(w) {
+			return
+		}
+		var req alertRuleRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		rule, err := updateAlertRule(r.Context(), s.state, user.ID, id, req.Series, req.Change, req.Condition, strconv.FormatFloat(req.Threshold, 'f', -1, 64))
+		if err == sql.ErrNoRows {
+			sendNotFound(w, "Alert rule not found")
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("API: %s updated alert rule %s (%s %s %s)", user.Username, rule.ID, alertLabel(rule.Series, rule.Change), rule.Condition, rule.Threshold)
+		sendJsonResponse(w, alertRuleResponseFromDB(rule))
+
+	case http.MethodDelete:
+		if !auth.RoleAllows(roleFromContext(r.Context()), auth.RoleEditor) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		p := s.params(r)
+		id := p.uuid("id")
+		if !p.ok(w) {
+			return
+		}
+		n, err := s.state.db.DeleteAlertRule(r.Context(), database.DeleteAlertRuleParams{ID: id, UserID: user.ID})
+		if err != nil {
+			log.Printf("API Error: Failed to delete alert rule %s for %s: %v", id, user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		if n == 0 {
+			sendNotFound(w, "Alert rule not found")
+			return
+		}
+		log.Printf("API: %s removed alert rule %s", user.Username, id)
+		recordAudit(r.Context(), s.state, user, auditSourceAPI, "alerts:remove", id.String(), r.RemoteAddr)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAlertRulesExport returns the authenticated user's alert rules as a YAML document that
+// /api/alerts/rules/import restores, or as JSON with format=json.
+// Usage: GET /api/alerts/rules/export[?format=yaml|json]
+func (s *apiServer) handleAlertRulesExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _ := userFromContext(r.Context())
+	p := s.params(r)
+	format := p.enum("format", "yaml", "yaml", "json")
+	if !p.ok(w) {
+		return
+	}
+
+	doc, err := exportAlertRules(r.Context(), s.state, user.ID)
+	if err != nil {
+		log.Printf("API Error: Failed to export alert rules for %s: %v", user.Username, err)
+		sendInternalError(w)
+		return
+	}
+	if format == "json" {
+		sendJsonResponse(w, doc)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Header().Set("Content-Disposition", `attachment; filename="alert-rules.yaml"`)
+	if err := encodeAlertRulesDocument(w, doc); err != nil {
+		log.Printf("API Error: Failed to encode alert rules for %s: %v", user.Username, err)
+	}
+}
+
+// handleAlertRulesImport adds the rules of an exported YAML (or JSON) document to the
+// authenticated user's alert rules, skipping those already present; mode=replace removes the
+// user's other rules so the result matches the document. An invalid rule rejects the whole
+// document. Requires the editor role.
+// Usage: POST /api/alerts/rules/import[?mode=merge|replace]
+func (s *apiServer) handleAlertRulesImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !auth.RoleAllows(roleFromContext(r.Context()), auth.RoleEditor) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	user, _ := userFromContext(r.Context())
+	p := s.params(r)
+	mode := p.enum("mode", "merge", "merge", "replace")
+	if !p.ok(w) {
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	var result AlertImportResult
+	doc, err := parseAlertRulesDocument(body)
+	if err == nil {
+		result, err = importAlertRules(r.Context(), s.state, user.ID, doc, mode == "replace")
+	}
+	if errors.Is(err, errInvalidAlertImport) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("API Error: Failed to import alert rules for %s: %v", user.Username, err)
+		sendInternalError(w)
+		return
+	}
+	log.Printf("API: %s imported alert rules (%s): %d added, %d skipped, %d removed", user.Username, mode, result.Added, result.Skipped, result.Removed)
+	sendJsonResponse(w, result)
+}
+
+func alertRuleResponseFromDB(rule database.AlertRule) AlertRuleResponse {
+	threshold, _ := strconv.ParseFloat(rule.Threshold, 64)
+	resp := AlertRuleResponse{
+		ID:          rule.ID.String(),
+		Series:      rule.Series,
+		Change:      rule.Change,
+		Condition:   rule.Condition,
+		Threshold:   threshold,
+		IsTriggered: rule.IsTriggered,
+	}
+	if rule.LastTriggeredAt.Valid {
+		resp.LastTriggeredAt = &rule.LastTriggeredAt.Time
+	}
+	return resp
+}
+
+func alertEventResponseFromDB(e database.AlertEvent) AlertEventResponse {
+	threshold, _ := strconv.ParseFloat(e.Threshold, 64)
+	observed, _ := strconv.ParseFloat(e.ObservedValue, 64)
+	return AlertEventResponse{
+		ID:            e.ID.String(),
+		RuleID:        e.RuleID.String(),
+		Series:        e.Series,
+		Change:        e.Change,
+		Condition:     e.Condition,
+		Threshold:     threshold,
+		ObservedValue: observed,
+		ObservedDate:  e.ObservedDate.Format("2006-01-02"),
+		TriggeredAt:   e.TriggeredAt,
+	}
+}
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// parseAnalyticsQuery reads the series, start_date and end_date parameters shared by the
+// analytics endpoints. The dates are optional and default to all stored history up to today.
+func parseAnalyticsQuery(p *queryParams) (key seriesKey, start, end time.Time) {
+	key = p.series("series", true) // e.g., "stock:1155" or "fx:USD"
+	start, end = p.dateRange(returnsEpoch, false)
+	return key, start, end
+}
+
+// realTransform reads the transform and cpi_base parameters of the time-series endpoints,
+// reporting whether CPI-deflated values were asked for and in prices of which month
+// (the zero time for the index base).
+func realTransform(p *queryParams) (realValues bool, base time.Time) {
+	realValues = p.enum("transform", "nominal", "nominal", "real") == "real"
+	return realValues, p.month("cpi_base")
+}
+
+// maxChartPoints caps points=N; past it downsampling saves little over the full series.
+const maxChartPoints = 10000
+
+// chartPoints reads the points parameter of the time-series endpoints: the number of points
+// the series is downsampled to with LTTB, or 0 (the default) for the full series.
+func chartPoints(p *queryParams) int {
+	return p.intBetween("points", 0, 3, maxChartPoints)
+}
+
+// downsample keeps n items of a series sorted oldest first, chosen by LTTB on the point each
+// item maps to, so charts of long ranges stay faithful at a fixed size. n of 0 keeps them all.
+func downsample[T any](items []T, n int, point func(T) analytics.Point) []T {
+	if n == 0 || len(items) <= n {
+		return items
+	}
+	points := make([]analytics.Point, len(items))
+	for i, item := range items {
+		points[i] = point(item)
+	}
+	indices := analytics.LTTB(points, n)
+	kept := make([]T, len(indices))
+	for i, idx := range indices {
+		kept[i] = items[idx]
+	}
+	return kept
+}
+
+// loadRealTransform returns the CPI deflator for transform=real, or nil for nominal values.
+// It writes an error response and returns ok=false when CPI is missing or cannot be loaded.
+func (s *apiServer) loadRealTransform(w http.ResponseWriter, r *http.Request, realValues bool, base, start, end time.Time) (d *cpiDeflator, ok bool) {
+	if !realValues {
+		return nil, true
+	}
+	d, err := newCPIDeflator(r.Context(), s.state, start, end, base)
+	if errors.Is(err, errNoCPI) {
+		sendNotFound(w, err.Error())
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("API Error: Failed to load CPI for transform=real: %v", err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"transform": "real"})
+		sendInternalError(w)
+		return nil, false
+	}
+	return d, true
+}
+
+// handleGetReturns serves stored daily percentage returns of a stock or currency.
+// GET /api/analytics/returns?series=stock:1155[&start_date=2024-01-01&end_date=2024-12-31][&points=500]
+func (s *apiServer) handleGetReturns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	key, start, end := parseAnalyticsQuery(p)
+	points := chartPoints(p)
+	if !p.ok(w) {
+		return
+	}
+
+	log.Printf("API: Querying daily returns for %s from %s to %s", key, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	rows, err := s.state.db.GetDailyReturnsBySeriesAndDateRange(r.Context(), database.GetDailyReturnsBySeriesAndDateRangeParams{
+		Series:    key.String(),
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		log.Printf("API Error: Database error fetching daily returns for %s: %v", key, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"series": key.String()})
+		sendInternalError(w)
+		return
+	}
+
+	response := make([]TimeSeriesDataPoint, 0, len(rows))
+	for _, row := range rows {
+		value, err := strconv.ParseFloat(row.PctReturn, 64)
+		if err != nil {
+			log.Printf("Error parsing daily return: %v", err)
+			continue
+		}
+		response = append(response, TimeSeriesDataPoint{Date: row.Date.Format("2006-01-02"), Value: value})
+	}
+	sendJsonResponse(w, downsample(response, points, TimeSeriesDataPoint.point))
+}
+
+// Structure for one day of aggregate news sentiment returned to the frontend
+type SentimentDataPoint struct {
+	Date      string  `json:"date"`
+	Value     float64 `json:"value"`     // Mean headline sentiment, -1 to +1
+	Headlines int64   `json:"headlines"` // Headlines published that day
+}
+
+func (d SentimentDataPoint) point() analytics.Point {
+	return datedPoint(d.Date, d.Value)
+}
+
+// handleGetSentiment serves the daily mean sentiment of the stored headlines about a stock,
+// for the days with at least one headline, to compare with its returns.
+// GET /api/analytics/sentiment?series=stock:1155[&start_date=2024-01-01&end_date=2024-12-31][&points=500]
+func (s *apiServer) handleGetSentiment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	key, start, end := parseAnalyticsQuery(p)
+	if key.Kind != "" && key.Kind != "stock" {
+		p.fail("series", "news sentiment is only available for stock series")
+	}
+	points := chartPoints(p)
+	if !p.ok(w) {
+		return
+	}
+
+	log.Printf("API: Querying news sentiment for %s from %s to %s", key, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	rows, err := s.state.db.GetDailyNewsSentimentByStockCode(r.Context(), database.GetDailyNewsSentimentByStockCodeParams{
+		StockCode: key.Code,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		log.Printf("API Error: Database error fetching news sentiment for %s: %v", key, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"series": key.String()})
+		sendInternalError(w)
+		return
+	}
+
+	response := make([]SentimentDataPoint, 0, len(rows))
+	for _, row := range rows {
+		value, err := strconv.ParseFloat(row.MeanSentiment, 64)
+		if err != nil {
+			log.Printf("Error parsing news sentiment: %v", err)
+			continue
+		}
+		response = append(response, SentimentDataPoint{Date: row.Date.Format("2006-01-02"), Value: value, Headlines: row.Headlines})
+	}
+	sendJsonResponse(w, downsample(response, points, SentimentDataPoint.point))
+}
+
+// handleGetVolatility serves stored rolling volatility of a stock or currency.
+// GET /api/analytics/volatility?series=fx:USD[&window=60][&start_date=2024-01-01&end_date=2024-12-31][&points=500]
+// window is 20, 60 or 250 trading days (default 20).
+func (s *apiServer) handleGetVolatility(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	key, start, end := parseAnalyticsQuery(p)
+	windowStr := p.enum("window", "20", "20", "60", "250")
+	points := chartPoints(p)
+	if !p.ok(w) {
+		return
+	}
+	window, _ := strconv.Atoi(windowStr)
+
+	log.Printf("API: Querying %d-day volatility for %s from %s to %s", window, key, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	rows, err := s.state.db.GetRollingVolatilityBySeriesAndDateRange(r.Context(), database.GetRollingVolatilityBySeriesAndDateRangeParams{
+		Series:     key.String(),
+		WindowDays: int32(window),
+		StartDate:  start,
+		EndDate:    end,
+	})
+	if err != nil {
+		log.Printf("API Error: Database error fetching volatility for %s: %v", key, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"series": key.String()})
+		sendInternalError(w)
+		return
+	}
+
+	response := make([]TimeSeriesDataPoint, 0, len(rows))
+	for _, row := range rows {
+		value, err := strconv.ParseFloat(row.Volatility, 64)
+		if err != nil {
+			log.Printf("Error parsing volatility: %v", err)
+			continue
+		}
+		response = append(response, TimeSeriesDataPoint{Date: row.Date.Format("2006-01-02"), Value: value})
+	}
+	sendJsonResponse(w, downsample(response, points, TimeSeriesDataPoint.point))
+}
+
+// Structure for a stock's rolling beta returned to the frontend
+type StockBetaResponse struct {
+	Code      string                `json:"code"`
+	Benchmark string                `json:"benchmark"` // Series key of the index, e.g. stock:FBMKLCI
+	Window    int                   `json:"window"`    // Trading days in each regression
+	Latest    *float64              `json:"latest"`    // Most recent beta in the range, null if none
+	Series    []TimeSeriesDataPoint `json:"series"`
+}
+
+// handleGetStockBeta serves the rolling beta of a stock against the configured benchmark index,
+// computed from stored daily returns.
+// GET /api/stock/beta?code=1155[&start_date=2024-01-01&end_date=2024-12-31][&window=250]
+// The range defaults to the year ending on end_date (today when omitted).
+func (s *apiServer) handleGetStockBeta(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	code := p.stockCode("code", true)
+	end := p.date("end_date", markettime.Today(), false)
+	start, end := p.dateRange(end.AddDate(-1, 0, 0), false)
+	window := p.intBetween("window", s.state.cfg.BetaWindow, 20, 1000)
+	if !p.ok(w) {
+		return
+	}
+
+	benchmark, err := parseSeriesKey(s.state.cfg.BetaBenchmark)
+	if err != nil {
+		log.Printf("API Error: Invalid beta benchmark %q: %v", s.state.cfg.BetaBenchmark, err)
+		sendInternalError(w)
+		return
+	}
+	stock := seriesKey{Kind: watchlistStock, Code: code}
+
+	// Twice the window in calendar days covers weekends and holidays before the first date
+	loadStart := start.AddDate(0, 0, -2*window)
+	log.Printf("API: Computing %d-day beta of %s against %s from %s to %s", window, stock, benchmark, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	benchReturns, err := loadDailyReturns(r.Context(), s.state, benchmark.String(), loadStart, end)
+	if err == nil && len(benchReturns) == 0 {
+		sendNotFound(w, fmt.Sprintf("No returns stored for benchmark %s", benchmark))
+		return
+	}
+	var stockReturns []analytics.Point
+	if err == nil {
+		stockReturns, err = loadDailyReturns(r.Context(), s.state, stock.String(), loadStart, end)
+	}
+	if err != nil {
+		log.Printf("API Error: Database error loading returns for beta of %s: %v", stock, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"series": stock.String()})
+		sendInternalError(w)
+		return
+	}
+
+	response := StockBetaResponse{Code: code, Benchmark: benchmark.String(), Window: window, Series: []TimeSeriesDataPoint{}}
+	for _, b := range analytics.RollingBeta(stockReturns, benchReturns, window) {
+		if b.Date.Before(start) {
+			continue
+		}
+		response.Series = append(response.Series, TimeSeriesDataPoint{Date: b.Date.Format("2006-01-02"), Value: b.Value})
+	}
+	if n := len(response.Series); n > 0 {
+		response.Latest = &response.Series[n-1].Value
+	}
+	sendJsonResponse(w, response)
+}
+
+// Structure for a drawdown analysis returned to the frontend
+type DrawdownResponse struct {
+	Series              string              `json:"series"`
+	MaxDrawdown         float64             `json:"max_drawdown"` // Percent, <= 0
+	PeakDate            string              `json:"peak_date,omitempty"`
+	TroughDate          string              `json:"trough_date,omitempty"`
+	RecoveryDate        string              `json:"recovery_date,omitempty"` // Empty while not recovered
+	MaxDrawdownDays     int                 `json:"max_drawdown_days"`       // Peak to recovery (or to the last day) of max_drawdown
+	LongestDurationDays int                 `json:"longest_duration_days"`   // Longest time under water in any drawdown
+	Points              []DrawdownDataPoint `json:"points"`
+}
+
+// Structure for one drawdown observation returned to the frontend
+type DrawdownDataPoint struct {
+	Date       string  `json:"date"`
+	Value      float64 `json:"value"`
+	RunningMax float64 `json:"running_max"`
+	Drawdown   float64 `json:"drawdown"` // Percent below running_max
+}
+
+// point is the drawdown as a series point, which is what points=N downsampling preserves.
+func (d DrawdownDataPoint) point() analytics.Point {
+	return datedPoint(d.Date, d.Drawdown)
+}
+
+// handleGetDrawdown serves the running maximum and drawdown of a stock's closes or a
+// currency's noon rates, with the depth and timing of the deepest drawdown.
+// GET /api/analytics/drawdown?series=stock:1155[&start_date=2024-01-01&end_date=2024-12-31][&points=500]
+// points=N downsamples the points on their drawdown; the summary always covers every day.
+func (s *apiServer) handleGetDrawdown(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	key, start, end := parseAnalyticsQuery(p)
+	chart := chartPoints(p)
+	if !p.ok(w) {
+		return
+	}
+
+	log.Printf("API: Computing drawdown for %s from %s to %s", key, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	points, err := loadSeries(r.Context(), s.state, key, start, end)
+	if err != nil {
+		log.Printf("API Error: Database error loading %s for drawdown: %v", key, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"series": key.String()})
+		sendInternalError(w)
+		return
+	}
+	// loadSeries adds the last observation before start; the running maximum starts inside the range
+	if len(points) > 0 && points[0].Date.Before(start) {
+		points = points[1:]
+	}
+
+	series, stats := analytics.Drawdowns(points)
+	response := DrawdownResponse{
+		Series:              key.String(),
+		MaxDrawdown:         stats.MaxDrawdown,
+		MaxDrawdownDays:     stats.MaxDrawdownDays,
+		LongestDurationDays: stats.LongestDurationDays,
+		Points:              make([]DrawdownDataPoint, 0, len(series)),
+	}
+	if stats.MaxDrawdown < 0 {
+		response.PeakDate = stats.PeakDate.Format("2006-01-02")
+		response.TroughDate = stats.TroughDate.Format("2006-01-02")
+		if stats.RecoveryDate != nil {
+			response.RecoveryDate = stats.RecoveryDate.Format("2006-01-02")
+		}
+	}
+	for _, p := range series {
+		response.Points = append(response.Points, DrawdownDataPoint{
+			Date:       p.Date.Format("2006-01-02"),
+			Value:      p.Value,
+			RunningMax: p.RunningMax,
+			Drawdown:   p.Drawdown,
+		})
+	}
+	response.Points = downsample(response.Points, chart, DrawdownDataPoint.point)
+	sendJsonResponse(w, response)
+}
+
+// Structure for a correlation matrix returned to the frontend, suitable for a heatmap
+type CorrelationMatrixResponse struct {
+	Series       []string     `json:"series"`          // Row and column labels
+	Window       int          `json:"window"`          // Trading days correlated
+	AsOf         string       `json:"as_of,omitempty"` // Last date of the window
+	Matrix       [][]*float64 `json:"matrix"`          // null where two series overlap too little
+	Observations [][]int      `json:"observations"`    // Dates both series had a return
+}
+
+// handleGetCorrelation serves the correlation matrix of daily returns across stocks and
+// currencies. Without series it returns the matrix last stored by the correlation job.
+// GET /api/analytics/correlation[?series=stock:1155,stock:5347,fx:USD][&window=60][&end_date=2024-12-31]
+func (s *apiServer) handleGetCorrelation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	window := p.intBetween("window", s.state.cfg.CorrelationWindow, 10, 1000)
+	series := p.seriesList("series", maxCorrelationSeries)
+	p.limitCost("series", len(series), window)
+	end := p.date("end_date", markettime.Today(), false)
+	if len(series) == 0 && p.has("end_date") {
+		p.fail("end_date", "requires the series parameter")
+	}
+	if !p.ok(w) {
+		return
+	}
+
+	var m correlationMatrix
+	var err error
+	if len(series) == 0 {
+		log.Printf("API: Loading stored %d-day correlation matrix", window)
+		m, err = storedCorrelationMatrix(r.Context(), s.state, window)
+	} else {
+		log.Printf("API: Computing %d-day correlation matrix of %d series to %s", window, len(series), end.Format("2006-01-02"))
+		m, err = computeCorrelationMatrix(r.Context(), s.state, series, window, end)
+	}
+	if err != nil {
+		log.Printf("API Error: Failed to build correlation matrix: %v", err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"endpoint": "correlation"})
+		sendInternalError(w)
+		return
+	}
+
+	response := CorrelationMatrixResponse{
+		Series:       m.Series,
+		Window:       m.Window,
+		Matrix:       m.Matrix,
+		Observations: m.Observations,
+	}
+	if response.Series == nil {
+		response.Series, response.Matrix, response.Observations = []string{}, [][]*float64{}, [][]int{}
+	}
+	if !m.AsOf.IsZero() {
+		response.AsOf = m.AsOf.Format("2006-01-02")
+	}
+	sendJsonResponse(w, response)
+}
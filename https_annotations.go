@@ -0,0 +1,69 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// annotationsEpoch is the default start_date of /api/annotations; it predates every stored event.
+var annotationsEpoch = time.Date(1957, 8, 31, 0, 0, 0, 0, markettime.Location)
+
+// Structure for a chart annotation (a macro event marked on price and FX charts)
+type AnnotationResponse struct {
+	ID          int32  `json:"id"`
+	Date        string `json:"date"` // YYYY-MM-DD
+	Country     string `json:"country"`
+	Category    string `json:"category"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// handleGetAnnotations returns the macro events between start_date and end_date (default: all
+// of them), optionally restricted to one category and country.
+// Usage: GET /api/annotations?start_date=&end_date=&category=opr|budget|election|other&country=MY
+func (s *apiServer) handleGetAnnotations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	start, end := p.dateRange(annotationsEpoch, false)
+	category := p.enum("category", "", annotationCategories...)
+	country := p.country("country")
+	if !p.ok(w) {
+		return
+	}
+
+	rows, err := s.state.db.ListAnnotationsBetween(r.Context(), database.ListAnnotationsBetweenParams{
+		StartDate:   start,
+		EndDate:     end,
+		CountryCode: sql.NullString{String: country, Valid: country != ""},
+	})
+	if err != nil {
+		log.Printf("API Error: Database error listing annotations: %v", err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"endpoint": "annotations"})
+		sendInternalError(w)
+		return
+	}
+	response := make([]AnnotationResponse, 0, len(rows))
+	for _, row := range rows {
+		if category != "" && row.Category != category {
+			continue
+		}
+		response = append(response, AnnotationResponse{
+			ID:          row.ID,
+			Date:        row.EventDate.Format("2006-01-02"),
+			Country:     row.CountryCode,
+			Category:    row.Category,
+			Title:       row.Title,
+			Description: row.Description,
+		})
+	}
+	sendJsonResponse(w, response)
+}
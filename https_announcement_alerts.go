@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/auth"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+)
+
+// Structure for an announcement alert rule returned to the frontend
+type AnnouncementAlertRuleResponse struct {
+	ID        string    `json:"id"`
+	Category  string    `json:"category"`
+	StockCode string    `json:"stock_code,omitempty"` // Empty when the rule watches the user's watchlist
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Structure for a triggered announcement alert returned to the frontend
+type AnnouncementAlertEventResponse struct {
+	ID          string    `json:"id"`
+	RuleID      string    `json:"rule_id"`
+	StockCode   string    `json:"stock_code"`
+	Category    string    `json:"category"`
+	Title       string    `json:"title"`
+	Link        string    `json:"link,omitempty"`
+	AnnouncedAt time.Time `json:"announced_at"`
+	TriggeredAt time.Time `json:"triggered_at"`
+}
+
+type announcementAlertRuleRequest struct {
+	Category  string `json:"category"`   // any, results, dividend, corporate_action or merger
+	StockCode string `json:"stock_code"` // Optional; omit to watch every stock on your watchlist
+}
+
+// handleGetAnnouncementAlerts returns the authenticated user's triggered announcement alerts,
+// newest first.
+// Query: optional limit (default 50, max 500).
+func (s *apiServer) handleGetAnnouncementAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _ := userFromContext(r.Context())
+
+	p := s.params(r)
+	limit := p.intBetween("limit", 50, 1, 500)
+	if !p.ok(w) {
+		return
+	}
+
+	events, err := s.state.db.ListAnnouncementAlertEventsByUser(r.Context(), database.ListAnnouncementAlertEventsByUserParams{
+		UserID:     user.ID,
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		log.Printf("API Error: Failed to load announcement alerts for %s: %v", user.Username, err)
+		sendInternalError(w)
+		return
+	}
+	response := make([]AnnouncementAlertEventResponse, 0, len(events))
+	for _, e := range events {
+		response = append(response, announcementAlertEventResponseFromDB(e))
+	}
+	sendJsonResponse(w, response)
+}
+
+// handleAnnouncementAlertRules serves the authenticated user's announcement alert rules.
+// GET lists rules; POST {"category": "results", "stock_code": "1155"} adds one (without
+// stock_code the rule covers the user's watchlist); DELETE ?id= removes one. Changing rules
+// requires the editor role.
+func (s *apiServer) handleAnnouncementAlertRules(w http.ResponseWriter, r *http.Request) {
+	user, _ := userFromContext(r.Context())
+
+	switch r.Method {
+	case http.MethodGet:
+		rules, err := s.state.db.ListAnnouncementAlertRulesByUser(r.Context(), user.ID)
+		if err != nil {
+			log.Printf("API Error: Failed to load announcement alert rules for %s: %v", user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		response := make([]AnnouncementAlertRuleResponse, 0, len(rules))
+		for _, rule := range rules {
+			response = append(response, announcementAlertRuleResponseFromDB(rule))
+		}
+		sendJsonResponse(w, response)
+
+	case http.MethodPost:
+		if !auth.RoleAllows(roleFromContext(r.Context()), auth.RoleEditor) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		var req announcementAlertRuleRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		params, err := newAnnouncementAlertRuleParams(r.Context(), s.state, user.ID, req.Category, req.StockCode)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rule, err := s.state.db.CreateAnnouncementAlertRule(r.Context(), params)
+		if err != nil {
+			log.Printf("API Error: Failed to create announcement alert rule for %s: %v", user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		log.Printf("API: %s added announcement alert rule %s (%s on %s)", user.Username, rule.ID, rule.Category, announcementRuleScope(rule))
+		sendJsonResponse(w, announcementAlertRuleResponseFromDB(rule))
+
+	case http.MethodDelete:
+		if !auth.RoleAllows(roleFromContext(r.Context()), auth.RoleEditor) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		p := s.params(r)
+		id := p.uuid("id")
+		if !p.ok(w) {
+			return
+		}
+		n, err := s.state.db.DeleteAnnouncementAlertRule(r.Context(), database.DeleteAnnouncementAlertRuleParams{ID: id, UserID: user.ID})
+		if err != nil {
+			log.Printf("API Error: Failed to delete announcement alert rule %s for %s: %v", id, user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		if n == 0 {
+			sendNotFound(w, "Announcement alert rule not found")
+			return
+		}
+		log.Printf("API: %s removed announcement alert rule %s", user.Username, id)
+		recordAudit(r.Context(), s.state, user, auditSourceAPI, "alerts:announcements:remove", id.String(), r.RemoteAddr)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func announcementAlertRuleResponseFromDB(rule database.AnnouncementAlertRule) AnnouncementAlertRuleResponse {
+	return AnnouncementAlertRuleResponse{
+		ID:        rule.ID.String(),
+		Category:  rule.Category,
+		StockCode: rule.StockCode.String,
+		CreatedAt: rule.CreatedAt,
+	}
+}
+
+func announcementAlertEventResponseFromDB(e database.AnnouncementAlertEvent) AnnouncementAlertEventResponse {
+	return AnnouncementAlertEventResponse{
+		ID:          e.ID.String(),
+		RuleID:      e.RuleID.String(),
+		StockCode:   e.StockCode,
+		Category:    e.Category,
+		Title:       e.Title,
+		Link:        e.Link,
+		AnnouncedAt: e.AnnouncedAt,
+		TriggeredAt: e.TriggeredAt,
+	}
+}
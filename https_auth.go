@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/auth"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+)
+
+// contextKey is used for values the auth middleware stores on the request context.
+type contextKey string
+
+const (
+	userContextKey contextKey = "user"
+	roleContextKey contextKey = "role"
+)
+
+// apiKeyHeader carries an API key for non-interactive clients, as an alternative to a bearer JWT.
+const apiKeyHeader = "X-API-Key"
+
+// Structure returned to the frontend for the authenticated user
+type UserResponse struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+}
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type loginResponse struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	User      UserResponse `json:"user"`
+}
+
+func userResponseFromDB(u database.User) UserResponse {
+	return UserResponse{ID: u.ID.String(), Username: u.Username, Email: u.Email, Role: u.Role}
+}
+
+// handleAuthLogin checks a username/password against the users table and issues a short-lived JWT.
+// Request body: {"username": "...", "password": "..."}
+func (s *apiServer) handleAuthLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.state.cfg.JWTSecret == "" {
+		http.Error(w, "API login is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req loginRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	if req.Username == "" || req.Password == "" {
+		http.Error(w, "Missing required fields: username, password", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.state.db.GetUserByUsername(r.Context(), req.Username)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("API Error: Failed to look up user %s: %v", req.Username, err)
+		sendInternalError(w)
+		return
+	}
+	hash := user.HashedPassword
+	if err == sql.ErrNoRows {
+		hash = auth.DummyPasswordHash // Same bcrypt work as a wrong password
+	}
+	if auth.CheckPasswordHash(req.Password, hash) != nil || err == sql.ErrNoRows {
+		log.Printf("API: Failed login for %s", req.Username)
+		user.Username = req.Username // Zero value when the user does not exist
+		recordAudit(r.Context(), s.state, user, auditSourceAPI, "login:failed", "", r.RemoteAddr)
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
+
+	token, expiresAt, err := auth.MakeJWT(user.ID, s.state.cfg.JWTSecret, s.state.cfg.JWTTTL)
+	if err != nil {
+		log.Printf("API Error: %v", err)
+		sendInternalError(w)
+		return
+	}
+
+	log.Printf("API: Issued token for %s (expires %s)", user.Username, expiresAt.Format(time.RFC3339))
+	recordAudit(r.Context(), s.state, user, auditSourceAPI, "login", "", r.RemoteAddr)
+	sendJsonResponse(w, loginResponse{Token: token, ExpiresAt: expiresAt, User: userResponseFromDB(user)})
+}
+
+// handleAuthMe returns the user the request's token or API key belongs to, with the effective role.
+func (s *apiServer) handleAuthMe(w http.ResponseWriter, r *http.Request) {
+	user, _ := userFromContext(r.Context())
+	resp := userResponseFromDB(user)
+	resp.Role = roleFromContext(r.Context()) // An API key's role may be below its owner's
+	sendJsonResponse(w, resp)
+}
+
+// requireAuth wraps a handler so it only runs for authenticated requests (any role).
+func (s *apiServer) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return s.requireRole(auth.RoleViewer, next)
+}
+
+// requireRole wraps a handler so it only runs for requests authenticated with a bearer JWT
+// or an API key whose role is at least required. For API keys the lesser of the key's role
+// and its owner's current role applies, so demoting a user also limits their keys. The user and effective role are available via userFromContext
+// and roleFromContext.
+func (s *apiServer) requireRole(required string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, role, status := s.authenticate(r)
+		if status != http.StatusOK {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		if !auth.RoleAllows(role, required) {
+			log.Printf("API: %s (%s) denied %s %s, requires %s", user.Username, role, r.Method, r.URL.Path, required)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		ctx := context.WithValue(r.Context(), userContextKey, user)
+		ctx = context.WithValue(ctx, roleContextKey, role)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// authenticate resolves the user and role for a request from its API key or bearer JWT.
+// It returns http.StatusOK on success, otherwise the status to respond with.
+func (s *apiServer) authenticate(r *http.Request) (database.User, string, int) {
+	ctx := r.Context()
+
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		apiKey, err := s.state.db.GetAPIKeyByHash(ctx, auth.HashToken(key))
+		if err != nil {
+			if err != sql.ErrNoRows {
+				log.Printf("API Error: Failed to look up API key: %v", err)
+				return database.User{}, "", http.StatusInternalServerError
+			}
+			return database.User{}, "", http.StatusUnauthorized
+		}
+		user, err := s.state.db.GetUserByID(ctx, apiKey.UserID)
+		if err != nil {
+			log.Printf("API Error: Failed to load owner of API key %s: %v", apiKey.ID, err)
+			return database.User{}, "", http.StatusInternalServerError
+		}
+		if err := s.state.db.TouchAPIKey(ctx, apiKey.ID); err != nil {
+			log.Printf("API Error: Failed to record use of API key %s: %v", apiKey.ID, err)
+		}
+		return user, auth.LesserRole(apiKey.Role, user.Role), http.StatusOK
+	}
+
+	if s.state.cfg.JWTSecret == "" {
+		return database.User{}, "", http.StatusServiceUnavailable
+	}
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return database.User{}, "", http.StatusUnauthorized
+	}
+	userID, err := auth.ValidateJWT(token, s.state.cfg.JWTSecret)
+	if err != nil {
+		return database.User{}, "", http.StatusUnauthorized
+	}
+	user, err := s.state.db.GetUserByID(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows { // User deleted after the token was issued
+			return database.User{}, "", http.StatusUnauthorized
+		}
+		log.Printf("API Error: Failed to load user %s: %v", userID, err)
+		return database.User{}, "", http.StatusInternalServerError
+	}
+	return user, user.Role, http.StatusOK
+}
+
+// userFromContext returns the user stored by requireRole.
+func userFromContext(ctx context.Context) (database.User, bool) {
+	user, ok := ctx.Value(userContextKey).(database.User)
+	return user, ok
+}
+
+// roleFromContext returns the effective role stored by requireRole.
+func roleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(roleContextKey).(string)
+	return role
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+)
+
+// Backtest strategies
+const strategySMACrossover = "sma_crossover"
+
+// Structure for a strategy backtest returned to the frontend
+type BacktestResponse struct {
+	StockCode        string                `json:"stock_code"`
+	Strategy         string                `json:"strategy"`
+	Fast             int                   `json:"fast"` // Fast moving average, in trading days
+	Slow             int                   `json:"slow"` // Slow moving average, in trading days
+	StartDate        string                `json:"start_date,omitempty"`
+	EndDate          string                `json:"end_date,omitempty"`
+	InitialCapital   float64               `json:"initial_capital"`
+	FinalValue       float64               `json:"final_value"`
+	TotalReturn      float64               `json:"total_return"` // Percent
+	CAGR             float64               `json:"cagr"`         // Percent per year
+	MaxDrawdown      float64               `json:"max_drawdown"` // Percent (<= 0)
+	BuyAndHoldReturn float64               `json:"buy_and_hold_return"`
+	Exposure         float64               `json:"exposure"` // Percent of days invested
+	Trades           []BacktestTrade       `json:"trades"`
+	Equity           []TimeSeriesDataPoint `json:"equity"`
+}
+
+// Structure for one simulated trade returned to the frontend
+type BacktestTrade struct {
+	Date  string  `json:"date"`
+	Side  string  `json:"side"` // buy or sell
+	Price float64 `json:"price"`
+}
+
+// handleGetBacktest runs a long-only strategy over a stock's stored daily closes and returns
+// its equity curve, CAGR and maximum drawdown. The sma_crossover strategy holds the stock
+// while its fast moving average is above the slow one; the averages are warmed up on closes
+// before start_date. Trades happen at the close, without costs.
+// GET /api/backtest?code=1155&fast=20&slow=50[&start_date=2020-01-01&end_date=2024-12-31][&initial=10000][&points=500]
+// points=N downsamples the equity curve; the statistics always cover every day.
+func (s *apiServer) handleGetBacktest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	code := p.stockCode("code", true)
+	strategy := p.enum("strategy", strategySMACrossover, strategySMACrossover)
+	fast := p.intBetween("fast", 20, 1, 250)
+	slow := p.intBetween("slow", 50, 2, 500)
+	start, end := p.dateRange(returnsEpoch, false)
+	initial := 10000.0
+	if p.has("initial") {
+		initial = p.number("initial", false)
+		if initial <= 0 {
+			p.fail("initial", "must be positive")
+		}
+	}
+	chart := chartPoints(p)
+	if fast >= slow {
+		p.fail("fast", "must be shorter than slow (%d)", slow)
+	}
+	if !p.ok(w) {
+		return
+	}
+
+	log.Printf("API: Backtesting %s %d/%d on %s from %s to %s", strategy, fast, slow, code, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	// Twice the slow window in calendar days covers its trading days across weekends and holidays
+	closes, err := loadStockCloses(r.Context(), s.state, code, start.AddDate(0, 0, -2*slow-14), end)
+	if err != nil {
+		log.Printf("API Error: Database error loading %s for backtest: %v", code, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"stock_code": code})
+		sendInternalError(w)
+		return
+	}
+	result := analytics.SMACrossover(closes, start, fast, slow, initial)
+	if len(result.Equity) == 0 {
+		sendNotFound(w, "No closing prices stored for "+code+" in the requested range")
+		return
+	}
+
+	response := BacktestResponse{
+		StockCode:        code,
+		Strategy:         strategy,
+		Fast:             fast,
+		Slow:             slow,
+		StartDate:        result.Equity[0].Date.Format("2006-01-02"),
+		EndDate:          result.Equity[len(result.Equity)-1].Date.Format("2006-01-02"),
+		InitialCapital:   initial,
+		FinalValue:       result.Equity[len(result.Equity)-1].Value,
+		TotalReturn:      result.TotalReturn,
+		CAGR:             result.CAGR,
+		MaxDrawdown:      result.MaxDrawdown,
+		BuyAndHoldReturn: result.BuyAndHoldReturn,
+		Exposure:         result.Exposure,
+		Trades:           make([]BacktestTrade, 0, len(result.Trades)),
+		Equity:           make([]TimeSeriesDataPoint, 0, len(result.Equity)),
+	}
+	for _, t := range result.Trades {
+		response.Trades = append(response.Trades, BacktestTrade{Date: t.Date.Format("2006-01-02"), Side: t.Side, Price: t.Price})
+	}
+	for _, e := range result.Equity {
+		response.Equity = append(response.Equity, TimeSeriesDataPoint{Date: e.Date.Format("2006-01-02"), Value: e.Value})
+	}
+	response.Equity = downsample(response.Equity, chart, TimeSeriesDataPoint.point)
+	sendJsonResponse(w, response)
+}
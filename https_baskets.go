@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/auth"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Structure for a basket returned to the frontend
+type BasketResponse struct {
+	ID         string                    `json:"id"`
+	Name       string                    `json:"name"`
+	BaseDate   string                    `json:"base_date"`
+	BaseValue  float64                   `json:"base_value"`
+	Components []BasketComponentResponse `json:"components"`
+	CreatedAt  time.Time                 `json:"created_at"`
+}
+
+// Structure for a basket component returned to the frontend
+type BasketComponentResponse struct {
+	StockCode string  `json:"stock_code"`
+	Weight    float64 `json:"weight"`
+}
+
+type basketRequest struct {
+	Name       string                    `json:"name"`
+	BaseDate   string                    `json:"base_date"`  // YYYY-MM-DD, default one year ago
+	BaseValue  float64                   `json:"base_value"` // Default 100
+	Components []BasketComponentResponse `json:"components"`
+}
+
+// handleBaskets serves the authenticated user's composite index baskets.
+// GET lists baskets; POST {"name", "base_date", "base_value", "components": [{"stock_code", "weight"}]}
+// creates one and computes its index; DELETE ?id= removes one. Changing baskets requires the editor role.
+func (s *apiServer) handleBaskets(w http.ResponseWriter, r *http.Request) {
+	user, _ := userFromContext(r.Context())
+
+	switch r.Method {
+	case http.MethodGet:
+		baskets, err := s.state.db.ListBasketsByUser(r.Context(), user.ID)
+		if err != nil {
+			log.Printf("API Error: Failed to load baskets for %s: %v", user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		response := make([]BasketResponse, 0, len(baskets))
+		for _, b := range baskets {
+			components, err := s.state.db.ListBasketComponents(r.Context(), b.ID)
+			if err != nil {
+				log.Printf("API Error: Failed to load components of basket %s: %v", b.ID, err)
+				sendInternalError(w)
+				return
+			}
+			response = append(response, basketResponseFromDB(b, components))
+		}
+		sendJsonResponse(w, response)
+
+	case http.MethodPost:
+		if !auth.RoleAllows(roleFromContext(r.Context()), auth.RoleEditor) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		var req basketRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		params, components, err := newBasketParams(r.Context(), s.state, user.ID, req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		basket, err := s.createBasket(r, params, components)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			http.Error(w, "A basket with this name already exists", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.Printf("API Error: Failed to create basket for %s: %v", user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		if _, err := computeBasket(r.Context(), s.state, basket); err != nil {
+			// The basket exists; its values are filled in after the next fetch
+			log.Printf("API Error: Failed to compute new basket %s: %v", basket.ID, err)
+		}
+		log.Printf("API: %s created basket %s (%s)", user.Username, basket.ID, basket.Name)
+		sendJsonResponse(w, basketResponseFromDB(basket, components))
+
+	case http.MethodDelete:
+		if !auth.RoleAllows(roleFromContext(r.Context()), auth.RoleEditor) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		p := s.params(r)
+		id := p.uuid("id")
+		if !p.ok(w) {
+			return
+		}
+		n, err := s.state.db.DeleteBasket(r.Context(), database.DeleteBasketParams{ID: id, UserID: user.ID})
+		if err != nil {
+			log.Printf("API Error: Failed to delete basket %s for %s: %v", id, user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		if n == 0 {
+			sendNotFound(w, "Basket not found")
+			return
+		}
+		log.Printf("API: %s removed basket %s", user.Username, id)
+		recordAudit(r.Context(), s.state, user, auditSourceAPI, "baskets:delete", id.String(), r.RemoteAddr)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// createBasket stores a basket and its components in one transaction.
+func (s *apiServer) createBasket(r *http.Request, params database.CreateBasketParams, components []database.BasketComponent) (database.Basket, error) {
+	tx, err := s.state.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		return database.Basket{}, err
+	}
+	defer tx.Rollback()
+	qtx := s.state.db.WithTx(tx)
+	basket, err := qtx.CreateBasket(r.Context(), params)
+	if err != nil {
+		return database.Basket{}, err
+	}
+	for _, c := range components {
+		err := qtx.CreateBasketComponent(r.Context(), database.CreateBasketComponentParams{
+			BasketID:  basket.ID,
+			StockCode: c.StockCode,
+			Weight:    c.Weight,
+		})
+		if err != nil {
+			return database.Basket{}, err
+		}
+	}
+	return basket, tx.Commit()
+}
+
+// newBasketParams validates a basket request.
+func newBasketParams(ctx context.Context, s *AppState, userID uuid.UUID, req basketRequest) (database.CreateBasketParams, []database.BasketComponent, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return database.CreateBasketParams{}, nil, fmt.Errorf("name is required (at most 100 characters)")
+	}
+	baseDate := markettime.Today().AddDate(-1, 0, 0)
+	if req.BaseDate != "" {
+		var err error
+		if baseDate, err = markettime.ParseDate(req.BaseDate); err != nil {
+			return database.CreateBasketParams{}, nil, fmt.Errorf("invalid base_date (use YYYY-MM-DD)")
+		}
+	}
+	baseValue := req.BaseValue
+	if baseValue == 0 {
+		baseValue = 100
+	}
+	if baseValue < 0 {
+		return database.CreateBasketParams{}, nil, fmt.Errorf("base_value must be positive")
+	}
+	if len(req.Components) == 0 || len(req.Components) > maxBasketComponents {
+		return database.CreateBasketParams{}, nil, fmt.Errorf("a basket needs 1 to %d components", maxBasketComponents)
+	}
+
+	id := uuid.New()
+	components := make([]database.BasketComponent, 0, len(req.Components))
+	seen := make(map[string]bool, len(req.Components))
+	for _, c := range req.Components {
+		code, err := lookupStockCode(ctx, s, c.StockCode)
+		if err != nil {
+			return database.CreateBasketParams{}, nil, err
+		}
+		if seen[code] {
+			return database.CreateBasketParams{}, nil, fmt.Errorf("stock %s is listed twice", code)
+		}
+		seen[code] = true
+		if c.Weight <= 0 {
+			return database.CreateBasketParams{}, nil, fmt.Errorf("weight for %s must be positive", code)
+		}
+		components = append(components, database.BasketComponent{BasketID: id, StockCode: code, Weight: fmt.Sprint(c.Weight)})
+	}
+
+	return database.CreateBasketParams{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		BaseDate:  baseDate,
+		BaseValue: fmt.Sprint(baseValue),
+	}, components, nil
+}
+
+// handleGetBasketValues returns the composite index of one of the authenticated user's baskets.
+// Query: id, optional start_date and end_date (YYYY-MM-DD; default all stored values).
+func (s *apiServer) handleGetBasketValues(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _ := userFromContext(r.Context())
+
+	p := s.params(r)
+	id := p.uuid("id")
+	start, end := p.dateRange(returnsEpoch, false)
+	if !p.ok(w) {
+		return
+	}
+
+	// Only the owner may read a basket's values
+	if _, err := s.state.db.GetBasketByIDAndUser(r.Context(), database.GetBasketByIDAndUserParams{ID: id, UserID: user.ID}); err != nil {
+		if err == sql.ErrNoRows {
+			sendNotFound(w, "Basket not found")
+			return
+		}
+		log.Printf("API Error: Failed to load basket %s: %v", id, err)
+		sendInternalError(w)
+		return
+	}
+
+	rows, err := s.state.db.GetBasketValuesByDateRange(r.Context(), database.GetBasketValuesByDateRangeParams{
+		BasketID:  id,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		log.Printf("API Error: Failed to load values of basket %s: %v", id, err)
+		sendInternalError(w)
+		return
+	}
+	response := make([]TimeSeriesDataPoint, 0, len(rows))
+	for _, row := range rows {
+		value, err := strconv.ParseFloat(row.Value, 64)
+		if err != nil {
+			log.Printf("Error parsing basket value: %v", err)
+			continue
+		}
+		response = append(response, TimeSeriesDataPoint{Date: row.Date.Format("2006-01-02"), Value: value})
+	}
+	sendJsonResponse(w, response)
+}
+
+func basketResponseFromDB(b database.Basket, components []database.BasketComponent) BasketResponse {
+	baseValue, _ := strconv.ParseFloat(b.BaseValue, 64)
+	response := BasketResponse{
+		ID:         b.ID.String(),
+		Name:       b.Name,
+		BaseDate:   b.BaseDate.Format("2006-01-02"),
+		BaseValue:  baseValue,
+		Components: make([]BasketComponentResponse, 0, len(components)),
+		CreatedAt:  b.CreatedAt,
+	}
+	for _, c := range components {
+		weight, _ := strconv.ParseFloat(c.Weight, 64)
+		response.Components = append(response.Components, BasketComponentResponse{StockCode: c.StockCode, Weight: weight})
+	}
+	return response
+}
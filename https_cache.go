@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+)
+
+// cached wraps a public data handler so identical GET requests (same path and query
+// parameters) are answered from the response cache until the TTL passes or new data is
+// stored. Only successful responses are cached; per-user endpoints must not be wrapped.
+// Requests answered by next are bounded by API_REQUEST_TIMEOUT.
+func (s *apiServer) cached(next http.HandlerFunc) http.HandlerFunc {
+	next = s.timed(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		cache := s.state.cache
+		if cache == nil || r.Method != http.MethodGet {
+			next(w, r)
+			return
+		}
+		key := "api:" + r.URL.Path + "?" + r.URL.Query().Encode() // Encode sorts by parameter name
+		if body, ok := cache.Get(r.Context(), key); ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Cache", "HIT")
+			w.Write(body)
+			return
+		}
+
+		generation := cache.Generation(r.Context())
+		w.Header().Set("X-Cache", "MISS")
+		rec := &cachingWriter{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+		if rec.status == http.StatusOK {
+			cache.Set(r.Context(), key, generation, rec.body.Bytes())
+		}
+	}
+}
+
+// cachingWriter passes a response through while keeping a copy of its body.
+type cachingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *cachingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cachingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// invalidateResponseCache drops cached API responses and latest values after stored data has
+// changed. It runs after the data is committed, so it must not be skipped because the
+// caller's context was cancelled.
+func invalidateResponseCache(s *AppState) {
+	if s.cache == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.cache.Invalidate(ctx)
+}
+
+// cachedLatestValue returns the latest observation of a series from the cache, if stored.
+func cachedLatestValue(ctx context.Context, s *AppState, key seriesKey) (analytics.Point, bool) {
+	if s.cache == nil {
+		return analytics.Point{}, false
+	}
+	body, ok := s.cache.Get(ctx, "latest:"+key.String())
+	if !ok {
+		return analytics.Point{}, false
+	}
+	var p analytics.Point
+	if err := json.Unmarshal(body, &p); err != nil {
+		return analytics.Point{}, false
+	}
+	return p, true
+}
+
+// storeLatestValue caches the latest observation of a series, taken at generation.
+func storeLatestValue(ctx context.Context, s *AppState, key seriesKey, generation uint64, p analytics.Point) {
+	if s.cache == nil {
+		return
+	}
+	if body, err := json.Marshal(p); err == nil {
+		s.cache.Set(ctx, "latest:"+key.String(), generation, body)
+	}
+}
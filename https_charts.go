@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/auth"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const maxChartSeries = 10
+
+// chartLookbacks are the spans a saved chart can show before its end date (or today).
+var chartLookbacks = []string{"1m", "3m", "6m", "1y", "3y", "5y", "10y"}
+
+// Structure for a saved chart returned to the frontend
+type SavedChartResponse struct {
+	ID        string                     `json:"id"`
+	Name      string                     `json:"name"`
+	Series    []SavedChartSeriesResponse `json:"series"`
+	StartDate string                     `json:"start_date,omitempty"` // YYYY-MM-DD; empty for the lookback
+	EndDate   string                     `json:"end_date,omitempty"`   // YYYY-MM-DD; empty for today
+	Lookback  string                     `json:"lookback,omitempty"`   // e.g. 1y; empty with start_date for the whole history
+	Shared    bool                       `json:"shared"`
+	URL       string                     `json:"url"`
+	CreatedAt time.Time                  `json:"created_at"`
+	UpdatedAt time.Time                  `json:"updated_at"`
+}
+
+// Structure for a series plotted on a saved chart
+type SavedChartSeriesResponse struct {
+	Series    string `json:"series"`              // stock:1155, fx:USD or macro:cpi
+	Transform string `json:"transform,omitempty"` // real (stock, fx); yoy, mom or 3mma (macro)
+}
+
+type savedChartRequest struct {
+	Name      string                     `json:"name"`
+	Series    []SavedChartSeriesResponse `json:"series"`
+	StartDate string                     `json:"start_date"`
+	EndDate   string                     `json:"end_date"`
+	Lookback  string                     `json:"lookback"`
+	Shared    bool                       `json:"shared"`
+}
+
+// handleCharts serves the authenticated user's saved charts.
+// GET lists them; POST {"name", "series": [{"series": "stock:1155", "transform": "real"}], "start_date",
+// "end_date", "lookback", "shared"} saves one. Saving requires the editor role.
+func (s *apiServer) handleCharts(w http.ResponseWriter, r *http.Request) {
+	user, _ := userFromContext(r.Context())
+
+	switch r.Method {
+	case http.MethodGet:
+		charts, err := s.state.db.ListSavedChartsByUser(r.Context(), user.ID)
+		if err != nil {
+			log.Printf("API Error: Failed to load saved charts for %s: %v", user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		response := make([]SavedChartResponse, 0, len(charts))
+		for _, c := range charts {
+			series, err := s.state.db.ListSavedChartSeries(r.Context(), c.ID)
+			if err != nil {
+				log.Printf("API Error: Failed to load series of chart %s: %v", c.ID, err)
+				sendInternalError(w)
+				return
+			}
+			response = append(response, s.savedChartResponseFromDB(c, series))
+		}
+		sendJsonResponse(w, response)
+
+	case http.MethodPost:
+		if !auth.RoleAllows(roleFromContext(r.Context()), auth.RoleEditor) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		s.saveChart(w, r, user, uuid.Nil)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleChart serves one saved chart at /api/charts/{id}.
+// GET returns it to its owner, or to anyone when it is shared; PUT replaces it and DELETE removes
+// it, both for the owner with the editor role.
+func (s *apiServer) handleChart(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		sendNotFound(w, "Chart not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.getChart(w, r, id)
+
+	case http.MethodPut:
+		s.requireRole(auth.RoleEditor, func(w http.ResponseWriter, r *http.Request) {
+			user, _ := userFromContext(r.Context())
+			s.saveChart(w, r, user, id)
+		})(w, r)
+
+	case http.MethodDelete:
+		s.requireRole(auth.RoleEditor, func(w http.ResponseWriter, r *http.Request) {
+			user, _ := userFromContext(r.Context())
+			n, err := s.state.db.DeleteSavedChart(r.Context(), database.DeleteSavedChartParams{ID: id, UserID: user.ID})
+			if err != nil {
+				log.Printf("API Error: Failed to delete chart %s for %s: %v", id, user.Username, err)
+				sendInternalError(w)
+				return
+			}
+			if n == 0 {
+				sendNotFound(w, "Chart not found")
+				return
+			}
+			log.Printf("API: %s removed chart %s", user.Username, id)
+			recordAudit(r.Context(), s.state, user, auditSourceAPI, "charts:delete", id.String(), r.RemoteAddr)
+			w.WriteHeader(http.StatusNoContent)
+		})(w, r)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getChart writes a saved chart if it is shared or the request is authenticated as its owner.
+// A private chart is reported as not found to anyone else, authenticated or not, so IDs cannot
+// be probed.
+func (s *apiServer) getChart(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	chart, err := s.state.db.GetSavedChart(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		sendNotFound(w, "Chart not found")
+		return
+	}
+	if err != nil {
+		log.Printf("API Error: Failed to load chart %s: %v", id, err)
+		sendInternalError(w)
+		return
+	}
+	if !chart.Shared {
+		user, _, status := s.authenticate(r)
+		if status == http.StatusInternalServerError {
+			sendInternalError(w)
+			return
+		}
+		if status != http.StatusOK || user.ID != chart.UserID {
+			sendNotFound(w, "Chart not found")
+			return
+		}
+	}
+	series, err := s.state.db.ListSavedChartSeries(r.Context(), chart.ID)
+	if err != nil {
+		log.Printf("API Error: Failed to load series of chart %s: %v", chart.ID, err)
+		sendInternalError(w)
+		return
+	}
+	sendJsonResponse(w, s.savedChartResponseFromDB(chart, series))
+}
+
+// saveChart creates a chart from the request body, or replaces the user's chart id when it is
+// not uuid.Nil, and writes the stored chart.
+func (s *apiServer) saveChart(w http.ResponseWriter, r *http.Request, user database.User, id uuid.UUID) {
+	var req savedChartRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	params, series, err := newSavedChartParams(r.Context(), s.state, user.ID, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if id != uuid.Nil {
+		params.ID = id
+	}
+
+	chart, err := s.storeChart(r, params, series, id != uuid.Nil)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+		http.Error(w, "A chart with this name already exists", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		sendNotFound(w, "Chart not found")
+		return
+	}
+	if err != nil {
+		log.Printf("API Error: Failed to save chart for %s: %v", user.Username, err)
+		sendInternalError(w)
+		return
+	}
+	log.Printf("API: %s saved chart %s (%s)", user.Username, chart.ID, chart.Name)
+	sendJsonResponse(w, s.savedChartResponseFromDB(chart, series))
+}
+
+// storeChart creates (or, with update, replaces) a saved chart and its series in one
+// transaction. Updating a chart the user does not own returns sql.ErrNoRows.
+func (s *apiServer) storeChart(r *http.Request, params database.CreateSavedChartParams, series []database.SavedChartSeries, update bool) (database.SavedChart, error) {
+	tx, err := s.state.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		return database.SavedChart{}, err
+	}
+	defer tx.Rollback()
+	qtx := s.state.db.WithTx(tx)
+	var chart database.SavedChart
+	if update {
+		chart, err = qtx.UpdateSavedChart(r.Context(), database.UpdateSavedChartParams(params))
+		if err == nil {
+			err = qtx.DeleteSavedChartSeries(r.Context(), chart.ID)
+		}
+	} else {
+		chart, err = qtx.CreateSavedChart(r.Context(), params)
+	}
+	if err != nil {
+		return database.SavedChart{}, err
+	}
+	for _, sr := range series {
+		err := qtx.CreateSavedChartSeries(r.Context(), database.CreateSavedChartSeriesParams{
+			ChartID:   chart.ID,
+			Position:  sr.Position,
+			SeriesKey: sr.SeriesKey,
+			Transform: sr.Transform,
+		})
+		if err != nil {
+			return database.SavedChart{}, err
+		}
+	}
+	return chart, tx.Commit()
+}
+
+// newSavedChartParams validates a saved chart request.
+func newSavedChartParams(ctx context.Context, s *AppState, userID uuid.UUID, req savedChartRequest) (database.CreateSavedChartParams, []database.SavedChartSeries, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return database.CreateSavedChartParams{}, nil, fmt.Errorf("name is required (at most 100 characters)")
+	}
+	if len(req.Series) == 0 || len(req.Series) > maxChartSeries {
+		return database.CreateSavedChartParams{}, nil, fmt.Errorf("a chart needs 1 to %d series", maxChartSeries)
+	}
+	params := database.CreateSavedChartParams{ID: uuid.New(), UserID: userID, Name: name, Shared: req.Shared}
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *sql.NullTime
+	}{{"start_date", req.StartDate, &params.StartDate}, {"end_date", req.EndDate, &params.EndDate}} {
+		if field.value == "" {
+			continue
+		}
+		date, err := markettime.ParseDate(field.value)
+		if err != nil {
+			return database.CreateSavedChartParams{}, nil, fmt.Errorf("invalid %s (use YYYY-MM-DD)", field.name)
+		}
+		*field.dest = sql.NullTime{Time: date, Valid: true}
+	}
+	if params.StartDate.Valid && params.EndDate.Valid && params.EndDate.Time.Before(params.StartDate.Time) {
+		return database.CreateSavedChartParams{}, nil, fmt.Errorf("end_date is before start_date")
+	}
+	if lookback := strings.ToLower(strings.TrimSpace(req.Lookback)); lookback != "" {
+		if params.StartDate.Valid {
+			return database.CreateSavedChartParams{}, nil, fmt.Errorf("use either start_date or lookback, not both")
+		}
+		if !slices.Contains(chartLookbacks, lookback) {
+			return database.CreateSavedChartParams{}, nil, fmt.Errorf("invalid lookback %q (use %s)", req.Lookback, strings.Join(chartLookbacks, ", "))
+		}
+		params.Lookback = sql.NullString{String: lookback, Valid: true}
+	}
+
+	series := make([]database.SavedChartSeries, 0, len(req.Series))
+	seen := make(map[string]bool, len(req.Series))
+	for i, sr := range req.Series {
+		key, transform, err := parseChartSeries(ctx, s, sr.Series, sr.Transform)
+		if err != nil {
+			return database.CreateSavedChartParams{}, nil, err
+		}
+		if seen[key+" "+transform] {
+			return database.CreateSavedChartParams{}, nil, fmt.Errorf("series %s is listed twice", key)
+		}
+		seen[key+" "+transform] = true
+		series = append(series, database.SavedChartSeries{ChartID: params.ID, Position: int32(i), SeriesKey: key, Transform: transform})
+	}
+	return params, series, nil
+}
+
+// parseChartSeries normalizes a chart series key and its transform: stock and fx series may be
+// shown real (deflated by CPI), macro series as stored or as yoy, mom or 3mma.
+func parseChartSeries(ctx context.Context, s *AppState, raw, transform string) (string, string, error) {
+	transform = strings.ToLower(strings.TrimSpace(transform))
+	kind, code, _ := strings.Cut(strings.TrimSpace(raw), ":")
+	if strings.EqualFold(kind, "macro") {
+		code = strings.ToLower(code)
+		if _, ok := macroSeries[code]; !ok {
+			return "", "", fmt.Errorf("unknown macro series %q", code)
+		}
+		switch transform {
+		case "", "level":
+			transform = ""
+		case "yoy", "mom", "3mma":
+		default:
+			return "", "", fmt.Errorf("invalid transform %q for macro:%s (use level, yoy, mom or 3mma)", transform, code)
+		}
+		return "macro:" + code, transform, nil
+	}
+
+	key, err := parseSeriesKey(raw)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid series %q (use stock:<code>, fx:<currency> or macro:<series>)", raw)
+	}
+	if _, _, err := newWatchlistItem(ctx, s, key.Kind, key.Code); err != nil {
+		return "", "", err
+	}
+	switch transform {
+	case "", "nominal":
+		transform = ""
+	case "real":
+	default:
+		return "", "", fmt.Errorf("invalid transform %q for %s (use nominal or real)", transform, key)
+	}
+	return key.String(), transform, nil
+}
+
+func (s *apiServer) savedChartResponseFromDB(c database.SavedChart, series []database.SavedChartSeries) SavedChartResponse {
+	response := SavedChartResponse{
+		ID:        c.ID.String(),
+		Name:      c.Name,
+		Series:    make([]SavedChartSeriesResponse, 0, len(series)),
+		Lookback:  c.Lookback.String,
+		Shared:    c.Shared,
+		URL:       s.state.cfg.BasePath + "/api/charts/" + c.ID.String(),
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+	if c.StartDate.Valid {
+		response.StartDate = c.StartDate.Time.Format("2006-01-02")
+	}
+	if c.EndDate.Valid {
+		response.EndDate = c.EndDate.Time.Format("2006-01-02")
+	}
+	for _, sr := range series {
+		response.Series = append(response.Series, SavedChartSeriesResponse{Series: sr.SeriesKey, Transform: sr.Transform})
+	}
+	return response
+}
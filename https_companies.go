@@ -0,0 +1,127 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+)
+
+// Structure for a company returned to the frontend
+type CompanyResponse struct {
+	StockCode    string   `json:"stock_code"`
+	CompanyName  string   `json:"company_name"`
+	Country      string   `json:"country,omitempty"`
+	Sector       string   `json:"sector,omitempty"`
+	Subsector    string   `json:"subsector,omitempty"`
+	Status       string   `json:"status"`                  // active, missing or delisted
+	DelistedDate string   `json:"delisted_date,omitempty"` // YYYY-MM-DD
+	FormerNames  []string `json:"former_names,omitempty"`
+}
+
+// handleGetStocks lists the stored companies, optionally only those of one country or sector
+// (matched case-insensitively). Delisted companies are included, marked by their status.
+// fields=stock_code,company_name returns only those fields of each company.
+// Usage: GET /api/stocks?country=MY&sector=Financial%20Services[&fields=a,b]
+func (s *apiServer) handleGetStocks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	country := p.country("country")
+	sector := p.str("sector", false)
+	fields := p.fields("fields", CompanyResponse{})
+	if !p.ok(w) {
+		return
+	}
+
+	companies, err := s.state.db.ListCompanies(r.Context(), sql.NullString{String: country, Valid: country != ""})
+	if err != nil {
+		log.Printf("API Error: Database error listing companies: %v", err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"endpoint": "stocks"})
+		sendInternalError(w)
+		return
+	}
+	response := make([]CompanyResponse, 0, len(companies))
+	for _, c := range companies {
+		if sector != "" && !strings.EqualFold(c.Sector.String, sector) {
+			continue
+		}
+		company := CompanyResponse{
+			StockCode:   c.StockCode,
+			CompanyName: c.CompanyName,
+			Country:     c.CountryCode.String,
+			Sector:      c.Sector.String,
+			Subsector:   c.Subsector.String,
+			Status:      c.Status,
+			FormerNames: c.FormerNames,
+		}
+		if c.DelistedDate.Valid {
+			company.DelistedDate = c.DelistedDate.Time.Format("2006-01-02")
+		}
+		response = append(response, company)
+	}
+	sendJsonFields(w, r, response, fields)
+}
+
+// Structure for a stock's latest close with its 52-week range
+type StockSnapshotResponse struct {
+	StockCode   string   `json:"stock_code"`
+	CompanyName string   `json:"company_name"`
+	Country     string   `json:"country,omitempty"`
+	Sector      string   `json:"sector,omitempty"`
+	PriceDate   string   `json:"price_date"`
+	Price       *float64 `json:"price"`
+	High52w     *float64 `json:"high_52w"` // Highest close of the 52 weeks to price_date
+	HighDate    string   `json:"high_date"`
+	Low52w      *float64 `json:"low_52w"` // Lowest close of the 52 weeks to price_date
+	LowDate     string   `json:"low_date"`
+	OffHigh     *float64 `json:"off_high"` // Percent below high_52w (0 at the high)
+	OffLow      *float64 `json:"off_low"`  // Percent above low_52w (0 at the low)
+}
+
+// handleGetStocksLatest returns every stock's latest close with its 52-week high and low and
+// its distance from each, optionally only those of one country. The ranges are kept up to
+// date as closes are stored. fields= selects the fields returned, as on /api/stocks.
+// Usage: GET /api/stocks/latest?country=MY[&fields=a,b]
+func (s *apiServer) handleGetStocksLatest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	country := p.country("country")
+	fields := p.fields("fields", StockSnapshotResponse{})
+	if !p.ok(w) {
+		return
+	}
+
+	rows, err := s.state.db.ListStockRanges52w(r.Context(), sql.NullString{String: country, Valid: country != ""})
+	if err != nil {
+		log.Printf("API Error: Database error listing latest closes: %v", err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"endpoint": "stocks/latest"})
+		sendInternalError(w)
+		return
+	}
+	response := make([]StockSnapshotResponse, 0, len(rows))
+	for _, row := range rows {
+		snapshot := StockSnapshotResponse{
+			StockCode:   row.StockCode,
+			CompanyName: row.CompanyName,
+			Country:     row.CountryCode.String,
+			Sector:      row.Sector.String,
+			PriceDate:   row.PriceDate.Format("2006-01-02"),
+			Price:       nullableFloat(sql.NullString{String: row.ClosingPrice, Valid: true}),
+			High52w:     nullableFloat(sql.NullString{String: row.High52w, Valid: true}),
+			HighDate:    row.HighDate.Format("2006-01-02"),
+			Low52w:      nullableFloat(sql.NullString{String: row.Low52w, Valid: true}),
+			LowDate:     row.LowDate.Format("2006-01-02"),
+		}
+		snapshot.OffHigh, snapshot.OffLow = rangeDistances(snapshot.Price, snapshot.High52w, snapshot.Low52w)
+		response = append(response, snapshot)
+	}
+	sendJsonFields(w, r, response, fields)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/fxprovider"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// Structure for the result of a currency conversion
+type FxConversionResponse struct {
+	Amount  float64           `json:"amount"`
+	From    string            `json:"from"`
+	To      string            `json:"to"`
+	Date    string            `json:"date"` // Requested date
+	Session string            `json:"session"`
+	Rate    float64           `json:"rate"` // Units of To per 1 From
+	Result  float64           `json:"result"`
+	Legs    []FxConversionLeg `json:"legs"` // Stored MYR rates used; empty when From and To are the same
+}
+
+// Structure for one stored rate used in a conversion
+type FxConversionLeg struct {
+	Currency   string  `json:"currency"`
+	RateDate   string  `json:"rate_date"` // Date of the stored rate; before Date when none was published that day
+	MyrPerUnit float64 `json:"myr_per_unit"`
+	Fallback   bool    `json:"fallback"` // True when RateDate is before the requested date
+}
+
+// errNoFxRate is returned by myrRateOnOrBefore when a currency has no rate on or before a date.
+type errNoFxRate struct {
+	Currency string
+	Date     time.Time
+}
+
+func (e errNoFxRate) Error() string {
+	return fmt.Sprintf("no %s rate stored on or before %s", e.Currency, e.Date.Format("2006-01-02"))
+}
+
+// myrRateOnOrBefore returns the MYR per 1 unit rate of code for the session in effect on date:
+// the rate published that day or, failing that, the nearest earlier one.
+func myrRateOnOrBefore(ctx context.Context, s *AppState, code, session string, date time.Time) (FxConversionLeg, error) {
+	row, err := s.db.GetLatestForeignExchangeOnOrBefore(ctx, database.GetLatestForeignExchangeOnOrBeforeParams{
+		CurrencyCode: code,
+		OnDate:       date,
+		Session:      session,
+	})
+	if err == sql.ErrNoRows {
+		return FxConversionLeg{}, errNoFxRate{Currency: code, Date: date}
+	}
+	if err != nil {
+		return FxConversionLeg{}, fmt.Errorf("failed to look up %s rate on %s: %w", code, date.Format("2006-01-02"), err)
+	}
+	rate, err := strconv.ParseFloat(row.MiddleRatePerUnit, 64)
+	if err != nil || rate <= 0 {
+		return FxConversionLeg{}, fmt.Errorf("invalid stored %s rate %q on %s", code, row.MiddleRatePerUnit, row.Date.Format("2006-01-02"))
+	}
+	return FxConversionLeg{
+		Currency:   code,
+		RateDate:   row.Date.Format("2006-01-02"),
+		MyrPerUnit: rate,
+		Fallback:   row.Date.Before(date),
+	}, nil
+}
+
+// handleGetFxConvert converts an amount between two currencies (either may be MYR) with the
+// BNM middle rates in effect on date (default: today). Cross rates go through MYR; when no
+// rate was published on date (weekends, holidays) the nearest earlier one is used.
+// Usage: GET /api/fx/convert?amount=100&from=USD&to=SGD&date=2024-01-06&session=1200
+func (s *apiServer) handleGetFxConvert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	amount := p.number("amount", true)
+	from := p.currency("from", true)
+	to := p.currency("to", true)
+	date := p.date("date", markettime.Today(), false)
+	session := p.enum("session", "1200", fxprovider.Sessions...)
+	if !p.ok(w) {
+		return
+	}
+
+	response := FxConversionResponse{
+		Amount:  amount,
+		From:    from,
+		To:      to,
+		Date:    date.Format("2006-01-02"),
+		Session: session,
+		Rate:    1,
+		Legs:    []FxConversionLeg{},
+	}
+	if from != to {
+		myrPerFrom, myrPerTo := 1.0, 1.0
+		for _, code := range []string{from, to} {
+			if code == baseCurrency {
+				continue
+			}
+			leg, err := myrRateOnOrBefore(r.Context(), s.state, code, session, date)
+			if noRate, ok := err.(errNoFxRate); ok {
+				sendNotFound(w, noRate.Error())
+				return
+			}
+			if err != nil {
+				log.Printf("API Error: %v", err)
+				errreport.CaptureError(r.Context(), err, map[string]string{"currency": code})
+				sendInternalError(w)
+				return
+			}
+			if code == from {
+				myrPerFrom = leg.MyrPerUnit
+			} else {
+				myrPerTo = leg.MyrPerUnit
+			}
+			response.Legs = append(response.Legs, leg)
+		}
+		response.Rate = myrPerFrom / myrPerTo
+	}
+	response.Result = amount * response.Rate
+	sendJsonResponse(w, response)
+}
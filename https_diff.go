@@ -0,0 +1,55 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// handleGetDiff returns the change of every tracked series between the values in effect on
+// two dates, ranked by order (default: the largest moves in either direction). Query: from
+// and to (YYYY-MM-DD, default the last 7 days), kind (all, stock or fx), country (limits the
+// stocks; currencies are always included), order (movers, gainers or losers) and limit
+// (default 50).
+// Usage: GET /api/diff?from=2024-01-01&to=2024-01-08&kind=stock&country=MY&order=gainers&limit=10
+func (s *apiServer) handleGetDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	to := p.date("to", markettime.Today(), false)
+	from := p.date("from", to.AddDate(0, 0, -7), false)
+	if !from.Before(to) {
+		p.fail("from", "must be before to")
+	}
+	kind := p.enum("kind", "all", "all", watchlistStock, watchlistFx)
+	country := p.country("country")
+	order := p.enum("order", diffOrderMovers, diffOrderMovers, diffOrderGainers, diffOrderLosers)
+	limit := p.intBetween("limit", 50, 1, 1000)
+	if !p.ok(w) {
+		return
+	}
+	if kind == "all" {
+		kind = ""
+	}
+
+	diffs, err := diffSeries(r.Context(), s.state, from, to, kind, country, order)
+	if err != nil {
+		log.Printf("API Error: Failed to diff series from %s to %s: %v", from.Format("2006-01-02"), to.Format("2006-01-02"), err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"from": from.Format("2006-01-02"), "to": to.Format("2006-01-02")})
+		sendInternalError(w)
+		return
+	}
+	if len(diffs) > limit {
+		diffs = diffs[:limit]
+	}
+	sendJsonResponse(w, DiffResponse{
+		From:   from.Format("2006-01-02"),
+		To:     to.Format("2006-01-02"),
+		Order:  order,
+		Series: diffs,
+	})
+}
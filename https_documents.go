@@ -0,0 +1,126 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+)
+
+// Structure for a company report document returned to the frontend
+type CompanyDocumentResponse struct {
+	ID           int32     `json:"id"`
+	StockCode    string    `json:"stock_code"`
+	FiscalYear   int32     `json:"fiscal_year"`
+	Period       string    `json:"period"` // annual, q1, q2, q3 or q4
+	Title        string    `json:"title,omitempty"`
+	SourceURL    string    `json:"source_url"`
+	SHA256       string    `json:"sha256"`
+	SizeBytes    int64     `json:"size_bytes"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+	URL          string    `json:"url"` // Where this API serves the file
+}
+
+// handleGetStockDocuments lists the stored report documents of a stock, newest fiscal year first.
+// Usage: GET /api/stock/documents?code=1155
+func (s *apiServer) handleGetStockDocuments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	stockCode := p.stockCode("code", true)
+	if !p.ok(w) {
+		return
+	}
+
+	docs, err := s.state.db.ListCompanyDocumentsByStockCode(r.Context(), stockCode)
+	if err != nil {
+		log.Printf("API Error: Database error listing documents for %s: %v", stockCode, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"stock_code": stockCode})
+		sendInternalError(w)
+		return
+	}
+	response := make([]CompanyDocumentResponse, 0, len(docs))
+	for _, d := range docs {
+		response = append(response, CompanyDocumentResponse{
+			ID:           d.ID,
+			StockCode:    d.StockCode,
+			FiscalYear:   d.FiscalYear,
+			Period:       d.Period,
+			Title:        d.Title,
+			SourceURL:    d.SourceUrl,
+			SHA256:       d.Sha256,
+			SizeBytes:    d.SizeBytes,
+			DownloadedAt: d.DownloadedAt,
+			URL:          s.state.cfg.BasePath + "/api/stock/documents/file?id=" + strconv.Itoa(int(d.ID)),
+		})
+	}
+	sendJsonResponse(w, response)
+}
+
+// handleGetStockDocumentFile serves a stored report file. The checksum is the ETag, so
+// clients revalidate with If-None-Match instead of downloading it again.
+// Usage: GET /api/stock/documents/file?id=12
+func (s *apiServer) handleGetStockDocumentFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	p.str("id", true)
+	id := p.intBetween("id", 0, 1, math.MaxInt32)
+	if !p.ok(w) {
+		return
+	}
+
+	doc, err := s.state.db.GetCompanyDocument(r.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			sendNotFound(w, "Document not found")
+			return
+		}
+		log.Printf("API Error: Database error loading document %d: %v", id, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"document_id": strconv.Itoa(id)})
+		sendInternalError(w)
+		return
+	}
+	etag := `"` + doc.Sha256 + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	store, err := documentStorage(s.state)
+	if err != nil {
+		log.Printf("API Error: Document storage unavailable: %v", err)
+		sendInternalError(w)
+		return
+	}
+	file, err := store.open(r.Context(), doc.StorageKey)
+	if err != nil {
+		log.Printf("API Error: Failed to open document %d (%s): %v", doc.ID, doc.StorageKey, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"document_id": strconv.Itoa(id)})
+		http.Error(w, "Document file unavailable", http.StatusBadGateway)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(doc.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s-%d-%s.pdf"`, doc.StockCode, doc.FiscalYear, doc.Period))
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(w, file); err != nil {
+		log.Printf("API Error: Failed to send document %d: %v", doc.ID, err)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// Structure for an entitlement returned to the frontend
+type EntitlementResponse struct {
+	StockCode   string    `json:"stock_code"`
+	Type        string    `json:"type"` // dividend, bonus, rights, split or other
+	ExDate      string    `json:"ex_date"`
+	PaymentDate string    `json:"payment_date,omitempty"`
+	Description string    `json:"description"`
+	Amount      *float64  `json:"amount,omitempty"` // MYR per share, dividends only
+	Source      string    `json:"source"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// handleGetEntitlementsCalendar returns the entitlements going ex between start_date and
+// end_date inclusive (default: today to 90 days ahead), soonest first, optionally for one
+// stock (code) and/or type. Entitlements are scraped for DEFAULT_COUNTRY listings only, so
+// any other country has none.
+// Usage: GET /api/calendar/entitlements?start_date=&end_date=&code=1155&type=dividend&country=MY
+func (s *apiServer) handleGetEntitlementsCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	start := p.date("start_date", markettime.Today(), false)
+	end := p.date("end_date", start.AddDate(0, 0, 90), false)
+	if start.After(end) {
+		p.fail("start_date", "must not be after end_date")
+	} else if p.maxRangeDays > 0 && end.Sub(start) > time.Duration(p.maxRangeDays)*24*time.Hour {
+		p.tooLarge("end_date", "range is longer than %d days", p.maxRangeDays)
+	}
+	// Entitlements are stored for every listed stock, not only stored companies
+	code := normalizeStockCode(p.str("code", false))
+	if code != "" && !stockCodePattern.MatchString(code) {
+		p.fail("code", "invalid stock code %q", code)
+	}
+	entitlementType := p.enum("type", "", entitlementTypes...)
+	country := p.country("country")
+	if !p.ok(w) {
+		return
+	}
+	if country != "" && country != s.state.cfg.DefaultCountry {
+		sendJsonResponse(w, []EntitlementResponse{})
+		return
+	}
+
+	rows, err := s.state.db.ListEntitlementsBetween(r.Context(), database.ListEntitlementsBetweenParams{
+		StartDate:       start,
+		EndDate:         end,
+		StockCode:       sql.NullString{String: code, Valid: code != ""},
+		EntitlementType: sql.NullString{String: entitlementType, Valid: entitlementType != ""},
+	})
+	if err != nil {
+		log.Printf("API Error: Database error fetching entitlements: %v", err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"stock_code": code})
+		sendInternalError(w)
+		return
+	}
+	response := make([]EntitlementResponse, 0, len(rows))
+	for _, row := range rows {
+		e := EntitlementResponse{
+			StockCode:   row.StockCode,
+			Type:        row.EntitlementType,
+			ExDate:      row.ExDate.Format("2006-01-02"),
+			Description: row.Description,
+			Source:      row.SourceUrl,
+			FetchedAt:   row.FetchedAt,
+		}
+		if row.PaymentDate.Valid {
+			e.PaymentDate = row.PaymentDate.Time.Format("2006-01-02")
+		}
+		if row.Amount.Valid {
+			if v, err := strconv.ParseFloat(row.Amount.String, 64); err == nil {
+				e.Amount = &v
+			}
+		}
+		response = append(response, e)
+	}
+	sendJsonResponse(w, response)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+)
+
+// jsonFieldNames returns the JSON names of the fields of a response struct, in declaration order.
+func jsonFieldNames(item interface{}) []string {
+	t := reflect.TypeOf(item)
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// fields returns a comma-separated list of the JSON fields of item (a response struct) to keep,
+// or nil when the parameter is absent and every field is returned. Unknown names are rejected
+// with the list of known ones.
+func (p *queryParams) fields(name string, item interface{}) []string {
+	known := jsonFieldNames(item)
+	var fields []string
+	for _, raw := range strings.Split(p.str(name, false), ",") {
+		field := strings.ToLower(strings.TrimSpace(raw))
+		if field == "" || slices.Contains(fields, field) {
+			continue
+		}
+		if !slices.Contains(known, field) {
+			p.fail(name, "unknown field %q (use %s)", field, strings.Join(known, ", "))
+			return nil
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// selectFields reduces each element of items, a slice of response structs, to the given JSON
+// fields. With no fields the items are returned unchanged. Fields that would be omitted when
+// empty are still omitted.
+func selectFields(items interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return items, nil
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode items: %w", err)
+	}
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode items: %w", err)
+	}
+	for _, row := range rows {
+		for key := range row {
+			if !slices.Contains(fields, key) {
+				delete(row, key)
+			}
+		}
+	}
+	if rows == nil {
+		rows = []map[string]json.RawMessage{}
+	}
+	return rows, nil
+}
+
+// sendJsonFields writes items reduced to the requested fields (all of them when fields is empty).
+func sendJsonFields(w http.ResponseWriter, r *http.Request, items interface{}, fields []string) {
+	selected, err := selectFields(items, fields)
+	if err != nil {
+		log.Printf("API Error: Failed to select response fields: %v", err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"endpoint": r.URL.Path})
+		sendInternalError(w)
+		return
+	}
+	sendJsonResponse(w, selected)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// embeddedFrontend is the ./frontend directory as it was at build time, so a deployment is
+// the binary alone.
+//
+//go:embed frontend
+var embeddedFrontend embed.FS
+
+// frontendFiles returns the files the static file server serves: FRONTEND_DIR on disk when it
+// is set (./frontend for the dev profile), so edits show up without a rebuild, and otherwise
+// the embedded copy.
+func frontendFiles(s *AppState) http.FileSystem {
+	if s.cfg.FrontendDir != "" {
+		return http.Dir(s.cfg.FrontendDir)
+	}
+	files, err := fs.Sub(embeddedFrontend, "frontend")
+	if err != nil {
+		panic(err) // The embedded directory always exists
+	}
+	return http.FS(files)
+}
+
+// frontendSource describes where the frontend is served from, for the startup log.
+func frontendSource(s *AppState) string {
+	if s.cfg.FrontendDir != "" {
+		return "frontend from " + s.cfg.FrontendDir
+	}
+	return "embedded frontend"
+}
+
+// baseHref is the <base> element of the frontend pages. Their scripts and API calls use
+// relative URLs, so rewriting it to BASE_PATH moves the whole frontend under that prefix.
+const baseHref = `<base href="/">`
+
+// spaHandler serves the frontend files, answering GET requests for paths that match no file
+// with index.html, so a client-side routed page survives a reload. Paths under /api/ and
+// paths with a file extension (a missing script or image) still get a 404. Under a basePath
+// the pages are served with their <base> element pointing at it.
+func spaHandler(files http.FileSystem, basePath string) http.Handler {
+	fileServer := http.FileServer(files)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (r.Method == http.MethodGet || r.Method == http.MethodHead) &&
+			!strings.HasPrefix(r.URL.Path, "/api/") && path.Ext(r.URL.Path) == "" {
+			if f, err := files.Open(path.Clean(r.URL.Path)); err != nil {
+				r = r.Clone(r.Context())
+				r.URL.Path = "/"
+			} else {
+				f.Close()
+			}
+		}
+		if basePath != "" && (r.URL.Path == "/" || path.Ext(r.URL.Path) == ".html") && serveFrontendPage(w, r, files, basePath) {
+			return
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+// serveFrontendPage serves the HTML page at r.URL.Path ("/" being index.html) with its <base>
+// element set to basePath. It reports false, having written nothing, when the page cannot be
+// read.
+func serveFrontendPage(w http.ResponseWriter, r *http.Request, files http.FileSystem, basePath string) bool {
+	name := path.Clean(r.URL.Path)
+	if name == "/" {
+		name = "/index.html"
+	}
+	f, err := files.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+	page, err := io.ReadAll(f)
+	if err != nil {
+		return false
+	}
+	page = bytes.Replace(page, []byte(baseHref), []byte(`<base href="`+basePath+`/">`), 1)
+	http.ServeContent(w, r, name, info.ModTime(), bytes.NewReader(page))
+	return true
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+)
+
+// The Grafana JSON datasource protocol (also understood by the Infinity plugin's JSON mode):
+// GET / checks the connection, POST /search lists metric names and POST /query returns
+// datapoints. Metric names are series keys: stock:<code>, fx:<currency> or macro:<series>.
+
+type grafanaSearchRequest struct {
+	Target string `json:"target"`
+}
+
+type grafanaQueryRequest struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	Targets []struct {
+		RefID  string `json:"refId"`
+		Target string `json:"target"`
+		Type   string `json:"type"` // timeserie (default) or table
+	} `json:"targets"`
+	MaxDataPoints int `json:"maxDataPoints"`
+}
+
+// Structure for a time series returned to Grafana; datapoints are [value, unix milliseconds]
+type GrafanaTimeSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// Structure for a table returned to Grafana
+type GrafanaTable struct {
+	Type    string              `json:"type"` // Always "table"
+	Columns []map[string]string `json:"columns"`
+	Rows    [][]interface{}     `json:"rows"`
+}
+
+// handleGrafanaRoot answers Grafana's "Save & test" connection check.
+func (s *apiServer) handleGrafanaRoot(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/grafana/" && r.URL.Path != "/api/grafana" {
+		sendNotFound(w, "Not found")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleGrafanaSearch lists the series that can be queried, filtered by the request's target substring.
+func (s *apiServer) handleGrafanaSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req grafanaSearchRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	names, err := grafanaSeriesNames(r.Context(), s.state)
+	if err != nil {
+		log.Printf("API Error: Failed to list series for Grafana: %v", err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"endpoint": "grafana_search"})
+		sendInternalError(w)
+		return
+	}
+	filter := strings.ToLower(req.Target)
+	response := make([]string, 0, len(names))
+	for _, name := range names {
+		if strings.Contains(strings.ToLower(name), filter) {
+			response = append(response, name)
+		}
+	}
+	sendJsonResponse(w, response)
+}
+
+// grafanaQueryLimit describes why a query for targets series over days days exceeds
+// API_MAX_RANGE_DAYS or API_MAX_SERIES_DAYS, or returns "" when it does not.
+func grafanaQueryLimit(s *AppState, targets, days int) string {
+	if limit := s.cfg.APIMaxRangeDays; limit > 0 && days > limit+1 {
+		return fmt.Sprintf("The range is longer than %d days", limit)
+	}
+	if limit := s.cfg.APIMaxSeriesDays; limit > 0 && targets*days > limit {
+		return fmt.Sprintf("%d series over %d days is more than %d series-days; query fewer series or a shorter range", targets, days, limit)
+	}
+	return ""
+}
+
+// handleGrafanaQuery returns the requested series over the dashboard's time range.
+func (s *apiServer) handleGrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req grafanaQueryRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if req.Range.From.IsZero() || req.Range.To.IsZero() || req.Range.To.Before(req.Range.From) {
+		http.Error(w, "Invalid range", http.StatusBadRequest)
+		return
+	}
+	if msg := grafanaQueryLimit(s.state, len(req.Targets), rangeDays(req.Range.From, req.Range.To)); msg != "" {
+		sendJsonError(w, http.StatusUnprocessableEntity, "query_too_large", msg)
+		return
+	}
+
+	response := make([]interface{}, 0, len(req.Targets))
+	for _, target := range req.Targets {
+		if target.Target == "" {
+			continue // Grafana sends empty targets while a query is being edited
+		}
+		points, err := loadNamedSeries(r.Context(), s.state, target.Target, req.Range.From, req.Range.To)
+		if errors.Is(err, errUnknownSeries) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Printf("API Error: Failed to load %s for Grafana: %v", target.Target, err)
+			errreport.CaptureError(r.Context(), err, map[string]string{"series": target.Target})
+			sendInternalError(w)
+			return
+		}
+		points = thinPoints(points, req.MaxDataPoints)
+
+		if target.Type == "table" {
+			table := GrafanaTable{
+				Type:    "table",
+				Columns: []map[string]string{{"text": "Time", "type": "time"}, {"text": target.Target, "type": "number"}},
+				Rows:    make([][]interface{}, 0, len(points)),
+			}
+			for _, p := range points {
+				table.Rows = append(table.Rows, []interface{}{p.Date.UnixMilli(), p.Value})
+			}
+			response = append(response, table)
+			continue
+		}
+		series := GrafanaTimeSeries{Target: target.Target, Datapoints: make([][2]float64, 0, len(points))}
+		for _, p := range points {
+			series.Datapoints = append(series.Datapoints, [2]float64{p.Value, float64(p.Date.UnixMilli())})
+		}
+		response = append(response, series)
+	}
+	sendJsonResponse(w, response)
+}
+
+// grafanaSeriesNames lists every stored stock, currency and macro series.
+func grafanaSeriesNames(ctx context.Context, s *AppState) ([]string, error) {
+	stocks, err := s.db.ListStockCodesWithPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	currencies, err := s.db.ListForeignExchangeCurrencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(stocks)+len(currencies)+len(macroSeries))
+	for _, code := range stocks {
+		names = append(names, seriesKey{Kind: watchlistStock, Code: code}.String())
+	}
+	for _, code := range currencies {
+		names = append(names, seriesKey{Kind: watchlistFx, Code: code}.String())
+	}
+	for code := range macroSeries {
+		names = append(names, "macro:"+code)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// thinPoints keeps at most max evenly spaced points, always including the last one.
+func thinPoints(points []analytics.Point, max int) []analytics.Point {
+	if max <= 0 || len(points) <= max {
+		return points
+	}
+	if max == 1 {
+		return points[len(points)-1:]
+	}
+	thinned := make([]analytics.Point, 0, max)
+	step := float64(len(points)-1) / float64(max-1)
+	for i := 0; i < max; i++ {
+		thinned = append(thinned, points[int(float64(i)*step+0.5)])
+	}
+	return thinned
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"crypto/hmac"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/notify"
+)
+
+type ingestRequest struct {
+	Source       string    `json:"source"`
+	SentAt       time.Time `json:"sent_at"` // RFC 3339; must be within a few minutes of the server clock
+	Observations []struct {
+		Date  string  `json:"date"` // YYYY-MM-DD (first day of the period for macro series)
+		Value float64 `json:"value"`
+	} `json:"observations"`
+}
+
+// Structure for an ingest result returned to the pushing script
+type IngestResponse struct {
+	Source string `json:"source"`
+	Series string `json:"series"`
+	Stored int    `json:"stored"`
+}
+
+// handleIngest stores observations pushed by a registered ingest source (see ingest:register).
+// The body is {"source", "sent_at", "observations": [{"date", "value"}]}, signed with the
+// source's secret: the X-Econdb-Signature header is "sha256=" + hex(HMAC-SHA256(secret, body)).
+// sent_at must be current and a body is only accepted once, so captured pushes cannot be replayed.
+// The derived data (returns, volatility, baskets) and alerts are updated in the background.
+func (s *apiServer) handleIngest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 4<<20))
+	if err != nil {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	var req ingestRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	// Unknown sources and bad signatures get the same answer
+	source, err := s.state.db.GetIngestSource(r.Context(), strings.ToLower(req.Source))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("API Error: Failed to load ingest source %q: %v", req.Source, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"endpoint": "ingest"})
+		sendInternalError(w)
+		return
+	}
+	signature := r.Header.Get(notify.SignatureHeader)
+	if err != nil || !source.Active || !hmac.Equal([]byte(signature), []byte(notify.Sign(source.Secret, body))) {
+		log.Printf("API: Rejected ingest for source %q from %s", req.Source, r.RemoteAddr)
+		http.Error(w, "Unknown source or invalid signature", http.StatusUnauthorized)
+		return
+	}
+	// A signed body stays valid forever, so old ones must not be replayable
+	if skew := time.Since(req.SentAt); req.SentAt.IsZero() || math.Abs(skew.Seconds()) > ingestClockSkew.Seconds() {
+		http.Error(w, fmt.Sprintf("sent_at must be within %s of the server time", ingestClockSkew), http.StatusBadRequest)
+		return
+	}
+	// Nor recent ones: a body accepted once is refused while its sent_at is still current. A
+	// signature can only be replayed for twice the allowed skew, so older ones are dropped.
+	if _, err := s.state.db.DeleteIngestSignaturesBefore(r.Context(), time.Now().Add(-2*ingestClockSkew)); err != nil {
+		log.Printf("API Error: Failed to prune ingest signatures: %v", err)
+	}
+	if n, err := s.state.db.RecordIngestSignature(r.Context(), database.RecordIngestSignatureParams{Signature: signature, SourceName: source.Name}); err != nil {
+		log.Printf("API Error: Failed to record ingest signature of %s: %v", source.Name, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"endpoint": "ingest", "source": source.Name})
+		sendInternalError(w)
+		return
+	} else if n == 0 {
+		log.Printf("API: Rejected replayed ingest for source %s from %s", source.Name, r.RemoteAddr)
+		http.Error(w, "This payload was already accepted; send new observations with a fresh sent_at", http.StatusConflict)
+		return
+	}
+
+	if len(req.Observations) == 0 || len(req.Observations) > maxIngestObservations {
+		http.Error(w, fmt.Sprintf("observations must contain 1 to %d entries", maxIngestObservations), http.StatusBadRequest)
+		return
+	}
+	observations := make([]ingestObservation, 0, len(req.Observations))
+	for i, o := range req.Observations {
+		date, err := markettime.ParseDate(o.Date)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("observations[%d]: invalid date %q (use YYYY-MM-DD)", i, o.Date), http.StatusBadRequest)
+			return
+		}
+		if math.IsNaN(o.Value) || math.IsInf(o.Value, 0) || (!strings.HasPrefix(source.Series, "macro:") && o.Value <= 0) {
+			http.Error(w, fmt.Sprintf("observations[%d]: invalid value for %s", i, source.Series), http.StatusBadRequest)
+			return
+		}
+		observations = append(observations, ingestObservation{Date: date, Value: o.Value})
+	}
+
+	if err := storeIngestedObservations(r.Context(), s.state, source, observations); err != nil {
+		log.Printf("API Error: Failed to store ingest from %s: %v", source.Name, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"endpoint": "ingest", "source": source.Name})
+		sendInternalError(w)
+		return
+	}
+	log.Printf("API: Ingested %d observation(s) of %s from %s", len(observations), source.Series, source.Name)
+
+	job := "ingest:" + source.Name
+	runBackground(s.state, func() {
+		err := runRecovered(job, func() error {
+			notifyDataStored(s.state, job, len(observations))
+			runPostFetchJobs(s.state.workCtx, s.state)
+			return nil
+		})
+		if err != nil {
+			log.Printf("API Error: Post-ingest jobs for %s failed: %v", source.Name, err)
+		}
+	})
+
+	sendJsonResponse(w, IngestResponse{Source: source.Name, Series: source.Series, Stored: len(observations)})
+}
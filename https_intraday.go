@@ -0,0 +1,43 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// Structure for an /api/stock/intraday response
+type IntradayResponse struct {
+	StockCode string          `json:"stock_code"`
+	Date      string          `json:"date"`
+	Prices    []IntradayPoint `json:"prices"`
+}
+
+// handleGetStockIntraday returns the prices polled for a stock during one market date's
+// sessions, oldest first. Prices are only polled when INTRADAY_INTERVAL is set and are kept for
+// INTRADAY_RETENTION_DAYS. The date defaults to today.
+// Usage: GET /api/stock/intraday?code=1155[&date=YYYY-MM-DD]
+func (s *apiServer) handleGetStockIntraday(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	code := p.stockCode("code", true)
+	date := p.date("date", markettime.Today(), false)
+	if !p.ok(w) {
+		return
+	}
+
+	log.Printf("API: Querying intraday prices for %s on %s", code, date.Format("2006-01-02"))
+	prices, err := loadIntradayPrices(r.Context(), s.state, code, date)
+	if err != nil {
+		log.Printf("API Error: Database error fetching intraday prices: %v", err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"stock_code": code})
+		sendInternalError(w)
+		return
+	}
+	sendJsonResponse(w, IntradayResponse{StockCode: code, Date: date.Format("2006-01-02"), Prices: prices})
+}
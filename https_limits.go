@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// rangeDays returns the number of days from start to end, both included.
+func rangeDays(start, end time.Time) int {
+	return int(end.Sub(start).Hours()/24) + 1
+}
+
+// tooLarge records a parameter that is valid but asks for more than the API serves in one
+// request. Such requests are answered 422 once every parameter is otherwise valid.
+func (p *queryParams) tooLarge(param, format string, args ...interface{}) {
+	for _, e := range p.limits {
+		if e.Param == param {
+			return
+		}
+	}
+	p.limits = append(p.limits, ParamError{Param: param, Message: fmt.Sprintf(format, args...)})
+}
+
+// limitCost rejects a request for series series over days days each when it exceeds
+// API_MAX_SERIES_DAYS, the cap on the rows one multi-series request may read.
+func (p *queryParams) limitCost(param string, series, days int) {
+	if p.maxSeriesDays > 0 && series*days > p.maxSeriesDays {
+		p.tooLarge(param, "%d series over %d days is more than %d series-days; ask for fewer series or a shorter range", series, days, p.maxSeriesDays)
+	}
+}
+
+// timed cancels the request's context after API_REQUEST_TIMEOUT, so a slow query gives up
+// its connection instead of holding it while the client waits. A handler that then fails is
+// answered 504 in place of its 500.
+func (s *apiServer) timed(next http.HandlerFunc) http.HandlerFunc {
+	timeout := s.state.cfg.APIRequestTimeout
+	if timeout <= 0 {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next(&timeoutWriter{ResponseWriter: w, ctx: ctx, timeout: timeout, path: r.URL.Path}, r.WithContext(ctx))
+	}
+}
+
+// timeoutWriter turns the 500 a handler writes after its context's deadline passed into a 504
+// with the error envelope, discarding the handler's own body.
+type timeoutWriter struct {
+	http.ResponseWriter
+	ctx      context.Context
+	timeout  time.Duration
+	path     string
+	timedOut bool
+}
+
+func (w *timeoutWriter) WriteHeader(status int) {
+	if status == http.StatusInternalServerError && errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedOut = true
+		log.Printf("API: %s timed out after %s", w.path, w.timeout)
+		sendJsonError(w.ResponseWriter, http.StatusGatewayTimeout, "timeout",
+			fmt.Sprintf("The request took longer than %s; ask for a shorter range or fewer series", w.timeout))
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	if w.timedOut {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// handleGetLineage returns where the observation of a series in effect on date (default:
+// today) came from: its source, the batch run that stored it, the first table snapshot that
+// holds it and the transformations applied before it is served.
+// Usage: GET /api/lineage?series=fx:USD&date=2024-01-05 (or stock:<code>, macro:<series>)
+func (s *apiServer) handleGetLineage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	series := p.str("series", true)
+	date := p.date("date", markettime.Today(), false)
+	if series != "" {
+		if strings.HasPrefix(series, "macro:") {
+			var err error
+			if series, err = parseIngestSeries(series); err != nil {
+				p.fail("series", "%v", err)
+			}
+		} else if key, ok := p.seriesKey("series", series); ok {
+			series = key.String()
+		}
+	}
+	if !p.ok(w) {
+		return
+	}
+
+	lineage, err := observationLineage(r.Context(), s.state, series, date)
+	if err == sql.ErrNoRows {
+		sendNotFound(w, "No observation on or before this date")
+		return
+	}
+	if err != nil {
+		log.Printf("API Error: Failed to trace lineage of %s on %s: %v", series, date.Format("2006-01-02"), err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"series": series})
+		sendInternalError(w)
+		return
+	}
+	sendJsonResponse(w, lineage)
+}
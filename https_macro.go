@@ -0,0 +1,129 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+)
+
+// Structure for a seasonal decomposition returned to the frontend
+type DecompositionResponse struct {
+	Series string                   `json:"series"`
+	Model  string                   `json:"model"` // additive or multiplicative
+	Points []DecompositionDataPoint `json:"points"`
+}
+
+// Structure for one month of a seasonal decomposition
+type DecompositionDataPoint struct {
+	Date               string   `json:"date"`
+	Value              float64  `json:"value"`
+	Trend              *float64 `json:"trend"` // null for the first and last six months
+	Seasonal           float64  `json:"seasonal"`
+	Irregular          *float64 `json:"irregular"`
+	SeasonallyAdjusted float64  `json:"seasonally_adjusted"`
+}
+
+// parseMacroQuery reads the series, start_date and end_date parameters of the macro endpoints.
+// The dates default to all stored history.
+func parseMacroQuery(p *queryParams) (series string, start, end time.Time) {
+	series = strings.ToLower(p.str("series", true))
+	if _, known := macroSeries[series]; !known && series != "" {
+		p.fail("series", "unknown macro series %q (e.g. cpi or activity)", series)
+	}
+	start, end = p.dateRange(returnsEpoch, false)
+	return series, start, end
+}
+
+// handleGetMacroSeries serves a stored monthly macro series, optionally transformed.
+// series=activity is the composite activity index of ACTIVITY_INDEX_WEIGHTS components.
+// GET /api/macro/series?series=cpi[&transform=level|yoy|mom|3mma][&start_date=...&end_date=...][&points=N]
+// yoy and mom are percentage changes; 3mma is the trailing three-month moving average.
+func (s *apiServer) handleGetMacroSeries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	series, start, end := parseMacroQuery(p)
+	transform := p.enum("transform", "level", "level", "yoy", "mom", "3mma")
+	chart := chartPoints(p)
+	if !p.ok(w) {
+		return
+	}
+	var apply func([]analytics.Point) []analytics.Point
+	switch transform {
+	case "level":
+		apply = func(points []analytics.Point) []analytics.Point { return points }
+	case "yoy":
+		apply = analytics.YearOnYear
+	case "mom":
+		apply = analytics.MonthOnMonth
+	case "3mma":
+		apply = func(points []analytics.Point) []analytics.Point { return analytics.MovingAverage(points, 3) }
+	}
+
+	// A year of history before start lets every transform cover the first requested month
+	log.Printf("API: Querying macro series %s (%s) from %s to %s", series, transform, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	points, err := loadMacroSeries(r.Context(), s.state, series, start.AddDate(-1, 0, 0), end)
+	if err != nil {
+		log.Printf("API Error: Database error fetching macro series %s: %v", series, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"series": series})
+		sendInternalError(w)
+		return
+	}
+
+	response := []TimeSeriesDataPoint{}
+	for _, p := range apply(points) {
+		if p.Date.Before(start) {
+			continue
+		}
+		response = append(response, TimeSeriesDataPoint{Date: p.Date.Format("2006-01-02"), Value: p.Value})
+	}
+	sendJsonResponse(w, downsample(response, chart, TimeSeriesDataPoint.point))
+}
+
+// handleGetMacroDecomposition serves a classical seasonal decomposition of a monthly macro series.
+// GET /api/macro/decompose?series=cpi[&model=additive|multiplicative][&start_date=...&end_date=...]
+// At least 24 consecutive months are required.
+func (s *apiServer) handleGetMacroDecomposition(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	series, start, end := parseMacroQuery(p)
+	model := p.enum("model", "additive", "additive", "multiplicative")
+	if !p.ok(w) {
+		return
+	}
+
+	points, err := loadMacroSeries(r.Context(), s.state, series, start, end)
+	if err != nil {
+		log.Printf("API Error: Database error fetching macro series %s: %v", series, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"series": series})
+		sendInternalError(w)
+		return
+	}
+	d, ok := analytics.SeasonalDecompose(points, model == "multiplicative")
+	if !ok {
+		http.Error(w, "Decomposition needs at least 24 consecutive months (and positive values for the multiplicative model)", http.StatusUnprocessableEntity)
+		return
+	}
+
+	response := DecompositionResponse{Series: series, Model: model, Points: make([]DecompositionDataPoint, 0, len(d.Dates))}
+	for i, date := range d.Dates {
+		response.Points = append(response.Points, DecompositionDataPoint{
+			Date:               date.Format("2006-01-02"),
+			Value:              d.Values[i],
+			Trend:              d.Trend[i],
+			Seasonal:           d.Seasonal[i],
+			Irregular:          d.Irregular[i],
+			SeasonallyAdjusted: d.SeasonallyAdjusted[i],
+		})
+	}
+	sendJsonResponse(w, response)
+}
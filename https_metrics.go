@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// econCollector exports the latest stored economic values as Prometheus gauges, so alerting
+// rules (e.g. "MYR per USD above 4.8") can be written in an existing monitoring stack. It reads
+// the database on every scrape and is served from its own registry, apart from any process metrics.
+type econCollector struct {
+	state *AppState
+
+	fxRate     *prometheus.Desc
+	fxDate     *prometheus.Desc
+	stockClose *prometheus.Desc
+	stockDate  *prometheus.Desc
+	macroValue *prometheus.Desc
+	macroDate  *prometheus.Desc
+	scrapeOK   *prometheus.Desc
+}
+
+func newEconCollector(s *AppState) *econCollector {
+	return &econCollector{
+		state: s,
+		fxRate: prometheus.NewDesc("econdb_fx_middle_rate",
+			"Latest BNM middle rate, MYR per one unit of the currency.", []string{"currency", "session"}, nil),
+		fxDate: prometheus.NewDesc("econdb_fx_rate_date_seconds",
+			"Date of the latest BNM middle rate as a Unix timestamp.", []string{"currency", "session"}, nil),
+		stockClose: prometheus.NewDesc("econdb_stock_close",
+			"Latest closing price of the stock.", []string{"code"}, nil),
+		stockDate: prometheus.NewDesc("econdb_stock_close_date_seconds",
+			"Date of the latest closing price as a Unix timestamp.", []string{"code"}, nil),
+		macroValue: prometheus.NewDesc("econdb_macro_value",
+			"Latest published value of the macroeconomic series.", []string{"series"}, nil),
+		macroDate: prometheus.NewDesc("econdb_macro_period_seconds",
+			"Start of the period of the latest macroeconomic value as a Unix timestamp.", []string{"series"}, nil),
+		scrapeOK: prometheus.NewDesc("econdb_scrape_success",
+			"1 if every economic value could be read from the database, 0 otherwise.", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *econCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.fxRate, c.fxDate, c.stockClose, c.stockDate, c.macroValue, c.macroDate, c.scrapeOK} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector. A failing query is logged and reported through
+// econdb_scrape_success; the other values are still exported.
+func (c *econCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ok := 1.0
+
+	session := c.state.cfg.FXSession
+	rates, err := c.state.db.ListLatestForeignExchangeRates(ctx, session)
+	if err != nil {
+		log.Printf("Metrics Error: Failed to load latest FX rates: %v", err)
+		ok = 0
+	}
+	for _, row := range rates {
+		rate, convErr := strconv.ParseFloat(row.MiddleRatePerUnit, 64)
+		if convErr != nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.fxRate, prometheus.GaugeValue, rate, row.CurrencyCode, session)
+		ch <- prometheus.MustNewConstMetric(c.fxDate, prometheus.GaugeValue, float64(row.Date.Unix()), row.CurrencyCode, session)
+	}
+
+	closes, err := c.state.db.ListLatestStockCloses(ctx)
+	if err != nil {
+		log.Printf("Metrics Error: Failed to load latest stock closes: %v", err)
+		ok = 0
+	}
+	for _, row := range closes {
+		price, convErr := strconv.ParseFloat(row.ClosingPrice, 64)
+		if convErr != nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.stockClose, prometheus.GaugeValue, price, row.StockCode)
+		ch <- prometheus.MustNewConstMetric(c.stockDate, prometheus.GaugeValue, float64(row.PriceDate.Unix()), row.StockCode)
+	}
+
+	codes := make([]string, 0, len(macroSeries))
+	for code := range macroSeries {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		points, err := loadMacroSeries(ctx, c.state, code, returnsEpoch, markettime.Today())
+		if err != nil {
+			log.Printf("Metrics Error: Failed to load macro series %s: %v", code, err)
+			ok = 0
+			continue
+		}
+		if len(points) == 0 {
+			continue
+		}
+		last := points[len(points)-1]
+		ch <- prometheus.MustNewConstMetric(c.macroValue, prometheus.GaugeValue, last.Value, code)
+		ch <- prometheus.MustNewConstMetric(c.macroDate, prometheus.GaugeValue, float64(last.Date.Unix()), code)
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeOK, prometheus.GaugeValue, ok)
+}
+
+// economicMetricsHandler serves the economic gauges in the Prometheus text format.
+func (s *apiServer) economicMetricsHandler() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(newEconCollector(s.state))
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/fxprovider"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// maxFixingCurrencies caps the currencies of one /api/fx/fixings request.
+const maxFixingCurrencies = 20
+
+// Structure for the monthly fixings of one currency
+type FxFixing struct {
+	Currency     string  `json:"currency"`
+	Month        string  `json:"month"`          // YYYY-MM
+	Average      float64 `json:"average"`        // Mean of the month's daily MYR-per-unit middle rates
+	MonthEnd     float64 `json:"month_end"`      // MYR-per-unit middle rate of MonthEndDate
+	MonthEndDate string  `json:"month_end_date"` // Last date in the month with a stored rate
+	Observations int32   `json:"observations"`   // Daily rates averaged
+	Complete     bool    `json:"complete"`       // False for the current month, whose figures may still change
+}
+
+// monthOf returns the first day of the month containing d.
+func monthOf(d time.Time) time.Time {
+	return time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, d.Location())
+}
+
+// loadMonthlyStockCloses returns the month-end closes of a stock for the months overlapping
+// [start, end] from the monthly_stock_closes view, dated on the trading day of each close so
+// they can be converted and deflated like daily closes. The last month's close is the last
+// one on or before end.
+func loadMonthlyStockCloses(ctx context.Context, s *AppState, code string, start, end time.Time) ([]database.GetStockPricesWithDetailsByCodeAndDateRangeRow, error) {
+	rows, err := s.db.GetMonthlyStockClosesWithDetailsByCodeAndDateRange(ctx, database.GetMonthlyStockClosesWithDetailsByCodeAndDateRangeParams{
+		StockCode: code,
+		StartDate: monthOf(start),
+		EndDate:   end,
+	})
+	if err != nil {
+		return nil, err
+	}
+	closes := make([]database.GetStockPricesWithDetailsByCodeAndDateRangeRow, 0, len(rows))
+	for _, row := range rows {
+		closes = append(closes, database.GetStockPricesWithDetailsByCodeAndDateRangeRow{
+			CompanyName:  row.CompanyName,
+			PriceDate:    row.LastDate,
+			ClosingPrice: row.ClosingPrice,
+			StockCode:    row.StockCode,
+		})
+	}
+	return closes, nil
+}
+
+// loadMonthlyFxRates returns the monthly average MYR-per-unit rates of a currency for the
+// months overlapping [start, end] from the monthly_fx_rates view, dated on the first of each
+// month.
+func loadMonthlyFxRates(ctx context.Context, s *AppState, code, session string, start, end time.Time) ([]database.GetForeignExchangeByCurrencyAndDateRangeRow, error) {
+	rows, err := s.db.GetMonthlyFxRatesByCurrencyAndDateRange(ctx, database.GetMonthlyFxRatesByCurrencyAndDateRangeParams{
+		CurrencyCode: code,
+		Session:      session,
+		StartDate:    monthOf(start),
+		EndDate:      monthOf(end),
+	})
+	if err != nil {
+		return nil, err
+	}
+	rates := make([]database.GetForeignExchangeByCurrencyAndDateRangeRow, 0, len(rows))
+	for _, row := range rows {
+		rates = append(rates, database.GetForeignExchangeByCurrencyAndDateRangeRow{
+			Date:              row.Month,
+			MiddleRate:        row.AvgMiddleRatePerUnit,
+			Unit:              1,
+			MiddleRatePerUnit: row.AvgMiddleRatePerUnit,
+			Source:            "monthly_average",
+		})
+	}
+	return rates, nil
+}
+
+// handleGetFxFixings returns the monthly average and month-end rates of one or more currencies,
+// the two figures usually needed for accounting, computed from the daily rates rather than the
+// monthly_fx_rates view so the current month is up to date.
+// Usage: GET /api/fx/fixings?code=USD,SGD&start_month=2024-01&end_month=2024-06&session=1200
+// start_month defaults to 11 months before end_month, which defaults to the current month.
+func (s *apiServer) handleGetFxFixings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	var codes []string
+	for _, raw := range strings.Split(p.str("code", true), ",") {
+		code := strings.ToUpper(strings.TrimSpace(raw))
+		switch {
+		case code == "" || slices.Contains(codes, code):
+			continue
+		case code == baseCurrency:
+			p.fail("code", "rates are quoted in MYR; use a foreign currency")
+		case !currencyCodePattern.MatchString(code):
+			p.fail("code", "invalid currency code %q (use a 3-letter ISO code)", code)
+		case !p.knownCurrency(code):
+			p.fail("code", "unknown currency %q (no rates stored)", code)
+		}
+		codes = append(codes, code)
+	}
+	if len(codes) > maxFixingCurrencies {
+		p.fail("code", "too many currencies (maximum %d)", maxFixingCurrencies)
+	}
+	thisMonth := monthOf(markettime.Today())
+	endMonth := p.month("end_month")
+	if endMonth.IsZero() {
+		endMonth = thisMonth
+	}
+	startMonth := p.month("start_month")
+	if startMonth.IsZero() {
+		startMonth = endMonth.AddDate(0, -11, 0)
+	}
+	if startMonth.After(endMonth) {
+		p.fail("start_month", "must not be after end_month")
+	}
+	endDate := endMonth.AddDate(0, 1, -1)
+	if p.maxRangeDays > 0 && endDate.Sub(startMonth) > time.Duration(p.maxRangeDays)*24*time.Hour {
+		p.tooLarge("start_month", "range is longer than %d days", p.maxRangeDays)
+	}
+	p.limitCost("code", len(codes), rangeDays(startMonth, endDate))
+	session := p.enum("session", "1200", fxprovider.Sessions...)
+	if !p.ok(w) {
+		return
+	}
+
+	log.Printf("API: Querying FX fixings for %s (session %s) from %s to %s", strings.Join(codes, ","), session, startMonth.Format("2006-01"), endMonth.Format("2006-01"))
+	rows, err := s.state.db.GetFxFixingsByCurrenciesAndDateRange(r.Context(), database.GetFxFixingsByCurrenciesAndDateRangeParams{
+		CurrencyCodes: codes,
+		Session:       session,
+		StartDate:     startMonth,
+		EndDate:       endDate,
+	})
+	if err != nil {
+		log.Printf("API Error: Database error fetching FX fixings: %v", err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"currency": strings.Join(codes, ",")})
+		sendInternalError(w)
+		return
+	}
+
+	response := make([]FxFixing, 0, len(rows))
+	for _, row := range rows {
+		average, err := strconv.ParseFloat(row.AvgMiddleRatePerUnit, 64)
+		if err != nil {
+			log.Printf("Error parsing average rate of %s for %s: %v", row.CurrencyCode, row.Month.Format("2006-01"), err)
+			continue
+		}
+		monthEnd, err := strconv.ParseFloat(row.MonthEndRatePerUnit, 64)
+		if err != nil {
+			log.Printf("Error parsing month-end rate of %s for %s: %v", row.CurrencyCode, row.Month.Format("2006-01"), err)
+			continue
+		}
+		response = append(response, FxFixing{
+			Currency:     row.CurrencyCode,
+			Month:        row.Month.Format("2006-01"),
+			Average:      average,
+			MonthEnd:     monthEnd,
+			MonthEndDate: row.LastDate.Format("2006-01-02"),
+			Observations: row.Observations,
+			Complete:     row.Month.Before(thisMonth),
+		})
+	}
+	sendJsonResponse(w, response)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// Structure for a news headline returned to the frontend
+type NewsHeadlineResponse struct {
+	Title       string    `json:"title"`
+	Link        string    `json:"link"`
+	Source      string    `json:"source"`
+	PublishedAt time.Time `json:"published_at"`
+	Sentiment   float64   `json:"sentiment"` // -1 (negative) to +1 (positive)
+}
+
+// handleGetStockNews returns the stored headlines about a stock, newest first, published
+// between start_date and end_date inclusive (default: the last 90 days).
+// Usage: GET /api/stock/news?code=1155&start_date=&end_date=&limit=50
+func (s *apiServer) handleGetStockNews(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	stockCode := p.stockCode("code", true)
+	start, end := p.dateRange(markettime.Today().AddDate(0, 0, -90), false)
+	limit := p.intBetween("limit", 50, 1, 1000)
+	if !p.ok(w) {
+		return
+	}
+
+	rows, err := s.state.db.ListNewsHeadlinesByStockCode(r.Context(), database.ListNewsHeadlinesByStockCodeParams{
+		StockCode:  stockCode,
+		StartTime:  start,
+		EndTime:    end.AddDate(0, 0, 1), // end_date is inclusive
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		log.Printf("API Error: Database error fetching headlines for %s: %v", stockCode, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"stock_code": stockCode})
+		sendInternalError(w)
+		return
+	}
+	response := make([]NewsHeadlineResponse, 0, len(rows))
+	for _, row := range rows {
+		sentiment, _ := strconv.ParseFloat(row.Sentiment, 64)
+		response = append(response, NewsHeadlineResponse{
+			Title:       row.Title,
+			Link:        row.Link,
+			Source:      row.Source,
+			PublishedAt: row.PublishedAt,
+			Sentiment:   sentiment,
+		})
+	}
+	sendJsonResponse(w, response)
+}
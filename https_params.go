@@ -0,0 +1,355 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/google/uuid"
+)
+
+// Structure for error responses returned to the frontend
+type APIError struct {
+	Error APIErrorBody `json:"error"`
+}
+
+// Structure for the body of an error response
+type APIErrorBody struct {
+	Code    string       `json:"code"` // Machine-readable, e.g. invalid_parameters
+	Message string       `json:"message"`
+	Params  []ParamError `json:"params,omitempty"` // One entry per rejected query parameter
+}
+
+// Structure for one rejected query parameter
+type ParamError struct {
+	Param   string `json:"param"`
+	Message string `json:"message"`
+}
+
+// sendJsonError writes the error envelope with the given status.
+func sendJsonError(w http.ResponseWriter, status int, code, message string, params ...ParamError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(APIError{Error: APIErrorBody{Code: code, Message: message, Params: params}}); err != nil {
+		log.Printf("API Error: Failed to encode JSON error response: %v", err)
+	}
+}
+
+// sendNotFound writes a 404 error envelope with message.
+func sendNotFound(w http.ResponseWriter, message string) {
+	sendJsonError(w, http.StatusNotFound, "not_found", message)
+}
+
+// sendInternalError writes a 500 error envelope. The cause is logged by the caller and never
+// sent to the client.
+func sendInternalError(w http.ResponseWriter) {
+	sendJsonError(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+}
+
+// queryParams validates the query parameters of one request. Each accessor returns the
+// parsed value (or its default) and records a ParamError when the parameter is invalid, so
+// a handler reads everything it needs and then calls ok once to reject the request with
+// every problem listed.
+type queryParams struct {
+	ctx           context.Context
+	state         *AppState
+	values        url.Values
+	errs          []ParamError
+	limits        []ParamError // Valid parameters asking for too much (see tooLarge)
+	currencies    []string     // Stored currencies, loaded on first use
+	loadedCodes   bool
+	maxRangeDays  int
+	maxSeriesDays int
+}
+
+// params starts validating the query parameters of r.
+func (s *apiServer) params(r *http.Request) *queryParams {
+	return &queryParams{
+		ctx:           r.Context(),
+		state:         s.state,
+		values:        r.URL.Query(),
+		maxRangeDays:  s.state.cfg.APIMaxRangeDays,
+		maxSeriesDays: s.state.cfg.APIMaxSeriesDays,
+	}
+}
+
+func (p *queryParams) fail(param, format string, args ...interface{}) {
+	for _, e := range p.errs {
+		if e.Param == param {
+			return // Only the first problem with each parameter is reported
+		}
+	}
+	p.errs = append(p.errs, ParamError{Param: param, Message: fmt.Sprintf(format, args...)})
+}
+
+// ok reports whether every parameter read so far was valid and within the query limits,
+// writing a 400 response with the error envelope when one was invalid, or a 422 when the
+// request is valid but too large.
+func (p *queryParams) ok(w http.ResponseWriter) bool {
+	if len(p.errs) > 0 {
+		sendJsonError(w, http.StatusBadRequest, "invalid_parameters", "Invalid query parameters", p.errs...)
+		return false
+	}
+	if len(p.limits) > 0 {
+		sendJsonError(w, http.StatusUnprocessableEntity, "query_too_large", "The request asks for more data than one request may read", p.limits...)
+		return false
+	}
+	return true
+}
+
+// has reports whether the parameter was given with a non-empty value.
+func (p *queryParams) has(name string) bool {
+	return p.values.Get(name) != ""
+}
+
+// str returns the trimmed parameter, recording an error if it is required and missing.
+func (p *queryParams) str(name string, required bool) string {
+	value := strings.TrimSpace(p.values.Get(name))
+	if value == "" && required {
+		p.fail(name, "required")
+	}
+	return value
+}
+
+// date returns a YYYY-MM-DD (or RFC 3339) parameter as a market date, or def when it is absent.
+func (p *queryParams) date(name string, def time.Time, required bool) time.Time {
+	value := p.str(name, required)
+	if value == "" {
+		return def
+	}
+	d, err := markettime.ParseDate(value)
+	if err != nil {
+		p.fail(name, "invalid date %q (use YYYY-MM-DD)", value)
+		return def
+	}
+	return d
+}
+
+// month returns a YYYY-MM parameter as the first day of that month, or the zero time when absent.
+func (p *queryParams) month(name string) time.Time {
+	value := p.str(name, false)
+	if value == "" {
+		return time.Time{}
+	}
+	m, err := time.Parse("2006-01", value)
+	if err != nil {
+		p.fail(name, "invalid month %q (use YYYY-MM)", value)
+	}
+	return m
+}
+
+// dateRange returns the start_date and end_date parameters. When optional they default to
+// defStart and today. start_date must not be after end_date, and an explicit start_date may
+// be at most API_MAX_RANGE_DAYS before end_date.
+func (p *queryParams) dateRange(defStart time.Time, required bool) (start, end time.Time) {
+	start = p.date("start_date", defStart, required)
+	end = p.date("end_date", markettime.Today(), required)
+	if start.After(end) {
+		p.fail("start_date", "must not be after end_date")
+	} else if p.maxRangeDays > 0 && p.has("start_date") && end.Sub(start) > time.Duration(p.maxRangeDays)*24*time.Hour {
+		p.tooLarge("start_date", "range is longer than %d days", p.maxRangeDays)
+	}
+	return start, end
+}
+
+// enum returns the lower-cased parameter if it is one of allowed, or def when it is absent.
+func (p *queryParams) enum(name, def string, allowed ...string) string {
+	value := strings.ToLower(p.str(name, false))
+	if value == "" {
+		return def
+	}
+	if !slices.Contains(allowed, value) {
+		p.fail(name, "invalid value %q (use %s)", value, strings.Join(allowed, ", "))
+		return def
+	}
+	return value
+}
+
+// boolean returns a true/false parameter, or def when it is absent.
+func (p *queryParams) boolean(name string, def bool) bool {
+	value := p.str(name, false)
+	if value == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		p.fail(name, "invalid value %q (use true or false)", value)
+		return def
+	}
+	return b
+}
+
+// intBetween returns an integer parameter in [min, max], or def when it is absent.
+func (p *queryParams) intBetween(name string, def, min, max int) int {
+	value := p.str(name, false)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		p.fail(name, "invalid value %q (use %d-%d)", value, min, max)
+		return def
+	}
+	return n
+}
+
+// number returns a finite decimal parameter, or 0 when it is absent.
+func (p *queryParams) number(name string, required bool) float64 {
+	value := p.str(name, required)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+		p.fail(name, "invalid number %q", value)
+		return 0
+	}
+	return n
+}
+
+// uuid returns a required UUID parameter.
+func (p *queryParams) uuid(name string) uuid.UUID {
+	value := p.str(name, true)
+	if value == "" {
+		return uuid.Nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		p.fail(name, "invalid id %q", value)
+	}
+	return id
+}
+
+// currency returns an upper-cased ISO currency code that has stored rates (MYR is always
+// accepted), recording an error otherwise.
+func (p *queryParams) currency(name string, required bool) string {
+	code := strings.ToUpper(p.str(name, required))
+	if code == "" || code == baseCurrency {
+		return code
+	}
+	if !currencyCodePattern.MatchString(code) {
+		p.fail(name, "invalid currency code %q (use a 3-letter ISO code)", code)
+		return code
+	}
+	if !p.knownCurrency(code) {
+		p.fail(name, "unknown currency %q (no rates stored)", code)
+	}
+	return code
+}
+
+// country returns an upper-cased 2-letter ISO country code, recording an error otherwise.
+func (p *queryParams) country(name string) string {
+	code := strings.ToUpper(p.str(name, false))
+	if code != "" && !countryCodePattern.MatchString(code) {
+		p.fail(name, "invalid country code %q (use a 2-letter ISO code such as MY or SG)", code)
+	}
+	return code
+}
+
+// stockCode returns an upper-cased stock code of a stored company, recording an error otherwise.
+func (p *queryParams) stockCode(name string, required bool) string {
+	code := normalizeStockCode(p.str(name, required))
+	if code == "" {
+		return code
+	}
+	if !stockCodePattern.MatchString(code) {
+		p.fail(name, "invalid stock code %q", code)
+		return code
+	}
+	if !p.knownStock(code) {
+		p.fail(name, "unknown stock code %q", code)
+	}
+	return code
+}
+
+// series returns a stock:<code> or fx:<currency> parameter naming a stored stock or currency.
+func (p *queryParams) series(name string, required bool) seriesKey {
+	value := p.str(name, required)
+	if value == "" {
+		return seriesKey{}
+	}
+	key, ok := p.seriesKey(name, value)
+	if !ok {
+		return seriesKey{}
+	}
+	return key
+}
+
+// seriesList returns a comma-separated list of series keys without duplicates, at most max long.
+func (p *queryParams) seriesList(name string, max int) []string {
+	var series []string
+	for _, raw := range strings.Split(p.str(name, false), ",") {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		key, ok := p.seriesKey(name, raw)
+		if !ok {
+			return nil
+		}
+		if !slices.Contains(series, key.String()) {
+			series = append(series, key.String())
+		}
+	}
+	if len(series) > max {
+		p.fail(name, "too many series (maximum %d)", max)
+	}
+	return series
+}
+
+func (p *queryParams) seriesKey(name, raw string) (seriesKey, bool) {
+	key, err := parseSeriesKey(raw)
+	if err != nil {
+		p.fail(name, "%v", err)
+		return key, false
+	}
+	switch key.Kind {
+	case watchlistStock:
+		if !p.knownStock(key.Code) {
+			p.fail(name, "unknown stock code %q", key.Code)
+			return key, false
+		}
+	case watchlistFx:
+		if !p.knownCurrency(key.Code) {
+			p.fail(name, "unknown currency %q (no rates stored)", key.Code)
+			return key, false
+		}
+	}
+	return key, true
+}
+
+// knownCurrency reports whether code has stored rates. Database errors are logged and the
+// code is accepted, leaving the handler's own query to fail.
+func (p *queryParams) knownCurrency(code string) bool {
+	if !p.loadedCodes {
+		currencies, err := p.state.db.ListForeignExchangeCurrencies(p.ctx)
+		if err != nil {
+			log.Printf("API Error: Failed to load currencies for validation: %v", err)
+			return true
+		}
+		p.currencies, p.loadedCodes = currencies, true
+	}
+	return slices.Contains(p.currencies, code)
+}
+
+// knownStock reports whether a company with code is stored. Database errors are logged and
+// the code is accepted.
+func (p *queryParams) knownStock(code string) bool {
+	_, err := p.state.db.GetCompanyByStockCode(p.ctx, code)
+	if err == sql.ErrNoRows {
+		return false
+	}
+	if err != nil {
+		log.Printf("API Error: Failed to look up stock %s for validation: %v", code, err)
+	}
+	return true
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/auth"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+)
+
+// Structure for a holding returned to the frontend
+type PortfolioHoldingResponse struct {
+	ID        string  `json:"id"`
+	StockCode string  `json:"stock_code"`
+	Quantity  float64 `json:"quantity"`
+	CostBasis float64 `json:"cost_basis"` // Per share, MYR
+	TradeDate string  `json:"trade_date"`
+}
+
+// Structure for one day of portfolio valuation
+type PortfolioValueDataPoint struct {
+	Date          string  `json:"date"`
+	MarketValue   float64 `json:"market_value"`
+	CostBasis     float64 `json:"cost_basis"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+	Currency      string  `json:"currency"`
+}
+
+type portfolioHoldingRequest struct {
+	StockCode string  `json:"stock_code"`
+	Quantity  float64 `json:"quantity"`
+	CostBasis float64 `json:"cost_basis"`
+	TradeDate string  `json:"trade_date"` // YYYY-MM-DD
+}
+
+// handlePortfolioHoldings serves the authenticated user's purchase lots.
+// GET lists lots; POST {"stock_code", "quantity", "cost_basis", "trade_date"} adds one; DELETE ?id= removes one.
+// Changing holdings requires the editor role.
+func (s *apiServer) handlePortfolioHoldings(w http.ResponseWriter, r *http.Request) {
+	user, _ := userFromContext(r.Context())
+
+	switch r.Method {
+	case http.MethodGet:
+		holdings, err := s.state.db.ListPortfolioHoldingsByUser(r.Context(), user.ID)
+		if err != nil {
+			log.Printf("API Error: Failed to load portfolio for %s: %v", user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		response := make([]PortfolioHoldingResponse, 0, len(holdings))
+		for _, h := range holdings {
+			lot, err := lotFromHolding(h)
+			if err != nil {
+				log.Printf("API Error: Skipping holding %s: %v", h.ID, err)
+				continue
+			}
+			response = append(response, PortfolioHoldingResponse{
+				ID:        h.ID.String(),
+				StockCode: lot.Code,
+				Quantity:  lot.Quantity,
+				CostBasis: lot.CostBasis,
+				TradeDate: lot.TradeDate.Format("2006-01-02"),
+			})
+		}
+		sendJsonResponse(w, response)
+
+	case http.MethodPost:
+		if !auth.RoleAllows(roleFromContext(r.Context()), auth.RoleEditor) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		var req portfolioHoldingRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		params, err := newPortfolioHoldingParams(r.Context(), s.state, user.ID, req.StockCode,
+			fmt.Sprint(req.Quantity), fmt.Sprint(req.CostBasis), req.TradeDate)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		holding, err := s.state.db.CreatePortfolioHolding(r.Context(), params)
+		if err != nil {
+			log.Printf("API Error: Failed to add holding for %s: %v", user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		log.Printf("API: %s added holding %s (%s)", user.Username, holding.ID, holding.StockCode)
+		sendJsonResponse(w, PortfolioHoldingResponse{
+			ID:        holding.ID.String(),
+			StockCode: holding.StockCode,
+			Quantity:  req.Quantity,
+			CostBasis: req.CostBasis,
+			TradeDate: holding.TradeDate.Format("2006-01-02"),
+		})
+
+	case http.MethodDelete:
+		if !auth.RoleAllows(roleFromContext(r.Context()), auth.RoleEditor) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		p := s.params(r)
+		id := p.uuid("id")
+		if !p.ok(w) {
+			return
+		}
+		n, err := s.state.db.DeletePortfolioHolding(r.Context(), database.DeletePortfolioHoldingParams{ID: id, UserID: user.ID})
+		if err != nil {
+			log.Printf("API Error: Failed to delete holding %s for %s: %v", id, user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		if n == 0 {
+			sendNotFound(w, "Holding not found")
+			return
+		}
+		log.Printf("API: %s removed holding %s", user.Username, id)
+		recordAudit(r.Context(), s.state, user, auditSourceAPI, "portfolio:remove", id.String(), r.RemoteAddr)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleGetPortfolioValue returns the authenticated user's daily portfolio value and unrealized P&L.
+// Query: start_date, end_date (YYYY-MM-DD), optional currency (default MYR). Values in another
+// currency are converted from MYR with the BNM noon middle rate in effect on each date.
+func (s *apiServer) handleGetPortfolioValue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _ := userFromContext(r.Context())
+
+	p := s.params(r)
+	startDate, endDate := p.dateRange(time.Time{}, true)
+	currency := p.currency("currency", false)
+	if currency == "" {
+		currency = baseCurrency
+	}
+	if !p.ok(w) {
+		return
+	}
+
+	holdings, err := s.state.db.ListPortfolioHoldingsByUser(r.Context(), user.ID)
+	if err != nil {
+		log.Printf("API Error: Failed to load portfolio for %s: %v", user.Username, err)
+		sendInternalError(w)
+		return
+	}
+
+	lots := make([]analytics.Lot, 0, len(holdings))
+	prices := make(map[string][]analytics.Point)
+	for _, h := range holdings {
+		lot, err := lotFromHolding(h)
+		if err != nil {
+			log.Printf("API Error: Skipping holding %s: %v", h.ID, err)
+			continue
+		}
+		lots = append(lots, lot)
+		if _, loaded := prices[lot.Code]; loaded {
+			continue
+		}
+		closes, err := loadStockCloses(r.Context(), s.state, lot.Code, startDate, endDate)
+		if err != nil {
+			log.Printf("API Error: %v", err)
+			sendInternalError(w)
+			return
+		}
+		prices[lot.Code] = closes
+	}
+
+	var fxRates []analytics.Point
+	if currency != baseCurrency {
+		fxRates, err = loadFxPerUnit(r.Context(), s.state, currency, startDate, endDate)
+		if err != nil {
+			log.Printf("API Error: %v", err)
+			sendInternalError(w)
+			return
+		}
+		if len(fxRates) == 0 {
+			http.Error(w, fmt.Sprintf("No FX rates stored for %s", currency), http.StatusBadRequest)
+			return
+		}
+	}
+
+	values := analytics.ValuePortfolio(lots, prices, startDate, endDate)
+	response := make([]PortfolioValueDataPoint, 0, len(values))
+	for _, v := range values {
+		divisor := 1.0
+		if currency != baseCurrency {
+			rate, ok := analytics.ValueAt(fxRates, v.Date)
+			if !ok || rate == 0 {
+				continue // No rate yet for this date
+			}
+			divisor = rate
+		}
+		response = append(response, PortfolioValueDataPoint{
+			Date:          v.Date.Format("2006-01-02"),
+			MarketValue:   v.MarketValue / divisor,
+			CostBasis:     v.CostBasis / divisor,
+			UnrealizedPnL: v.UnrealizedPnL / divisor,
+			Currency:      currency,
+		})
+	}
+
+	log.Printf("API: Valued portfolio of %s (%d lots) on %d dates in %s", user.Username, len(lots), len(response), currency)
+	sendJsonResponse(w, response)
+}
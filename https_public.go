@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+)
+
+// defaultPublicEndpoints are the route patterns served in public read-only mode when
+// PUBLIC_ENDPOINTS is not set: the public data endpoints, the status pages and the frontend.
+var defaultPublicEndpoints = []string{
+	"/api/stocks",
+	"/api/stocks/latest",
+	"/api/sectors/flows",
+	"/api/stock/prices",
+	"/api/stock/intraday",
+	"/api/stock/beta",
+	"/api/stock/news",
+	"/api/stock/documents",
+	"/api/stock/documents/file",
+	"/api/fx/rates",
+	"/api/fx/reer",
+	"/api/fx/convert",
+	"/api/fx/fixings",
+	"/api/fx/spreads",
+	"/api/analytics/returns",
+	"/api/analytics/sentiment",
+	"/api/analytics/volatility",
+	"/api/analytics/drawdown",
+	"/api/analytics/correlation",
+	"/api/backtest",
+	"/api/screener",
+	"/api/macro/series",
+	"/api/macro/decompose",
+	"/api/annotations",
+	"/api/search",
+	"/api/lineage",
+	"/api/diff",
+	"/api/calendar/entitlements",
+	"/api/status",
+	"/status",
+	"/",
+}
+
+// publicReadOnly restricts mux to the PUBLIC_ENDPOINTS (or defaultPublicEndpoints) when
+// PUBLIC_READ_ONLY is set, so the dataset can be shared openly: every other route (login,
+// admin, ingest, per-user data) answers 404 as if it did not exist, only GET and HEAD are
+// accepted, and successful responses may be cached by browsers and CDNs for
+// PUBLIC_CACHE_MAX_AGE. Routes are matched by the pattern mux resolves a request to, so the
+// frontend's client-side paths fall under "/". It returns mux unchanged otherwise.
+func publicReadOnly(s *AppState, mux *http.ServeMux) http.Handler {
+	if !s.cfg.PublicReadOnly {
+		return mux
+	}
+	allowed := s.cfg.PublicEndpoints
+	if len(allowed) == 0 {
+		allowed = defaultPublicEndpoints
+	}
+	for _, endpoint := range allowed {
+		if !slices.Contains(defaultPublicEndpoints, endpoint) {
+			log.Printf("Warning: PUBLIC_ENDPOINTS entry %s is not a public read endpoint; it is served without login.", endpoint)
+		}
+	}
+	log.Printf("Public read-only mode: serving %d endpoint(s) without login; all other routes are disabled.", len(allowed))
+	cacheControl := fmt.Sprintf("public, max-age=%d", int(s.cfg.PublicCacheMaxAge.Seconds()))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); !slices.Contains(allowed, pattern) {
+			sendNotFound(w, "Not found")
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		mux.ServeHTTP(&publicCacheWriter{ResponseWriter: w, cacheControl: cacheControl}, r)
+	})
+}
+
+// publicCacheWriter adds the Cache-Control header to successful responses only, so errors are
+// not kept by caches.
+type publicCacheWriter struct {
+	http.ResponseWriter
+	cacheControl string
+	wroteHeader  bool
+}
+
+func (w *publicCacheWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if status == http.StatusOK && w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", w.cacheControl)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *publicCacheWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
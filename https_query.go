@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/lib/pq"
+)
+
+// maxSandboxQueryLength caps the SQL text of one /api/query request.
+const maxSandboxQueryLength = 4000
+
+// sandboxBlockedNames are functions and catalogs a sandbox query may not mention. They only
+// turn away obvious misuse early with a clear message: quoted and escaped identifiers get
+// around any pattern, so the boundary is the query_sandbox login role /api/query connects as
+// (see openQueryDB), which can read nothing but the sandbox views.
+// String literals containing them are rejected too, which is the price of not parsing SQL.
+var sandboxBlockedNames = regexp.MustCompile(`(?i)\b(set_config|pg_\w+|information_schema|dblink\w*|lo_\w+|current_setting|\w+_to_xml\w*)\b`)
+
+// Structure of an /api/query request
+type sandboxQueryRequest struct {
+	Query string `json:"query"` // One SELECT (or WITH ... SELECT) over the sandbox views
+	Limit int    `json:"limit"` // Rows returned, at most QUERY_MAX_ROWS (default)
+}
+
+// Structure of an /api/query result
+type SandboxQueryResponse struct {
+	Columns   []string `json:"columns"`
+	Rows      [][]any  `json:"rows"`
+	Truncated bool     `json:"truncated"` // True when the query had more rows than the limit
+}
+
+// errSandboxQuery is returned by validateSandboxQuery for a query that may not be run.
+type errSandboxQuery string
+
+func (e errSandboxQuery) Error() string { return string(e) }
+
+// validateSandboxQuery checks that query is a single SELECT with no comments or blocked names
+// and returns it without a trailing semicolon.
+func validateSandboxQuery(query string) (string, error) {
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	if query == "" {
+		return "", errSandboxQuery("query is required")
+	}
+	if len(query) > maxSandboxQueryLength {
+		return "", errSandboxQuery(fmt.Sprintf("query is longer than %d characters", maxSandboxQueryLength))
+	}
+	first := strings.ToLower(strings.Fields(query)[0])
+	if first != "select" && first != "with" {
+		return "", errSandboxQuery("only SELECT queries are allowed")
+	}
+	if strings.Contains(query, ";") {
+		return "", errSandboxQuery("only one statement is allowed")
+	}
+	if strings.Contains(query, "--") || strings.Contains(query, "/*") {
+		return "", errSandboxQuery("comments are not allowed")
+	}
+	if name := sandboxBlockedNames.FindString(query); name != "" {
+		return "", errSandboxQuery(fmt.Sprintf("%s is not allowed", name))
+	}
+	return query, nil
+}
+
+// handleQuery runs an analyst's read-only SELECT against the views in the sandbox schema
+// (fx_rates, stock_prices, companies, stock_ratios, macro_observations, daily_returns,
+// monthly_fx_rates and monthly_stock_closes), without handing out database credentials.
+// The query runs in a read-only transaction on the QUERY_DB_URL connection, logged in as the
+// query_sandbox role, with at most QUERY_MAX_ROWS rows returned and QUERY_TIMEOUT to finish.
+// Usage: POST /api/query {"query": "SELECT date, middle_rate_per_unit FROM fx_rates WHERE currency_code = 'USD'", "limit": 100}
+func (s *apiServer) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	maxRows := s.state.cfg.QueryMaxRows
+	if maxRows == 0 {
+		sendNotFound(w, "The query endpoint is disabled (QUERY_MAX_ROWS=0)")
+		return
+	}
+	if s.state.queryDB == nil {
+		sendNotFound(w, "The query endpoint is disabled (no QUERY_DB_URL)")
+		return
+	}
+	user, _ := userFromContext(r.Context())
+
+	var req sandboxQueryRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	query, err := validateSandboxQuery(req.Query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := maxRows
+	if req.Limit < 0 || req.Limit > maxRows {
+		http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxRows), http.StatusBadRequest)
+		return
+	}
+	if req.Limit > 0 {
+		limit = req.Limit
+	}
+
+	log.Printf("API: %s running sandbox query (limit %d): %s", user.Username, limit, query)
+	started := time.Now()
+	response, err := runSandboxQuery(r.Context(), s.state, query, limit)
+	var pqErr *pq.Error
+	switch {
+	case errors.As(err, &pqErr) && pqErr.Code == "57014": // query_canceled, by statement_timeout
+		http.Error(w, fmt.Sprintf("Query took longer than %s", s.state.cfg.QueryTimeout), http.StatusGatewayTimeout)
+		return
+	case errors.As(err, &pqErr) && (pqErr.Code.Class() == "42" || pqErr.Code.Class() == "22"):
+		// Syntax, unknown or forbidden relation, or a bad value: the caller's query is at fault
+		http.Error(w, "Query failed: "+pqErr.Message, http.StatusBadRequest)
+		return
+	case err != nil:
+		log.Printf("API Error: Sandbox query for %s failed: %v", user.Username, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"user": user.Username})
+		sendInternalError(w)
+		return
+	}
+	log.Printf("API: Sandbox query for %s returned %d row(s) in %s", user.Username, len(response.Rows), time.Since(started).Round(time.Millisecond))
+	sendJsonResponse(w, response)
+}
+
+// openQueryDB opens /api/query's connection pool and checks that it logs in as a role of its
+// own: not the application's role (the one app is logged in as), not a superuser and not a
+// member of the application's role, so no query can SET ROLE its way to the user tables.
+func openQueryDB(ctx context.Context, app *sql.DB, url string) (*sql.DB, error) {
+	var appRole string
+	if err := app.QueryRowContext(ctx, "SELECT current_user").Scan(&appRole); err != nil {
+		return nil, fmt.Errorf("failed to read the application role: %w", err)
+	}
+	queryDB, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare the query sandbox connection: %w", err)
+	}
+	var role string
+	var super, member bool
+	err = queryDB.QueryRowContext(ctx, `SELECT current_user, r.rolsuper, pg_has_role(current_user, $1::name, 'MEMBER')
+		FROM pg_roles r WHERE r.rolname = current_user`, appRole).Scan(&role, &super, &member)
+	switch {
+	case err != nil:
+		err = fmt.Errorf("failed to connect the query sandbox: %w", err)
+	case role == appRole || member:
+		err = fmt.Errorf("QUERY_DB_URL logs in as %s, which can act as the application role %s", role, appRole)
+	case super:
+		err = fmt.Errorf("QUERY_DB_URL logs in as %s, a superuser", role)
+	}
+	if err != nil {
+		queryDB.Close()
+		return nil, err
+	}
+	return queryDB, nil
+}
+
+// runSandboxQuery runs a validated query with its limit applied. The query is wrapped as a
+// subquery and sent with a parameter, so the server rejects anything but one statement.
+func runSandboxQuery(ctx context.Context, s *AppState, query string, limit int) (SandboxQueryResponse, error) {
+	tx, err := s.queryDB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return SandboxQueryResponse{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	for _, stmt := range []string{
+		"SET LOCAL search_path = sandbox",
+		fmt.Sprintf("SET LOCAL statement_timeout = %d", s.cfg.QueryTimeout.Milliseconds()),
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return SandboxQueryResponse{}, fmt.Errorf("failed to prepare sandbox (%s): %w", stmt, err)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT * FROM ("+query+") AS sandbox_query LIMIT $1", limit+1)
+	if err != nil {
+		return SandboxQueryResponse{}, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return SandboxQueryResponse{}, err
+	}
+	response := SandboxQueryResponse{Columns: columns, Rows: [][]any{}}
+	for rows.Next() {
+		if len(response.Rows) == limit {
+			response.Truncated = true
+			break
+		}
+		values := make([]any, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return SandboxQueryResponse{}, err
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b) // Decimals and text arrive as bytes
+			}
+		}
+		response.Rows = append(response.Rows, values)
+	}
+	return response, rows.Err()
+}
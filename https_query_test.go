@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSandboxQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr string
+	}{
+		{name: "select", query: "SELECT * FROM fx_rates", want: "SELECT * FROM fx_rates"},
+		{name: "trailing semicolon and space", query: "  select date from fx_rates; ", want: "select date from fx_rates"},
+		{name: "with", query: "WITH r AS (SELECT 1) SELECT * FROM r", want: "WITH r AS (SELECT 1) SELECT * FROM r"},
+		{name: "empty", query: " ; ", wantErr: "query is required"},
+		{name: "too long", query: "SELECT " + strings.Repeat("1", maxSandboxQueryLength), wantErr: "longer than"},
+		{name: "update", query: "UPDATE users SET role = 'admin'", wantErr: "only SELECT"},
+		{name: "second statement", query: "SELECT 1; DROP TABLE users", wantErr: "only one statement"},
+		{name: "line comment", query: "SELECT 1 -- hidden", wantErr: "comments are not allowed"},
+		{name: "block comment", query: "SELECT /* hidden */ 1", wantErr: "comments are not allowed"},
+		{name: "catalog function", query: "SELECT pg_sleep(10)", wantErr: "pg_sleep is not allowed"},
+		{name: "blocked name in any case", query: "select SET_CONFIG('role', 'x', false)", wantErr: "SET_CONFIG is not allowed"},
+		{name: "information schema", query: "SELECT * FROM information_schema.tables", wantErr: "information_schema is not allowed"},
+		{name: "blocked name in a literal", query: "SELECT 'dblink' AS name", wantErr: "dblink is not allowed"},
+		{name: "name containing a blocked word", query: "SELECT lo_value FROM stock_prices", wantErr: "lo_value is not allowed"},
+		{name: "column with pg inside", query: "SELECT spg_rate FROM fx_rates", want: "SELECT spg_rate FROM fx_rates"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateSandboxQuery(tt.query)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("validateSandboxQuery(%q) error = %v, want one containing %q", tt.query, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateSandboxQuery(%q) error = %v", tt.query, err)
+			}
+			if got != tt.want {
+				t.Errorf("validateSandboxQuery(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+)
+
+// Structure for the stocks matching a screen
+type ScreenerResponse struct {
+	Filters []string         `json:"filters"`
+	Total   int              `json:"total"` // Matches before limit
+	Stocks  []ScreenerResult `json:"stocks"`
+}
+
+// handleGetScreener screens every company on its latest stored ratios and closes. filter holds
+// comma-separated expressions, all of which must hold (it may also be repeated): pe, dy, pb,
+// roe, price, off_high (percent below the 52-week high close) and off_low (percent above the
+// 52-week low close) compare with <, <=, >, >=, = or !=; sector, subsector and country with = or
+// != (case-insensitive). A stock without a filtered value stored does not match. country=XX is
+// short for filter=country=XX, as on the other list endpoints.
+// GET /api/screener?filter=sector=Financial Services,pe<15,dy>=4,off_high>=20[&country=MY][&sort=dy&order=desc][&limit=100]
+func (s *apiServer) handleGetScreener(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	var filters []screenerFilter
+	var expressions []string
+	for _, raw := range p.values["filter"] {
+		parsed, err := parseScreenerFilters(raw)
+		if err != nil {
+			p.fail("filter", "%v", err)
+			continue
+		}
+		filters = append(filters, parsed...)
+	}
+	if country := p.country("country"); country != "" {
+		filters = append(filters, screenerFilter{Field: "country", Op: "=", Text: country})
+	}
+	for _, f := range filters {
+		expressions = append(expressions, f.Field+f.Op+f.Text)
+	}
+	sortField := p.enum("sort", "code", append([]string{"code"}, screenerNumericFields...)...)
+	desc := p.enum("order", "asc", "asc", "desc") == "desc"
+	limit := p.intBetween("limit", 100, 1, 1000)
+	if !p.ok(w) {
+		return
+	}
+
+	log.Printf("API: Screening stocks on %s", strings.Join(expressions, ", "))
+	results, err := screenStocks(r.Context(), s.state, filters, sortField, desc)
+	if err != nil {
+		log.Printf("API Error: %v", err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"filter": strings.Join(expressions, ",")})
+		sendInternalError(w)
+		return
+	}
+	response := ScreenerResponse{Filters: append([]string{}, expressions...), Total: len(results), Stocks: results}
+	if len(results) > limit {
+		response.Stocks = results[:limit]
+	}
+	sendJsonResponse(w, response)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+)
+
+// searchKinds are the kinds of record GET /api/search covers.
+var searchKinds = []string{"company", "news", "event", "document"}
+
+// Structure for one search hit returned to the frontend
+type SearchResultItem struct {
+	Type      string     `json:"type"` // company, news, event or document
+	Title     string     `json:"title"`
+	Detail    string     `json:"detail,omitempty"` // Sector, event category or report period
+	StockCode string     `json:"stock_code,omitempty"`
+	Country   string     `json:"country,omitempty"`
+	Date      *time.Time `json:"date,omitempty"`
+	URL       string     `json:"url,omitempty"` // Article link, or where this API serves a document
+	Rank      float32    `json:"rank"`
+}
+
+// searchResultFromDB converts a search row; document URLs are prefixed with basePath.
+func searchResultFromDB(row database.SearchRow, basePath string) SearchResultItem {
+	item := SearchResultItem{
+		Type:      row.Kind,
+		Title:     row.Title,
+		Detail:    row.Detail,
+		StockCode: row.StockCode,
+		Country:   row.Country,
+		Rank:      row.Rank,
+	}
+	if row.OccurredAt.Valid {
+		item.Date = &row.OccurredAt.Time
+	}
+	switch row.Kind {
+	case "news":
+		item.URL, item.Detail = row.Detail, "" // The detail column carries the article link
+	case "document":
+		item.URL = basePath + "/api/stock/documents/file?id=" + row.Ref
+	}
+	return item
+}
+
+// handleSearch runs a full-text search across companies, news headlines, events and report
+// documents. q takes web search syntax: quoted phrases, OR, and -word to exclude.
+// Usage: GET /api/search?q=maybank+dividend[&type=company|news|event|document][&country=MY][&limit=20]
+func (s *apiServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	query := p.str("q", true)
+	kind := p.enum("type", "", searchKinds...)
+	country := p.country("country")
+	limit := p.intBetween("limit", 20, 1, 100)
+	if len(query) > 200 {
+		p.fail("q", "must be at most 200 characters")
+	}
+	if !p.ok(w) {
+		return
+	}
+
+	rows, err := s.state.db.Search(r.Context(), database.SearchParams{
+		Query:      query,
+		Kind:       sql.NullString{String: kind, Valid: kind != ""},
+		Country:    sql.NullString{String: country, Valid: country != ""},
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		log.Printf("API Error: Database error searching for %q: %v", query, err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"endpoint": "search"})
+		sendInternalError(w)
+		return
+	}
+	response := make([]SearchResultItem, 0, len(rows))
+	for _, row := range rows {
+		response = append(response, searchResultFromDB(row, s.state.cfg.BasePath))
+	}
+	sendJsonResponse(w, response)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// Structure for an /api/sectors/flows response
+type SectorFlowsResponse struct {
+	StartDate   string            `json:"start_date"`
+	EndDate     string            `json:"end_date"`
+	TradedValue float64           `json:"traded_value"` // Across all sectors, in MYR
+	Sectors     []SectorFlowTotal `json:"sectors"`      // Largest traded value first
+}
+
+// handleGetSectorFlows ranks the sectors by traded value (close times volume) over a range, with
+// each sector's share of the total and its per-day values. Only closes stored with a volume are
+// counted. The range defaults to the last 7 days. Only DEFAULT_COUNTRY closes carry a volume,
+// so any other country has no flows.
+// Usage: GET /api/sectors/flows[?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD][&country=MY]
+func (s *apiServer) handleGetSectorFlows(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	start, end := p.dateRange(markettime.Today().AddDate(0, 0, -(sectorFlowRefreshDays-1)), false)
+	country := p.country("country")
+	if !p.ok(w) {
+		return
+	}
+	if country != "" && country != s.state.cfg.DefaultCountry {
+		sendJsonResponse(w, SectorFlowsResponse{StartDate: start.Format("2006-01-02"), EndDate: end.Format("2006-01-02"), Sectors: []SectorFlowTotal{}})
+		return
+	}
+
+	log.Printf("API: Querying sector flows from %s to %s", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	totals, err := sectorFlowTotals(r.Context(), s.state, start, end)
+	if err != nil {
+		log.Printf("API Error: Database error fetching sector flows: %v", err)
+		errreport.CaptureError(r.Context(), err, nil)
+		sendInternalError(w)
+		return
+	}
+
+	response := SectorFlowsResponse{StartDate: start.Format("2006-01-02"), EndDate: end.Format("2006-01-02"), Sectors: totals}
+	for _, t := range totals {
+		response.TradedValue += t.TradedValue
+	}
+	sendJsonResponse(w, response)
+}
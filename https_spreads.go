@@ -0,0 +1,104 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/fxprovider"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// Structure for the buy/sell spread of one BNM rate
+type FxSpread struct {
+	Date      string   `json:"date"`
+	Buying    float64  `json:"buying"`     // MYR per 1 unit of the currency
+	Selling   float64  `json:"selling"`    // MYR per 1 unit of the currency
+	Spread    float64  `json:"spread"`     // Selling minus buying, MYR per 1 unit
+	SpreadPct *float64 `json:"spread_pct"` // Spread as a percentage of the middle rate
+}
+
+// Structure for an /api/fx/spreads response
+type FxSpreadsResponse struct {
+	Currency   string     `json:"currency"`
+	Session    string     `json:"session"`
+	Spreads    []FxSpread `json:"spreads"`
+	AveragePct *float64   `json:"average_pct"` // Mean spread_pct over the range; null without rates
+	MinPct     *float64   `json:"min_pct"`
+	MaxPct     *float64   `json:"max_pct"`
+}
+
+// handleGetFxSpreads returns the buy/sell spread of a currency's BNM rates per date, per unit
+// and as a percentage of the middle rate, with the range's mean, lowest and highest percentage.
+// Rates from other providers carry a single value and are not included. The range defaults to
+// the last year; session defaults to 1200.
+// Usage: GET /api/fx/spreads?code=USD[&start_date=YYYY-MM-DD&end_date=YYYY-MM-DD][&session=0900|1200|1700]
+func (s *apiServer) handleGetFxSpreads(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := s.params(r)
+	code := p.currency("code", true)
+	if code == baseCurrency {
+		p.fail("code", "rates are quoted in MYR; use a foreign currency")
+	}
+	start, end := p.dateRange(markettime.Today().AddDate(-1, 0, 0), false)
+	session := p.enum("session", "1200", fxprovider.Sessions...)
+	if !p.ok(w) {
+		return
+	}
+
+	log.Printf("API: Querying FX spreads for %s (session %s) from %s to %s", code, session, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	rows, err := s.state.db.GetFxSpreadsByCurrencyAndDateRange(r.Context(), database.GetFxSpreadsByCurrencyAndDateRangeParams{
+		CurrencyCode: code,
+		Session:      session,
+		StartDate:    start,
+		EndDate:      end,
+	})
+	if err != nil {
+		log.Printf("API Error: Database error fetching FX spreads: %v", err)
+		errreport.CaptureError(r.Context(), err, map[string]string{"currency": code})
+		sendInternalError(w)
+		return
+	}
+
+	response := FxSpreadsResponse{Currency: code, Session: session, Spreads: make([]FxSpread, 0, len(rows))}
+	var sumPct float64
+	pcts := 0
+	for _, row := range rows {
+		buying, errBuy := strconv.ParseFloat(row.BuyingRate, 64)
+		selling, errSell := strconv.ParseFloat(row.SellingRate, 64)
+		spread, errSpread := strconv.ParseFloat(row.SpreadPerUnit.String, 64)
+		if errBuy != nil || errSell != nil || errSpread != nil || row.Unit <= 0 {
+			log.Printf("Error parsing the %s rates of %s; skipping the date", code, row.Date.Format("2006-01-02"))
+			continue
+		}
+		spreadPct := nullableFloat(row.SpreadPct)
+		response.Spreads = append(response.Spreads, FxSpread{
+			Date:      row.Date.Format("2006-01-02"),
+			Buying:    buying / float64(row.Unit),
+			Selling:   selling / float64(row.Unit),
+			Spread:    spread,
+			SpreadPct: spreadPct,
+		})
+		if spreadPct == nil {
+			continue
+		}
+		sumPct += *spreadPct
+		pcts++
+		if response.MinPct == nil || *spreadPct < *response.MinPct {
+			response.MinPct = spreadPct
+		}
+		if response.MaxPct == nil || *spreadPct > *response.MaxPct {
+			response.MaxPct = spreadPct
+		}
+	}
+	if pcts > 0 {
+		average := sumPct / float64(pcts)
+		response.AveragePct = &average
+	}
+	sendJsonResponse(w, response)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+)
+
+// Structure for one data source's freshness returned by /api/status
+type DataSourceStatus struct {
+	Source         string     `json:"source"`
+	LastSuccessAt  *time.Time `json:"last_success_at,omitempty"`
+	LastErrorAt    *time.Time `json:"last_error_at,omitempty"`
+	LastErrors     int        `json:"last_errors,omitempty"`      // Failed fetches and stores of the last failed run; the messages are in /api/admin/runs
+	LatestDataDate string     `json:"latest_data_date,omitempty"` // YYYY-MM-DD
+	Stale          bool       `json:"stale"`                      // No data, or latest data older than STATUS_STALE_AFTER
+}
+
+// statusSource describes how to assess one data source: the batch commands that fill it
+// (looked up in fetch_runs) and how to find its most recent data.
+type statusSource struct {
+	Name        string
+	Commands    []string
+	MarketDates bool // Latest is a market date, so weekends and market holidays do not count towards staleness
+	Latest      func(ctx context.Context, db *database.Queries) (time.Time, error)
+}
+
+var statusSources = []statusSource{
+	{
+		Name:        "fx_rates",
+		Commands:    []string{"fx:fetch_all", "fx:fetch:range"},
+		MarketDates: true,
+		Latest: func(ctx context.Context, db *database.Queries) (time.Time, error) {
+			return db.GetLatestForeignExchangeDate(ctx)
+		},
+	},
+	{
+		Name:        "stock_prices",
+		Commands:    []string{"stock:fetch:price_all", "stock:fetch:profile_all"},
+		MarketDates: true,
+		Latest: func(ctx context.Context, db *database.Queries) (time.Time, error) {
+			return db.GetLatestStockPriceDate(ctx)
+		},
+	},
+	{
+		Name:     "company_profiles",
+		Commands: []string{"stock:fetch:profile_all"},
+		Latest: func(ctx context.Context, db *database.Queries) (time.Time, error) {
+			scraped, err := db.GetLatestProfileScrapedAt(ctx)
+			return scraped.Time, err
+		},
+	},
+	{
+		Name:        "neer",
+		Commands:    []string{"fx:eer:compute"},
+		MarketDates: true,
+		Latest: func(ctx context.Context, db *database.Queries) (time.Time, error) {
+			return db.GetLatestEffectiveExchangeRateDate(ctx, "neer")
+		},
+	},
+}
+
+// collectStatus assesses every data source. Lookup errors are logged and leave the affected
+// fields empty rather than failing the whole status.
+func (s *apiServer) collectStatus(ctx context.Context) []DataSourceStatus {
+	cal, err := loadTradingCalendar(ctx, s.state)
+	if err != nil {
+		log.Printf("API Error: %v", err)
+	}
+	statuses := make([]DataSourceStatus, 0, len(statusSources))
+	for _, src := range statusSources {
+		st := DataSourceStatus{Source: src.Name, Stale: true}
+
+		if len(src.Commands) > 0 {
+			success, err := s.state.db.GetLatestFetchRunByStatus(ctx, database.GetLatestFetchRunByStatusParams{
+				Commands: src.Commands,
+				Statuses: []string{fetchRunSucceeded, fetchRunPartial},
+			})
+			if err == nil && success.FinishedAt.Valid {
+				st.LastSuccessAt = &success.FinishedAt.Time
+			} else if err != nil && err != sql.ErrNoRows {
+				log.Printf("API Error: Failed to load last successful run for %s: %v", src.Name, err)
+			}
+
+			failure, err := s.state.db.GetLatestFetchRunByStatus(ctx, database.GetLatestFetchRunByStatusParams{
+				Commands: src.Commands,
+				Statuses: []string{fetchRunFailed, fetchRunPartial},
+			})
+			if err == nil {
+				at := failure.StartedAt
+				if failure.FinishedAt.Valid {
+					at = failure.FinishedAt.Time
+				}
+				st.LastErrorAt = &at
+				// Only a count: the messages can name hosts, queries and upstream URLs, and
+				// this status is public
+				st.LastErrors = int(failure.FailedFetches + failure.FailedStores)
+				if st.LastErrors == 0 {
+					st.LastErrors = 1 // The run was aborted by one error
+				}
+			} else if err != sql.ErrNoRows {
+				log.Printf("API Error: Failed to load last failed run for %s: %v", src.Name, err)
+			}
+		}
+
+		latest, err := src.Latest(ctx, s.state.db)
+		switch {
+		case err == sql.ErrNoRows || (err == nil && latest.IsZero()):
+			// No data yet; stays stale
+		case err != nil:
+			log.Printf("API Error: Failed to load latest data date for %s: %v", src.Name, err)
+		default:
+			st.LatestDataDate = latest.Format("2006-01-02")
+			// The next data is only due on the following trading day
+			due := latest
+			if src.MarketDates {
+				due = cal.NextTradingDay(latest)
+			}
+			st.Stale = time.Since(due) > s.state.cfg.StatusStaleAfter
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
+
+// handleGetStatus returns the freshness of each data source.
+func (s *apiServer) handleGetStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sendJsonResponse(w, s.collectStatus(r.Context()))
+}
+
+var statusPageTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Malaysia Econ DB - Status</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.4em 0.8em; text-align: left; }
+tr.stale td { background: #fdecea; }
+tr.fresh td { background: #edf7ed; }
+</style>
+</head>
+<body>
+<h1>Data status</h1>
+<p>Generated {{.Generated.Format "2006-01-02 15:04:05 MST"}}. Sources with no data newer than {{.StaleAfter}} are marked stale.</p>
+<table>
+<tr><th>Source</th><th>Latest data</th><th>Last successful fetch</th><th>Last error</th></tr>
+{{range .Sources}}<tr class="{{if .Stale}}stale{{else}}fresh{{end}}">
+<td>{{.Source}}</td>
+<td>{{if .LatestDataDate}}{{.LatestDataDate}}{{else}}none{{end}}</td>
+<td>{{with .LastSuccessAt}}{{.Format "2006-01-02 15:04"}}{{else}}-{{end}}</td>
+<td>{{with .LastErrorAt}}{{.Format "2006-01-02 15:04"}}{{end}} {{with .LastErrors}}({{.}} error(s)){{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// handleStatusPage renders the data source status as a simple HTML page.
+func (s *apiServer) handleStatusPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	data := struct {
+		Generated  time.Time
+		StaleAfter time.Duration
+		Sources    []DataSourceStatus
+	}{
+		Generated:  time.Now(),
+		StaleAfter: s.state.cfg.StatusStaleAfter,
+		Sources:    s.collectStatus(r.Context()),
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := statusPageTemplate.Execute(w, data); err != nil {
+		log.Printf("API Error: Failed to render status page: %v", err)
+	}
+}
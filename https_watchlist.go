@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/auth"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/google/uuid"
+)
+
+// Structure for a watchlist entry returned to the frontend
+type WatchlistItemResponse struct {
+	Type    string    `json:"type"` // stock or fx
+	Code    string    `json:"code"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+type watchlistItemRequest struct {
+	Type string `json:"type"`
+	Code string `json:"code"`
+}
+
+// handleWatchlists serves the authenticated user's watchlist.
+// GET lists items; POST {"type": "stock", "code": "1155"} adds one; DELETE ?type=stock&code=1155 removes one.
+// Viewers may read their list, changing it requires the editor role.
+func (s *apiServer) handleWatchlists(w http.ResponseWriter, r *http.Request) {
+	user, _ := userFromContext(r.Context())
+
+	switch r.Method {
+	case http.MethodGet:
+		items, err := s.state.db.ListWatchlistItemsByUser(r.Context(), user.ID)
+		if err != nil {
+			log.Printf("API Error: Failed to load watchlist for %s: %v", user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		response := make([]WatchlistItemResponse, 0, len(items))
+		for _, item := range items {
+			response = append(response, watchlistItemResponseFromDB(item))
+		}
+		sendJsonResponse(w, response)
+
+	case http.MethodPost:
+		if !auth.RoleAllows(roleFromContext(r.Context()), auth.RoleEditor) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		var req watchlistItemRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		itemType, code, err := newWatchlistItem(r.Context(), s.state, req.Type, req.Code)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		item, err := s.state.db.AddWatchlistItem(r.Context(), database.AddWatchlistItemParams{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			ItemType: itemType,
+			Code:     code,
+		})
+		if err != nil {
+			log.Printf("API Error: Failed to add %s %s to watchlist for %s: %v", itemType, code, user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		log.Printf("API: %s added %s %s to their watchlist", user.Username, itemType, code)
+		sendJsonResponse(w, watchlistItemResponseFromDB(item))
+
+	case http.MethodDelete:
+		if !auth.RoleAllows(roleFromContext(r.Context()), auth.RoleEditor) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		p := s.params(r)
+		itemType := p.enum("type", "", watchlistStock, watchlistFx)
+		if itemType == "" {
+			p.fail("type", "required (use %s or %s)", watchlistStock, watchlistFx)
+		}
+		code := strings.ToUpper(p.str("code", true))
+		if itemType != "" && code != "" {
+			var err error
+			if _, code, err = normalizeWatchlistItem(itemType, code); err != nil {
+				p.fail("code", "%v", err)
+			}
+		}
+		if !p.ok(w) {
+			return
+		}
+		n, err := s.state.db.DeleteWatchlistItem(r.Context(), database.DeleteWatchlistItemParams{
+			UserID:   user.ID,
+			ItemType: itemType,
+			Code:     code,
+		})
+		if err != nil {
+			log.Printf("API Error: Failed to remove %s %s from watchlist for %s: %v", itemType, code, user.Username, err)
+			sendInternalError(w)
+			return
+		}
+		if n == 0 {
+			sendNotFound(w, "Item not on watchlist")
+			return
+		}
+		log.Printf("API: %s removed %s %s from their watchlist", user.Username, itemType, code)
+		recordAudit(r.Context(), s.state, user, auditSourceAPI, "watchlist:remove", itemType+" "+code, r.RemoteAddr)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func watchlistItemResponseFromDB(item database.WatchlistItem) WatchlistItemResponse {
+	return WatchlistItemResponse{Type: item.ItemType, Code: item.Code, AddedAt: item.AddedAt}
+}
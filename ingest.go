@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/auth"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/google/uuid"
+)
+
+// Ingest limits
+const (
+	maxIngestObservations = 10000
+	ingestClockSkew       = 5 * time.Minute // How far sent_at may be from the server's clock
+)
+
+var ingestSourceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// ingestObservation is one pushed value of a source's series.
+type ingestObservation struct {
+	Date  time.Time
+	Value float64
+}
+
+// parseIngestSeries validates the series an ingest source feeds: stock:<code>, fx:<currency>
+// or macro:<series>. It returns the series in canonical form.
+func parseIngestSeries(raw string) (string, error) {
+	if code, ok := strings.CutPrefix(strings.TrimSpace(raw), "macro:"); ok {
+		code = strings.ToLower(code)
+		// Only registered series can be read back (the macro endpoints, Grafana, Sheets)
+		if _, ok := macroSeries[code]; !ok {
+			return "", fmt.Errorf("unknown macro series %q", code)
+		}
+		if code == activitySeries {
+			return "", fmt.Errorf("macro series %q is derived and cannot be pushed", code)
+		}
+		return "macro:" + code, nil
+	}
+	key, err := parseSeriesKey(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid series %q (use stock:<code>, fx:<currency> or macro:<series>)", raw)
+	}
+	return key.String(), nil
+}
+
+// storeIngestedObservations upserts pushed observations into the table of the source's series
+// in one transaction, so a rejected value stores nothing. Values are tagged with the source
+// ingest:<name> where the table records one.
+func storeIngestedObservations(ctx context.Context, s *AppState, source database.IngestSource, observations []ingestObservation) error {
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	sourceTag := "ingest:" + source.Name
+	kind, code, _ := strings.Cut(source.Series, ":")
+	var changed []ingestObservation // Published once committed
+	for _, o := range observations {
+		value := strconv.FormatFloat(o.Value, 'f', -1, 64)
+		stored := true
+		switch kind {
+		case watchlistStock:
+			err = qtx.UpsertStockPrice(ctx, database.UpsertStockPriceParams{
+				StockCode:    code,
+				PriceDate:    o.Date,
+				ClosingPrice: fmt.Sprintf("%.4f", o.Value),
+				SourceUrl:    sql.NullString{String: sourceTag, Valid: true},
+			})
+		case watchlistFx:
+			// Pushed rates carry a single value, stored as buying, selling and middle rate
+			rate := fmt.Sprintf("%.4f", o.Value)
+			err = qtx.UpsertForeignExchange(ctx, database.UpsertForeignExchangeParams{
+				ID:           uuid.New(),
+				CurrencyCode: code,
+				BuyingRate:   rate,
+				SellingRate:  rate,
+				MiddleRate:   rate,
+				Unit:         1,
+				Source:       sourceTag,
+				CreatedAt:    time.Now(),
+				Date:         o.Date,
+				Session:      "1200",
+			})
+		case "macro":
+			// A period pushed again with the same value is not a new observation
+			var rows int64
+			rows, err = qtx.UpsertMacroObservation(ctx, database.UpsertMacroObservationParams{
+				Series: code,
+				Period: o.Date,
+				Value:  value,
+				Source: sourceTag,
+			})
+			stored = rows > 0
+		default:
+			return fmt.Errorf("source %s has unsupported series %q", source.Name, source.Series)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to store %s for %s: %w", source.Series, o.Date.Format("2006-01-02"), err)
+		}
+		if stored {
+			changed = append(changed, o)
+		}
+	}
+	if err := qtx.TouchIngestSource(ctx, source.Name); err != nil {
+		return fmt.Errorf("failed to update source %s: %w", source.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if kind == watchlistStock {
+		refreshStockRanges(ctx, s, code)
+	}
+	invalidateResponseCache(s)
+	for _, o := range changed {
+		publishObservation(s, kind, code, o.Date, o.Value, sourceTag)
+	}
+	if kind == "macro" {
+		refreshActivityIndex(ctx, s, code)
+	}
+	return nil
+}
+
+// --- Ingest Source Command Handlers ---
+
+// handlerIngestRegister registers a push ingestion source for one series (admin only). A
+// signing secret is generated unless one is given, and printed once.
+// Usage: ingest:register <name> <series> [secret]
+func handlerIngestRegister(s *AppState, cmd command) error {
+	if len(cmd.Args) < 2 || len(cmd.Args) > 3 {
+		return fmt.Errorf("usage: %s <name> <stock:CODE|fx:CUR|macro:SERIES> [secret]", cmd.Name)
+	}
+	name := strings.ToLower(cmd.Args[0])
+	if !ingestSourceNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid source name %q (lowercase letters, digits, _ and -; at most 50)", cmd.Args[0])
+	}
+	series, err := parseIngestSeries(cmd.Args[1])
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	// Stock prices reference companies, so the company must be known before prices can be pushed
+	if code, ok := strings.CutPrefix(series, watchlistStock+":"); ok {
+		if _, err := s.db.GetCompanyByStockCode(ctx, code); errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("unknown stock %s (run stock:fetch:profile %s, or company:add for other countries, first)", code, code)
+		} else if err != nil {
+			return fmt.Errorf("failed to look up stock %s: %w", code, err)
+		}
+	}
+
+	secret := ""
+	if len(cmd.Args) == 3 {
+		secret = cmd.Args[2]
+	} else if secret, err = auth.MakeSessionToken(); err != nil {
+		return err
+	}
+	source, err := s.db.CreateIngestSource(ctx, database.CreateIngestSourceParams{
+		Name:   name,
+		Series: series,
+		Secret: secret,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register source %s: %w", name, err)
+	}
+	log.Printf("Registered ingest source %s for %s.", source.Name, source.Series)
+	fmt.Printf("Ingest source %s registered for %s.\n", source.Name, source.Series)
+	if len(cmd.Args) == 2 {
+		fmt.Printf("Signing secret (store it now; it is not shown again):\n  %s\n", secret)
+	}
+	return nil
+}
+
+// handlerIngestList lists ingest sources without their secrets (admin only).
+// Usage: ingest:list
+func handlerIngestList(s *AppState, cmd command) error {
+	sources, err := s.db.ListIngestSources(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to list ingest sources: %w", err)
+	}
+	if len(sources) == 0 {
+		fmt.Println("No ingest sources.")
+		return nil
+	}
+	for _, src := range sources {
+		last := "never"
+		if src.LastIngestedAt.Valid {
+			last = src.LastIngestedAt.Time.Format(time.RFC3339)
+		}
+		state := "active"
+		if !src.Active {
+			state = "inactive"
+		}
+		fmt.Printf("%-20s %-16s %s, last push %s\n", src.Name, src.Series, state, last)
+	}
+	return nil
+}
+
+// handlerIngestRemove deletes an ingest source (admin only). Data it pushed is kept.
+// Usage: ingest:remove <name>
+func handlerIngestRemove(s *AppState, cmd command) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <name>", cmd.Name)
+	}
+	n, err := s.db.DeleteIngestSource(cmd.Context(), strings.ToLower(cmd.Args[0]))
+	if err != nil {
+		return fmt.Errorf("failed to remove ingest source %s: %w", cmd.Args[0], err)
+	}
+	if n == 0 {
+		return fmt.Errorf("ingest source %s not found", cmd.Args[0])
+	}
+	fmt.Printf("Ingest source %s removed.\n", cmd.Args[0])
+	return nil
+}
@@ -1,7 +1,9 @@
 package fxclient
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -47,17 +49,38 @@ type SingleRateApiResponse struct { // New struct for this specific endpoint
 	Meta map[string]interface{} `json:"meta"`
 }
 
+// --- Structs for FetchMonthRates (One Currency, Many Dates) ---
+type CurrencyRateMonth struct {
+	CurrencyCode string           `json:"currency_code"`
+	Unit         int              `json:"unit"`
+	Rate         []RateInfoSingle `json:"rate"` // One entry per trading day in the month
+}
+
+type MonthRateApiResponse struct {
+	Data CurrencyRateMonth      `json:"data"`
+	Meta map[string]interface{} `json:"meta"`
+}
+
+// ErrNotFound is returned when the API has no rate for the requested currency/date (e.g. weekends and holidays).
+var ErrNotFound = errors.New("no data found")
+
 // --- Client Definition (Remains the same) ---
 type Client struct {
 	BaseURL    string
 	APIKey     string
+	Session    string // BNM publication session: 0900, 1200 or 1700
 	httpClient *http.Client
 }
 
-func New(cfg config.Config, baseURL string) *Client { // No change needed
+func New(cfg config.Config, baseURL string) *Client {
+	session := cfg.FXSession
+	if session == "" {
+		session = "1200"
+	}
 	return &Client{
 		BaseURL: baseURL,
 		APIKey:  cfg.FXAPIKey,
+		Session: session,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
@@ -65,12 +88,12 @@ func New(cfg config.Config, baseURL string) *Client { // No change needed
 }
 
 // --- Updated FetchTargetCurrencyRates ---
-func (c *Client) FetchTargetCurrencyRates(targetCurrency string, targetDate string) (SingleRateApiResponse, error) { // Changed return type
+func (c *Client) FetchTargetCurrencyRates(ctx context.Context, targetCurrency string, targetDate string) (SingleRateApiResponse, error) { // Changed return type
 
 	var apiResponse SingleRateApiResponse // Use the new struct type
 
-	apiEndpoint := fmt.Sprintf("%s/%s/date/%s?session=1200&quote=rm", c.BaseURL, targetCurrency, targetDate)
-	req, err := http.NewRequest("GET", apiEndpoint, nil)
+	apiEndpoint := fmt.Sprintf("%s/%s/date/%s?session=%s&quote=rm", c.BaseURL, targetCurrency, targetDate, c.Session)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiEndpoint, nil)
 	if err != nil {
 		return apiResponse, fmt.Errorf("error creating request: %w", err)
 	}
@@ -87,7 +110,7 @@ func (c *Client) FetchTargetCurrencyRates(targetCurrency string, targetDate stri
 	// Check for 404 specifically, treat it as "no data for this date" not necessarily a fatal error
 	if resp.StatusCode == http.StatusNotFound {
 		// Return the empty struct and a specific error or nil depending on how you want to handle it upstream
-		return apiResponse, fmt.Errorf("API returned 404 Not Found for %s on %s (likely no data)", targetCurrency, targetDate)
+		return apiResponse, fmt.Errorf("API returned 404 Not Found for %s on %s: %w", targetCurrency, targetDate, ErrNotFound)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -104,13 +127,47 @@ func (c *Client) FetchTargetCurrencyRates(targetCurrency string, targetDate stri
 	return apiResponse, nil
 }
 
+// FetchMonthRates fetches every published rate for a currency in a calendar month in a single request.
+func (c *Client) FetchMonthRates(ctx context.Context, targetCurrency string, year int, month int) (MonthRateApiResponse, error) {
+
+	var apiResponse MonthRateApiResponse
+
+	apiEndpoint := fmt.Sprintf("%s/%s/year/%d/month/%d?session=%s&quote=rm", c.BaseURL, targetCurrency, year, month, c.Session)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiEndpoint, nil)
+	if err != nil {
+		return apiResponse, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.BNM.API.v1+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return apiResponse, fmt.Errorf("error making API request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return apiResponse, fmt.Errorf("API returned 404 Not Found for %s in %04d-%02d: %w", targetCurrency, year, month, ErrNotFound)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return apiResponse, fmt.Errorf("API request failed with status code: %d %s", resp.StatusCode, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
+		return apiResponse, fmt.Errorf("error decoding API response: %w", err)
+	}
+
+	return apiResponse, nil
+}
+
 // --- Updated FetchLatestRatesAll ---
-func (c *Client) FetchLatestRatesAll() (MultiRateApiResponse, error) { // Changed return type
+func (c *Client) FetchLatestRatesAll(ctx context.Context) (MultiRateApiResponse, error) { // Changed return type
 
 	var apiResponse MultiRateApiResponse // Use the struct where Data is an array
 
-	apiEndpoint := fmt.Sprintf("%s?session=1200&quote=rm", c.BaseURL)
-	req, err := http.NewRequest("GET", apiEndpoint, nil)
+	apiEndpoint := fmt.Sprintf("%s?session=%s&quote=rm", c.BaseURL, c.Session)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiEndpoint, nil)
 	if err != nil {
 		return apiResponse, fmt.Errorf("error creating request: %w", err)
 	}
@@ -0,0 +1,73 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ActivityIndex combines monthly indicators into a composite activity index.
+//
+// components maps each indicator to its monthly series (sorted oldest first), already
+// transformed to be comparable over time (e.g. year-on-year growth, or a diffusion index as
+// is). Each component is standardized to a z-score over its own history, and the index for a
+// month is the weighted average of the z-scores of the components published that month, with
+// weights renormalized over them. Months where the published components carry less than half
+// of the total weight are left out, so the index does not swing on a single early release.
+// The result reads in standard deviations: 0 is average activity, positive is above average.
+func ActivityIndex(components map[string][]Point, weights map[string]float64) []Point {
+	var totalWeight float64
+	byMonth := make(map[int][]weightedScore)
+	for name, points := range components {
+		w := weights[name]
+		if w <= 0 || len(points) < 2 {
+			continue
+		}
+		totalWeight += w
+		mean, sd := meanStdDev(points)
+		if sd == 0 {
+			continue
+		}
+		for _, p := range points {
+			key := monthKey(p.Date)
+			byMonth[key] = append(byMonth[key], weightedScore{Weight: w, Score: (p.Value - mean) / sd})
+		}
+	}
+
+	months := make([]int, 0, len(byMonth))
+	for key := range byMonth {
+		months = append(months, key)
+	}
+	sort.Ints(months)
+	var out []Point
+	for _, key := range months {
+		var weight, sum float64
+		for _, s := range byMonth[key] {
+			weight += s.Weight
+			sum += s.Weight * s.Score
+		}
+		if weight < totalWeight/2 {
+			continue
+		}
+		date := time.Date(key/12, time.Month(key%12+1), 1, 0, 0, 0, 0, time.UTC)
+		out = append(out, Point{Date: date, Value: sum / weight})
+	}
+	return out
+}
+
+type weightedScore struct {
+	Weight, Score float64
+}
+
+// meanStdDev returns the mean and sample standard deviation of the values of points.
+func meanStdDev(points []Point) (mean, sd float64) {
+	for _, p := range points {
+		mean += p.Value
+	}
+	mean /= float64(len(points))
+	var ss float64
+	for _, p := range points {
+		ss += (p.Value - mean) * (p.Value - mean)
+	}
+	return mean, math.Sqrt(ss / float64(len(points)-1))
+}
@@ -0,0 +1,101 @@
+package analytics
+
+import (
+	"math"
+	"time"
+)
+
+// Trade is a simulated switch into or out of the market at a day's close.
+type Trade struct {
+	Date  time.Time
+	Side  string // "buy" or "sell"
+	Price float64
+}
+
+// BacktestResult is the outcome of simulating a strategy over a price series.
+type BacktestResult struct {
+	Equity      []Point // Portfolio value at each close from the first simulated day
+	Trades      []Trade
+	TotalReturn float64 // Percent
+	CAGR        float64 // Compound annual growth rate, percent
+	MaxDrawdown float64 // Deepest fall of the equity curve from a high, percent (<= 0)
+	// Percent return of holding the stock over the same days, for comparison
+	BuyAndHoldReturn float64
+	Exposure         float64 // Percent of simulated days spent invested
+}
+
+// SMACrossover simulates a long-only moving average crossover strategy on closes (sorted
+// oldest first): fully invested while the fast-day simple moving average is above the
+// slow-day one, in cash otherwise. Signals are taken at a day's close and traded at that
+// close, without costs. Closes before start only warm up the averages; the simulation starts
+// with initial in cash on the first close on or after start. Non-positive closes are skipped.
+func SMACrossover(closes []Point, start time.Time, fast, slow int, initial float64) BacktestResult {
+	var prices []Point
+	for _, p := range closes {
+		if p.Value > 0 {
+			prices = append(prices, p)
+		}
+	}
+	fastMA, slowMA := smaByIndex(prices, fast), smaByIndex(prices, slow)
+
+	var result BacktestResult
+	cash, shares := initial, 0.0
+	first := -1
+	invested := 0
+	for i, p := range prices {
+		if p.Date.Before(start) {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		if shares > 0 {
+			invested++
+		}
+		long := !math.IsNaN(fastMA[i]) && !math.IsNaN(slowMA[i]) && fastMA[i] > slowMA[i]
+		switch {
+		case long && shares == 0:
+			shares, cash = cash/p.Value, 0
+			result.Trades = append(result.Trades, Trade{Date: p.Date, Side: "buy", Price: p.Value})
+		case !long && shares > 0:
+			cash, shares = shares*p.Value, 0
+			result.Trades = append(result.Trades, Trade{Date: p.Date, Side: "sell", Price: p.Value})
+		}
+		result.Equity = append(result.Equity, Point{Date: p.Date, Value: cash + shares*p.Value})
+	}
+	if first < 0 || initial <= 0 {
+		return result
+	}
+
+	last := prices[len(prices)-1]
+	final := result.Equity[len(result.Equity)-1].Value
+	result.TotalReturn = (final/initial - 1) * 100
+	result.BuyAndHoldReturn = (last.Value/prices[first].Value - 1) * 100
+	if days := last.Date.Sub(prices[first].Date).Hours() / 24; days > 0 {
+		result.CAGR = (math.Pow(final/initial, 365.25/days) - 1) * 100
+	}
+	if n := len(result.Equity) - 1; n > 0 {
+		result.Exposure = float64(invested) / float64(n) * 100
+	}
+	_, stats := Drawdowns(result.Equity)
+	result.MaxDrawdown = stats.MaxDrawdown
+	return result
+}
+
+// smaByIndex returns the trailing window-observation average at each index of points, NaN
+// until window observations are available.
+func smaByIndex(points []Point, window int) []float64 {
+	out := make([]float64, len(points))
+	sum := 0.0
+	for i, p := range points {
+		sum += p.Value
+		if i >= window {
+			sum -= points[i-window].Value
+		}
+		out[i] = math.NaN()
+		if window > 0 && i >= window-1 {
+			out[i] = sum / float64(window)
+		}
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// dailyPoints returns values as consecutive daily points from 2024-01-01.
+func dailyPoints(values ...float64) []Point {
+	points := make([]Point, len(values))
+	for i, v := range values {
+		points[i] = Point{Date: day(i), Value: v}
+	}
+	return points
+}
+
+// day returns the date i days after 2024-01-01.
+func day(i int) time.Time {
+	return time.Date(2024, 1, 1+i, 0, 0, 0, 0, time.UTC)
+}
+
+func TestSMACrossover(t *testing.T) {
+	tests := []struct {
+		name             string
+		closes           []Point
+		start            time.Time
+		fast, slow       int
+		equity           []float64
+		trades           []Trade
+		totalReturn      float64
+		buyAndHoldReturn float64
+		exposure         float64
+		maxDrawdown      float64
+	}{
+		{
+			name:   "buys on the cross up and sells on the cross down",
+			closes: dailyPoints(10, 11, 12, 11, 9),
+			start:  day(0),
+			fast:   1, slow: 2,
+			equity: []float64{100, 100, 100.0 / 11 * 12, 100, 100},
+			trades: []Trade{
+				{Date: day(1), Side: "buy", Price: 11},
+				{Date: day(3), Side: "sell", Price: 11},
+			},
+			totalReturn:      0,
+			buyAndHoldReturn: -10,
+			exposure:         50,
+			maxDrawdown:      (100/(100.0/11*12) - 1) * 100,
+		},
+		{
+			name:   "closes before start only warm up the averages",
+			closes: dailyPoints(10, 11, 12, 13),
+			start:  day(2),
+			fast:   1, slow: 2,
+			equity:           []float64{100, 100.0 / 12 * 13},
+			trades:           []Trade{{Date: day(2), Side: "buy", Price: 12}},
+			totalReturn:      (13.0/12 - 1) * 100,
+			buyAndHoldReturn: (13.0/12 - 1) * 100,
+			exposure:         100,
+		},
+		{
+			name:   "non-positive closes are skipped",
+			closes: dailyPoints(10, 0, 11, -1, 12),
+			start:  day(0),
+			fast:   1, slow: 2,
+			equity:           []float64{100, 100, 100.0 / 11 * 12},
+			trades:           []Trade{{Date: day(2), Side: "buy", Price: 11}},
+			totalReturn:      (12.0/11 - 1) * 100,
+			buyAndHoldReturn: 20,
+			exposure:         50,
+		},
+		{
+			name:   "stays in cash until the slow average is available",
+			closes: dailyPoints(10, 11, 12),
+			start:  day(0),
+			fast:   1, slow: 5,
+			equity:           []float64{100, 100, 100},
+			buyAndHoldReturn: 20,
+		},
+		{
+			name:   "no closes on or after start",
+			closes: dailyPoints(10, 11, 12),
+			start:  day(5),
+			fast:   1, slow: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SMACrossover(tt.closes, tt.start, tt.fast, tt.slow, 100)
+			if len(got.Equity) != len(tt.equity) {
+				t.Fatalf("got %d equity points, want %d", len(got.Equity), len(tt.equity))
+			}
+			for i, want := range tt.equity {
+				if !approxEqual(got.Equity[i].Value, want) {
+					t.Errorf("equity[%d] = %v, want %v", i, got.Equity[i].Value, want)
+				}
+			}
+			if len(got.Trades) != len(tt.trades) {
+				t.Fatalf("got trades %+v, want %+v", got.Trades, tt.trades)
+			}
+			for i, want := range tt.trades {
+				if got := got.Trades[i]; !got.Date.Equal(want.Date) || got.Side != want.Side || got.Price != want.Price {
+					t.Errorf("trade[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+			if !approxEqual(got.TotalReturn, tt.totalReturn) {
+				t.Errorf("TotalReturn = %v, want %v", got.TotalReturn, tt.totalReturn)
+			}
+			if !approxEqual(got.BuyAndHoldReturn, tt.buyAndHoldReturn) {
+				t.Errorf("BuyAndHoldReturn = %v, want %v", got.BuyAndHoldReturn, tt.buyAndHoldReturn)
+			}
+			if !approxEqual(got.Exposure, tt.exposure) {
+				t.Errorf("Exposure = %v, want %v", got.Exposure, tt.exposure)
+			}
+			if !approxEqual(got.MaxDrawdown, tt.maxDrawdown) {
+				t.Errorf("MaxDrawdown = %v, want %v", got.MaxDrawdown, tt.maxDrawdown)
+			}
+		})
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,56 @@
+package analytics
+
+import "math"
+
+// LTTB selects threshold points of points (sorted oldest first) with the
+// Largest-Triangle-Three-Buckets algorithm, which keeps the peaks and troughs that make a
+// chart look like the full series. It returns the indices of the kept points in order; the
+// first and last points are always kept. All indices are returned when there are no more than
+// threshold points or threshold is below 3.
+func LTTB(points []Point, threshold int) []int {
+	n := len(points)
+	if threshold >= n || threshold < 3 {
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+
+	x := func(i int) float64 { return float64(points[i].Date.Unix()) }
+	kept := make([]int, 0, threshold)
+	kept = append(kept, 0)
+
+	// The points between the first and last are split into threshold-2 buckets; from each
+	// the point forming the largest triangle with the previously kept point and the average
+	// of the next bucket is kept.
+	bucketSize := float64(n-2) / float64(threshold-2)
+	a := 0
+	for b := 0; b < threshold-2; b++ {
+		start := int(float64(b)*bucketSize) + 1
+		end := int(float64(b+1)*bucketSize) + 1
+
+		nextStart, nextEnd := end, int(float64(b+2)*bucketSize)+1
+		if nextEnd > n {
+			nextEnd = n
+		}
+		var avgX, avgY float64
+		for i := nextStart; i < nextEnd; i++ {
+			avgX += x(i)
+			avgY += points[i].Value
+		}
+		count := float64(nextEnd - nextStart)
+		avgX, avgY = avgX/count, avgY/count
+
+		best, bestArea := start, -1.0
+		for i := start; i < end; i++ {
+			area := math.Abs((x(a)-avgX)*(points[i].Value-points[a].Value) - (x(a)-x(i))*(avgY-points[a].Value))
+			if area > bestArea {
+				best, bestArea = i, area
+			}
+		}
+		kept = append(kept, best)
+		a = best
+	}
+	return append(kept, n-1)
+}
@@ -0,0 +1,76 @@
+package analytics
+
+import (
+	"math"
+	"time"
+)
+
+// EffectiveExchangeRate computes a trade-weighted effective exchange rate index for MYR.
+//
+// rates maps each partner currency to its MYR-per-unit series; weights maps the same
+// currencies to their trade weights (normalized here to sum to 1). The index is the
+// weighted geometric mean of each bilateral rate relative to the base date, scaled to 100,
+// so a rising index means the ringgit has strengthened against the basket.
+//
+// Observations are aligned on the union of dates, carrying each currency's last rate
+// forward; the base date is the first date on which every weighted currency has a rate.
+func EffectiveExchangeRate(rates map[string][]Point, weights map[string]float64) []Point {
+	var totalWeight float64
+	for code, w := range weights {
+		if w > 0 && len(rates[code]) > 0 {
+			totalWeight += w
+		}
+	}
+	if totalWeight == 0 {
+		return nil
+	}
+
+	// Collect the union of observation dates
+	byCurrency := make(map[string]map[time.Time]float64, len(weights))
+	dateSet := make(map[time.Time]bool)
+	for code, w := range weights {
+		if w <= 0 || len(rates[code]) == 0 {
+			continue // Currencies without data are dropped and the remaining weights renormalized
+		}
+		byCurrency[code] = make(map[time.Time]float64, len(rates[code]))
+		for _, p := range rates[code] {
+			if p.Value <= 0 {
+				continue // A zero or negative rate would poison the geometric mean
+			}
+			byCurrency[code][p.Date] = p.Value
+			dateSet[p.Date] = true
+		}
+	}
+	dates := make([]Point, 0, len(dateSet))
+	for d := range dateSet {
+		dates = append(dates, Point{Date: d})
+	}
+	SortPoints(dates)
+
+	last := make(map[string]float64, len(byCurrency))
+	base := make(map[string]float64, len(byCurrency))
+	var index []Point
+	for _, d := range dates {
+		for code, series := range byCurrency {
+			if v, ok := series[d.Date]; ok {
+				last[code] = v
+			}
+		}
+		if len(last) < len(byCurrency) {
+			continue // Not every currency has a rate yet
+		}
+		if len(base) == 0 {
+			for code, v := range last {
+				base[code] = v
+			}
+		}
+
+		logSum := 0.0
+		for code, v := range last {
+			// MYR per foreign unit falling means MYR strengthened, so invert the ratio
+			logSum += (weights[code] / totalWeight) * math.Log(base[code]/v)
+		}
+		index = append(index, Point{Date: d.Date, Value: 100 * math.Exp(logSum)})
+	}
+	return index
+}
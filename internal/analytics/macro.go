@@ -0,0 +1,165 @@
+package analytics
+
+import "time"
+
+// monthKey identifies the calendar month of a date.
+func monthKey(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
+
+// changeOverMonths returns the percentage change of each observation of a monthly series
+// (sorted oldest first) against the observation the given number of months earlier. Months
+// without that earlier observation, or where it is not positive, are skipped.
+func changeOverMonths(points []Point, months int) []Point {
+	byMonth := make(map[int]float64, len(points))
+	for _, p := range points {
+		byMonth[monthKey(p.Date)] = p.Value
+	}
+	var out []Point
+	for _, p := range points {
+		prev, ok := byMonth[monthKey(p.Date)-months]
+		if !ok || prev <= 0 {
+			continue
+		}
+		out = append(out, Point{Date: p.Date, Value: (p.Value/prev - 1) * 100})
+	}
+	return out
+}
+
+// YearOnYear returns the percentage change of a monthly series against the same month a year earlier.
+func YearOnYear(points []Point) []Point {
+	return changeOverMonths(points, 12)
+}
+
+// MonthOnMonth returns the percentage change of a monthly series against the previous month.
+func MonthOnMonth(points []Point) []Point {
+	return changeOverMonths(points, 1)
+}
+
+// MovingAverage returns the trailing average of window consecutive observations (sorted oldest
+// first), dated on the last one; e.g. window 3 gives the 3-month moving average of a monthly series.
+func MovingAverage(points []Point, window int) []Point {
+	if window < 1 || len(points) < window {
+		return nil
+	}
+	out := make([]Point, 0, len(points)-window+1)
+	sum := 0.0
+	for i, p := range points {
+		sum += p.Value
+		if i >= window {
+			sum -= points[i-window].Value
+		}
+		if i >= window-1 {
+			out = append(out, Point{Date: p.Date, Value: sum / float64(window)})
+		}
+	}
+	return out
+}
+
+// Decomposition splits a monthly series into trend, seasonal and irregular components.
+// With the additive model Value = Trend + Seasonal + Irregular; with the multiplicative model
+// Value = Trend * Seasonal * Irregular and Seasonal averages 1. Trend and Irregular are
+// unavailable for the first and last six months, where the centred average cannot be formed.
+type Decomposition struct {
+	Dates              []time.Time
+	Values             []float64
+	Trend              []*float64
+	Seasonal           []float64
+	Irregular          []*float64
+	SeasonallyAdjusted []float64 // Value with the seasonal component removed
+}
+
+// SeasonalDecompose performs a classical decomposition of a monthly series (sorted oldest
+// first, one observation per month without gaps): the trend is a centred 2x12 moving average
+// and the seasonal factor for each calendar month is the average detrended value of that
+// month, normalized to sum to zero (additive) or average one (multiplicative). At least two
+// full years are needed; ok is false otherwise.
+func SeasonalDecompose(points []Point, multiplicative bool) (Decomposition, bool) {
+	n := len(points)
+	if n < 24 {
+		return Decomposition{}, false
+	}
+	for i := 1; i < n; i++ {
+		if monthKey(points[i].Date) != monthKey(points[i-1].Date)+1 {
+			return Decomposition{}, false // Gaps or duplicates would misalign the seasons
+		}
+	}
+
+	d := Decomposition{
+		Dates:              make([]time.Time, n),
+		Values:             make([]float64, n),
+		Trend:              make([]*float64, n),
+		Seasonal:           make([]float64, n),
+		Irregular:          make([]*float64, n),
+		SeasonallyAdjusted: make([]float64, n),
+	}
+	for i, p := range points {
+		d.Dates[i], d.Values[i] = p.Date, p.Value
+		if multiplicative && p.Value <= 0 {
+			return Decomposition{}, false
+		}
+	}
+
+	// Centred 2x12 moving average: half weight on the two ends of a 13-month span
+	for i := 6; i < n-6; i++ {
+		sum := (d.Values[i-6] + d.Values[i+6]) / 2
+		for j := i - 5; j <= i+5; j++ {
+			sum += d.Values[j]
+		}
+		trend := sum / 12
+		d.Trend[i] = &trend
+	}
+
+	// Average detrended value per calendar month
+	var totals [12]float64
+	var counts [12]int
+	for i := range d.Values {
+		if d.Trend[i] == nil {
+			continue
+		}
+		m := int(d.Dates[i].Month()) - 1
+		if multiplicative {
+			totals[m] += d.Values[i] / *d.Trend[i]
+		} else {
+			totals[m] += d.Values[i] - *d.Trend[i]
+		}
+		counts[m]++
+	}
+	var factors [12]float64
+	mean := 0.0
+	for m := range factors {
+		if counts[m] > 0 {
+			factors[m] = totals[m] / float64(counts[m])
+		} else if multiplicative {
+			factors[m] = 1
+		}
+		mean += factors[m] / 12
+	}
+	for m := range factors {
+		if multiplicative {
+			factors[m] /= mean
+		} else {
+			factors[m] -= mean
+		}
+	}
+
+	for i := range d.Values {
+		seasonal := factors[int(d.Dates[i].Month())-1]
+		d.Seasonal[i] = seasonal
+		if multiplicative {
+			d.SeasonallyAdjusted[i] = d.Values[i] / seasonal
+		} else {
+			d.SeasonallyAdjusted[i] = d.Values[i] - seasonal
+		}
+		if d.Trend[i] != nil {
+			var irregular float64
+			if multiplicative {
+				irregular = d.SeasonallyAdjusted[i] / *d.Trend[i]
+			} else {
+				irregular = d.SeasonallyAdjusted[i] - *d.Trend[i]
+			}
+			d.Irregular[i] = &irregular
+		}
+	}
+	return d, true
+}
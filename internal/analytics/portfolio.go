@@ -0,0 +1,67 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+)
+
+// Lot is a single purchase of a stock.
+type Lot struct {
+	Code      string
+	Quantity  float64
+	CostBasis float64 // Price paid per share
+	TradeDate time.Time
+}
+
+// PortfolioValue is the valuation of a set of lots on one date.
+type PortfolioValue struct {
+	Date          time.Time
+	MarketValue   float64
+	CostBasis     float64
+	UnrealizedPnL float64
+}
+
+// ValuePortfolio values lots on every date in [start, end] on which any held stock has a
+// closing price. prices maps stock codes to closing prices sorted oldest first; it should
+// include the last price before start so the first dates can be valued. A lot is included
+// from its trade date, priced at its stock's most recent close, or at its cost basis until
+// the first close is available.
+func ValuePortfolio(lots []Lot, prices map[string][]Point, start, end time.Time) []PortfolioValue {
+	dateSet := make(map[time.Time]bool)
+	for _, lot := range lots {
+		for _, p := range prices[lot.Code] {
+			if !p.Date.Before(start) && !p.Date.After(end) {
+				dateSet[p.Date] = true
+			}
+		}
+	}
+	dates := make([]time.Time, 0, len(dateSet))
+	for d := range dateSet {
+		dates = append(dates, d)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	var values []PortfolioValue
+	for _, date := range dates {
+		v := PortfolioValue{Date: date}
+		held := false
+		for _, lot := range lots {
+			if lot.TradeDate.After(date) {
+				continue
+			}
+			held = true
+			price, ok := ValueAt(prices[lot.Code], date)
+			if !ok {
+				price = lot.CostBasis
+			}
+			v.MarketValue += lot.Quantity * price
+			v.CostBasis += lot.Quantity * lot.CostBasis
+		}
+		if !held {
+			continue // Nothing bought yet
+		}
+		v.UnrealizedPnL = v.MarketValue - v.CostBasis
+		values = append(values, v)
+	}
+	return values
+}
@@ -0,0 +1,172 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Quality checks run by QualityChecks.
+const (
+	CheckGap     = "gap"
+	CheckOutlier = "outlier"
+	CheckStuck   = "stuck"
+	CheckZero    = "zero"
+)
+
+// zeroScore ranks a zero or negative value, which is almost always a failed scrape, above all
+// but the most extreme of the other findings.
+const zeroScore = 10
+
+// Anomaly is one suspicious stretch of a series found by a quality check.
+type Anomaly struct {
+	Check string    // CheckGap, CheckOutlier, CheckStuck or CheckZero
+	Start time.Time // First date concerned; for a gap, the last observation before it
+	End   time.Time // Last date concerned; for a gap, the first observation after it
+	Value float64   // The suspicious value; for a gap, the number of missing observations
+	Score float64   // Severity relative to the check's threshold (1 = just past it); ranks findings
+}
+
+// QualityThresholds configures QualityChecks.
+type QualityThresholds struct {
+	MinGap      int     // Missing expected observations in a row that make a gap
+	OutlierZ    float64 // Robust z-score of a period change that makes an outlier
+	MinStuckRun int     // Identical observations in a row that make a stuck value
+}
+
+// QualityChecks runs every quality check over points (sorted oldest first) and returns the
+// anomalies found, most severe first. next returns the date the observation after d is
+// expected on (the next trading day, or month). positive flags zero and negative values, as
+// for prices and exchange rates; otherwise only exact zeros are flagged.
+func QualityChecks(points []Point, next func(d time.Time) time.Time, positive bool, t QualityThresholds) []Anomaly {
+	var found []Anomaly
+	found = append(found, Gaps(points, next, t.MinGap)...)
+	found = append(found, Outliers(points, t.OutlierZ)...)
+	found = append(found, StuckValues(points, t.MinStuckRun)...)
+	found = append(found, Zeros(points, positive)...)
+	RankAnomalies(found)
+	return found
+}
+
+// RankAnomalies sorts anomalies by score, most severe first.
+func RankAnomalies(anomalies []Anomaly) {
+	sort.SliceStable(anomalies, func(i, j int) bool { return anomalies[i].Score > anomalies[j].Score })
+}
+
+// Gaps finds runs of at least minMissing expected observations missing between consecutive
+// points. Nothing is reported before the first or after the last point.
+func Gaps(points []Point, next func(d time.Time) time.Time, minMissing int) []Anomaly {
+	if minMissing < 1 {
+		return nil
+	}
+	var gaps []Anomaly
+	for i := 1; i < len(points); i++ {
+		missing := 0
+		for d := next(points[i-1].Date); d.Before(points[i].Date); d = next(d) {
+			missing++
+		}
+		if missing >= minMissing {
+			gaps = append(gaps, Anomaly{
+				Check: CheckGap,
+				Start: points[i-1].Date,
+				End:   points[i].Date,
+				Value: float64(missing),
+				Score: float64(missing) / float64(minMissing),
+			})
+		}
+	}
+	return gaps
+}
+
+// Outliers finds observations whose percentage change from the previous one lies more than z
+// robust standard deviations (1.4826 times the median absolute deviation) from the median
+// change, so a single bad value does not hide itself by inflating the spread. Changes from a
+// non-positive value are skipped, as in DailyReturns.
+func Outliers(points []Point, z float64) []Anomaly {
+	if z <= 0 {
+		return nil
+	}
+	changes := DailyReturns(points)
+	if len(changes) < 10 {
+		return nil // Too few changes for a meaningful spread
+	}
+	values := make([]float64, len(changes))
+	for i, c := range changes {
+		values[i] = c.Value
+	}
+	center := median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - center)
+	}
+	spread := 1.4826 * median(deviations)
+	if spread == 0 {
+		return nil // Mostly unchanged values; StuckValues reports those
+	}
+
+	byDate := make(map[time.Time]float64, len(points))
+	for _, p := range points {
+		byDate[p.Date] = p.Value
+	}
+	var outliers []Anomaly
+	for _, c := range changes {
+		score := math.Abs(c.Value-center) / spread
+		if score > z {
+			outliers = append(outliers, Anomaly{
+				Check: CheckOutlier,
+				Start: c.Date,
+				End:   c.Date,
+				Value: byDate[c.Date],
+				Score: score / z,
+			})
+		}
+	}
+	return outliers
+}
+
+// StuckValues finds runs of at least minRun consecutive identical observations, the mark of
+// a scraper returning a cached or default page.
+func StuckValues(points []Point, minRun int) []Anomaly {
+	if minRun < 2 {
+		return nil
+	}
+	var stuck []Anomaly
+	for start := 0; start < len(points); {
+		end := start
+		for end+1 < len(points) && points[end+1].Value == points[start].Value {
+			end++
+		}
+		if run := end - start + 1; run >= minRun {
+			stuck = append(stuck, Anomaly{
+				Check: CheckStuck,
+				Start: points[start].Date,
+				End:   points[end].Date,
+				Value: points[start].Value,
+				Score: float64(run) / float64(minRun),
+			})
+		}
+		start = end + 1
+	}
+	return stuck
+}
+
+// Zeros finds zero values, and negative ones too when positive is set.
+func Zeros(points []Point, positive bool) []Anomaly {
+	var zeros []Anomaly
+	for _, p := range points {
+		if p.Value == 0 || (positive && p.Value < 0) {
+			zeros = append(zeros, Anomaly{Check: CheckZero, Start: p.Date, End: p.Date, Value: p.Value, Score: zeroScore})
+		}
+	}
+	return zeros
+}
+
+// median returns the median of values, reordering them.
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
@@ -0,0 +1,207 @@
+package analytics
+
+import (
+	"math"
+	"time"
+)
+
+// TradingDaysPerYear annualizes daily statistics.
+const TradingDaysPerYear = 252
+
+// RollingVolatility returns the annualized volatility of daily percentage returns (sorted
+// oldest first) over a trailing window of the given number of observations: the sample
+// standard deviation of the window scaled by sqrt(TradingDaysPerYear), in percent. The first
+// point is dated on the window's last return, so there are len(returns)-window+1 points.
+func RollingVolatility(returns []Point, window int) []Point {
+	if window < 2 || len(returns) < window {
+		return nil
+	}
+	scale := math.Sqrt(TradingDaysPerYear)
+	out := make([]Point, 0, len(returns)-window+1)
+	for end := window; end <= len(returns); end++ {
+		slice := returns[end-window : end]
+		mean := 0.0
+		for _, r := range slice {
+			mean += r.Value
+		}
+		mean /= float64(window)
+		variance := 0.0
+		for _, r := range slice {
+			variance += (r.Value - mean) * (r.Value - mean)
+		}
+		variance /= float64(window - 1)
+		out = append(out, Point{Date: slice[window-1].Date, Value: math.Sqrt(variance) * scale})
+	}
+	return out
+}
+
+// RollingBeta returns the beta of asset against benchmark, both daily percentage returns
+// sorted oldest first, over a trailing window of dates on which both have a return: the
+// covariance of the two divided by the variance of the benchmark. Windows where the
+// benchmark did not move are skipped.
+func RollingBeta(asset, benchmark []Point, window int) []Point {
+	if window < 2 {
+		return nil
+	}
+	// Pair the returns by date; days missing from either series are left out
+	benchByDate := make(map[string]float64, len(benchmark))
+	for _, p := range benchmark {
+		benchByDate[p.Date.Format("2006-01-02")] = p.Value
+	}
+	var dates []time.Time
+	var xs, ys []float64
+	for _, p := range asset {
+		if b, ok := benchByDate[p.Date.Format("2006-01-02")]; ok {
+			dates = append(dates, p.Date)
+			xs = append(xs, b)
+			ys = append(ys, p.Value)
+		}
+	}
+	if len(dates) < window {
+		return nil
+	}
+
+	out := make([]Point, 0, len(dates)-window+1)
+	for end := window; end <= len(dates); end++ {
+		x, y := xs[end-window:end], ys[end-window:end]
+		meanX, meanY := 0.0, 0.0
+		for i := range x {
+			meanX += x[i]
+			meanY += y[i]
+		}
+		meanX /= float64(window)
+		meanY /= float64(window)
+		cov, variance := 0.0, 0.0
+		for i := range x {
+			cov += (x[i] - meanX) * (y[i] - meanY)
+			variance += (x[i] - meanX) * (x[i] - meanX)
+		}
+		if variance == 0 {
+			continue
+		}
+		out = append(out, Point{Date: dates[end-1], Value: cov / variance})
+	}
+	return out
+}
+
+// DrawdownPoint is one observation with its running maximum and its drawdown from it.
+type DrawdownPoint struct {
+	Date       time.Time
+	Value      float64
+	RunningMax float64
+	Drawdown   float64 // Percentage below RunningMax (0 at a new high, -25 = 25% below)
+}
+
+// DrawdownStats summarizes the drawdowns of a series.
+type DrawdownStats struct {
+	MaxDrawdown  float64    // Deepest drawdown, in percent (<= 0)
+	PeakDate     time.Time  // High the deepest drawdown fell from
+	TroughDate   time.Time  // Low of the deepest drawdown
+	RecoveryDate *time.Time // First date back at the peak value, nil if not yet recovered
+	// Length, in calendar days, of the deepest drawdown from its peak to its recovery (or to
+	// the last observation while not recovered)
+	MaxDrawdownDays int
+	// Longest stretch, in calendar days, from a high to its recovery (or to the last
+	// observation for a drawdown still in progress)
+	LongestDurationDays int
+}
+
+// Drawdowns computes the running maximum and drawdown of each point (sorted oldest first)
+// along with the depth, timing and length of the deepest drawdown and the longest time under
+// water, which may belong to a shallower drawdown.
+// Non-positive values are skipped.
+func Drawdowns(points []Point) ([]DrawdownPoint, DrawdownStats) {
+	var series []DrawdownPoint
+	var stats DrawdownStats
+	var peak float64
+	var peakDate time.Time
+	underWater := false
+	recovered := true // Whether the deepest drawdown so far has been recovered
+	for _, p := range points {
+		if p.Value <= 0 {
+			continue
+		}
+		if p.Value >= peak {
+			if underWater {
+				if days := int(p.Date.Sub(peakDate).Hours() / 24); days > stats.LongestDurationDays {
+					stats.LongestDurationDays = days
+				}
+			}
+			if !recovered {
+				date := p.Date
+				stats.RecoveryDate = &date
+				recovered = true
+			}
+			peak, peakDate = p.Value, p.Date
+			underWater = false
+		}
+		dd := (p.Value/peak - 1) * 100
+		if dd < 0 {
+			underWater = true
+		}
+		if dd < stats.MaxDrawdown {
+			stats.MaxDrawdown = dd
+			stats.PeakDate, stats.TroughDate = peakDate, p.Date
+			stats.RecoveryDate = nil
+			recovered = false
+		}
+		series = append(series, DrawdownPoint{Date: p.Date, Value: p.Value, RunningMax: peak, Drawdown: dd})
+	}
+	// A drawdown still in progress counts up to the last observation
+	if n := len(series); n > 0 && underWater {
+		if days := int(series[n-1].Date.Sub(peakDate).Hours() / 24); days > stats.LongestDurationDays {
+			stats.LongestDurationDays = days
+		}
+	}
+	if n := len(series); n > 0 && stats.MaxDrawdown < 0 {
+		end := series[n-1].Date
+		if stats.RecoveryDate != nil {
+			end = *stats.RecoveryDate
+		}
+		stats.MaxDrawdownDays = int(end.Sub(stats.PeakDate).Hours() / 24)
+	}
+	return series, stats
+}
+
+// Correlation returns the Pearson correlation of two daily return series (sorted oldest
+// first) over their last window dates in common, and the number of dates used. ok is false
+// when fewer than half the window (or three dates) overlap or either series did not move.
+func Correlation(a, b []Point, window int) (corr float64, observations int, ok bool) {
+	byDate := make(map[string]float64, len(b))
+	for _, p := range b {
+		byDate[p.Date.Format("2006-01-02")] = p.Value
+	}
+	var xs, ys []float64
+	for _, p := range a {
+		if v, found := byDate[p.Date.Format("2006-01-02")]; found {
+			xs = append(xs, p.Value)
+			ys = append(ys, v)
+		}
+	}
+	if len(xs) > window {
+		xs, ys = xs[len(xs)-window:], ys[len(ys)-window:]
+	}
+	n := len(xs)
+	if n < 3 || n < window/2 {
+		return 0, n, false
+	}
+
+	meanX, meanY := 0.0, 0.0
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+	cov, varX, varY := 0.0, 0.0, 0.0
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, n, false
+	}
+	return cov / math.Sqrt(varX*varY), n, true
+}
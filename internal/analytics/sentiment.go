@@ -0,0 +1,74 @@
+package analytics
+
+import (
+	"strings"
+	"unicode"
+)
+
+// positiveWords and negativeWords are a small financial-news lexicon, in the spirit of the
+// Loughran-McDonald word lists, restricted to words common in Malaysian business headlines.
+var positiveWords = wordSet(
+	"gain", "gains", "rise", "rises", "rising", "rose", "jump", "jumps", "surge", "surges",
+	"soar", "soars", "rally", "rallies", "climb", "climbs", "higher", "record", "strong",
+	"stronger", "growth", "grow", "grows", "profit", "profits", "beat", "beats", "upgrade",
+	"upgraded", "outperform", "buy", "bullish", "boost", "boosts", "expand", "expands",
+	"expansion", "win", "wins", "secures", "award", "awarded", "contract", "dividend",
+	"improve", "improves", "improved", "recovery", "rebound", "rebounds", "positive", "upbeat",
+	"robust", "optimistic", "approval", "approved",
+)
+
+var negativeWords = wordSet(
+	"fall", "falls", "fell", "drop", "drops", "dropped", "decline", "declines", "slump",
+	"slumps", "plunge", "plunges", "tumble", "tumbles", "slide", "slides", "lower", "weak",
+	"weaker", "loss", "losses", "miss", "misses", "downgrade", "downgraded", "underperform",
+	"sell", "bearish", "cut", "cuts", "probe", "investigation", "lawsuit", "sued", "fraud",
+	"default", "debt", "warning", "warns", "concern", "concerns", "risk", "risks", "delay",
+	"delayed", "suspend", "suspended", "halt", "halted", "resign", "resigns", "negative",
+	"pressure", "slowdown", "layoffs", "penalty", "fine", "fined",
+)
+
+// negators flip the polarity of the next sentiment word ("no growth", "not profitable").
+var negators = wordSet("no", "not", "never", "without", "fails", "failed")
+
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// HeadlineSentiment scores a headline from -1 (all sentiment words negative) to +1 (all
+// positive): (positive - negative) / (positive + negative) over the lexicon words it
+// contains, or 0 when it contains none. A negator flips the next sentiment word within
+// three words.
+func HeadlineSentiment(headline string) float64 {
+	words := strings.FieldsFunc(strings.ToLower(headline), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	positive, negative := 0, 0
+	negateFor := 0 // Words left in which a negator applies
+	for _, w := range words {
+		w = strings.Trim(w, "'")
+		switch {
+		case negators[w]:
+			negateFor = 3
+			continue
+		case positiveWords[w], negativeWords[w]:
+			if positiveWords[w] != (negateFor > 0) {
+				positive++
+			} else {
+				negative++
+			}
+			negateFor = 0
+			continue
+		}
+		if negateFor > 0 {
+			negateFor--
+		}
+	}
+	if positive+negative == 0 {
+		return 0
+	}
+	return float64(positive-negative) / float64(positive+negative)
+}
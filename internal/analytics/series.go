@@ -0,0 +1,114 @@
+// Package analytics holds pure computations over stored time series. It has no database
+// access; callers load the series and persist the results.
+package analytics
+
+import (
+	"sort"
+	"time"
+)
+
+// Point is a single observation in a time series.
+type Point struct {
+	Date  time.Time
+	Value float64
+}
+
+// SortPoints orders points by date, oldest first.
+func SortPoints(points []Point) {
+	sort.Slice(points, func(i, j int) bool { return points[i].Date.Before(points[j].Date) })
+}
+
+// ValueAt returns the last value on or before date in points (sorted oldest first),
+// and false if the series has no observation that early.
+func ValueAt(points []Point, date time.Time) (float64, bool) {
+	i := sort.Search(len(points), func(i int) bool { return points[i].Date.After(date) })
+	if i == 0 {
+		return 0, false
+	}
+	return points[i-1].Value, true
+}
+
+// DailyReturns returns the percentage change between consecutive observations of points
+// (sorted oldest first), dated on the later observation. Changes from a non-positive value
+// are skipped.
+func DailyReturns(points []Point) []Point {
+	if len(points) < 2 {
+		return nil
+	}
+	returns := make([]Point, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		prev := points[i-1].Value
+		if prev <= 0 {
+			continue
+		}
+		returns = append(returns, Point{Date: points[i].Date, Value: (points[i].Value/prev - 1) * 100})
+	}
+	return returns
+}
+
+// CompositeIndex computes a fixed-weight index over a basket of price series.
+//
+// prices maps each component to its price series; weights maps the same components to their
+// weights (normalized here to sum to 1). The index is the weighted average of each price
+// relative to the base date, scaled to baseValue.
+//
+// Observations are aligned on the union of dates, carrying each component's last price
+// forward; the base date is the first date on which every weighted component has a price.
+func CompositeIndex(prices map[string][]Point, weights map[string]float64, baseValue float64) []Point {
+	var totalWeight float64
+	for code, w := range weights {
+		if w > 0 && len(prices[code]) > 0 {
+			totalWeight += w
+		}
+	}
+	if totalWeight == 0 {
+		return nil
+	}
+
+	byCode := make(map[string]map[time.Time]float64, len(weights))
+	dateSet := make(map[time.Time]bool)
+	for code, w := range weights {
+		if w <= 0 || len(prices[code]) == 0 {
+			continue // Components without data are dropped and the remaining weights renormalized
+		}
+		byCode[code] = make(map[time.Time]float64, len(prices[code]))
+		for _, p := range prices[code] {
+			if p.Value <= 0 {
+				continue
+			}
+			byCode[code][p.Date] = p.Value
+			dateSet[p.Date] = true
+		}
+	}
+	dates := make([]Point, 0, len(dateSet))
+	for d := range dateSet {
+		dates = append(dates, Point{Date: d})
+	}
+	SortPoints(dates)
+
+	last := make(map[string]float64, len(byCode))
+	base := make(map[string]float64, len(byCode))
+	var index []Point
+	for _, d := range dates {
+		for code, series := range byCode {
+			if v, ok := series[d.Date]; ok {
+				last[code] = v
+			}
+		}
+		if len(last) < len(byCode) {
+			continue // Not every component has a price yet
+		}
+		if len(base) == 0 {
+			for code, v := range last {
+				base[code] = v
+			}
+		}
+
+		sum := 0.0
+		for code, v := range last {
+			sum += (weights[code] / totalWeight) * v / base[code]
+		}
+		index = append(index, Point{Date: d.Date, Value: baseValue * sum})
+	}
+	return index
+}
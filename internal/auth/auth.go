@@ -0,0 +1,51 @@
+// Package auth provides password hashing and token helpers shared by the CLI and HTTP API.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength is the shortest password accepted at registration.
+const MinPasswordLength = 8
+
+// HashPassword returns the bcrypt hash of password.
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPasswordHash returns nil if password matches the bcrypt hash.
+func CheckPasswordHash(password, hash string) error {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+}
+
+// DummyPasswordHash is a bcrypt hash, at the cost HashPassword uses, of a random password
+// nobody knows. Logins for unknown users check against it so they take as long as a wrong
+// password and do not reveal which usernames exist.
+const DummyPasswordHash = "$2a$10$H4i2Xf0Sud9iKwq5UBq2K.HYIiST8QSe0b/cUT4kQ6Z80bHVHgT5u"
+
+// MakeSessionToken returns a random 256-bit hex-encoded token.
+func MakeSessionToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken returns the hex-encoded SHA-256 of a token, which is what gets stored in the database.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// TokenIssuer is the "iss" claim on every JWT we issue.
+const TokenIssuer = "malaysia-econ-db"
+
+// ErrNoBearerToken is returned by GetBearerToken when the Authorization header is missing or malformed.
+var ErrNoBearerToken = errors.New("no bearer token in Authorization header")
+
+// MakeJWT signs an HS256 token for userID that expires after ttl.
+func MakeJWT(userID uuid.UUID, secret string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now().UTC()
+	expiresAt := now.Add(ttl)
+	claims := jwt.RegisteredClaims{
+		Issuer:    TokenIssuer,
+		Subject:   userID.String(),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// ValidateJWT checks the signature, issuer and expiry of tokenString and returns the user ID it was issued for.
+func ValidateJWT(tokenString, secret string) (uuid.UUID, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(TokenIssuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid token: %w", err)
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid token subject: %w", err)
+	}
+	return userID, nil
+}
+
+// GetBearerToken extracts the token from an "Authorization: Bearer <token>" header.
+func GetBearerToken(headers http.Header) (string, error) {
+	value := headers.Get("Authorization")
+	scheme, token, ok := strings.Cut(value, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", ErrNoBearerToken
+	}
+	return strings.TrimSpace(token), nil
+}
@@ -0,0 +1,37 @@
+package auth
+
+// Roles, from least to most privileged. Each role includes the permissions of the ones below it:
+// viewers may only read, editors may also change their own data, and admins may trigger
+// fetches, delete data and manage users and API keys.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// Roles lists every valid role, least privileged first.
+var Roles = []string{RoleViewer, RoleEditor, RoleAdmin}
+
+var roleRank = map[string]int{RoleViewer: 1, RoleEditor: 2, RoleAdmin: 3}
+
+// ValidRole reports whether role is one of Roles.
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// RoleAllows reports whether a holder of role has at least the privileges of required.
+// Unknown roles are never allowed.
+func RoleAllows(role, required string) bool {
+	have, ok := roleRank[role]
+	return ok && have >= roleRank[required]
+}
+
+// LesserRole returns whichever of a and b is less privileged. An unknown role is returned
+// over a known one, so the result is never allowed more than either input.
+func LesserRole(a, b string) string {
+	if roleRank[a] <= roleRank[b] {
+		return a
+	}
+	return b
+}
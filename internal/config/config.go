@@ -1,29 +1,172 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Profiles are the named environments a configuration can be loaded for.
+var Profiles = []string{"dev", "staging", "prod"}
+
 // Config holds application configuration values.
 type Config struct {
-	DBURL                     string
-	FXAPIKey                  string
-	ServerAddr                string
-	CertFile                  string
-	KeyFile                   string
-	FXAPIBaseURL              string // Added field for API base URL
-	I3InvestorBaseURL         string
-	I3InvestorStockProfileURL string
-	StockList                 []string
+	Profile                  string // Named environment (dev, staging, prod); empty when none was selected
+	DBURL                    string
+	FXAPIKey                 string
+	ServerAddr               string
+	ServerDisabled           bool     // Run without the HTTPS server (CLI, scheduler and bot only)
+	FrontendDir              string   // Serve the frontend from this directory instead of the copy embedded in the binary
+	BasePath                 string   // Path prefix the API and frontend are served under behind a reverse proxy, e.g. /econ (empty for the root)
+	TrustedProxies           []string // IPs or CIDRs of reverse proxies whose X-Forwarded-For header gives the client address
+	CertFile                 string
+	KeyFile                  string
+	PublicReadOnly           bool          // Serve only the public read endpoints, to anyone, with no login, admin or write paths
+	PublicEndpoints          []string      // Route patterns served in public read-only mode (the public data endpoints when empty)
+	PublicCacheMaxAge        time.Duration // Cache-Control max-age sent with public read-only responses
+	TLSMinVersion            string        // Oldest TLS version accepted: 1.2 or 1.3
+	TLSCipherSuites          []string      // Go names of the TLS 1.2 cipher suites offered (a hardened default set when empty)
+	TLSClientAuth            string        // Client certificate policy: none, request, require, verify_if_given or require_and_verify
+	TLSClientCAFile          string        // PEM bundle client certificates are verified against
+	HSTSMaxAge               time.Duration // Strict-Transport-Security max-age sent with every response (0 disables)
+	HSTSIncludeSubdomains    bool
+	HSTSPreload              bool
+	FXAPIBaseURL             string   // Added field for API base URL
+	FXProvider               string   // Which FX provider to use ("bnm" is the default)
+	FXSession                string   // Default BNM session to fetch (0900, 1200 or 1700)
+	FXFallbackProviders      []string // Providers tried in order when the primary is unavailable
+	FrankfurterBaseURL       string
+	ExchangeRateHostBaseURL  string
+	ExchangeRateHostAPIKey   string
+	Sources                  map[string]SourceConfig // Per-source base URL and politeness settings, keyed by the Source* names
+	StockList                []string
+	DefaultCountry           string             // ISO 3166 country of STOCK_LIST and the scraped sources; others arrive through /api/ingest
+	ProfileRefreshInterval   time.Duration      // Profiles scraped more recently than this are skipped by stock:fetch:profile_all
+	DelistAfterDays          int                // Days a stock page must stay missing before the company is marked delisted (0 never)
+	IntradayInterval         time.Duration      // How often tracked stocks are polled during market hours (0 disables)
+	IntradayRetentionDays    int                // Intraday prices older than this are removed by db:maintenance (0 keeps them)
+	EERWeights               map[string]float64 // Trade weights per currency for the effective exchange rate index
+	EERStartDate             time.Time          // First date included in the effective exchange rate computation
+	EERRecalcInterval        time.Duration      // How often the scheduler recomputes the index (0 disables)
+	ActivityIndexWeights     map[string]float64 // Weights per macro series in the composite activity index
+	ActivityIndexLevelSeries []string           // Components used as stored (e.g. diffusion indexes, growth rates) rather than as year-on-year growth
+	SessionTTL               time.Duration      // Lifetime of a login session
+	JWTSecret                string             // HMAC key for API tokens; API login is disabled when empty
+	JWTTTL                   time.Duration      // Lifetime of an API token
+	SMTPHost                 string             // Email notifications are disabled when empty
+	SMTPPort                 int
+	SMTPUsername             string
+	SMTPPassword             string
+	SMTPFrom                 string
+	NotifyEmailTo            []string // Operators who receive batch-run failure summaries
+	TelegramBotToken         string   // Telegram bot is disabled when empty
+	TelegramAPIBaseURL       string
+	TelegramNotifyChatIDs    []string      // Chats that receive batch-run failure summaries
+	WebhookMaxAttempts       int           // Delivery attempts per webhook event, including the first
+	WebhookRetryBackoff      time.Duration // Delay before the first retry; doubled for each further retry
+	LogFile                  string        // Logs are also written here, with rotation, when set
+	LogMaxSizeMB             int           // Rotate once the log file reaches this size
+	LogMaxAgeDays            int           // Delete rotated files older than this (0 keeps them)
+	LogMaxBackups            int           // Number of rotated files to keep (0 keeps all)
+	LogCompress              bool          // Gzip rotated files
+	StatusStaleAfter         time.Duration // Data sources with nothing newer are flagged stale on /status
+	CommandTimeout           time.Duration // CLI commands, triggered fetches and scheduled jobs are cancelled after this (0 disables)
+	ShutdownGracePeriod      time.Duration // On shutdown, running commands and jobs get this long to finish before they are cancelled
+	APIMaxRangeDays          int           // Longest start_date to end_date span an API request may ask for (0 disables)
+	APIMaxSeriesDays         int           // Series times days a multi-series API request may ask for (0 disables)
+	APIRequestTimeout        time.Duration // Data API requests are cancelled and answered 504 after this (0 disables)
+	APICacheTTL              time.Duration // Public data responses are cached for this long, until new data is stored (0 disables)
+	APICacheMaxEntries       int           // Responses held in the in-memory cache at once
+	CacheRedisURL            string        // Share the cache between instances through Redis (in-memory when empty)
+	CacheKeyPrefix           string        // Redis keys are <prefix>:<generation>:<key>
+	QueryMaxRows             int           // Rows an /api/query SELECT may return (0 disables the endpoint)
+	QueryTimeout             time.Duration // /api/query statements are cancelled after this
+	QueryDBURL               string        // Connection of the query_sandbox login role /api/query runs on (the endpoint is disabled when empty)
+	BetaBenchmark            string        // Series key stocks are regressed against for beta, e.g. stock:FBMKLCI
+	BetaWindow               int           // Trading days in the rolling beta regression
+	CorrelationSeries        []string      // Series in the stored correlation matrix (all with returns when empty)
+	CorrelationWindow        int           // Trading days the stored correlation matrix covers
+	CorrelationInterval      time.Duration // How often the scheduler recomputes the matrix (0 disables)
+	DigestEmailTo            []string      // Recipients of the market digest (tracked stocks and all currencies)
+	DigestWatchlistUsers     bool          // Also email every user with a watchlist a digest of their own items
+	DigestInterval           time.Duration // Period each digest covers and how often it is sent (24h daily, 168h weekly; 0 disables)
+	DigestTopMovers          int           // Stocks listed among the top movers
+	SnapshotEndpoint         string        // S3-compatible endpoint (host[:port]) for table snapshots
+	SnapshotBucket           string        // Snapshots are disabled when empty
+	SnapshotPrefix           string        // Key prefix; objects are <prefix>/<YYYY-MM-DD>/<table>.csv.gz
+	SnapshotAccessKey        string
+	SnapshotSecretKey        string
+	SnapshotRegion           string
+	SnapshotUseSSL           bool
+	SnapshotRetentionDays    int           // Snapshots older than this are deleted after each export (0 keeps them)
+	SnapshotInterval         time.Duration // How often the scheduler exports a snapshot (0 disables)
+	SnapshotExcludeTables    []string      // Tables left out of snapshots
+	SnapshotCredentials      bool          // Include the credential tables (users, sessions, keys, secrets), left out by default
+	PublishDir               string        // Directory static series JSON files are written to (disabled when empty)
+	PublishBucket            string        // Bucket they are uploaded to, using the SNAPSHOT_* endpoint and credentials (disabled when empty)
+	PublishPrefix            string        // Key prefix in PublishBucket
+	PublishInterval          time.Duration // How often the scheduler republishes (0 disables)
+	DocumentsDir             string        // Directory company report files are stored in
+	DocumentsBucket          string        // Bucket they are stored in instead, using the SNAPSHOT_* endpoint and credentials
+	DocumentsPrefix          string        // Key prefix in DocumentsBucket
+	DocumentMaxMB            int           // Largest report file documents:fetch downloads
+	HolidayRefreshInterval   time.Duration // How often the scheduler refreshes the holidays (0 disables)
+	EntitlementsURLs         []string      // Bursa/i3investor entitlement pages scraped by entitlements:fetch (disabled when empty)
+	EntitlementsInterval     time.Duration // How often the scheduler runs entitlements:fetch (0 disables)
+	MaintenanceInterval      time.Duration // How often the scheduler runs db:maintenance (0 disables)
+	AuditRetentionDays       int           // Audit log entries older than this are removed by db:maintenance (0 keeps them)
+	FetchRunRetentionDays    int           // Finished fetch runs older than this are removed by db:maintenance (0 keeps them)
+	PartitionMonthsAhead     int           // Monthly audit log partitions db:maintenance keeps created ahead of time
+	NewsFeedURLs             []string      // RSS/Atom feeds scanned for headlines about tracked companies (disabled when empty)
+	NewsFetchInterval        time.Duration // How often the scheduler runs news:fetch (0 disables)
+	GSheetsCredentialsFile   string        // Google service account JSON key file
+	GSheetsSpreadsheetID     string        // Spreadsheet series are pushed to (disabled when empty)
+	GSheetsSheet             string        // Tab of the spreadsheet that is overwritten
+	GSheetsSeries            []string      // Series pushed, e.g. fx:USD, stock:1155, macro:cpi
+	GSheetsLookbackDays      int           // Days of history written
+	GSheetsInterval          time.Duration // How often the scheduler pushes (0 disables)
+	GSheetsAPIBaseURL        string
+	EventBus                 string // Message bus stored observations are published to: nats, kafka or empty (disabled)
+	EventBusURL              string // NATS server URL, or comma-separated Kafka brokers
+	EventTopicPrefix         string // Events go to <prefix>.stock, <prefix>.fx and <prefix>.macro
+	SentryDSN                string // Error reporting is disabled when empty
+	SentryEnvironment        string
+}
+
+// defaultFrontendDir serves ./frontend from disk for the dev profile, so edits show up without
+// a rebuild, and otherwise the frontend embedded in the binary.
+func defaultFrontendDir(profile string) string {
+	if profile == "dev" {
+		return "./frontend"
+	}
+	return ""
 }
 
 // Read loads configuration from environment variables.
-// It loads from a .env file first if it exists.
-func Read() (Config, error) {
+// When a profile is given (or APP_PROFILE is set) .env.<profile> is loaded first and must
+// exist; the shared .env file is then loaded if it exists. Neither overrides variables already
+// set in the environment, and the profile file takes precedence over .env.
+func Read(profile string) (Config, error) {
+	if profile == "" {
+		profile = os.Getenv("APP_PROFILE")
+	}
+	if profile != "" {
+		if !validProfile(profile) {
+			return Config{}, fmt.Errorf("unknown profile %q (use %s)", profile, strings.Join(Profiles, ", "))
+		}
+		// A missing profile file is an error so a typo can't silently fall back to another environment's settings
+		profileFile := ".env." + profile
+		if err := godotenv.Load(profileFile); err != nil {
+			return Config{}, fmt.Errorf("failed to load %s for profile %s: %w", profileFile, profile, err)
+		}
+		log.Printf("Loaded configuration for profile %s from %s.", profile, profileFile)
+	}
+
 	// Attempt to load .env file, ignore error if it doesn't exist
 	err := godotenv.Load()
 	if err != nil {
@@ -32,47 +175,177 @@ func Read() (Config, error) {
 		log.Println("Loaded configuration from .env file.")
 	}
 	// Load stock list separately
-	stockListStr := getEnv("STOCK_LIST", "")
-	var stockList []string
-	if stockListStr != "" {
-		// Split by comma
-		rawList := strings.Split(stockListStr, ",")
-		// Trim whitespace from each element and filter out empty strings
-		for _, code := range rawList {
-			trimmedCode := strings.TrimSpace(code)
-			if trimmedCode != "" { // Only add non-empty codes
-				stockList = append(stockList, trimmedCode)
-			}
-		}
-	} else {
+	stockList := getEnvList("STOCK_LIST")
+	if len(stockList) == 0 {
 		log.Println("Warning: STOCK_LIST environment variable not set or empty.")
-		stockList = []string{} // Initialize as empty slice
+	}
+	// Macro series codes are lower case; getEnvWeights upper-cases the currency codes it is usually given
+	// ipi and exports come from macro:fetch; loan_growth and pmi are pushed through /api/ingest
+	activityWeights := make(map[string]float64)
+	for code, weight := range getEnvWeights("ACTIVITY_INDEX_WEIGHTS", "ipi:0.35,exports:0.25,loan_growth:0.20,pmi:0.20") {
+		activityWeights[strings.ToLower(code)] = weight
+	}
+	activityLevelSeries := []string{"pmi", "loan_growth"}
+	if _, set := os.LookupEnv("ACTIVITY_INDEX_LEVEL_SERIES"); set {
+		activityLevelSeries = getEnvList("ACTIVITY_INDEX_LEVEL_SERIES")
 	}
 
+	// Credentials may come from <KEY>_FILE (Docker secrets) or a vault:<path>#<field> reference
+	secrets := newSecretResolver()
+
 	cfg := Config{
-		DBURL:                     getEnv("DB_URL", ""),           // Provide a default or handle error if critical
-		ServerAddr:                getEnv("SERVER_ADDR", ":8443"), // Default HTTPS port
-		CertFile:                  getEnv("CERT_FILE", "./certs/cert.pem"),
-		KeyFile:                   getEnv("KEY_FILE", "./certs/key.pem"),
-		FXAPIBaseURL:              getEnv("FX_API_BASE_URL", ""), // Read API base URL
-		I3InvestorBaseURL:         getEnv("I3_INVESTOR_BASE_URL", ""),
-		I3InvestorStockProfileURL: getEnv("I3_INVESTOR_STOCK_PROFILE_URL", ""),
-		StockList:                 stockList,
+		Profile:                 profile,
+		DBURL:                   secrets.get("DB_URL", ""),
+		FXAPIKey:                secrets.get("FX_API_KEY", ""),
+		ServerAddr:              getEnv("SERVER_ADDR", ":8443"), // Default HTTPS port
+		ServerDisabled:          getEnvBool("SERVER_DISABLED", false),
+		FrontendDir:             getEnv("FRONTEND_DIR", defaultFrontendDir(profile)),
+		BasePath:                strings.TrimRight(getEnv("BASE_PATH", ""), "/"),
+		TrustedProxies:          getEnvList("TRUSTED_PROXIES"), // e.g. "127.0.0.1,10.0.0.0/8"
+		PublicReadOnly:          getEnvBool("PUBLIC_READ_ONLY", false),
+		PublicEndpoints:         getEnvList("PUBLIC_ENDPOINTS"), // e.g. "/api/fx/rates,/api/stock/prices,/"
+		PublicCacheMaxAge:       getEnvDuration("PUBLIC_CACHE_MAX_AGE", time.Hour),
+		CertFile:                getEnv("CERT_FILE", "./certs/cert.pem"),
+		KeyFile:                 getEnv("KEY_FILE", "./certs/key.pem"),
+		TLSMinVersion:           getEnv("TLS_MIN_VERSION", "1.2"),
+		TLSCipherSuites:         getEnvList("TLS_CIPHER_SUITES"),
+		TLSClientAuth:           getEnv("TLS_CLIENT_AUTH", "none"),
+		TLSClientCAFile:         getEnv("TLS_CLIENT_CA_FILE", ""),
+		HSTSMaxAge:              getEnvDuration("HSTS_MAX_AGE", 0),
+		HSTSIncludeSubdomains:   getEnvBool("HSTS_INCLUDE_SUBDOMAINS", false),
+		HSTSPreload:             getEnvBool("HSTS_PRELOAD", false),
+		FXAPIBaseURL:            getEnv("FX_API_BASE_URL", ""), // Read API base URL
+		FXProvider:              getEnv("FX_PROVIDER", "bnm"),
+		FXSession:               getEnv("FX_SESSION", "1200"),
+		FXFallbackProviders:     getEnvList("FX_FALLBACK_PROVIDERS"), // e.g. "frankfurter,exchangeratehost"
+		FrankfurterBaseURL:      getEnv("FRANKFURTER_BASE_URL", "https://api.frankfurter.app"),
+		ExchangeRateHostBaseURL: getEnv("EXCHANGERATE_HOST_BASE_URL", "https://api.exchangerate.host"),
+		ExchangeRateHostAPIKey:  secrets.get("EXCHANGERATE_HOST_API_KEY", ""),
+		Sources:                 readSources(),
+		StockList:               stockList,
+		DefaultCountry:          strings.ToUpper(getEnv("DEFAULT_COUNTRY", "MY")),
+		ProfileRefreshInterval:  getEnvDuration("PROFILE_REFRESH_INTERVAL", 7*24*time.Hour), // Default: refresh weekly
+		DelistAfterDays:         getEnvInt("DELIST_AFTER_DAYS", 14),
+		IntradayInterval:        getEnvDuration("INTRADAY_INTERVAL", 0), // e.g. 15m; off by default
+		IntradayRetentionDays:   getEnvInt("INTRADAY_RETENTION_DAYS", 30),
+		// Illustrative default basket; set EER_WEIGHTS from current DOSM trade shares for real use
+		EERWeights:               getEnvWeights("EER_WEIGHTS", "USD:0.20,CNY:0.20,SGD:0.15,EUR:0.10,JPY:0.10,THB:0.05,IDR:0.05,KRW:0.05,TWD:0.05,HKD:0.05"),
+		EERStartDate:             getEnvDate("EER_START_DATE", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		EERRecalcInterval:        getEnvDuration("EER_RECALC_INTERVAL", 24*time.Hour),
+		ActivityIndexWeights:     activityWeights,
+		ActivityIndexLevelSeries: activityLevelSeries,
+		SessionTTL:               getEnvDuration("SESSION_TTL", 24*time.Hour),
+		JWTSecret:                secrets.get("JWT_SECRET", ""),
+		JWTTTL:                   getEnvDuration("JWT_TTL", 15*time.Minute),
+		SMTPHost:                 getEnv("SMTP_HOST", ""),
+		SMTPPort:                 getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:             getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:             secrets.get("SMTP_PASSWORD", ""),
+		SMTPFrom:                 getEnv("SMTP_FROM", ""),
+		NotifyEmailTo:            getEnvList("NOTIFY_EMAIL_TO"),
+		TelegramBotToken:         secrets.get("TELEGRAM_BOT_TOKEN", ""),
+		TelegramAPIBaseURL:       getEnv("TELEGRAM_API_BASE_URL", "https://api.telegram.org"),
+		TelegramNotifyChatIDs:    getEnvList("TELEGRAM_NOTIFY_CHAT_IDS"),
+		WebhookMaxAttempts:       getEnvInt("WEBHOOK_MAX_ATTEMPTS", 4),
+		WebhookRetryBackoff:      getEnvDuration("WEBHOOK_RETRY_BACKOFF", 2*time.Second),
+		LogFile:                  getEnv("LOG_FILE", ""),
+		LogMaxSizeMB:             getEnvInt("LOG_MAX_SIZE_MB", 100),
+		LogMaxAgeDays:            getEnvInt("LOG_MAX_AGE_DAYS", 28),
+		LogMaxBackups:            getEnvInt("LOG_MAX_BACKUPS", 5),
+		LogCompress:              getEnvBool("LOG_COMPRESS", true),
+		StatusStaleAfter:         getEnvDuration("STATUS_STALE_AFTER", 96*time.Hour), // Covers weekends and public holidays
+		CommandTimeout:           getEnvDuration("COMMAND_TIMEOUT", 2*time.Hour),
+		ShutdownGracePeriod:      getEnvDuration("SHUTDOWN_GRACE_PERIOD", 30*time.Second),
+		APIMaxRangeDays:          getEnvInt("API_MAX_RANGE_DAYS", 20*366),
+		APIMaxSeriesDays:         getEnvInt("API_MAX_SERIES_DAYS", 50000), // e.g. 10 series over 13 years
+		APIRequestTimeout:        getEnvDuration("API_REQUEST_TIMEOUT", 8*time.Second),
+		APICacheTTL:              getEnvDuration("API_CACHE_TTL", 10*time.Minute),
+		APICacheMaxEntries:       getEnvInt("API_CACHE_MAX_ENTRIES", 1000),
+		CacheRedisURL:            secrets.get("CACHE_REDIS_URL", ""), // e.g. redis://:password@localhost:6379/0
+		CacheKeyPrefix:           getEnv("CACHE_KEY_PREFIX", "econdb:cache"),
+		QueryMaxRows:             getEnvInt("QUERY_MAX_ROWS", 1000),
+		QueryTimeout:             getEnvDuration("QUERY_TIMEOUT", 5*time.Second),
+		QueryDBURL:               secrets.get("QUERY_DB_URL", ""), // Logs in as query_sandbox, never the application role
+		BetaBenchmark:            getEnv("BETA_BENCHMARK", "stock:FBMKLCI"),
+		BetaWindow:               getEnvInt("BETA_WINDOW", 250),
+		CorrelationSeries:        getEnvList("CORRELATION_SERIES"), // e.g. "stock:1155,stock:5347,fx:USD,fx:SGD"
+		CorrelationWindow:        getEnvInt("CORRELATION_WINDOW", 60),
+		CorrelationInterval:      getEnvDuration("CORRELATION_INTERVAL", 24*time.Hour),
+		DigestEmailTo:            getEnvList("DIGEST_EMAIL_TO"),
+		DigestWatchlistUsers:     getEnvBool("DIGEST_WATCHLIST_USERS", false),
+		DigestInterval:           getEnvDuration("DIGEST_INTERVAL", 24*time.Hour),
+		DigestTopMovers:          getEnvInt("DIGEST_TOP_MOVERS", 5),
+		SnapshotEndpoint:         getEnv("SNAPSHOT_ENDPOINT", "s3.amazonaws.com"),
+		SnapshotBucket:           getEnv("SNAPSHOT_BUCKET", ""),
+		SnapshotPrefix:           strings.Trim(getEnv("SNAPSHOT_PREFIX", "econdb"), "/"),
+		SnapshotAccessKey:        secrets.get("SNAPSHOT_ACCESS_KEY", ""),
+		SnapshotSecretKey:        secrets.get("SNAPSHOT_SECRET_KEY", ""),
+		SnapshotRegion:           getEnv("SNAPSHOT_REGION", ""),
+		SnapshotUseSSL:           getEnvBool("SNAPSHOT_USE_SSL", true),
+		SnapshotRetentionDays:    getEnvInt("SNAPSHOT_RETENTION_DAYS", 30),
+		SnapshotInterval:         getEnvDuration("SNAPSHOT_INTERVAL", 24*time.Hour),
+		SnapshotExcludeTables:    getEnvList("SNAPSHOT_EXCLUDE_TABLES"), // e.g. "audit_log,goose_db_version"
+		SnapshotCredentials:      getEnvBool("SNAPSHOT_INCLUDE_CREDENTIALS", false),
+		PublishDir:               getEnv("PUBLISH_DIR", ""),
+		PublishBucket:            getEnv("PUBLISH_BUCKET", ""),
+		PublishPrefix:            strings.Trim(getEnv("PUBLISH_PREFIX", "public"), "/"),
+		PublishInterval:          getEnvDuration("PUBLISH_INTERVAL", 24*time.Hour),
+		DocumentsDir:             getEnv("DOCUMENTS_DIR", "./documents"),
+		DocumentsBucket:          getEnv("DOCUMENTS_BUCKET", ""),
+		DocumentsPrefix:          strings.Trim(getEnv("DOCUMENTS_PREFIX", "documents"), "/"),
+		DocumentMaxMB:            getEnvInt("DOCUMENT_MAX_MB", 50),
+		HolidayRefreshInterval:   getEnvDuration("HOLIDAY_REFRESH_INTERVAL", 7*24*time.Hour),
+		EntitlementsURLs:         getEnvList("ENTITLEMENTS_URLS"), // e.g. "https://klse.i3investor.com/web/entitlement/dividend/latest"
+		EntitlementsInterval:     getEnvDuration("ENTITLEMENTS_FETCH_INTERVAL", 24*time.Hour),
+		MaintenanceInterval:      getEnvDuration("MAINTENANCE_INTERVAL", 6*time.Hour),
+		AuditRetentionDays:       getEnvInt("AUDIT_RETENTION_DAYS", 365),
+		FetchRunRetentionDays:    getEnvInt("FETCH_RUN_RETENTION_DAYS", 180),
+		PartitionMonthsAhead:     getEnvInt("PARTITION_MONTHS_AHEAD", 3),
+		NewsFeedURLs:             getEnvList("NEWS_FEED_URLS"), // e.g. "https://www.thestar.com.my/rss/Business/Business-News"
+		NewsFetchInterval:        getEnvDuration("NEWS_FETCH_INTERVAL", time.Hour),
+		GSheetsCredentialsFile:   getEnv("GSHEETS_CREDENTIALS_FILE", ""),
+		GSheetsSpreadsheetID:     getEnv("GSHEETS_SPREADSHEET_ID", ""),
+		GSheetsSheet:             getEnv("GSHEETS_SHEET", "EconDB"),
+		GSheetsSeries:            getEnvList("GSHEETS_SERIES"), // e.g. "fx:USD,fx:SGD,stock:1155,macro:cpi"
+		GSheetsLookbackDays:      getEnvInt("GSHEETS_LOOKBACK_DAYS", 90),
+		GSheetsInterval:          getEnvDuration("GSHEETS_INTERVAL", 24*time.Hour),
+		GSheetsAPIBaseURL:        getEnv("GSHEETS_API_BASE_URL", "https://sheets.googleapis.com"),
+		EventBus:                 strings.ToLower(getEnv("EVENT_BUS", "")),
+		EventBusURL:              secrets.get("EVENT_BUS_URL", ""), // May embed credentials
+		EventTopicPrefix:         getEnv("EVENT_TOPIC_PREFIX", "econdb"),
+		SentryDSN:                secrets.get("SENTRY_DSN", ""),
+		SentryEnvironment:        getEnv("SENTRY_ENVIRONMENT", sentryEnvironmentDefault(profile)),
 	}
 
-	// Add validation if needed (e.g., check if critical variables are set)
-	if cfg.DBURL == "" {
-		log.Println("Warning: DATABASE_URL environment variable not set.")
-		// Depending on requirements, you might return an error here:
-		// return Config{}, errors.New("DATABASE_URL environment variable is required")
+	if err := secrets.err(); err != nil {
+		return Config{}, fmt.Errorf("failed to resolve secrets: %w", err)
 	}
-	if cfg.FXAPIBaseURL == "" {
-		log.Println("Warning: FX_API_BASE_URL environment variable not set.")
+
+	// Hard requirements are checked by Validate; this only flags optional features left off
+	if cfg.JWTSecret == "" {
+		log.Println("Warning: JWT_SECRET environment variable not set; API login is disabled.")
 	}
 
 	return cfg, nil
 }
 
+func validProfile(profile string) bool {
+	for _, p := range Profiles {
+		if p == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// sentryEnvironmentDefault reports errors under the profile name, or "production" without one.
+func sentryEnvironmentDefault(profile string) string {
+	if profile == "" {
+		return "production"
+	}
+	return profile
+}
+
 // getEnv retrieves an environment variable or returns a default value.
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -80,3 +353,94 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvList retrieves a comma-separated environment variable as a slice,
+// trimming whitespace and dropping empty entries. Unset variables yield an empty slice.
+func getEnvList(key string) []string {
+	list := []string{}
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		trimmed := strings.TrimSpace(item)
+		if trimmed != "" { // Only add non-empty entries
+			list = append(list, trimmed)
+		}
+	}
+	return list
+}
+
+// getEnvInt retrieves an environment variable as an integer, returning the fallback if unset or invalid.
+func getEnvInt(key string, fallback int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: Invalid integer '%s' for %s, using default %d.", value, key, fallback)
+		return fallback
+	}
+	return n
+}
+
+// getEnvBool retrieves an environment variable as a boolean ("true", "1", "false", ...),
+// returning the fallback if unset or invalid.
+func getEnvBool(key string, fallback bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: Invalid boolean '%s' for %s, using default %t.", value, key, fallback)
+		return fallback
+	}
+	return b
+}
+
+// getEnvDuration retrieves an environment variable as a time.Duration (e.g. "24h", "90m").
+// It returns the fallback if the variable is unset or cannot be parsed.
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: Invalid duration '%s' for %s, using default %s.", value, key, fallback)
+		return fallback
+	}
+	return d
+}
+
+// getEnvDate retrieves an environment variable as a YYYY-MM-DD date, returning the fallback if unset or invalid.
+func getEnvDate(key string, fallback time.Time) time.Time {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return fallback
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		log.Printf("Warning: Invalid date '%s' for %s (use YYYY-MM-DD), using default %s.", value, key, fallback.Format("2006-01-02"))
+		return fallback
+	}
+	return t
+}
+
+// getEnvWeights parses a "CODE:weight,CODE:weight" list (e.g. "USD:0.2,CNY:0.2").
+// Malformed entries are skipped with a warning.
+func getEnvWeights(key, fallback string) map[string]float64 {
+	weights := make(map[string]float64)
+	for _, entry := range strings.Split(getEnv(key, fallback), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		code, weightStr, ok := strings.Cut(entry, ":")
+		weight, err := strconv.ParseFloat(strings.TrimSpace(weightStr), 64)
+		if !ok || err != nil || weight < 0 {
+			log.Printf("Warning: Ignoring invalid weight entry '%s' in %s.", entry, key)
+			continue
+		}
+		weights[strings.ToUpper(strings.TrimSpace(code))] = weight
+	}
+	return weights
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// vaultPrefix marks a secret value that is a reference into HashiCorp Vault's KV v2 engine,
+// e.g. "vault:secret/data/econdb#db_url" reads field db_url of secret/data/econdb.
+const vaultPrefix = "vault:"
+
+// secretResolver reads secret settings from the environment, from <KEY>_FILE paths (Docker
+// secrets) or from Vault references. Errors are collected so Read can report all of them.
+type secretResolver struct {
+	errs       []error
+	httpClient *http.Client
+	vaultCache map[string]map[string]interface{} // KV data per Vault path
+}
+
+func newSecretResolver() *secretResolver {
+	return &secretResolver{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		vaultCache: make(map[string]map[string]interface{}),
+	}
+}
+
+// get returns the secret for key, in order of precedence:
+//   - the contents of the file named by <key>_FILE, without trailing newlines
+//   - the value of key, resolved through Vault if it starts with "vault:"
+//   - fallback
+func (r *secretResolver) get(key, fallback string) string {
+	if path := os.Getenv(key + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("%s_FILE: %w", key, err))
+			return fallback
+		}
+		return strings.TrimRight(string(data), "\r\n")
+	}
+
+	value := getEnv(key, fallback)
+	if ref, ok := strings.CutPrefix(value, vaultPrefix); ok {
+		secret, err := r.readVault(ref)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("%s: %w", key, err))
+			return fallback
+		}
+		return secret
+	}
+	return value
+}
+
+// err returns every resolution error, joined.
+func (r *secretResolver) err() error {
+	return errors.Join(r.errs...)
+}
+
+// readVault resolves a "path#field" reference using VAULT_ADDR and VAULT_TOKEN (or VAULT_TOKEN_FILE).
+func (r *secretResolver) readVault(ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("invalid Vault reference %q (use vault:<path>#<field>)", vaultPrefix+ref)
+	}
+
+	data, cached := r.vaultCache[path]
+	if !cached {
+		addr := strings.TrimRight(os.Getenv("VAULT_ADDR"), "/")
+		if addr == "" {
+			return "", errors.New("Vault reference used but VAULT_ADDR is not set")
+		}
+		token := os.Getenv("VAULT_TOKEN")
+		if tokenFile := os.Getenv("VAULT_TOKEN_FILE"); tokenFile != "" {
+			raw, err := os.ReadFile(tokenFile)
+			if err != nil {
+				return "", fmt.Errorf("VAULT_TOKEN_FILE: %w", err)
+			}
+			token = strings.TrimSpace(string(raw))
+		}
+		if token == "" {
+			return "", errors.New("Vault reference used but neither VAULT_TOKEN nor VAULT_TOKEN_FILE is set")
+		}
+
+		req, err := http.NewRequest(http.MethodGet, addr+"/v1/"+strings.TrimLeft(path, "/"), nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to build Vault request for %s: %w", path, err)
+		}
+		req.Header.Set("X-Vault-Token", token)
+		resp, err := r.httpClient.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s from Vault: %w", path, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("failed to read %s from Vault: status %s", path, resp.Status)
+		}
+
+		var body struct {
+			Data struct {
+				Data map[string]interface{} `json:"data"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return "", fmt.Errorf("failed to decode Vault response for %s: %w", path, err)
+		}
+		data = body.Data.Data
+		r.vaultCache[path] = data
+	}
+
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("Vault secret %s has no string field %q", path, field)
+	}
+	return value, nil
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// Names of the data sources the scrapers and API clients request pages from.
+const (
+	SourceI3Investor        = "i3investor"         // Stock price and ratio pages; BaseURL is followed by the stock code
+	SourceI3InvestorProfile = "i3investor_profile" // Stock profile pages; BaseURL is followed by the stock code
+	SourceOpenDOSM          = "opendosm"           // data.gov.my data catalogue API
+	SourceBursaHolidays     = "bursa_holidays"     // Bursa holiday calendar page (market:holidays:fetch is disabled without a BaseURL)
+	SourceEntitlements      = "entitlements"       // Entitlement pages listed in ENTITLEMENTS_URLS
+	SourceDocuments         = "documents"          // Company report PDFs downloaded by documents:fetch
+)
+
+// browserUserAgent is sent to sites that block requests without a browser-like User-Agent.
+const browserUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
+
+// SourceConfig holds how politely one data source is requested. Each field is read from
+// SOURCE_<NAME>_<FIELD>, e.g. SOURCE_I3INVESTOR_RATE_LIMIT=1s.
+type SourceConfig struct {
+	Name      string
+	BaseURL   string
+	Enabled   bool          // Requests to a disabled source fail without being sent
+	RateLimit time.Duration // Minimum delay between the starts of two requests (0 disables)
+	Timeout   time.Duration // Per attempt
+	Retries   int           // Further attempts after a network error, 429 or 5xx response
+	UserAgent string
+}
+
+// EnvPrefix is the prefix of the source's environment variables, e.g. SOURCE_I3INVESTOR_.
+func (sc SourceConfig) EnvPrefix() string {
+	return "SOURCE_" + strings.ToUpper(sc.Name) + "_"
+}
+
+// sourceDefault is a source's configuration when none of its variables are set, and the
+// flat variable its base URL was read from before sources had their own block.
+type sourceDefault struct {
+	SourceConfig
+	legacyURLVar string
+}
+
+var sourceDefaults = []sourceDefault{
+	{SourceConfig{Name: SourceI3Investor, RateLimit: 500 * time.Millisecond, Timeout: 15 * time.Second, Retries: 2, UserAgent: browserUserAgent}, "I3_INVESTOR_BASE_URL"},
+	{SourceConfig{Name: SourceI3InvestorProfile, RateLimit: 500 * time.Millisecond, Timeout: 15 * time.Second, Retries: 2, UserAgent: browserUserAgent}, "I3_INVESTOR_STOCK_PROFILE_URL"},
+	{SourceConfig{Name: SourceOpenDOSM, BaseURL: "https://api.data.gov.my", Timeout: 30 * time.Second, Retries: 2, UserAgent: "Malaysia-Econ-DB/1.0"}, "OPENDOSM_BASE_URL"},
+	{SourceConfig{Name: SourceBursaHolidays, Timeout: 30 * time.Second, Retries: 1, UserAgent: browserUserAgent}, "BURSA_HOLIDAYS_URL"},
+	{SourceConfig{Name: SourceEntitlements, RateLimit: time.Second, Timeout: 30 * time.Second, Retries: 1, UserAgent: browserUserAgent}, ""},
+	{SourceConfig{Name: SourceDocuments, RateLimit: time.Second, Timeout: 5 * time.Minute, Retries: 1, UserAgent: browserUserAgent}, ""},
+}
+
+// readSources loads the configuration of every known source. A base URL still set through its
+// old flat variable (e.g. I3_INVESTOR_BASE_URL) is used, with a warning, when the
+// SOURCE_<NAME>_BASE_URL variable is not.
+func readSources() map[string]SourceConfig {
+	sources := make(map[string]SourceConfig, len(sourceDefaults))
+	for _, def := range sourceDefaults {
+		sc := def.SourceConfig
+		prefix := sc.EnvPrefix()
+		if legacy, set := os.LookupEnv(def.legacyURLVar); set && def.legacyURLVar != "" {
+			if _, set := os.LookupEnv(prefix + "BASE_URL"); !set {
+				log.Printf("Warning: %s is deprecated; set %sBASE_URL instead.", def.legacyURLVar, prefix)
+				sc.BaseURL = legacy
+			}
+		}
+		sc.BaseURL = getEnv(prefix+"BASE_URL", sc.BaseURL)
+		sc.Enabled = getEnvBool(prefix+"ENABLED", true)
+		sc.RateLimit = getEnvDuration(prefix+"RATE_LIMIT", sc.RateLimit)
+		sc.Timeout = getEnvDuration(prefix+"TIMEOUT", sc.Timeout)
+		sc.Retries = getEnvInt(prefix+"RETRIES", sc.Retries)
+		sc.UserAgent = getEnv(prefix+"USER_AGENT", sc.UserAgent)
+		sources[sc.Name] = sc
+	}
+	return sources
+}
+
+// Source returns the configuration of the named source (one of the Source* constants).
+// An unknown name yields a disabled source.
+func (c Config) Source(name string) SourceConfig {
+	if sc, ok := c.Sources[name]; ok {
+		return sc
+	}
+	return SourceConfig{Name: name}
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultCipherSuites are used for TLS 1.2 connections when TLS_CIPHER_SUITES is not set.
+var defaultCipherSuites = []string{
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", // Required for HTTP/2
+}
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify_if_given":    tls.VerifyClientCertIfGiven,
+	"require_and_verify": tls.RequireAndVerifyClientCert,
+}
+
+// TLSConfig builds the HTTPS server's TLS settings from TLS_MIN_VERSION, TLS_CIPHER_SUITES,
+// TLS_CLIENT_AUTH and TLS_CLIENT_CA_FILE. Cipher suites only apply to TLS 1.2; Go does not
+// allow the TLS 1.3 suites to be configured.
+func (c Config) TLSConfig() (*tls.Config, error) {
+	minVersion, ok := tlsVersions[c.TLSMinVersion]
+	if !ok {
+		return nil, fmt.Errorf("TLS_MIN_VERSION %q must be 1.2 or 1.3", c.TLSMinVersion)
+	}
+	suites, err := cipherSuiteIDs(c.TLSCipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	clientAuth, ok := clientAuthTypes[strings.ToLower(c.TLSClientAuth)]
+	if !ok {
+		return nil, fmt.Errorf("TLS_CLIENT_AUTH %q must be none, request, require, verify_if_given or require_and_verify", c.TLSClientAuth)
+	}
+
+	cfg := &tls.Config{
+		MinVersion:       minVersion,
+		CurvePreferences: []tls.CurveID{tls.CurveP521, tls.CurveP384, tls.CurveP256},
+		CipherSuites:     suites,
+		ClientAuth:       clientAuth,
+	}
+	verify := clientAuth == tls.VerifyClientCertIfGiven || clientAuth == tls.RequireAndVerifyClientCert
+	if verify && c.TLSClientCAFile == "" {
+		return nil, fmt.Errorf("TLS_CLIENT_AUTH %s requires TLS_CLIENT_CA_FILE", c.TLSClientAuth)
+	}
+	if c.TLSClientCAFile != "" {
+		pem, err := os.ReadFile(c.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("TLS_CLIENT_CA_FILE %q cannot be read: %w", c.TLSClientCAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("TLS_CLIENT_CA_FILE %q has no PEM certificates", c.TLSClientCAFile)
+		}
+		cfg.ClientCAs = pool
+	}
+	return cfg, nil
+}
+
+// cipherSuiteIDs resolves Go cipher suite names (e.g. TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256),
+// rejecting the ones Go considers insecure. An empty list gives defaultCipherSuites.
+func cipherSuiteIDs(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		names = defaultCipherSuites
+	}
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+	insecure := make(map[string]bool)
+	for _, suite := range tls.InsecureCipherSuites() {
+		insecure[suite.Name] = true
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		name = strings.ToUpper(name)
+		id, ok := known[name]
+		switch {
+		case insecure[name]:
+			return nil, fmt.Errorf("TLS_CIPHER_SUITES: %s is insecure", name)
+		case !ok:
+			return nil, fmt.Errorf("TLS_CIPHER_SUITES: unknown cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// HSTSHeader is the Strict-Transport-Security header value sent with every HTTPS response,
+// or empty when HSTS_MAX_AGE is 0.
+func (c Config) HSTSHeader() string {
+	if c.HSTSMaxAge <= 0 {
+		return ""
+	}
+	header := fmt.Sprintf("max-age=%d", int64(c.HSTSMaxAge.Seconds()))
+	if c.HSTSIncludeSubdomains {
+		header += "; includeSubDomains"
+	}
+	if c.HSTSPreload {
+		header += "; preload"
+	}
+	return header
+}
@@ -0,0 +1,384 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate checks that required settings are present, URLs and addresses are well formed,
+// referenced files exist and dependent options are set together. It returns every problem
+// found, joined, so they can all be fixed in one go.
+func (c Config) Validate() error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	// Required
+	if c.DBURL == "" {
+		add("DB_URL is required")
+	} else if strings.Contains(c.DBURL, "://") {
+		if u, err := url.Parse(c.DBURL); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			add("DB_URL must be a postgres:// URL or a key=value connection string")
+		}
+	}
+	if !c.ServerDisabled {
+		if _, _, err := net.SplitHostPort(c.ServerAddr); err != nil {
+			add("SERVER_ADDR %q is not a valid host:port: %v", c.ServerAddr, err)
+		}
+		for name, path := range map[string]string{"CERT_FILE": c.CertFile, "KEY_FILE": c.KeyFile} {
+			if _, err := os.Stat(path); err != nil {
+				add("%s %q cannot be read: %v", name, path, err)
+			}
+		}
+		if c.FrontendDir != "" {
+			if info, err := os.Stat(filepath.Join(c.FrontendDir, "index.html")); err != nil || info.IsDir() {
+				add("FRONTEND_DIR %q has no index.html", c.FrontendDir)
+			}
+		}
+		if c.BasePath != "" && (!strings.HasPrefix(c.BasePath, "/") || strings.ContainsAny(c.BasePath, "?#") || path.Clean(c.BasePath) != c.BasePath) {
+			add("BASE_PATH %q must be a clean absolute path such as /econ", c.BasePath)
+		}
+		if _, err := c.TrustedProxyPrefixes(); err != nil {
+			errs = append(errs, err)
+		}
+		if _, err := c.TLSConfig(); err != nil {
+			errs = append(errs, err)
+		}
+		if c.HSTSMaxAge < 0 {
+			add("HSTS_MAX_AGE must not be negative (0 disables it)")
+		}
+		if c.HSTSPreload && (!c.HSTSIncludeSubdomains || c.HSTSMaxAge < 365*24*time.Hour) {
+			add("HSTS_PRELOAD requires HSTS_INCLUDE_SUBDOMAINS and an HSTS_MAX_AGE of at least 8760h")
+		}
+	}
+
+	// URLs (optional ones are only checked when set)
+	checkURL := func(name, value string, required bool) {
+		if value == "" {
+			if required {
+				add("%s is required", name)
+			}
+			return
+		}
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("%s %q must be an absolute http(s) URL", name, value)
+		}
+	}
+	checkURL("FX_API_BASE_URL", c.FXAPIBaseURL, false)
+	checkURL("FRANKFURTER_BASE_URL", c.FrankfurterBaseURL, false)
+	checkURL("EXCHANGERATE_HOST_BASE_URL", c.ExchangeRateHostBaseURL, false)
+	checkURL("TELEGRAM_API_BASE_URL", c.TelegramAPIBaseURL, false)
+	for _, feed := range c.NewsFeedURLs {
+		checkURL("NEWS_FEED_URLS entry", feed, false)
+	}
+	for _, page := range c.EntitlementsURLs {
+		checkURL("ENTITLEMENTS_URLS entry", page, false)
+	}
+	if !validCountryCode(c.DefaultCountry) {
+		add("DEFAULT_COUNTRY %q must be a 2-letter ISO 3166 country code", c.DefaultCountry)
+	}
+
+	// Data sources; the stock scrapers are only needed when there are stocks to fetch
+	for _, def := range sourceDefaults {
+		sc := c.Source(def.Name)
+		prefix := sc.EnvPrefix()
+		required := sc.Enabled && len(c.StockList) > 0 && (sc.Name == SourceI3Investor || sc.Name == SourceI3InvestorProfile)
+		checkURL(prefix+"BASE_URL", sc.BaseURL, required)
+		if sc.RateLimit < 0 {
+			add("%sRATE_LIMIT must not be negative (0 disables it)", prefix)
+		}
+		if sc.Timeout <= 0 {
+			add("%sTIMEOUT must be positive", prefix)
+		}
+		if sc.Retries < 0 {
+			add("%sRETRIES must not be negative", prefix)
+		}
+	}
+
+	// FX providers and the settings each one needs
+	for i, name := range append([]string{c.FXProvider}, c.FXFallbackProviders...) {
+		setting := "FX_PROVIDER"
+		if i > 0 {
+			setting = "FX_FALLBACK_PROVIDERS"
+		}
+		switch strings.ToLower(name) {
+		case "", "bnm":
+			if c.FXAPIBaseURL == "" {
+				add("%s uses bnm, which requires FX_API_BASE_URL", setting)
+			}
+		case "frankfurter":
+		case "exchangeratehost":
+			if c.ExchangeRateHostAPIKey == "" {
+				add("%s uses exchangeratehost, which requires EXCHANGERATE_HOST_API_KEY", setting)
+			}
+		default:
+			add("%s: unknown FX provider %q (use bnm, frankfurter or exchangeratehost)", setting, name)
+		}
+	}
+	switch c.FXSession {
+	case "0900", "1200", "1700":
+	default:
+		add("FX_SESSION %q must be 0900, 1200 or 1700", c.FXSession)
+	}
+	if len(c.EERWeights) == 0 {
+		add("EER_WEIGHTS has no valid CODE:weight entries")
+	}
+
+	// Analytics
+	if !validSeriesKey(c.BetaBenchmark) {
+		add("BETA_BENCHMARK %q must be a series key like stock:FBMKLCI or fx:USD", c.BetaBenchmark)
+	}
+	if c.BetaWindow < 20 {
+		add("BETA_WINDOW must be at least 20")
+	}
+	for _, series := range c.CorrelationSeries {
+		if !validSeriesKey(series) {
+			add("CORRELATION_SERIES: %q is not a series key like stock:1155 or fx:USD", series)
+		}
+	}
+	if c.CorrelationWindow < 10 {
+		add("CORRELATION_WINDOW must be at least 10")
+	}
+	if c.CorrelationInterval < 0 {
+		add("CORRELATION_INTERVAL must not be negative (0 disables it)")
+	}
+
+	// Snapshots and published datasets (which share the object storage settings)
+	if c.SnapshotBucket != "" || c.PublishBucket != "" {
+		if c.SnapshotEndpoint == "" || strings.Contains(c.SnapshotEndpoint, "://") {
+			add("SNAPSHOT_ENDPOINT %q must be a host[:port] without a scheme", c.SnapshotEndpoint)
+		}
+		if (c.SnapshotAccessKey == "") != (c.SnapshotSecretKey == "") {
+			add("SNAPSHOT_ACCESS_KEY and SNAPSHOT_SECRET_KEY must be set together")
+		}
+	}
+	if c.SnapshotRetentionDays < 0 {
+		add("SNAPSHOT_RETENTION_DAYS must not be negative (0 keeps every snapshot)")
+	}
+	if c.SnapshotInterval < 0 {
+		add("SNAPSHOT_INTERVAL must not be negative (0 disables it)")
+	}
+	if c.PublishInterval < 0 {
+		add("PUBLISH_INTERVAL must not be negative (0 disables it)")
+	}
+
+	if c.DocumentMaxMB < 1 {
+		add("DOCUMENT_MAX_MB must be at least 1")
+	}
+
+	if c.HolidayRefreshInterval < 0 {
+		add("HOLIDAY_REFRESH_INTERVAL must not be negative (0 disables it)")
+	}
+	if c.EntitlementsInterval < 0 {
+		add("ENTITLEMENTS_FETCH_INTERVAL must not be negative (0 disables it)")
+	}
+	if c.MaintenanceInterval < 0 {
+		add("MAINTENANCE_INTERVAL must not be negative (0 disables it)")
+	}
+	if c.AuditRetentionDays < 0 {
+		add("AUDIT_RETENTION_DAYS must not be negative (0 keeps every entry)")
+	}
+	if c.FetchRunRetentionDays < 0 {
+		add("FETCH_RUN_RETENTION_DAYS must not be negative (0 keeps every run)")
+	}
+	if c.PartitionMonthsAhead < 1 {
+		add("PARTITION_MONTHS_AHEAD must be at least 1")
+	}
+	if c.NewsFetchInterval < 0 {
+		add("NEWS_FETCH_INTERVAL must not be negative (0 disables it)")
+	}
+
+	// Google Sheets push
+	if c.GSheetsSpreadsheetID != "" {
+		if c.GSheetsCredentialsFile == "" {
+			add("GSHEETS_SPREADSHEET_ID is set but GSHEETS_CREDENTIALS_FILE is not")
+		}
+		if len(c.GSheetsSeries) == 0 {
+			add("GSHEETS_SPREADSHEET_ID is set but GSHEETS_SERIES is empty")
+		}
+		if c.GSheetsSheet == "" || strings.ContainsAny(c.GSheetsSheet, "!'") {
+			add("GSHEETS_SHEET %q must be a non-empty tab name without ! or '", c.GSheetsSheet)
+		}
+	}
+	for _, series := range c.GSheetsSeries {
+		if !validSeriesKey(series) && !strings.HasPrefix(series, "macro:") {
+			add("GSHEETS_SERIES entry %q must be stock:<code>, fx:<currency> or macro:<series>", series)
+		}
+	}
+	if c.GSheetsLookbackDays < 1 {
+		add("GSHEETS_LOOKBACK_DAYS must be at least 1")
+	}
+	if c.GSheetsInterval < 0 {
+		add("GSHEETS_INTERVAL must not be negative (0 disables it)")
+	}
+
+	// Event bus
+	switch c.EventBus {
+	case "":
+	case "nats", "kafka":
+		if c.EventBusURL == "" {
+			add("EVENT_BUS is %s but EVENT_BUS_URL is not set", c.EventBus)
+		}
+		if c.EventTopicPrefix == "" || strings.ContainsAny(c.EventTopicPrefix, " *>") {
+			add("EVENT_TOPIC_PREFIX %q must be non-empty without spaces or wildcards", c.EventTopicPrefix)
+		}
+	default:
+		add("EVENT_BUS %q must be nats, kafka or empty", c.EventBus)
+	}
+
+	// Auth
+	if c.JWTSecret != "" && len(c.JWTSecret) < 32 {
+		add("JWT_SECRET must be at least 32 characters")
+	}
+	if c.SessionTTL <= 0 {
+		add("SESSION_TTL must be positive")
+	}
+	if c.JWTTTL <= 0 {
+		add("JWT_TTL must be positive")
+	}
+
+	// Notifications
+	if c.SMTPHost != "" {
+		if c.SMTPFrom == "" {
+			add("SMTP_HOST is set but SMTP_FROM is not")
+		}
+		if c.SMTPPort < 1 || c.SMTPPort > 65535 {
+			add("SMTP_PORT %d is out of range", c.SMTPPort)
+		}
+		if (c.SMTPUsername == "") != (c.SMTPPassword == "") {
+			add("SMTP_USERNAME and SMTP_PASSWORD must be set together")
+		}
+	} else {
+		if len(c.NotifyEmailTo) > 0 {
+			add("NOTIFY_EMAIL_TO is set but SMTP_HOST is not")
+		}
+		if len(c.DigestEmailTo) > 0 || c.DigestWatchlistUsers {
+			add("DIGEST_EMAIL_TO or DIGEST_WATCHLIST_USERS is set but SMTP_HOST is not")
+		}
+	}
+	if c.DigestInterval < 0 {
+		add("DIGEST_INTERVAL must not be negative (0 disables it)")
+	}
+	if c.DigestTopMovers < 1 {
+		add("DIGEST_TOP_MOVERS must be at least 1")
+	}
+	if len(c.TelegramNotifyChatIDs) > 0 {
+		if c.TelegramBotToken == "" {
+			add("TELEGRAM_NOTIFY_CHAT_IDS is set but TELEGRAM_BOT_TOKEN is not")
+		}
+		for _, id := range c.TelegramNotifyChatIDs {
+			if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+				add("TELEGRAM_NOTIFY_CHAT_IDS: %q is not a numeric chat ID", id)
+			}
+		}
+	}
+	if c.WebhookMaxAttempts < 1 {
+		add("WEBHOOK_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.WebhookRetryBackoff < 0 {
+		add("WEBHOOK_RETRY_BACKOFF must not be negative")
+	}
+
+	// Scheduling and status
+	if c.ProfileRefreshInterval < 0 {
+		add("PROFILE_REFRESH_INTERVAL must not be negative")
+	}
+	if c.DelistAfterDays < 0 {
+		add("DELIST_AFTER_DAYS must not be negative (0 never marks companies delisted)")
+	}
+	if c.IntradayInterval != 0 && c.IntradayInterval < time.Minute {
+		add("INTRADAY_INTERVAL must be at least 1m (0 disables it)")
+	}
+	if c.IntradayRetentionDays < 0 {
+		add("INTRADAY_RETENTION_DAYS must not be negative (0 keeps every price)")
+	}
+	if c.EERRecalcInterval < 0 {
+		add("EER_RECALC_INTERVAL must not be negative (0 disables it)")
+	}
+	if c.StatusStaleAfter <= 0 {
+		add("STATUS_STALE_AFTER must be positive")
+	}
+	if c.CommandTimeout < 0 {
+		add("COMMAND_TIMEOUT must not be negative (0 disables it)")
+	}
+	if c.ShutdownGracePeriod < 0 {
+		add("SHUTDOWN_GRACE_PERIOD must not be negative (0 cancels running work at once)")
+	}
+	if c.APIMaxRangeDays < 0 {
+		add("API_MAX_RANGE_DAYS must not be negative (0 disables the limit)")
+	}
+	if c.APIMaxSeriesDays < 0 {
+		add("API_MAX_SERIES_DAYS must not be negative (0 disables the limit)")
+	}
+	if c.APIRequestTimeout < 0 || c.APIRequestTimeout >= 10*time.Second {
+		add("API_REQUEST_TIMEOUT must be from 0 (disabled) to under 10s, the server's write timeout")
+	}
+	if c.PublicCacheMaxAge < 0 {
+		add("PUBLIC_CACHE_MAX_AGE must not be negative")
+	}
+	for _, endpoint := range c.PublicEndpoints {
+		if !strings.HasPrefix(endpoint, "/") {
+			add("PUBLIC_ENDPOINTS entry %q must be a route path starting with /", endpoint)
+		}
+	}
+	if c.APICacheTTL < 0 {
+		add("API_CACHE_TTL must not be negative (0 disables the cache)")
+	}
+	if c.APICacheTTL > 0 && c.APICacheMaxEntries < 1 {
+		add("API_CACHE_MAX_ENTRIES must be at least 1")
+	}
+	if c.QueryMaxRows < 0 {
+		add("QUERY_MAX_ROWS must not be negative (0 disables /api/query)")
+	}
+	if c.QueryMaxRows > 0 && c.QueryTimeout <= 0 {
+		add("QUERY_TIMEOUT must be positive")
+	}
+	if c.QueryDBURL != "" && c.QueryDBURL == c.DBURL {
+		add("QUERY_DB_URL must log in as the query_sandbox role, not with DB_URL")
+	}
+	if c.CacheRedisURL != "" {
+		if !strings.HasPrefix(c.CacheRedisURL, "redis://") && !strings.HasPrefix(c.CacheRedisURL, "rediss://") {
+			add("CACHE_REDIS_URL must start with redis:// or rediss://")
+		}
+		if c.CacheKeyPrefix == "" || strings.ContainsAny(c.CacheKeyPrefix, " *") {
+			add("CACHE_KEY_PREFIX %q must be non-empty without spaces or wildcards", c.CacheKeyPrefix)
+		}
+	}
+
+	// Logging
+	if c.LogFile != "" {
+		if info, err := os.Stat(filepath.Dir(c.LogFile)); err != nil || !info.IsDir() {
+			add("LOG_FILE directory %q does not exist", filepath.Dir(c.LogFile))
+		}
+		if c.LogMaxSizeMB < 1 {
+			add("LOG_MAX_SIZE_MB must be at least 1")
+		}
+		if c.LogMaxAgeDays < 0 || c.LogMaxBackups < 0 {
+			add("LOG_MAX_AGE_DAYS and LOG_MAX_BACKUPS must not be negative")
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validSeriesKey reports whether key has the stock:<code> or fx:<currency> shape; codes are
+// checked further where the key is used.
+func validSeriesKey(key string) bool {
+	kind, code, ok := strings.Cut(key, ":")
+	return ok && (kind == "stock" || kind == "fx") && code != ""
+}
+
+// validCountryCode reports whether code is two upper-case letters, like MY or SG.
+func validCountryCode(code string) bool {
+	return len(code) == 2 && code[0] >= 'A' && code[0] <= 'Z' && code[1] >= 'A' && code[1] <= 'Z'
+}
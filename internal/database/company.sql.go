@@ -8,11 +8,36 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+const createCompany = `-- name: CreateCompany :execrows
+INSERT INTO companies (stock_code, company_name, country_code)
+VALUES ($1, $2, $3)
+ON CONFLICT (stock_code) DO NOTHING
+`
+
+type CreateCompanyParams struct {
+	StockCode   string
+	CompanyName string
+	CountryCode sql.NullString
+}
+
+// Registers a company that has no scraped profile, such as one listed in another country
+// whose prices arrive through ingestion.
+func (q *Queries) CreateCompany(ctx context.Context, arg CreateCompanyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createCompany, arg.StockCode, arg.CompanyName, arg.CountryCode)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getCompanyByStockCode = `-- name: GetCompanyByStockCode :one
 
-SELECT stock_code, company_name, country_code, sector, subsector, listing_date, profile_source_url, profile_last_scraped_at, created_at, updated_at FROM companies
+SELECT stock_code, company_name, country_code, sector, subsector, listing_date, profile_source_url, profile_last_scraped_at, created_at, updated_at, website, par_value, shares_outstanding, sector_raw, subsector_raw, status, delisted_date, former_names, missing_since, missing_count, short_name FROM companies
 WHERE stock_code = $1
 `
 
@@ -32,10 +57,298 @@ func (q *Queries) GetCompanyByStockCode(ctx context.Context, stockCode string) (
 		&i.ProfileLastScrapedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Website,
+		&i.ParValue,
+		&i.SharesOutstanding,
+		&i.SectorRaw,
+		&i.SubsectorRaw,
+		&i.Status,
+		&i.DelistedDate,
+		pq.Array(&i.FormerNames),
+		&i.MissingSince,
+		&i.MissingCount,
+		&i.ShortName,
 	)
 	return i, err
 }
 
+const getLatestProfileScrapedAt = `-- name: GetLatestProfileScrapedAt :one
+SELECT profile_last_scraped_at FROM companies
+WHERE profile_last_scraped_at IS NOT NULL
+ORDER BY profile_last_scraped_at DESC
+LIMIT 1
+`
+
+// Time of the most recent company profile scrape.
+func (q *Queries) GetLatestProfileScrapedAt(ctx context.Context) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, getLatestProfileScrapedAt)
+	var profile_last_scraped_at sql.NullTime
+	err := row.Scan(&profile_last_scraped_at)
+	return profile_last_scraped_at, err
+}
+
+const listCompanies = `-- name: ListCompanies :many
+SELECT stock_code, company_name, country_code, sector, subsector, listing_date, profile_source_url, profile_last_scraped_at, created_at, updated_at, website, par_value, shares_outstanding, sector_raw, subsector_raw, status, delisted_date, former_names, missing_since, missing_count, short_name FROM companies
+WHERE $1::text IS NULL OR country_code = $1
+ORDER BY stock_code
+`
+
+func (q *Queries) ListCompanies(ctx context.Context, countryCode sql.NullString) ([]Company, error) {
+	rows, err := q.db.QueryContext(ctx, listCompanies, countryCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Company
+	for rows.Next() {
+		var i Company
+		if err := rows.Scan(
+			&i.StockCode,
+			&i.CompanyName,
+			&i.CountryCode,
+			&i.Sector,
+			&i.Subsector,
+			&i.ListingDate,
+			&i.ProfileSourceUrl,
+			&i.ProfileLastScrapedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Website,
+			&i.ParValue,
+			&i.SharesOutstanding,
+			&i.SectorRaw,
+			&i.SubsectorRaw,
+			&i.Status,
+			&i.DelistedDate,
+			pq.Array(&i.FormerNames),
+			&i.MissingSince,
+			&i.MissingCount,
+			&i.ShortName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCompaniesByStockCodes = `-- name: ListCompaniesByStockCodes :many
+SELECT stock_code, company_name, country_code, sector, subsector, listing_date, profile_source_url, profile_last_scraped_at, created_at, updated_at, website, par_value, shares_outstanding, sector_raw, subsector_raw, status, delisted_date, former_names, missing_since, missing_count, short_name FROM companies
+WHERE stock_code = ANY($1::text[])
+ORDER BY stock_code
+`
+
+func (q *Queries) ListCompaniesByStockCodes(ctx context.Context, stockCodes []string) ([]Company, error) {
+	rows, err := q.db.QueryContext(ctx, listCompaniesByStockCodes, pq.Array(stockCodes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Company
+	for rows.Next() {
+		var i Company
+		if err := rows.Scan(
+			&i.StockCode,
+			&i.CompanyName,
+			&i.CountryCode,
+			&i.Sector,
+			&i.Subsector,
+			&i.ListingDate,
+			&i.ProfileSourceUrl,
+			&i.ProfileLastScrapedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Website,
+			&i.ParValue,
+			&i.SharesOutstanding,
+			&i.SectorRaw,
+			&i.SubsectorRaw,
+			&i.Status,
+			&i.DelistedDate,
+			pq.Array(&i.FormerNames),
+			&i.MissingSince,
+			&i.MissingCount,
+			&i.ShortName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCompanyLifecycles = `-- name: ListCompanyLifecycles :many
+SELECT stock_code, company_name, status, delisted_date, former_names, missing_since, missing_count
+FROM companies
+WHERE status <> 'active' OR cardinality(former_names) > 0
+ORDER BY status, stock_code
+`
+
+type ListCompanyLifecyclesRow struct {
+	StockCode    string
+	CompanyName  string
+	Status       string
+	DelistedDate sql.NullTime
+	FormerNames  []string
+	MissingSince sql.NullTime
+	MissingCount int32
+}
+
+// Companies that are missing, delisted or have been renamed.
+func (q *Queries) ListCompanyLifecycles(ctx context.Context) ([]ListCompanyLifecyclesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCompanyLifecycles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCompanyLifecyclesRow
+	for rows.Next() {
+		var i ListCompanyLifecyclesRow
+		if err := rows.Scan(
+			&i.StockCode,
+			&i.CompanyName,
+			&i.Status,
+			&i.DelistedDate,
+			pq.Array(&i.FormerNames),
+			&i.MissingSince,
+			&i.MissingCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDelistedStockCodes = `-- name: ListDelistedStockCodes :many
+SELECT stock_code FROM companies
+WHERE status = 'delisted'
+ORDER BY stock_code
+`
+
+func (q *Queries) ListDelistedStockCodes(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listDelistedStockCodes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var stock_code string
+		if err := rows.Scan(&stock_code); err != nil {
+			return nil, err
+		}
+		items = append(items, stock_code)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordStockPageFound = `-- name: RecordStockPageFound :execrows
+UPDATE companies SET status = 'active', missing_since = NULL, missing_count = 0, updated_at = NOW()
+WHERE stock_code = $1 AND status = 'missing'
+`
+
+// Returns a company marked missing to active once its stock page is found again.
+func (q *Queries) RecordStockPageFound(ctx context.Context, stockCode string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, recordStockPageFound, stockCode)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const recordStockPageMissing = `-- name: RecordStockPageMissing :one
+UPDATE companies SET
+    missing_count = missing_count + 1,
+    missing_since = COALESCE(missing_since, $1::date),
+    status = CASE
+        WHEN $2::int > 0
+            AND $1::date - COALESCE(missing_since, $1::date) >= $2::int
+        THEN 'delisted'
+        ELSE 'missing'
+    END,
+    delisted_date = CASE
+        WHEN $2::int > 0
+            AND $1::date - COALESCE(missing_since, $1::date) >= $2::int
+        THEN COALESCE(missing_since, $1::date)
+        ELSE NULL
+    END,
+    updated_at = NOW()
+WHERE stock_code = $3 AND status <> 'delisted'
+RETURNING status, missing_since, missing_count
+`
+
+type RecordStockPageMissingParams struct {
+	FetchDate       time.Time
+	DelistAfterDays int32
+	StockCode       string
+}
+
+type RecordStockPageMissingRow struct {
+	Status       string
+	MissingSince sql.NullTime
+	MissingCount int32
+}
+
+// Counts a fetch that found a company's stock page missing. The company is marked missing, or
+// delisted as of the first missing fetch once the page has been missing for delist_after_days
+// (0 never delists). Companies already delisted are left alone.
+func (q *Queries) RecordStockPageMissing(ctx context.Context, arg RecordStockPageMissingParams) (RecordStockPageMissingRow, error) {
+	row := q.db.QueryRowContext(ctx, recordStockPageMissing, arg.FetchDate, arg.DelistAfterDays, arg.StockCode)
+	var i RecordStockPageMissingRow
+	err := row.Scan(&i.Status, &i.MissingSince, &i.MissingCount)
+	return i, err
+}
+
+const setCompanyStatus = `-- name: SetCompanyStatus :execrows
+UPDATE companies SET
+    status = $1,
+    delisted_date = $2,
+    missing_since = NULL,
+    missing_count = 0,
+    updated_at = NOW()
+WHERE stock_code = $3
+`
+
+type SetCompanyStatusParams struct {
+	Status       string
+	DelistedDate sql.NullTime
+	StockCode    string
+}
+
+// Sets a company's status by hand, clearing the missing-page count.
+func (q *Queries) SetCompanyStatus(ctx context.Context, arg SetCompanyStatusParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setCompanyStatus, arg.Status, arg.DelistedDate, arg.StockCode)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const upsertCompany = `-- name: UpsertCompany :exec
 INSERT INTO companies (
     stock_code,
@@ -45,6 +358,12 @@ INSERT INTO companies (
     subsector,
     listing_date,            -- Make sure your Go code can pass NULL for this if not available
     profile_source_url,      -- Make sure your Go code can pass NULL
+    website,                 -- Will be string or NULL from Go
+    par_value,               -- Will be numeric or NULL from Go
+    shares_outstanding,      -- Will be int64 or NULL from Go
+    sector_raw,
+    subsector_raw,
+    short_name,
     profile_last_scraped_at, -- This will be set by the query
     created_at,              -- Handled by DB default on INSERT
     updated_at               -- Handled by DB default on INSERT or trigger on UPDATE
@@ -56,29 +375,53 @@ INSERT INTO companies (
     $5,             -- Will be string or NULL from Go
     $6,          -- Will be time.Time or NULL from Go
     $7,    -- Will be string or NULL from Go
+    $8,               -- Will be string or NULL from Go
+    $9,             -- Will be numeric or NULL from Go
+    $10,    -- Will be int64 or NULL from Go
+    $11,            -- As scraped; sector and subsector are the canonical labels
+    $12,
+    $13,
     NOW(),                           -- Set profile_last_scraped_at to current time
     DEFAULT,                         -- Use default for created_at on new insert
     DEFAULT                          -- Use default for updated_at on new insert
 )
 ON CONFLICT (stock_code) DO UPDATE SET
     company_name = EXCLUDED.company_name,
+    former_names = CASE                 -- Keep the name a renamed company was listed under
+        WHEN companies.company_name <> EXCLUDED.company_name AND companies.company_name <> ''
+            AND NOT companies.company_name = ANY(companies.former_names)
+        THEN array_append(companies.former_names, companies.company_name::text)
+        ELSE companies.former_names
+    END,
     country_code = EXCLUDED.country_code,
     sector = EXCLUDED.sector,
     subsector = EXCLUDED.subsector,
     listing_date = EXCLUDED.listing_date,
     profile_source_url = EXCLUDED.profile_source_url,
+    website = EXCLUDED.website,
+    par_value = EXCLUDED.par_value,
+    shares_outstanding = EXCLUDED.shares_outstanding,
+    sector_raw = EXCLUDED.sector_raw,
+    subsector_raw = EXCLUDED.subsector_raw,
+    short_name = COALESCE(EXCLUDED.short_name, companies.short_name),
     profile_last_scraped_at = NOW(), -- Update this timestamp on conflict
     updated_at = NOW()
 `
 
 type UpsertCompanyParams struct {
-	StockCode        string
-	CompanyName      string
-	CountryCode      sql.NullString
-	Sector           sql.NullString
-	Subsector        sql.NullString
-	ListingDate      sql.NullTime
-	ProfileSourceUrl sql.NullString
+	StockCode         string
+	CompanyName       string
+	CountryCode       sql.NullString
+	Sector            sql.NullString
+	Subsector         sql.NullString
+	ListingDate       sql.NullTime
+	ProfileSourceUrl  sql.NullString
+	Website           sql.NullString
+	ParValue          sql.NullString
+	SharesOutstanding sql.NullInt64
+	SectorRaw         sql.NullString
+	SubsectorRaw      sql.NullString
+	ShortName         sql.NullString
 }
 
 // Inserts a new company profile or updates an existing one based on stock_code.
@@ -91,6 +434,12 @@ func (q *Queries) UpsertCompany(ctx context.Context, arg UpsertCompanyParams) er
 		arg.Subsector,
 		arg.ListingDate,
 		arg.ProfileSourceUrl,
+		arg.Website,
+		arg.ParValue,
+		arg.SharesOutstanding,
+		arg.SectorRaw,
+		arg.SubsectorRaw,
+		arg.ShortName,
 	)
 	return err
 }
@@ -7,20 +7,65 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const deleteForeignExchange = `-- name: DeleteForeignExchange :exec
+DELETE FROM foreign_exchange WHERE id = $1
+`
+
+func (q *Queries) DeleteForeignExchange(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteForeignExchange, id)
+	return err
+}
+
+const deleteForeignExchangeRange = `-- name: DeleteForeignExchangeRange :execrows
+DELETE FROM foreign_exchange
+WHERE
+    upper(btrim(currency_code)) = $1
+    AND date >= $2
+    AND date <= $3
+    AND session = ANY($4::text[])
+`
+
+type DeleteForeignExchangeRangeParams struct {
+	CurrencyCode string
+	StartDate    time.Time
+	EndDate      time.Time
+	Sessions     []string
+}
+
+// Removes a currency's rates of the given sessions within a range (used by repair before re-fetching).
+func (q *Queries) DeleteForeignExchangeRange(ctx context.Context, arg DeleteForeignExchangeRangeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteForeignExchangeRange,
+		arg.CurrencyCode,
+		arg.StartDate,
+		arg.EndDate,
+		pq.Array(arg.Sessions),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getForeignExchangeByCurrencyAndDateRange = `-- name: GetForeignExchangeByCurrencyAndDateRange :many
 SELECT
     date,
-    middle_rate -- Adjust if you want other rates
+    middle_rate, -- Adjust if you want other rates
+    unit,
+    middle_rate_per_unit,
+    source
 FROM foreign_exchange
 WHERE
     currency_code = $1 -- Explicitly name currency_code
     AND date >= $2        -- Explicitly name start_date
     AND date <= $3          -- Explicitly name end_date
+    AND session = $4         -- BNM session, '1200' unless intraday rates are requested
 ORDER BY
     date ASC
 `
@@ -29,15 +74,24 @@ type GetForeignExchangeByCurrencyAndDateRangeParams struct {
 	CurrencyCode string
 	StartDate    time.Time
 	EndDate      time.Time
+	Session      string
 }
 
 type GetForeignExchangeByCurrencyAndDateRangeRow struct {
-	Date       time.Time
-	MiddleRate string
+	Date              time.Time
+	MiddleRate        string
+	Unit              int32
+	MiddleRatePerUnit string
+	Source            string
 }
 
 func (q *Queries) GetForeignExchangeByCurrencyAndDateRange(ctx context.Context, arg GetForeignExchangeByCurrencyAndDateRangeParams) ([]GetForeignExchangeByCurrencyAndDateRangeRow, error) {
-	rows, err := q.db.QueryContext(ctx, getForeignExchangeByCurrencyAndDateRange, arg.CurrencyCode, arg.StartDate, arg.EndDate)
+	rows, err := q.db.QueryContext(ctx, getForeignExchangeByCurrencyAndDateRange,
+		arg.CurrencyCode,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Session,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +99,355 @@ func (q *Queries) GetForeignExchangeByCurrencyAndDateRange(ctx context.Context,
 	var items []GetForeignExchangeByCurrencyAndDateRangeRow
 	for rows.Next() {
 		var i GetForeignExchangeByCurrencyAndDateRangeRow
-		if err := rows.Scan(&i.Date, &i.MiddleRate); err != nil {
+		if err := rows.Scan(
+			&i.Date,
+			&i.MiddleRate,
+			&i.Unit,
+			&i.MiddleRatePerUnit,
+			&i.Source,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getForeignExchangeObservationOnOrBefore = `-- name: GetForeignExchangeObservationOnOrBefore :one
+SELECT id, currency_code, buying_rate, selling_rate, middle_rate, created_at, date, unit, middle_rate_per_unit, source, session, fetch_run_id, spread_per_unit, spread_pct FROM foreign_exchange
+WHERE
+    currency_code = $1
+    AND date <= $2
+    AND session = $3
+ORDER BY
+    date DESC
+LIMIT 1
+`
+
+type GetForeignExchangeObservationOnOrBeforeParams struct {
+	CurrencyCode string
+	OnDate       time.Time
+	Session      string
+}
+
+// The full stored row of the most recent rate for a currency and session on or before a date.
+func (q *Queries) GetForeignExchangeObservationOnOrBefore(ctx context.Context, arg GetForeignExchangeObservationOnOrBeforeParams) (ForeignExchange, error) {
+	row := q.db.QueryRowContext(ctx, getForeignExchangeObservationOnOrBefore, arg.CurrencyCode, arg.OnDate, arg.Session)
+	var i ForeignExchange
+	err := row.Scan(
+		&i.ID,
+		&i.CurrencyCode,
+		&i.BuyingRate,
+		&i.SellingRate,
+		&i.MiddleRate,
+		&i.CreatedAt,
+		&i.Date,
+		&i.Unit,
+		&i.MiddleRatePerUnit,
+		&i.Source,
+		&i.Session,
+		&i.FetchRunID,
+		&i.SpreadPerUnit,
+		&i.SpreadPct,
+	)
+	return i, err
+}
+
+const getFxSpreadsByCurrencyAndDateRange = `-- name: GetFxSpreadsByCurrencyAndDateRange :many
+SELECT
+    date,
+    buying_rate,
+    selling_rate,
+    unit,
+    spread_per_unit,
+    spread_pct
+FROM foreign_exchange
+WHERE
+    currency_code = $1
+    AND session = $2
+    AND date >= $3
+    AND date <= $4
+    AND source = 'bnm'
+ORDER BY
+    date ASC
+`
+
+type GetFxSpreadsByCurrencyAndDateRangeParams struct {
+	CurrencyCode string
+	Session      string
+	StartDate    time.Time
+	EndDate      time.Time
+}
+
+type GetFxSpreadsByCurrencyAndDateRangeRow struct {
+	Date          time.Time
+	BuyingRate    string
+	SellingRate   string
+	Unit          int32
+	SpreadPerUnit sql.NullString
+	SpreadPct     sql.NullString
+}
+
+// BNM buying and selling rates of a currency with their spread, per date. Rates from other
+// providers carry a single value and are left out.
+func (q *Queries) GetFxSpreadsByCurrencyAndDateRange(ctx context.Context, arg GetFxSpreadsByCurrencyAndDateRangeParams) ([]GetFxSpreadsByCurrencyAndDateRangeRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFxSpreadsByCurrencyAndDateRange,
+		arg.CurrencyCode,
+		arg.Session,
+		arg.StartDate,
+		arg.EndDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFxSpreadsByCurrencyAndDateRangeRow
+	for rows.Next() {
+		var i GetFxSpreadsByCurrencyAndDateRangeRow
+		if err := rows.Scan(
+			&i.Date,
+			&i.BuyingRate,
+			&i.SellingRate,
+			&i.Unit,
+			&i.SpreadPerUnit,
+			&i.SpreadPct,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLatestForeignExchangeDate = `-- name: GetLatestForeignExchangeDate :one
+SELECT date FROM foreign_exchange
+ORDER BY date DESC
+LIMIT 1
+`
+
+// Most recent date with any stored FX rate.
+func (q *Queries) GetLatestForeignExchangeDate(ctx context.Context) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getLatestForeignExchangeDate)
+	var date time.Time
+	err := row.Scan(&date)
+	return date, err
+}
+
+const getLatestForeignExchangeOnOrBefore = `-- name: GetLatestForeignExchangeOnOrBefore :one
+SELECT date, middle_rate, unit, middle_rate_per_unit
+FROM foreign_exchange
+WHERE
+    currency_code = $1
+    AND date <= $2
+    AND session = $3
+ORDER BY
+    date DESC
+LIMIT 1
+`
+
+type GetLatestForeignExchangeOnOrBeforeParams struct {
+	CurrencyCode string
+	OnDate       time.Time
+	Session      string
+}
+
+type GetLatestForeignExchangeOnOrBeforeRow struct {
+	Date              time.Time
+	MiddleRate        string
+	Unit              int32
+	MiddleRatePerUnit string
+}
+
+// Returns the most recent stored rate for a currency on or before the given date.
+func (q *Queries) GetLatestForeignExchangeOnOrBefore(ctx context.Context, arg GetLatestForeignExchangeOnOrBeforeParams) (GetLatestForeignExchangeOnOrBeforeRow, error) {
+	row := q.db.QueryRowContext(ctx, getLatestForeignExchangeOnOrBefore, arg.CurrencyCode, arg.OnDate, arg.Session)
+	var i GetLatestForeignExchangeOnOrBeforeRow
+	err := row.Scan(
+		&i.Date,
+		&i.MiddleRate,
+		&i.Unit,
+		&i.MiddleRatePerUnit,
+	)
+	return i, err
+}
+
+const listForeignExchangeCurrencies = `-- name: ListForeignExchangeCurrencies :many
+SELECT DISTINCT currency_code FROM foreign_exchange
+ORDER BY currency_code
+`
+
+// Currencies with at least one stored rate.
+func (q *Queries) ListForeignExchangeCurrencies(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listForeignExchangeCurrencies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var currency_code string
+		if err := rows.Scan(&currency_code); err != nil {
+			return nil, err
+		}
+		items = append(items, currency_code)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listForeignExchangeDates = `-- name: ListForeignExchangeDates :many
+SELECT date
+FROM foreign_exchange
+WHERE
+    currency_code = $1
+    AND date >= $2
+    AND date <= $3
+    AND session = $4
+ORDER BY
+    date ASC
+`
+
+type ListForeignExchangeDatesParams struct {
+	CurrencyCode string
+	StartDate    time.Time
+	EndDate      time.Time
+	Session      string
+}
+
+// Lists the dates that already have a stored rate for a currency within a range (used for gap-filling).
+func (q *Queries) ListForeignExchangeDates(ctx context.Context, arg ListForeignExchangeDatesParams) ([]time.Time, error) {
+	rows, err := q.db.QueryContext(ctx, listForeignExchangeDates,
+		arg.CurrencyCode,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Session,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []time.Time
+	for rows.Next() {
+		var date time.Time
+		if err := rows.Scan(&date); err != nil {
+			return nil, err
+		}
+		items = append(items, date)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listForeignExchangeDuplicates = `-- name: ListForeignExchangeDuplicates :many
+SELECT id, currency_code, date, session, source, middle_rate, created_at
+FROM foreign_exchange
+WHERE (upper(btrim(currency_code)), date, session) IN (
+    SELECT upper(btrim(currency_code)), date, session
+    FROM foreign_exchange
+    GROUP BY upper(btrim(currency_code)), date, session
+    HAVING count(*) > 1
+)
+ORDER BY upper(btrim(currency_code)), date, session,
+    (source = 'bnm') DESC, (currency_code = upper(btrim(currency_code))) DESC, created_at DESC
+`
+
+type ListForeignExchangeDuplicatesRow struct {
+	ID           uuid.UUID
+	CurrencyCode string
+	Date         time.Time
+	Session      string
+	Source       string
+	MiddleRate   string
+	CreatedAt    time.Time
+}
+
+// Rows sharing a currency (ignoring case and surrounding spaces), date and session with
+// another row. Within each group the row to keep comes first: BNM over third-party sources,
+// then a canonical (upper-case) code, then the most recently fetched.
+func (q *Queries) ListForeignExchangeDuplicates(ctx context.Context) ([]ListForeignExchangeDuplicatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listForeignExchangeDuplicates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListForeignExchangeDuplicatesRow
+	for rows.Next() {
+		var i ListForeignExchangeDuplicatesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CurrencyCode,
+			&i.Date,
+			&i.Session,
+			&i.Source,
+			&i.MiddleRate,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listForeignExchangeRatesOnOrBefore = `-- name: ListForeignExchangeRatesOnOrBefore :many
+SELECT DISTINCT ON (currency_code) currency_code, date, middle_rate_per_unit
+FROM foreign_exchange
+WHERE
+    date <= $1
+    AND session = $2
+ORDER BY currency_code, date DESC
+`
+
+type ListForeignExchangeRatesOnOrBeforeParams struct {
+	OnDate  time.Time
+	Session string
+}
+
+type ListForeignExchangeRatesOnOrBeforeRow struct {
+	CurrencyCode      string
+	Date              time.Time
+	MiddleRatePerUnit string
+}
+
+// The most recent rate on or before a date of every currency for a session.
+func (q *Queries) ListForeignExchangeRatesOnOrBefore(ctx context.Context, arg ListForeignExchangeRatesOnOrBeforeParams) ([]ListForeignExchangeRatesOnOrBeforeRow, error) {
+	rows, err := q.db.QueryContext(ctx, listForeignExchangeRatesOnOrBefore, arg.OnDate, arg.Session)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListForeignExchangeRatesOnOrBeforeRow
+	for rows.Next() {
+		var i ListForeignExchangeRatesOnOrBeforeRow
+		if err := rows.Scan(&i.CurrencyCode, &i.Date, &i.MiddleRatePerUnit); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -59,19 +461,77 @@ func (q *Queries) GetForeignExchangeByCurrencyAndDateRange(ctx context.Context,
 	return items, nil
 }
 
+const listLatestForeignExchangeRates = `-- name: ListLatestForeignExchangeRates :many
+SELECT DISTINCT ON (currency_code) currency_code, date, middle_rate_per_unit
+FROM foreign_exchange
+WHERE session = $1
+ORDER BY currency_code, date DESC
+`
+
+type ListLatestForeignExchangeRatesRow struct {
+	CurrencyCode      string
+	Date              time.Time
+	MiddleRatePerUnit string
+}
+
+// The most recent rate of every currency for a session.
+func (q *Queries) ListLatestForeignExchangeRates(ctx context.Context, session string) ([]ListLatestForeignExchangeRatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLatestForeignExchangeRates, session)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLatestForeignExchangeRatesRow
+	for rows.Next() {
+		var i ListLatestForeignExchangeRatesRow
+		if err := rows.Scan(&i.CurrencyCode, &i.Date, &i.MiddleRatePerUnit); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setForeignExchangeCurrencyCode = `-- name: SetForeignExchangeCurrencyCode :exec
+UPDATE foreign_exchange SET currency_code = $1 WHERE id = $2
+`
+
+type SetForeignExchangeCurrencyCodeParams struct {
+	CurrencyCode string
+	ID           uuid.UUID
+}
+
+func (q *Queries) SetForeignExchangeCurrencyCode(ctx context.Context, arg SetForeignExchangeCurrencyCodeParams) error {
+	_, err := q.db.ExecContext(ctx, setForeignExchangeCurrencyCode, arg.CurrencyCode, arg.ID)
+	return err
+}
+
 const upsertForeignExchange = `-- name: UpsertForeignExchange :exec
 INSERT INTO foreign_exchange (
-    id, currency_code, buying_rate, selling_rate, middle_rate, created_at, date
+    id, currency_code, buying_rate, selling_rate, middle_rate, unit, source, created_at, date, session, fetch_run_id
 ) VALUES (
     -- Name all parameters explicitly
     $1, $2, $3,
-    $4, $5, $6, $7
+    $4, $5, $6, $7, $8, $9,
+    $10, $11
 )
-ON CONFLICT (currency_code, date) DO UPDATE SET
+ON CONFLICT (currency_code, date, session) DO UPDATE SET
     buying_rate = EXCLUDED.buying_rate,
     selling_rate = EXCLUDED.selling_rate,
     middle_rate = EXCLUDED.middle_rate,
-    created_at = EXCLUDED.created_at
+    unit = EXCLUDED.unit,
+    source = EXCLUDED.source,
+    created_at = EXCLUDED.created_at,
+    fetch_run_id = EXCLUDED.fetch_run_id
+WHERE
+    foreign_exchange.source = EXCLUDED.source
+    OR EXCLUDED.source = 'bnm'
 `
 
 type UpsertForeignExchangeParams struct {
@@ -80,10 +540,15 @@ type UpsertForeignExchangeParams struct {
 	BuyingRate   string
 	SellingRate  string
 	MiddleRate   string
+	Unit         int32
+	Source       string
 	CreatedAt    time.Time
 	Date         time.Time
+	Session      string
+	FetchRunID   uuid.NullUUID
 }
 
+// A third-party rate never overwrites a BNM rate; BNM (or the same source) always may.
 func (q *Queries) UpsertForeignExchange(ctx context.Context, arg UpsertForeignExchangeParams) error {
 	_, err := q.db.ExecContext(ctx, upsertForeignExchange,
 		arg.ID,
@@ -91,8 +556,12 @@ func (q *Queries) UpsertForeignExchange(ctx context.Context, arg UpsertForeignEx
 		arg.BuyingRate,
 		arg.SellingRate,
 		arg.MiddleRate,
+		arg.Unit,
+		arg.Source,
 		arg.CreatedAt,
 		arg.Date,
+		arg.Session,
+		arg.FetchRunID,
 	)
 	return err
 }
@@ -11,6 +11,128 @@ import (
 	"github.com/google/uuid"
 )
 
+// Triggered alerts, with the rule as it was when it fired.
+type AlertEvent struct {
+	ID            uuid.UUID
+	RuleID        uuid.UUID
+	UserID        uuid.UUID
+	Series        string
+	Condition     string
+	Threshold     string
+	ObservedValue string
+	ObservedDate  time.Time
+	TriggeredAt   time.Time
+	Change        string
+}
+
+// Threshold alerts on stored series, owned by a user.
+type AlertRule struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	// Series key: stock:<code> (closing price), fx:<currency> (MYR per unit) or macro:<series>.
+	Series    string
+	Condition string
+	Threshold string
+	// True while the condition holds; the rule fires again only after it has cleared.
+	IsTriggered     bool
+	LastTriggeredAt sql.NullTime
+	CreatedAt       time.Time
+	// Value compared: level (as stored), or yoy or mom percent change of a macro series.
+	Change string
+}
+
+// Macro events shown as markers on charts.
+type Annotation struct {
+	ID        int32
+	EventDate time.Time
+	// Kind of event: opr, budget, election or other.
+	Category    string
+	Title       string
+	Description string
+	// Where the entry came from: seed or the username that added it.
+	Source    string
+	CreatedAt time.Time
+	// ISO 3166 country the event belongs to.
+	CountryCode string
+}
+
+// Announcements that matched an announcement alert rule.
+type AnnouncementAlertEvent struct {
+	ID        uuid.UUID
+	RuleID    uuid.UUID
+	UserID    uuid.UUID
+	StockCode string
+	// Category the announcement was classified as (never any).
+	Category    string
+	Title       string
+	Link        string
+	AnnouncedAt time.Time
+	TriggeredAt time.Time
+}
+
+// Alerts on new company announcements of a category, owned by a user.
+type AnnouncementAlertRule struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	// any, results, dividend, corporate_action (bonus, rights, split) or merger.
+	Category string
+	// Stock the rule watches; NULL for every stock on the owner's watchlist.
+	StockCode sql.NullString
+	CreatedAt time.Time
+}
+
+// API keys for non-interactive clients; only a SHA-256 hash of the key is stored.
+type ApiKey struct {
+	ID      uuid.UUID
+	UserID  uuid.UUID
+	Name    string
+	KeyHash string
+	// Access role granted to requests using this key.
+	Role       string
+	CreatedAt  time.Time
+	LastUsedAt sql.NullTime
+}
+
+// Authenticated user actions, queryable by admins. Partitioned by month.
+type AuditLog struct {
+	ID     int64
+	UserID uuid.NullUUID
+	// Username at the time of the action; kept after the user is deleted.
+	Username string
+	// CLI command name or equivalent (e.g. login, users:delete, fx:fetch_all).
+	Action     string
+	Details    string
+	Source     string
+	RemoteAddr string
+	CreatedAt  time.Time
+}
+
+// User-defined weighted baskets of stocks, tracked as a composite index.
+type Basket struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Name   string
+	// The index starts at base_value on the first date every component has a price on or after this.
+	BaseDate  time.Time
+	BaseValue string
+	CreatedAt time.Time
+}
+
+// Stocks in a basket with their weights (normalized to sum to 1 when computed).
+type BasketComponent struct {
+	BasketID  uuid.UUID
+	StockCode string
+	Weight    string
+}
+
+// Daily composite index values per basket, recomputed after each fetch.
+type BasketValue struct {
+	BasketID   uuid.UUID
+	Date       time.Time
+	Value      string
+	ComputedAt time.Time
+}
+
 // Stores profile information for companies listed on stock exchanges.
 type Company struct {
 	// The unique stock code/ticker symbol (e.g., "1155" for Maybank).
@@ -19,9 +141,9 @@ type Company struct {
 	CompanyName string
 	// The country code where the company is primarily listed or operates (e.g., MY).
 	CountryCode sql.NullString
-	// The primary economic sector of the company.
+	// The primary economic sector of the company, normalized to the canonical taxonomy where it is known.
 	Sector sql.NullString
-	// A more specific subsector or industry classification.
+	// A more specific subsector or industry classification, normalized like sector.
 	Subsector sql.NullString
 	// The date the company was listed on the stock exchange.
 	ListingDate sql.NullTime
@@ -33,6 +155,56 @@ type Company struct {
 	CreatedAt time.Time
 	// Timestamp when this company record was last modified.
 	UpdatedAt time.Time
+	// The company website URL as listed on the profile page.
+	Website sql.NullString
+	// The par value per share.
+	ParValue sql.NullString
+	// The number of shares outstanding at the time of the last profile scrape.
+	SharesOutstanding sql.NullInt64
+	// Sector as scraped from the profile page.
+	SectorRaw sql.NullString
+	// Subsector as scraped from the profile page.
+	SubsectorRaw sql.NullString
+	// active, missing (the stock page has disappeared in recent fetches) or delisted.
+	Status string
+	// Date the company was delisted: the first fetch its page was missing, or as set by hand.
+	DelistedDate sql.NullTime
+	// Names the company was listed under before company_name, oldest first.
+	FormerNames []string
+	// Date of the first fetch in the current run of fetches that found the stock page missing.
+	MissingSince sql.NullTime
+	// Consecutive fetches that found the stock page missing.
+	MissingCount int32
+	// Board (short) name the stock trades under, e.g. MAYBANK, when the profile page shows one.
+	ShortName sql.NullString
+}
+
+// Annual and quarterly report files per company and period.
+type CompanyDocument struct {
+	ID         int32
+	StockCode  string
+	FiscalYear int32
+	// annual, or the quarter (q1-q4) of a quarterly report.
+	Period    string
+	Title     string
+	SourceUrl string
+	// Path of the file relative to DOCUMENTS_DIR or the DOCUMENTS_BUCKET prefix.
+	StorageKey string
+	// Hex SHA-256 of the file, also used as its ETag.
+	Sha256       string
+	SizeBytes    int64
+	ContentType  string
+	DownloadedAt time.Time
+}
+
+// Daily percentage returns per series, derived from daily_stock_prices and foreign_exchange.
+type DailyReturn struct {
+	// Series key: stock:<code> or fx:<currency>.
+	Series string
+	Date   time.Time
+	// Percentage change from the previous observation (1.5 = +1.5%).
+	PctReturn  string
+	ComputedAt time.Time
 }
 
 // Stores daily closing stock prices scraped from sources like i3investor.
@@ -48,6 +220,62 @@ type DailyStockPrice struct {
 	SourceUrl sql.NullString
 	// Timestamp indicating when this row was added or last updated.
 	ExtractedAt time.Time
+	// Batch run that last stored the price, if any.
+	FetchRunID uuid.NullUUID
+	// Shares traded on price_date, as shown on the source page; NULL when not captured.
+	Volume sql.NullInt64
+}
+
+// Trade-weighted MYR effective exchange rate indices derived from foreign_exchange.
+type EffectiveExchangeRate struct {
+	// neer for the nominal index; reer once relative price indices are available.
+	IndexType string
+	Date      time.Time
+	// Index value, 100 at the base date. Higher means a stronger ringgit.
+	Value      string
+	ComputedAt time.Time
+}
+
+// Announced corporate entitlements per stock and ex-date.
+type Entitlement struct {
+	ID int32
+	// Stock code as listed on the source page; need not be a stored company.
+	StockCode       string
+	EntitlementType string
+	ExDate          time.Time
+	PaymentDate     sql.NullTime
+	// Entitlement text from the source, e.g. Interim dividend or 1 bonus share for every 4.
+	Description string
+	// Cash amount per share in MYR for dividends, when the source gives one.
+	Amount    sql.NullString
+	SourceUrl string
+	FetchedAt time.Time
+}
+
+// Batch fetch items completed per market date, skipped when the batch is re-run that day.
+type FetchCheckpoint struct {
+	Command string
+	RunDate time.Time
+	// Command-specific item key, e.g. a stock code or "USD 1200 2024-01-01..2024-01-31".
+	Item        string
+	CompletedAt time.Time
+}
+
+// Summary of each batch fetch run.
+type FetchRun struct {
+	ID      uuid.UUID
+	Command string
+	Args    string
+	// running, succeeded, partial (some fetches or stores failed) or failed.
+	Status            string
+	StartedAt         time.Time
+	FinishedAt        sql.NullTime
+	SuccessfulFetches int32
+	FailedFetches     int32
+	SuccessfulStores  int32
+	FailedStores      int32
+	// The first few errors of the run; the full list is in the logs.
+	ErrorSamples []string
 }
 
 type ForeignExchange struct {
@@ -58,6 +286,270 @@ type ForeignExchange struct {
 	MiddleRate   string
 	CreatedAt    time.Time
 	Date         time.Time
+	// Number of foreign currency units the quoted rates apply to (e.g. 100 for JPY).
+	Unit int32
+	// Middle rate normalized to MYR per 1 unit of the foreign currency.
+	MiddleRatePerUnit string
+	// Provider the rate was fetched from (bnm, frankfurter, exchangeratehost).
+	Source string
+	// BNM publication session (0900, 1200, 1700). Third-party daily rates are stored as 1200.
+	Session string
+	// Batch run that last stored the rate, if any.
+	FetchRunID uuid.NullUUID
+	// Selling minus buying rate, in MYR per 1 unit of the foreign currency.
+	SpreadPerUnit sql.NullString
+	// Selling minus buying rate as a percentage of the middle rate.
+	SpreadPct sql.NullString
+}
+
+// Signatures of accepted ingest pushes, kept while their sent_at could still be accepted.
+type IngestSignature struct {
+	Signature  string
+	SourceName string
+	ReceivedAt time.Time
+}
+
+// Registered push ingestion sources, managed by admins.
+type IngestSource struct {
+	Name string
+	// Series the source feeds: stock:<code>, fx:<currency> or macro:<series>.
+	Series         string
+	Secret         string
+	Active         bool
+	CreatedAt      time.Time
+	LastIngestedAt sql.NullTime
+}
+
+// Last prices polled every INTRADAY_INTERVAL during market hours. Not used for the daily closes.
+type IntradayPrice struct {
+	StockCode string
+	// When the price was polled.
+	ObservedAt time.Time
+	// Last traded price shown on the source page at observed_at.
+	Price string
+	// Shares traded so far that day; NULL when not shown.
+	Volume    sql.NullInt64
+	SourceUrl sql.NullString
+}
+
+// Weekdays on which Bursa Malaysia does not trade.
+type MarketHoliday struct {
+	HolidayDate time.Time
+	Name        string
+	// Where the entry came from: seed or bursa.
+	Source    string
+	UpdatedAt time.Time
+}
+
+// Periodic macroeconomic observations, one row per series and period.
+type MacroObservation struct {
+	// Series code, e.g. cpi.
+	Series string
+	// First day of the period the value covers.
+	Period time.Time
+	Value  string
+	// Dataset the value came from, e.g. opendosm:cpi_headline.
+	Source    string
+	FetchedAt time.Time
+}
+
+// Average MYR-per-unit middle rate of each currency, session and month.
+type MonthlyFxRate struct {
+	CurrencyCode string
+	Session      string
+	// First day of the month.
+	Month                time.Time
+	AvgMiddleRatePerUnit string
+	Observations         int32
+	// Last date in the month with a stored rate.
+	LastDate time.Time
+}
+
+// Closing price of each stock on the last stored trading day of each month.
+type MonthlyStockClose struct {
+	StockCode string
+	// First day of the month.
+	Month time.Time
+	// Trading day the closing price is from.
+	LastDate     time.Time
+	ClosingPrice string
+}
+
+// Feed headlines mentioning a tracked company.
+type NewsHeadline struct {
+	ID        int64
+	StockCode string
+	Title     string
+	Link      string
+	// Title of the feed the headline came from.
+	Source string
+	// Publication time from the feed, or the fetch time when the feed has none.
+	PublishedAt time.Time
+	FetchedAt   time.Time
+	// Headline sentiment from -1 (negative) to +1 (positive); 0 when neutral.
+	Sentiment string
+}
+
+// Stock purchase lots per user, valued against daily_stock_prices.
+type PortfolioHolding struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	StockCode string
+	// Number of shares in the lot.
+	Quantity string
+	// Price paid per share in MYR.
+	CostBasis string
+	// Date the lot was bought; it is excluded from valuations before this date.
+	TradeDate time.Time
+	CreatedAt time.Time
+}
+
+// Pairwise correlations of daily_returns, one row per unordered pair and window.
+type ReturnCorrelation struct {
+	// Series key that sorts first; pairs are stored once.
+	SeriesA    string
+	SeriesB    string
+	WindowDays int32
+	// Last date of the window the correlation was computed over.
+	AsOf        time.Time
+	Correlation string
+	// Number of dates on which both series had a return.
+	Observations int32
+	ComputedAt   time.Time
+}
+
+// Rolling volatility per series and window, derived from daily_returns.
+type RollingVolatility struct {
+	// Series key: stock:<code> or fx:<currency>.
+	Series string
+	// Number of daily returns in the trailing window.
+	WindowDays int32
+	Date       time.Time
+	// Annualized standard deviation of daily returns, in percent.
+	Volatility string
+	ComputedAt time.Time
+}
+
+// Chart setups saved by a user: the series plotted and the date range.
+type SavedChart struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Name   string
+	// First date shown; NULL for the lookback before end_date (or today).
+	StartDate sql.NullTime
+	// Last date shown; NULL for today, so the chart stays current.
+	EndDate sql.NullTime
+	// Span shown when start_date is NULL, e.g. 1y; NULL with start_date for the whole history.
+	Lookback sql.NullString
+	// Shared charts can be read by anyone with the ID, without signing in.
+	Shared    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Series plotted on a saved chart, in legend order.
+type SavedChartSeries struct {
+	ChartID  uuid.UUID
+	Position int32
+	// kind:code, e.g. stock:1155, fx:USD or macro:cpi.
+	SeriesKey string
+	// real for CPI-deflated stock and FX series; yoy, mom or 3mma for macro series; empty as stored.
+	Transform string
+}
+
+// Canonical sectors company profiles are normalized to.
+type Sector struct {
+	Name      string
+	CreatedAt time.Time
+}
+
+// Daily traded value per sector. Derived from daily_stock_prices and companies.sector.
+type SectorFlow struct {
+	// Canonical sector, or Unclassified for companies without one.
+	Sector   string
+	FlowDate time.Time
+	// Sum of closing price times volume over the sector's stocks, in the quote currency.
+	TradedValue string
+	// Shares traded across the sector's stocks.
+	Volume int64
+	// Stocks with a captured volume that day.
+	Stocks    int32
+	UpdatedAt time.Time
+}
+
+// Scraped sector and subsector labels merged into a canonical one.
+type SectorMapping struct {
+	Kind string
+	// Scraped label, lower-cased with whitespace collapsed.
+	Label string
+	// Name of the canonical sector or subsector the label stands for.
+	Canonical string
+	CreatedAt time.Time
+}
+
+// Latest close and the highest and lowest closes of the 52 weeks up to it, per stock. Derived from daily_stock_prices.
+type StockRanges52w struct {
+	StockCode string
+	// Date of the latest stored close.
+	PriceDate time.Time
+	// The latest stored close.
+	ClosingPrice string
+	// Highest close of the 52 weeks to price_date.
+	High52w string
+	// Most recent date the high was closed at.
+	HighDate time.Time
+	// Lowest close of the 52 weeks to price_date.
+	Low52w string
+	// Most recent date the low was closed at.
+	LowDate   time.Time
+	UpdatedAt time.Time
+}
+
+// Financial ratios per stock and market date, as shown on the source page.
+type StockRatio struct {
+	StockCode string
+	RatioDate time.Time
+	// Return on equity, in percent.
+	Roe sql.NullString
+	// Net tangible assets per share, in MYR.
+	Nta sql.NullString
+	// Trailing dividend yield, in percent.
+	DividendYield sql.NullString
+	// Price to book ratio.
+	PriceToBook sql.NullString
+	SourceUrl   string
+	// Batch run that last stored the ratios, if any.
+	FetchRunID  uuid.NullUUID
+	ExtractedAt time.Time
+	// Trailing price to earnings ratio; NULL when earnings are negative or not shown.
+	PriceToEarnings sql.NullString
+}
+
+// Canonical subsectors of each sector.
+type Subsector struct {
+	Sector    string
+	Name      string
+	CreatedAt time.Time
+}
+
+// Pending telegram:link requests, at most one per user; removed when the bot receives the token.
+type TelegramLinkToken struct {
+	UserID uuid.UUID
+	// Hex-encoded SHA-256 of the token the user sends to the bot.
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+// Stock codes and currencies fetched in addition to STOCK_LIST.
+type TrackedInstrument struct {
+	// stock (code is a stock code) or fx (code is an ISO currency code).
+	ItemType string
+	Code     string
+	// Username that added the instrument.
+	AddedBy string
+	AddedAt time.Time
+	// ISO 3166 country of the listing (stocks) or of the instance's base currency (fx).
+	CountryCode string
 }
 
 type User struct {
@@ -66,4 +558,39 @@ type User struct {
 	Email          string
 	HashedPassword string
 	CreatedAt      time.Time
+	// Access role: admin, editor or viewer.
+	Role string
+	// Telegram chat that receives this user's alerts (set with telegram:link).
+	TelegramChatID sql.NullInt64
+}
+
+// Active login sessions; rows are removed on logout or after expiry.
+type UserSession struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	// Hex-encoded SHA-256 of the session token handed to the client.
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Stock codes and currencies each user is watching.
+type WatchlistItem struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	// stock (code is a stock code) or fx (code is an ISO currency code).
+	ItemType string
+	Code     string
+	AddedAt  time.Time
+}
+
+// Outbound webhook subscriptions, managed by admins.
+type Webhook struct {
+	ID     uuid.UUID
+	Url    string
+	Secret string
+	// Subscribed events: data.stored, alert.triggered, fetch.failed.
+	EventTypes []string
+	Active     bool
+	CreatedAt  time.Time
 }
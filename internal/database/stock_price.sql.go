@@ -9,10 +9,111 @@ import (
 	"context"
 	"database/sql"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const deleteStockPrice = `-- name: DeleteStockPrice :exec
+DELETE FROM daily_stock_prices WHERE id = $1
+`
+
+func (q *Queries) DeleteStockPrice(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteStockPrice, id)
+	return err
+}
+
+const getLatestStockPriceDate = `-- name: GetLatestStockPriceDate :one
+SELECT price_date FROM daily_stock_prices
+ORDER BY price_date DESC
+LIMIT 1
+`
+
+// Most recent date with any stored stock price.
+func (q *Queries) GetLatestStockPriceDate(ctx context.Context) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getLatestStockPriceDate)
+	var price_date time.Time
+	err := row.Scan(&price_date)
+	return price_date, err
+}
+
+const getLatestStockPriceOnOrBefore = `-- name: GetLatestStockPriceOnOrBefore :one
+SELECT price_date, closing_price
+FROM daily_stock_prices
+WHERE
+    stock_code = $1
+    AND price_date <= $2
+ORDER BY
+    price_date DESC
+LIMIT 1
+`
+
+type GetLatestStockPriceOnOrBeforeParams struct {
+	StockCode string
+	OnDate    time.Time
+}
+
+type GetLatestStockPriceOnOrBeforeRow struct {
+	PriceDate    time.Time
+	ClosingPrice string
+}
+
+// Returns the most recent closing price for a stock on or before the given date.
+func (q *Queries) GetLatestStockPriceOnOrBefore(ctx context.Context, arg GetLatestStockPriceOnOrBeforeParams) (GetLatestStockPriceOnOrBeforeRow, error) {
+	row := q.db.QueryRowContext(ctx, getLatestStockPriceOnOrBefore, arg.StockCode, arg.OnDate)
+	var i GetLatestStockPriceOnOrBeforeRow
+	err := row.Scan(&i.PriceDate, &i.ClosingPrice)
+	return i, err
+}
+
+const getStockClosingPricesByCodeAndDateRange = `-- name: GetStockClosingPricesByCodeAndDateRange :many
+SELECT price_date, closing_price
+FROM daily_stock_prices
+WHERE
+    stock_code = $1
+    AND price_date >= $2
+    AND price_date <= $3
+ORDER BY
+    price_date ASC
+`
+
+type GetStockClosingPricesByCodeAndDateRangeParams struct {
+	StockCode string
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type GetStockClosingPricesByCodeAndDateRangeRow struct {
+	PriceDate    time.Time
+	ClosingPrice string
+}
+
+// Closing prices only, without requiring a companies row (used for valuations and analytics).
+func (q *Queries) GetStockClosingPricesByCodeAndDateRange(ctx context.Context, arg GetStockClosingPricesByCodeAndDateRangeParams) ([]GetStockClosingPricesByCodeAndDateRangeRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStockClosingPricesByCodeAndDateRange, arg.StockCode, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStockClosingPricesByCodeAndDateRangeRow
+	for rows.Next() {
+		var i GetStockClosingPricesByCodeAndDateRangeRow
+		if err := rows.Scan(&i.PriceDate, &i.ClosingPrice); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getStockPrice = `-- name: GetStockPrice :one
-SELECT id, stock_code, price_date, closing_price, source_url, extracted_at FROM daily_stock_prices
+SELECT id, stock_code, price_date, closing_price, source_url, extracted_at, fetch_run_id, volume FROM daily_stock_prices
 WHERE stock_code = $1 AND price_date = $2 -- Use named args here too
 LIMIT 1
 `
@@ -32,6 +133,40 @@ func (q *Queries) GetStockPrice(ctx context.Context, arg GetStockPriceParams) (D
 		&i.ClosingPrice,
 		&i.SourceUrl,
 		&i.ExtractedAt,
+		&i.FetchRunID,
+		&i.Volume,
+	)
+	return i, err
+}
+
+const getStockPriceObservationOnOrBefore = `-- name: GetStockPriceObservationOnOrBefore :one
+SELECT id, stock_code, price_date, closing_price, source_url, extracted_at, fetch_run_id, volume FROM daily_stock_prices
+WHERE
+    stock_code = $1
+    AND price_date <= $2
+ORDER BY
+    price_date DESC
+LIMIT 1
+`
+
+type GetStockPriceObservationOnOrBeforeParams struct {
+	StockCode string
+	OnDate    time.Time
+}
+
+// The full stored row of the most recent price for a stock on or before a date.
+func (q *Queries) GetStockPriceObservationOnOrBefore(ctx context.Context, arg GetStockPriceObservationOnOrBeforeParams) (DailyStockPrice, error) {
+	row := q.db.QueryRowContext(ctx, getStockPriceObservationOnOrBefore, arg.StockCode, arg.OnDate)
+	var i DailyStockPrice
+	err := row.Scan(
+		&i.ID,
+		&i.StockCode,
+		&i.PriceDate,
+		&i.ClosingPrice,
+		&i.SourceUrl,
+		&i.ExtractedAt,
+		&i.FetchRunID,
+		&i.Volume,
 	)
 	return i, err
 }
@@ -95,16 +230,243 @@ func (q *Queries) GetStockPricesWithDetailsByCodeAndDateRange(ctx context.Contex
 	return items, nil
 }
 
+const listLatestStockCloses = `-- name: ListLatestStockCloses :many
+SELECT DISTINCT ON (stock_code) stock_code, price_date, closing_price
+FROM daily_stock_prices
+ORDER BY stock_code, price_date DESC
+`
+
+type ListLatestStockClosesRow struct {
+	StockCode    string
+	PriceDate    time.Time
+	ClosingPrice string
+}
+
+// The most recent closing price of every stock.
+func (q *Queries) ListLatestStockCloses(ctx context.Context) ([]ListLatestStockClosesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLatestStockCloses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLatestStockClosesRow
+	for rows.Next() {
+		var i ListLatestStockClosesRow
+		if err := rows.Scan(&i.StockCode, &i.PriceDate, &i.ClosingPrice); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMisdatedStockPrices = `-- name: ListMisdatedStockPrices :many
+SELECT id, stock_code, price_date, closing_price, source_url, extracted_at, fetch_run_id, volume FROM daily_stock_prices
+WHERE
+    price_date = (extracted_at AT TIME ZONE 'UTC')::date
+    AND (extracted_at AT TIME ZONE 'Asia/Kuala_Lumpur')::date > price_date
+    AND (source_url IS NULL OR source_url NOT LIKE 'ingest:%')
+ORDER BY price_date DESC, stock_code
+`
+
+// Scraped prices filed under the UTC date of their extraction when the market (Kuala Lumpur)
+// date was already the next day, newest first. Pushed (ingest:) prices carry their own dates.
+func (q *Queries) ListMisdatedStockPrices(ctx context.Context) ([]DailyStockPrice, error) {
+	rows, err := q.db.QueryContext(ctx, listMisdatedStockPrices)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DailyStockPrice
+	for rows.Next() {
+		var i DailyStockPrice
+		if err := rows.Scan(
+			&i.ID,
+			&i.StockCode,
+			&i.PriceDate,
+			&i.ClosingPrice,
+			&i.SourceUrl,
+			&i.ExtractedAt,
+			&i.FetchRunID,
+			&i.Volume,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStockClosesOnOrBefore = `-- name: ListStockClosesOnOrBefore :many
+SELECT DISTINCT ON (stock_code) stock_code, price_date, closing_price
+FROM daily_stock_prices
+WHERE
+    stock_code = ANY($1::text[])
+    AND price_date <= $2
+ORDER BY stock_code, price_date DESC
+`
+
+type ListStockClosesOnOrBeforeParams struct {
+	StockCodes []string
+	OnDate     time.Time
+}
+
+type ListStockClosesOnOrBeforeRow struct {
+	StockCode    string
+	PriceDate    time.Time
+	ClosingPrice string
+}
+
+// The most recent closing price on or before a date of each of the given stocks.
+func (q *Queries) ListStockClosesOnOrBefore(ctx context.Context, arg ListStockClosesOnOrBeforeParams) ([]ListStockClosesOnOrBeforeRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStockClosesOnOrBefore, pq.Array(arg.StockCodes), arg.OnDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStockClosesOnOrBeforeRow
+	for rows.Next() {
+		var i ListStockClosesOnOrBeforeRow
+		if err := rows.Scan(&i.StockCode, &i.PriceDate, &i.ClosingPrice); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStockCodesWithPrices = `-- name: ListStockCodesWithPrices :many
+SELECT DISTINCT stock_code FROM daily_stock_prices
+ORDER BY stock_code
+`
+
+// Stock codes with at least one stored price.
+func (q *Queries) ListStockCodesWithPrices(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listStockCodesWithPrices)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var stock_code string
+		if err := rows.Scan(&stock_code); err != nil {
+			return nil, err
+		}
+		items = append(items, stock_code)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStockPriceDuplicates = `-- name: ListStockPriceDuplicates :many
+SELECT id, stock_code, price_date, closing_price, extracted_at
+FROM daily_stock_prices
+WHERE (upper(btrim(stock_code)), price_date) IN (
+    SELECT upper(btrim(stock_code)), price_date
+    FROM daily_stock_prices
+    GROUP BY upper(btrim(stock_code)), price_date
+    HAVING count(*) > 1
+)
+ORDER BY upper(btrim(stock_code)), price_date,
+    (stock_code = upper(btrim(stock_code))) DESC, extracted_at DESC
+`
+
+type ListStockPriceDuplicatesRow struct {
+	ID           int32
+	StockCode    string
+	PriceDate    time.Time
+	ClosingPrice string
+	ExtractedAt  time.Time
+}
+
+// Prices sharing a stock code (ignoring case and surrounding spaces) and date with another
+// row. Within each group the row to keep comes first: the canonical code, then the most
+// recently extracted.
+func (q *Queries) ListStockPriceDuplicates(ctx context.Context) ([]ListStockPriceDuplicatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStockPriceDuplicates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStockPriceDuplicatesRow
+	for rows.Next() {
+		var i ListStockPriceDuplicatesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.StockCode,
+			&i.PriceDate,
+			&i.ClosingPrice,
+			&i.ExtractedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const shiftStockPriceDate = `-- name: ShiftStockPriceDate :execrows
+UPDATE daily_stock_prices d
+SET price_date = d.price_date + 1
+WHERE d.id = $1
+    AND NOT EXISTS (
+        SELECT 1 FROM daily_stock_prices o
+        WHERE o.stock_code = d.stock_code AND o.price_date = d.price_date + 1
+    )
+`
+
+// Moves a price to the next day unless the stock already has a price on that day.
+func (q *Queries) ShiftStockPriceDate(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, shiftStockPriceDate, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const upsertStockPrice = `-- name: UpsertStockPrice :exec
 INSERT INTO daily_stock_prices (
-    stock_code, price_date, closing_price, source_url, extracted_at
+    stock_code, price_date, closing_price, source_url, extracted_at, fetch_run_id, volume
 ) VALUES (
-    $1, $2, $3, $4, CURRENT_TIMESTAMP
+    $1, $2, $3, $4, CURRENT_TIMESTAMP, $5,
+    $6
 )
 ON CONFLICT (stock_code, price_date) DO UPDATE SET
     closing_price = EXCLUDED.closing_price,
     source_url = EXCLUDED.source_url,
-    extracted_at = CURRENT_TIMESTAMP
+    extracted_at = CURRENT_TIMESTAMP,
+    fetch_run_id = EXCLUDED.fetch_run_id,
+    volume = COALESCE(EXCLUDED.volume, daily_stock_prices.volume)
 `
 
 type UpsertStockPriceParams struct {
@@ -112,6 +474,8 @@ type UpsertStockPriceParams struct {
 	PriceDate    time.Time
 	ClosingPrice string
 	SourceUrl    sql.NullString
+	FetchRunID   uuid.NullUUID
+	Volume       sql.NullInt64
 }
 
 func (q *Queries) UpsertStockPrice(ctx context.Context, arg UpsertStockPriceParams) error {
@@ -120,6 +484,8 @@ func (q *Queries) UpsertStockPrice(ctx context.Context, arg UpsertStockPricePara
 		arg.PriceDate,
 		arg.ClosingPrice,
 		arg.SourceUrl,
+		arg.FetchRunID,
+		arg.Volume,
 	)
 	return err
 }
@@ -7,21 +7,34 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
     id,
     username,
     email,
     hashed_password,
-    created_at
+    created_at,
+    role
 ) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING id, username, email, hashed_password, created_at
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, username, email, hashed_password, created_at, role, telegram_chat_id
 `
 
 type CreateUserParams struct {
@@ -30,6 +43,7 @@ type CreateUserParams struct {
 	Email          string
 	HashedPassword string
 	CreatedAt      time.Time
+	Role           string
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -39,7 +53,151 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.Email,
 		arg.HashedPassword,
 		arg.CreatedAt,
+		arg.Role,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.Role,
+		&i.TelegramChatID,
+	)
+	return i, err
+}
+
+const deleteUserByUsername = `-- name: DeleteUserByUsername :execrows
+DELETE FROM users
+WHERE username = $1
+`
+
+func (q *Queries) DeleteUserByUsername(ctx context.Context, username string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUserByUsername, username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, username, email, hashed_password, created_at, role, telegram_chat_id FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.Role,
+		&i.TelegramChatID,
+	)
+	return i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT id, username, email, hashed_password, created_at, role, telegram_chat_id FROM users
+WHERE username = $1
+`
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByUsername, username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.Role,
+		&i.TelegramChatID,
 	)
+	return i, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, username, email, hashed_password, created_at, role, telegram_chat_id FROM users
+ORDER BY username ASC
+`
+
+func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.HashedPassword,
+			&i.CreatedAt,
+			&i.Role,
+			&i.TelegramChatID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockUsers = `-- name: LockUsers :exec
+LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE
+`
+
+// Blocks other writers to users until the transaction ends, so a registration's check for
+// an empty table holds until its insert commits.
+func (q *Queries) LockUsers(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, lockUsers)
+	return err
+}
+
+const setUserTelegramChatID = `-- name: SetUserTelegramChatID :exec
+UPDATE users
+SET telegram_chat_id = $1
+WHERE id = $2
+`
+
+type SetUserTelegramChatIDParams struct {
+	TelegramChatID sql.NullInt64
+	ID             uuid.UUID
+}
+
+func (q *Queries) SetUserTelegramChatID(ctx context.Context, arg SetUserTelegramChatIDParams) error {
+	_, err := q.db.ExecContext(ctx, setUserTelegramChatID, arg.TelegramChatID, arg.ID)
+	return err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $1
+WHERE username = $2
+RETURNING id, username, email, hashed_password, created_at, role, telegram_chat_id
+`
+
+type UpdateUserRoleParams struct {
+	Role     string
+	Username string
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRole, arg.Role, arg.Username)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -47,6 +205,8 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.CreatedAt,
+		&i.Role,
+		&i.TelegramChatID,
 	)
 	return i, err
 }
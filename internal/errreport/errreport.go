@@ -0,0 +1,86 @@
+// Package errreport sends errors and panics to Sentry. Reporting is optional: until Init is
+// called with a DSN every function is a no-op, so callers never need to check.
+package errreport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	sentryhttp "github.com/getsentry/sentry-go/http"
+)
+
+// flushTimeout bounds how long Flush waits for buffered events to be sent.
+const flushTimeout = 5 * time.Second
+
+var enabled bool
+
+// Init configures the Sentry client. It does nothing when dsn is empty.
+func Init(dsn, environment, release string) error {
+	if dsn == "" {
+		return nil
+	}
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:              dsn,
+		Environment:      environment,
+		Release:          release,
+		AttachStacktrace: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialise Sentry: %w", err)
+	}
+	enabled = true
+	return nil
+}
+
+// Enabled reports whether errors are being sent to Sentry.
+func Enabled() bool {
+	return enabled
+}
+
+// Flush waits for buffered events to be sent. Call it before the process exits.
+func Flush() {
+	if enabled {
+		sentry.Flush(flushTimeout)
+	}
+}
+
+// Middleware reports panics in next, along with the request URL, headers and query parameters,
+// then re-panics so the server's own recovery still runs. It also attaches a hub to the request
+// context so CaptureError calls from handlers carry the same request details.
+func Middleware(next http.Handler) http.Handler {
+	if !enabled {
+		return next
+	}
+	return sentryhttp.New(sentryhttp.Options{Repanic: true}).Handle(next)
+}
+
+// CaptureError reports err with the given tags (e.g. stock_code, command). If ctx comes from a
+// request wrapped by Middleware the event also carries the request details.
+func CaptureError(ctx context.Context, err error, tags map[string]string) {
+	if !enabled || err == nil {
+		return
+	}
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTags(tags)
+		hub.CaptureException(err)
+	})
+}
+
+// CapturePanic reports a value recovered from a panic with the given tags.
+func CapturePanic(recovered interface{}, tags map[string]string) {
+	if !enabled || recovered == nil {
+		return
+	}
+	hub := sentry.CurrentHub().Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetTags(tags)
+	})
+	hub.Recover(recovered)
+}
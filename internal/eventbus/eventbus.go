@@ -0,0 +1,89 @@
+// Package eventbus publishes messages to a message bus (NATS or Kafka) so downstream systems
+// can subscribe to data updates instead of polling the API.
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// Publisher sends a message to a topic (a NATS subject or Kafka topic). key identifies the
+// entity the message is about; Kafka uses it for partitioning so updates stay ordered per key.
+type Publisher interface {
+	Publish(ctx context.Context, topic, key string, payload []byte) error
+	Close() error
+}
+
+// New connects to the bus of the given kind ("nats" or "kafka"). url is the NATS server URL,
+// or a comma-separated list of Kafka brokers. It returns nil when kind is empty.
+func New(kind, url string) (Publisher, error) {
+	switch strings.ToLower(kind) {
+	case "":
+		return nil, nil
+	case "nats":
+		conn, err := nats.Connect(url, nats.Name("Malaysia-Econ-DB"), nats.MaxReconnects(-1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to NATS at %s: %w", url, err)
+		}
+		return &natsPublisher{conn: conn}, nil
+	case "kafka":
+		var brokers []string
+		for _, b := range strings.Split(url, ",") {
+			if b = strings.TrimSpace(b); b != "" {
+				brokers = append(brokers, b)
+			}
+		}
+		if len(brokers) == 0 {
+			return nil, fmt.Errorf("no Kafka brokers given")
+		}
+		return &kafkaPublisher{writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.Hash{},
+			BatchTimeout:           100 * time.Millisecond,
+			AllowAutoTopicCreation: true,
+			// Async so a slow or unavailable broker never holds up a fetch; failures are logged
+			Async: true,
+			Completion: func(messages []kafka.Message, err error) {
+				if err != nil {
+					log.Printf("Event bus: failed to publish %d message(s) to Kafka: %v", len(messages), err)
+				}
+			},
+		}}, nil
+	}
+	return nil, fmt.Errorf("unknown event bus %q (use nats or kafka)", kind)
+}
+
+type natsPublisher struct {
+	conn *nats.Conn
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, topic, key string, payload []byte) error {
+	msg := nats.NewMsg(topic)
+	msg.Header.Set("Key", key)
+	msg.Data = payload
+	return p.conn.PublishMsg(msg)
+}
+
+// Close flushes buffered messages before disconnecting.
+func (p *natsPublisher) Close() error {
+	return p.conn.Drain()
+}
+
+type kafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, topic, key string, payload []byte) error {
+	return p.writer.WriteMessages(ctx, kafka.Message{Topic: topic, Key: []byte(key), Value: payload})
+}
+
+// Close flushes buffered messages before disconnecting.
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
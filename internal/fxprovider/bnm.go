@@ -0,0 +1,106 @@
+package fxprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	fxclient "github.com/Ernestlph/Malaysia-Econ-DB/internal/BNMApiClient"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
+)
+
+// BNMProvider adapts the Bank Negara Malaysia API client to the FXProvider interface.
+type BNMProvider struct {
+	client *fxclient.Client
+}
+
+// NewBNM creates a BNM-backed provider using FX_API_BASE_URL.
+func NewBNM(cfg config.Config) *BNMProvider {
+	return &BNMProvider{client: fxclient.New(cfg, cfg.FXAPIBaseURL)}
+}
+
+func (p *BNMProvider) Name() string { return "bnm" }
+
+func (p *BNMProvider) FetchLatestRates(ctx context.Context) ([]Rate, error) {
+	resp, err := p.client.FetchLatestRatesAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rates := make([]Rate, 0, len(resp.Data))
+	for _, d := range resp.Data {
+		date, err := time.Parse("2006-01-02", d.Rate.Date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse date %q for %s: %w", d.Rate.Date, d.CurrencyCode, err)
+		}
+		rates = append(rates, Rate{
+			CurrencyCode: d.CurrencyCode,
+			Unit:         d.Unit,
+			Date:         date,
+			BuyingRate:   d.Rate.BuyingRate,
+			SellingRate:  d.Rate.SellingRate,
+			MiddleRate:   d.Rate.MiddleRate,
+			Source:       p.Name(),
+			Session:      p.client.Session,
+		})
+	}
+	return rates, nil
+}
+
+func (p *BNMProvider) FetchRate(ctx context.Context, currencyCode string, date time.Time) (Rate, error) {
+	resp, err := p.client.FetchTargetCurrencyRates(ctx, currencyCode, date.Format("2006-01-02"))
+	if err != nil {
+		if errors.Is(err, fxclient.ErrNotFound) {
+			return Rate{}, fmt.Errorf("%w: %v", ErrNoData, err)
+		}
+		return Rate{}, err
+	}
+	parsedDate, err := time.Parse("2006-01-02", resp.Data.Rate.Date)
+	if err != nil {
+		return Rate{}, fmt.Errorf("failed to parse date %q: %w", resp.Data.Rate.Date, err)
+	}
+	return Rate{
+		CurrencyCode: currencyCode,
+		Unit:         resp.Data.Unit,
+		Date:         parsedDate,
+		BuyingRate:   resp.Data.Rate.BuyingRate,
+		SellingRate:  resp.Data.Rate.SellingRate,
+		MiddleRate:   resp.Data.Rate.MiddleRate,
+		Source:       p.Name(),
+		Session:      p.client.Session,
+	}, nil
+}
+
+// FetchRange uses BNM's per-month endpoint, issuing one request per calendar month touched by the range.
+func (p *BNMProvider) FetchRange(ctx context.Context, currencyCode string, start, end time.Time) ([]Rate, error) {
+	var rates []Rate
+	for month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); !month.After(end); month = month.AddDate(0, 1, 0) {
+		resp, err := p.client.FetchMonthRates(ctx, currencyCode, month.Year(), int(month.Month()))
+		if err != nil {
+			if errors.Is(err, fxclient.ErrNotFound) {
+				continue // No published rates in this month
+			}
+			return rates, err
+		}
+		for _, r := range resp.Data.Rate {
+			date, err := time.Parse("2006-01-02", r.Date)
+			if err != nil {
+				return rates, fmt.Errorf("failed to parse date %q: %w", r.Date, err)
+			}
+			if date.Before(start) || date.After(end) {
+				continue
+			}
+			rates = append(rates, Rate{
+				CurrencyCode: currencyCode,
+				Unit:         resp.Data.Unit,
+				Date:         date,
+				BuyingRate:   r.BuyingRate,
+				SellingRate:  r.SellingRate,
+				MiddleRate:   r.MiddleRate,
+				Source:       p.Name(),
+				Session:      p.client.Session,
+			})
+		}
+	}
+	return rates, nil
+}
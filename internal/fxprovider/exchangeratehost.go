@@ -0,0 +1,135 @@
+package fxprovider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
+)
+
+// ExchangeRateHostProvider fetches rates from exchangerate.host (requires an access key).
+// Only a mid rate is published, so buying and selling rates are set equal to it.
+type ExchangeRateHostProvider struct {
+	BaseURL    string
+	APIKey     string
+	httpClient *http.Client
+}
+
+type exchangeRateHostError struct {
+	Code int    `json:"code"`
+	Info string `json:"info"`
+}
+
+type exchangeRateHostResponse struct {
+	Success   bool                  `json:"success"`
+	Error     exchangeRateHostError `json:"error"`
+	Timestamp int64                 `json:"timestamp"`
+	Date      string                `json:"date"`
+	Source    string                `json:"source"`
+	Quotes    map[string]float64    `json:"quotes"` // e.g. "USDMYR": 4.70
+}
+
+type exchangeRateHostRangeResponse struct {
+	Success bool                          `json:"success"`
+	Error   exchangeRateHostError         `json:"error"`
+	Quotes  map[string]map[string]float64 `json:"quotes"` // date -> pair -> rate
+}
+
+// NewExchangeRateHost creates an exchangerate.host-backed provider using EXCHANGERATE_HOST_BASE_URL and EXCHANGERATE_HOST_API_KEY.
+func NewExchangeRateHost(cfg config.Config) *ExchangeRateHostProvider {
+	return &ExchangeRateHostProvider{
+		BaseURL:    cfg.ExchangeRateHostBaseURL,
+		APIKey:     cfg.ExchangeRateHostAPIKey,
+		httpClient: newHTTPClient(),
+	}
+}
+
+func (p *ExchangeRateHostProvider) Name() string { return "exchangeratehost" }
+
+func (p *ExchangeRateHostProvider) endpoint(path string, params url.Values) string {
+	params.Set("access_key", p.APIKey)
+	return fmt.Sprintf("%s/%s?%s", p.BaseURL, path, params.Encode())
+}
+
+// FetchLatestRates requests quotes with MYR as the source and inverts them to MYR per unit.
+func (p *ExchangeRateHostProvider) FetchLatestRates(ctx context.Context) ([]Rate, error) {
+	var resp exchangeRateHostResponse
+	if err := getJSON(ctx, p.httpClient, p.endpoint("live", url.Values{"source": {"MYR"}}), &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("exchangerate.host error %d: %s", resp.Error.Code, resp.Error.Info)
+	}
+	date := time.Unix(resp.Timestamp, 0).UTC().Truncate(24 * time.Hour)
+	rates := make([]Rate, 0, len(resp.Quotes))
+	for pair, perMYR := range resp.Quotes {
+		code := strings.TrimPrefix(pair, "MYR")
+		if perMYR == 0 || code == "" || code == "MYR" {
+			continue
+		}
+		rates = append(rates, p.rate(code, date, 1/perMYR))
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].CurrencyCode < rates[j].CurrencyCode })
+	return rates, nil
+}
+
+func (p *ExchangeRateHostProvider) FetchRate(ctx context.Context, currencyCode string, date time.Time) (Rate, error) {
+	var resp exchangeRateHostResponse
+	params := url.Values{"date": {date.Format("2006-01-02")}, "source": {currencyCode}, "currencies": {"MYR"}}
+	if err := getJSON(ctx, p.httpClient, p.endpoint("historical", params), &resp); err != nil {
+		return Rate{}, err
+	}
+	if !resp.Success {
+		return Rate{}, fmt.Errorf("exchangerate.host error %d: %s", resp.Error.Code, resp.Error.Info)
+	}
+	myr, ok := resp.Quotes[currencyCode+"MYR"]
+	if !ok {
+		return Rate{}, fmt.Errorf("no %s rate published for %s: %w", currencyCode, date.Format("2006-01-02"), ErrNoData)
+	}
+	return p.rate(currencyCode, date, myr), nil
+}
+
+func (p *ExchangeRateHostProvider) FetchRange(ctx context.Context, currencyCode string, start, end time.Time) ([]Rate, error) {
+	var resp exchangeRateHostRangeResponse
+	params := url.Values{
+		"start_date": {start.Format("2006-01-02")},
+		"end_date":   {end.Format("2006-01-02")},
+		"source":     {currencyCode},
+		"currencies": {"MYR"},
+	}
+	if err := getJSON(ctx, p.httpClient, p.endpoint("timeframe", params), &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("exchangerate.host error %d: %s", resp.Error.Code, resp.Error.Info)
+	}
+	var rates []Rate
+	for dateStr, quotes := range resp.Quotes {
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse date %q: %w", dateStr, err)
+		}
+		if myr, ok := quotes[currencyCode+"MYR"]; ok {
+			rates = append(rates, p.rate(currencyCode, date, myr))
+		}
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Date.Before(rates[j].Date) })
+	return rates, nil
+}
+
+func (p *ExchangeRateHostProvider) rate(currencyCode string, date time.Time, myrPerUnit float64) Rate {
+	return Rate{
+		CurrencyCode: currencyCode,
+		Unit:         1,
+		Date:         date,
+		BuyingRate:   myrPerUnit,
+		SellingRate:  myrPerUnit,
+		MiddleRate:   myrPerUnit,
+		Source:       p.Name(),
+	}
+}
@@ -0,0 +1,69 @@
+package fxprovider
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+// FailoverProvider tries each provider in order, moving to the next only when one is unavailable.
+// ErrNoData is treated as an authoritative answer (e.g. a BNM holiday) and is not failed over,
+// so third-party rates never fill dates the primary source deliberately has no rate for.
+// A cancelled context is not failed over either.
+type FailoverProvider struct {
+	providers []FXProvider
+}
+
+// NewFailover creates a provider that tries providers in the given order.
+func NewFailover(providers ...FXProvider) *FailoverProvider {
+	return &FailoverProvider{providers: providers}
+}
+
+func (f *FailoverProvider) Name() string {
+	names := make([]string, 0, len(f.providers))
+	for _, p := range f.providers {
+		names = append(names, p.Name())
+	}
+	return strings.Join(names, ">")
+}
+
+func (f *FailoverProvider) FetchLatestRates(ctx context.Context) ([]Rate, error) {
+	var lastErr error
+	for _, p := range f.providers {
+		rates, err := p.FetchLatestRates(ctx)
+		if err == nil || ctx.Err() != nil {
+			return rates, err
+		}
+		log.Printf("FX provider %s failed to fetch latest rates, trying next: %v", p.Name(), err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (f *FailoverProvider) FetchRate(ctx context.Context, currencyCode string, date time.Time) (Rate, error) {
+	var lastErr error
+	for _, p := range f.providers {
+		rate, err := p.FetchRate(ctx, currencyCode, date)
+		if err == nil || errors.Is(err, ErrNoData) || ctx.Err() != nil {
+			return rate, err
+		}
+		log.Printf("FX provider %s failed for %s on %s, trying next: %v", p.Name(), currencyCode, date.Format("2006-01-02"), err)
+		lastErr = err
+	}
+	return Rate{}, lastErr
+}
+
+func (f *FailoverProvider) FetchRange(ctx context.Context, currencyCode string, start, end time.Time) ([]Rate, error) {
+	var lastErr error
+	for _, p := range f.providers {
+		rates, err := p.FetchRange(ctx, currencyCode, start, end)
+		if err == nil || errors.Is(err, ErrNoData) || ctx.Err() != nil {
+			return rates, err
+		}
+		log.Printf("FX provider %s failed for %s from %s to %s, trying next: %v", p.Name(), currencyCode, start.Format("2006-01-02"), end.Format("2006-01-02"), err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,107 @@
+package fxprovider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
+)
+
+// FrankfurterProvider fetches ECB reference rates from the Frankfurter API (https://frankfurter.dev).
+// Only a middle rate is published, so buying and selling rates are set equal to it.
+type FrankfurterProvider struct {
+	BaseURL    string
+	httpClient *http.Client
+}
+
+type frankfurterResponse struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+type frankfurterRangeResponse struct {
+	Base  string                        `json:"base"`
+	Rates map[string]map[string]float64 `json:"rates"` // date -> currency -> rate
+}
+
+// NewFrankfurter creates a Frankfurter-backed provider using FRANKFURTER_BASE_URL.
+func NewFrankfurter(cfg config.Config) *FrankfurterProvider {
+	return &FrankfurterProvider{BaseURL: cfg.FrankfurterBaseURL, httpClient: newHTTPClient()}
+}
+
+func (p *FrankfurterProvider) Name() string { return "frankfurter" }
+
+// FetchLatestRates requests rates with MYR as the base and inverts them to MYR per unit.
+func (p *FrankfurterProvider) FetchLatestRates(ctx context.Context) ([]Rate, error) {
+	var resp frankfurterResponse
+	if err := getJSON(ctx, p.httpClient, fmt.Sprintf("%s/latest?from=MYR", p.BaseURL), &resp); err != nil {
+		return nil, err
+	}
+	date, err := time.Parse("2006-01-02", resp.Date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse date %q: %w", resp.Date, err)
+	}
+	rates := make([]Rate, 0, len(resp.Rates))
+	for code, perMYR := range resp.Rates {
+		if perMYR == 0 {
+			continue
+		}
+		rates = append(rates, p.rate(code, date, 1/perMYR))
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].CurrencyCode < rates[j].CurrencyCode })
+	return rates, nil
+}
+
+// FetchRate returns ErrNoData when Frankfurter answers with a different (earlier) date, which it does for non-trading days.
+func (p *FrankfurterProvider) FetchRate(ctx context.Context, currencyCode string, date time.Time) (Rate, error) {
+	var resp frankfurterResponse
+	url := fmt.Sprintf("%s/%s?from=%s&to=MYR", p.BaseURL, date.Format("2006-01-02"), currencyCode)
+	if err := getJSON(ctx, p.httpClient, url, &resp); err != nil {
+		return Rate{}, err
+	}
+	myr, ok := resp.Rates["MYR"]
+	if !ok || resp.Date != date.Format("2006-01-02") {
+		return Rate{}, fmt.Errorf("no %s rate published for %s: %w", currencyCode, date.Format("2006-01-02"), ErrNoData)
+	}
+	return p.rate(currencyCode, date, myr), nil
+}
+
+func (p *FrankfurterProvider) FetchRange(ctx context.Context, currencyCode string, start, end time.Time) ([]Rate, error) {
+	var resp frankfurterRangeResponse
+	url := fmt.Sprintf("%s/%s..%s?from=%s&to=MYR", p.BaseURL, start.Format("2006-01-02"), end.Format("2006-01-02"), currencyCode)
+	if err := getJSON(ctx, p.httpClient, url, &resp); err != nil {
+		return nil, err
+	}
+	var rates []Rate
+	for dateStr, byCurrency := range resp.Rates {
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse date %q: %w", dateStr, err)
+		}
+		// Frankfurter may include the last trading day before start; keep only the requested range
+		if date.Before(start) || date.After(end) {
+			continue
+		}
+		if myr, ok := byCurrency["MYR"]; ok {
+			rates = append(rates, p.rate(currencyCode, date, myr))
+		}
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Date.Before(rates[j].Date) })
+	return rates, nil
+}
+
+func (p *FrankfurterProvider) rate(currencyCode string, date time.Time, myrPerUnit float64) Rate {
+	return Rate{
+		CurrencyCode: currencyCode,
+		Unit:         1,
+		Date:         date,
+		BuyingRate:   myrPerUnit,
+		SellingRate:  myrPerUnit,
+		MiddleRate:   myrPerUnit,
+		Source:       p.Name(),
+	}
+}
@@ -0,0 +1,42 @@
+package fxprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// newHTTPClient returns the HTTP client shared by the third-party providers.
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+// getJSON performs a GET request and decodes a JSON body into out.
+// A 404 is reported as ErrNoData so callers can tell missing dates from outages.
+func getJSON(ctx context.Context, client *http.Client, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making API request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("API returned 404 Not Found for %s: %w", url, ErrNoData)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API request failed with status code: %d %s", resp.StatusCode, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding API response: %w", err)
+	}
+	return nil
+}
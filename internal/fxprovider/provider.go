@@ -0,0 +1,95 @@
+package fxprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
+)
+
+// ErrNoData is returned by providers when no rate exists for the requested currency/date
+// (weekends, public holidays, or dates outside the provider's history).
+var ErrNoData = errors.New("no FX data for requested date")
+
+// Rate is a single exchange rate observation, quoted in MYR per Unit of CurrencyCode.
+type Rate struct {
+	CurrencyCode string
+	Unit         int
+	Date         time.Time
+	BuyingRate   float64
+	SellingRate  float64
+	MiddleRate   float64
+	Source       string // Name of the provider that produced the rate, stored as provenance
+	Session      string // BNM session (0900, 1200, 1700); empty for providers with a single daily rate
+}
+
+// Sessions lists the BNM publication sessions that can be fetched.
+var Sessions = []string{"0900", "1200", "1700"}
+
+// ValidSession reports whether session is a known BNM publication session.
+func ValidSession(session string) bool {
+	for _, s := range Sessions {
+		if s == session {
+			return true
+		}
+	}
+	return false
+}
+
+// FXProvider is implemented by every FX data source the application can fetch from.
+type FXProvider interface {
+	// Name identifies the provider in logs (e.g. "bnm").
+	Name() string
+	// FetchLatestRates returns the most recent rate for every currency the provider quotes.
+	FetchLatestRates(ctx context.Context) ([]Rate, error)
+	// FetchRate returns the rate for a single currency on a single date.
+	FetchRate(ctx context.Context, currencyCode string, date time.Time) (Rate, error)
+	// FetchRange returns all available rates for a currency between start and end (inclusive),
+	// using the provider's bulk endpoints where it has them. Non-trading days are simply absent.
+	FetchRange(ctx context.Context, currencyCode string, start, end time.Time) ([]Rate, error)
+}
+
+// New returns the provider selected by cfg.FXProvider (BNM by default). When FX_FALLBACK_PROVIDERS
+// is set, the result fails over to those providers in order if the primary is unavailable.
+func New(cfg config.Config) (FXProvider, error) {
+	primary, err := newNamed(cfg, cfg.FXProvider)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.FXFallbackProviders) == 0 {
+		return primary, nil
+	}
+
+	providers := []FXProvider{primary}
+	for _, name := range cfg.FXFallbackProviders {
+		p, err := newNamed(cfg, name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fallback provider: %w", err)
+		}
+		providers = append(providers, p)
+	}
+	return NewFailover(providers...), nil
+}
+
+// newNamed constructs a single provider by name.
+func newNamed(cfg config.Config, name string) (FXProvider, error) {
+	switch strings.ToLower(name) {
+	case "", "bnm":
+		if cfg.FXAPIBaseURL == "" {
+			return nil, fmt.Errorf("FX_API_BASE_URL is not configured")
+		}
+		return NewBNM(cfg), nil
+	case "frankfurter":
+		return NewFrankfurter(cfg), nil
+	case "exchangeratehost":
+		if cfg.ExchangeRateHostAPIKey == "" {
+			return nil, fmt.Errorf("EXCHANGERATE_HOST_API_KEY is not configured")
+		}
+		return NewExchangeRateHost(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown FX provider %q", name)
+	}
+}
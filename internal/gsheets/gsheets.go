@@ -0,0 +1,101 @@
+// Package gsheets writes cell values to Google Sheets through the Sheets API v4, authenticating
+// as a service account. The sheet must be shared with the service account's email.
+package gsheets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/oauth2/jwt"
+)
+
+const sheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+
+// serviceAccountKey holds the fields used from a service account JSON key file.
+type serviceAccountKey struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// Client updates spreadsheets.
+type Client struct {
+	BaseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a client authenticated with the service account key in credentialsFile,
+// for the API at baseURL (https://sheets.googleapis.com).
+func NewClient(ctx context.Context, credentialsFile, baseURL string) (*Client, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading service account key: %w", err)
+	}
+	var key serviceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("error decoding service account key %s: %w", credentialsFile, err)
+	}
+	if key.Type != "service_account" || key.ClientEmail == "" || key.PrivateKey == "" {
+		return nil, fmt.Errorf("%s is not a service account key", credentialsFile)
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	cfg := &jwt.Config{
+		Email:        key.ClientEmail,
+		PrivateKey:   []byte(key.PrivateKey),
+		PrivateKeyID: key.PrivateKeyID,
+		Scopes:       []string{sheetsScope},
+		TokenURL:     key.TokenURI,
+	}
+	return &Client{BaseURL: baseURL, httpClient: cfg.Client(ctx)}, nil
+}
+
+// ReplaceValues clears sheetRange (A1 notation, e.g. "EconDB!A:Z") and writes rows from its
+// top-left cell. Cells are written as given (no formula or date parsing).
+func (c *Client) ReplaceValues(ctx context.Context, spreadsheetID, sheetRange string, rows [][]interface{}) error {
+	base := fmt.Sprintf("%s/v4/spreadsheets/%s/values/%s", c.BaseURL, url.PathEscape(spreadsheetID), url.PathEscape(sheetRange))
+	if err := c.call(ctx, http.MethodPost, base+":clear", struct{}{}); err != nil {
+		return fmt.Errorf("error clearing %s: %w", sheetRange, err)
+	}
+	body := map[string]interface{}{
+		"range":          sheetRange,
+		"majorDimension": "ROWS",
+		"values":         rows,
+	}
+	if err := c.call(ctx, http.MethodPut, base+"?valueInputOption=RAW", body); err != nil {
+		return fmt.Errorf("error writing %s: %w", sheetRange, err)
+	}
+	return nil
+}
+
+func (c *Client) call(ctx context.Context, method, endpoint string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making API request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("API request failed with status %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
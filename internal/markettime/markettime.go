@@ -0,0 +1,108 @@
+// Package markettime pins calendar dates to Malaysian market time (Asia/Kuala_Lumpur, UTC+8).
+//
+// Dates of prices, rates and API parameters are market dates: the calendar day in Kuala Lumpur,
+// represented as midnight UTC of that day, which is also how DATE columns are scanned. Deriving
+// a date from the UTC clock instead files anything stored before 08:00 MYT under the previous day.
+package markettime
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // The zone must resolve on hosts without a tz database
+)
+
+// Location is the market timezone.
+var Location = mustLoadLocation("Asia/Kuala_Lumpur")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("markettime: %v", err))
+	}
+	return loc
+}
+
+// DateOf returns the market date of the instant t.
+func DateOf(t time.Time) time.Time {
+	y, m, d := t.In(Location).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Today returns the current market date.
+func Today() time.Time {
+	return DateOf(time.Now())
+}
+
+// ParseDate parses a YYYY-MM-DD market date. An RFC 3339 timestamp is also accepted and
+// resolves to its market date, so 2024-03-01T20:00:00Z is 2024-03-02.
+func ParseDate(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD)", s)
+	}
+	return DateOf(t), nil
+}
+
+// Bursa Malaysia's trading sessions, as minutes after midnight market time: the morning session
+// from 09:00 to 12:30 and the afternoon session from 14:30 to 17:00, closing auction included.
+var sessions = [][2]int{{9 * 60, 12*60 + 30}, {14*60 + 30, 17 * 60}}
+
+// InSession reports whether the instant t falls within a trading session. It does not consult
+// the calendar; check IsTradingDay as well.
+func InSession(t time.Time) bool {
+	local := t.In(Location)
+	minute := local.Hour()*60 + local.Minute()
+	for _, session := range sessions {
+		if minute >= session[0] && minute < session[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// AfterClose reports whether the market day of the instant t is over: it falls on a weekend or
+// after the afternoon session has closed, so a price read then is the day's close. Like
+// InSession it does not consult the calendar.
+func AfterClose(t time.Time) bool {
+	local := t.In(Location)
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+		return true
+	}
+	return local.Hour()*60+local.Minute() >= sessions[len(sessions)-1][1]
+}
+
+// Calendar tells trading days from weekends and market holidays. A nil Calendar knows no
+// holidays and only closes on weekends.
+type Calendar struct {
+	holidays map[time.Time]bool
+}
+
+// NewCalendar creates a calendar closed on the given market dates.
+func NewCalendar(holidays []time.Time) *Calendar {
+	c := &Calendar{holidays: make(map[time.Time]bool, len(holidays))}
+	for _, d := range holidays {
+		c.holidays[DateOf(d)] = true
+	}
+	return c
+}
+
+// IsTradingDay reports whether the market is open on market date d.
+func (c *Calendar) IsTradingDay(d time.Time) bool {
+	day := DateOf(d)
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return false
+	}
+	return c == nil || !c.holidays[day]
+}
+
+// NextTradingDay returns the first trading day after market date d.
+func (c *Calendar) NextTradingDay(d time.Time) time.Time {
+	next := DateOf(d).AddDate(0, 0, 1)
+	for !c.IsTradingDay(next) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
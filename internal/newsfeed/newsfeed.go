@@ -0,0 +1,151 @@
+// Package newsfeed reads headlines from RSS 2.0 and Atom feeds, such as those published by
+// The Edge and The Star.
+package newsfeed
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Item is one headline of a feed.
+type Item struct {
+	Title     string
+	Link      string
+	Published time.Time // Zero when the feed gives no usable date
+	Source    string    // Title of the feed (e.g. "The Star - Business")
+}
+
+// feed covers both formats: an RSS <rss><channel> document or an Atom <feed>.
+type feed struct {
+	XMLName xml.Name
+	// RSS 2.0
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			Title   string `xml:"title"`
+			Link    string `xml:"link"`
+			GUID    string `xml:"guid"`
+			PubDate string `xml:"pubDate"`
+		} `xml:"item"`
+	} `xml:"channel"`
+	// Atom
+	Title   string `xml:"title"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// dateLayouts are the pubDate formats seen in the wild; RFC 1123 is the RSS standard.
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+}
+
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// maxFeedBytes caps the size of a feed read, so a misbehaving server cannot exhaust memory;
+// a larger feed fails to parse.
+const maxFeedBytes = 10 << 20
+
+// Client downloads feeds.
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient creates a feed client.
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Fetch downloads and parses the feed at url. Items without a title or link are skipped.
+func (c *Client) Fetch(ctx context.Context, url string) ([]Item, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml, text/xml")
+	req.Header.Set("User-Agent", "Malaysia-Econ-DB-News/1.0")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching feed %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed %s returned status code: %d %s", url, resp.StatusCode, resp.Status)
+	}
+	return Parse(io.LimitReader(resp.Body, maxFeedBytes))
+}
+
+// Parse reads an RSS 2.0 or Atom document.
+func Parse(r io.Reader) ([]Item, error) {
+	var f feed
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false // Feeds often carry HTML entities such as &nbsp;
+	decoder.Entity = xml.HTMLEntity
+	if err := decoder.Decode(&f); err != nil {
+		return nil, fmt.Errorf("error parsing feed: %w", err)
+	}
+
+	var items []Item
+	switch f.XMLName.Local {
+	case "rss":
+		source := strings.TrimSpace(f.Channel.Title)
+		for _, it := range f.Channel.Items {
+			link := strings.TrimSpace(it.Link)
+			if link == "" && strings.HasPrefix(it.GUID, "http") {
+				link = strings.TrimSpace(it.GUID)
+			}
+			items = append(items, Item{Title: strings.TrimSpace(it.Title), Link: link, Published: parseDate(it.PubDate), Source: source})
+		}
+	case "feed":
+		source := strings.TrimSpace(f.Title)
+		for _, e := range f.Entries {
+			var link string
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = strings.TrimSpace(l.Href)
+					break
+				}
+			}
+			published := parseDate(e.Published)
+			if published.IsZero() {
+				published = parseDate(e.Updated)
+			}
+			items = append(items, Item{Title: strings.TrimSpace(e.Title), Link: link, Published: published, Source: source})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported feed format <%s>", f.XMLName.Local)
+	}
+
+	valid := items[:0]
+	for _, it := range items {
+		if it.Title != "" && it.Link != "" {
+			valid = append(valid, it)
+		}
+	}
+	return valid, nil
+}
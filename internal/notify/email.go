@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig holds the settings for an SMTP relay.
+type SMTPConfig struct {
+	Host     string
+	Port     int // 465 uses implicit TLS; other ports upgrade with STARTTLS when offered
+	Username string
+	Password string
+	From     string
+}
+
+// EmailNotifier sends plain-text (optionally with an HTML alternative) email through an SMTP relay.
+type EmailNotifier struct {
+	cfg SMTPConfig
+}
+
+// NewEmailNotifier returns a notifier for cfg, or nil if no SMTP host is configured.
+func NewEmailNotifier(cfg SMTPConfig) *EmailNotifier {
+	if cfg.Host == "" {
+		return nil
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &EmailNotifier{cfg: cfg}
+}
+
+// smtpTimeout bounds connecting to the relay and, separately, the whole SMTP session after it,
+// so a stalled server fails the send instead of hanging the alert run.
+const smtpTimeout = 30 * time.Second
+
+// Send delivers msg to every address in to.
+func (n *EmailNotifier) Send(to []string, msg Message) error {
+	if len(to) == 0 {
+		return nil
+	}
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+	body := n.buildMessage(to, msg)
+
+	conn, err := (&net.Dialer{Timeout: smtpTimeout}).Dial("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to set SMTP deadline: %w", err)
+	}
+	tlsConfig := &tls.Config{ServerName: n.cfg.Host}
+	if n.cfg.Port == 465 {
+		// Implicit TLS (SMTPS)
+		conn = tls.Client(conn, tlsConfig)
+	}
+	client, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session with %s: %w", addr, err)
+	}
+	defer client.Close()
+	if n.cfg.Port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("SMTP STARTTLS failed: %w", err)
+			}
+		}
+	}
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(n.cfg.From); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM failed: %w", err)
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("SMTP RCPT TO %s failed: %w", rcpt, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("failed to write email body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email via %s: %w", addr, err)
+	}
+	return client.Quit()
+}
+
+func (n *EmailNotifier) buildMessage(to []string, msg Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", sanitizeHeader(n.cfg.From))
+	fmt.Fprintf(&b, "To: %s\r\n", sanitizeHeader(strings.Join(to, ", ")))
+	fmt.Fprintf(&b, "Subject: %s\r\n", sanitizeHeader(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	if msg.HTML == "" {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		b.WriteString(crlf(msg.Body))
+		b.WriteString("\r\n")
+		return b.Bytes()
+	}
+
+	boundary := multipartBoundary()
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n", boundary, crlf(msg.Body))
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n%s\r\n", boundary, crlf(msg.HTML))
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return b.Bytes()
+}
+
+// crlf converts line endings to the CRLF SMTP requires.
+func crlf(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+}
+
+// multipartBoundary returns a random MIME boundary.
+func multipartBoundary() string {
+	var buf [12]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "econdb-boundary-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return "econdb-" + hex.EncodeToString(buf[:])
+}
@@ -0,0 +1,17 @@
+// Package notify delivers notifications (triggered alerts, batch-run failure summaries)
+// over external channels. Each channel is optional and configured independently.
+package notify
+
+import "strings"
+
+// Message is a channel-independent notification.
+type Message struct {
+	Subject string
+	Body    string // Plain text; used by every channel
+	HTML    string // Optional HTML alternative, sent by email alongside Body
+}
+
+// sanitizeHeader strips line breaks so a value cannot inject extra headers.
+func sanitizeHeader(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTelegramBaseURL is the Telegram Bot API endpoint.
+const DefaultTelegramBaseURL = "https://api.telegram.org"
+
+// TelegramBot talks to the Telegram Bot API: it sends messages and long-polls for incoming ones.
+type TelegramBot struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// TelegramUpdate is an incoming update; only text messages are decoded.
+type TelegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *TelegramMessage `json:"message"`
+}
+
+// TelegramMessage is the subset of a Telegram message the bot uses.
+type TelegramMessage struct {
+	Chat struct {
+		ID int64 `json:"id"`
+	} `json:"chat"`
+	Text string `json:"text"`
+}
+
+type telegramResponse struct {
+	OK          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+// NewTelegramBot returns a bot for token, or nil if no token is configured.
+func NewTelegramBot(token, baseURL string) *TelegramBot {
+	if token == "" {
+		return nil
+	}
+	if baseURL == "" {
+		baseURL = DefaultTelegramBaseURL
+	}
+	return &TelegramBot{
+		token:   token,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		// Must exceed the long-poll timeout used by GetUpdates
+		httpClient: &http.Client{Timeout: 90 * time.Second},
+	}
+}
+
+// SendMessage posts plain text to a chat.
+func (b *TelegramBot) SendMessage(ctx context.Context, chatID int64, text string) error {
+	return b.call(ctx, "sendMessage", map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}, nil)
+}
+
+// Send delivers msg to each chat, returning the first error after trying all of them.
+func (b *TelegramBot) Send(ctx context.Context, chatIDs []int64, msg Message) error {
+	text := msg.Subject
+	if msg.Body != "" {
+		text += "\n\n" + msg.Body
+	}
+	var firstErr error
+	for _, id := range chatIDs {
+		if err := b.SendMessage(ctx, id, text); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// GetUpdates long-polls for updates after offset, waiting up to timeout for one to arrive.
+func (b *TelegramBot) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]TelegramUpdate, error) {
+	var updates []TelegramUpdate
+	err := b.call(ctx, "getUpdates", map[string]interface{}{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// ParseChatIDs converts configured chat ID strings, rejecting any that are not integers.
+func ParseChatIDs(raw []string) ([]int64, error) {
+	ids := make([]int64, 0, len(raw))
+	for _, r := range raw {
+		id, err := strconv.ParseInt(r, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Telegram chat ID %q", r)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// call invokes a Bot API method with a JSON body and decodes its result into out (if non-nil).
+func (b *TelegramBot) call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+	// The token is part of the path, so errors below never include the URL
+	url := fmt.Sprintf("%s/bot%s/%s", b.baseURL, b.token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request", method)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("telegram %s request failed", method)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read telegram %s response: %w", method, err)
+	}
+	var tr telegramResponse
+	if err := json.Unmarshal(respBody, &tr); err != nil {
+		return fmt.Errorf("failed to decode telegram %s response (status %d): %w", method, resp.StatusCode, err)
+	}
+	if !tr.OK {
+		return fmt.Errorf("telegram %s failed: %s", method, tr.Description)
+	}
+	if out != nil {
+		if err := json.Unmarshal(tr.Result, out); err != nil {
+			return fmt.Errorf("failed to decode telegram %s result: %w", method, err)
+		}
+	}
+	return nil
+}
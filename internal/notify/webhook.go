@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Webhook request headers. Receivers verify SignatureHeader by computing
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)) over TimestampHeader and the raw
+// request body, and reject timestamps more than a few minutes old so captured deliveries
+// cannot be replayed.
+const (
+	EventHeader     = "X-Econdb-Event"
+	DeliveryHeader  = "X-Econdb-Delivery"
+	SignatureHeader = "X-Econdb-Signature"
+	TimestampHeader = "X-Econdb-Timestamp" // Unix seconds when the attempt was signed
+)
+
+// WebhookPayload is the JSON body POSTed to webhook endpoints.
+type WebhookPayload struct {
+	Event      string      `json:"event"`
+	DeliveryID string      `json:"delivery_id"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// WebhookSender delivers signed webhook payloads, retrying failed attempts with backoff.
+type WebhookSender struct {
+	httpClient  *http.Client
+	maxAttempts int
+	backoff     time.Duration // Delay before the second attempt; doubled for each further attempt
+}
+
+// NewWebhookSender returns a sender that makes up to maxAttempts delivery attempts.
+func NewWebhookSender(maxAttempts int, backoff time.Duration) *WebhookSender {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &WebhookSender{
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}
+}
+
+// Sign returns the signature header value for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignTimestamped returns the signature header value of a webhook delivery: body signed
+// together with the timestamp it is sent with.
+func SignTimestamped(secret, timestamp string, body []byte) string {
+	return Sign(secret, append([]byte(timestamp+"."), body...))
+}
+
+// Deliver POSTs payload to url. Any 2xx response is success; network errors, 429s and 5xx
+// responses are retried, other statuses fail immediately.
+func (w *WebhookSender) Deliver(ctx context.Context, url, secret string, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	delay := w.backoff
+	var lastErr error
+	for attempt := 1; attempt <= w.maxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		// Each attempt is signed afresh, so retries carry a current timestamp
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		retry, err := w.post(ctx, url, payload, body, timestamp, SignTimestamped(secret, timestamp, body))
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return fmt.Errorf("webhook delivery to %s failed: %w", url, lastErr)
+}
+
+// post makes one delivery attempt and reports whether a failure is worth retrying.
+func (w *WebhookSender) post(ctx context.Context, url string, payload WebhookPayload, body []byte, timestamp, signature string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Malaysia-Econ-DB-Webhook/1.0")
+	req.Header.Set(EventHeader, payload.Event)
+	req.Header.Set(DeliveryHeader, payload.DeliveryID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, signature)
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Drain so the connection can be reused
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("endpoint returned %s", resp.Status)
+}
@@ -0,0 +1,130 @@
+// Package objectstore stores files in S3-compatible storage (AWS S3, MinIO, ...) under a
+// common key prefix, and prunes dated uploads that are past their retention.
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// Options configures a Store.
+type Options struct {
+	Endpoint  string // host[:port], e.g. s3.amazonaws.com or minio.internal:9000
+	Bucket    string
+	Prefix    string // Prepended to every key, without leading or trailing slashes
+	AccessKey string // Credentials come from the environment or instance role when empty
+	SecretKey string
+	Region    string
+	UseSSL    bool
+}
+
+// Store writes objects to one bucket under a prefix.
+type Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// New creates a store; it does not contact the endpoint.
+func New(opts Options) (*Store, error) {
+	creds := credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.EnvAWS{},
+		&credentials.EnvMinio{},
+		&credentials.IAM{},
+	})
+	if opts.AccessKey != "" {
+		creds = credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, "")
+	}
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: opts.UseSSL,
+		Region: opts.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating client for %s: %w", opts.Endpoint, err)
+	}
+	return &Store{client: client, bucket: opts.Bucket, prefix: strings.Trim(opts.Prefix, "/")}, nil
+}
+
+// key joins the store prefix and name.
+func (s *Store) key(name string) string {
+	if s.prefix == "" {
+		return name
+	}
+	return s.prefix + "/" + name
+}
+
+// Put uploads size bytes from r as name (relative to the prefix). A size of -1 streams the
+// body in parts of unknown total length.
+func (s *Store) Put(ctx context.Context, name string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, s.key(name), r, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("error uploading %s to bucket %s: %w", s.key(name), s.bucket, err)
+	}
+	return nil
+}
+
+// PruneDated deletes objects stored as <prefix>/<YYYY-MM-DD>/... whose date is before cutoff.
+// Keys without a date directory are left alone. It returns the number of objects deleted.
+func (s *Store) PruneDated(ctx context.Context, cutoff time.Time) (int, error) {
+	listPrefix := ""
+	if s.prefix != "" {
+		listPrefix = s.prefix + "/"
+	}
+	deleted := 0
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: listPrefix, Recursive: true}) {
+		if obj.Err != nil {
+			return deleted, fmt.Errorf("error listing bucket %s: %w", s.bucket, obj.Err)
+		}
+		dir, _, found := strings.Cut(strings.TrimPrefix(obj.Key, listPrefix), "/")
+		if !found {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", dir)
+		if err != nil || !date.Before(cutoff) {
+			continue
+		}
+		if err := s.client.RemoveObject(ctx, s.bucket, obj.Key, minio.RemoveObjectOptions{}); err != nil {
+			return deleted, fmt.Errorf("error deleting %s: %w", obj.Key, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// Get opens the object stored as name (relative to the prefix). The caller closes the reader.
+func (s *Store) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, s.key(name), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error downloading %s from bucket %s: %w", s.key(name), s.bucket, err)
+	}
+	// GetObject is lazy; Stat surfaces a missing object before the caller starts writing
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, fmt.Errorf("error downloading %s from bucket %s: %w", s.key(name), s.bucket, err)
+	}
+	return obj, nil
+}
+
+// Exists reports whether an object is stored as name (relative to the prefix).
+func (s *Store) Exists(ctx context.Context, name string) (bool, error) {
+	_, err := s.client.StatObject(ctx, s.bucket, s.key(name), minio.StatObjectOptions{})
+	if err == nil {
+		return true, nil
+	}
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return false, nil
+	}
+	return false, fmt.Errorf("error checking %s in bucket %s: %w", s.key(name), s.bucket, err)
+}
+
+// Key returns the full object key name is stored under.
+func (s *Store) Key(name string) string {
+	return s.key(name)
+}
@@ -0,0 +1,85 @@
+// Package opendosm reads macroeconomic datasets (CPI, trade, ...) published by the
+// Department of Statistics Malaysia through the data.gov.my data catalogue API.
+package opendosm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// Observation is one period of a dataset; Date is the first day of the period.
+type Observation struct {
+	Date  time.Time
+	Value float64
+}
+
+// Doer sends HTTP requests; *http.Client and the rate-limited scraper clients satisfy it.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client requests datasets from the data catalogue API.
+type Client struct {
+	BaseURL    string
+	httpClient Doer
+}
+
+// NewClient creates a client for the API at baseURL (e.g. https://api.data.gov.my) that sends
+// its requests through httpClient, or a plain HTTP client when it is nil.
+func NewClient(baseURL string, httpClient Doer) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{BaseURL: baseURL, httpClient: httpClient}
+}
+
+// Fetch returns the valueField column of dataset, restricted by filter (the API's
+// "value@column" syntax, empty for none), sorted oldest first. Rows without a numeric
+// value (e.g. not yet published) are skipped.
+func (c *Client) Fetch(ctx context.Context, dataset, filter, valueField string) ([]Observation, error) {
+	params := url.Values{"id": {dataset}}
+	if filter != "" {
+		params.Set("filter", filter)
+	}
+	reqURL := fmt.Sprintf("%s/data-catalogue?%s", c.BaseURL, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making API request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API request for %s failed with status code: %d %s", dataset, resp.StatusCode, resp.Status)
+	}
+
+	var rows []map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("error decoding API response for %s: %w", dataset, err)
+	}
+
+	observations := make([]Observation, 0, len(rows))
+	for _, row := range rows {
+		dateStr, _ := row["date"].(string)
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected date %q in %s: %w", dateStr, dataset, err)
+		}
+		value, ok := row[valueField].(float64)
+		if !ok {
+			continue
+		}
+		observations = append(observations, Observation{Date: date, Value: value})
+	}
+	sort.Slice(observations, func(i, j int) bool { return observations[i].Date.Before(observations[j].Date) })
+	return observations, nil
+}
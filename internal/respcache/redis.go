@@ -0,0 +1,134 @@
+package respcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is a response cache shared by every instance connected to the same Redis server.
+// Entries are stored under <prefix>:<generation>:<key>, and Invalidate increments the shared
+// generation, so an invalidation by any instance hides every older entry until it expires.
+//
+// While Redis is unreachable the cache falls back to the local in-memory cache, so requests
+// are still cached on this instance. Invalidations made by other instances in that time are
+// not seen, so a local entry may be served for up to the TTL.
+type Redis struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+	local  *Memory
+	down   atomic.Bool // Set while Redis is failing, so the outage is logged once
+}
+
+// NewRedis connects to the Redis server at url (redis://[user:password@]host:port/db) and
+// checks that it answers.
+func NewRedis(ctx context.Context, url, prefix string, ttl time.Duration, local *Memory) (*Redis, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := client.Ping(pingCtx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis at %s: %w", opts.Addr, err)
+	}
+	return &Redis{client: client, prefix: prefix, ttl: ttl, local: local}, nil
+}
+
+// Close closes the connection to Redis.
+func (c *Redis) Close() error {
+	return c.client.Close()
+}
+
+func (c *Redis) generationKey() string {
+	return c.prefix + ":generation"
+}
+
+func (c *Redis) entryKey(generation uint64, key string) string {
+	return c.prefix + ":" + strconv.FormatUint(generation, 10) + ":" + key
+}
+
+// failed records a Redis error, logging only the first of an outage.
+func (c *Redis) failed(err error) {
+	if !c.down.Swap(true) {
+		log.Printf("Cache: Redis unavailable, falling back to the in-memory cache: %v", err)
+	}
+}
+
+// recovered records a successful Redis call, logging the end of an outage.
+func (c *Redis) recovered() {
+	if c.down.Swap(false) {
+		log.Println("Cache: Redis reachable again; using the shared cache.")
+	}
+}
+
+// Get returns the cached response for key, if there is one in the current generation.
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool) {
+	generation, err := c.currentGeneration(ctx)
+	if err != nil {
+		c.failed(err)
+		return c.local.Get(ctx, key)
+	}
+	body, err := c.client.Get(ctx, c.entryKey(generation, key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false
+	}
+	if err != nil {
+		c.failed(err)
+		return c.local.Get(ctx, key)
+	}
+	return body, true
+}
+
+// Generation returns the shared generation, or the local one while Redis is unreachable.
+func (c *Redis) Generation(ctx context.Context) uint64 {
+	generation, err := c.currentGeneration(ctx)
+	if err != nil {
+		c.failed(err)
+		return c.local.Generation(ctx)
+	}
+	return generation
+}
+
+func (c *Redis) currentGeneration(ctx context.Context) (uint64, error) {
+	generation, err := c.client.Get(ctx, c.generationKey()).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil // Never invalidated
+	}
+	if err != nil {
+		return 0, err
+	}
+	c.recovered()
+	return generation, nil
+}
+
+// Set stores body under key in the given generation. If the cache has been invalidated since,
+// the entry is stored under the old generation, where it is never read, and simply expires.
+func (c *Redis) Set(ctx context.Context, key string, generation uint64, body []byte) {
+	if c.down.Load() {
+		c.local.Set(ctx, key, generation, body)
+		return
+	}
+	if err := c.client.Set(ctx, c.entryKey(generation, key), body, c.ttl).Err(); err != nil {
+		c.failed(err)
+	}
+}
+
+// Invalidate increments the shared generation and clears the local fallback cache.
+func (c *Redis) Invalidate(ctx context.Context) {
+	c.local.Invalidate(ctx)
+	if err := c.client.Incr(ctx, c.generationKey()).Err(); err != nil {
+		c.failed(err)
+		return
+	}
+	c.recovered()
+}
@@ -0,0 +1,128 @@
+// Package respcache caches rendered API responses for a short time, so repeated requests for
+// the same historical slice are answered without going back to the database.
+package respcache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Cache stores rendered responses under string keys for a fixed TTL. Invalidate drops every
+// entry, and callers use it whenever stored data changes. A response is stored with the
+// generation taken before it was rendered, so one rendered from data that changed meanwhile
+// is never served. Backend failures are treated as misses; caching is never worth failing a
+// request over.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Generation(ctx context.Context) uint64
+	Set(ctx context.Context, key string, generation uint64, body []byte)
+	Invalidate(ctx context.Context)
+}
+
+// New returns the cache for the configuration: Redis when redisURL is set, so instances behind
+// a load balancer share entries and invalidations, and otherwise an in-memory cache. It
+// returns nil when ttl is not positive. When Redis cannot be reached it returns the in-memory
+// cache along with the error.
+func New(ctx context.Context, redisURL, prefix string, ttl time.Duration, maxEntries int) (Cache, error) {
+	if ttl <= 0 {
+		return nil, nil
+	}
+	local := NewMemory(ttl, maxEntries)
+	if redisURL == "" {
+		return local, nil
+	}
+	c, err := NewRedis(ctx, redisURL, prefix, ttl, local)
+	if err != nil {
+		return local, fmt.Errorf("redis cache unavailable: %w", err)
+	}
+	return c, nil
+}
+
+// Memory is an in-process response cache with a fixed TTL, holding at most maxEntries.
+type Memory struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu         sync.Mutex
+	entries    map[string]entry
+	generation uint64 // Bumped by Invalidate so responses rendered before it are not stored
+}
+
+type entry struct {
+	body    []byte
+	expires time.Time
+}
+
+// NewMemory returns a cache holding up to maxEntries responses for ttl each. It returns nil
+// when ttl is not positive; a nil *Memory is a valid cache that stores nothing.
+func NewMemory(ttl time.Duration, maxEntries int) *Memory {
+	if ttl <= 0 {
+		return nil
+	}
+	return &Memory{ttl: ttl, maxEntries: maxEntries, entries: make(map[string]entry)}
+}
+
+// Get returns the cached response for key, if there is one that has not expired.
+func (c *Memory) Get(ctx context.Context, key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return e.body, true
+}
+
+// Generation returns a token to pass to Set once the response has been rendered.
+func (c *Memory) Generation(ctx context.Context) uint64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// Set stores body under key, unless the cache was invalidated since generation was taken.
+func (c *Memory) Set(ctx context.Context, key string, generation uint64, body []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return // Rendered from data that has since changed
+	}
+	now := time.Now()
+	if c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			return // Full of live entries; they expire within the TTL
+		}
+	}
+	c.entries[key] = entry{body: body, expires: now.Add(c.ttl)}
+}
+
+// Invalidate drops every cached response.
+func (c *Memory) Invalidate(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	clear(c.entries)
+}
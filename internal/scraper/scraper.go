@@ -0,0 +1,139 @@
+// Package scraper sends the HTTP requests of the data-source scrapers, applying each
+// source's politeness settings: a minimum delay between requests, a per-attempt timeout,
+// retries with backoff and the User-Agent header.
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
+)
+
+// Client requests pages from one source. It is safe for concurrent use; the rate limit
+// is shared by every caller of the same Client.
+type Client struct {
+	cfg        config.SourceConfig
+	httpClient *http.Client
+
+	mu   sync.Mutex
+	next time.Time // Earliest start of the next request
+}
+
+// New creates a client for the source described by cfg.
+func New(cfg config.SourceConfig) *Client {
+	return &Client{cfg: cfg, httpClient: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// Set holds one client per configured source.
+type Set map[string]*Client
+
+// NewSet creates a client for every source in sources.
+func NewSet(sources map[string]config.SourceConfig) Set {
+	set := make(Set, len(sources))
+	for name, cfg := range sources {
+		set[name] = New(cfg)
+	}
+	return set
+}
+
+// Source returns the client of the named source. An unknown source gets a disabled client,
+// so requests through it fail instead of going out without any politeness settings.
+func (s Set) Source(name string) *Client {
+	if c, ok := s[name]; ok {
+		return c
+	}
+	return New(config.SourceConfig{Name: name})
+}
+
+// BaseURL is the source's configured base URL.
+func (c *Client) BaseURL() string { return c.cfg.BaseURL }
+
+// Enabled reports whether requests to the source are allowed.
+func (c *Client) Enabled() bool { return c.cfg.Enabled }
+
+// Do sends req once the rate limit allows, retrying network errors, 429 and 5xx responses
+// up to the source's retry count with a doubling delay. The last response is returned
+// whatever its status; the caller closes its body. req must not have a body.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if !c.cfg.Enabled {
+		return nil, fmt.Errorf("source %s is disabled (%sENABLED)", c.cfg.Name, c.cfg.EnvPrefix())
+	}
+	if c.cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", c.cfg.UserAgent)
+	}
+	backoff := max(c.cfg.RateLimit, time.Second)
+	for attempt := 0; ; attempt++ {
+		if err := c.wait(req.Context()); err != nil {
+			return nil, err
+		}
+		resp, err := c.httpClient.Do(req.Clone(req.Context()))
+		retry := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if !retry || attempt >= c.cfg.Retries {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// Get fetches url and returns the response if its status is 200 OK. The caller closes its body.
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", url, err)
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL %s: %w", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &StatusError{StatusCode: resp.StatusCode, URL: url}
+	}
+	return resp, nil
+}
+
+// StatusError is returned by Get for a response whose status is not 200 OK.
+type StatusError struct {
+	StatusCode int
+	URL        string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("received non-200 status code %d from %s", e.StatusCode, e.URL)
+}
+
+// wait blocks until the source's rate limit allows another request to start.
+func (c *Client) wait(ctx context.Context) error {
+	c.mu.Lock()
+	start := time.Now()
+	if c.next.After(start) {
+		start = c.next
+	}
+	c.next = start.Add(c.cfg.RateLimit)
+	c.mu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
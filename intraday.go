@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/scraper"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Structure for one polled intraday price
+type IntradayPoint struct {
+	Time   string  `json:"time"` // RFC 3339 in market time (UTC+8)
+	Price  float64 `json:"price"`
+	Volume *int64  `json:"volume"` // Shares traded so far that day; null when not shown
+}
+
+// loadIntradayPrices returns the prices polled for a stock on a market date, oldest first.
+func loadIntradayPrices(ctx context.Context, s *AppState, code string, date time.Time) ([]IntradayPoint, error) {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, markettime.Location)
+	rows, err := s.db.GetIntradayPricesByCodeAndTimeRange(ctx, database.GetIntradayPricesByCodeAndTimeRangeParams{
+		StockCode: code,
+		StartTime: start,
+		EndTime:   start.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load intraday prices of %s: %w", code, err)
+	}
+	points := make([]IntradayPoint, 0, len(rows))
+	for _, row := range rows {
+		price, err := strconv.ParseFloat(row.Price, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid intraday price of %s at %s: %w", code, row.ObservedAt.Format(time.RFC3339), err)
+		}
+		point := IntradayPoint{Time: row.ObservedAt.In(markettime.Location).Format(time.RFC3339), Price: price}
+		if row.Volume.Valid {
+			volume := row.Volume.Int64
+			point.Volume = &volume
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// pollIntradayPrices stores the current price of every tracked stock that is not delisted,
+// stamped with one poll time so the stocks of a poll line up. The daily closes are left to
+// stock:fetch:price_all. It returns the number stored, and an error only when every stock failed.
+func pollIntradayPrices(ctx context.Context, s *AppState) (int, error) {
+	source := s.sources.Source(config.SourceI3Investor)
+	observedAt := time.Now().UTC().Truncate(time.Minute)
+	stored, failed := 0, 0
+	var lastErr error
+	for _, code := range activeStocks(ctx, s, trackedStocks(ctx, s, s.cfg.DefaultCountry)) {
+		if err := ctx.Err(); err != nil {
+			return stored, err
+		}
+		if err := fetchIntradayPrice(ctx, s, source, code, observedAt); err != nil {
+			log.Printf("Error polling the intraday price of %s: %v", code, err)
+			failed++
+			lastErr = err
+			continue
+		}
+		stored++
+	}
+	if stored > 0 {
+		invalidateResponseCache(s)
+	}
+	if stored == 0 && failed > 0 {
+		return 0, fmt.Errorf("every one of %d intraday polls failed, last: %w", failed, lastErr)
+	}
+	return stored, nil
+}
+
+// fetchIntradayPrice reads the last price and the day's volume so far from a stock's page and
+// stores them as of observedAt.
+func fetchIntradayPrice(ctx context.Context, s *AppState, source *scraper.Client, code string, observedAt time.Time) error {
+	url := source.BaseURL() + code
+	resp, err := source.Get(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML from %s: %w", url, err)
+	}
+	priceStr, found := findLastPrice(doc)
+	if !found {
+		return errStockPageMissing
+	}
+	price, err := strconv.ParseFloat(strings.TrimSpace(priceStr), 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse price string '%s' to float: %w", priceStr, err)
+	}
+	return s.db.InsertIntradayPrice(ctx, database.InsertIntradayPriceParams{
+		StockCode:  code,
+		ObservedAt: observedAt,
+		Price:      fmt.Sprintf("%.4f", price),
+		Volume:     findVolume(doc, code),
+		SourceUrl:  sql.NullString{String: url, Valid: true},
+	})
+}
+
+// runIntradayPoll is the scheduled intraday job: it polls only while a trading session is open,
+// so INTRADAY_INTERVAL ticks outside market hours do nothing.
+func runIntradayPoll(ctx context.Context, s *AppState) error {
+	if !markettime.InSession(time.Now()) {
+		return nil
+	}
+	n, err := pollIntradayPrices(ctx, s)
+	if err == nil {
+		log.Printf("Scheduler: stored %d intraday price(s).", n)
+	}
+	return err
+}
+
+// --- Intraday Command Handlers ---
+
+// handlerStockIntraday lists the intraday prices polled for a stock on a market date (default
+// today).
+// Usage: stock:intraday <stock_code> [YYYY-MM-DD]
+func handlerStockIntraday(s *AppState, cmd command) error {
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		return fmt.Errorf("usage: %s <stock_code> [YYYY-MM-DD]", cmd.Name)
+	}
+	code := normalizeStockCode(cmd.Args[0])
+	date := markettime.Today()
+	if len(cmd.Args) == 2 {
+		var err error
+		if date, err = markettime.ParseDate(cmd.Args[1]); err != nil {
+			return err
+		}
+	}
+	prices, err := loadIntradayPrices(cmd.Context(), s, code, date)
+	if err != nil {
+		return err
+	}
+	if len(prices) == 0 {
+		fmt.Printf("No intraday prices stored for %s on %s.\n", code, date.Format("2006-01-02"))
+		return nil
+	}
+	fmt.Printf("Intraday prices of %s on %s:\n", code, date.Format("2006-01-02"))
+	for _, p := range prices {
+		volume := "-"
+		if p.Volume != nil {
+			volume = strconv.FormatInt(*p.Volume, 10)
+		}
+		fmt.Printf("  %s %12.4f %14s\n", p.Time[11:16], p.Price, volume)
+	}
+	return nil
+}
+
+// handlerStockIntradayPoll polls every tracked stock once now, in or out of market hours, as
+// the INTRADAY_INTERVAL job does during a session (admin only).
+// Usage: stock:intraday:poll
+func handlerStockIntradayPoll(s *AppState, cmd command) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+	if !markettime.InSession(time.Now()) {
+		log.Println("No trading session is open; the prices polled are the last of the previous session.")
+	}
+	n, err := pollIntradayPrices(cmd.Context(), s)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Stored %d intraday price(s).\n", n)
+	return nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/scraper"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Company statuses, as stored in companies.status.
+const (
+	companyActive   = "active"
+	companyMissing  = "missing"
+	companyDelisted = "delisted"
+)
+
+// errStockPageMissing marks a fetch that reached the stock page but found no price on it, as
+// happens once a stock is delisted.
+var errStockPageMissing = errors.New("the page has no price; the stock may have been delisted")
+
+// stockPageMissing reports whether a failed stock page fetch means the page has disappeared,
+// rather than that the site was unreachable or the page could not be parsed.
+func stockPageMissing(err error) bool {
+	var statusErr *scraper.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone
+	}
+	return errors.Is(err, errStockPageMissing)
+}
+
+// stockPageChecks collects which stock pages a batch fetch found and which had disappeared, so
+// the company statuses can be updated once the batch is done.
+type stockPageChecks struct {
+	found, missing []string
+}
+
+// record notes the outcome of fetching a stock's page.
+func (c *stockPageChecks) record(code string, err error) {
+	switch {
+	case err == nil:
+		c.found = append(c.found, code)
+	case stockPageMissing(err):
+		c.missing = append(c.missing, code)
+	}
+}
+
+// apply returns companies whose pages were found to active and counts a missing page against
+// the others, marking them delisted once their pages have been missing for DELIST_AFTER_DAYS.
+// When every page of the batch was missing the site or the scraper is more likely at fault than
+// the companies, so nothing is recorded. It returns the codes newly marked delisted.
+func (c *stockPageChecks) apply(ctx context.Context, s *AppState) []string {
+	if len(c.found) == 0 && len(c.missing) > 1 {
+		log.Printf("Every one of %d stock pages was missing; not recording them as missing (check the source and the scraper).", len(c.missing))
+		return nil
+	}
+	for _, code := range c.found {
+		n, err := s.db.RecordStockPageFound(ctx, code)
+		if err != nil {
+			log.Printf("Error recording the page of %s as found: %v", code, err)
+		} else if n > 0 {
+			log.Printf("The stock page of %s is back; marked active again.", code)
+		}
+	}
+	var delisted []string
+	for _, code := range c.missing {
+		row, err := s.db.RecordStockPageMissing(ctx, database.RecordStockPageMissingParams{
+			FetchDate:       markettime.Today(),
+			DelistAfterDays: int32(s.cfg.DelistAfterDays),
+			StockCode:       code,
+		})
+		if err == sql.ErrNoRows {
+			continue // No stored company, or already delisted
+		}
+		if err != nil {
+			log.Printf("Error recording the page of %s as missing: %v", code, err)
+			continue
+		}
+		if row.Status == companyDelisted {
+			log.Printf("Marked %s delisted: its stock page has been missing since %s.", code, row.MissingSince.Time.Format("2006-01-02"))
+			delisted = append(delisted, code)
+		} else {
+			log.Printf("The stock page of %s is missing (%d fetch(es) since %s).", code, row.MissingCount, row.MissingSince.Time.Format("2006-01-02"))
+		}
+	}
+	if len(c.found)+len(c.missing) > 0 {
+		invalidateResponseCache(s)
+	}
+	return delisted
+}
+
+// activeStocks drops the delisted companies from codes, logging each one skipped. Their stored
+// data is kept; only the fetching stops.
+func activeStocks(ctx context.Context, s *AppState, codes []string) []string {
+	delisted, err := s.db.ListDelistedStockCodes(ctx)
+	if err != nil {
+		log.Printf("Error loading delisted companies, fetching every code: %v", err)
+		return codes
+	}
+	skip := make(map[string]bool, len(delisted))
+	for _, code := range delisted {
+		skip[code] = true
+	}
+	active := make([]string, 0, len(codes))
+	for _, code := range codes {
+		if skip[code] {
+			log.Printf("Skipping %s: delisted (see stock:lifecycle).", code)
+			continue
+		}
+		active = append(active, code)
+	}
+	return active
+}
+
+// handlerStockLifecycle lists the companies that are missing, delisted or have been renamed.
+// Usage: stock:lifecycle
+func handlerStockLifecycle(s *AppState, cmd command) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+	companies, err := s.db.ListCompanyLifecycles(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to list companies: %w", err)
+	}
+	if len(companies) == 0 {
+		fmt.Println("Every company is active and has kept its name.")
+		return nil
+	}
+	for _, c := range companies {
+		detail := ""
+		switch c.Status {
+		case companyDelisted:
+			if c.DelistedDate.Valid {
+				detail = "since " + c.DelistedDate.Time.Format("2006-01-02")
+			}
+		case companyMissing:
+			detail = fmt.Sprintf("page missing since %s (%d fetch(es))", c.MissingSince.Time.Format("2006-01-02"), c.MissingCount)
+		}
+		fmt.Printf("  %-8s %-8s %-40s %s\n", c.StockCode, c.Status, c.CompanyName, detail)
+		if len(c.FormerNames) > 0 {
+			fmt.Printf("           formerly %s\n", strings.Join(c.FormerNames, ", "))
+		}
+	}
+	return nil
+}
+
+// handlerStockLifecycleCheck requests the price page of every tracked stock that is not
+// delisted and records which have disappeared, without storing prices. Companies whose pages
+// stay missing for DELIST_AFTER_DAYS are marked delisted and skipped by the fetch-all commands;
+// stock:fetch:price_all and stock:fetch:profile_all record missing pages the same way (admin only).
+// Usage: stock:lifecycle:check
+func handlerStockLifecycleCheck(s *AppState, cmd command) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+	ctx := cmd.Context()
+	source := s.sources.Source(config.SourceI3Investor)
+	var checks stockPageChecks
+	failed := 0
+	for _, code := range activeStocks(ctx, s, trackedStocks(ctx, s, s.cfg.DefaultCountry)) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := checkStockPage(ctx, source, code)
+		checks.record(code, err)
+		if err != nil && !stockPageMissing(err) {
+			log.Printf("Could not check the stock page of %s: %v", code, err)
+			failed++
+		}
+	}
+	delisted := checks.apply(ctx, s)
+	fmt.Printf("Checked %d stock page(s): %d found, %d missing, %d could not be checked.\n",
+		len(checks.found)+len(checks.missing)+failed, len(checks.found), len(checks.missing), failed)
+	if len(delisted) > 0 {
+		fmt.Printf("Marked delisted: %s\n", strings.Join(delisted, ", "))
+	}
+	return nil
+}
+
+// checkStockPage fetches a stock's price page and checks that it still shows a price.
+func checkStockPage(ctx context.Context, source *scraper.Client, code string) error {
+	resp, err := source.Get(ctx, source.BaseURL()+code)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML of %s: %w", code, err)
+	}
+	if _, ok := findLastPrice(doc); !ok {
+		return errStockPageMissing
+	}
+	return nil
+}
+
+// handlerStockStatus sets a company's status by hand: delisted (as of the given date, default
+// today) to stop fetching it, or active to resume (admin only).
+// Usage: stock:status <stock_code> <active|delisted> [YYYY-MM-DD]
+func handlerStockStatus(s *AppState, cmd command) error {
+	if len(cmd.Args) < 2 || len(cmd.Args) > 3 {
+		return fmt.Errorf("usage: %s <stock_code> <active|delisted> [YYYY-MM-DD]", cmd.Name)
+	}
+	code := normalizeStockCode(cmd.Args[0])
+	status := strings.ToLower(cmd.Args[1])
+	params := database.SetCompanyStatusParams{Status: status, StockCode: code}
+	switch status {
+	case companyActive:
+		if len(cmd.Args) == 3 {
+			return fmt.Errorf("a date can only be given for delisted")
+		}
+	case companyDelisted:
+		date := markettime.Today()
+		if len(cmd.Args) == 3 {
+			var err error
+			if date, err = markettime.ParseDate(cmd.Args[2]); err != nil {
+				return fmt.Errorf("failed to parse delisting date: %w", err)
+			}
+		}
+		params.DelistedDate = sql.NullTime{Time: date, Valid: true}
+	default:
+		return fmt.Errorf("invalid status %q (use active or delisted)", cmd.Args[1])
+	}
+	n, err := s.db.SetCompanyStatus(cmd.Context(), params)
+	if err != nil {
+		return fmt.Errorf("failed to set the status of %s: %w", code, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("company %s not found", code)
+	}
+	invalidateResponseCache(s)
+	log.Printf("Set the status of %s to %s.", code, status)
+	fmt.Printf("Company %s is now %s.\n", code, status)
+	return nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/google/uuid"
+)
+
+// snapshotProbeDays is how many daily snapshots after a value was stored are checked for the
+// first one that contains it.
+const snapshotProbeDays = 7
+
+// Structure for the lineage of one stored observation
+type LineageResponse struct {
+	Series          string           `json:"series"`
+	Date            string           `json:"date"` // Date (or period start) of the observation, on or before the requested date
+	Value           string           `json:"value"`
+	Source          string           `json:"source"` // Provider, scraped page, OpenDOSM dataset or ingest:<source>
+	StoredAt        time.Time        `json:"stored_at"`
+	FetchRun        *LineageFetchRun `json:"fetch_run,omitempty"` // Batch run that stored the value, when it was stored by one
+	Snapshot        *LineageSnapshot `json:"snapshot,omitempty"`  // First table snapshot holding the stored value
+	Transformations []string         `json:"transformations"`     // Steps from the source figure to the served value, in order
+}
+
+// Structure for the batch run an observation was stored by
+type LineageFetchRun struct {
+	ID         uuid.UUID  `json:"id"`
+	Command    string     `json:"command"`
+	Args       string     `json:"args,omitempty"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Structure for the table snapshot an observation was exported in
+type LineageSnapshot struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	Date   string `json:"date"`
+}
+
+// observationLineage traces the observation of series (stock:<code>, fx:<currency> or
+// macro:<series>) in effect on date back to its source, fetch run and snapshot. It returns
+// sql.ErrNoRows when the series has no observation on or before date.
+func observationLineage(ctx context.Context, s *AppState, series string, date time.Time) (LineageResponse, error) {
+	kind, code, _ := strings.Cut(series, ":")
+	lineage := LineageResponse{Series: series}
+	var runID uuid.NullUUID
+	var table string
+	switch kind {
+	case watchlistFx:
+		row, err := s.db.GetForeignExchangeObservationOnOrBefore(ctx, database.GetForeignExchangeObservationOnOrBeforeParams{
+			CurrencyCode: code,
+			OnDate:       date,
+			Session:      "1200", // The session fx:<currency> series are served from
+		})
+		if err != nil {
+			return lineage, err
+		}
+		lineage.Date, lineage.Value = row.Date.Format("2006-01-02"), row.MiddleRatePerUnit
+		lineage.Source, lineage.StoredAt = row.Source, row.CreatedAt
+		runID, table = row.FetchRunID, "foreign_exchange"
+		if name, ok := strings.CutPrefix(row.Source, "ingest:"); ok {
+			lineage.Transformations = append(lineage.Transformations, fmt.Sprintf("Pushed by ingest source %s as MYR per %d %s", name, row.Unit, code))
+		} else {
+			lineage.Transformations = append(lineage.Transformations, fmt.Sprintf("Middle rate %s quoted by %s for the %s session as MYR per %d %s", row.MiddleRate, row.Source, row.Session, row.Unit, code))
+		}
+		if row.Unit != 1 {
+			lineage.Transformations = append(lineage.Transformations, fmt.Sprintf("Divided by the quote unit (%d) to give MYR per 1 %s", row.Unit, code))
+		}
+
+	case watchlistStock:
+		row, err := s.db.GetStockPriceObservationOnOrBefore(ctx, database.GetStockPriceObservationOnOrBeforeParams{
+			StockCode: code,
+			OnDate:    date,
+		})
+		if err != nil {
+			return lineage, err
+		}
+		lineage.Date, lineage.Value = row.PriceDate.Format("2006-01-02"), row.ClosingPrice
+		lineage.Source, lineage.StoredAt = row.SourceUrl.String, row.ExtractedAt
+		runID, table = row.FetchRunID, "daily_stock_prices"
+		if name, ok := strings.CutPrefix(row.SourceUrl.String, "ingest:"); ok {
+			lineage.Transformations = append(lineage.Transformations, fmt.Sprintf("Pushed by ingest source %s for %s", name, lineage.Date))
+		} else {
+			lineage.Transformations = append(lineage.Transformations,
+				"Last Price scraped from the source page and rounded to 4 decimal places",
+				"Filed under the Kuala Lumpur market date of the fetch")
+		}
+
+	case "macro":
+		row, err := s.db.GetMacroObservationOnOrBefore(ctx, database.GetMacroObservationOnOrBeforeParams{
+			Series: code,
+			OnDate: date,
+		})
+		if err != nil {
+			return lineage, err
+		}
+		lineage.Date, lineage.Value = row.Period.Format("2006-01-02"), row.Value
+		lineage.Source, lineage.StoredAt = row.Source, row.FetchedAt
+		table = "macro_observations"
+		if name, ok := strings.CutPrefix(row.Source, "ingest:"); ok {
+			lineage.Transformations = append(lineage.Transformations, fmt.Sprintf("Pushed by ingest source %s for the period starting %s", name, lineage.Date))
+		} else {
+			lineage.Transformations = append(lineage.Transformations, fmt.Sprintf("Read from %s and stored as published for the period starting %s", row.Source, lineage.Date))
+		}
+
+	default:
+		return lineage, fmt.Errorf("unsupported series kind %q", kind)
+	}
+
+	if runID.Valid {
+		run, err := s.db.GetFetchRun(ctx, runID.UUID)
+		switch {
+		case err == nil:
+			lineage.FetchRun = &LineageFetchRun{
+				ID:        run.ID,
+				Command:   run.Command,
+				Args:      run.Args,
+				Status:    run.Status,
+				StartedAt: run.StartedAt,
+			}
+			if run.FinishedAt.Valid {
+				lineage.FetchRun.FinishedAt = &run.FinishedAt.Time
+			}
+		case err != sql.ErrNoRows:
+			return lineage, fmt.Errorf("failed to load fetch run %s: %w", runID.UUID, err)
+		}
+	}
+	lineage.Snapshot = findSnapshot(ctx, s, table, lineage.StoredAt)
+	return lineage, nil
+}
+
+// findSnapshot returns the first daily snapshot of table exported after storedAt, or nil when
+// snapshots are disabled, the table is excluded, or none of the following snapshotProbeDays
+// days has one (yet, or any more after SNAPSHOT_RETENTION_DAYS). Storage errors are logged.
+func findSnapshot(ctx context.Context, s *AppState, table string, storedAt time.Time) *LineageSnapshot {
+	if s.cfg.SnapshotBucket == "" || slices.Contains(s.cfg.SnapshotExcludeTables, table) {
+		return nil
+	}
+	store, err := snapshotStore(s)
+	if err != nil {
+		log.Printf("Lineage: snapshot storage unavailable: %v", err)
+		return nil
+	}
+	// Snapshots are filed under the UTC date of the export and hold what was stored before it
+	day := storedAt.UTC().Truncate(24 * time.Hour)
+	today := time.Now().UTC()
+	if s.cfg.SnapshotRetentionDays > 0 {
+		c := markettime.Today().AddDate(0, 0, -s.cfg.SnapshotRetentionDays)
+		if cutoff := time.Date(c.Year(), c.Month(), c.Day(), 0, 0, 0, 0, time.UTC); day.Before(cutoff) {
+			day = cutoff // Older snapshots have been pruned
+		}
+	}
+	for i := 0; i < snapshotProbeDays && !day.After(today); i, day = i+1, day.AddDate(0, 0, 1) {
+		name := fmt.Sprintf("%s/%s.csv.gz", day.Format("2006-01-02"), table)
+		found, err := store.Exists(ctx, name)
+		if err != nil {
+			log.Printf("Lineage: %v", err)
+			return nil
+		}
+		if found {
+			return &LineageSnapshot{Bucket: s.cfg.SnapshotBucket, Key: store.Key(name), Date: day.Format("2006-01-02")}
+		}
+	}
+	return nil
+}
+
+// --- Lineage Command Handlers ---
+
+// handlerLineage prints the lineage of the observation of a series in effect on a date.
+// Usage: lineage <stock:CODE|fx:CUR|macro:SERIES> [YYYY-MM-DD]  (default: today)
+func handlerLineage(s *AppState, cmd command) error {
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		return fmt.Errorf("usage: %s <stock:CODE|fx:CUR|macro:SERIES> [YYYY-MM-DD]", cmd.Name)
+	}
+	series, err := parseIngestSeries(cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	date := markettime.Today()
+	if len(cmd.Args) == 2 {
+		if date, err = markettime.ParseDate(cmd.Args[1]); err != nil {
+			return fmt.Errorf("invalid date %q (use YYYY-MM-DD)", cmd.Args[1])
+		}
+	}
+	lineage, err := observationLineage(cmd.Context(), s, series, date)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%s has no observation on or before %s", series, date.Format("2006-01-02"))
+	}
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(lineage, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/opendosm"
+)
+
+// macroSource describes where a macro series is read from in the OpenDOSM data catalogue.
+type macroSource struct {
+	Dataset     string // Catalogue dataset ID; empty for series pushed through /api/ingest or derived here
+	Filter      string // "value@column" restriction, empty for none
+	Field       string // Column holding the value
+	Description string
+}
+
+// macroSeries are the macroeconomic series that can be fetched, by series code.
+var macroSeries = map[string]macroSource{
+	"cpi":          {Dataset: "cpi_headline", Filter: "overall@division", Field: "index", Description: "Consumer price index, headline (2010=100), monthly"},
+	"ipi":          {Dataset: "ipi", Filter: "abs@series", Field: "index", Description: "Industrial production index (2015=100), monthly"},
+	"exports":      {Dataset: "trade_sitc_1d", Filter: "overall@section", Field: "exports", Description: "Gross exports (RM), monthly"},
+	"loan_growth":  {Description: "Banking system loan growth, year-on-year percent, monthly (pushed through /api/ingest)"},
+	"pmi":          {Description: "Manufacturing purchasing managers' index, monthly (pushed through /api/ingest)"},
+	activitySeries: {Description: "Composite activity index of ACTIVITY_INDEX_WEIGHTS components, standard deviations from average, monthly (derived)"},
+}
+
+// fetchMacroSeries downloads a macro series from OpenDOSM and upserts every period.
+// It returns the number of periods stored; only new or revised periods are published as events.
+func fetchMacroSeries(ctx context.Context, s *AppState, code string) (int, error) {
+	source, ok := macroSeries[code]
+	if !ok {
+		return 0, fmt.Errorf("unknown macro series %q", code)
+	}
+	if source.Dataset == "" {
+		return 0, fmt.Errorf("macro series %q is not fetched from OpenDOSM", code)
+	}
+	api := s.sources.Source(config.SourceOpenDOSM)
+	client := opendosm.NewClient(api.BaseURL(), api)
+	observations, err := client.Fetch(ctx, source.Dataset, source.Filter, source.Field)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch %s: %w", code, err)
+	}
+
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+	var changed []opendosm.Observation
+	for _, o := range observations {
+		rows, err := qtx.UpsertMacroObservation(ctx, database.UpsertMacroObservationParams{
+			Series: code,
+			Period: o.Date,
+			Value:  strconv.FormatFloat(o.Value, 'f', -1, 64),
+			Source: "opendosm:" + source.Dataset,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to store %s for %s: %w", code, o.Date.Format("2006-01"), err)
+		}
+		if rows > 0 {
+			changed = append(changed, o)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit %s: %w", code, err)
+	}
+	invalidateResponseCache(s)
+	for _, o := range changed {
+		publishObservation(s, "macro", code, o.Date, o.Value, "opendosm:"+source.Dataset)
+	}
+	return len(observations), nil
+}
+
+// loadMacroSeries returns the stored observations of a macro series in [start, end], oldest first.
+func loadMacroSeries(ctx context.Context, s *AppState, code string, start, end time.Time) ([]analytics.Point, error) {
+	rows, err := s.db.GetMacroObservationsBySeriesAndDateRange(ctx, database.GetMacroObservationsBySeriesAndDateRangeParams{
+		Series:    code,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", code, err)
+	}
+	points := make([]analytics.Point, 0, len(rows))
+	for _, row := range rows {
+		v, convErr := strconv.ParseFloat(row.Value, 64)
+		if convErr != nil {
+			continue
+		}
+		points = append(points, analytics.Point{Date: row.Period, Value: v})
+	}
+	return points, nil
+}
+
+// errNoCPI is returned when the CPI needed for a real (deflated) series is not stored.
+var errNoCPI = errors.New("no CPI stored (run macro:fetch cpi)")
+
+// cpiDeflator converts nominal values into prices of a base period using stored CPI.
+type cpiDeflator struct {
+	cpi  []analytics.Point
+	base float64 // CPI level of the base period
+}
+
+// newCPIDeflator loads the CPI needed to deflate values dated in [start, end], from the latest
+// month published on or before start. basePeriod is the first day of the base month, or the
+// zero time to express values at the index's own base (CPI = 100).
+func newCPIDeflator(ctx context.Context, s *AppState, start, end, basePeriod time.Time) (*cpiDeflator, error) {
+	// Start from the month containing start so its own CPI is included
+	monthStart := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	cpi, err := loadMacroSeries(ctx, s, "cpi", monthStart, end)
+	if err != nil {
+		return nil, err
+	}
+	// CPI is published with a lag, so a recent range may hold none of its own; the latest
+	// month released before it is carried forward
+	if len(cpi) == 0 || cpi[0].Date.After(start) {
+		prior, err := s.db.GetMacroObservationOnOrBefore(ctx, database.GetMacroObservationOnOrBeforeParams{Series: "cpi", OnDate: start})
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to load cpi before %s: %w", start.Format("2006-01-02"), err)
+		}
+		if err == nil && prior.Period.Before(monthStart) {
+			if v, convErr := strconv.ParseFloat(prior.Value, 64); convErr == nil {
+				cpi = append([]analytics.Point{{Date: prior.Period, Value: v}}, cpi...)
+			}
+		}
+	}
+	if len(cpi) == 0 {
+		return nil, fmt.Errorf("%w on or before %s", errNoCPI, end.Format("2006-01"))
+	}
+	d := &cpiDeflator{cpi: cpi, base: 100}
+	if !basePeriod.IsZero() {
+		base, err := loadMacroSeries(ctx, s, "cpi", basePeriod, basePeriod)
+		if err != nil {
+			return nil, err
+		}
+		if len(base) == 0 || base[0].Value <= 0 {
+			return nil, fmt.Errorf("%w for base period %s", errNoCPI, basePeriod.Format("2006-01"))
+		}
+		d.base = base[0].Value
+	}
+	return d, nil
+}
+
+// deflate returns value in base-period prices, using the CPI of the month of date (the
+// latest published CPI for months not yet released). ok is false before the first CPI month.
+func (d *cpiDeflator) deflate(date time.Time, value float64) (float64, bool) {
+	level, ok := analytics.ValueAt(d.cpi, date)
+	if !ok || level <= 0 {
+		return 0, false
+	}
+	return value * d.base / level, true
+}
+
+// handlerMacroFetch downloads macro series from OpenDOSM, then recomputes the activity index
+// when one of its components was stored and evaluates the alert rules on the new periods.
+// Usage: macro:fetch [series...]  (all OpenDOSM series when none are given)
+func handlerMacroFetch(s *AppState, cmd command) error {
+	codes := cmd.Args
+	if len(codes) == 0 {
+		for code, source := range macroSeries {
+			if source.Dataset != "" {
+				codes = append(codes, code)
+			}
+		}
+		sort.Strings(codes)
+	}
+	var failed, stored []string
+	defer func() {
+		refreshActivityIndex(cmd.Context(), s, stored...)
+		if len(stored) > 0 {
+			runAlertsAfterFetch(cmd.Context(), s)
+		}
+	}()
+	for _, code := range codes {
+		code = strings.ToLower(code)
+		n, err := fetchMacroSeries(cmd.Context(), s, code)
+		if err != nil {
+			log.Printf("Error fetching macro series %s: %v", code, err)
+			failed = append(failed, code)
+			continue
+		}
+		stored = append(stored, code)
+		fmt.Printf("Stored %d periods of %s (%s).\n", n, code, macroSeries[code].Description)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to fetch %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql" // Import database/sql
+	"io"
 	"log"
 	"os"
 	"os/signal"
@@ -10,35 +11,79 @@ import (
 	"syscall"
 	"time" // Import time for DB connection timeout
 
-	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"   // Import config package
-	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database" // Import database package
-	_ "github.com/lib/pq"                                     // Import PostgreSQL driver
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"    // Import config package
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"  // Import database package
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport" // Optional Sentry error reporting
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/eventbus"  // Optional NATS/Kafka publishing of stored observations
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/notify"    // Notification channels (email, Telegram, webhooks)
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/respcache" // API response cache, in memory or shared through Redis
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/scraper"   // Per-source politeness for the scrapers
+	_ "github.com/lib/pq"                                      // Import PostgreSQL driver
+	"golang.org/x/sync/singleflight"                           // Shares concurrent identical fetches
+	"gopkg.in/natefinch/lumberjack.v2"                         // Rotating log file writer
 )
 
 // --- state struct definition (as shown above, or imported) ---
 type AppState struct {
-	db     *database.Queries
-	dbConn *sql.DB // Keep if raw connection needed, otherwise remove
-	cfg    *config.Config
+	db       *database.Queries
+	dbConn   *sql.DB // Keep if raw connection needed, otherwise remove
+	queryDB  *sql.DB // /api/query's pool, logged in as query_sandbox; nil when QUERY_DB_URL is unset
+	cfg      *config.Config
+	email    *notify.EmailNotifier // nil when SMTP is not configured
+	telegram *notify.TelegramBot   // nil when no bot token is configured
+	webhooks *notify.WebhookSender
+	events   eventbus.Publisher // nil when no event bus is configured
+	fetches  singleflight.Group // Concurrent identical fetches share one run; see sharedFetch
+	cache    respcache.Cache    // API response cache (in-memory or Redis); nil when disabled
+	sources  scraper.Set        // Rate-limited HTTP clients of the scraped data sources
+
+	// Commands and jobs run under workCtx, cancelled SHUTDOWN_GRACE_PERIOD after shutdown
+	// begins; work tracks those started with runBackground so shutdown can wait for them
+	workCtx context.Context
+	work    sync.WaitGroup
+
+	// CLI login state; set by login/register, cleared by logout
+	currentUser  *database.User
+	sessionToken string
 }
 
 // --- End Struct Definition ---
 
 func main() {
+	flags := parseFlags()
+
 	log.Println("Application starting...")
 
 	// --- Load Configuration ---
-	cfg, err := config.Read() // Load config first
+	cfg, err := config.Read(flags.Profile) // Load config first
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	flags.apply(&cfg) // Command line flags take precedence over the environment
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
 
-	// Check if certificate files exist (remains the same)
-	if _, err := os.Stat(cfg.CertFile); os.IsNotExist(err) {
-		log.Printf("Warning: Certificate file not found at %s. HTTPS server might fail.", cfg.CertFile)
+	// --- Log File (optional, in addition to stderr) ---
+	if cfg.LogFile != "" {
+		logFile := &lumberjack.Logger{
+			Filename:   cfg.LogFile,
+			MaxSize:    cfg.LogMaxSizeMB,
+			MaxAge:     cfg.LogMaxAgeDays,
+			MaxBackups: cfg.LogMaxBackups,
+			Compress:   cfg.LogCompress,
+		}
+		defer logFile.Close()
+		log.SetOutput(io.MultiWriter(os.Stderr, logFile))
+		log.Printf("Writing logs to %s (rotating at %d MB, keeping %d files for %d days).", cfg.LogFile, cfg.LogMaxSizeMB, cfg.LogMaxBackups, cfg.LogMaxAgeDays)
 	}
-	if _, err := os.Stat(cfg.KeyFile); os.IsNotExist(err) {
-		log.Printf("Warning: Key file not found at %s. HTTPS server might fail.", cfg.KeyFile)
+
+	// --- Error Reporting (optional) ---
+	if err := errreport.Init(cfg.SentryDSN, cfg.SentryEnvironment, ""); err != nil {
+		log.Printf("Warning: %v; errors will only be logged.", err)
+	} else if errreport.Enabled() {
+		log.Printf("Reporting errors to Sentry (environment %s).", cfg.SentryEnvironment)
+		defer errreport.Flush()
 	}
 
 	// --- Establish Database Connection ---
@@ -67,29 +112,122 @@ func main() {
 	}
 	log.Println("Database connection successful.")
 
+	// --- Query Sandbox Connection (optional) ---
+	// /api/query runs on its own pool logged in as query_sandbox, never on the application role
+	var queryDB *sql.DB
+	if cfg.QueryDBURL != "" && cfg.QueryMaxRows > 0 {
+		queryDB, err = openQueryDB(dbCtx, dbConn, cfg.QueryDBURL)
+		if err != nil {
+			log.Printf("Warning: %v; /api/query is disabled.", err)
+		} else {
+			defer queryDB.Close()
+			log.Println("Query sandbox connection successful.")
+		}
+	}
+
 	// --- Create Shared Application State ---
 	dbQueries := database.New(dbConn) // Initialize sqlc queries
 	programState := &AppState{        // Create the state struct instance
 		db:     dbQueries,
 		dbConn: dbConn, // Pass raw connection if needed by any handler
 		cfg:    &cfg,   // Pass pointer to the loaded config
+		email: notify.NewEmailNotifier(notify.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		}),
+		telegram: notify.NewTelegramBot(cfg.TelegramBotToken, cfg.TelegramAPIBaseURL),
+		webhooks: notify.NewWebhookSender(cfg.WebhookMaxAttempts, cfg.WebhookRetryBackoff),
+		sources:  scraper.NewSet(cfg.Sources),
+		queryDB:  queryDB,
+	}
+
+	// --- Event Bus (optional) ---
+	if events, err := eventbus.New(cfg.EventBus, cfg.EventBusURL); err != nil {
+		log.Printf("Warning: %v; stored observations will not be published.", err)
+	} else if events != nil {
+		log.Printf("Publishing stored observations to %s (topics %s.*).", cfg.EventBus, cfg.EventTopicPrefix)
+		programState.events = events
+		defer func() {
+			if err := events.Close(); err != nil {
+				log.Printf("Error closing event bus connection: %v", err)
+			}
+		}()
+	}
+
+	// --- Response Cache (optional, shared through Redis when configured) ---
+	// A public read-only instance keeps responses at least as long as it lets clients cache
+	// them; stored data changes still invalidate them at once
+	cacheTTL := cfg.APICacheTTL
+	if cfg.PublicReadOnly && cfg.PublicCacheMaxAge > cacheTTL {
+		cacheTTL = cfg.PublicCacheMaxAge
 	}
+	cache, err := respcache.New(context.Background(), cfg.CacheRedisURL, cfg.CacheKeyPrefix, cacheTTL, cfg.APICacheMaxEntries)
+	if err != nil {
+		log.Printf("Warning: %v; caching API responses in memory on this instance only.", err)
+	} else if redisCache, ok := cache.(*respcache.Redis); ok {
+		log.Printf("Caching API responses in Redis for %s (keys %s:*).", cacheTTL, cfg.CacheKeyPrefix)
+		defer func() {
+			if err := redisCache.Close(); err != nil {
+				log.Printf("Error closing Redis connection: %v", err)
+			}
+		}()
+	}
+	programState.cache = cache
 
 	// --- Setup for Graceful Shutdown (remains the same) ---
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel() // Ensure context is cancelled on exit
+	workCtx, cancelWork := withGracePeriod(ctx, cfg.ShutdownGracePeriod)
+	defer cancelWork()
+	programState.workCtx = workCtx
 
 	var wg sync.WaitGroup
 	shutdownChan := make(chan struct{}, 1) // Buffered channel
 
+	// shutdown is called when a service goroutine dies from a panic, so the others stop cleanly
+	shutdown := func() {
+		select {
+		case <-shutdownChan:
+		default:
+			close(shutdownChan)
+		}
+		cancel()
+	}
+
 	// --- Goroutine Setup ---
-	wg.Add(2) // Expecting two goroutines (server + CLI)
+	wg.Add(3) // CLI + scheduler + Telegram bot, plus the server unless disabled
 
 	// Start HTTPS server, passing the shared programState
-	go runHttpsServer(ctx, &wg, shutdownChan, programState) // <<< MODIFIED: Pass programState
+	if cfg.ServerDisabled {
+		log.Println("HTTPS server disabled.")
+	} else {
+		wg.Add(1)
+		go func() {
+			defer guardGoroutine("HTTPS server", shutdown)
+			runHttpsServer(ctx, &wg, shutdownChan, programState)
+		}()
+	}
+
+	// Start CLI, passing the shared programState and cancel func; commands run under ctx
+	go func() {
+		defer guardGoroutine("CLI", shutdown)
+		runCli(ctx, cancel, &wg, shutdownChan, programState)
+	}()
 
-	// Start CLI, passing the shared programState and cancel func
-	go runCli(cancel, &wg, shutdownChan, programState) // <<< MODIFIED: Pass programState
+	// Start background job scheduler; it stops when ctx is cancelled
+	go func() {
+		defer guardGoroutine("scheduler", shutdown)
+		runScheduler(ctx, &wg, programState)
+	}()
+
+	// Start Telegram bot polling (returns at once if not configured)
+	go func() {
+		defer guardGoroutine("Telegram bot", shutdown)
+		runTelegramBot(ctx, &wg, programState)
+	}()
 
 	// --- Graceful Shutdown Handling (OS Signals - remains the same) ---
 	sigChan := make(chan os.Signal, 1)
@@ -112,6 +250,8 @@ func main() {
 	// --- Wait for Goroutines (remains the same) ---
 	log.Println("Waiting for goroutines to finish...")
 	wg.Wait()
+	// Fetches triggered through the API are not owned by a service goroutine
+	waitForBackgroundWork(programState)
 
 	log.Println("Application finished.")
 }
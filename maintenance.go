@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// partitionedTable is the monthly-partitioned table maintained by db:maintenance.
+const partitionedTable = "audit_log"
+
+// materializedView is a view refreshed by the maintenance job.
+type materializedView struct {
+	Name    string
+	Refresh func(ctx context.Context) error
+}
+
+func materializedViews(s *AppState) []materializedView {
+	return []materializedView{
+		{Name: "monthly_fx_rates", Refresh: s.db.RefreshMonthlyFxRates},
+		{Name: "monthly_stock_closes", Refresh: s.db.RefreshMonthlyStockCloses},
+	}
+}
+
+// maintenanceResult counts what one maintenance run did.
+type maintenanceResult struct {
+	ViewsRefreshed    int
+	PartitionsCreated int
+	PartitionsDropped int
+	AuditRowsDeleted  int64
+	FetchRunsDeleted  int64
+	IntradayDeleted   int64
+	SessionsDeleted   int64
+	StepsSucceeded    int
+	StepsFailed       int
+	Errors            []string
+}
+
+// step records the outcome of one maintenance step.
+func (m *maintenanceResult) step(name string, err error) {
+	if err != nil {
+		m.StepsFailed++
+		m.Errors = append(m.Errors, fmt.Sprintf("%s: %v", name, err))
+		log.Printf("Maintenance: %s failed: %v", name, err)
+		return
+	}
+	m.StepsSucceeded++
+}
+
+// runMaintenance runs every maintenance step: ANALYZE, a concurrent refresh of the
+// materialized views read by interval=monthly API requests, creation of the audit log
+// partitions for the next PARTITION_MONTHS_AHEAD months, and removal of audit log entries,
+// finished fetch runs, intraday prices and sessions past their retention. A failed step does not stop the
+// others; their errors are joined.
+func runMaintenance(ctx context.Context, s *AppState) (maintenanceResult, error) {
+	var res maintenanceResult
+
+	_, err := s.dbConn.ExecContext(ctx, "ANALYZE")
+	res.step("analyze", err)
+
+	for _, view := range materializedViews(s) {
+		err := view.Refresh(ctx)
+		res.step("refresh "+view.Name, err)
+		if err == nil {
+			log.Printf("Maintenance: refreshed %s.", view.Name)
+			res.ViewsRefreshed++
+		}
+	}
+	if res.ViewsRefreshed > 0 {
+		invalidateResponseCache(s)
+	}
+
+	now := time.Now().UTC()
+	res.PartitionsCreated, err = createPartitions(ctx, s, now, s.cfg.PartitionMonthsAhead)
+	res.step("create partitions", err)
+
+	if s.cfg.AuditRetentionDays > 0 {
+		cutoff := now.AddDate(0, 0, -s.cfg.AuditRetentionDays)
+		res.PartitionsDropped, err = dropPartitionsBefore(ctx, s, cutoff)
+		res.step("drop expired partitions", err)
+		res.AuditRowsDeleted, err = s.db.DeleteAuditLogBefore(ctx, cutoff)
+		res.step("delete expired audit log entries", err)
+	}
+	if s.cfg.FetchRunRetentionDays > 0 {
+		res.FetchRunsDeleted, err = s.db.DeleteFetchRunsBefore(ctx, now.AddDate(0, 0, -s.cfg.FetchRunRetentionDays))
+		res.step("delete expired fetch runs", err)
+	}
+	if s.cfg.IntradayRetentionDays > 0 {
+		res.IntradayDeleted, err = s.db.DeleteIntradayPricesBefore(ctx, now.AddDate(0, 0, -s.cfg.IntradayRetentionDays))
+		res.step("delete expired intraday prices", err)
+	}
+	res.SessionsDeleted, err = s.db.DeleteExpiredUserSessions(ctx)
+	res.step("delete expired sessions", err)
+
+	var errs []error
+	for _, e := range res.Errors {
+		errs = append(errs, errors.New(e))
+	}
+	return res, errors.Join(errs...)
+}
+
+// partitionName returns the name of the partition holding the month starting at month.
+func partitionName(month time.Time) string {
+	return fmt.Sprintf("%s_p%s", partitionedTable, month.Format("200601"))
+}
+
+// createPartitions creates the monthly partitions from next month through monthsAhead months
+// from now that do not exist yet. It returns how many it created.
+func createPartitions(ctx context.Context, s *AppState, now time.Time, monthsAhead int) (int, error) {
+	existing, err := listPartitions(ctx, s)
+	if err != nil {
+		return 0, err
+	}
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	created := 0
+	for i := 1; i <= monthsAhead; i++ {
+		start := first.AddDate(0, i, 0)
+		name := partitionName(start)
+		if _, ok := existing[name]; ok {
+			continue
+		}
+		// Fails if the default partition already holds rows for the month, which only happens
+		// when the job has not run for longer than PARTITION_MONTHS_AHEAD.
+		stmt := fmt.Sprintf("CREATE TABLE %s PARTITION OF %s FOR VALUES FROM (%s) TO (%s)",
+			pq.QuoteIdentifier(name), pq.QuoteIdentifier(partitionedTable),
+			pq.QuoteLiteral(start.Format(time.RFC3339)), pq.QuoteLiteral(start.AddDate(0, 1, 0).Format(time.RFC3339)))
+		if _, err := s.dbConn.ExecContext(ctx, stmt); err != nil {
+			return created, fmt.Errorf("failed to create %s: %w", name, err)
+		}
+		log.Printf("Maintenance: created partition %s.", name)
+		created++
+	}
+	return created, nil
+}
+
+// dropPartitionsBefore drops the monthly partitions that end on or before cutoff. It returns
+// how many it dropped.
+func dropPartitionsBefore(ctx context.Context, s *AppState, cutoff time.Time) (int, error) {
+	existing, err := listPartitions(ctx, s)
+	if err != nil {
+		return 0, err
+	}
+	dropped := 0
+	for name, start := range existing {
+		if start.AddDate(0, 1, 0).After(cutoff) {
+			continue
+		}
+		if _, err := s.dbConn.ExecContext(ctx, "DROP TABLE "+pq.QuoteIdentifier(name)); err != nil {
+			return dropped, fmt.Errorf("failed to drop %s: %w", name, err)
+		}
+		log.Printf("Maintenance: dropped partition %s.", name)
+		dropped++
+	}
+	return dropped, nil
+}
+
+// listPartitions returns the monthly partitions of the audit log by name, with the start of
+// the month each holds. The default partition is left out.
+func listPartitions(ctx context.Context, s *AppState) (map[string]time.Time, error) {
+	rows, err := s.dbConn.QueryContext(ctx, `SELECT c.relname FROM pg_inherits i
+JOIN pg_class c ON c.oid = i.inhrelid
+JOIN pg_class p ON p.oid = i.inhparent
+WHERE p.relname = $1`, partitionedTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions: %w", err)
+	}
+	defer rows.Close()
+	partitions := map[string]time.Time{}
+	prefix := partitionedTable + "_p"
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to list partitions: %w", err)
+		}
+		suffix, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		if start, err := time.Parse("200601", suffix); err == nil {
+			partitions[name] = start
+		}
+	}
+	return partitions, rows.Err()
+}
+
+// recordedMaintenance runs the maintenance as cmd, recording it in fetch_runs with one
+// fetch per step.
+func recordedMaintenance(s *AppState, cmd command) (maintenanceResult, error) {
+	run := startFetchRun(s, cmd)
+	cmd = run.attach(cmd)
+	res, err := runMaintenance(cmd.Context(), s)
+	run.finish(s, fetchStats{
+		SuccessfulFetches: res.StepsSucceeded,
+		FailedFetches:     res.StepsFailed,
+		Errors:            res.Errors,
+	}, nil)
+	return res, err
+}
+
+// handlerDbMaintenance runs the database maintenance now.
+// Usage: db:maintenance
+func handlerDbMaintenance(s *AppState, cmd command) error {
+	res, err := recordedMaintenance(s, cmd)
+	fmt.Printf("Maintenance: %d step(s) succeeded, %d failed.\n", res.StepsSucceeded, res.StepsFailed)
+	fmt.Printf("  Refreshed %d materialized view(s); created %d and dropped %d partition(s).\n",
+		res.ViewsRefreshed, res.PartitionsCreated, res.PartitionsDropped)
+	fmt.Printf("  Deleted %d audit log entries, %d fetch run(s), %d intraday price(s) and %d expired session(s).\n",
+		res.AuditRowsDeleted, res.FetchRunsDeleted, res.IntradayDeleted, res.SessionsDeleted)
+	return err
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/newsfeed"
+)
+
+// companyNameSuffixes are dropped from company names before matching headlines, which
+// rarely spell them out ("Malayan Banking Berhad" is reported as "Malayan Banking").
+var companyNameSuffixes = []string{" BERHAD", " BHD.", " BHD"}
+
+// newsMatcher finds the tracked companies a headline mentions.
+type newsMatcher struct {
+	codes    []string
+	patterns []*regexp.Regexp
+}
+
+// newNewsMatcher builds a matcher for the stored companies among codes. A company is matched
+// on its name, its board name (headlines say "Maybank" rather than "Malayan Banking") and its
+// stock code when written as "(1155)", "KLSE:1155" or "1155.KL"; a bare number is too often a
+// figure or a year. Codes without a stored profile are skipped, since there is no name to
+// look for.
+func newNewsMatcher(ctx context.Context, s *AppState, codes []string) (*newsMatcher, error) {
+	companies, err := s.db.ListCompaniesByStockCodes(ctx, codes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load company names: %w", err)
+	}
+	m := &newsMatcher{}
+	for _, c := range companies {
+		names := []string{c.CompanyName}
+		if c.ShortName.Valid {
+			names = append(names, c.ShortName.String)
+		}
+		var alternatives []string
+		for _, name := range names {
+			name = strings.ToUpper(strings.TrimSpace(name))
+			for _, suffix := range companyNameSuffixes {
+				name = strings.TrimSuffix(name, suffix)
+			}
+			if len(name) < 3 {
+				continue // Too short to match without false positives
+			}
+			alternatives = append(alternatives, `\b`+regexp.QuoteMeta(name)+`\b`)
+		}
+		if len(alternatives) == 0 {
+			continue
+		}
+		code := regexp.QuoteMeta(c.StockCode)
+		alternatives = append(alternatives, `\(`+code+`\)`, `\bKLSE:\s*`+code+`\b`, `\b`+code+`\.KL\b`)
+		m.codes = append(m.codes, c.StockCode)
+		m.patterns = append(m.patterns, regexp.MustCompile(`(?i)`+strings.Join(alternatives, "|")))
+	}
+	return m, nil
+}
+
+// match returns the stock codes of the companies title mentions.
+func (m *newsMatcher) match(title string) []string {
+	var codes []string
+	for i, pattern := range m.patterns {
+		if pattern.MatchString(title) {
+			codes = append(codes, m.codes[i])
+		}
+	}
+	return codes
+}
+
+// fetchNews reads every NEWS_FEED_URLS feed and stores the headlines that mention a tracked
+// company. A feed that cannot be read does not stop the others. It returns the number of
+// new headlines stored. New headlines are evaluated against the announcement alerts.
+func fetchNews(ctx context.Context, s *AppState) (int, error) {
+	if len(s.cfg.NewsFeedURLs) == 0 {
+		return 0, fmt.Errorf("NEWS_FEED_URLS is not set")
+	}
+	matcher, err := newNewsMatcher(ctx, s, trackedStocks(ctx, s, ""))
+	if err != nil {
+		return 0, err
+	}
+	if len(matcher.codes) == 0 {
+		log.Println("No tracked company has a stored profile; run stock:fetch:profile_all first.")
+		return 0, nil
+	}
+
+	client := newsfeed.NewClient()
+	stored := 0
+	var errs []error
+	var announced []announcement
+	defer func() { runAnnouncementAlerts(ctx, s, announced) }()
+	for _, url := range s.cfg.NewsFeedURLs {
+		items, err := client.Fetch(ctx, url)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, item := range items {
+			published := item.Published
+			if published.IsZero() {
+				published = time.Now().UTC()
+			}
+			source := item.Source
+			if source == "" {
+				source = url
+			}
+			sentiment := fmt.Sprintf("%.4f", analytics.HeadlineSentiment(item.Title))
+			for _, code := range matcher.match(item.Title) {
+				n, err := s.db.InsertNewsHeadline(ctx, database.InsertNewsHeadlineParams{
+					StockCode:   code,
+					Title:       item.Title,
+					Link:        item.Link,
+					Source:      source,
+					PublishedAt: published,
+					Sentiment:   sentiment,
+				})
+				if err != nil {
+					return stored, fmt.Errorf("failed to store headline %q for %s: %w", item.Title, code, err)
+				}
+				if n > 0 {
+					announced = append(announced, announcement{StockCode: code, Categories: headlineCategories(item.Title), Title: item.Title, Link: item.Link, At: published})
+				}
+				stored += int(n)
+			}
+		}
+	}
+	if stored > 0 {
+		invalidateResponseCache(s)
+	}
+	return stored, errors.Join(errs...)
+}
+
+// newsInterval is the news fetch interval, or 0 when no feeds are configured.
+func newsInterval(s *AppState) time.Duration {
+	if len(s.cfg.NewsFeedURLs) == 0 {
+		return 0
+	}
+	return s.cfg.NewsFetchInterval
+}
+
+// handlerNews lists the latest stored headlines about a stock.
+// Usage: news <stock_code> [LIMIT]  (default: 20)
+func handlerNews(s *AppState, cmd command) error {
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		return fmt.Errorf("usage: %s <stock_code> [LIMIT]", cmd.Name)
+	}
+	code, err := lookupStockCode(cmd.Context(), s, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	limit := 20
+	if len(cmd.Args) == 2 {
+		n, err := strconv.Atoi(cmd.Args[1])
+		if err != nil || n < 1 || n > 1000 {
+			return fmt.Errorf("invalid limit %q (use 1-1000)", cmd.Args[1])
+		}
+		limit = n
+	}
+	rows, err := s.db.ListNewsHeadlinesByStockCode(cmd.Context(), database.ListNewsHeadlinesByStockCodeParams{
+		StockCode:  code,
+		StartTime:  time.Time{},
+		EndTime:    time.Now().UTC(),
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list headlines: %w", err)
+	}
+	if len(rows) == 0 {
+		fmt.Printf("No headlines stored for %s.\n", code)
+		return nil
+	}
+	for _, row := range rows {
+		fmt.Printf("  %s %7s  %s\n      %s (%s)\n", row.PublishedAt.Format("2006-01-02 15:04"), row.Sentiment, row.Title, row.Link, row.Source)
+	}
+	return nil
+}
+
+// handlerNewsFetch scans the news feeds for headlines about tracked companies now (admin only).
+// Usage: news:fetch
+func handlerNewsFetch(s *AppState, cmd command) error {
+	n, err := fetchNews(cmd.Context(), s)
+	fmt.Printf("Stored %d new headline(s).\n", n)
+	notifyDataStored(s, cmd.Name, n)
+	return err
+}
+
+// handlerNewsSentimentRescore scores every stored headline again, after the sentiment
+// lexicon has changed (admin only).
+// Usage: news:sentiment:rescore
+func handlerNewsSentimentRescore(s *AppState, cmd command) error {
+	rows, err := s.db.ListNewsHeadlineTitles(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to list headlines: %w", err)
+	}
+	for _, row := range rows {
+		err := s.db.UpdateNewsHeadlineSentiment(cmd.Context(), database.UpdateNewsHeadlineSentimentParams{
+			ID:        row.ID,
+			Sentiment: fmt.Sprintf("%.4f", analytics.HeadlineSentiment(row.Title)),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update sentiment of headline %d: %w", row.ID, err)
+		}
+	}
+	if len(rows) > 0 {
+		invalidateResponseCache(s)
+	}
+	fmt.Printf("Scored %d headline(s).\n", len(rows))
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/notify"
+	"github.com/google/uuid"
+)
+
+// maxFailuresInSummary caps how many individual failures are listed in a summary message.
+const maxFailuresInSummary = 50
+
+// notifyAlerts fires alert.triggered webhooks and sends each rule owner one message listing
+// their newly triggered alerts, by email and to their linked Telegram chat.
+func notifyAlerts(ctx context.Context, s *AppState, events []database.AlertEvent) {
+	for _, e := range events {
+		fireWebhookEvent(s, webhookAlertTriggered, alertEventResponseFromDB(e))
+	}
+	if len(events) == 0 || (s.email == nil && s.telegram == nil) {
+		return
+	}
+
+	byUser := make(map[uuid.UUID][]database.AlertEvent)
+	for _, e := range events {
+		byUser[e.UserID] = append(byUser[e.UserID], e)
+	}
+	for userID, userEvents := range byUser {
+		notifyUser(ctx, s, userID, "alert", len(userEvents), alertMessage(userEvents))
+	}
+}
+
+// notifyAnnouncementAlerts sends each user a summary of their triggered announcement alerts
+// and fires announcement.triggered webhooks.
+func notifyAnnouncementAlerts(ctx context.Context, s *AppState, events []database.AnnouncementAlertEvent) {
+	for _, e := range events {
+		fireWebhookEvent(s, webhookAnnouncementTriggered, announcementAlertEventResponseFromDB(e))
+	}
+	if len(events) == 0 || (s.email == nil && s.telegram == nil) {
+		return
+	}
+
+	byUser := make(map[uuid.UUID][]database.AnnouncementAlertEvent)
+	for _, e := range events {
+		byUser[e.UserID] = append(byUser[e.UserID], e)
+	}
+	for userID, userEvents := range byUser {
+		notifyUser(ctx, s, userID, "announcement alert", len(userEvents), announcementAlertMessage(userEvents))
+	}
+}
+
+// notifyUser sends msg, a summary of count notifications of kind, to a user by email and,
+// when they have linked a chat, Telegram.
+func notifyUser(ctx context.Context, s *AppState, userID uuid.UUID, kind string, count int, msg notify.Message) {
+	user, err := s.db.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Printf("Error loading user %s for %s notification: %v", userID, kind, err)
+		return
+	}
+	if s.email != nil {
+		if err := s.email.Send([]string{user.Email}, msg); err != nil {
+			log.Printf("Error emailing %ss to %s: %v", kind, user.Username, err)
+		} else {
+			log.Printf("Emailed %d %s(s) to %s.", count, kind, user.Username)
+		}
+	}
+	if s.telegram != nil && user.TelegramChatID.Valid {
+		if err := s.telegram.Send(ctx, []int64{user.TelegramChatID.Int64}, msg); err != nil {
+			log.Printf("Error sending %ss to %s on Telegram: %v", kind, user.Username, err)
+		}
+	}
+}
+
+// notifyBatchFailures sends operators a summary (and fires fetch.failed webhooks) when a batch
+// run finished with failures.
+func notifyBatchFailures(s *AppState, job string, failures []string) {
+	if len(failures) == 0 {
+		return
+	}
+	fireWebhookEvent(s, webhookFetchFailed, fetchFailedEvent{Job: job, Failures: failures})
+	msg := batchFailureMessage(job, failures)
+	if s.email != nil && len(s.cfg.NotifyEmailTo) > 0 {
+		if err := s.email.Send(s.cfg.NotifyEmailTo, msg); err != nil {
+			log.Printf("Error emailing failure summary for %s: %v", job, err)
+		}
+	}
+	if s.telegram != nil && len(s.cfg.TelegramNotifyChatIDs) > 0 {
+		chatIDs, err := notify.ParseChatIDs(s.cfg.TelegramNotifyChatIDs)
+		if err != nil {
+			log.Printf("Error sending failure summary for %s to Telegram: %v", job, err)
+			return
+		}
+		if err := s.telegram.Send(context.Background(), chatIDs, msg); err != nil {
+			log.Printf("Error sending failure summary for %s to Telegram: %v", job, err)
+		}
+	}
+}
+
+func alertMessage(events []database.AlertEvent) notify.Message {
+	var b strings.Builder
+	for _, e := range events {
+		fmt.Fprintf(&b, "%s %s %s: observed %s on %s\n", alertLabel(e.Series, e.Change), e.Condition, e.Threshold, e.ObservedValue, e.ObservedDate.Format("2006-01-02"))
+	}
+	subject := fmt.Sprintf("Alert: %s %s %s", alertLabel(events[0].Series, events[0].Change), events[0].Condition, events[0].Threshold)
+	if len(events) > 1 {
+		subject = fmt.Sprintf("%d alerts triggered", len(events))
+	}
+	return notify.Message{Subject: subject, Body: b.String()}
+}
+
+func announcementAlertMessage(events []database.AnnouncementAlertEvent) notify.Message {
+	var b strings.Builder
+	for _, e := range events {
+		fmt.Fprintf(&b, "%s %s: %s\n", e.StockCode, e.Category, e.Title)
+		if e.Link != "" {
+			fmt.Fprintf(&b, "  %s\n", e.Link)
+		}
+	}
+	subject := fmt.Sprintf("Announcement: %s %s", events[0].StockCode, events[0].Title)
+	if len(events) > 1 {
+		subject = fmt.Sprintf("%d announcement alerts triggered", len(events))
+	}
+	return notify.Message{Subject: subject, Body: b.String()}
+}
+
+func batchFailureMessage(job string, failures []string) notify.Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s finished with %d failure(s):\n\n", job, len(failures))
+	for i, f := range failures {
+		if i == maxFailuresInSummary {
+			fmt.Fprintf(&b, "... and %d more (see logs)\n", len(failures)-maxFailuresInSummary)
+			break
+		}
+		fmt.Fprintf(&b, "- %s\n", f)
+	}
+	return notify.Message{Subject: fmt.Sprintf("%s: %d failure(s)", job, len(failures)), Body: b.String()}
+}
+
+// notifyDataStored fires data.stored webhooks after a fetch stored new data.
+func notifyDataStored(s *AppState, job string, records int) {
+	if records == 0 {
+		return
+	}
+	fireWebhookEvent(s, webhookDataStored, dataStoredEvent{Job: job, Records: records})
+}
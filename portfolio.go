@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/google/uuid"
+)
+
+// baseCurrency is the currency stock prices and cost bases are stored in.
+const baseCurrency = "MYR"
+
+// --- Portfolio Command Handlers ---
+
+// handlerPortfolio lists the current user's holdings with their latest valuation.
+// Usage: portfolio
+func handlerPortfolio(s *AppState, cmd command) error {
+	user := cmd.user
+	ctx := cmd.Context()
+	holdings, err := s.db.ListPortfolioHoldingsByUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load portfolio: %w", err)
+	}
+	if len(holdings) == 0 {
+		fmt.Println("Portfolio is empty. Add lots with portfolio:add <code> <quantity> <cost_per_share> <YYYY-MM-DD>.")
+		return nil
+	}
+
+	today := markettime.Today()
+	var totalValue, totalCost float64
+	for _, h := range holdings {
+		lot, err := lotFromHolding(h)
+		if err != nil {
+			log.Printf("Skipping holding %s: %v", h.ID, err)
+			continue
+		}
+		price := lot.CostBasis
+		priceNote := "no price, at cost"
+		latest, err := s.db.GetLatestStockPriceOnOrBefore(ctx, database.GetLatestStockPriceOnOrBeforeParams{
+			StockCode: lot.Code,
+			OnDate:    today,
+		})
+		if err == nil {
+			if p, convErr := strconv.ParseFloat(latest.ClosingPrice, 64); convErr == nil {
+				price = p
+				priceNote = "close " + latest.PriceDate.Format("2006-01-02")
+			}
+		} else if err != sql.ErrNoRows {
+			return fmt.Errorf("failed to load latest price for %s: %w", lot.Code, err)
+		}
+
+		value := lot.Quantity * price
+		cost := lot.Quantity * lot.CostBasis
+		totalValue += value
+		totalCost += cost
+		fmt.Printf("%s  %-8s %12.2f @ %10.4f (%s)  value %12.2f  P&L %+12.2f  [%s]\n",
+			h.ID, lot.Code, lot.Quantity, lot.CostBasis, lot.TradeDate.Format("2006-01-02"), value, value-cost, priceNote)
+	}
+	fmt.Printf("Total value %.2f %s, cost %.2f, unrealized P&L %+.2f\n", totalValue, baseCurrency, totalCost, totalValue-totalCost)
+	return nil
+}
+
+// handlerPortfolioAdd records a purchase lot for the current user.
+// Usage: portfolio:add <code> <quantity> <cost_per_share> <YYYY-MM-DD>
+func handlerPortfolioAdd(s *AppState, cmd command) error {
+	user := cmd.user
+	if len(cmd.Args) != 4 {
+		return fmt.Errorf("usage: %s <code> <quantity> <cost_per_share> <YYYY-MM-DD>", cmd.Name)
+	}
+	params, err := newPortfolioHoldingParams(cmd.Context(), s, user.ID, cmd.Args[0], cmd.Args[1], cmd.Args[2], cmd.Args[3])
+	if err != nil {
+		return err
+	}
+	holding, err := s.db.CreatePortfolioHolding(cmd.Context(), params)
+	if err != nil {
+		return fmt.Errorf("failed to add holding: %w", err)
+	}
+	log.Printf("User %s added holding %s: %s x %s @ %s.", user.Username, holding.ID, holding.StockCode, holding.Quantity, holding.CostBasis)
+	fmt.Printf("Added lot %s.\n", holding.ID)
+	return nil
+}
+
+// handlerPortfolioRemove deletes one of the current user's lots.
+// Usage: portfolio:remove <id>
+func handlerPortfolioRemove(s *AppState, cmd command) error {
+	user := cmd.user
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <id>", cmd.Name)
+	}
+	id, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid holding ID %q: %w", cmd.Args[0], err)
+	}
+	n, err := s.db.DeletePortfolioHolding(cmd.Context(), database.DeletePortfolioHoldingParams{ID: id, UserID: user.ID})
+	if err != nil {
+		return fmt.Errorf("failed to remove holding %s: %w", id, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("holding %s not found in your portfolio", id)
+	}
+	log.Printf("User %s removed holding %s.", user.Username, id)
+	fmt.Printf("Removed lot %s.\n", id)
+	return nil
+}
+
+// --- Portfolio helpers shared with the API ---
+
+// newPortfolioHoldingParams validates raw holding fields as entered by a user.
+func newPortfolioHoldingParams(ctx context.Context, s *AppState, userID uuid.UUID, code, quantity, costBasis, tradeDate string) (database.CreatePortfolioHoldingParams, error) {
+	code, err := lookupStockCode(ctx, s, code)
+	if err != nil {
+		return database.CreatePortfolioHoldingParams{}, err
+	}
+	qty, err := strconv.ParseFloat(strings.TrimSpace(quantity), 64)
+	if err != nil || qty <= 0 {
+		return database.CreatePortfolioHoldingParams{}, fmt.Errorf("invalid quantity %q (must be a positive number)", quantity)
+	}
+	cost, err := strconv.ParseFloat(strings.TrimSpace(costBasis), 64)
+	if err != nil || cost < 0 {
+		return database.CreatePortfolioHoldingParams{}, fmt.Errorf("invalid cost per share %q", costBasis)
+	}
+	date, err := markettime.ParseDate(strings.TrimSpace(tradeDate))
+	if err != nil {
+		return database.CreatePortfolioHoldingParams{}, fmt.Errorf("invalid trade date %q (use YYYY-MM-DD)", tradeDate)
+	}
+	return database.CreatePortfolioHoldingParams{
+		ID:        uuid.New(),
+		UserID:    userID,
+		StockCode: code,
+		Quantity:  strconv.FormatFloat(qty, 'f', -1, 64),
+		CostBasis: strconv.FormatFloat(cost, 'f', -1, 64),
+		TradeDate: date,
+	}, nil
+}
+
+// lotFromHolding converts a stored holding's decimal columns for valuation.
+func lotFromHolding(h database.PortfolioHolding) (analytics.Lot, error) {
+	qty, err := strconv.ParseFloat(h.Quantity, 64)
+	if err != nil {
+		return analytics.Lot{}, fmt.Errorf("invalid quantity %q: %w", h.Quantity, err)
+	}
+	cost, err := strconv.ParseFloat(h.CostBasis, 64)
+	if err != nil {
+		return analytics.Lot{}, fmt.Errorf("invalid cost basis %q: %w", h.CostBasis, err)
+	}
+	return analytics.Lot{Code: h.StockCode, Quantity: qty, CostBasis: cost, TradeDate: h.TradeDate}, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// trustProxies replaces the RemoteAddr of requests arriving from a trusted reverse proxy (see
+// TRUSTED_PROXIES) with the client address the proxy forwarded, so audit entries and logs
+// record the client rather than the proxy. The forwarded address has no port.
+// It returns next unchanged when no proxies are trusted.
+func trustProxies(trusted []netip.Prefix, next http.Handler) http.Handler {
+	if len(trusted) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if peer, ok := remoteIP(r.RemoteAddr); ok && isTrustedProxy(trusted, peer) {
+			if client, ok := forwardedClient(r, trusted); ok {
+				r = r.Clone(r.Context())
+				r.RemoteAddr = client.String()
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forwardedClient returns the client address from X-Forwarded-For, or X-Real-IP when that is
+// absent. X-Forwarded-For is read right to left, skipping trusted proxies, so a client cannot
+// pose as another address by sending the header itself; if every hop is trusted the leftmost
+// one is the client.
+func forwardedClient(r *http.Request, trusted []netip.Prefix) (netip.Addr, bool) {
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	if len(hops) == 0 {
+		return remoteIP(r.Header.Get("X-Real-IP"))
+	}
+	var client netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := remoteIP(hops[i])
+		if !ok {
+			break // A malformed hop ends the chain that can be trusted
+		}
+		client = addr
+		if !isTrustedProxy(trusted, addr) {
+			break
+		}
+	}
+	return client, client.IsValid()
+}
+
+// remoteIP parses an address with or without a port.
+func remoteIP(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	addr, err := netip.ParseAddr(strings.Trim(s, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+func isTrustedProxy(trusted []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// mountAt serves h under basePath (see BASE_PATH), with the prefix stripped so handlers and
+// the frontend routes see the same paths as at the root. basePath itself redirects to
+// basePath + "/"; anything outside it is a 404. It returns h unchanged when basePath is empty.
+func mountAt(basePath string, h http.Handler) http.Handler {
+	if basePath == "" {
+		return h
+	}
+	mux := http.NewServeMux()
+	mux.Handle(basePath+"/", http.StripPrefix(basePath, h))
+	mux.Handle(basePath, http.RedirectHandler(basePath+"/", http.StatusMovedPermanently))
+	return mux
+}
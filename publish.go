@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/objectstore"
+)
+
+// Structure for a published series file (e.g. fx/USD.json)
+type PublishedSeries struct {
+	Series    string                `json:"series"` // Series key, e.g. fx:USD
+	Unit      string                `json:"unit"`
+	UpdatedAt time.Time             `json:"updated_at"`
+	Data      []TimeSeriesDataPoint `json:"data"`
+}
+
+// Structure for an entry of the published index.json
+type PublishedSeriesIndexItem struct {
+	Series     string  `json:"series"`
+	Path       string  `json:"path"` // Relative to the index, e.g. fx/USD.json
+	LatestDate string  `json:"latest_date"`
+	Latest     float64 `json:"latest"`
+	Points     int     `json:"points"`
+}
+
+// publishTarget receives published files by relative path.
+type publishTarget interface {
+	put(ctx context.Context, name string, data []byte) error
+}
+
+// dirPublishTarget writes files under a local directory, replacing each atomically.
+type dirPublishTarget struct {
+	dir string
+}
+
+func (t dirPublishTarget) put(ctx context.Context, name string, data []byte) error {
+	path := filepath.Join(t.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// bucketPublishTarget uploads files to object storage.
+type bucketPublishTarget struct {
+	store *objectstore.Store
+}
+
+func (t bucketPublishTarget) put(ctx context.Context, name string, data []byte) error {
+	return t.store.Put(ctx, name, bytes.NewReader(data), int64(len(data)), "application/json")
+}
+
+// publishTargets returns the configured publishing destinations.
+func publishTargets(s *AppState) ([]publishTarget, error) {
+	var targets []publishTarget
+	if s.cfg.PublishDir != "" {
+		targets = append(targets, dirPublishTarget{dir: s.cfg.PublishDir})
+	}
+	if s.cfg.PublishBucket != "" {
+		store, err := objectstore.New(objectstore.Options{
+			Endpoint:  s.cfg.SnapshotEndpoint,
+			Bucket:    s.cfg.PublishBucket,
+			Prefix:    s.cfg.PublishPrefix,
+			AccessKey: s.cfg.SnapshotAccessKey,
+			SecretKey: s.cfg.SnapshotSecretKey,
+			Region:    s.cfg.SnapshotRegion,
+			UseSSL:    s.cfg.SnapshotUseSSL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, bucketPublishTarget{store: store})
+	}
+	return targets, nil
+}
+
+// publishDatasets writes the full history of every stock, currency and macro series as
+// <kind>/<code>.json, plus an index.json listing them, to PUBLISH_DIR and/or PUBLISH_BUCKET.
+// It returns the number of series published.
+func publishDatasets(ctx context.Context, s *AppState) (int, error) {
+	targets, err := publishTargets(s)
+	if err != nil {
+		return 0, err
+	}
+	if len(targets) == 0 {
+		return 0, fmt.Errorf("publishing is disabled (set PUBLISH_DIR or PUBLISH_BUCKET)")
+	}
+
+	stocks, err := s.db.ListStockCodesWithPrices(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list stock codes: %w", err)
+	}
+	currencies, err := s.db.ListForeignExchangeCurrencies(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list currencies: %w", err)
+	}
+	macroCodes := make([]string, 0, len(macroSeries))
+	for code := range macroSeries {
+		macroCodes = append(macroCodes, code)
+	}
+	sort.Strings(macroCodes)
+
+	now, today := time.Now().UTC(), markettime.Today()
+	var index []PublishedSeriesIndexItem
+	publish := func(series, path, unit string, points []analytics.Point) error {
+		if len(points) == 0 {
+			return nil
+		}
+		file := PublishedSeries{Series: series, Unit: unit, UpdatedAt: now, Data: make([]TimeSeriesDataPoint, 0, len(points))}
+		for _, p := range points {
+			file.Data = append(file.Data, TimeSeriesDataPoint{Date: p.Date.Format("2006-01-02"), Value: p.Value})
+		}
+		data, err := json.Marshal(file)
+		if err != nil {
+			return err
+		}
+		for _, t := range targets {
+			if err := t.put(ctx, path, data); err != nil {
+				return fmt.Errorf("failed to publish %s: %w", path, err)
+			}
+		}
+		last := points[len(points)-1]
+		index = append(index, PublishedSeriesIndexItem{
+			Series:     series,
+			Path:       path,
+			LatestDate: last.Date.Format("2006-01-02"),
+			Latest:     last.Value,
+			Points:     len(points),
+		})
+		return nil
+	}
+
+	for _, code := range stocks {
+		key := seriesKey{Kind: watchlistStock, Code: code}
+		points, err := loadStockCloses(ctx, s, code, returnsEpoch, today)
+		if err != nil {
+			return 0, err
+		}
+		if err := publish(key.String(), "stock/"+code+".json", "MYR", points); err != nil {
+			return 0, err
+		}
+	}
+	for _, code := range currencies {
+		key := seriesKey{Kind: watchlistFx, Code: code}
+		points, err := loadFxPerUnit(ctx, s, code, returnsEpoch, today)
+		if err != nil {
+			return 0, err
+		}
+		if err := publish(key.String(), "fx/"+code+".json", "MYR per 1 "+code, points); err != nil {
+			return 0, err
+		}
+	}
+	for _, code := range macroCodes {
+		points, err := loadMacroSeries(ctx, s, code, returnsEpoch, today)
+		if err != nil {
+			return 0, err
+		}
+		if err := publish("macro:"+code, "macro/"+code+".json", macroSeries[code].Description, points); err != nil {
+			return 0, err
+		}
+	}
+
+	// The index goes last so it never lists a file that has not been written
+	data, err := json.Marshal(index)
+	if err != nil {
+		return 0, err
+	}
+	for _, t := range targets {
+		if err := t.put(ctx, "index.json", data); err != nil {
+			return 0, fmt.Errorf("failed to publish index.json: %w", err)
+		}
+	}
+	return len(index), nil
+}
+
+// publishInterval is the publishing interval, or 0 when no destination is configured.
+func publishInterval(s *AppState) time.Duration {
+	if s.cfg.PublishDir == "" && s.cfg.PublishBucket == "" {
+		return 0
+	}
+	return s.cfg.PublishInterval
+}
+
+// handlerPublishRun publishes the static series files now.
+// Usage: publish:run
+func handlerPublishRun(s *AppState, cmd command) error {
+	n, err := publishDatasets(cmd.Context(), s)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Published %d series.\n", n)
+	return nil
+}
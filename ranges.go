@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// refreshStockRanges recomputes the derived 52-week high and low of one stock, or of every stock
+// when code is empty, after closes were stored, moved or deleted. Errors are logged: the ranges
+// are caught up by the next refresh or stock:ranges:refresh.
+func refreshStockRanges(ctx context.Context, s *AppState, code string) {
+	if _, err := s.db.RefreshStockRanges52w(ctx, sql.NullString{String: code, Valid: code != ""}); err != nil {
+		if code == "" {
+			code = "all stocks"
+		}
+		log.Printf("Error refreshing the 52-week range of %s: %v", code, err)
+	}
+}
+
+// rangeDistances returns how far price lies below its 52-week high and above its 52-week low,
+// in percent (0 at the high or low). Each is nil when a value is missing or the bound is not positive.
+func rangeDistances(price, high, low *float64) (offHigh, offLow *float64) {
+	if price == nil {
+		return nil, nil
+	}
+	if high != nil && *high > 0 {
+		off := (1 - *price / *high) * 100
+		offHigh = &off
+	}
+	if low != nil && *low > 0 {
+		off := (*price / *low - 1) * 100
+		offLow = &off
+	}
+	return offHigh, offLow
+}
+
+// handlerStockRangesRefresh recomputes the 52-week high and low of every stock from the stored
+// closes, for after prices were changed outside the fetch commands (admin only).
+// Usage: stock:ranges:refresh
+func handlerStockRangesRefresh(s *AppState, cmd command) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+	n, err := s.db.RefreshStockRanges52w(cmd.Context(), sql.NullString{})
+	if err != nil {
+		return fmt.Errorf("failed to refresh 52-week ranges: %w", err)
+	}
+	invalidateResponseCache(s)
+	fmt.Printf("Refreshed the 52-week range of %d stock(s).\n", n)
+	return nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// stockRatios are the ratios shown on a stock page, as strings with 4 decimal places.
+// Ratios the page does not show (or shows as "-") are NULL.
+type stockRatios struct {
+	ROE           sql.NullString
+	NTA           sql.NullString
+	DividendYield sql.NullString
+	PriceToBook   sql.NullString
+	PE            sql.NullString
+}
+
+func (r stockRatios) empty() bool {
+	return !r.ROE.Valid && !r.NTA.Valid && !r.DividendYield.Valid && !r.PriceToBook.Valid && !r.PE.Valid
+}
+
+// parseStockRatios reads the ratios from the label/value stat blocks of an i3investor stock
+// page, the same blocks the last price is read from.
+func parseStockRatios(doc *goquery.Document, stockCode string) stockRatios {
+	var ratios stockRatios
+	doc.Find("div.col-md-3.col-6").Each(func(i int, block *goquery.Selection) {
+		label := strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(block.Find("p").First().Text()), ":"))
+		var field *sql.NullString
+		switch label {
+		case "ROE", "ROE (%)":
+			field = &ratios.ROE
+		case "NTA", "NTA (RM)":
+			field = &ratios.NTA
+		case "DY", "DY (%)", "DIVIDEND YIELD", "DIVIDEND YIELD (%)":
+			field = &ratios.DividendYield
+		case "P/B", "PB", "P/B RATIO", "PRICE/BOOK":
+			field = &ratios.PriceToBook
+		case "P/E", "PE", "PER", "P/E RATIO":
+			field = &ratios.PE
+		default:
+			return
+		}
+		raw := strings.TrimSpace(block.Find("p > strong").First().Text())
+		if raw == "" || raw == "-" || strings.EqualFold(raw, "N/A") {
+			return
+		}
+		v, err := parseProfileNumber(strings.TrimSuffix(raw, "%"))
+		if err != nil {
+			log.Printf("Warning: Could not parse %s '%s' for %s: %v", label, raw, stockCode, err)
+			return
+		}
+		*field = sql.NullString{String: fmt.Sprintf("%.4f", v), Valid: true}
+	})
+	return ratios
+}
+
+// fetchStockRatios scrapes the ratios of a stock and stores them under today's market date.
+func fetchStockRatios(s *AppState, cmd command, stockCode string) (stockRatios, error) {
+	source := s.sources.Source(config.SourceI3Investor)
+	pageURL := source.BaseURL() + stockCode
+	log.Printf("Fetching ratios for %s from %s", stockCode, pageURL)
+
+	resp, err := source.Get(cmd.Context(), pageURL)
+	if err != nil {
+		return stockRatios{}, err
+	}
+	defer resp.Body.Close()
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return stockRatios{}, fmt.Errorf("failed to parse HTML from %s: %w", pageURL, err)
+	}
+
+	ratios := parseStockRatios(doc, stockCode)
+	if ratios.empty() {
+		return ratios, fmt.Errorf("could not find ROE, NTA, dividend yield, P/B or P/E on page %s", pageURL)
+	}
+	err = s.db.UpsertStockRatios(cmd.Context(), database.UpsertStockRatiosParams{
+		StockCode:       stockCode,
+		RatioDate:       markettime.Today(),
+		Roe:             ratios.ROE,
+		Nta:             ratios.NTA,
+		DividendYield:   ratios.DividendYield,
+		PriceToBook:     ratios.PriceToBook,
+		PriceToEarnings: ratios.PE,
+		SourceUrl:       pageURL,
+		FetchRunID:      fetchRunFromContext(cmd.Context()),
+	})
+	if err != nil {
+		return ratios, fmt.Errorf("failed to upsert ratios for %s: %w", stockCode, err)
+	}
+	invalidateResponseCache(s)
+	return ratios, nil
+}
+
+// --- Ratio Command Handlers ---
+
+// handlerStockFetchRatios scrapes ROE, NTA, dividend yield, P/B and P/E for the given stocks, or for
+// every tracked DEFAULT_COUNTRY stock when none are given. Re-running on the same market date
+// replaces that day's ratios; earlier days are kept as history.
+// Usage: stock:fetch:ratios [CODE...]
+func handlerStockFetchRatios(s *AppState, cmd command) error {
+	codes := make([]string, 0, len(cmd.Args))
+	for _, arg := range cmd.Args {
+		code, err := parseStockCode(cmd.Context(), s, arg)
+		if err != nil {
+			return fmt.Errorf("usage: %s [CODE...]: %w", cmd.Name, err)
+		}
+		codes = append(codes, code)
+	}
+	if len(codes) == 0 {
+		codes = activeStocks(cmd.Context(), s, trackedStocks(cmd.Context(), s, s.cfg.DefaultCountry))
+	}
+
+	run := startFetchRun(s, cmd)
+	cmd = run.attach(cmd)
+	var failures []string
+	stored := 0
+	for _, code := range codes {
+		if err := cmd.Context().Err(); err != nil {
+			run.finish(s, fetchStats{SuccessfulFetches: stored, SuccessfulStores: stored, FailedFetches: len(failures), Errors: failures}, err)
+			return err
+		}
+		var ratios stockRatios
+		err := sharedFetch(s, "stock:ratios:"+code, func() error {
+			var err error
+			ratios, err = fetchStockRatios(s, cmd, code)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to fetch ratios for %s: %v", code, err)
+			failures = append(failures, fmt.Sprintf("ratios %s: %v", code, err))
+			reportStockFetchError(cmd, code, s.sources.Source(config.SourceI3Investor).BaseURL()+code, err)
+			continue
+		}
+		fmt.Printf("%s: ROE %s, NTA %s, DY %s, P/B %s, P/E %s\n", code,
+			ratioText(ratios.ROE), ratioText(ratios.NTA), ratioText(ratios.DividendYield), ratioText(ratios.PriceToBook), ratioText(ratios.PE))
+		stored++
+	}
+	run.finish(s, fetchStats{SuccessfulFetches: stored, SuccessfulStores: stored, FailedFetches: len(failures), Errors: failures}, nil)
+	notifyDataStored(s, cmd.Name, stored)
+	notifyBatchFailures(s, cmd.Name, failures)
+	fmt.Printf("Stored ratios for %d of %d stock(s).\n", stored, len(codes))
+	if stored == 0 && len(failures) > 0 {
+		return fmt.Errorf("failed to fetch ratios for every stock")
+	}
+	return nil
+}
+
+// ratioText formats a stored ratio for display, "-" when it is NULL.
+func ratioText(v sql.NullString) string {
+	if !v.Valid {
+		return "-"
+	}
+	if f, err := strconv.ParseFloat(v.String, 64); err == nil {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return v.String
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+)
+
+// logPanic logs a recovered panic with its stack trace and reports it.
+func logPanic(name string, recovered interface{}) {
+	log.Printf("PANIC in %s: %v\n%s", name, recovered, debug.Stack())
+	errreport.CapturePanic(recovered, map[string]string{"component": name})
+}
+
+// runRecovered calls fn, turning a panic into an error so a single bad command, job run or
+// request cannot take down the long-running process.
+func runRecovered(name string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logPanic(name, r)
+			err = fmt.Errorf("internal error in %s: %v", name, r)
+		}
+	}()
+	return fn()
+}
+
+// guardGoroutine is deferred at the top of the main service goroutines (server, CLI, scheduler,
+// Telegram bot). A panic that escapes one of them is logged and triggers a graceful shutdown
+// of the rest instead of crashing the process mid-write.
+func guardGoroutine(name string, shutdown func()) {
+	if r := recover(); r != nil {
+		logPanic(name, r)
+		log.Printf("%s stopped after a panic, shutting down...", name)
+		shutdown()
+	}
+}
+
+// recoverPanics wraps the HTTP handler chain so a panicking handler gets a 500 response
+// and a logged stack trace.
+func recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler { // Deliberate abort; let net/http handle it
+					panic(rec)
+				}
+				// errreport.Middleware (inside this one) has already reported the panic
+				log.Printf("PANIC in API %s %s: %v\n%s", r.Method, r.URL.Path, rec, debug.Stack())
+				sendInternalError(w)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
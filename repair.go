@@ -0,0 +1,275 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/fxprovider"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/opendosm"
+)
+
+// errNothingRefetched stops a repair whose source returned nothing for the range, so the
+// stored rows are not deleted without replacements.
+var errNothingRefetched = errors.New("the source returned nothing for the range; stored rows left untouched")
+
+// handlerRepair replaces a series' stored observations between two dates with a fresh copy
+// from its source, for recovering from periods of known-bad scrapes. The whole range is
+// fetched first; only if that succeeds are the stored rows deleted and the fetched ones
+// stored, in one transaction. Without --apply it only reports what would be replaced (admin only).
+// Stock prices cannot be repaired: the price pages only show the latest close.
+// Usage: repair <fx:CUR|macro:SERIES> <start_date> <end_date> [--session=0900|1200|1700|all] [--apply]
+func handlerRepair(s *AppState, cmd command) error {
+	sessions, rest, err := parseFxSessionFlag(s, cmd.Args)
+	if err != nil {
+		return err
+	}
+	apply := false
+	var args []string
+	for _, arg := range rest {
+		if arg == "--apply" {
+			apply = true
+			continue
+		}
+		args = append(args, arg)
+	}
+	if len(args) != 3 {
+		return fmt.Errorf("usage: %s <fx:CUR|macro:SERIES> <start_date YYYY-MM-DD> <end_date YYYY-MM-DD> [--session=0900|1200|1700|all] [--apply]", cmd.Name)
+	}
+	start, err := markettime.ParseDate(args[1])
+	if err != nil {
+		return fmt.Errorf("failed to parse start date: %w", err)
+	}
+	end, err := markettime.ParseDate(args[2])
+	if err != nil {
+		return fmt.Errorf("failed to parse end date: %w", err)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("end date must be after start date")
+	}
+	if end.After(markettime.Today()) {
+		end = markettime.Today()
+	}
+
+	kind, code, _ := strings.Cut(args[0], ":")
+	switch strings.ToLower(kind) {
+	case watchlistFx:
+		code = strings.ToUpper(code)
+		if len(code) != 3 {
+			return fmt.Errorf("invalid currency code format: %s (must be 3 letters)", code)
+		}
+		return repairFx(s, cmd, code, sessions, start, end, apply)
+	case "macro":
+		code = strings.ToLower(code)
+		if source, ok := macroSeries[code]; !ok || source.Dataset == "" {
+			return fmt.Errorf("macro series %q is not fetched from OpenDOSM", code)
+		}
+		return repairMacro(s, cmd, code, start, end, apply)
+	case watchlistStock:
+		return fmt.Errorf("stock prices cannot be re-fetched for past dates (the price pages only show the latest close); use stock:repair:dates for misdated prices")
+	}
+	return fmt.Errorf("invalid series %q (use fx:<currency> or macro:<series>)", args[0])
+}
+
+// repairFx replaces a currency's rates of the given sessions in [start, end].
+func repairFx(s *AppState, cmd command, code string, sessions []string, start, end time.Time, apply bool) error {
+	ctx := cmd.Context()
+	stored := 0
+	for _, session := range sessions {
+		dates, err := s.db.ListForeignExchangeDates(ctx, database.ListForeignExchangeDatesParams{
+			CurrencyCode: code,
+			StartDate:    start,
+			EndDate:      end,
+			Session:      session,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list stored FX dates for %s: %w", code, err)
+		}
+		stored += len(dates)
+	}
+	series := fmt.Sprintf("fx:%s (sessions %s)", code, strings.Join(sessions, ", "))
+	if !apply {
+		return reportRepairPlan(cmd, series, stored, start, end)
+	}
+
+	run := startFetchRun(s, cmd)
+	cmd = run.attach(cmd)
+	ctx = cmd.Context()
+	var stats fetchStats
+	var rates []fxprovider.Rate
+	for _, session := range sessions {
+		provider, err := newFxProviderForSession(s, session)
+		if err != nil {
+			return finishRepair(s, cmd, run, stats, err)
+		}
+		// One calendar month per request, as bulk providers serve
+		for ws := start; !ws.After(end); {
+			we := time.Date(ws.Year(), ws.Month()+1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+			if we.After(end) {
+				we = end
+			}
+			fetched, err := provider.FetchRange(ctx, code, ws, we)
+			if err != nil {
+				stats.FailedFetches++
+				return finishRepair(s, cmd, run, stats, fmt.Errorf("failed to fetch %s session %s for %s to %s: %w",
+					code, session, ws.Format("2006-01-02"), we.Format("2006-01-02"), err))
+			}
+			stats.SuccessfulFetches++
+			rates = append(rates, fetched...)
+			ws = we.AddDate(0, 0, 1)
+		}
+	}
+	if len(rates) == 0 {
+		return finishRepair(s, cmd, run, stats, errNothingRefetched)
+	}
+
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return finishRepair(s, cmd, run, stats, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+	deleted, err := qtx.DeleteForeignExchangeRange(ctx, database.DeleteForeignExchangeRangeParams{
+		CurrencyCode: code,
+		StartDate:    start,
+		EndDate:      end,
+		Sessions:     sessions,
+	})
+	if err != nil {
+		return finishRepair(s, cmd, run, stats, fmt.Errorf("failed to delete stored rates of %s: %w", code, err))
+	}
+	params := make([]database.UpsertForeignExchangeParams, 0, len(rates))
+	for _, rate := range rates {
+		p := fxRateParams(ctx, rate)
+		if err := qtx.UpsertForeignExchange(ctx, p); err != nil {
+			stats.FailedStores++
+			return finishRepair(s, cmd, run, stats, fmt.Errorf("failed to store %s rate of %s: %w", code, rate.Date.Format("2006-01-02"), err))
+		}
+		params = append(params, p)
+	}
+	if err := tx.Commit(); err != nil {
+		return finishRepair(s, cmd, run, stats, fmt.Errorf("failed to commit repair of %s: %w", code, err))
+	}
+	stats.SuccessfulStores = len(rates)
+	invalidateResponseCache(s)
+	for i, p := range params {
+		publishFxRate(s, p, rates[i])
+	}
+	fmt.Printf("Replaced %d stored rate(s) of %s between %s and %s with %d re-fetched.\n",
+		deleted, series, start.Format("2006-01-02"), end.Format("2006-01-02"), len(rates))
+
+	// Returns from the repaired range onwards are derived from the replaced rates
+	if _, err := updateDailyReturns(ctx, s, seriesKey{Kind: watchlistFx, Code: code}, true); err != nil {
+		log.Printf("Error recomputing daily returns for fx:%s: %v", code, err)
+	}
+	runPostFetchJobs(ctx, s)
+	return finishRepair(s, cmd, run, stats, nil)
+}
+
+// repairMacro replaces a macro series' periods in [start, end]. OpenDOSM serves whole
+// datasets, so the series is downloaded in full and only the periods in the range are kept.
+func repairMacro(s *AppState, cmd command, code string, start, end time.Time, apply bool) error {
+	ctx := cmd.Context()
+	stored, err := s.db.GetMacroObservationsBySeriesAndDateRange(ctx, database.GetMacroObservationsBySeriesAndDateRangeParams{
+		Series:    code,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", code, err)
+	}
+	series := "macro:" + code
+	if !apply {
+		return reportRepairPlan(cmd, series, len(stored), start, end)
+	}
+
+	run := startFetchRun(s, cmd)
+	cmd = run.attach(cmd)
+	ctx = cmd.Context()
+	var stats fetchStats
+	source := macroSeries[code]
+	api := s.sources.Source(config.SourceOpenDOSM)
+	observations, err := opendosm.NewClient(api.BaseURL(), api).Fetch(ctx, source.Dataset, source.Filter, source.Field)
+	if err != nil {
+		stats.FailedFetches++
+		return finishRepair(s, cmd, run, stats, fmt.Errorf("failed to fetch %s: %w", code, err))
+	}
+	stats.SuccessfulFetches++
+	var inRange []opendosm.Observation
+	for _, o := range observations {
+		if !o.Date.Before(start) && !o.Date.After(end) {
+			inRange = append(inRange, o)
+		}
+	}
+	if len(inRange) == 0 {
+		return finishRepair(s, cmd, run, stats, errNothingRefetched)
+	}
+
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return finishRepair(s, cmd, run, stats, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+	deleted, err := qtx.DeleteMacroObservationsInRange(ctx, database.DeleteMacroObservationsInRangeParams{
+		Series:    code,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return finishRepair(s, cmd, run, stats, fmt.Errorf("failed to delete stored periods of %s: %w", code, err))
+	}
+	for _, o := range inRange {
+		_, err := qtx.UpsertMacroObservation(ctx, database.UpsertMacroObservationParams{
+			Series: code,
+			Period: o.Date,
+			Value:  strconv.FormatFloat(o.Value, 'f', -1, 64),
+			Source: "opendosm:" + source.Dataset,
+		})
+		if err != nil {
+			stats.FailedStores++
+			return finishRepair(s, cmd, run, stats, fmt.Errorf("failed to store %s for %s: %w", code, o.Date.Format("2006-01"), err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return finishRepair(s, cmd, run, stats, fmt.Errorf("failed to commit repair of %s: %w", code, err))
+	}
+	stats.SuccessfulStores = len(inRange)
+	invalidateResponseCache(s)
+	for _, o := range inRange {
+		publishObservation(s, "macro", code, o.Date, o.Value, "opendosm:"+source.Dataset)
+	}
+	fmt.Printf("Replaced %d stored period(s) of %s between %s and %s with %d re-fetched.\n",
+		deleted, series, start.Format("2006-01-02"), end.Format("2006-01-02"), len(inRange))
+	refreshActivityIndex(ctx, s, code)
+	runAlertsAfterFetch(ctx, s)
+	return finishRepair(s, cmd, run, stats, nil)
+}
+
+// reportRepairPlan prints what a repair without --apply would replace.
+func reportRepairPlan(cmd command, series string, stored int, start, end time.Time) error {
+	fmt.Printf("%d stored observation(s) of %s between %s and %s would be deleted and re-fetched.\n",
+		stored, series, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	fmt.Printf("Run %s with --apply to repair them.\n", cmd.Name)
+	return nil
+}
+
+// finishRepair records the outcome of a repair run, reporting a failure, and returns err.
+func finishRepair(s *AppState, cmd command, run fetchRun, stats fetchStats, err error) error {
+	if err != nil {
+		stats.Errors = append(stats.Errors, err.Error())
+		errreport.CaptureError(cmd.Context(), err, map[string]string{"command": cmd.Name})
+	}
+	run.finish(s, stats, err)
+	if err == nil {
+		notifyDataStored(s, cmd.Name, stats.SuccessfulStores)
+	}
+	return err
+}
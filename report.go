@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/go-pdf/fpdf"
+)
+
+// reportRecentRows is the number of latest observations listed in a report's data table.
+const reportRecentRows = 20
+
+// reportStats summarizes a series over the report range.
+type reportStats struct {
+	First, Last    analytics.Point
+	High, Low      analytics.Point
+	Mean           float64
+	Change         float64 // Percent, first to last
+	Volatility     float64 // Annualized, percent; 0 with fewer than two returns
+	MaxDrawdown    float64 // Percent (<= 0)
+	Observations   int
+	DrawdownPeak   time.Time
+	DrawdownTrough time.Time
+}
+
+// parseReportSeries accepts a series key (stock:1155, fx:USD), a 3-letter currency code or a stock code.
+func parseReportSeries(raw string) (seriesKey, error) {
+	if strings.Contains(raw, ":") {
+		return parseSeriesKey(raw)
+	}
+	code := normalizeStockCode(raw)
+	if currencyCodePattern.MatchString(code) {
+		return seriesKey{Kind: watchlistFx, Code: code}, nil
+	}
+	if stockCodePattern.MatchString(code) {
+		return seriesKey{Kind: watchlistStock, Code: code}, nil
+	}
+	return seriesKey{}, fmt.Errorf("invalid stock code or currency %q", raw)
+}
+
+// parseReportRange turns 30d, 6m, 1y, ytd, max or START:END (YYYY-MM-DD) into a date range ending at now.
+func parseReportRange(raw string, now time.Time) (time.Time, time.Time, error) {
+	today := markettime.DateOf(now)
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	switch raw {
+	case "ytd":
+		return time.Date(today.Year(), 1, 1, 0, 0, 0, 0, time.UTC), today, nil
+	case "max":
+		return returnsEpoch, today, nil
+	}
+	if startStr, endStr, ok := strings.Cut(raw, ":"); ok {
+		start, err := markettime.ParseDate(startStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start date %q (use YYYY-MM-DD)", startStr)
+		}
+		end, err := markettime.ParseDate(endStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end date %q (use YYYY-MM-DD)", endStr)
+		}
+		if end.Before(start) {
+			return time.Time{}, time.Time{}, fmt.Errorf("end date %s is before start date %s", endStr, startStr)
+		}
+		return start, end, nil
+	}
+	if len(raw) >= 2 {
+		n, err := strconv.Atoi(raw[:len(raw)-1])
+		if err == nil && n > 0 {
+			switch raw[len(raw)-1] {
+			case 'd':
+				return today.AddDate(0, 0, -n), today, nil
+			case 'm':
+				return today.AddDate(0, -n, 0), today, nil
+			case 'y':
+				return today.AddDate(-n, 0, 0), today, nil
+			}
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("invalid range %q (use e.g. 30d, 6m, 1y, ytd, max or YYYY-MM-DD:YYYY-MM-DD)", raw)
+}
+
+// summarizeReport computes the report statistics of points (oldest first, at least one).
+func summarizeReport(points []analytics.Point) reportStats {
+	stats := reportStats{First: points[0], Last: points[len(points)-1], High: points[0], Low: points[0], Observations: len(points)}
+	var sum float64
+	for _, p := range points {
+		sum += p.Value
+		if p.Value > stats.High.Value {
+			stats.High = p
+		}
+		if p.Value < stats.Low.Value {
+			stats.Low = p
+		}
+	}
+	stats.Mean = sum / float64(len(points))
+	if stats.First.Value != 0 {
+		stats.Change = (stats.Last.Value/stats.First.Value - 1) * 100
+	}
+	returns := analytics.DailyReturns(points)
+	if vol := analytics.RollingVolatility(returns, len(returns)); len(vol) > 0 {
+		stats.Volatility = vol[0].Value
+	}
+	_, dd := analytics.Drawdowns(points)
+	stats.MaxDrawdown, stats.DrawdownPeak, stats.DrawdownTrough = dd.MaxDrawdown, dd.PeakDate, dd.TroughDate
+	return stats
+}
+
+// generateReport writes a PDF with a chart, summary statistics and the latest observations
+// of a series over [start, end] to path.
+func generateReport(ctx context.Context, s *AppState, key seriesKey, start, end time.Time, path string) error {
+	points, err := loadSeries(ctx, s, key, start, end)
+	if err != nil {
+		return err
+	}
+	// Drop the observation carried in from before start
+	if len(points) > 0 && points[0].Date.Before(start) {
+		points = points[1:]
+	}
+	if len(points) == 0 {
+		return fmt.Errorf("no data for %s between %s and %s", key, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+	stats := summarizeReport(points)
+
+	title := "Stock " + key.Code + " closing price"
+	unit := "MYR"
+	if key.Kind == watchlistFx {
+		title = key.Code + " middle rate"
+		unit = "MYR per 1 " + key.Code
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("Malaysia-Econ-DB", true)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 9, title, "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("%s to %s (%s) - generated %s", stats.First.Date.Format("2 Jan 2006"), stats.Last.Date.Format("2 Jan 2006"),
+		unit, time.Now().Format("2 Jan 2006 15:04")), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	drawReportChart(pdf, points, 15, pdf.GetY(), 180, 80)
+	pdf.SetY(pdf.GetY() + 90)
+
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 8, "Summary", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	summary := [][2]string{
+		{"First", fmt.Sprintf("%.4f on %s", stats.First.Value, stats.First.Date.Format("2006-01-02"))},
+		{"Last", fmt.Sprintf("%.4f on %s", stats.Last.Value, stats.Last.Date.Format("2006-01-02"))},
+		{"Change", fmt.Sprintf("%+.2f%%", stats.Change)},
+		{"High", fmt.Sprintf("%.4f on %s", stats.High.Value, stats.High.Date.Format("2006-01-02"))},
+		{"Low", fmt.Sprintf("%.4f on %s", stats.Low.Value, stats.Low.Date.Format("2006-01-02"))},
+		{"Average", fmt.Sprintf("%.4f", stats.Mean)},
+		{"Annualized volatility", fmt.Sprintf("%.2f%%", stats.Volatility)},
+		{"Maximum drawdown", fmt.Sprintf("%.2f%% (%s to %s)", stats.MaxDrawdown, stats.DrawdownPeak.Format("2006-01-02"), stats.DrawdownTrough.Format("2006-01-02"))},
+		{"Observations", strconv.Itoa(stats.Observations)},
+	}
+	if stats.MaxDrawdown == 0 {
+		summary[7][1] = "none"
+	}
+	for _, row := range summary {
+		pdf.CellFormat(50, 6, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, row[1], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 8, "Recent data", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(230, 230, 230)
+	pdf.CellFormat(40, 6, "Date", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(40, 6, "Value", "1", 0, "R", true, 0, "")
+	pdf.CellFormat(40, 6, "Change", "1", 1, "R", true, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	from := max(0, len(points)-reportRecentRows)
+	for i := len(points) - 1; i >= from; i-- {
+		change := ""
+		if i > 0 && points[i-1].Value != 0 {
+			change = fmt.Sprintf("%+.2f%%", (points[i].Value/points[i-1].Value-1)*100)
+		}
+		pdf.CellFormat(40, 6, points[i].Date.Format("2006-01-02"), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(40, 6, fmt.Sprintf("%.4f", points[i].Value), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(40, 6, change, "1", 1, "R", false, 0, "")
+	}
+
+	return pdf.OutputFileAndClose(path)
+}
+
+// drawReportChart draws points as a line chart in the box at (x, y) of size w x h, with
+// the value range on the left and the date range below.
+func drawReportChart(pdf *fpdf.Fpdf, points []analytics.Point, x, y, w, h float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		lo, hi = math.Min(lo, p.Value), math.Max(hi, p.Value)
+	}
+	if hi == lo {
+		lo, hi = lo-1, hi+1
+	}
+	pad := (hi - lo) * 0.05
+	lo, hi = lo-pad, hi+pad
+	t0, t1 := points[0].Date, points[len(points)-1].Date
+	span := t1.Sub(t0).Seconds()
+
+	// Frame and horizontal grid lines with value labels
+	pdf.SetDrawColor(200, 200, 200)
+	pdf.SetLineWidth(0.2)
+	pdf.SetFont("Helvetica", "", 8)
+	labelW := 16.0
+	plotX, plotW := x+labelW, w-labelW
+	for i := 0; i <= 4; i++ {
+		gy := y + h - h*float64(i)/4
+		pdf.Line(plotX, gy, plotX+plotW, gy)
+		pdf.Text(x, gy+1, strconv.FormatFloat(lo+(hi-lo)*float64(i)/4, 'f', 2, 64))
+	}
+	pdf.Rect(plotX, y, plotW, h, "D")
+	pdf.Text(plotX, y+h+5, t0.Format("2006-01-02"))
+	pdf.Text(plotX+plotW-pdf.GetStringWidth(t1.Format("2006-01-02")), y+h+5, t1.Format("2006-01-02"))
+
+	// The series
+	pdf.SetDrawColor(0, 90, 160)
+	pdf.SetLineWidth(0.4)
+	for i, p := range points {
+		px := plotX
+		if span > 0 {
+			px += plotW * p.Date.Sub(t0).Seconds() / span
+		}
+		py := y + h - h*(p.Value-lo)/(hi-lo)
+		if i == 0 {
+			pdf.MoveTo(px, py)
+		} else {
+			pdf.LineTo(px, py)
+		}
+	}
+	pdf.DrawPath("D")
+	pdf.SetDrawColor(0, 0, 0)
+}
+
+// handlerReportGenerate writes a PDF report of a stock or currency.
+// Usage: report:generate <code|currency> <range> [--out=FILE]
+func handlerReportGenerate(s *AppState, cmd command) error {
+	var args []string
+	var out string
+	for _, arg := range cmd.Args {
+		if value, ok := strings.CutPrefix(arg, "--out="); ok {
+			out = value
+			continue
+		}
+		args = append(args, arg)
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("usage: report:generate <code|currency> <30d|6m|1y|ytd|max|YYYY-MM-DD:YYYY-MM-DD> [--out=FILE]")
+	}
+	key, err := parseReportSeries(args[0])
+	if err != nil {
+		return err
+	}
+	start, end, err := parseReportRange(args[1], time.Now())
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		out = fmt.Sprintf("report_%s_%s_%s.pdf", key.Code, start.Format("20060102"), end.Format("20060102"))
+	}
+	if err := generateReport(cmd.Context(), s, key, start, end, out); err != nil {
+		return fmt.Errorf("failed to generate report for %s: %w", key, err)
+	}
+	fmt.Printf("Wrote %s.\n", out)
+	return nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// returnsEpoch is the start of a full recomputation; it predates any stored data.
+var returnsEpoch = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// computeDailyReturns brings daily_returns up to date for every stock and currency with stored
+// data. Normally only observations newer than the last stored return are processed; with full
+// set every series is recomputed from its first observation (e.g. after prices were corrected).
+// It returns the number of returns stored.
+func computeDailyReturns(ctx context.Context, s *AppState, full bool) (int, error) {
+	stocks, err := s.db.ListStockCodesWithPrices(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list stock codes: %w", err)
+	}
+	currencies, err := s.db.ListForeignExchangeCurrencies(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list currencies: %w", err)
+	}
+	keys := make([]seriesKey, 0, len(stocks)+len(currencies))
+	for _, code := range stocks {
+		keys = append(keys, seriesKey{Kind: watchlistStock, Code: code})
+	}
+	for _, code := range currencies {
+		keys = append(keys, seriesKey{Kind: watchlistFx, Code: code})
+	}
+
+	stored := 0
+	var failed []string
+	for _, key := range keys {
+		n, err := updateDailyReturns(ctx, s, key, full)
+		if err != nil {
+			log.Printf("Error updating daily returns for %s: %v", key, err)
+			failed = append(failed, key.String())
+		}
+		stored += n
+	}
+	if len(failed) > 0 {
+		return stored, fmt.Errorf("failed to update daily returns for %s", strings.Join(failed, ", "))
+	}
+	return stored, nil
+}
+
+// updateDailyReturns stores the returns of one series from its last stored return (or from the
+// start when full is set), in a single transaction. The last return is always recomputed, since
+// a later fetch may replace that day's price. Observations stored behind it (fx:fetch:range,
+// --missing-only, ingested history) and closes replaced after their return was computed move
+// the start back to the earliest of them, so they get fresh returns and the return after them
+// a new base.
+func updateDailyReturns(ctx context.Context, s *AppState, key seriesKey, full bool) (int, error) {
+	start := returnsEpoch
+	if !full {
+		latest, err := s.db.GetLatestDailyReturnDate(ctx, key.String())
+		if err != nil && err != sql.ErrNoRows {
+			return 0, fmt.Errorf("failed to load last stored return: %w", err)
+		}
+		if err == nil {
+			stale, err := earliestStaleReturnDate(ctx, s, key)
+			if err != nil && err != sql.ErrNoRows {
+				return 0, fmt.Errorf("failed to look for backfilled or replaced observations: %w", err)
+			}
+			start = incrementalReturnsStart(latest, stale)
+		}
+	}
+
+	// loadSeries includes the last observation before start, so the first new return has a base
+	points, err := loadSeries(ctx, s, key, start, markettime.Today())
+	if err != nil {
+		return 0, err
+	}
+	returns := analytics.DailyReturns(points)
+	if len(returns) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+	for _, r := range returns {
+		err := qtx.UpsertDailyReturn(ctx, database.UpsertDailyReturnParams{
+			Series:    key.String(),
+			Date:      r.Date,
+			PctReturn: fmt.Sprintf("%.8f", r.Value),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to store return for %s: %w", r.Date.Format("2006-01-02"), err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit returns: %w", err)
+	}
+	invalidateResponseCache(s)
+	return len(returns), nil
+}
+
+// incrementalReturnsStart is the first date whose return an incremental update recomputes: the
+// date of the last stored return, or stale when that is earlier. A zero stale means none.
+func incrementalReturnsStart(latest, stale time.Time) time.Time {
+	if !stale.IsZero() && stale.Before(latest) {
+		return stale
+	}
+	return latest
+}
+
+// earliestStaleReturnDate returns the earliest observation of a series that follows another
+// but has no stored return or, for stocks, was stored after its return was computed. It
+// returns sql.ErrNoRows when there is none. Rates are only checked for missing returns:
+// foreign_exchange.created_at is the fetching host's wall clock, not comparable with the
+// database's computed_at.
+func earliestStaleReturnDate(ctx context.Context, s *AppState, key seriesKey) (time.Time, error) {
+	if key.Kind == watchlistFx {
+		return s.db.GetEarliestFxDateWithoutReturn(ctx, database.GetEarliestFxDateWithoutReturnParams{
+			CurrencyCode: key.Code,
+			Series:       key.String(),
+		})
+	}
+	return s.db.GetEarliestStockDateWithStaleReturn(ctx, database.GetEarliestStockDateWithStaleReturnParams{
+		StockCode: key.Code,
+		Series:    key.String(),
+	})
+}
+
+// runPostFetchJobs brings derived data up to date and evaluates alerts at the end of a fetch
+// cycle. Like runAlertsAfterFetch, failures are logged rather than returned.
+func runPostFetchJobs(ctx context.Context, s *AppState) {
+	n, err := computeDailyReturns(ctx, s, false)
+	if err != nil {
+		log.Printf("Error computing daily returns: %v", err)
+	}
+	if n > 0 {
+		log.Printf("Stored %d daily return(s).", n)
+	}
+	// Volatility is derived from the returns, so it runs even if some series failed above
+	n, err = computeRollingVolatility(ctx, s, false)
+	if err != nil {
+		log.Printf("Error computing rolling volatility: %v", err)
+	}
+	if n > 0 {
+		log.Printf("Stored %d rolling volatility value(s).", n)
+	}
+	n, err = computeBasketValues(ctx, s)
+	if err != nil {
+		log.Printf("Error computing basket values: %v", err)
+	}
+	if n > 0 {
+		log.Printf("Stored %d basket index value(s).", n)
+	}
+	if _, err := refreshSectorFlows(ctx, s, markettime.Today().AddDate(0, 0, -sectorFlowRefreshDays)); err != nil {
+		log.Printf("Error refreshing sector flows: %v", err)
+	}
+	runAlertsAfterFetch(ctx, s)
+}
+
+// handlerReturnsCompute updates the derived daily returns on demand.
+// Usage: returns:compute [--full]
+func handlerReturnsCompute(s *AppState, cmd command) error {
+	full := false
+	switch {
+	case len(cmd.Args) == 0:
+	case len(cmd.Args) == 1 && cmd.Args[0] == "--full":
+		full = true
+	default:
+		return fmt.Errorf("usage: %s [--full]", cmd.Name)
+	}
+	n, err := computeDailyReturns(cmd.Context(), s, full)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Stored %d daily returns.\n", n)
+	return nil
+}
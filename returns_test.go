@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+)
+
+func TestIncrementalReturnsStart(t *testing.T) {
+	latest := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		stale time.Time
+		want  time.Time
+	}{
+		{name: "nothing stale", want: latest},
+		{name: "backfilled before the last return", stale: latest.AddDate(0, 0, -10), want: latest.AddDate(0, 0, -10)},
+		{name: "last day replaced", stale: latest, want: latest},
+		{name: "stale after the last return", stale: latest.AddDate(0, 0, 1), want: latest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := incrementalReturnsStart(latest, tt.stale); !got.Equal(tt.want) {
+				t.Errorf("incrementalReturnsStart(%s, %s) = %s, want %s", latest.Format("2006-01-02"), tt.stale.Format("2006-01-02"), got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+// An intraday close replaced by the post-close fetch must not keep the return computed from it.
+func TestIncrementalReturnsRecomputeReplacedLastDay(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	closes := []analytics.Point{{Date: day(11), Value: 10}, {Date: day(12), Value: 10}, {Date: day(13), Value: 11}}
+	stored := make(map[time.Time]float64)
+	for _, r := range analytics.DailyReturns(closes) {
+		stored[r.Date] = r.Value
+	}
+
+	closes[2].Value = 12 // The post-close fetch replaces the intraday close
+	start := incrementalReturnsStart(day(13), time.Time{})
+	for _, r := range analytics.DailyReturns(seriesFrom(closes, start)) {
+		stored[r.Date] = r.Value
+	}
+
+	if got, want := stored[day(13)], 20.0; got < want-1e-9 || got > want+1e-9 {
+		t.Errorf("return on the replaced day = %v, want %v", got, want)
+	}
+	if got := stored[day(12)]; got != 0 {
+		t.Errorf("return on the day before = %v, want 0", got)
+	}
+}
+
+// seriesFrom returns the points from start preceded by the last one before it, as loadSeries does.
+func seriesFrom(points []analytics.Point, start time.Time) []analytics.Point {
+	for i, p := range points {
+		if !p.Date.Before(start) {
+			return points[max(i-1, 0):]
+		}
+	}
+	return nil
+}
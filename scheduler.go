@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// scheduledJob is a background task run by the scheduler at a fixed interval.
+type scheduledJob struct {
+	Name            string
+	Interval        time.Duration
+	TradingDaysOnly bool // Skipped on weekends and market holidays, when there is no new market data
+	Run             func(ctx context.Context, s *AppState) error
+}
+
+// scheduledJobs returns the jobs enabled by the current configuration.
+// Jobs with a zero interval are disabled.
+func scheduledJobs(s *AppState) []scheduledJob {
+	jobs := []scheduledJob{
+		{
+			Name:            "fx:eer:compute",
+			Interval:        s.cfg.EERRecalcInterval,
+			TradingDaysOnly: true,
+			Run: func(ctx context.Context, s *AppState) error {
+				n, err := runEffectiveExchangeRates(ctx, s)
+				if err == nil {
+					log.Printf("Scheduler: stored %d NEER observations.", n)
+				}
+				return err
+			},
+		},
+		{
+			Name:            "stock:intraday:poll",
+			Interval:        s.cfg.IntradayInterval,
+			TradingDaysOnly: true,
+			Run:             runIntradayPoll,
+		},
+		{
+			Name:            "correlation:compute",
+			Interval:        s.cfg.CorrelationInterval,
+			TradingDaysOnly: true,
+			Run: func(ctx context.Context, s *AppState) error {
+				n, err := refreshStoredCorrelations(ctx, s)
+				if err == nil {
+					log.Printf("Scheduler: stored %d correlation pairs.", n)
+				}
+				return err
+			},
+		},
+		{
+			Name:     "digest:send",
+			Interval: digestInterval(s),
+			Run: func(ctx context.Context, s *AppState) error {
+				until := time.Now().UTC()
+				n, err := sendDigests(ctx, s, until.Add(-s.cfg.DigestInterval), until)
+				log.Printf("Scheduler: sent %d digest email(s).", n)
+				return err
+			},
+		},
+		{
+			Name:     "market:holidays:fetch",
+			Interval: holidaysInterval(s),
+			Run: func(ctx context.Context, s *AppState) error {
+				n, err := fetchMarketHolidays(ctx, s)
+				if err == nil {
+					log.Printf("Scheduler: stored %d market holidays.", n)
+				}
+				return err
+			},
+		},
+		{
+			Name:     "entitlements:fetch",
+			Interval: entitlementsInterval(s),
+			Run: func(ctx context.Context, s *AppState) error {
+				n, err := fetchEntitlements(ctx, s)
+				log.Printf("Scheduler: stored %d entitlement(s).", n)
+				return err
+			},
+		},
+		{
+			Name:     "news:fetch",
+			Interval: newsInterval(s),
+			Run: func(ctx context.Context, s *AppState) error {
+				n, err := fetchNews(ctx, s)
+				log.Printf("Scheduler: stored %d news headline(s).", n)
+				return err
+			},
+		},
+		{
+			Name:     "publish:run",
+			Interval: publishInterval(s),
+			Run: func(ctx context.Context, s *AppState) error {
+				n, err := publishDatasets(ctx, s)
+				if err == nil {
+					log.Printf("Scheduler: published %d series.", n)
+				}
+				return err
+			},
+		},
+		{
+			Name:     "sheets:push",
+			Interval: sheetsInterval(s),
+			Run: func(ctx context.Context, s *AppState) error {
+				n, err := pushSheets(ctx, s)
+				if err == nil {
+					log.Printf("Scheduler: pushed %d row(s) to Google Sheets.", n)
+				}
+				return err
+			},
+		},
+		{
+			Name:     "db:maintenance",
+			Interval: s.cfg.MaintenanceInterval,
+			Run: func(ctx context.Context, s *AppState) error {
+				res, err := recordedMaintenance(s, command{Name: "db:maintenance", ctx: ctx})
+				log.Printf("Scheduler: maintenance ran %d step(s), %d failed.", res.StepsSucceeded+res.StepsFailed, res.StepsFailed)
+				return err
+			},
+		},
+		{
+			Name:     "snapshot:export",
+			Interval: snapshotInterval(s),
+			Run: func(ctx context.Context, s *AppState) error {
+				n, err := exportSnapshot(ctx, s)
+				if err == nil {
+					log.Printf("Scheduler: exported snapshot of %d tables.", n)
+				}
+				return err
+			},
+		},
+	}
+
+	var enabled []scheduledJob
+	for _, job := range jobs {
+		if job.Interval > 0 {
+			enabled = append(enabled, job)
+		}
+	}
+	return enabled
+}
+
+// snapshotInterval is the snapshot export interval, or 0 when no bucket is configured.
+func snapshotInterval(s *AppState) time.Duration {
+	if s.cfg.SnapshotBucket == "" {
+		return 0
+	}
+	return s.cfg.SnapshotInterval
+}
+
+// isTradingToday reports whether today is a trading day. If the holidays cannot be loaded
+// only weekends count as closed.
+func isTradingToday(ctx context.Context, s *AppState) bool {
+	cal, err := loadTradingCalendar(ctx, s)
+	if err != nil {
+		log.Printf("Scheduler: %v", err)
+	}
+	return cal.IsTradingDay(markettime.Today())
+}
+
+// runScheduler starts one ticker goroutine per enabled job and returns when ctx is cancelled.
+func runScheduler(ctx context.Context, wg *sync.WaitGroup, appState *AppState) {
+	defer wg.Done()
+
+	jobs := scheduledJobs(appState)
+	if len(jobs) == 0 {
+		log.Println("Scheduler: no jobs enabled.")
+		return
+	}
+
+	var jobsWg sync.WaitGroup
+	for _, job := range jobs {
+		jobsWg.Add(1)
+		go func(job scheduledJob) {
+			defer jobsWg.Done()
+			log.Printf("Scheduler: running %s every %s", job.Name, job.Interval)
+			ticker := time.NewTicker(job.Interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if job.TradingDaysOnly && !isTradingToday(ctx, appState) {
+						log.Printf("Scheduler: skipping %s, the market is closed today", job.Name)
+						continue
+					}
+					start := time.Now()
+					// Runs past shutdown for the grace period, so it can finish and record its run
+					jobCtx, cancel := withCommandTimeout(appState.workCtx, appState)
+					err := runRecovered("scheduled "+job.Name, func() error { return job.Run(jobCtx, appState) })
+					cancel()
+					if err != nil {
+						log.Printf("Scheduler: %s failed after %s: %v", job.Name, time.Since(start).Round(time.Millisecond), err)
+						notifyBatchFailures(appState, "scheduled "+job.Name, []string{err.Error()})
+						errreport.CaptureError(ctx, err, map[string]string{"job": job.Name})
+						continue
+					}
+					log.Printf("Scheduler: %s finished in %s", job.Name, time.Since(start).Round(time.Millisecond))
+				}
+			}
+		}(job)
+	}
+
+	<-ctx.Done()
+	log.Println("Scheduler: shutdown signal received, waiting for running jobs...")
+	jobsWg.Wait()
+	log.Println("Scheduler stopped.")
+}
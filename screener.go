@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Screener fields: numeric ones compare with <, <=, >, >=, = and !=; text ones with = and !=
+// (case-insensitive).
+var (
+	screenerNumericFields = []string{"pe", "dy", "pb", "roe", "price", "off_high", "off_low"}
+	screenerTextFields    = []string{"sector", "subsector", "country"}
+)
+
+// Structure for one stock matching a screen
+type ScreenerResult struct {
+	StockCode     string   `json:"stock_code"`
+	CompanyName   string   `json:"company_name"`
+	Country       string   `json:"country,omitempty"`
+	Sector        string   `json:"sector,omitempty"`
+	Subsector     string   `json:"subsector,omitempty"`
+	RatioDate     string   `json:"ratio_date,omitempty"` // Date of the ratios; empty when none are stored
+	PE            *float64 `json:"pe"`
+	DividendYield *float64 `json:"dy"` // Percent
+	PriceToBook   *float64 `json:"pb"`
+	ROE           *float64 `json:"roe"` // Percent
+	PriceDate     string   `json:"price_date,omitempty"`
+	Price         *float64 `json:"price"`
+	High52w       *float64 `json:"high_52w"` // Highest close of the 52 weeks to price_date
+	Low52w        *float64 `json:"low_52w"`  // Lowest close of the 52 weeks to price_date
+	OffHigh       *float64 `json:"off_high"` // Percent below high_52w (0 at the high)
+	OffLow        *float64 `json:"off_low"`  // Percent above low_52w (0 at the low)
+}
+
+// screenerFilter is one parsed filter expression, e.g. pe<15 or sector=Financial Services.
+type screenerFilter struct {
+	Field string
+	Op    string
+	Value float64 // Numeric fields
+	Text  string  // Text fields
+}
+
+// parseScreenerFilters parses a comma-separated list of filter expressions.
+func parseScreenerFilters(raw string) ([]screenerFilter, error) {
+	var filters []screenerFilter
+	for _, expr := range strings.Split(raw, ",") {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		f, err := parseScreenerFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+func parseScreenerFilter(expr string) (screenerFilter, error) {
+	opAt := strings.IndexAny(expr, "<>=!")
+	if opAt <= 0 {
+		return screenerFilter{}, fmt.Errorf("invalid filter %q (use <field><op><value>, e.g. pe<15)", expr)
+	}
+	op := expr[opAt : opAt+1]
+	if two := expr[opAt:min(opAt+2, len(expr))]; two == ">=" || two == "<=" || two == "!=" {
+		op = two
+	} else if op == "!" {
+		return screenerFilter{}, fmt.Errorf("invalid operator in filter %q (use <, <=, >, >=, = or !=)", expr)
+	}
+	f := screenerFilter{
+		Field: strings.ToLower(strings.TrimSpace(expr[:opAt])),
+		Op:    op,
+		Text:  strings.TrimSpace(expr[opAt+len(op):]),
+	}
+	switch {
+	case slices.Contains(screenerNumericFields, f.Field):
+		v, err := strconv.ParseFloat(f.Text, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return screenerFilter{}, fmt.Errorf("invalid number %q in filter %q", f.Text, expr)
+		}
+		f.Value = v
+	case slices.Contains(screenerTextFields, f.Field):
+		if f.Op != "=" && f.Op != "!=" {
+			return screenerFilter{}, fmt.Errorf("%s can only be compared with = or != in filter %q", f.Field, expr)
+		}
+	default:
+		return screenerFilter{}, fmt.Errorf("unknown field %q in filter %q (use %s or %s)", f.Field, expr,
+			strings.Join(screenerNumericFields, ", "), strings.Join(screenerTextFields, ", "))
+	}
+	return f, nil
+}
+
+// numeric returns the value of a numeric field of r, or nil when it is not stored.
+func (r ScreenerResult) numeric(field string) *float64 {
+	switch field {
+	case "pe":
+		return r.PE
+	case "dy":
+		return r.DividendYield
+	case "pb":
+		return r.PriceToBook
+	case "roe":
+		return r.ROE
+	case "price":
+		return r.Price
+	case "off_high":
+		return r.OffHigh
+	case "off_low":
+		return r.OffLow
+	}
+	return nil
+}
+
+// matches reports whether r passes f. A stock without the field stored never matches.
+func (f screenerFilter) matches(r ScreenerResult) bool {
+	if slices.Contains(screenerTextFields, f.Field) {
+		value := map[string]string{"sector": r.Sector, "subsector": r.Subsector, "country": r.Country}[f.Field]
+		return strings.EqualFold(value, f.Text) == (f.Op == "=")
+	}
+	v := r.numeric(f.Field)
+	if v == nil {
+		return false
+	}
+	switch f.Op {
+	case ">=":
+		return *v >= f.Value
+	case "<=":
+		return *v <= f.Value
+	case ">":
+		return *v > f.Value
+	case "<":
+		return *v < f.Value
+	case "!=":
+		return *v != f.Value
+	}
+	return *v == f.Value
+}
+
+// screenStocks returns the companies passing every filter, ordered by sortField ("code" or a
+// numeric field; stocks without it last), descending when desc is set.
+func screenStocks(ctx context.Context, s *AppState, filters []screenerFilter, sortField string, desc bool) ([]ScreenerResult, error) {
+	rows, err := s.db.ListScreenerRows(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load screener data: %w", err)
+	}
+	results := []ScreenerResult{}
+	for _, row := range rows {
+		r := ScreenerResult{
+			StockCode:     row.StockCode,
+			CompanyName:   row.CompanyName,
+			Country:       row.CountryCode.String,
+			Sector:        row.Sector.String,
+			Subsector:     row.Subsector.String,
+			PE:            nullableFloat(row.PriceToEarnings),
+			DividendYield: nullableFloat(row.DividendYield),
+			PriceToBook:   nullableFloat(row.PriceToBook),
+			ROE:           nullableFloat(row.Roe),
+			Price:         nullableFloat(row.ClosingPrice),
+			High52w:       nullableFloat(row.High52w),
+			Low52w:        nullableFloat(row.Low52w),
+		}
+		if row.RatioDate.Valid {
+			r.RatioDate = row.RatioDate.Time.Format("2006-01-02")
+		}
+		if row.PriceDate.Valid {
+			r.PriceDate = row.PriceDate.Time.Format("2006-01-02")
+		}
+		r.OffHigh, r.OffLow = rangeDistances(r.Price, r.High52w, r.Low52w)
+		passes := true
+		for _, f := range filters {
+			if !f.matches(r) {
+				passes = false
+				break
+			}
+		}
+		if passes {
+			results = append(results, r)
+		}
+	}
+
+	if sortField != "code" {
+		sort.SliceStable(results, func(i, j int) bool {
+			a, b := results[i].numeric(sortField), results[j].numeric(sortField)
+			if a == nil || b == nil {
+				return b == nil && a != nil
+			}
+			if desc {
+				return *a > *b
+			}
+			return *a < *b
+		})
+	} else if desc {
+		sort.SliceStable(results, func(i, j int) bool { return results[i].StockCode > results[j].StockCode })
+	}
+	return results, nil
+}
+
+// nullableFloat parses a stored decimal, nil when it is NULL or unparseable.
+func nullableFloat(v sql.NullString) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	f, err := strconv.ParseFloat(v.String, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// --- Screener Command Handlers ---
+
+// handlerScreener lists the stocks passing every filter expression.
+// Usage: screener [FILTER...] [--sort=FIELD] [--desc] [--top=N]  e.g. screener "sector=Financial Services" pe<15 dy>=4
+func handlerScreener(s *AppState, cmd command) error {
+	usage := fmt.Errorf("usage: %s [FILTER...] [--sort=code|%s] [--desc] [--top=N]", cmd.Name, strings.Join(screenerNumericFields, "|"))
+	var filters []screenerFilter
+	sortField, desc, top := "code", false, 50
+	for _, arg := range cmd.Args {
+		switch {
+		case strings.HasPrefix(arg, "--sort="):
+			sortField = strings.ToLower(strings.TrimPrefix(arg, "--sort="))
+			if sortField != "code" && !slices.Contains(screenerNumericFields, sortField) {
+				return usage
+			}
+		case arg == "--desc":
+			desc = true
+		case strings.HasPrefix(arg, "--top="):
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "--top="))
+			if err != nil || n < 1 {
+				return usage
+			}
+			top = n
+		default:
+			f, err := parseScreenerFilter(arg)
+			if err != nil {
+				return err
+			}
+			filters = append(filters, f)
+		}
+	}
+
+	results, err := screenStocks(cmd.Context(), s, filters, sortField, desc)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d stock(s) match.\n", len(results))
+	for i, r := range results {
+		if i == top {
+			break
+		}
+		fmt.Printf("  %-8s %-30.30s P/E %-8s DY %-7s P/B %-7s price %-9s %s below 52w high, %s above low\n", r.StockCode, r.CompanyName,
+			screenerText(r.PE), screenerText(r.DividendYield), screenerText(r.PriceToBook), screenerText(r.Price),
+			screenerText(r.OffHigh)+"%", screenerText(r.OffLow)+"%")
+	}
+	return nil
+}
+
+// screenerText formats a screener value for display, "-" when it is not stored.
+func screenerText(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*v, 'f', 2, 64)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+)
+
+// handlerSearch searches companies, news headlines, events and report documents.
+// Usage: search [--country=XX] <terms...>
+func handlerSearch(s *AppState, cmd command) error {
+	args, country, err := takeCountryFlag(s, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s [--country=XX] <terms...>", cmd.Name)
+	}
+	query := strings.Join(args, " ")
+	rows, err := s.db.Search(cmd.Context(), database.SearchParams{
+		Query:      query,
+		Country:    sql.NullString{String: country, Valid: country != ""},
+		MaxResults: 20,
+	})
+	if err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+	if len(rows) == 0 {
+		fmt.Printf("Nothing matches %q.\n", query)
+		return nil
+	}
+	for _, row := range rows {
+		item := searchResultFromDB(row, s.cfg.BasePath)
+		date := ""
+		if item.Date != nil {
+			date = item.Date.Format("2006-01-02")
+		}
+		fmt.Printf("  %-8s %-10s %-6s %s\n", item.Type, date, item.StockCode, item.Title)
+	}
+	return nil
+}
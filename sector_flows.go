@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// sectorFlowRefreshDays is how far back the post-fetch jobs re-derive the sector flows, so
+// closes stored late or corrected within the week are picked up.
+const sectorFlowRefreshDays = 7
+
+// Structure for one sector's traded value over a range
+type SectorFlowTotal struct {
+	Sector      string          `json:"sector"`
+	TradedValue float64         `json:"traded_value"` // Sum of close times volume, in MYR
+	Volume      int64           `json:"volume"`       // Shares traded
+	SharePct    float64         `json:"share_pct"`    // Percent of the traded value of all sectors
+	Days        []SectorFlowDay `json:"days"`
+}
+
+// Structure for one sector's traded value on one date
+type SectorFlowDay struct {
+	Date        string  `json:"date"`
+	TradedValue float64 `json:"traded_value"`
+	Volume      int64   `json:"volume"`
+	Stocks      int32   `json:"stocks"` // Stocks with a captured volume
+}
+
+// refreshSectorFlows re-derives the sector flows from start on in one transaction and returns
+// the number of sector-days stored.
+func refreshSectorFlows(ctx context.Context, s *AppState, start time.Time) (int64, error) {
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+	if _, err := qtx.DeleteSectorFlowsSince(ctx, start); err != nil {
+		return 0, fmt.Errorf("failed to clear sector flows: %w", err)
+	}
+	n, err := qtx.InsertSectorFlowsSince(ctx, start)
+	if err != nil {
+		return 0, fmt.Errorf("failed to derive sector flows: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// sectorFlowTotals sums the stored sector flows of [start, end] per sector, largest traded
+// value first.
+func sectorFlowTotals(ctx context.Context, s *AppState, start, end time.Time) ([]SectorFlowTotal, error) {
+	rows, err := s.db.GetSectorFlowsByDateRange(ctx, database.GetSectorFlowsByDateRangeParams{StartDate: start, EndDate: end})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sector flows: %w", err)
+	}
+	totals := []SectorFlowTotal{}
+	index := make(map[string]int)
+	var all float64
+	for _, row := range rows {
+		value, err := strconv.ParseFloat(row.TradedValue, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid traded value of %s on %s: %w", row.Sector, row.FlowDate.Format("2006-01-02"), err)
+		}
+		i, ok := index[row.Sector]
+		if !ok {
+			i = len(totals)
+			index[row.Sector] = i
+			totals = append(totals, SectorFlowTotal{Sector: row.Sector})
+		}
+		totals[i].TradedValue += value
+		totals[i].Volume += row.Volume
+		totals[i].Days = append(totals[i].Days, SectorFlowDay{
+			Date:        row.FlowDate.Format("2006-01-02"),
+			TradedValue: value,
+			Volume:      row.Volume,
+			Stocks:      row.Stocks,
+		})
+		all += value
+	}
+	for i := range totals {
+		if all > 0 {
+			totals[i].SharePct = totals[i].TradedValue / all * 100
+		}
+	}
+	sort.SliceStable(totals, func(i, j int) bool { return totals[i].TradedValue > totals[j].TradedValue })
+	return totals, nil
+}
+
+// --- Sector Flow Command Handlers ---
+
+// handlerSectorsFlows ranks the sectors by traded value (close times volume) over the last
+// --days (default 7).
+// Usage: sectors:flows [--days=N]
+func handlerSectorsFlows(s *AppState, cmd command) error {
+	days := 7
+	for _, arg := range cmd.Args {
+		value, ok := strings.CutPrefix(arg, "--days=")
+		n, err := strconv.Atoi(value)
+		if !ok || err != nil || n < 1 {
+			return fmt.Errorf("usage: %s [--days=N]", cmd.Name)
+		}
+		days = n
+	}
+	end := markettime.Today()
+	start := end.AddDate(0, 0, -(days - 1))
+	totals, err := sectorFlowTotals(cmd.Context(), s, start, end)
+	if err != nil {
+		return err
+	}
+	if len(totals) == 0 {
+		fmt.Printf("No traded volume stored between %s and %s.\n", start.Format("2006-01-02"), end.Format("2006-01-02"))
+		return nil
+	}
+	fmt.Printf("Traded value by sector, %s to %s:\n", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	for i, t := range totals {
+		fmt.Printf("%3d. %-40s %18.2f %6.2f%%\n", i+1, t.Sector, t.TradedValue, t.SharePct)
+	}
+	return nil
+}
+
+// handlerSectorsFlowsRefresh re-derives the sector flows from a date on (default: all stored
+// closes), e.g. after sectors were re-normalized or prices repaired (admin only).
+// Usage: sectors:flows:refresh [start_date]
+func handlerSectorsFlowsRefresh(s *AppState, cmd command) error {
+	if len(cmd.Args) > 1 {
+		return fmt.Errorf("usage: %s [start_date YYYY-MM-DD]", cmd.Name)
+	}
+	var start time.Time
+	if len(cmd.Args) == 1 {
+		var err error
+		if start, err = markettime.ParseDate(cmd.Args[0]); err != nil {
+			return fmt.Errorf("failed to parse start date: %w", err)
+		}
+	}
+	n, err := refreshSectorFlows(cmd.Context(), s, start)
+	if err != nil {
+		return err
+	}
+	invalidateResponseCache(s)
+	fmt.Printf("Stored %d sector flow(s).\n", n)
+	return nil
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+)
+
+// Kinds of sector label, as stored in sector_mappings.kind.
+const (
+	sectorKind    = "sector"
+	subsectorKind = "subsector"
+)
+
+// sectorTaxonomy normalizes scraped sector and subsector labels to the canonical names in the
+// sectors and subsectors tables.
+type sectorTaxonomy struct {
+	names    map[string]map[string]string // kind -> label key -> canonical name
+	mappings map[string]map[string]string // kind -> label key -> canonical name, from sector_mappings
+}
+
+// sectorLabelKey is the form labels are matched in: lower-cased with whitespace collapsed.
+func sectorLabelKey(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// loadSectorTaxonomy loads the canonical sectors, subsectors and mapping rules.
+func loadSectorTaxonomy(ctx context.Context, s *AppState) (*sectorTaxonomy, error) {
+	t := &sectorTaxonomy{
+		names:    map[string]map[string]string{sectorKind: {}, subsectorKind: {}},
+		mappings: map[string]map[string]string{sectorKind: {}, subsectorKind: {}},
+	}
+	sectors, err := s.db.ListSectors(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sectors: %w", err)
+	}
+	for _, name := range sectors {
+		t.names[sectorKind][sectorLabelKey(name)] = name
+	}
+	subsectors, err := s.db.ListSubsectors(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load subsectors: %w", err)
+	}
+	for _, sub := range subsectors {
+		t.names[subsectorKind][sectorLabelKey(sub.Name)] = sub.Name
+	}
+	mappings, err := s.db.ListSectorMappings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sector mappings: %w", err)
+	}
+	for _, m := range mappings {
+		t.mappings[m.Kind][m.Label] = m.Canonical
+	}
+	return t, nil
+}
+
+// canonical returns the canonical name for a scraped label and whether one is known: the
+// mapped name when a mapping rule matches, else the canonical name the label differs from
+// only in case and spacing. Unknown labels come back with their whitespace tidied.
+func (t *sectorTaxonomy) canonical(kind, label string) (string, bool) {
+	key := sectorLabelKey(label)
+	if name, ok := t.mappings[kind][key]; ok {
+		return name, true
+	}
+	if name, ok := t.names[kind][key]; ok {
+		return name, true
+	}
+	return strings.Join(strings.Fields(label), " "), false
+}
+
+// normalize returns the canonical form of a nullable scraped label.
+func (t *sectorTaxonomy) normalize(kind string, label sql.NullString) sql.NullString {
+	if !label.Valid || strings.TrimSpace(label.String) == "" {
+		return sql.NullString{}
+	}
+	name, _ := t.canonical(kind, label.String)
+	return sql.NullString{String: name, Valid: true}
+}
+
+// normalizeCompanySectors re-derives every company's sector and subsector from the scraped
+// labels with the current taxonomy, returning the number of companies changed.
+func normalizeCompanySectors(ctx context.Context, s *AppState) (int, error) {
+	t, err := loadSectorTaxonomy(ctx, s)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := s.db.ListCompanySectorLabels(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load company sectors: %w", err)
+	}
+	changed := 0
+	for _, row := range rows {
+		if !row.SectorRaw.Valid && !row.SubsectorRaw.Valid {
+			continue // Registered without a scraped profile; keep whatever was set
+		}
+		sector, subsector := t.normalize(sectorKind, row.SectorRaw), t.normalize(subsectorKind, row.SubsectorRaw)
+		if sector == row.Sector && subsector == row.Subsector {
+			continue
+		}
+		err := s.db.SetCompanySectors(ctx, database.SetCompanySectorsParams{
+			Sector:    sector,
+			Subsector: subsector,
+			StockCode: row.StockCode,
+		})
+		if err != nil {
+			return changed, fmt.Errorf("failed to update sectors of %s: %w", row.StockCode, err)
+		}
+		changed++
+	}
+	if changed > 0 {
+		// Flows are aggregated by sector, so every stored day may have moved
+		if _, err := refreshSectorFlows(ctx, s, time.Time{}); err != nil {
+			log.Printf("Error refreshing sector flows: %v", err)
+		}
+		invalidateResponseCache(s)
+	}
+	return changed, nil
+}
+
+// handlerSectors lists the canonical sectors and subsectors with their company counts, then the
+// scraped labels that match none of them, for review with sectors:merge.
+// Usage: sectors
+func handlerSectors(s *AppState, cmd command) error {
+	ctx := cmd.Context()
+	t, err := loadSectorTaxonomy(ctx, s)
+	if err != nil {
+		return err
+	}
+	subsectors, err := s.db.ListSubsectors(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load subsectors: %w", err)
+	}
+	rows, err := s.db.ListCompanySectorLabels(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load company sectors: %w", err)
+	}
+
+	counts := map[string]map[string]int{sectorKind: {}, subsectorKind: {}}
+	unknown := map[string]map[string]int{sectorKind: {}, subsectorKind: {}}
+	for _, row := range rows {
+		for kind, label := range map[string]sql.NullString{sectorKind: row.SectorRaw, subsectorKind: row.SubsectorRaw} {
+			if !label.Valid || strings.TrimSpace(label.String) == "" {
+				continue
+			}
+			name, known := t.canonical(kind, label.String)
+			counts[kind][name]++
+			if !known {
+				unknown[kind][name]++
+			}
+		}
+	}
+
+	sectorNames := make([]string, 0, len(t.names[sectorKind]))
+	for _, name := range t.names[sectorKind] {
+		sectorNames = append(sectorNames, name)
+	}
+	sort.Strings(sectorNames)
+	for _, name := range sectorNames {
+		fmt.Printf("%-40s %4d companies\n", name, counts[sectorKind][name])
+		for _, sub := range subsectors {
+			if sub.Sector == name {
+				fmt.Printf("  %-38s %4d companies\n", sub.Name, counts[subsectorKind][sub.Name])
+			}
+		}
+	}
+
+	for _, kind := range []string{sectorKind, subsectorKind} {
+		if len(unknown[kind]) == 0 {
+			continue
+		}
+		labels := make([]string, 0, len(unknown[kind]))
+		for label := range unknown[kind] {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		fmt.Printf("Unrecognised %s labels (merge with sectors:merge or add with sectors:add):\n", kind)
+		for _, label := range labels {
+			fmt.Printf("  %-38s %4d companies\n", label, unknown[kind][label])
+		}
+	}
+	return nil
+}
+
+// handlerSectorsAdd adds a canonical sector, or a subsector of one after a "/", and
+// re-normalizes the companies whose scraped label it matches (admin only).
+// Usage: sectors:add <sector> [/ <subsector>]   e.g. sectors:add Financial Services / Banking
+func handlerSectorsAdd(s *AppState, cmd command) error {
+	sector, subsector, hasSub := strings.Cut(strings.Join(cmd.Args, " "), "/")
+	sector, subsector = strings.TrimSpace(sector), strings.TrimSpace(subsector)
+	if sector == "" || (hasSub && subsector == "") {
+		return fmt.Errorf("usage: %s <sector> [/ <subsector>]", cmd.Name)
+	}
+	ctx := cmd.Context()
+	t, err := loadSectorTaxonomy(ctx, s)
+	if err != nil {
+		return err
+	}
+	if existing, ok := t.names[sectorKind][sectorLabelKey(sector)]; ok {
+		if !hasSub {
+			return fmt.Errorf("sector %q already exists", existing)
+		}
+		sector = existing // Subsectors may be added to an existing sector in any case
+	} else {
+		if _, err := s.db.CreateSector(ctx, sector); err != nil {
+			return fmt.Errorf("failed to add sector %q: %w", sector, err)
+		}
+		fmt.Printf("Added sector %q.\n", sector)
+	}
+	if hasSub {
+		if existing, ok := t.names[subsectorKind][sectorLabelKey(subsector)]; ok {
+			return fmt.Errorf("subsector %q already exists", existing)
+		}
+		if _, err := s.db.CreateSubsector(ctx, database.CreateSubsectorParams{Sector: sector, Name: subsector}); err != nil {
+			return fmt.Errorf("failed to add subsector %q: %w", subsector, err)
+		}
+		fmt.Printf("Added subsector %q of %q.\n", subsector, sector)
+	}
+	return reportSectorNormalization(ctx, s)
+}
+
+// handlerSectorsMerge records that a scraped label stands for a canonical sector (or, with
+// --subsector, subsector) and re-normalizes the companies carrying it (admin only).
+// Usage: sectors:merge [--subsector] <variant> = <canonical>   e.g. sectors:merge Finance = Financial Services
+func handlerSectorsMerge(s *AppState, cmd command) error {
+	kind := sectorKind
+	var words []string
+	for _, arg := range cmd.Args {
+		if arg == "--subsector" {
+			kind = subsectorKind
+			continue
+		}
+		words = append(words, arg)
+	}
+	variant, target, ok := strings.Cut(strings.Join(words, " "), "=")
+	label := sectorLabelKey(variant)
+	if !ok || label == "" || strings.TrimSpace(target) == "" {
+		return fmt.Errorf("usage: %s [--subsector] <variant> = <canonical>", cmd.Name)
+	}
+	ctx := cmd.Context()
+	t, err := loadSectorTaxonomy(ctx, s)
+	if err != nil {
+		return err
+	}
+	canonical, ok := t.names[kind][sectorLabelKey(target)]
+	if !ok {
+		return fmt.Errorf("%q is not a canonical %s; add it first with sectors:add", strings.TrimSpace(target), kind)
+	}
+	if name, ok := t.names[kind][label]; ok && name != canonical {
+		return fmt.Errorf("%q is itself a canonical %s", name, kind)
+	}
+	err = s.db.UpsertSectorMapping(ctx, database.UpsertSectorMappingParams{Kind: kind, Label: label, Canonical: canonical})
+	if err != nil {
+		return fmt.Errorf("failed to store mapping: %w", err)
+	}
+	log.Printf("Merged %s label %q into %q.", kind, label, canonical)
+	fmt.Printf("Merged %s %q into %q.\n", kind, strings.TrimSpace(variant), canonical)
+	return reportSectorNormalization(ctx, s)
+}
+
+// handlerSectorsNormalize re-applies the taxonomy to every company's scraped labels (admin only).
+// Usage: sectors:normalize
+func handlerSectorsNormalize(s *AppState, cmd command) error {
+	return reportSectorNormalization(cmd.Context(), s)
+}
+
+func reportSectorNormalization(ctx context.Context, s *AppState) error {
+	changed, err := normalizeCompanySectors(ctx, s)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Normalized the sectors of %d company(ies).\n", changed)
+	return nil
+}
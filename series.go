@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// seriesKey identifies a stored time series by kind and code, written "kind:code":
+// "stock:1155" is a stock's closing price, "fx:USD" is the BNM noon middle rate in MYR per unit.
+type seriesKey struct {
+	Kind string
+	Code string
+}
+
+func (k seriesKey) String() string {
+	return k.Kind + ":" + k.Code
+}
+
+// parseSeriesKey parses and normalizes a "kind:code" series key.
+func parseSeriesKey(raw string) (seriesKey, error) {
+	kind, code, ok := strings.Cut(strings.TrimSpace(raw), ":")
+	if !ok {
+		return seriesKey{}, fmt.Errorf("invalid series %q (use stock:<code> or fx:<currency>)", raw)
+	}
+	kind, code, err := normalizeWatchlistItem(kind, code)
+	if err != nil {
+		return seriesKey{}, err
+	}
+	return seriesKey{Kind: kind, Code: code}, nil
+}
+
+// loadSeries returns the observations of a series in [start, end], preceded by the last
+// observation before start (if any) so it can be carried forward from the first day.
+func loadSeries(ctx context.Context, s *AppState, key seriesKey, start, end time.Time) ([]analytics.Point, error) {
+	switch key.Kind {
+	case watchlistStock:
+		return loadStockCloses(ctx, s, key.Code, start, end)
+	case watchlistFx:
+		return loadFxPerUnit(ctx, s, key.Code, start, end)
+	}
+	return nil, fmt.Errorf("unsupported series kind %q", key.Kind)
+}
+
+// latestSeriesValue returns the most recent observation of a series, or sql.ErrNoRows if it has
+// none. Values are served from the response cache when it is enabled.
+func latestSeriesValue(ctx context.Context, s *AppState, key seriesKey) (analytics.Point, error) {
+	if p, ok := cachedLatestValue(ctx, s, key); ok {
+		return p, nil
+	}
+	var generation uint64
+	if s.cache != nil {
+		generation = s.cache.Generation(ctx)
+	}
+	p, err := loadLatestSeriesValue(ctx, s, key)
+	if err != nil {
+		return p, err
+	}
+	storeLatestValue(ctx, s, key, generation, p)
+	return p, nil
+}
+
+func loadLatestSeriesValue(ctx context.Context, s *AppState, key seriesKey) (analytics.Point, error) {
+	return seriesValueOnOrBefore(ctx, s, key, markettime.Today())
+}
+
+// seriesValueOnOrBefore returns the last observation of a series on or before date, or
+// sql.ErrNoRows if it has none.
+func seriesValueOnOrBefore(ctx context.Context, s *AppState, key seriesKey, date time.Time) (analytics.Point, error) {
+	switch key.Kind {
+	case watchlistStock:
+		row, err := s.db.GetLatestStockPriceOnOrBefore(ctx, database.GetLatestStockPriceOnOrBeforeParams{
+			StockCode: key.Code,
+			OnDate:    date,
+		})
+		if err != nil {
+			return analytics.Point{}, err
+		}
+		v, err := strconv.ParseFloat(row.ClosingPrice, 64)
+		if err != nil {
+			return analytics.Point{}, fmt.Errorf("invalid closing price '%s' for %s: %w", row.ClosingPrice, key, err)
+		}
+		return analytics.Point{Date: row.PriceDate, Value: v}, nil
+	case watchlistFx:
+		row, err := s.db.GetLatestForeignExchangeOnOrBefore(ctx, database.GetLatestForeignExchangeOnOrBeforeParams{
+			CurrencyCode: key.Code,
+			OnDate:       date,
+			Session:      "1200",
+		})
+		if err != nil {
+			return analytics.Point{}, err
+		}
+		v, err := strconv.ParseFloat(row.MiddleRatePerUnit, 64)
+		if err != nil {
+			return analytics.Point{}, fmt.Errorf("invalid rate '%s' for %s: %w", row.MiddleRatePerUnit, key, err)
+		}
+		return analytics.Point{Date: row.Date, Value: v}, nil
+	}
+	return analytics.Point{}, fmt.Errorf("unsupported series kind %q", key.Kind)
+}
+
+// loadStockCloses returns a stock's closing prices in [start, end], preceded by the last
+// close before start (if any) so the series can be carried forward from the first day.
+func loadStockCloses(ctx context.Context, s *AppState, code string, start, end time.Time) ([]analytics.Point, error) {
+	var points []analytics.Point
+	seed, err := s.db.GetLatestStockPriceOnOrBefore(ctx, database.GetLatestStockPriceOnOrBeforeParams{
+		StockCode: code,
+		OnDate:    start.AddDate(0, 0, -1),
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to load price for %s before %s: %w", code, start.Format("2006-01-02"), err)
+	}
+	if err == nil {
+		if v, convErr := strconv.ParseFloat(seed.ClosingPrice, 64); convErr == nil {
+			points = append(points, analytics.Point{Date: seed.PriceDate, Value: v})
+		}
+	}
+
+	rows, err := s.db.GetStockClosingPricesByCodeAndDateRange(ctx, database.GetStockClosingPricesByCodeAndDateRangeParams{
+		StockCode: code,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prices for %s: %w", code, err)
+	}
+	for _, row := range rows {
+		v, convErr := strconv.ParseFloat(row.ClosingPrice, 64)
+		if convErr != nil {
+			log.Printf("Skipping invalid closing price '%s' for %s on %s", row.ClosingPrice, code, row.PriceDate.Format("2006-01-02"))
+			continue
+		}
+		points = append(points, analytics.Point{Date: row.PriceDate, Value: v})
+	}
+	return points, nil
+}
+
+// loadFxPerUnit returns MYR per 1 unit of currency for [start, end] from the 1200 session,
+// preceded by the last rate before start (if any).
+func loadFxPerUnit(ctx context.Context, s *AppState, currency string, start, end time.Time) ([]analytics.Point, error) {
+	var points []analytics.Point
+	seed, err := s.db.GetLatestForeignExchangeOnOrBefore(ctx, database.GetLatestForeignExchangeOnOrBeforeParams{
+		CurrencyCode: currency,
+		OnDate:       start.AddDate(0, 0, -1),
+		Session:      "1200",
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to load %s rate before %s: %w", currency, start.Format("2006-01-02"), err)
+	}
+	if err == nil {
+		if v, convErr := strconv.ParseFloat(seed.MiddleRatePerUnit, 64); convErr == nil {
+			points = append(points, analytics.Point{Date: seed.Date, Value: v})
+		}
+	}
+
+	rows, err := s.db.GetForeignExchangeByCurrencyAndDateRange(ctx, database.GetForeignExchangeByCurrencyAndDateRangeParams{
+		CurrencyCode: currency,
+		StartDate:    start,
+		EndDate:      end,
+		Session:      "1200",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s rates: %w", currency, err)
+	}
+	for _, row := range rows {
+		v, convErr := strconv.ParseFloat(row.MiddleRatePerUnit, 64)
+		if convErr != nil {
+			continue
+		}
+		points = append(points, analytics.Point{Date: row.Date, Value: v})
+	}
+	return points, nil
+}
+
+// errUnknownSeries is returned for series names that are not a known series.
+var errUnknownSeries = errors.New("unknown series")
+
+// loadNamedSeries loads a series named stock:<code>, fx:<currency> or macro:<series> in
+// [from, to], without the observation before from that loadSeries adds.
+func loadNamedSeries(ctx context.Context, s *AppState, target string, from, to time.Time) ([]analytics.Point, error) {
+	if code, ok := strings.CutPrefix(target, "macro:"); ok {
+		if _, known := macroSeries[code]; !known {
+			return nil, fmt.Errorf("%w %q", errUnknownSeries, target)
+		}
+		return loadMacroSeries(ctx, s, code, from, to)
+	}
+	key, err := parseSeriesKey(target)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", errUnknownSeries, target, err)
+	}
+	points, err := loadSeries(ctx, s, key, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) > 0 && points[0].Date.Before(from) {
+		points = points[1:]
+	}
+	return points, nil
+}
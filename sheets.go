@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/gsheets"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// sheetsTable builds the rows pushed to Google Sheets: a header of Date and the series names,
+// then one row per date (newest first) on which any series has a value. Missing values are
+// left blank.
+func sheetsTable(ctx context.Context, s *AppState, series []string, from, to time.Time) ([][]interface{}, error) {
+	values := make(map[string][]interface{})
+	for col, name := range series {
+		points, err := loadNamedSeries(ctx, s, name, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range points {
+			date := p.Date.Format("2006-01-02")
+			row, ok := values[date]
+			if !ok {
+				row = make([]interface{}, len(series))
+				for i := range row {
+					row[i] = ""
+				}
+				values[date] = row
+			}
+			row[col] = p.Value
+		}
+	}
+	dates := make([]string, 0, len(values))
+	for date := range values {
+		dates = append(dates, date)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+
+	header := []interface{}{"Date"}
+	for _, name := range series {
+		header = append(header, name)
+	}
+	rows := [][]interface{}{header}
+	for _, date := range dates {
+		rows = append(rows, append([]interface{}{date}, values[date]...))
+	}
+	return rows, nil
+}
+
+// pushSheets overwrites the GSHEETS_SHEET tab of the configured spreadsheet with the last
+// GSHEETS_LOOKBACK_DAYS of GSHEETS_SERIES. It returns the number of data rows written.
+func pushSheets(ctx context.Context, s *AppState) (int, error) {
+	if s.cfg.GSheetsSpreadsheetID == "" || len(s.cfg.GSheetsSeries) == 0 {
+		return 0, fmt.Errorf("Google Sheets push is disabled (set GSHEETS_SPREADSHEET_ID and GSHEETS_SERIES)")
+	}
+	client, err := gsheets.NewClient(ctx, s.cfg.GSheetsCredentialsFile, s.cfg.GSheetsAPIBaseURL)
+	if err != nil {
+		return 0, err
+	}
+	to := markettime.Today()
+	from := to.AddDate(0, 0, -s.cfg.GSheetsLookbackDays)
+	rows, err := sheetsTable(ctx, s, s.cfg.GSheetsSeries, from, to)
+	if err != nil {
+		return 0, err
+	}
+	// Quoting keeps tab names with spaces valid in A1 notation
+	sheetRange := fmt.Sprintf("'%s'!A1:ZZ", s.cfg.GSheetsSheet)
+	if err := client.ReplaceValues(ctx, s.cfg.GSheetsSpreadsheetID, sheetRange, rows); err != nil {
+		return 0, err
+	}
+	return len(rows) - 1, nil
+}
+
+// sheetsInterval is the Google Sheets push interval, or 0 when no spreadsheet is configured.
+func sheetsInterval(s *AppState) time.Duration {
+	if s.cfg.GSheetsSpreadsheetID == "" || len(s.cfg.GSheetsSeries) == 0 {
+		return 0
+	}
+	return s.cfg.GSheetsInterval
+}
+
+// handlerSheetsPush pushes the configured series to Google Sheets now.
+// Usage: sheets:push
+func handlerSheetsPush(s *AppState, cmd command) error {
+	n, err := pushSheets(cmd.Context(), s)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d row(s) of %d series to Google Sheets.\n", n, len(s.cfg.GSheetsSeries))
+	return nil
+}
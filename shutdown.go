@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// workFinishTimeout bounds how long shutdown waits for background work once its grace period
+// is over and it has been cancelled, which is when it records its outcome.
+const workFinishTimeout = 15 * time.Second
+
+// withGracePeriod returns a context for work that should outlive parent briefly: it keeps
+// parent's values, and is cancelled grace after parent is (or when the returned cancel is
+// called). Commands and jobs running when shutdown begins can then finish, checkpoint and
+// record their fetch run instead of being cut off.
+func withGracePeriod(parent context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+	stop := context.AfterFunc(parent, func() {
+		if grace > 0 {
+			log.Printf("Giving running commands and jobs %s to finish...", grace)
+		}
+		timer := time.AfterFunc(grace, cancel)
+		context.AfterFunc(ctx, func() { timer.Stop() })
+	})
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// runBackground runs fn in a goroutine tracked by s.work, so shutdown waits for it before
+// the database pool closes.
+func runBackground(s *AppState, fn func()) {
+	s.work.Add(1)
+	go func() {
+		defer s.work.Done()
+		fn()
+	}()
+}
+
+// waitForBackgroundWork waits for the work started with runBackground. It gives up
+// workFinishTimeout after the grace period, when that work has been cancelled but not
+// returned, so a hung source cannot block shutdown forever.
+func waitForBackgroundWork(s *AppState) {
+	done := make(chan struct{})
+	go func() {
+		s.work.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(s.cfg.ShutdownGracePeriod + workFinishTimeout):
+		log.Println("Warning: Background work did not finish in time; its fetch runs may be left running.")
+	}
+}
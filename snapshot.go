@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/objectstore"
+	"github.com/lib/pq"
+)
+
+// credentialTables hold password hashes, session tokens, API keys and signing secrets. Snapshots
+// leave them out unless SNAPSHOT_INCLUDE_CREDENTIALS is set, since the bucket is shared more
+// widely than the database (and named by /api/lineage).
+var credentialTables = []string{"api_keys", "ingest_signatures", "ingest_sources", "telegram_link_tokens", "user_sessions", "users", "webhooks"}
+
+// snapshotStore opens the SNAPSHOT_BUCKET store.
+func snapshotStore(s *AppState) (*objectstore.Store, error) {
+	if s.cfg.SnapshotBucket == "" {
+		return nil, fmt.Errorf("snapshots are disabled (SNAPSHOT_BUCKET is not set)")
+	}
+	return objectstore.New(objectstore.Options{
+		Endpoint:  s.cfg.SnapshotEndpoint,
+		Bucket:    s.cfg.SnapshotBucket,
+		Prefix:    s.cfg.SnapshotPrefix,
+		AccessKey: s.cfg.SnapshotAccessKey,
+		SecretKey: s.cfg.SnapshotSecretKey,
+		Region:    s.cfg.SnapshotRegion,
+		UseSSL:    s.cfg.SnapshotUseSSL,
+	})
+}
+
+// exportSnapshot writes every table (but the credential tables, by default) as a gzipped CSV to
+// <prefix>/<YYYY-MM-DD>/<table>.csv.gz in the configured bucket, then deletes snapshots older
+// than the retention. All tables are read in one repeatable-read transaction so the files are
+// consistent with each other. It returns the number of tables exported.
+func exportSnapshot(ctx context.Context, s *AppState) (int, error) {
+	store, err := snapshotStore(s)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.dbConn.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	exclude := s.cfg.SnapshotExcludeTables
+	if !s.cfg.SnapshotCredentials {
+		exclude = append(slices.Clone(exclude), credentialTables...)
+	}
+	tables, err := snapshotTables(ctx, tx, exclude)
+	if err != nil {
+		return 0, err
+	}
+	day := time.Now().UTC().Format("2006-01-02")
+	for _, table := range tables {
+		var buf bytes.Buffer
+		rows, err := writeTableCSV(ctx, tx, table, &buf)
+		if err != nil {
+			return 0, fmt.Errorf("failed to export %s: %w", table, err)
+		}
+		name := fmt.Sprintf("%s/%s.csv.gz", day, table)
+		if err := store.Put(ctx, name, &buf, int64(buf.Len()), "application/gzip"); err != nil {
+			return 0, err
+		}
+		log.Printf("Snapshot: uploaded %s (%d rows).", name, rows)
+	}
+
+	if s.cfg.SnapshotRetentionDays > 0 {
+		cutoff := markettime.Today().AddDate(0, 0, -s.cfg.SnapshotRetentionDays)
+		n, err := store.PruneDated(ctx, cutoff)
+		if err != nil {
+			return len(tables), fmt.Errorf("exported %d tables but failed to prune old snapshots: %w", len(tables), err)
+		}
+		if n > 0 {
+			log.Printf("Snapshot: deleted %d objects from before %s.", n, cutoff.Format("2006-01-02"))
+		}
+	}
+	return len(tables), nil
+}
+
+// snapshotTables lists the tables of the public schema, minus the excluded ones. Partitioned
+// tables are exported whole, so their partitions are skipped.
+func snapshotTables(ctx context.Context, tx *sql.Tx, exclude []string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT c.relname FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p') AND NOT c.relispartition
+ORDER BY c.relname`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+		if !slices.Contains(exclude, table) {
+			tables = append(tables, table)
+		}
+	}
+	return tables, rows.Err()
+}
+
+// writeTableCSV writes a header row and every row of table to w as gzipped CSV.
+// NULLs are written as empty fields and timestamps in RFC 3339. It returns the row count.
+func writeTableCSV(ctx context.Context, tx *sql.Tx, table string, w *bytes.Buffer) (int, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT * FROM "+pq.QuoteIdentifier(table))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	gz := gzip.NewWriter(w)
+	out := csv.NewWriter(gz)
+	if err := out.Write(columns); err != nil {
+		return 0, err
+	}
+	values := make([]interface{}, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+	n := 0
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return n, err
+		}
+		for i, v := range values {
+			record[i] = csvField(v)
+		}
+		if err := out.Write(record); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return n, err
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return n, err
+	}
+	return n, gz.Close()
+}
+
+// csvField formats a value scanned by database/sql for a CSV cell.
+func csvField(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// handlerSnapshotExport uploads a snapshot of every table on demand.
+// Usage: snapshot:export
+func handlerSnapshotExport(s *AppState, cmd command) error {
+	n, err := exportSnapshot(cmd.Context(), s)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d tables to bucket %s.\n", n, s.cfg.SnapshotBucket)
+	return nil
+}
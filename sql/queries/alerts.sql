@@ -0,0 +1,54 @@
+-- name: CreateAlertRule :one
+INSERT INTO alert_rules (
+    id, user_id, series, condition, threshold, change
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ListAlertRulesByUser :many
+SELECT * FROM alert_rules
+WHERE user_id = $1
+ORDER BY created_at ASC;
+
+-- name: ListAlertRules :many
+-- Locks the rules until the transaction ends, so concurrent evaluations take turns and each
+-- sees the triggered state the previous one stored.
+SELECT * FROM alert_rules
+ORDER BY series ASC, id ASC
+FOR UPDATE;
+
+-- name: DeleteAlertRule :execrows
+DELETE FROM alert_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteAlertRulesByUser :execrows
+-- Clears a user's rules before an import that replaces them.
+DELETE FROM alert_rules
+WHERE user_id = $1;
+
+-- name: UpdateAlertRule :one
+-- Replaces a rule's condition and re-arms it, so it fires on the next evaluation that meets it.
+UPDATE alert_rules
+SET series = $3, condition = $4, threshold = $5, change = $6, is_triggered = FALSE
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: SetAlertRuleTriggered :exec
+UPDATE alert_rules
+SET
+    is_triggered = sqlc.arg(is_triggered),
+    last_triggered_at = CASE WHEN sqlc.arg(is_triggered) THEN NOW() ELSE last_triggered_at END
+WHERE id = sqlc.arg(id);
+
+-- name: CreateAlertEvent :one
+INSERT INTO alert_events (
+    id, rule_id, user_id, series, condition, threshold, observed_value, observed_date, change
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: ListAlertEventsByUser :many
+SELECT * FROM alert_events
+WHERE user_id = sqlc.arg(user_id)
+ORDER BY triggered_at DESC
+LIMIT sqlc.arg(max_results);
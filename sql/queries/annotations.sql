@@ -0,0 +1,14 @@
+-- name: CreateAnnotation :one
+INSERT INTO annotations (event_date, category, title, description, source, country_code)
+VALUES (sqlc.arg(event_date), sqlc.arg(category), sqlc.arg(title), sqlc.arg(description), sqlc.arg(source), sqlc.arg(country_code))
+RETURNING *;
+
+-- name: DeleteAnnotation :execrows
+DELETE FROM annotations
+WHERE id = sqlc.arg(id);
+
+-- name: ListAnnotationsBetween :many
+SELECT * FROM annotations
+WHERE event_date >= sqlc.arg(start_date) AND event_date <= sqlc.arg(end_date)
+  AND (sqlc.narg(country_code)::text IS NULL OR country_code = sqlc.narg(country_code))
+ORDER BY event_date, id;
@@ -0,0 +1,32 @@
+-- name: CreateAnnouncementAlertRule :one
+INSERT INTO announcement_alert_rules (
+    id, user_id, category, stock_code
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListAnnouncementAlertRulesByUser :many
+SELECT * FROM announcement_alert_rules
+WHERE user_id = $1
+ORDER BY created_at ASC;
+
+-- name: ListAnnouncementAlertRules :many
+SELECT * FROM announcement_alert_rules
+ORDER BY user_id, created_at;
+
+-- name: DeleteAnnouncementAlertRule :execrows
+DELETE FROM announcement_alert_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateAnnouncementAlertEvent :one
+INSERT INTO announcement_alert_events (
+    id, rule_id, user_id, stock_code, category, title, link, announced_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: ListAnnouncementAlertEventsByUser :many
+SELECT * FROM announcement_alert_events
+WHERE user_id = sqlc.arg(user_id)
+ORDER BY triggered_at DESC
+LIMIT sqlc.arg(max_results);
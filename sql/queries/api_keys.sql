@@ -0,0 +1,28 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    id,
+    user_id,
+    name,
+    key_hash,
+    role
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetAPIKeyByHash :one
+SELECT * FROM api_keys
+WHERE key_hash = $1;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+ORDER BY created_at ASC;
+
+-- name: TouchAPIKey :exec
+-- Records when a key was last used to authenticate a request.
+UPDATE api_keys
+SET last_used_at = NOW()
+WHERE id = $1;
+
+-- name: DeleteAPIKey :execrows
+DELETE FROM api_keys
+WHERE id = $1;
@@ -0,0 +1,19 @@
+-- name: CreateAuditLogEntry :exec
+INSERT INTO audit_log (
+    user_id, username, action, details, source, remote_addr
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+);
+
+-- name: ListAuditLog :many
+-- Newest first, optionally filtered by action and/or username.
+SELECT * FROM audit_log
+WHERE
+    (sqlc.narg(action)::text IS NULL OR action = sqlc.narg(action))
+    AND (sqlc.narg(username)::text IS NULL OR username = sqlc.narg(username))
+ORDER BY created_at DESC
+LIMIT sqlc.arg(max_results);
+
+-- name: DeleteAuditLogBefore :execrows
+-- Entries left in the default partition or a partially expired month after whole months are dropped.
+DELETE FROM audit_log WHERE created_at < sqlc.arg(before);
@@ -0,0 +1,58 @@
+-- name: CreateBasket :one
+INSERT INTO baskets (
+    id, user_id, name, base_date, base_value
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: CreateBasketComponent :exec
+INSERT INTO basket_components (
+    basket_id, stock_code, weight
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: ListBaskets :many
+SELECT * FROM baskets
+ORDER BY created_at ASC;
+
+-- name: ListBasketsByUser :many
+SELECT * FROM baskets
+WHERE user_id = $1
+ORDER BY name ASC;
+
+-- name: GetBasketByIDAndUser :one
+SELECT * FROM baskets
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListBasketComponents :many
+SELECT * FROM basket_components
+WHERE basket_id = $1
+ORDER BY stock_code ASC;
+
+-- name: DeleteBasket :execrows
+DELETE FROM baskets
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteBasketValues :exec
+DELETE FROM basket_values WHERE basket_id = $1;
+
+-- name: UpsertBasketValue :exec
+INSERT INTO basket_values (
+    basket_id, date, value, computed_at
+) VALUES (
+    $1, $2, $3, CURRENT_TIMESTAMP
+)
+ON CONFLICT (basket_id, date) DO UPDATE SET
+    value = EXCLUDED.value,
+    computed_at = CURRENT_TIMESTAMP;
+
+-- name: GetBasketValuesByDateRange :many
+SELECT date, value
+FROM basket_values
+WHERE
+    basket_id = sqlc.arg(basket_id)
+    AND date >= sqlc.arg(start_date)
+    AND date <= sqlc.arg(end_date)
+ORDER BY
+    date ASC;
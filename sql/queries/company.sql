@@ -8,6 +8,12 @@ INSERT INTO companies (
     subsector,
     listing_date,            -- Make sure your Go code can pass NULL for this if not available
     profile_source_url,      -- Make sure your Go code can pass NULL
+    website,                 -- Will be string or NULL from Go
+    par_value,               -- Will be numeric or NULL from Go
+    shares_outstanding,      -- Will be int64 or NULL from Go
+    sector_raw,
+    subsector_raw,
+    short_name,
     profile_last_scraped_at, -- This will be set by the query
     created_at,              -- Handled by DB default on INSERT
     updated_at               -- Handled by DB default on INSERT or trigger on UPDATE
@@ -19,21 +25,113 @@ INSERT INTO companies (
     sqlc.arg(subsector),             -- Will be string or NULL from Go
     sqlc.arg(listing_date),          -- Will be time.Time or NULL from Go
     sqlc.arg(profile_source_url),    -- Will be string or NULL from Go
+    sqlc.arg(website),               -- Will be string or NULL from Go
+    sqlc.arg(par_value),             -- Will be numeric or NULL from Go
+    sqlc.arg(shares_outstanding),    -- Will be int64 or NULL from Go
+    sqlc.arg(sector_raw),            -- As scraped; sector and subsector are the canonical labels
+    sqlc.arg(subsector_raw),
+    sqlc.narg(short_name),
     NOW(),                           -- Set profile_last_scraped_at to current time
     DEFAULT,                         -- Use default for created_at on new insert
     DEFAULT                          -- Use default for updated_at on new insert
 )
 ON CONFLICT (stock_code) DO UPDATE SET
     company_name = EXCLUDED.company_name,
+    former_names = CASE                 -- Keep the name a renamed company was listed under
+        WHEN companies.company_name <> EXCLUDED.company_name AND companies.company_name <> ''
+            AND NOT companies.company_name = ANY(companies.former_names)
+        THEN array_append(companies.former_names, companies.company_name::text)
+        ELSE companies.former_names
+    END,
     country_code = EXCLUDED.country_code,
     sector = EXCLUDED.sector,
     subsector = EXCLUDED.subsector,
     listing_date = EXCLUDED.listing_date,
     profile_source_url = EXCLUDED.profile_source_url,
+    website = EXCLUDED.website,
+    par_value = EXCLUDED.par_value,
+    shares_outstanding = EXCLUDED.shares_outstanding,
+    sector_raw = EXCLUDED.sector_raw,
+    subsector_raw = EXCLUDED.subsector_raw,
+    short_name = COALESCE(EXCLUDED.short_name, companies.short_name),
     profile_last_scraped_at = NOW(), -- Update this timestamp on conflict
     updated_at = NOW();              -- Explicitly update this via trigger or NOW()
 
 -- name: GetCompanyByStockCode :one
 -- Retrieves a company's profile by its stock code.
 SELECT * FROM companies
-WHERE stock_code = $1;
\ No newline at end of file
+WHERE stock_code = $1;
+
+-- name: GetLatestProfileScrapedAt :one
+-- Time of the most recent company profile scrape.
+SELECT profile_last_scraped_at FROM companies
+WHERE profile_last_scraped_at IS NOT NULL
+ORDER BY profile_last_scraped_at DESC
+LIMIT 1;
+
+-- name: ListCompaniesByStockCodes :many
+SELECT * FROM companies
+WHERE stock_code = ANY(sqlc.arg(stock_codes)::text[])
+ORDER BY stock_code;
+
+-- name: CreateCompany :execrows
+-- Registers a company that has no scraped profile, such as one listed in another country
+-- whose prices arrive through ingestion.
+INSERT INTO companies (stock_code, company_name, country_code)
+VALUES (sqlc.arg(stock_code), sqlc.arg(company_name), sqlc.arg(country_code))
+ON CONFLICT (stock_code) DO NOTHING;
+
+-- name: ListCompanies :many
+SELECT * FROM companies
+WHERE sqlc.narg(country_code)::text IS NULL OR country_code = sqlc.narg(country_code)
+ORDER BY stock_code;
+
+-- name: RecordStockPageMissing :one
+-- Counts a fetch that found a company's stock page missing. The company is marked missing, or
+-- delisted as of the first missing fetch once the page has been missing for delist_after_days
+-- (0 never delists). Companies already delisted are left alone.
+UPDATE companies SET
+    missing_count = missing_count + 1,
+    missing_since = COALESCE(missing_since, sqlc.arg(fetch_date)::date),
+    status = CASE
+        WHEN sqlc.arg(delist_after_days)::int > 0
+            AND sqlc.arg(fetch_date)::date - COALESCE(missing_since, sqlc.arg(fetch_date)::date) >= sqlc.arg(delist_after_days)::int
+        THEN 'delisted'
+        ELSE 'missing'
+    END,
+    delisted_date = CASE
+        WHEN sqlc.arg(delist_after_days)::int > 0
+            AND sqlc.arg(fetch_date)::date - COALESCE(missing_since, sqlc.arg(fetch_date)::date) >= sqlc.arg(delist_after_days)::int
+        THEN COALESCE(missing_since, sqlc.arg(fetch_date)::date)
+        ELSE NULL
+    END,
+    updated_at = NOW()
+WHERE stock_code = sqlc.arg(stock_code) AND status <> 'delisted'
+RETURNING status, missing_since, missing_count;
+
+-- name: RecordStockPageFound :execrows
+-- Returns a company marked missing to active once its stock page is found again.
+UPDATE companies SET status = 'active', missing_since = NULL, missing_count = 0, updated_at = NOW()
+WHERE stock_code = $1 AND status = 'missing';
+
+-- name: SetCompanyStatus :execrows
+-- Sets a company's status by hand, clearing the missing-page count.
+UPDATE companies SET
+    status = sqlc.arg(status),
+    delisted_date = sqlc.narg(delisted_date),
+    missing_since = NULL,
+    missing_count = 0,
+    updated_at = NOW()
+WHERE stock_code = sqlc.arg(stock_code);
+
+-- name: ListCompanyLifecycles :many
+-- Companies that are missing, delisted or have been renamed.
+SELECT stock_code, company_name, status, delisted_date, former_names, missing_since, missing_count
+FROM companies
+WHERE status <> 'active' OR cardinality(former_names) > 0
+ORDER BY status, stock_code;
+
+-- name: ListDelistedStockCodes :many
+SELECT stock_code FROM companies
+WHERE status = 'delisted'
+ORDER BY stock_code;
@@ -0,0 +1,15 @@
+-- name: DeleteReturnCorrelationsByWindow :exec
+DELETE FROM return_correlations WHERE window_days = sqlc.arg(window_days);
+
+-- name: CreateReturnCorrelation :exec
+INSERT INTO return_correlations (
+    series_a, series_b, window_days, as_of, correlation, observations, computed_at
+) VALUES (
+    sqlc.arg(series_a), sqlc.arg(series_b), sqlc.arg(window_days), sqlc.arg(as_of),
+    sqlc.arg(correlation), sqlc.arg(observations), CURRENT_TIMESTAMP
+);
+
+-- name: ListReturnCorrelationsByWindow :many
+SELECT * FROM return_correlations
+WHERE window_days = sqlc.arg(window_days)
+ORDER BY series_a, series_b;
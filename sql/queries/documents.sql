@@ -0,0 +1,29 @@
+-- name: UpsertCompanyDocument :one
+-- Stores a document, replacing an earlier download of the same company and period.
+INSERT INTO company_documents (
+    stock_code, fiscal_year, period, title, source_url, storage_key, sha256, size_bytes, content_type
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (stock_code, fiscal_year, period) DO UPDATE SET
+    title = EXCLUDED.title,
+    source_url = EXCLUDED.source_url,
+    storage_key = EXCLUDED.storage_key,
+    sha256 = EXCLUDED.sha256,
+    size_bytes = EXCLUDED.size_bytes,
+    content_type = EXCLUDED.content_type,
+    downloaded_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetCompanyDocument :one
+SELECT * FROM company_documents WHERE id = $1;
+
+-- name: ListCompanyDocumentsByStockCode :many
+-- Newest fiscal year first.
+SELECT * FROM company_documents
+WHERE stock_code = $1
+ORDER BY fiscal_year DESC, period;
+
+-- name: DeleteCompanyDocument :one
+DELETE FROM company_documents WHERE id = $1
+RETURNING *;
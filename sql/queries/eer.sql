@@ -0,0 +1,30 @@
+-- name: UpsertEffectiveExchangeRate :exec
+INSERT INTO effective_exchange_rates (
+    index_type, date, value, computed_at
+) VALUES (
+    sqlc.arg(index_type), sqlc.arg(date), sqlc.arg(value), CURRENT_TIMESTAMP
+)
+ON CONFLICT (index_type, date) DO UPDATE SET
+    value = EXCLUDED.value,
+    computed_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteEffectiveExchangeRates :exec
+-- Clears an index before a full recomputation (the base date may have moved).
+DELETE FROM effective_exchange_rates
+WHERE index_type = sqlc.arg(index_type);
+
+-- name: GetEffectiveExchangeRatesByDateRange :many
+SELECT date, value
+FROM effective_exchange_rates
+WHERE
+    index_type = sqlc.arg(index_type)
+    AND date >= sqlc.arg(start_date)
+    AND date <= sqlc.arg(end_date)
+ORDER BY
+    date ASC;
+
+-- name: GetLatestEffectiveExchangeRateDate :one
+SELECT date FROM effective_exchange_rates
+WHERE index_type = sqlc.arg(index_type)
+ORDER BY date DESC
+LIMIT 1;
@@ -0,0 +1,24 @@
+-- name: UpsertEntitlement :one
+-- Returns whether the entitlement is new (rather than an update of a stored one).
+INSERT INTO entitlements (
+    stock_code, entitlement_type, ex_date, payment_date, description, amount, source_url, fetched_at
+) VALUES (
+    sqlc.arg(stock_code), sqlc.arg(entitlement_type), sqlc.arg(ex_date), sqlc.narg(payment_date),
+    sqlc.arg(description), sqlc.narg(amount), sqlc.arg(source_url), CURRENT_TIMESTAMP
+)
+ON CONFLICT (stock_code, entitlement_type, ex_date) DO UPDATE SET
+    payment_date = EXCLUDED.payment_date,
+    description = EXCLUDED.description,
+    amount = EXCLUDED.amount,
+    source_url = EXCLUDED.source_url,
+    fetched_at = CURRENT_TIMESTAMP
+RETURNING (xmax = 0) AS inserted;
+
+-- name: ListEntitlementsBetween :many
+-- Entitlements going ex in a date range, optionally for one stock and/or type, soonest first.
+SELECT * FROM entitlements
+WHERE
+    ex_date >= sqlc.arg(start_date) AND ex_date <= sqlc.arg(end_date)
+    AND (sqlc.narg(stock_code)::text IS NULL OR stock_code = sqlc.narg(stock_code))
+    AND (sqlc.narg(entitlement_type)::text IS NULL OR entitlement_type = sqlc.narg(entitlement_type))
+ORDER BY ex_date, stock_code, entitlement_type;
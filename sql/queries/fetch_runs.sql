@@ -0,0 +1,54 @@
+-- name: CreateFetchRun :one
+INSERT INTO fetch_runs (
+    id, command, args, started_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: FinishFetchRun :exec
+UPDATE fetch_runs
+SET
+    status = $2,
+    finished_at = $3,
+    successful_fetches = $4,
+    failed_fetches = $5,
+    successful_stores = $6,
+    failed_stores = $7,
+    error_samples = $8
+WHERE id = $1;
+
+-- name: ListFetchRuns :many
+-- Newest first, optionally filtered by command.
+SELECT * FROM fetch_runs
+WHERE sqlc.narg(command)::text IS NULL OR command = sqlc.narg(command)
+ORDER BY started_at DESC
+LIMIT sqlc.arg(max_results);
+
+-- name: GetLatestFetchRunByStatus :one
+-- Most recent run of any of the given commands with one of the given statuses.
+SELECT * FROM fetch_runs
+WHERE command = ANY(sqlc.arg(commands)::text[])
+  AND status = ANY(sqlc.arg(statuses)::text[])
+ORDER BY started_at DESC
+LIMIT 1;
+
+-- name: CreateFetchCheckpoint :exec
+INSERT INTO fetch_checkpoints (command, run_date, item)
+VALUES (sqlc.arg(command), sqlc.arg(run_date), sqlc.arg(item))
+ON CONFLICT (command, run_date, item) DO NOTHING;
+
+-- name: ListFetchCheckpoints :many
+-- Items of a command completed on a market date.
+SELECT item FROM fetch_checkpoints
+WHERE command = sqlc.arg(command) AND run_date = sqlc.arg(run_date);
+
+-- name: DeleteFetchCheckpointsBefore :execrows
+DELETE FROM fetch_checkpoints WHERE run_date < sqlc.arg(run_date);
+
+-- name: DeleteFetchRunsBefore :execrows
+-- Finished runs started before the cutoff. Values they stored lose their lineage link
+-- (fetch_run_id is set to NULL).
+DELETE FROM fetch_runs WHERE started_at < sqlc.arg(before) AND status <> 'running';
+
+-- name: GetFetchRun :one
+SELECT * FROM fetch_runs WHERE id = $1;
@@ -1,26 +1,150 @@
 -- name: UpsertForeignExchange :exec
+-- A third-party rate never overwrites a BNM rate; BNM (or the same source) always may.
 INSERT INTO foreign_exchange (
-    id, currency_code, buying_rate, selling_rate, middle_rate, created_at, date
+    id, currency_code, buying_rate, selling_rate, middle_rate, unit, source, created_at, date, session, fetch_run_id
 ) VALUES (
     -- Name all parameters explicitly
     sqlc.arg(id), sqlc.arg(currency_code), sqlc.arg(buying_rate),
-    sqlc.arg(selling_rate), sqlc.arg(middle_rate), sqlc.arg(created_at), sqlc.arg(date)
+    sqlc.arg(selling_rate), sqlc.arg(middle_rate), sqlc.arg(unit), sqlc.arg(source), sqlc.arg(created_at), sqlc.arg(date),
+    sqlc.arg(session), sqlc.narg(fetch_run_id)
 )
-ON CONFLICT (currency_code, date) DO UPDATE SET
+ON CONFLICT (currency_code, date, session) DO UPDATE SET
     buying_rate = EXCLUDED.buying_rate,
     selling_rate = EXCLUDED.selling_rate,
     middle_rate = EXCLUDED.middle_rate,
-    created_at = EXCLUDED.created_at
+    unit = EXCLUDED.unit,
+    source = EXCLUDED.source,
+    created_at = EXCLUDED.created_at,
+    fetch_run_id = EXCLUDED.fetch_run_id
+WHERE
+    foreign_exchange.source = EXCLUDED.source
+    OR EXCLUDED.source = 'bnm'
 ;
 
 -- name: GetForeignExchangeByCurrencyAndDateRange :many
 SELECT
     date,
-    middle_rate -- Adjust if you want other rates
+    middle_rate, -- Adjust if you want other rates
+    unit,
+    middle_rate_per_unit,
+    source
 FROM foreign_exchange
 WHERE
     currency_code = sqlc.arg(currency_code) -- Explicitly name currency_code
     AND date >= sqlc.arg(start_date)        -- Explicitly name start_date
     AND date <= sqlc.arg(end_date)          -- Explicitly name end_date
+    AND session = sqlc.arg(session)         -- BNM session, '1200' unless intraday rates are requested
+ORDER BY
+    date ASC;
+
+-- name: GetFxSpreadsByCurrencyAndDateRange :many
+-- BNM buying and selling rates of a currency with their spread, per date. Rates from other
+-- providers carry a single value and are left out.
+SELECT
+    date,
+    buying_rate,
+    selling_rate,
+    unit,
+    spread_per_unit,
+    spread_pct
+FROM foreign_exchange
+WHERE
+    currency_code = sqlc.arg(currency_code)
+    AND session = sqlc.arg(session)
+    AND date >= sqlc.arg(start_date)
+    AND date <= sqlc.arg(end_date)
+    AND source = 'bnm'
+ORDER BY
+    date ASC;
+
+-- name: ListForeignExchangeDates :many
+-- Lists the dates that already have a stored rate for a currency within a range (used for gap-filling).
+SELECT date
+FROM foreign_exchange
+WHERE
+    currency_code = sqlc.arg(currency_code)
+    AND date >= sqlc.arg(start_date)
+    AND date <= sqlc.arg(end_date)
+    AND session = sqlc.arg(session)
+ORDER BY
+    date ASC;
+
+-- name: GetLatestForeignExchangeOnOrBefore :one
+-- Returns the most recent stored rate for a currency on or before the given date.
+SELECT date, middle_rate, unit, middle_rate_per_unit
+FROM foreign_exchange
+WHERE
+    currency_code = sqlc.arg(currency_code)
+    AND date <= sqlc.arg(on_date)
+    AND session = sqlc.arg(session)
+ORDER BY
+    date DESC
+LIMIT 1;
+
+-- name: GetForeignExchangeObservationOnOrBefore :one
+-- The full stored row of the most recent rate for a currency and session on or before a date.
+SELECT * FROM foreign_exchange
+WHERE
+    currency_code = sqlc.arg(currency_code)
+    AND date <= sqlc.arg(on_date)
+    AND session = sqlc.arg(session)
 ORDER BY
-    date ASC;
\ No newline at end of file
+    date DESC
+LIMIT 1;
+
+-- name: GetLatestForeignExchangeDate :one
+-- Most recent date with any stored FX rate.
+SELECT date FROM foreign_exchange
+ORDER BY date DESC
+LIMIT 1;
+
+-- name: ListForeignExchangeCurrencies :many
+-- Currencies with at least one stored rate.
+SELECT DISTINCT currency_code FROM foreign_exchange
+ORDER BY currency_code;
+
+-- name: ListLatestForeignExchangeRates :many
+-- The most recent rate of every currency for a session.
+SELECT DISTINCT ON (currency_code) currency_code, date, middle_rate_per_unit
+FROM foreign_exchange
+WHERE session = sqlc.arg(session)
+ORDER BY currency_code, date DESC;
+
+-- name: ListForeignExchangeRatesOnOrBefore :many
+-- The most recent rate on or before a date of every currency for a session.
+SELECT DISTINCT ON (currency_code) currency_code, date, middle_rate_per_unit
+FROM foreign_exchange
+WHERE
+    date <= sqlc.arg(on_date)
+    AND session = sqlc.arg(session)
+ORDER BY currency_code, date DESC;
+
+-- name: ListForeignExchangeDuplicates :many
+-- Rows sharing a currency (ignoring case and surrounding spaces), date and session with
+-- another row. Within each group the row to keep comes first: BNM over third-party sources,
+-- then a canonical (upper-case) code, then the most recently fetched.
+SELECT id, currency_code, date, session, source, middle_rate, created_at
+FROM foreign_exchange
+WHERE (upper(btrim(currency_code)), date, session) IN (
+    SELECT upper(btrim(currency_code)), date, session
+    FROM foreign_exchange
+    GROUP BY upper(btrim(currency_code)), date, session
+    HAVING count(*) > 1
+)
+ORDER BY upper(btrim(currency_code)), date, session,
+    (source = 'bnm') DESC, (currency_code = upper(btrim(currency_code))) DESC, created_at DESC;
+
+-- name: DeleteForeignExchange :exec
+DELETE FROM foreign_exchange WHERE id = sqlc.arg(id);
+
+-- name: DeleteForeignExchangeRange :execrows
+-- Removes a currency's rates of the given sessions within a range (used by repair before re-fetching).
+DELETE FROM foreign_exchange
+WHERE
+    upper(btrim(currency_code)) = sqlc.arg(currency_code)
+    AND date >= sqlc.arg(start_date)
+    AND date <= sqlc.arg(end_date)
+    AND session = ANY(sqlc.arg(sessions)::text[]);
+
+-- name: SetForeignExchangeCurrencyCode :exec
+UPDATE foreign_exchange SET currency_code = sqlc.arg(currency_code) WHERE id = sqlc.arg(id);
@@ -0,0 +1,35 @@
+-- name: CreateIngestSource :one
+INSERT INTO ingest_sources (
+    name, series, secret
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetIngestSource :one
+SELECT * FROM ingest_sources
+WHERE name = $1;
+
+-- name: ListIngestSources :many
+SELECT * FROM ingest_sources
+ORDER BY name ASC;
+
+-- name: TouchIngestSource :exec
+UPDATE ingest_sources
+SET last_ingested_at = CURRENT_TIMESTAMP
+WHERE name = $1;
+
+-- name: DeleteIngestSource :execrows
+DELETE FROM ingest_sources
+WHERE name = $1;
+
+-- name: RecordIngestSignature :execrows
+-- Records the signature of an accepted push; no row is inserted when it was accepted before.
+INSERT INTO ingest_signatures (
+    signature, source_name
+) VALUES (
+    $1, $2
+) ON CONFLICT (signature) DO NOTHING;
+
+-- name: DeleteIngestSignaturesBefore :execrows
+DELETE FROM ingest_signatures
+WHERE received_at < sqlc.arg(before);
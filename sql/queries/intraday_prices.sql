@@ -0,0 +1,16 @@
+-- name: InsertIntradayPrice :exec
+INSERT INTO intraday_prices (stock_code, observed_at, price, volume, source_url)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (stock_code, observed_at) DO NOTHING;
+
+-- name: GetIntradayPricesByCodeAndTimeRange :many
+SELECT observed_at, price, volume
+FROM intraday_prices
+WHERE stock_code = sqlc.arg(stock_code)
+  AND observed_at >= sqlc.arg(start_time) AND observed_at < sqlc.arg(end_time)
+ORDER BY observed_at ASC;
+
+-- name: DeleteIntradayPricesBefore :execrows
+-- Prunes the polled prices past INTRADAY_RETENTION_DAYS.
+DELETE FROM intraday_prices
+WHERE observed_at < $1;
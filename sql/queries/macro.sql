@@ -0,0 +1,65 @@
+-- name: UpsertMacroObservation :execrows
+-- Affects no row when the period is already stored with the same value and source.
+INSERT INTO macro_observations (
+    series, period, value, source, fetched_at
+) VALUES (
+    sqlc.arg(series), sqlc.arg(period), sqlc.arg(value), sqlc.arg(source), CURRENT_TIMESTAMP
+)
+ON CONFLICT (series, period) DO UPDATE SET
+    value = EXCLUDED.value,
+    source = EXCLUDED.source,
+    -- Only new or revised values count as fetched, so releases can be found by fetched_at
+    fetched_at = CASE
+        WHEN macro_observations.value IS DISTINCT FROM EXCLUDED.value THEN CURRENT_TIMESTAMP
+        ELSE macro_observations.fetched_at
+    END
+WHERE
+    macro_observations.value IS DISTINCT FROM EXCLUDED.value
+    OR macro_observations.source IS DISTINCT FROM EXCLUDED.source;
+
+-- name: DeleteMacroObservationsExcept :exec
+-- Removes the periods of a derived series that a recomputation no longer produces.
+DELETE FROM macro_observations
+WHERE
+    series = sqlc.arg(series)
+    AND NOT (period = ANY(sqlc.arg(periods)::date[]));
+
+-- name: DeleteMacroObservationsInRange :execrows
+-- Removes a series' periods within a range (used by repair before re-fetching).
+DELETE FROM macro_observations
+WHERE
+    series = sqlc.arg(series)
+    AND period >= sqlc.arg(start_date)
+    AND period <= sqlc.arg(end_date);
+
+-- name: GetMacroObservationOnOrBefore :one
+-- The stored row of the most recent period of a series starting on or before a date.
+SELECT * FROM macro_observations
+WHERE
+    series = sqlc.arg(series)
+    AND period <= sqlc.arg(on_date)
+ORDER BY
+    period DESC
+LIMIT 1;
+
+-- name: GetMacroObservationsBySeriesAndDateRange :many
+SELECT period, value
+FROM macro_observations
+WHERE
+    series = sqlc.arg(series)
+    AND period >= sqlc.arg(start_date)
+    AND period <= sqlc.arg(end_date)
+ORDER BY
+    period ASC;
+
+-- name: ListMacroObservationsFetchedSince :many
+-- Periods that were first published or revised since a time. A series first stored in that
+-- time is its initial history load, not a release, so it is left out until the next period.
+SELECT m.series, m.period, m.value, m.fetched_at
+FROM macro_observations m
+WHERE m.fetched_at >= sqlc.arg(since)
+  AND EXISTS (
+    SELECT 1 FROM macro_observations seeded
+    WHERE seeded.series = m.series AND seeded.fetched_at < sqlc.arg(since)
+  )
+ORDER BY m.series ASC, m.period ASC;
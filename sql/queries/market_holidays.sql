@@ -0,0 +1,16 @@
+-- name: UpsertMarketHoliday :exec
+INSERT INTO market_holidays (holiday_date, name, source, updated_at)
+VALUES (sqlc.arg(holiday_date), sqlc.arg(name), sqlc.arg(source), CURRENT_TIMESTAMP)
+ON CONFLICT (holiday_date) DO UPDATE SET
+    name = EXCLUDED.name,
+    source = EXCLUDED.source,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: ListMarketHolidays :many
+SELECT * FROM market_holidays
+ORDER BY holiday_date;
+
+-- name: ListMarketHolidaysBetween :many
+SELECT * FROM market_holidays
+WHERE holiday_date >= sqlc.arg(start_date) AND holiday_date <= sqlc.arg(end_date)
+ORDER BY holiday_date;
@@ -0,0 +1,73 @@
+-- name: GetMonthlyFxRatesByCurrencyAndDateRange :many
+-- Monthly average rates from the monthly_fx_rates view; start_date and end_date are month starts.
+SELECT month, avg_middle_rate_per_unit, observations, last_date
+FROM monthly_fx_rates
+WHERE
+    currency_code = sqlc.arg(currency_code)
+    AND session = sqlc.arg(session)
+    AND month >= sqlc.arg(start_date)
+    AND month <= sqlc.arg(end_date)
+ORDER BY
+    month ASC;
+
+-- name: GetMonthlyStockClosesWithDetailsByCodeAndDateRange :many
+-- Month-end closes from the monthly_stock_closes view; start_date is a month start. The month of
+-- end_date is taken from the daily prices up to end_date, so its close is never from a later day
+-- (and is not missing while the view lags).
+SELECT
+    c.company_name,
+    closes.month,
+    closes.last_date,
+    closes.closing_price,
+    closes.stock_code
+FROM (
+    SELECT month, last_date, closing_price, stock_code
+    FROM monthly_stock_closes
+    WHERE
+        stock_code = sqlc.arg(stock_code)
+        AND month >= sqlc.arg(start_date)
+        AND month < date_trunc('month', sqlc.arg(end_date)::DATE)
+    UNION ALL
+    (
+        SELECT date_trunc('month', price_date)::DATE, price_date, closing_price, stock_code
+        FROM daily_stock_prices
+        WHERE
+            stock_code = sqlc.arg(stock_code)
+            AND price_date >= GREATEST(sqlc.arg(start_date), date_trunc('month', sqlc.arg(end_date)::DATE))
+            AND price_date <= sqlc.arg(end_date)
+        ORDER BY price_date DESC
+        LIMIT 1
+    )
+) closes
+JOIN
+    companies c ON closes.stock_code = c.stock_code
+ORDER BY
+    closes.month ASC;
+
+-- name: GetFxFixingsByCurrenciesAndDateRange :many
+-- Monthly average and month-end MYR-per-unit rates computed from the daily rates (so, unlike
+-- monthly_fx_rates, including those stored since the last refresh). The month-end rate is the
+-- one of the last stored date in the month.
+SELECT
+    currency_code,
+    date_trunc('month', date)::DATE AS month,
+    AVG(middle_rate_per_unit)::DECIMAL(14, 8) AS avg_middle_rate_per_unit,
+    (array_agg(middle_rate_per_unit ORDER BY date DESC))[1]::DECIMAL(14, 8) AS month_end_rate_per_unit,
+    MAX(date)::DATE AS last_date,
+    COUNT(*)::INTEGER AS observations
+FROM foreign_exchange
+WHERE
+    currency_code = ANY(sqlc.arg(currency_codes)::text[])
+    AND session = sqlc.arg(session)
+    AND date >= sqlc.arg(start_date)
+    AND date <= sqlc.arg(end_date)
+GROUP BY
+    currency_code, date_trunc('month', date)
+ORDER BY
+    currency_code ASC, month ASC;
+
+-- name: RefreshMonthlyFxRates :exec
+REFRESH MATERIALIZED VIEW CONCURRENTLY monthly_fx_rates;
+
+-- name: RefreshMonthlyStockCloses :exec
+REFRESH MATERIALIZED VIEW CONCURRENTLY monthly_stock_closes;
@@ -0,0 +1,33 @@
+-- name: InsertNewsHeadline :execrows
+-- Stores a headline unless the article is already stored for the company.
+INSERT INTO news_headlines (stock_code, title, link, source, published_at, sentiment)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (stock_code, link) DO NOTHING;
+
+-- name: ListNewsHeadlinesByStockCode :many
+-- Newest first, published within [start_time, end_time).
+SELECT * FROM news_headlines
+WHERE stock_code = sqlc.arg(stock_code)
+  AND published_at >= sqlc.arg(start_time)
+  AND published_at < sqlc.arg(end_time)
+ORDER BY published_at DESC, id DESC
+LIMIT sqlc.arg(max_results);
+
+-- name: ListNewsHeadlineTitles :many
+SELECT id, title FROM news_headlines ORDER BY id;
+
+-- name: UpdateNewsHeadlineSentiment :exec
+UPDATE news_headlines SET sentiment = $2 WHERE id = $1;
+
+-- name: GetDailyNewsSentimentByStockCode :many
+-- Mean headline sentiment per Kuala Lumpur calendar day with at least one headline.
+SELECT
+    (published_at AT TIME ZONE 'Asia/Kuala_Lumpur')::date AS date,
+    AVG(sentiment)::text AS mean_sentiment,
+    COUNT(*) AS headlines
+FROM news_headlines
+WHERE stock_code = sqlc.arg(stock_code)
+  AND (published_at AT TIME ZONE 'Asia/Kuala_Lumpur')::date >= sqlc.arg(start_date)::date
+  AND (published_at AT TIME ZONE 'Asia/Kuala_Lumpur')::date <= sqlc.arg(end_date)::date
+GROUP BY 1
+ORDER BY 1;
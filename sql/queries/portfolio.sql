@@ -0,0 +1,15 @@
+-- name: CreatePortfolioHolding :one
+INSERT INTO portfolio_holdings (
+    id, user_id, stock_code, quantity, cost_basis, trade_date
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ListPortfolioHoldingsByUser :many
+SELECT * FROM portfolio_holdings
+WHERE user_id = $1
+ORDER BY stock_code ASC, trade_date ASC;
+
+-- name: DeletePortfolioHolding :execrows
+DELETE FROM portfolio_holdings
+WHERE id = $1 AND user_id = $2;
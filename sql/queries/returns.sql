@@ -0,0 +1,63 @@
+-- name: UpsertDailyReturn :exec
+INSERT INTO daily_returns (
+    series, date, pct_return, computed_at
+) VALUES (
+    sqlc.arg(series), sqlc.arg(date), sqlc.arg(pct_return), CURRENT_TIMESTAMP
+)
+ON CONFLICT (series, date) DO UPDATE SET
+    pct_return = EXCLUDED.pct_return,
+    computed_at = CURRENT_TIMESTAMP;
+
+-- name: GetLatestDailyReturnDate :one
+SELECT date FROM daily_returns
+WHERE series = sqlc.arg(series)
+ORDER BY date DESC
+LIMIT 1;
+
+-- name: GetEarliestFxDateWithoutReturn :one
+-- Earliest midday rate of a currency that has a positive rate before it but no stored return,
+-- e.g. one backfilled behind the last stored return.
+SELECT f.date FROM (
+    SELECT date, LAG(middle_rate_per_unit) OVER (ORDER BY date) AS prev_rate
+    FROM foreign_exchange
+    WHERE currency_code = sqlc.arg(currency_code) AND session = '1200'
+) f
+WHERE
+    f.prev_rate > 0
+    AND NOT EXISTS (SELECT 1 FROM daily_returns r WHERE r.series = sqlc.arg(series) AND r.date = f.date)
+ORDER BY f.date
+LIMIT 1;
+
+-- name: GetEarliestStockDateWithStaleReturn :one
+-- Earliest close of a stock that has a positive close before it and no stored return (e.g. one
+-- backfilled behind the last stored return), or whose close was stored after its return was
+-- computed (e.g. an intraday price replaced by the post-close fetch).
+SELECT p.price_date FROM (
+    SELECT price_date, extracted_at, LAG(closing_price) OVER (ORDER BY price_date) AS prev_close
+    FROM daily_stock_prices
+    WHERE stock_code = sqlc.arg(stock_code)
+) p
+LEFT JOIN daily_returns r ON r.series = sqlc.arg(series) AND r.date = p.price_date
+WHERE
+    p.prev_close > 0
+    AND (r.date IS NULL OR r.computed_at < p.extracted_at)
+ORDER BY p.price_date
+LIMIT 1;
+
+-- name: GetDailyReturnsBySeriesAndDateRange :many
+SELECT date, pct_return
+FROM daily_returns
+WHERE
+    series = sqlc.arg(series)
+    AND date >= sqlc.arg(start_date)
+    AND date <= sqlc.arg(end_date)
+ORDER BY
+    date ASC;
+
+-- name: ListDailyReturnSeries :many
+SELECT DISTINCT series FROM daily_returns ORDER BY series;
+
+-- name: DeleteDailyReturnsFromDate :exec
+-- Removes a series' returns from a date on, e.g. before prices moved to other dates are recomputed.
+DELETE FROM daily_returns
+WHERE series = sqlc.arg(series) AND date >= sqlc.arg(from_date);
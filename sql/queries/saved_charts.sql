@@ -0,0 +1,46 @@
+-- name: CreateSavedChart :one
+INSERT INTO saved_charts (
+    id, user_id, name, start_date, end_date, lookback, shared
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: UpdateSavedChart :one
+UPDATE saved_charts
+SET
+    name = $3,
+    start_date = $4,
+    end_date = $5,
+    lookback = $6,
+    shared = $7,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: GetSavedChart :one
+SELECT * FROM saved_charts
+WHERE id = $1;
+
+-- name: ListSavedChartsByUser :many
+SELECT * FROM saved_charts
+WHERE user_id = $1
+ORDER BY name ASC;
+
+-- name: DeleteSavedChart :execrows
+DELETE FROM saved_charts
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateSavedChartSeries :exec
+INSERT INTO saved_chart_series (
+    chart_id, position, series_key, transform
+) VALUES (
+    $1, $2, $3, $4
+);
+
+-- name: DeleteSavedChartSeries :exec
+DELETE FROM saved_chart_series WHERE chart_id = $1;
+
+-- name: ListSavedChartSeries :many
+SELECT * FROM saved_chart_series
+WHERE chart_id = $1
+ORDER BY position ASC;
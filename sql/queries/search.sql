@@ -0,0 +1,38 @@
+-- name: Search :many
+-- Companies, news headlines, events (annotations) and report documents matching a
+-- websearch-style query, best match first. kind filters to one of company, news, event or
+-- document, and country to the companies (or events) of one country.
+WITH q AS (
+    SELECT websearch_to_tsquery('english', sqlc.arg(query)) AS english,
+           websearch_to_tsquery('simple', sqlc.arg(query)) AS simple
+)
+SELECT kind, ref, stock_code, country, title, detail, occurred_at, rank FROM (
+    SELECT 'company'::text AS kind, c.stock_code::text AS ref, c.stock_code::text AS stock_code,
+           COALESCE(c.country_code, '')::text AS country,
+           c.company_name::text AS title, COALESCE(c.sector, '')::text AS detail,
+           NULL::timestamptz AS occurred_at,
+           ts_rank(to_tsvector('simple', c.stock_code || ' ' || c.company_name || ' ' || COALESCE(c.sector, '') || ' ' || COALESCE(c.subsector, '')), q.simple) AS rank
+    FROM companies c, q
+    WHERE to_tsvector('simple', c.stock_code || ' ' || c.company_name || ' ' || COALESCE(c.sector, '') || ' ' || COALESCE(c.subsector, '')) @@ q.simple
+    UNION ALL
+    SELECT 'news', n.id::text, n.stock_code::text, COALESCE(c.country_code, '')::text, n.title, n.link, n.published_at,
+           ts_rank(to_tsvector('english', n.title), q.english)
+    FROM news_headlines n
+    LEFT JOIN companies c ON c.stock_code = n.stock_code, q
+    WHERE to_tsvector('english', n.title) @@ q.english
+    UNION ALL
+    SELECT 'event', a.id::text, ''::text, a.country_code::text, a.title::text, a.category::text, a.event_date::timestamptz,
+           ts_rank(to_tsvector('english', a.title || ' ' || a.description), q.english)
+    FROM annotations a, q
+    WHERE to_tsvector('english', a.title || ' ' || a.description) @@ q.english
+    UNION ALL
+    SELECT 'document', d.id::text, d.stock_code::text, COALESCE(c.country_code, '')::text, d.title::text, d.fiscal_year || ' ' || d.period, d.downloaded_at,
+           ts_rank(to_tsvector('english', d.title), q.english)
+    FROM company_documents d
+    LEFT JOIN companies c ON c.stock_code = d.stock_code, q
+    WHERE to_tsvector('english', d.title) @@ q.english
+) results
+WHERE (sqlc.narg(kind)::text IS NULL OR kind = sqlc.narg(kind))
+  AND (sqlc.narg(country)::text IS NULL OR country = sqlc.narg(country))
+ORDER BY rank DESC, occurred_at DESC NULLS LAST
+LIMIT sqlc.arg(max_results);
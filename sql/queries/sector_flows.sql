@@ -0,0 +1,27 @@
+-- name: DeleteSectorFlowsSince :execrows
+-- Clears the sector flows from a date on, before they are re-derived.
+DELETE FROM sector_flows
+WHERE flow_date >= sqlc.arg(start_date);
+
+-- name: InsertSectorFlowsSince :execrows
+-- Derives the daily traded value of each sector from the closes with a captured volume from a
+-- date on. Only scraped closes carry a volume, so every flow is in the quote currency of
+-- DEFAULT_COUNTRY.
+INSERT INTO sector_flows (sector, flow_date, traded_value, volume, stocks, updated_at)
+SELECT
+    COALESCE(NULLIF(c.sector, ''), 'Unclassified') AS sector,
+    dsp.price_date,
+    SUM(dsp.closing_price * dsp.volume)::DECIMAL(20, 2),
+    SUM(dsp.volume)::BIGINT,
+    COUNT(*)::INTEGER,
+    NOW()
+FROM daily_stock_prices dsp
+JOIN companies c ON c.stock_code = dsp.stock_code
+WHERE dsp.price_date >= sqlc.arg(start_date) AND dsp.volume IS NOT NULL
+GROUP BY COALESCE(NULLIF(c.sector, ''), 'Unclassified'), dsp.price_date;
+
+-- name: GetSectorFlowsByDateRange :many
+SELECT sector, flow_date, traded_value, volume, stocks
+FROM sector_flows
+WHERE flow_date >= sqlc.arg(start_date) AND flow_date <= sqlc.arg(end_date)
+ORDER BY sector ASC, flow_date ASC;
@@ -0,0 +1,33 @@
+-- name: CreateSector :execrows
+INSERT INTO sectors (name) VALUES (sqlc.arg(name))
+ON CONFLICT (name) DO NOTHING;
+
+-- name: CreateSubsector :execrows
+INSERT INTO subsectors (sector, name) VALUES (sqlc.arg(sector), sqlc.arg(name))
+ON CONFLICT (sector, name) DO NOTHING;
+
+-- name: ListSectors :many
+SELECT name FROM sectors ORDER BY name;
+
+-- name: ListSubsectors :many
+SELECT sector, name FROM subsectors ORDER BY sector, name;
+
+-- name: UpsertSectorMapping :exec
+-- Merges a scraped label into a canonical sector or subsector.
+INSERT INTO sector_mappings (kind, label, canonical)
+VALUES (sqlc.arg(kind), sqlc.arg(label), sqlc.arg(canonical))
+ON CONFLICT (kind, label) DO UPDATE SET canonical = EXCLUDED.canonical;
+
+-- name: ListSectorMappings :many
+SELECT kind, label, canonical FROM sector_mappings ORDER BY kind, label;
+
+-- name: ListCompanySectorLabels :many
+-- Scraped and normalized sector labels of every company, for review and re-normalization.
+SELECT stock_code, sector_raw, subsector_raw, sector, subsector
+FROM companies
+ORDER BY stock_code;
+
+-- name: SetCompanySectors :exec
+UPDATE companies
+SET sector = sqlc.arg(sector), subsector = sqlc.arg(subsector), updated_at = NOW()
+WHERE stock_code = sqlc.arg(stock_code);
@@ -0,0 +1,19 @@
+-- name: CreateUserSession :one
+INSERT INTO user_sessions (
+    id, user_id, token_hash, created_at, expires_at
+) VALUES (
+    sqlc.arg(id), sqlc.arg(user_id), sqlc.arg(token_hash), CURRENT_TIMESTAMP, sqlc.arg(expires_at)
+) RETURNING *;
+
+-- name: GetUserSessionByTokenHash :one
+-- Returns an unexpired session for the given token hash.
+SELECT * FROM user_sessions
+WHERE token_hash = $1 AND expires_at > NOW();
+
+-- name: DeleteUserSession :exec
+DELETE FROM user_sessions
+WHERE token_hash = $1;
+
+-- name: DeleteExpiredUserSessions :execrows
+DELETE FROM user_sessions
+WHERE expires_at <= NOW();
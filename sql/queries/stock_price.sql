@@ -1,13 +1,16 @@
 -- name: UpsertStockPrice :exec
 INSERT INTO daily_stock_prices (
-    stock_code, price_date, closing_price, source_url, extracted_at
+    stock_code, price_date, closing_price, source_url, extracted_at, fetch_run_id, volume
 ) VALUES (
-    sqlc.arg(stock_code), sqlc.arg(price_date), sqlc.arg(closing_price), sqlc.arg(source_url), CURRENT_TIMESTAMP
+    sqlc.arg(stock_code), sqlc.arg(price_date), sqlc.arg(closing_price), sqlc.arg(source_url), CURRENT_TIMESTAMP, sqlc.narg(fetch_run_id),
+    sqlc.narg(volume)
 )
 ON CONFLICT (stock_code, price_date) DO UPDATE SET
     closing_price = EXCLUDED.closing_price,
     source_url = EXCLUDED.source_url,
-    extracted_at = CURRENT_TIMESTAMP;
+    extracted_at = CURRENT_TIMESTAMP,
+    fetch_run_id = EXCLUDED.fetch_run_id,
+    volume = COALESCE(EXCLUDED.volume, daily_stock_prices.volume); -- Keep a captured volume when a re-fetch has none
 
 -- name: GetStockPrice :one
 SELECT * FROM daily_stock_prices
@@ -29,4 +32,100 @@ WHERE
     AND dsp.price_date >= sqlc.arg(start_date)
     AND dsp.price_date <= sqlc.arg(end_date)
 ORDER BY
-    dsp.price_date ASC;
\ No newline at end of file
+    dsp.price_date ASC;
+
+-- name: GetStockClosingPricesByCodeAndDateRange :many
+-- Closing prices only, without requiring a companies row (used for valuations and analytics).
+SELECT price_date, closing_price
+FROM daily_stock_prices
+WHERE
+    stock_code = sqlc.arg(stock_code)
+    AND price_date >= sqlc.arg(start_date)
+    AND price_date <= sqlc.arg(end_date)
+ORDER BY
+    price_date ASC;
+
+-- name: GetLatestStockPriceOnOrBefore :one
+-- Returns the most recent closing price for a stock on or before the given date.
+SELECT price_date, closing_price
+FROM daily_stock_prices
+WHERE
+    stock_code = sqlc.arg(stock_code)
+    AND price_date <= sqlc.arg(on_date)
+ORDER BY
+    price_date DESC
+LIMIT 1;
+
+-- name: GetStockPriceObservationOnOrBefore :one
+-- The full stored row of the most recent price for a stock on or before a date.
+SELECT * FROM daily_stock_prices
+WHERE
+    stock_code = sqlc.arg(stock_code)
+    AND price_date <= sqlc.arg(on_date)
+ORDER BY
+    price_date DESC
+LIMIT 1;
+
+-- name: GetLatestStockPriceDate :one
+-- Most recent date with any stored stock price.
+SELECT price_date FROM daily_stock_prices
+ORDER BY price_date DESC
+LIMIT 1;
+
+-- name: ListStockCodesWithPrices :many
+-- Stock codes with at least one stored price.
+SELECT DISTINCT stock_code FROM daily_stock_prices
+ORDER BY stock_code;
+
+-- name: ListLatestStockCloses :many
+-- The most recent closing price of every stock.
+SELECT DISTINCT ON (stock_code) stock_code, price_date, closing_price
+FROM daily_stock_prices
+ORDER BY stock_code, price_date DESC;
+
+-- name: ListStockClosesOnOrBefore :many
+-- The most recent closing price on or before a date of each of the given stocks.
+SELECT DISTINCT ON (stock_code) stock_code, price_date, closing_price
+FROM daily_stock_prices
+WHERE
+    stock_code = ANY(sqlc.arg(stock_codes)::text[])
+    AND price_date <= sqlc.arg(on_date)
+ORDER BY stock_code, price_date DESC;
+
+-- name: ListMisdatedStockPrices :many
+-- Scraped prices filed under the UTC date of their extraction when the market (Kuala Lumpur)
+-- date was already the next day, newest first. Pushed (ingest:) prices carry their own dates.
+SELECT * FROM daily_stock_prices
+WHERE
+    price_date = (extracted_at AT TIME ZONE 'UTC')::date
+    AND (extracted_at AT TIME ZONE 'Asia/Kuala_Lumpur')::date > price_date
+    AND (source_url IS NULL OR source_url NOT LIKE 'ingest:%')
+ORDER BY price_date DESC, stock_code;
+
+-- name: ShiftStockPriceDate :execrows
+-- Moves a price to the next day unless the stock already has a price on that day.
+UPDATE daily_stock_prices d
+SET price_date = d.price_date + 1
+WHERE d.id = sqlc.arg(id)
+    AND NOT EXISTS (
+        SELECT 1 FROM daily_stock_prices o
+        WHERE o.stock_code = d.stock_code AND o.price_date = d.price_date + 1
+    );
+
+-- name: DeleteStockPrice :exec
+DELETE FROM daily_stock_prices WHERE id = sqlc.arg(id);
+
+-- name: ListStockPriceDuplicates :many
+-- Prices sharing a stock code (ignoring case and surrounding spaces) and date with another
+-- row. Within each group the row to keep comes first: the canonical code, then the most
+-- recently extracted.
+SELECT id, stock_code, price_date, closing_price, extracted_at
+FROM daily_stock_prices
+WHERE (upper(btrim(stock_code)), price_date) IN (
+    SELECT upper(btrim(stock_code)), price_date
+    FROM daily_stock_prices
+    GROUP BY upper(btrim(stock_code)), price_date
+    HAVING count(*) > 1
+)
+ORDER BY upper(btrim(stock_code)), price_date,
+    (stock_code = upper(btrim(stock_code))) DESC, extracted_at DESC;
@@ -0,0 +1,49 @@
+-- name: RefreshStockRanges52w :execrows
+-- Recomputes the latest close and 52-week high and low of one stock, or of every stock when
+-- stock_code is NULL, from the stored closes.
+INSERT INTO stock_ranges_52w (stock_code, price_date, closing_price, high_52w, high_date, low_52w, low_date, updated_at)
+SELECT l.stock_code, l.price_date, l.closing_price, h.closing_price, h.price_date, lo.closing_price, lo.price_date, NOW()
+FROM (
+    SELECT DISTINCT ON (dsp.stock_code) dsp.stock_code, dsp.price_date, dsp.closing_price
+    FROM daily_stock_prices dsp
+    WHERE sqlc.narg(stock_code)::text IS NULL OR dsp.stock_code = sqlc.narg(stock_code)
+    ORDER BY dsp.stock_code, dsp.price_date DESC
+) l
+CROSS JOIN LATERAL (
+    SELECT d.price_date, d.closing_price FROM daily_stock_prices d
+    WHERE d.stock_code = l.stock_code AND d.price_date > l.price_date - INTERVAL '52 weeks' AND d.price_date <= l.price_date
+    ORDER BY d.closing_price DESC, d.price_date DESC
+    LIMIT 1
+) h
+CROSS JOIN LATERAL (
+    SELECT d.price_date, d.closing_price FROM daily_stock_prices d
+    WHERE d.stock_code = l.stock_code AND d.price_date > l.price_date - INTERVAL '52 weeks' AND d.price_date <= l.price_date
+    ORDER BY d.closing_price ASC, d.price_date DESC
+    LIMIT 1
+) lo
+ON CONFLICT (stock_code) DO UPDATE SET
+    price_date = EXCLUDED.price_date,
+    closing_price = EXCLUDED.closing_price,
+    high_52w = EXCLUDED.high_52w,
+    high_date = EXCLUDED.high_date,
+    low_52w = EXCLUDED.low_52w,
+    low_date = EXCLUDED.low_date,
+    updated_at = NOW();
+
+-- name: ListStockRanges52w :many
+-- The latest close and 52-week range of every stock, optionally of one country.
+SELECT
+    r.stock_code,
+    c.company_name,
+    c.country_code,
+    c.sector,
+    r.price_date,
+    r.closing_price,
+    r.high_52w,
+    r.high_date,
+    r.low_52w,
+    r.low_date
+FROM stock_ranges_52w r
+JOIN companies c ON c.stock_code = r.stock_code
+WHERE sqlc.narg(country_code)::text IS NULL OR c.country_code = sqlc.narg(country_code)
+ORDER BY r.stock_code;
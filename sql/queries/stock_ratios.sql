@@ -0,0 +1,45 @@
+-- name: UpsertStockRatios :exec
+INSERT INTO stock_ratios (
+    stock_code, ratio_date, roe, nta, dividend_yield, price_to_book, price_to_earnings, source_url, fetch_run_id, extracted_at
+) VALUES (
+    sqlc.arg(stock_code), sqlc.arg(ratio_date), sqlc.narg(roe), sqlc.narg(nta), sqlc.narg(dividend_yield),
+    sqlc.narg(price_to_book), sqlc.narg(price_to_earnings), sqlc.arg(source_url), sqlc.narg(fetch_run_id), CURRENT_TIMESTAMP
+)
+ON CONFLICT (stock_code, ratio_date) DO UPDATE SET
+    roe = EXCLUDED.roe,
+    nta = EXCLUDED.nta,
+    dividend_yield = EXCLUDED.dividend_yield,
+    price_to_book = EXCLUDED.price_to_book,
+    price_to_earnings = EXCLUDED.price_to_earnings,
+    source_url = EXCLUDED.source_url,
+    fetch_run_id = EXCLUDED.fetch_run_id,
+    extracted_at = CURRENT_TIMESTAMP;
+
+-- name: ListScreenerRows :many
+-- Every company with its latest stored ratios, its latest close and the highest and lowest
+-- closes of the 52 weeks up to that close, for the stock screener.
+SELECT
+    c.stock_code,
+    c.company_name,
+    c.country_code,
+    c.sector,
+    c.subsector,
+    r.ratio_date,
+    r.roe,
+    r.dividend_yield,
+    r.price_to_book,
+    r.price_to_earnings,
+    g.price_date,
+    g.closing_price,
+    g.high_52w,
+    g.low_52w
+FROM companies c
+LEFT JOIN LATERAL (
+    SELECT sr.ratio_date, sr.roe, sr.dividend_yield, sr.price_to_book, sr.price_to_earnings
+    FROM stock_ratios sr
+    WHERE sr.stock_code = c.stock_code
+    ORDER BY sr.ratio_date DESC
+    LIMIT 1
+) r ON TRUE
+LEFT JOIN stock_ranges_52w g ON g.stock_code = c.stock_code
+ORDER BY c.stock_code ASC;
@@ -0,0 +1,14 @@
+-- name: UpsertTelegramLinkToken :exec
+-- Issues a link token for a user, replacing any pending one.
+INSERT INTO telegram_link_tokens (user_id, token_hash, expires_at)
+VALUES (sqlc.arg(user_id), sqlc.arg(token_hash), sqlc.arg(expires_at))
+ON CONFLICT (user_id) DO UPDATE SET
+    token_hash = EXCLUDED.token_hash,
+    expires_at = EXCLUDED.expires_at;
+
+-- name: ClaimTelegramLinkToken :one
+-- Consumes an unexpired link token, returning the user it was issued to. Each token links
+-- at most one chat.
+DELETE FROM telegram_link_tokens
+WHERE token_hash = sqlc.arg(token_hash) AND expires_at > NOW()
+RETURNING user_id;
@@ -0,0 +1,12 @@
+-- name: AddTrackedInstrument :execrows
+INSERT INTO tracked_instruments (item_type, code, added_by, country_code)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (item_type, code) DO NOTHING;
+
+-- name: RemoveTrackedInstrument :execrows
+DELETE FROM tracked_instruments
+WHERE item_type = $1 AND code = $2;
+
+-- name: ListTrackedInstruments :many
+SELECT * FROM tracked_instruments
+ORDER BY item_type, code;
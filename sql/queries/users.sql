@@ -4,8 +4,44 @@ INSERT INTO users (
     username,
     email,
     hashed_password,
-    created_at
+    created_at,
+    role
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
+
+-- name: GetUserByUsername :one
+SELECT * FROM users
+WHERE username = $1;
+
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1;
+
+-- name: ListUsers :many
+SELECT * FROM users
+ORDER BY username ASC;
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+-- name: LockUsers :exec
+-- Blocks other writers to users until the transaction ends, so a registration's check for
+-- an empty table holds until its insert commits.
+LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = sqlc.arg(role)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: DeleteUserByUsername :execrows
+DELETE FROM users
+WHERE username = $1;
+
+-- name: SetUserTelegramChatID :exec
+UPDATE users
+SET telegram_chat_id = sqlc.arg(telegram_chat_id)
+WHERE id = sqlc.arg(id);
@@ -0,0 +1,44 @@
+-- name: UpsertRollingVolatility :exec
+INSERT INTO rolling_volatility (
+    series, window_days, date, volatility, computed_at
+) VALUES (
+    sqlc.arg(series), sqlc.arg(window_days), sqlc.arg(date), sqlc.arg(volatility), CURRENT_TIMESTAMP
+)
+ON CONFLICT (series, window_days, date) DO UPDATE SET
+    volatility = EXCLUDED.volatility,
+    computed_at = CURRENT_TIMESTAMP;
+
+-- name: GetLatestRollingVolatilityDate :one
+SELECT date FROM rolling_volatility
+WHERE series = sqlc.arg(series) AND window_days = sqlc.arg(window_days)
+ORDER BY date DESC
+LIMIT 1;
+
+-- name: GetEarliestReturnRecomputedAfterVolatility :one
+-- Earliest return of a series stored or recomputed since its volatility window was last
+-- written, e.g. after rates were backfilled behind the last stored value.
+SELECT r.date FROM daily_returns r
+WHERE
+    r.series = sqlc.arg(series)
+    AND r.computed_at > (
+        SELECT MAX(v.computed_at) FROM rolling_volatility v
+        WHERE v.series = sqlc.arg(series) AND v.window_days = sqlc.arg(window_days)
+    )
+ORDER BY r.date
+LIMIT 1;
+
+-- name: GetRollingVolatilityBySeriesAndDateRange :many
+SELECT date, volatility
+FROM rolling_volatility
+WHERE
+    series = sqlc.arg(series)
+    AND window_days = sqlc.arg(window_days)
+    AND date >= sqlc.arg(start_date)
+    AND date <= sqlc.arg(end_date)
+ORDER BY
+    date ASC;
+
+-- name: DeleteRollingVolatilityFromDate :exec
+-- Removes a series' volatility in every window from a date on, e.g. before its returns are recomputed.
+DELETE FROM rolling_volatility
+WHERE series = sqlc.arg(series) AND date >= sqlc.arg(from_date);
@@ -0,0 +1,19 @@
+-- name: AddWatchlistItem :one
+-- Adding an item that is already on the list returns the existing row.
+INSERT INTO watchlist_items (
+    id, user_id, item_type, code
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (user_id, item_type, code) DO UPDATE
+SET added_at = watchlist_items.added_at
+RETURNING *;
+
+-- name: ListWatchlistItemsByUser :many
+SELECT * FROM watchlist_items
+WHERE user_id = $1
+ORDER BY item_type ASC, code ASC;
+
+-- name: DeleteWatchlistItem :execrows
+DELETE FROM watchlist_items
+WHERE user_id = $1 AND item_type = $2 AND code = $3;
@@ -0,0 +1,18 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (
+    id, url, secret, event_types
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListWebhooks :many
+SELECT * FROM webhooks
+ORDER BY created_at ASC;
+
+-- name: ListActiveWebhooksForEvent :many
+SELECT * FROM webhooks
+WHERE active AND sqlc.arg(event_type)::text = ANY(event_types);
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1;
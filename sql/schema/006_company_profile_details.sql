@@ -0,0 +1,16 @@
+-- +goose Up
+-- Additional profile fields scraped from the i3investor stock overview page.
+ALTER TABLE companies
+ADD COLUMN website VARCHAR(512) NULL,           -- Company website URL
+ADD COLUMN par_value DECIMAL(12, 4) NULL,       -- Par value per share (in listing currency)
+ADD COLUMN shares_outstanding BIGINT NULL;      -- Number of shares outstanding
+
+COMMENT ON COLUMN companies.website IS 'The company website URL as listed on the profile page.';
+COMMENT ON COLUMN companies.par_value IS 'The par value per share.';
+COMMENT ON COLUMN companies.shares_outstanding IS 'The number of shares outstanding at the time of the last profile scrape.';
+
+-- +goose Down
+ALTER TABLE companies
+DROP COLUMN IF EXISTS shares_outstanding,
+DROP COLUMN IF EXISTS par_value,
+DROP COLUMN IF EXISTS website;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- BNM quotes some currencies per 100 units (JPY, IDR, KRW, ...). Store the quoted unit and
+-- a normalized MYR-per-1-unit middle rate so consumers don't have to know the convention.
+ALTER TABLE foreign_exchange
+ADD COLUMN unit INTEGER NOT NULL DEFAULT 1,
+ADD COLUMN middle_rate_per_unit DECIMAL(14, 8) GENERATED ALWAYS AS (middle_rate / unit) STORED;
+
+COMMENT ON COLUMN foreign_exchange.unit IS 'Number of foreign currency units the quoted rates apply to (e.g. 100 for JPY).';
+COMMENT ON COLUMN foreign_exchange.middle_rate_per_unit IS 'Middle rate normalized to MYR per 1 unit of the foreign currency.';
+
+-- NOTE: Rows stored before this migration default to unit = 1. Re-run fx:fetch:range over
+-- the affected history to correct per-100 currencies; the upsert refreshes the unit.
+
+-- +goose Down
+ALTER TABLE foreign_exchange
+DROP COLUMN IF EXISTS middle_rate_per_unit,
+DROP COLUMN IF EXISTS unit;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Record which provider each FX observation came from. BNM is the reference source;
+-- third-party fallbacks (Frankfurter, exchangerate.host) are only used when BNM is unavailable.
+ALTER TABLE foreign_exchange
+ADD COLUMN source VARCHAR(32) NOT NULL DEFAULT 'bnm';
+
+COMMENT ON COLUMN foreign_exchange.source IS 'Provider the rate was fetched from (bnm, frankfurter, exchangeratehost).';
+
+CREATE INDEX idx_fx_source ON foreign_exchange (source);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_fx_source;
+ALTER TABLE foreign_exchange
+DROP COLUMN IF EXISTS source;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Derived trade-weighted effective exchange rate indices for MYR, recomputed by the scheduler.
+CREATE TABLE effective_exchange_rates (
+    index_type VARCHAR(8) NOT NULL,         -- 'neer' (nominal) or 'reer' (real)
+    date DATE NOT NULL,                     -- Observation date
+    value DECIMAL(14, 6) NOT NULL,          -- Index value (base date = 100)
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (index_type, date)
+);
+
+COMMENT ON TABLE effective_exchange_rates IS 'Trade-weighted MYR effective exchange rate indices derived from foreign_exchange.';
+COMMENT ON COLUMN effective_exchange_rates.index_type IS 'neer for the nominal index; reer once relative price indices are available.';
+COMMENT ON COLUMN effective_exchange_rates.value IS 'Index value, 100 at the base date. Higher means a stronger ringgit.';
+
+-- +goose Down
+DROP TABLE IF EXISTS effective_exchange_rates;
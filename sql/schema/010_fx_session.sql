@@ -0,0 +1,16 @@
+-- +goose Up
+-- BNM publishes rates at several sessions per day (0900, 1200, 1700). Store each session as
+-- its own observation. Existing rows were all fetched from the 1200 session.
+ALTER TABLE foreign_exchange
+ADD COLUMN session VARCHAR(4) NOT NULL DEFAULT '1200';
+
+COMMENT ON COLUMN foreign_exchange.session IS 'BNM publication session (0900, 1200, 1700). Third-party daily rates are stored as 1200.';
+
+ALTER TABLE foreign_exchange DROP CONSTRAINT uq_currency_date;
+ALTER TABLE foreign_exchange ADD CONSTRAINT uq_currency_date_session UNIQUE (currency_code, date, session);
+
+-- +goose Down
+DELETE FROM foreign_exchange WHERE session <> '1200';
+ALTER TABLE foreign_exchange DROP CONSTRAINT IF EXISTS uq_currency_date_session;
+ALTER TABLE foreign_exchange ADD CONSTRAINT uq_currency_date UNIQUE (currency_code, date);
+ALTER TABLE foreign_exchange DROP COLUMN IF EXISTS session;
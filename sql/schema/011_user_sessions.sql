@@ -0,0 +1,17 @@
+-- +goose Up
+-- Login sessions for users. Only a SHA-256 hash of the session token is stored.
+CREATE TABLE user_sessions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+COMMENT ON TABLE user_sessions IS 'Active login sessions; rows are removed on logout or after expiry.';
+COMMENT ON COLUMN user_sessions.token_hash IS 'Hex-encoded SHA-256 of the session token handed to the client.';
+
+CREATE INDEX idx_user_sessions_user_id ON user_sessions (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_sessions;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Role-based access control: admin > editor > viewer. The earliest registered user is
+-- promoted to admin so an existing install keeps someone able to manage roles.
+ALTER TABLE users
+ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'viewer'
+    CHECK (role IN ('admin', 'editor', 'viewer'));
+
+COMMENT ON COLUMN users.role IS 'Access role: admin, editor or viewer.';
+
+UPDATE users SET role = 'admin'
+WHERE id = (SELECT id FROM users ORDER BY created_at ASC LIMIT 1);
+
+-- API keys for non-interactive clients. Each key belongs to a user but carries its own role.
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    role VARCHAR(16) NOT NULL DEFAULT 'viewer'
+        CHECK (role IN ('admin', 'editor', 'viewer')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE
+);
+
+COMMENT ON TABLE api_keys IS 'API keys for non-interactive clients; only a SHA-256 hash of the key is stored.';
+COMMENT ON COLUMN api_keys.role IS 'Access role granted to requests using this key.';
+
+CREATE INDEX idx_api_keys_user_id ON api_keys (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_keys;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
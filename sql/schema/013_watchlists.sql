@@ -0,0 +1,16 @@
+-- +goose Up
+-- Per-user watchlists of stock codes and currencies for the dashboard and digests.
+CREATE TABLE watchlist_items (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    item_type VARCHAR(10) NOT NULL CHECK (item_type IN ('stock', 'fx')),
+    code VARCHAR(20) NOT NULL,
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT uq_watchlist_user_item UNIQUE (user_id, item_type, code)
+);
+
+COMMENT ON TABLE watchlist_items IS 'Stock codes and currencies each user is watching.';
+COMMENT ON COLUMN watchlist_items.item_type IS 'stock (code is a stock code) or fx (code is an ISO currency code).';
+
+-- +goose Down
+DROP TABLE IF EXISTS watchlist_items;
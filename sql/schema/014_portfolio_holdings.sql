@@ -0,0 +1,21 @@
+-- +goose Up
+-- Each row is one purchase lot; a position in a stock is the sum of its lots.
+CREATE TABLE portfolio_holdings (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    stock_code VARCHAR(20) NOT NULL,
+    quantity DECIMAL(18, 4) NOT NULL CHECK (quantity > 0),
+    cost_basis DECIMAL(12, 4) NOT NULL CHECK (cost_basis >= 0),
+    trade_date DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+COMMENT ON TABLE portfolio_holdings IS 'Stock purchase lots per user, valued against daily_stock_prices.';
+COMMENT ON COLUMN portfolio_holdings.quantity IS 'Number of shares in the lot.';
+COMMENT ON COLUMN portfolio_holdings.cost_basis IS 'Price paid per share in MYR.';
+COMMENT ON COLUMN portfolio_holdings.trade_date IS 'Date the lot was bought; it is excluded from valuations before this date.';
+
+CREATE INDEX idx_portfolio_holdings_user_id ON portfolio_holdings (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS portfolio_holdings;
@@ -0,0 +1,39 @@
+-- +goose Up
+-- User-defined threshold alerts on stored series, evaluated after each fetch cycle.
+CREATE TABLE alert_rules (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    series VARCHAR(40) NOT NULL,
+    condition VARCHAR(2) NOT NULL CHECK (condition IN ('>', '>=', '<', '<=')),
+    threshold DECIMAL(18, 6) NOT NULL,
+    is_triggered BOOLEAN NOT NULL DEFAULT FALSE,
+    last_triggered_at TIMESTAMP WITH TIME ZONE NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+COMMENT ON TABLE alert_rules IS 'Threshold alerts on stored series, owned by a user.';
+COMMENT ON COLUMN alert_rules.series IS 'Series key: stock:<code> (closing price) or fx:<currency> (MYR per unit).';
+COMMENT ON COLUMN alert_rules.is_triggered IS 'True while the condition holds; the rule fires again only after it has cleared.';
+
+CREATE INDEX idx_alert_rules_user_id ON alert_rules (user_id);
+
+-- Each time a rule's condition becomes true, one event is recorded.
+CREATE TABLE alert_events (
+    id UUID PRIMARY KEY,
+    rule_id UUID NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    series VARCHAR(40) NOT NULL,
+    condition VARCHAR(2) NOT NULL,
+    threshold DECIMAL(18, 6) NOT NULL,
+    observed_value DECIMAL(18, 6) NOT NULL,
+    observed_date DATE NOT NULL,
+    triggered_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+COMMENT ON TABLE alert_events IS 'Triggered alerts, with the rule as it was when it fired.';
+
+CREATE INDEX idx_alert_events_user_id_triggered_at ON alert_events (user_id, triggered_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS alert_events;
+DROP TABLE IF EXISTS alert_rules;
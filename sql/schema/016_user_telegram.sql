@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN telegram_chat_id BIGINT NULL;
+
+COMMENT ON COLUMN users.telegram_chat_id IS 'Telegram chat that receives this user''s alerts (set with telegram:link).';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS telegram_chat_id;
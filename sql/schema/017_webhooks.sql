@@ -0,0 +1,17 @@
+-- +goose Up
+-- Outbound webhooks: each subscribes a URL to one or more event types. Payloads are signed
+-- with an HMAC-SHA256 of the body using the webhook's secret.
+CREATE TABLE webhooks (
+    id UUID PRIMARY KEY,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    event_types TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+COMMENT ON TABLE webhooks IS 'Outbound webhook subscriptions, managed by admins.';
+COMMENT ON COLUMN webhooks.event_types IS 'Subscribed events: data.stored, alert.triggered, fetch.failed.';
+
+-- +goose Down
+DROP TABLE IF EXISTS webhooks;
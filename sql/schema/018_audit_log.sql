@@ -0,0 +1,21 @@
+-- +goose Up
+-- Authenticated actions (logins, deletions, role/key/webhook changes, manual fetches).
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    username VARCHAR(255) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    source VARCHAR(10) NOT NULL CHECK (source IN ('cli', 'api')),
+    remote_addr VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+COMMENT ON TABLE audit_log IS 'Authenticated user actions, queryable by admins.';
+COMMENT ON COLUMN audit_log.username IS 'Username at the time of the action; kept after the user is deleted.';
+COMMENT ON COLUMN audit_log.action IS 'CLI command name or equivalent (e.g. login, users:delete, fx:fetch_all).';
+
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- One row per batch fetch command (fx:fetch_all, fx:fetch:range, stock:fetch:*_all), inserted
+-- when the batch starts and completed with its counters when it ends.
+CREATE TABLE fetch_runs (
+    id UUID PRIMARY KEY,
+    command VARCHAR(64) NOT NULL,
+    args TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'succeeded', 'partial', 'failed')),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NULL,
+    successful_fetches INTEGER NOT NULL DEFAULT 0,
+    failed_fetches INTEGER NOT NULL DEFAULT 0,
+    successful_stores INTEGER NOT NULL DEFAULT 0,
+    failed_stores INTEGER NOT NULL DEFAULT 0,
+    error_samples TEXT[] NOT NULL DEFAULT '{}'
+);
+
+COMMENT ON TABLE fetch_runs IS 'Summary of each batch fetch run.';
+COMMENT ON COLUMN fetch_runs.status IS 'running, succeeded, partial (some fetches or stores failed) or failed.';
+COMMENT ON COLUMN fetch_runs.error_samples IS 'The first few errors of the run; the full list is in the logs.';
+
+CREATE INDEX idx_fetch_runs_command_started_at ON fetch_runs (command, started_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS fetch_runs;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Daily percentage returns derived from stored closes (stocks) and noon middle rates (FX),
+-- computed after each fetch so analytics endpoints can read them instead of recomputing.
+CREATE TABLE daily_returns (
+    series VARCHAR(32) NOT NULL,
+    date DATE NOT NULL,
+    pct_return DECIMAL(14, 8) NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (series, date)
+);
+
+COMMENT ON TABLE daily_returns IS 'Daily percentage returns per series, derived from daily_stock_prices and foreign_exchange.';
+COMMENT ON COLUMN daily_returns.series IS 'Series key: stock:<code> or fx:<currency>.';
+COMMENT ON COLUMN daily_returns.pct_return IS 'Percentage change from the previous observation (1.5 = +1.5%).';
+
+-- +goose Down
+DROP TABLE IF EXISTS daily_returns;
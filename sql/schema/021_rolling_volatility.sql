@@ -0,0 +1,18 @@
+-- +goose Up
+-- Rolling annualized volatility derived from daily_returns over 20, 60 and 250 trading days.
+CREATE TABLE rolling_volatility (
+    series VARCHAR(32) NOT NULL,
+    window_days INTEGER NOT NULL,
+    date DATE NOT NULL,
+    volatility DECIMAL(14, 8) NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (series, window_days, date)
+);
+
+COMMENT ON TABLE rolling_volatility IS 'Rolling volatility per series and window, derived from daily_returns.';
+COMMENT ON COLUMN rolling_volatility.series IS 'Series key: stock:<code> or fx:<currency>.';
+COMMENT ON COLUMN rolling_volatility.window_days IS 'Number of daily returns in the trailing window.';
+COMMENT ON COLUMN rolling_volatility.volatility IS 'Annualized standard deviation of daily returns, in percent.';
+
+-- +goose Down
+DROP TABLE IF EXISTS rolling_volatility;
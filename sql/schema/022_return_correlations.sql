@@ -0,0 +1,22 @@
+-- +goose Up
+-- Latest pairwise correlations of daily returns, recomputed by the scheduled correlation job
+-- so the default matrix can be served without loading every series.
+CREATE TABLE return_correlations (
+    series_a VARCHAR(32) NOT NULL,
+    series_b VARCHAR(32) NOT NULL,
+    window_days INTEGER NOT NULL,
+    as_of DATE NOT NULL,
+    correlation DECIMAL(10, 8) NOT NULL,
+    observations INTEGER NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (series_a, series_b, window_days),
+    CHECK (series_a < series_b)
+);
+
+COMMENT ON TABLE return_correlations IS 'Pairwise correlations of daily_returns, one row per unordered pair and window.';
+COMMENT ON COLUMN return_correlations.series_a IS 'Series key that sorts first; pairs are stored once.';
+COMMENT ON COLUMN return_correlations.as_of IS 'Last date of the window the correlation was computed over.';
+COMMENT ON COLUMN return_correlations.observations IS 'Number of dates on which both series had a return.';
+
+-- +goose Down
+DROP TABLE IF EXISTS return_correlations;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Monthly (or other periodic) macroeconomic series such as CPI, fetched from OpenDOSM.
+CREATE TABLE macro_observations (
+    series VARCHAR(32) NOT NULL,
+    period DATE NOT NULL,
+    value DECIMAL(20, 6) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    fetched_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (series, period)
+);
+
+COMMENT ON TABLE macro_observations IS 'Periodic macroeconomic observations, one row per series and period.';
+COMMENT ON COLUMN macro_observations.series IS 'Series code, e.g. cpi.';
+COMMENT ON COLUMN macro_observations.period IS 'First day of the period the value covers.';
+COMMENT ON COLUMN macro_observations.source IS 'Dataset the value came from, e.g. opendosm:cpi_headline.';
+
+-- +goose Down
+DROP TABLE IF EXISTS macro_observations;
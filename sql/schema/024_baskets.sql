@@ -0,0 +1,38 @@
+-- +goose Up
+-- User-defined baskets of stocks tracked as a single composite index series.
+CREATE TABLE baskets (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    base_date DATE NOT NULL,
+    base_value DECIMAL(12, 4) NOT NULL DEFAULT 100 CHECK (base_value > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE basket_components (
+    basket_id UUID NOT NULL REFERENCES baskets(id) ON DELETE CASCADE,
+    stock_code VARCHAR(20) NOT NULL,
+    weight DECIMAL(10, 6) NOT NULL CHECK (weight > 0),
+    PRIMARY KEY (basket_id, stock_code)
+);
+
+CREATE TABLE basket_values (
+    basket_id UUID NOT NULL REFERENCES baskets(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    value DECIMAL(18, 6) NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (basket_id, date)
+);
+
+COMMENT ON TABLE baskets IS 'User-defined weighted baskets of stocks, tracked as a composite index.';
+COMMENT ON COLUMN baskets.base_date IS 'The index starts at base_value on the first date every component has a price on or after this.';
+COMMENT ON TABLE basket_components IS 'Stocks in a basket with their weights (normalized to sum to 1 when computed).';
+COMMENT ON TABLE basket_values IS 'Daily composite index values per basket, recomputed after each fetch.';
+
+CREATE INDEX idx_baskets_user_id ON baskets (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS basket_values;
+DROP TABLE IF EXISTS basket_components;
+DROP TABLE IF EXISTS baskets;
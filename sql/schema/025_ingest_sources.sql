@@ -0,0 +1,17 @@
+-- +goose Up
+-- External scripts registered to push observations of one series through POST /api/ingest.
+-- Payloads are signed with an HMAC-SHA256 of the body using the source's secret.
+CREATE TABLE ingest_sources (
+    name VARCHAR(50) PRIMARY KEY,
+    series VARCHAR(40) NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    last_ingested_at TIMESTAMP WITH TIME ZONE
+);
+
+COMMENT ON TABLE ingest_sources IS 'Registered push ingestion sources, managed by admins.';
+COMMENT ON COLUMN ingest_sources.series IS 'Series the source feeds: stock:<code>, fx:<currency> or macro:<series>.';
+
+-- +goose Down
+DROP TABLE IF EXISTS ingest_sources;
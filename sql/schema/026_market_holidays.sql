@@ -0,0 +1,32 @@
+-- +goose Up
+-- Weekdays on which Bursa Malaysia is closed. Weekends are never stored. The seed covers the
+-- fixed-date public holidays; movable ones (Chinese New Year, Hari Raya, Deepavali, ...) come
+-- from the Bursa calendar via market:holidays:fetch.
+CREATE TABLE market_holidays (
+    holiday_date DATE PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    source VARCHAR(20) NOT NULL DEFAULT 'seed',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+COMMENT ON TABLE market_holidays IS 'Weekdays on which Bursa Malaysia does not trade.';
+COMMENT ON COLUMN market_holidays.source IS 'Where the entry came from: seed or bursa.';
+
+INSERT INTO market_holidays (holiday_date, name) VALUES
+    ('2024-01-01', 'New Year''s Day'),
+    ('2024-05-01', 'Labour Day'),
+    ('2024-09-16', 'Malaysia Day'),
+    ('2024-12-25', 'Christmas Day'),
+    ('2025-01-01', 'New Year''s Day'),
+    ('2025-05-01', 'Labour Day'),
+    ('2025-09-01', 'National Day (observed)'),
+    ('2025-09-16', 'Malaysia Day'),
+    ('2025-12-25', 'Christmas Day'),
+    ('2026-01-01', 'New Year''s Day'),
+    ('2026-05-01', 'Labour Day'),
+    ('2026-08-31', 'National Day'),
+    ('2026-09-16', 'Malaysia Day'),
+    ('2026-12-25', 'Christmas Day');
+
+-- +goose Down
+DROP TABLE IF EXISTS market_holidays;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Items of a batch fetch (a stock code, an FX month window) completed on a market date, so a
+-- re-run on the same day after a crash can skip them.
+CREATE TABLE fetch_checkpoints (
+    command VARCHAR(64) NOT NULL,
+    run_date DATE NOT NULL,
+    item VARCHAR(100) NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (command, run_date, item)
+);
+
+COMMENT ON TABLE fetch_checkpoints IS 'Batch fetch items completed per market date, skipped when the batch is re-run that day.';
+COMMENT ON COLUMN fetch_checkpoints.item IS 'Command-specific item key, e.g. a stock code or "USD 1200 2024-01-01..2024-01-31".';
+
+-- +goose Down
+DROP TABLE IF EXISTS fetch_checkpoints;
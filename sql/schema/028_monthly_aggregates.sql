@@ -0,0 +1,40 @@
+-- +goose Up
+-- Monthly aggregates for interval=monthly API requests, which would otherwise scan every
+-- daily row of a long range. Refreshed by the maintenance job (db:maintenance), so they may
+-- lag the daily tables until it next runs.
+CREATE MATERIALIZED VIEW monthly_fx_rates AS
+SELECT
+    currency_code,
+    session,
+    date_trunc('month', date)::DATE AS month,
+    AVG(middle_rate_per_unit)::DECIMAL(14, 8) AS avg_middle_rate_per_unit,
+    COUNT(*)::INTEGER AS observations,
+    MAX(date)::DATE AS last_date
+FROM foreign_exchange
+GROUP BY currency_code, session, date_trunc('month', date);
+
+-- Unique, so the view can be refreshed concurrently without blocking readers
+CREATE UNIQUE INDEX idx_monthly_fx_rates ON monthly_fx_rates (currency_code, session, month);
+
+COMMENT ON MATERIALIZED VIEW monthly_fx_rates IS 'Average MYR-per-unit middle rate of each currency, session and month.';
+COMMENT ON COLUMN monthly_fx_rates.month IS 'First day of the month.';
+COMMENT ON COLUMN monthly_fx_rates.last_date IS 'Last date in the month with a stored rate.';
+
+CREATE MATERIALIZED VIEW monthly_stock_closes AS
+SELECT DISTINCT ON (stock_code, date_trunc('month', price_date))
+    stock_code,
+    date_trunc('month', price_date)::DATE AS month,
+    price_date AS last_date,
+    closing_price
+FROM daily_stock_prices
+ORDER BY stock_code, date_trunc('month', price_date), price_date DESC;
+
+CREATE UNIQUE INDEX idx_monthly_stock_closes ON monthly_stock_closes (stock_code, month);
+
+COMMENT ON MATERIALIZED VIEW monthly_stock_closes IS 'Closing price of each stock on the last stored trading day of each month.';
+COMMENT ON COLUMN monthly_stock_closes.month IS 'First day of the month.';
+COMMENT ON COLUMN monthly_stock_closes.last_date IS 'Trading day the closing price is from.';
+
+-- +goose Down
+DROP MATERIALIZED VIEW IF EXISTS monthly_stock_closes;
+DROP MATERIALIZED VIEW IF EXISTS monthly_fx_rates;
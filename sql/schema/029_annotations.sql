@@ -0,0 +1,39 @@
+-- +goose Up
+-- Macro events (OPR decisions, budget days, elections) that charts mark on price and FX
+-- series. The seed covers recent well-known events; more are added with annotations:add.
+CREATE TABLE annotations (
+    id SERIAL PRIMARY KEY,
+    event_date DATE NOT NULL,
+    category VARCHAR(20) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    source VARCHAR(255) NOT NULL DEFAULT 'seed',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (event_date, category, title)
+);
+
+CREATE INDEX idx_annotations_event_date ON annotations (event_date);
+
+COMMENT ON TABLE annotations IS 'Macro events shown as markers on charts.';
+COMMENT ON COLUMN annotations.category IS 'Kind of event: opr, budget, election or other.';
+COMMENT ON COLUMN annotations.source IS 'Where the entry came from: seed or the username that added it.';
+
+INSERT INTO annotations (event_date, category, title) VALUES
+    ('2018-05-09', 'election', 'GE14'),
+    ('2020-01-22', 'opr', 'OPR cut to 2.75%'),
+    ('2020-03-03', 'opr', 'OPR cut to 2.50%'),
+    ('2020-05-05', 'opr', 'OPR cut to 2.00%'),
+    ('2020-07-07', 'opr', 'OPR cut to 1.75%'),
+    ('2022-05-11', 'opr', 'OPR raised to 2.00%'),
+    ('2022-07-06', 'opr', 'OPR raised to 2.25%'),
+    ('2022-09-08', 'opr', 'OPR raised to 2.50%'),
+    ('2022-11-03', 'opr', 'OPR raised to 2.75%'),
+    ('2022-11-19', 'election', 'GE15'),
+    ('2023-02-24', 'budget', 'Budget 2023 tabled'),
+    ('2023-05-03', 'opr', 'OPR raised to 3.00%'),
+    ('2023-10-13', 'budget', 'Budget 2024 tabled'),
+    ('2024-10-18', 'budget', 'Budget 2025 tabled'),
+    ('2025-07-09', 'opr', 'OPR cut to 2.75%');
+
+-- +goose Down
+DROP TABLE IF EXISTS annotations;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Stock codes and currencies added at runtime (tracked:add or the admin page). Batch fetches
+-- cover these in addition to STOCK_LIST, so the list can change without a redeploy.
+CREATE TABLE tracked_instruments (
+    item_type VARCHAR(10) NOT NULL CHECK (item_type IN ('stock', 'fx')),
+    code VARCHAR(20) NOT NULL,
+    added_by VARCHAR(255) NOT NULL,
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (item_type, code)
+);
+
+COMMENT ON TABLE tracked_instruments IS 'Stock codes and currencies fetched in addition to STOCK_LIST.';
+COMMENT ON COLUMN tracked_instruments.item_type IS 'stock (code is a stock code) or fx (code is an ISO currency code).';
+COMMENT ON COLUMN tracked_instruments.added_by IS 'Username that added the instrument.';
+
+-- +goose Down
+DROP TABLE IF EXISTS tracked_instruments;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Headlines from the NEWS_FEED_URLS RSS feeds that mention a tracked company, one row per
+-- company and article, so prices can be reviewed against the news.
+CREATE TABLE news_headlines (
+    id BIGSERIAL PRIMARY KEY,
+    stock_code VARCHAR(20) NOT NULL REFERENCES companies(stock_code) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    link TEXT NOT NULL,
+    source VARCHAR(255) NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    fetched_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT uq_news_headline_stock_link UNIQUE (stock_code, link)
+);
+
+CREATE INDEX idx_news_headlines_stock_published ON news_headlines (stock_code, published_at DESC);
+
+COMMENT ON TABLE news_headlines IS 'Feed headlines mentioning a tracked company.';
+COMMENT ON COLUMN news_headlines.source IS 'Title of the feed the headline came from.';
+COMMENT ON COLUMN news_headlines.published_at IS 'Publication time from the feed, or the fetch time when the feed has none.';
+
+-- +goose Down
+DROP TABLE IF EXISTS news_headlines;
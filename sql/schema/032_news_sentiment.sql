@@ -0,0 +1,11 @@
+-- +goose Up
+-- Lexicon sentiment of each headline, scored when it is stored (news:sentiment:rescore
+-- scores existing rows again after the lexicon changes).
+ALTER TABLE news_headlines
+ADD COLUMN sentiment DECIMAL(5, 4) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN news_headlines.sentiment IS 'Headline sentiment from -1 (negative) to +1 (positive); 0 when neutral.';
+
+-- +goose Down
+ALTER TABLE news_headlines
+DROP COLUMN IF EXISTS sentiment;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Annual and quarterly report files per company, downloaded by documents:fetch. The file
+-- itself lives in DOCUMENTS_DIR or DOCUMENTS_BUCKET under storage_key; this row holds its
+-- metadata and checksum.
+CREATE TABLE company_documents (
+    id SERIAL PRIMARY KEY,
+    stock_code VARCHAR(20) NOT NULL REFERENCES companies(stock_code) ON DELETE CASCADE,
+    fiscal_year INTEGER NOT NULL CHECK (fiscal_year BETWEEN 1900 AND 2200),
+    period VARCHAR(10) NOT NULL CHECK (period IN ('annual', 'q1', 'q2', 'q3', 'q4')),
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    source_url TEXT NOT NULL,
+    storage_key TEXT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    downloaded_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT uq_company_document_period UNIQUE (stock_code, fiscal_year, period)
+);
+
+COMMENT ON TABLE company_documents IS 'Annual and quarterly report files per company and period.';
+COMMENT ON COLUMN company_documents.period IS 'annual, or the quarter (q1-q4) of a quarterly report.';
+COMMENT ON COLUMN company_documents.storage_key IS 'Path of the file relative to DOCUMENTS_DIR or the DOCUMENTS_BUCKET prefix.';
+COMMENT ON COLUMN company_documents.sha256 IS 'Hex SHA-256 of the file, also used as its ETag.';
+
+-- +goose Down
+DROP TABLE IF EXISTS company_documents;
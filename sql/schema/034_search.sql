@@ -0,0 +1,17 @@
+-- +goose Up
+-- Full-text indexes for GET /api/search. They are expression indexes, so the search query
+-- must use the same to_tsvector expressions. Company names and sectors use the simple
+-- configuration (no stemming of proper names); headlines, events and document titles use
+-- english.
+CREATE INDEX idx_companies_search ON companies USING GIN (
+    to_tsvector('simple', stock_code || ' ' || company_name || ' ' || COALESCE(sector, '') || ' ' || COALESCE(subsector, ''))
+);
+CREATE INDEX idx_news_headlines_search ON news_headlines USING GIN (to_tsvector('english', title));
+CREATE INDEX idx_annotations_search ON annotations USING GIN (to_tsvector('english', title || ' ' || description));
+CREATE INDEX idx_company_documents_search ON company_documents USING GIN (to_tsvector('english', title));
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_company_documents_search;
+DROP INDEX IF EXISTS idx_annotations_search;
+DROP INDEX IF EXISTS idx_news_headlines_search;
+DROP INDEX IF EXISTS idx_companies_search;
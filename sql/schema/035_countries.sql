@@ -0,0 +1,25 @@
+-- +goose Up
+-- Country dimension, so instruments of several markets can be tracked by one instance.
+-- Everything stored so far is Malaysian: the profile scraper reads Bursa listings.
+UPDATE companies SET country_code = 'MY' WHERE country_code IS NULL OR country_code = '';
+CREATE INDEX idx_companies_country_code ON companies (country_code);
+
+ALTER TABLE tracked_instruments
+ADD COLUMN country_code VARCHAR(2) NOT NULL DEFAULT 'MY';
+COMMENT ON COLUMN tracked_instruments.country_code IS 'ISO 3166 country of the listing (stocks) or of the instance''s base currency (fx).';
+
+ALTER TABLE annotations
+ADD COLUMN country_code VARCHAR(2) NOT NULL DEFAULT 'MY';
+ALTER TABLE annotations DROP CONSTRAINT annotations_event_date_category_title_key;
+ALTER TABLE annotations ADD CONSTRAINT annotations_country_event_date_category_title_key
+    UNIQUE (country_code, event_date, category, title);
+COMMENT ON COLUMN annotations.country_code IS 'ISO 3166 country the event belongs to.';
+
+-- +goose Down
+ALTER TABLE annotations DROP CONSTRAINT IF EXISTS annotations_country_event_date_category_title_key;
+DELETE FROM annotations WHERE country_code <> 'MY';
+ALTER TABLE annotations ADD CONSTRAINT annotations_event_date_category_title_key
+    UNIQUE (event_date, category, title);
+ALTER TABLE annotations DROP COLUMN IF EXISTS country_code;
+ALTER TABLE tracked_instruments DROP COLUMN IF EXISTS country_code;
+DROP INDEX IF EXISTS idx_companies_country_code;
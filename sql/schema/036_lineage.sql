@@ -0,0 +1,18 @@
+-- +goose Up
+-- Link stored prices and rates to the batch run that fetched them, so /api/lineage can trace a
+-- published figure back to its source, run and snapshot. Values stored outside a batch run
+-- (single fetches, ingestion) have no run. The columns are indexed so that pruning fetch_runs
+-- (ON DELETE SET NULL) does not scan the price tables.
+ALTER TABLE foreign_exchange
+ADD COLUMN fetch_run_id UUID NULL REFERENCES fetch_runs (id) ON DELETE SET NULL;
+COMMENT ON COLUMN foreign_exchange.fetch_run_id IS 'Batch run that last stored the rate, if any.';
+CREATE INDEX idx_fx_fetch_run_id ON foreign_exchange (fetch_run_id);
+
+ALTER TABLE daily_stock_prices
+ADD COLUMN fetch_run_id UUID NULL REFERENCES fetch_runs (id) ON DELETE SET NULL;
+COMMENT ON COLUMN daily_stock_prices.fetch_run_id IS 'Batch run that last stored the price, if any.';
+CREATE INDEX idx_dsp_fetch_run_id ON daily_stock_prices (fetch_run_id);
+
+-- +goose Down
+ALTER TABLE daily_stock_prices DROP COLUMN IF EXISTS fetch_run_id;
+ALTER TABLE foreign_exchange DROP COLUMN IF EXISTS fetch_run_id;
@@ -0,0 +1,55 @@
+-- +goose Up
+-- Partition the audit log by month so db:maintenance can drop whole months past
+-- AUDIT_RETENTION_DAYS. Monthly partitions are created ahead by db:maintenance; rows outside
+-- them (including everything logged before this migration) land in the default partition.
+ALTER TABLE audit_log RENAME TO audit_log_unpartitioned;
+ALTER SEQUENCE audit_log_id_seq RENAME TO audit_log_unpartitioned_id_seq;
+ALTER INDEX idx_audit_log_created_at RENAME TO idx_audit_log_unpartitioned_created_at;
+
+CREATE TABLE audit_log (
+    id BIGSERIAL,
+    user_id UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    username VARCHAR(255) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    source VARCHAR(10) NOT NULL CHECK (source IN ('cli', 'api')),
+    remote_addr VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE TABLE audit_log_default PARTITION OF audit_log DEFAULT;
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at DESC);
+
+INSERT INTO audit_log SELECT * FROM audit_log_unpartitioned;
+SELECT setval(pg_get_serial_sequence('audit_log', 'id'), COALESCE((SELECT MAX(id) FROM audit_log), 0) + 1, false);
+DROP TABLE audit_log_unpartitioned;
+
+COMMENT ON TABLE audit_log IS 'Authenticated user actions, queryable by admins. Partitioned by month.';
+COMMENT ON COLUMN audit_log.username IS 'Username at the time of the action; kept after the user is deleted.';
+COMMENT ON COLUMN audit_log.action IS 'CLI command name or equivalent (e.g. login, users:delete, fx:fetch_all).';
+
+-- +goose Down
+ALTER TABLE audit_log RENAME TO audit_log_partitioned;
+ALTER SEQUENCE audit_log_id_seq RENAME TO audit_log_partitioned_id_seq;
+ALTER INDEX idx_audit_log_created_at RENAME TO idx_audit_log_partitioned_created_at;
+
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    username VARCHAR(255) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    source VARCHAR(10) NOT NULL CHECK (source IN ('cli', 'api')),
+    remote_addr VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at DESC);
+
+INSERT INTO audit_log SELECT * FROM audit_log_partitioned;
+SELECT setval(pg_get_serial_sequence('audit_log', 'id'), COALESCE((SELECT MAX(id) FROM audit_log), 0) + 1, false);
+DROP TABLE audit_log_partitioned;
+
+COMMENT ON TABLE audit_log IS 'Authenticated user actions, queryable by admins.';
+COMMENT ON COLUMN audit_log.username IS 'Username at the time of the action; kept after the user is deleted.';
+COMMENT ON COLUMN audit_log.action IS 'CLI command name or equivalent (e.g. login, users:delete, fx:fetch_all).';
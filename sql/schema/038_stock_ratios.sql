@@ -0,0 +1,27 @@
+-- +goose Up
+-- Valuation and return ratios scraped from the i3investor stock page by stock:fetch:ratios,
+-- one row per stock and market date so screens can use the ratios in effect on any day.
+CREATE TABLE stock_ratios (
+    stock_code VARCHAR(20) NOT NULL REFERENCES companies(stock_code) ON DELETE CASCADE,
+    ratio_date DATE NOT NULL,
+    roe DECIMAL(12, 4) NULL,
+    nta DECIMAL(12, 4) NULL,
+    dividend_yield DECIMAL(12, 4) NULL,
+    price_to_book DECIMAL(12, 4) NULL,
+    source_url VARCHAR(512) NOT NULL,
+    fetch_run_id UUID NULL REFERENCES fetch_runs (id) ON DELETE SET NULL,
+    extracted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (stock_code, ratio_date)
+);
+
+COMMENT ON TABLE stock_ratios IS 'Financial ratios per stock and market date, as shown on the source page.';
+COMMENT ON COLUMN stock_ratios.roe IS 'Return on equity, in percent.';
+COMMENT ON COLUMN stock_ratios.nta IS 'Net tangible assets per share, in MYR.';
+COMMENT ON COLUMN stock_ratios.dividend_yield IS 'Trailing dividend yield, in percent.';
+COMMENT ON COLUMN stock_ratios.price_to_book IS 'Price to book ratio.';
+COMMENT ON COLUMN stock_ratios.fetch_run_id IS 'Batch run that last stored the ratios, if any.';
+
+CREATE INDEX idx_stock_ratios_ratio_date ON stock_ratios (ratio_date);
+
+-- +goose Down
+DROP TABLE IF EXISTS stock_ratios;
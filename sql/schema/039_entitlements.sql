@@ -0,0 +1,26 @@
+-- +goose Up
+-- Corporate entitlements (dividends, bonus issues, rights issues, splits) scraped from the
+-- pages in ENTITLEMENTS_URLS by entitlements:fetch. Announced entitlements are stored ahead of
+-- their ex-date, so the table doubles as a forward-looking calendar.
+CREATE TABLE entitlements (
+    id SERIAL PRIMARY KEY,
+    stock_code VARCHAR(20) NOT NULL,
+    entitlement_type VARCHAR(16) NOT NULL CHECK (entitlement_type IN ('dividend', 'bonus', 'rights', 'split', 'other')),
+    ex_date DATE NOT NULL,
+    payment_date DATE NULL,
+    description TEXT NOT NULL DEFAULT '',
+    amount DECIMAL(14, 6) NULL,
+    source_url VARCHAR(512) NOT NULL,
+    fetched_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT uq_entitlement UNIQUE (stock_code, entitlement_type, ex_date)
+);
+
+COMMENT ON TABLE entitlements IS 'Announced corporate entitlements per stock and ex-date.';
+COMMENT ON COLUMN entitlements.stock_code IS 'Stock code as listed on the source page; need not be a stored company.';
+COMMENT ON COLUMN entitlements.description IS 'Entitlement text from the source, e.g. Interim dividend or 1 bonus share for every 4.';
+COMMENT ON COLUMN entitlements.amount IS 'Cash amount per share in MYR for dividends, when the source gives one.';
+
+CREATE INDEX idx_entitlements_ex_date ON entitlements (ex_date);
+
+-- +goose Down
+DROP TABLE IF EXISTS entitlements;
@@ -0,0 +1,42 @@
+-- +goose Up
+-- Alerts on company announcements: headlines stored by news:fetch and entitlements stored by
+-- entitlements:fetch. A rule matches new announcements of its category about one stock, or
+-- about any stock on its owner's watchlist when stock_code is NULL.
+CREATE TABLE announcement_alert_rules (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category VARCHAR(32) NOT NULL CHECK (category IN ('any', 'results', 'dividend', 'corporate_action', 'merger')),
+    stock_code VARCHAR(20) NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+COMMENT ON TABLE announcement_alert_rules IS 'Alerts on new company announcements of a category, owned by a user.';
+COMMENT ON COLUMN announcement_alert_rules.category IS 'any, results, dividend, corporate_action (bonus, rights, split) or merger.';
+COMMENT ON COLUMN announcement_alert_rules.stock_code IS 'Stock the rule watches; NULL for every stock on the owner''s watchlist.';
+
+CREATE INDEX idx_announcement_alert_rules_user_id ON announcement_alert_rules (user_id);
+
+-- One event per rule and announcement it matched.
+CREATE TABLE announcement_alert_events (
+    id UUID PRIMARY KEY,
+    rule_id UUID NOT NULL REFERENCES announcement_alert_rules(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    stock_code VARCHAR(20) NOT NULL,
+    category VARCHAR(32) NOT NULL,
+    title TEXT NOT NULL,
+    link TEXT NOT NULL DEFAULT '',
+    announced_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    triggered_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+COMMENT ON TABLE announcement_alert_events IS 'Announcements that matched an announcement alert rule.';
+COMMENT ON COLUMN announcement_alert_events.category IS 'Category the announcement was classified as (never any).';
+
+CREATE INDEX idx_announcement_alert_events_user_id_triggered_at ON announcement_alert_events (user_id, triggered_at DESC);
+
+COMMENT ON COLUMN webhooks.event_types IS 'Subscribed events: data.stored, alert.triggered, announcement.triggered, fetch.failed.';
+
+-- +goose Down
+COMMENT ON COLUMN webhooks.event_types IS 'Subscribed events: data.stored, alert.triggered, fetch.failed.';
+DROP TABLE IF EXISTS announcement_alert_events;
+DROP TABLE IF EXISTS announcement_alert_rules;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Price to earnings ratio for the stock screener, scraped with the other ratios.
+ALTER TABLE stock_ratios ADD COLUMN price_to_earnings DECIMAL(12, 4) NULL;
+
+COMMENT ON COLUMN stock_ratios.price_to_earnings IS 'Trailing price to earnings ratio; NULL when earnings are negative or not shown.';
+
+-- +goose Down
+ALTER TABLE stock_ratios DROP COLUMN IF EXISTS price_to_earnings;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- Chart setups saved by users so a dashboard view can be bookmarked and shared.
+CREATE TABLE saved_charts (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    start_date DATE NULL,
+    end_date DATE NULL,
+    lookback VARCHAR(8) NULL CHECK (lookback IN ('1m', '3m', '6m', '1y', '3y', '5y', '10y')),
+    shared BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE saved_chart_series (
+    chart_id UUID NOT NULL REFERENCES saved_charts(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    series_key VARCHAR(40) NOT NULL,
+    transform VARCHAR(16) NOT NULL DEFAULT '',
+    PRIMARY KEY (chart_id, position)
+);
+
+COMMENT ON TABLE saved_charts IS 'Chart setups saved by a user: the series plotted and the date range.';
+COMMENT ON COLUMN saved_charts.start_date IS 'First date shown; NULL for the lookback before end_date (or today).';
+COMMENT ON COLUMN saved_charts.end_date IS 'Last date shown; NULL for today, so the chart stays current.';
+COMMENT ON COLUMN saved_charts.lookback IS 'Span shown when start_date is NULL, e.g. 1y; NULL with start_date for the whole history.';
+COMMENT ON COLUMN saved_charts.shared IS 'Shared charts can be read by anyone with the ID, without signing in.';
+COMMENT ON TABLE saved_chart_series IS 'Series plotted on a saved chart, in legend order.';
+COMMENT ON COLUMN saved_chart_series.series_key IS 'kind:code, e.g. stock:1155, fx:USD or macro:cpi.';
+COMMENT ON COLUMN saved_chart_series.transform IS 'real for CPI-deflated stock and FX series; yoy, mom or 3mma for macro series; empty as stored.';
+
+CREATE INDEX idx_saved_charts_user_id ON saved_charts (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS saved_chart_series;
+DROP TABLE IF EXISTS saved_charts;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Alert rules can compare a macro series' period-over-period change instead of its level.
+ALTER TABLE alert_rules ADD COLUMN change VARCHAR(8) NOT NULL DEFAULT 'level' CHECK (change IN ('level', 'yoy', 'mom'));
+ALTER TABLE alert_events ADD COLUMN change VARCHAR(8) NOT NULL DEFAULT 'level';
+
+COMMENT ON COLUMN alert_rules.series IS 'Series key: stock:<code> (closing price), fx:<currency> (MYR per unit) or macro:<series>.';
+COMMENT ON COLUMN alert_rules.change IS 'Value compared: level (as stored), or yoy or mom percent change of a macro series.';
+
+-- +goose Down
+COMMENT ON COLUMN alert_rules.series IS 'Series key: stock:<code> (closing price) or fx:<currency> (MYR per unit).';
+ALTER TABLE alert_events DROP COLUMN IF EXISTS change;
+ALTER TABLE alert_rules DROP COLUMN IF EXISTS change;
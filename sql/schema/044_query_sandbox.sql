@@ -0,0 +1,58 @@
+-- +goose Up
+-- Views /api/query may select from. Queries run as query_sandbox, which can read nothing but
+-- these views, so user tables, sessions and keys stay out of reach even of a crafted query.
+-- Creating the role needs CREATEROLE (or a superuser) the first time this runs.
+CREATE SCHEMA sandbox;
+
+CREATE VIEW sandbox.fx_rates AS
+SELECT currency_code, date, session, buying_rate, selling_rate, middle_rate, unit, middle_rate_per_unit, source
+FROM foreign_exchange;
+
+CREATE VIEW sandbox.stock_prices AS
+SELECT stock_code, price_date, closing_price
+FROM daily_stock_prices;
+
+CREATE VIEW sandbox.companies AS
+SELECT stock_code, company_name, country_code, sector, subsector, listing_date, shares_outstanding
+FROM companies;
+
+CREATE VIEW sandbox.stock_ratios AS
+SELECT stock_code, ratio_date, roe, nta, dividend_yield, price_to_book
+FROM stock_ratios;
+
+CREATE VIEW sandbox.macro_observations AS
+SELECT series, period, value, source
+FROM macro_observations;
+
+CREATE VIEW sandbox.daily_returns AS
+SELECT series, date, pct_return
+FROM daily_returns;
+
+CREATE VIEW sandbox.monthly_fx_rates AS
+SELECT currency_code, session, month, avg_middle_rate_per_unit, observations, last_date
+FROM monthly_fx_rates;
+
+CREATE VIEW sandbox.monthly_stock_closes AS
+SELECT stock_code, month, last_date, closing_price
+FROM monthly_stock_closes;
+
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = 'query_sandbox') THEN
+        CREATE ROLE query_sandbox NOLOGIN;
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
+-- The application switches to the role with SET LOCAL ROLE, so it must be a member
+GRANT query_sandbox TO CURRENT_USER;
+GRANT USAGE ON SCHEMA sandbox TO query_sandbox;
+GRANT SELECT ON ALL TABLES IN SCHEMA sandbox TO query_sandbox;
+
+COMMENT ON SCHEMA sandbox IS 'Read-only views served by /api/query.';
+
+-- +goose Down
+DROP SCHEMA IF EXISTS sandbox CASCADE;
+-- The role is left in place: it is cluster-wide and may be shared by other databases
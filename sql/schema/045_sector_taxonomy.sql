@@ -0,0 +1,67 @@
+-- +goose Up
+-- Canonical sector taxonomy. Scraped profiles label the same sector inconsistently (case,
+-- spacing, abbreviations), which fragments sector aggregations, so companies.sector and
+-- subsector hold the canonical label and the new *_raw columns keep what was scraped.
+CREATE TABLE sectors (
+    name VARCHAR(255) PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE subsectors (
+    sector VARCHAR(255) NOT NULL REFERENCES sectors (name) ON UPDATE CASCADE ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (sector, name)
+);
+
+CREATE TABLE sector_mappings (
+    kind VARCHAR(9) NOT NULL CHECK (kind IN ('sector', 'subsector')),
+    label VARCHAR(255) NOT NULL,
+    canonical VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (kind, label)
+);
+
+COMMENT ON TABLE sectors IS 'Canonical sectors company profiles are normalized to.';
+COMMENT ON TABLE subsectors IS 'Canonical subsectors of each sector.';
+COMMENT ON TABLE sector_mappings IS 'Scraped sector and subsector labels merged into a canonical one.';
+COMMENT ON COLUMN sector_mappings.label IS 'Scraped label, lower-cased with whitespace collapsed.';
+COMMENT ON COLUMN sector_mappings.canonical IS 'Name of the canonical sector or subsector the label stands for.';
+
+ALTER TABLE companies
+ADD COLUMN sector_raw VARCHAR(255) NULL,
+ADD COLUMN subsector_raw VARCHAR(255) NULL;
+
+UPDATE companies SET sector_raw = sector, subsector_raw = subsector;
+
+COMMENT ON COLUMN companies.sector IS 'The primary economic sector of the company, normalized to the canonical taxonomy where it is known.';
+COMMENT ON COLUMN companies.subsector IS 'A more specific subsector or industry classification, normalized like sector.';
+COMMENT ON COLUMN companies.sector_raw IS 'Sector as scraped from the profile page.';
+COMMENT ON COLUMN companies.subsector_raw IS 'Subsector as scraped from the profile page.';
+
+-- Bursa Malaysia's sector classification
+INSERT INTO sectors (name) VALUES
+    ('Closed-End Fund'),
+    ('Construction'),
+    ('Consumer Products & Services'),
+    ('Energy'),
+    ('Financial Services'),
+    ('Health Care'),
+    ('Industrial Products & Services'),
+    ('Plantation'),
+    ('Property'),
+    ('Real Estate Investment Trusts'),
+    ('Special Purpose Acquisition Company'),
+    ('Technology'),
+    ('Telecommunications & Media'),
+    ('Transportation & Logistics'),
+    ('Utilities');
+
+-- +goose Down
+UPDATE companies SET sector = sector_raw, subsector = subsector_raw;
+COMMENT ON COLUMN companies.sector IS 'The primary economic sector of the company.';
+COMMENT ON COLUMN companies.subsector IS 'A more specific subsector or industry classification.';
+ALTER TABLE companies DROP COLUMN IF EXISTS subsector_raw, DROP COLUMN IF EXISTS sector_raw;
+DROP TABLE IF EXISTS sector_mappings;
+DROP TABLE IF EXISTS subsectors;
+DROP TABLE IF EXISTS sectors;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Company lifecycle. Codes whose pages disappear (delisted, or re-listed under a new code) are
+-- marked rather than deleted, so their stored prices stay queryable and fetch-all loops skip
+-- them instead of failing on them every run.
+ALTER TABLE companies
+ADD COLUMN status VARCHAR(10) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'missing', 'delisted')),
+ADD COLUMN delisted_date DATE NULL,
+ADD COLUMN former_names TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN missing_since DATE NULL,
+ADD COLUMN missing_count INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN companies.status IS 'active, missing (the stock page has disappeared in recent fetches) or delisted.';
+COMMENT ON COLUMN companies.delisted_date IS 'Date the company was delisted: the first fetch its page was missing, or as set by hand.';
+COMMENT ON COLUMN companies.former_names IS 'Names the company was listed under before company_name, oldest first.';
+COMMENT ON COLUMN companies.missing_since IS 'Date of the first fetch in the current run of fetches that found the stock page missing.';
+COMMENT ON COLUMN companies.missing_count IS 'Consecutive fetches that found the stock page missing.';
+
+CREATE INDEX idx_companies_status ON companies (status) WHERE status <> 'active';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_companies_status;
+ALTER TABLE companies
+DROP COLUMN IF EXISTS missing_count,
+DROP COLUMN IF EXISTS missing_since,
+DROP COLUMN IF EXISTS former_names,
+DROP COLUMN IF EXISTS delisted_date,
+DROP COLUMN IF EXISTS status;
@@ -0,0 +1,45 @@
+-- +goose Up
+-- Rolling 52-week high and low close per stock, refreshed whenever a close is stored, so the
+-- screener and latest-close endpoints read them instead of scanning a year of prices per stock
+-- on every request.
+CREATE TABLE stock_ranges_52w (
+    stock_code VARCHAR(20) PRIMARY KEY,
+    price_date DATE NOT NULL,
+    closing_price DECIMAL(12, 4) NOT NULL,
+    high_52w DECIMAL(12, 4) NOT NULL,
+    high_date DATE NOT NULL,
+    low_52w DECIMAL(12, 4) NOT NULL,
+    low_date DATE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+COMMENT ON TABLE stock_ranges_52w IS 'Latest close and the highest and lowest closes of the 52 weeks up to it, per stock. Derived from daily_stock_prices.';
+COMMENT ON COLUMN stock_ranges_52w.price_date IS 'Date of the latest stored close.';
+COMMENT ON COLUMN stock_ranges_52w.closing_price IS 'The latest stored close.';
+COMMENT ON COLUMN stock_ranges_52w.high_52w IS 'Highest close of the 52 weeks to price_date.';
+COMMENT ON COLUMN stock_ranges_52w.high_date IS 'Most recent date the high was closed at.';
+COMMENT ON COLUMN stock_ranges_52w.low_52w IS 'Lowest close of the 52 weeks to price_date.';
+COMMENT ON COLUMN stock_ranges_52w.low_date IS 'Most recent date the low was closed at.';
+
+INSERT INTO stock_ranges_52w (stock_code, price_date, closing_price, high_52w, high_date, low_52w, low_date)
+SELECT l.stock_code, l.price_date, l.closing_price, h.closing_price, h.price_date, lo.closing_price, lo.price_date
+FROM (
+    SELECT DISTINCT ON (stock_code) stock_code, price_date, closing_price
+    FROM daily_stock_prices
+    ORDER BY stock_code, price_date DESC
+) l
+CROSS JOIN LATERAL (
+    SELECT d.price_date, d.closing_price FROM daily_stock_prices d
+    WHERE d.stock_code = l.stock_code AND d.price_date > l.price_date - INTERVAL '52 weeks' AND d.price_date <= l.price_date
+    ORDER BY d.closing_price DESC, d.price_date DESC
+    LIMIT 1
+) h
+CROSS JOIN LATERAL (
+    SELECT d.price_date, d.closing_price FROM daily_stock_prices d
+    WHERE d.stock_code = l.stock_code AND d.price_date > l.price_date - INTERVAL '52 weeks' AND d.price_date <= l.price_date
+    ORDER BY d.closing_price ASC, d.price_date DESC
+    LIMIT 1
+) lo;
+
+-- +goose Down
+DROP TABLE IF EXISTS stock_ranges_52w;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Buy/sell spread of each quoted rate, for studying liquidity and the BNM fixing over time.
+-- Third-party and pushed rates carry a single value, so their spread is zero.
+ALTER TABLE foreign_exchange
+ADD COLUMN spread_per_unit DECIMAL(14, 8) GENERATED ALWAYS AS ((selling_rate - buying_rate) / unit) STORED,
+ADD COLUMN spread_pct DECIMAL(10, 6) GENERATED ALWAYS AS ((selling_rate - buying_rate) / NULLIF(middle_rate, 0) * 100) STORED;
+
+COMMENT ON COLUMN foreign_exchange.spread_per_unit IS 'Selling minus buying rate, in MYR per 1 unit of the foreign currency.';
+COMMENT ON COLUMN foreign_exchange.spread_pct IS 'Selling minus buying rate as a percentage of the middle rate.';
+
+-- +goose Down
+ALTER TABLE foreign_exchange
+DROP COLUMN IF EXISTS spread_pct,
+DROP COLUMN IF EXISTS spread_per_unit;
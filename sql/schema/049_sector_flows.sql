@@ -0,0 +1,28 @@
+-- +goose Up
+-- Traded volume of each close, and the daily traded value (close times volume) of each
+-- sector derived from it, for ranking where trading concentrated.
+ALTER TABLE daily_stock_prices ADD COLUMN volume BIGINT NULL;
+
+COMMENT ON COLUMN daily_stock_prices.volume IS 'Shares traded on price_date, as shown on the source page; NULL when not captured.';
+
+CREATE TABLE sector_flows (
+    sector VARCHAR(255) NOT NULL,
+    flow_date DATE NOT NULL,
+    traded_value DECIMAL(20, 2) NOT NULL,
+    volume BIGINT NOT NULL,
+    stocks INTEGER NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (sector, flow_date)
+);
+
+COMMENT ON TABLE sector_flows IS 'Daily traded value per sector. Derived from daily_stock_prices and companies.sector.';
+COMMENT ON COLUMN sector_flows.sector IS 'Canonical sector, or Unclassified for companies without one.';
+COMMENT ON COLUMN sector_flows.traded_value IS 'Sum of closing price times volume over the sector''s stocks, in the quote currency.';
+COMMENT ON COLUMN sector_flows.volume IS 'Shares traded across the sector''s stocks.';
+COMMENT ON COLUMN sector_flows.stocks IS 'Stocks with a captured volume that day.';
+
+CREATE INDEX idx_sector_flows_date ON sector_flows (flow_date);
+
+-- +goose Down
+DROP TABLE IF EXISTS sector_flows;
+ALTER TABLE daily_stock_prices DROP COLUMN IF EXISTS volume;
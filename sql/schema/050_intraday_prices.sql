@@ -0,0 +1,21 @@
+-- +goose Up
+-- Prices polled during market hours, kept apart from the daily closes and pruned after
+-- INTRADAY_RETENTION_DAYS by db:maintenance.
+CREATE TABLE intraday_prices (
+    stock_code VARCHAR(20) NOT NULL,
+    observed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    price DECIMAL(12, 4) NOT NULL,
+    volume BIGINT NULL,
+    source_url TEXT NULL,
+    PRIMARY KEY (stock_code, observed_at)
+);
+
+COMMENT ON TABLE intraday_prices IS 'Last prices polled every INTRADAY_INTERVAL during market hours. Not used for the daily closes.';
+COMMENT ON COLUMN intraday_prices.observed_at IS 'When the price was polled.';
+COMMENT ON COLUMN intraday_prices.price IS 'Last traded price shown on the source page at observed_at.';
+COMMENT ON COLUMN intraday_prices.volume IS 'Shares traded so far that day; NULL when not shown.';
+
+CREATE INDEX idx_intraday_prices_observed_at ON intraday_prices (observed_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS intraday_prices;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- /api/query connects as query_sandbox itself (QUERY_DB_URL) instead of switching to it with
+-- SET ROLE on the application's connection. The application role stops being a member, so a
+-- sandbox session has no path back to it; the limits are set on the role, so they hold even if
+-- a query gets around the application's checks. Set the password outside the migrations:
+--   ALTER ROLE query_sandbox PASSWORD '...';
+REVOKE query_sandbox FROM CURRENT_USER;
+ALTER ROLE query_sandbox LOGIN NOSUPERUSER NOCREATEDB NOCREATEROLE NOREPLICATION;
+ALTER ROLE query_sandbox SET default_transaction_read_only = on;
+ALTER ROLE query_sandbox SET statement_timeout = '5s';
+ALTER ROLE query_sandbox SET search_path = sandbox;
+
+-- +goose Down
+ALTER ROLE query_sandbox RESET ALL;
+ALTER ROLE query_sandbox NOLOGIN;
+GRANT query_sandbox TO CURRENT_USER;
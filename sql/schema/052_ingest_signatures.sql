@@ -0,0 +1,16 @@
+-- +goose Up
+-- Signatures of the pushes /api/ingest accepted within the sent_at window, so the same signed
+-- body cannot be replayed while its sent_at is still current. Rows older than the window are
+-- pruned as new pushes arrive.
+CREATE TABLE ingest_signatures (
+    signature VARCHAR(80) PRIMARY KEY,
+    source_name VARCHAR(50) NOT NULL REFERENCES ingest_sources(name) ON DELETE CASCADE,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_ingest_signatures_received_at ON ingest_signatures (received_at);
+
+COMMENT ON TABLE ingest_signatures IS 'Signatures of accepted ingest pushes, kept while their sent_at could still be accepted.';
+
+-- +goose Down
+DROP TABLE IF EXISTS ingest_signatures;
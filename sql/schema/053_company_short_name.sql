@@ -0,0 +1,10 @@
+-- +goose Up
+-- Headlines name companies by their board name ("MAYBANK", "TENAGA") far more often than by
+-- the registered name, so the news matcher also looks for the short name.
+ALTER TABLE companies
+ADD COLUMN short_name VARCHAR(100) NULL;
+
+COMMENT ON COLUMN companies.short_name IS 'Board (short) name the stock trades under, e.g. MAYBANK, when the profile page shows one.';
+
+-- +goose Down
+ALTER TABLE companies DROP COLUMN IF EXISTS short_name;
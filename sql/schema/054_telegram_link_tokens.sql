@@ -0,0 +1,15 @@
+-- +goose Up
+-- One-time tokens for telegram:link. The CLI issues a token and the user sends "/start <token>"
+-- to the bot from the chat to link, which proves they control that chat. Only a SHA-256 hash
+-- of the token is stored.
+CREATE TABLE telegram_link_tokens (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+COMMENT ON TABLE telegram_link_tokens IS 'Pending telegram:link requests, at most one per user; removed when the bot receives the token.';
+COMMENT ON COLUMN telegram_link_tokens.token_hash IS 'Hex-encoded SHA-256 of the token the user sends to the bot.';
+
+-- +goose Down
+DROP TABLE IF EXISTS telegram_link_tokens;
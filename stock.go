@@ -5,12 +5,15 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"net/http" // Required for HTTP requests
-	"strconv"  // Required for converting string price to float
+	"math"
+	"strconv" // Required for converting string price to float
 	"strings"
 	"time"
 
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/config"
 	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database" // Your sqlc generated package
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/errreport"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
 
 	"github.com/PuerkitoBio/goquery" // Import goquery
 )
@@ -22,33 +25,27 @@ func handlerStockFetchPrice(s *AppState, cmd command) error {
 	if len(cmd.Args) != 1 {
 		return fmt.Errorf("usage: %s <stock_code>", cmd.Name)
 	}
-	stockCode := cmd.Args[0]
-	profileURL := s.cfg.I3InvestorBaseURL + stockCode
+	stockCode, err := parseStockCode(cmd.Context(), s, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	return sharedFetch(s, "stock:price:"+stockCode, func() error { return fetchStockPrice(s, cmd, stockCode) })
+}
+
+func fetchStockPrice(s *AppState, cmd command, stockCode string) error {
+	source := s.sources.Source(config.SourceI3Investor)
+	profileURL := source.BaseURL() + stockCode
 
 	log.Printf("Fetching stock price for %s from %s", stockCode, profileURL)
 
 	// --- Step 1: Fetch HTML Content ---
-	// Create a client (good practice to reuse clients, but okay here for CLI command)
-	client := &http.Client{
-		Timeout: 15 * time.Second, // Set a timeout
-	}
-	req, err := http.NewRequest("GET", profileURL, nil)
+	// The source client applies the configured rate limit, timeout, retries and User-Agent
+	resp, err := source.Get(cmd.Context(), profileURL)
 	if err != nil {
-		return fmt.Errorf("failed to create request for %s: %w", profileURL, err)
-	}
-	// Set a User-Agent header, as some sites block requests without one
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to fetch URL %s: %w", profileURL, err)
+		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("received non-200 status code %d from %s", resp.StatusCode, profileURL)
-	}
-
 	// --- Step 2: Parse HTML using goquery ---
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
@@ -56,29 +53,9 @@ func handlerStockFetchPrice(s *AppState, cmd command) error {
 	}
 
 	// --- Step 3: Find the Target Element and Extract Price ---
-	var priceStr string
-	var found bool
-
-	// Find the specific div structure
-	// Iterate over potential divs, look for the one preceded by "Last Price" text.
-	// This selector targets divs that are likely containers for stock stats.
-	doc.Find("div.col-md-3.col-6").EachWithBreak(func(i int, s *goquery.Selection) bool {
-		// Check the first <p> tag within the div for the label "Last Price"
-		labelText := s.Find("p").First().Text()
-		if strings.Contains(labelText, "Last Price") {
-			// If label matches, find the price in the <p><strong> structure within the *same* div
-			priceSelection := s.Find("p > strong") // Look for strong tag within any p tag in this div
-			if priceSelection.Length() > 0 {
-				priceStr = priceSelection.First().Text() // Get text from the first strong tag found
-				found = true
-				return false // Stop iterating once found
-			}
-		}
-		return true // Continue iterating
-	})
-
-	if !found || priceStr == "" {
-		return fmt.Errorf("could not find 'Last Price' element or value on page %s", profileURL)
+	priceStr, found := findLastPrice(doc)
+	if !found {
+		return fmt.Errorf("could not find 'Last Price' element or value on page %s: %w", profileURL, errStockPageMissing)
 	}
 
 	log.Printf("Found raw price string: '%s'", priceStr)
@@ -91,24 +68,30 @@ func handlerStockFetchPrice(s *AppState, cmd command) error {
 	}
 
 	log.Printf("Parsed price: %.4f", price)
+	volume := findVolume(doc, stockCode) // Optional; NULL when the page does not show it
 
 	// --- Step 5: Prepare Data for Database ---
-	// Use today's date (UTC). You might adjust this if the site indicates a specific date.
-	priceDate := time.Now().UTC()
+	// Use today's market date. You might adjust this if the site indicates a specific date.
+	priceDate := markettime.Today()
 
 	// --- Step 6: Insert/Update Database ---
 	log.Printf("Upserting price %.4f for %s on %s into database...", price, stockCode, priceDate.Format("2006-01-02"))
 
-	err = s.db.UpsertStockPrice(context.Background(), database.UpsertStockPriceParams{
+	err = s.db.UpsertStockPrice(cmd.Context(), database.UpsertStockPriceParams{
 		StockCode:    stockCode,
 		PriceDate:    priceDate, // sqlc should handle time.Time -> DATE conversion
 		ClosingPrice: fmt.Sprintf("%.4f", price),
 		SourceUrl:    sql.NullString{String: profileURL, Valid: true}, // Use sql.NullString for optional columns
+		FetchRunID:   fetchRunFromContext(cmd.Context()),
+		Volume:       volume,
 	})
 
 	if err != nil {
 		return fmt.Errorf("failed to upsert stock price for %s: %w", stockCode, err)
 	}
+	refreshStockRanges(cmd.Context(), s, stockCode)
+	invalidateResponseCache(s)
+	publishObservation(s, watchlistStock, stockCode, priceDate, price, "i3investor")
 
 	log.Printf("Successfully stored stock price for %s.", stockCode)
 	fmt.Printf("Fetched and stored price for %s: %.4f\n", stockCode, price) // User feedback
@@ -116,24 +99,132 @@ func handlerStockFetchPrice(s *AppState, cmd command) error {
 	return nil
 }
 
+// findLastPrice returns the text of the "Last Price" stat on an i3investor stock page, and
+// whether the page has one.
+func findLastPrice(doc *goquery.Document) (string, bool) {
+	var priceStr string
+	var found bool
+
+	// Find the specific div structure
+	// Iterate over potential divs, look for the one preceded by "Last Price" text.
+	// This selector targets divs that are likely containers for stock stats.
+	doc.Find("div.col-md-3.col-6").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		// Check the first <p> tag within the div for the label "Last Price"
+		labelText := s.Find("p").First().Text()
+		if strings.Contains(labelText, "Last Price") {
+			// If label matches, find the price in the <p><strong> structure within the *same* div
+			priceSelection := s.Find("p > strong") // Look for strong tag within any p tag in this div
+			if priceSelection.Length() > 0 {
+				priceStr = priceSelection.First().Text() // Get text from the first strong tag found
+				found = true
+				return false // Stop iterating once found
+			}
+		}
+		return true // Continue iterating
+	})
+	return priceStr, found && priceStr != ""
+}
+
+// findVolume returns the traded volume shown on an i3investor stock page, NULL when the page
+// does not show one or it cannot be parsed.
+func findVolume(doc *goquery.Document, stockCode string) sql.NullInt64 {
+	var volume sql.NullInt64
+	doc.Find("div.col-md-3.col-6").EachWithBreak(func(i int, block *goquery.Selection) bool {
+		label := strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(block.Find("p").First().Text()), ":"))
+		if label != "VOLUME" && label != "VOL" {
+			return true
+		}
+		raw := strings.TrimSpace(block.Find("p > strong").First().Text())
+		if raw == "" || raw == "-" {
+			return false
+		}
+		v, err := parseVolume(raw)
+		if err != nil {
+			log.Printf("Warning: Could not parse volume '%s' for %s: %v", raw, stockCode, err)
+			return false
+		}
+		volume = sql.NullInt64{Int64: v, Valid: true}
+		return false
+	})
+	return volume
+}
+
+// parseVolume parses a volume such as "1,234,500" or an abbreviated one such as "12.5M" or "830K".
+func parseVolume(raw string) (int64, error) {
+	cleaned := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(raw), ",", ""))
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(cleaned, "K"):
+		multiplier = 1e3
+	case strings.HasSuffix(cleaned, "M"):
+		multiplier = 1e6
+	case strings.HasSuffix(cleaned, "B"):
+		multiplier = 1e9
+	}
+	if multiplier != 1 {
+		cleaned = strings.TrimSpace(cleaned[:len(cleaned)-1])
+	}
+	v, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("negative volume")
+	}
+	return int64(math.Round(v * multiplier)), nil
+}
+
+// handlerStockFetchPriceAll fetches the price of every configured stock. Stocks already fetched
+// today after the close are skipped unless --force is given, so a re-run after a crash resumes
+// where it stopped.
+// Usage: stock:fetch:price_all [--force]
 func handlerStockFetchPriceAll(s *AppState, cmd command) error {
-	if len(cmd.Args) != 0 {
-		return fmt.Errorf("usage: %s", cmd.Name)
+	force := false
+	for _, arg := range cmd.Args {
+		if arg != "--force" {
+			return fmt.Errorf("usage: %s [--force]", cmd.Name)
+		}
+		force = true
 	}
 
-	// Fetch all stock codes from the database
-	stockCodes := s.cfg.StockList
+	stockCodes := activeStocks(cmd.Context(), s, trackedStocks(cmd.Context(), s, s.cfg.DefaultCountry))
 
 	// Iterate over each stock code and fetch its price
+	run := startFetchRun(s, cmd)
+	cmd = run.attach(cmd)
+	checkpoints := loadFetchCheckpoints(cmd.Context(), s, cmd.Name, force)
+	var failures []string
+	var pages stockPageChecks
+	stored := 0
 	for _, stockCode := range stockCodes {
-		cmd := command{
-			Name: "stock:fetch:price",
-			Args: []string{stockCode},
+		if checkpoints.completed(stockCode) {
+			continue
 		}
-		if err := handlerStockFetchPrice(s, cmd); err != nil {
+		if err := cmd.Context().Err(); err != nil {
+			run.finish(s, fetchStats{SuccessfulFetches: stored, SuccessfulStores: stored, FailedFetches: len(failures), Errors: failures}, err)
+			return err // Shutting down or timed out; completed codes are checkpointed
+		}
+		cmd := cmd.subcommand("stock:fetch:price", stockCode)
+		err := handlerStockFetchPrice(s, cmd)
+		pages.record(stockCode, err)
+		if err != nil {
 			log.Printf("Failed to fetch price for %s: %v", stockCode, err)
+			failures = append(failures, fmt.Sprintf("price %s: %v", stockCode, err))
+			reportStockFetchError(cmd, stockCode, s.sources.Source(config.SourceI3Investor).BaseURL()+stockCode, err)
+			continue
+		}
+		// A price read during the day is replaced by the close, so only closes are checkpointed
+		if markettime.AfterClose(time.Now()) {
+			checkpoints.complete(cmd.Context(), s, stockCode)
 		}
+		stored++
 	}
+	pages.apply(cmd.Context(), s)
+	// Each price is one page fetch and one store; failures are not split between the two
+	run.finish(s, fetchStats{SuccessfulFetches: stored, SuccessfulStores: stored, FailedFetches: len(failures), Errors: failures}, nil)
+	notifyDataStored(s, cmd.Name, stored)
+	notifyBatchFailures(s, cmd.Name, failures)
+	runPostFetchJobs(cmd.Context(), s)
 
 	return nil
 }
@@ -190,28 +281,26 @@ func handlerStockFetchProfile(s *AppState, cmd command) error {
 	if len(cmd.Args) != 1 {
 		return fmt.Errorf("usage: %s <stock_code>", cmd.Name)
 	}
+	stockCode, err := parseStockCode(cmd.Context(), s, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	return sharedFetch(s, "stock:profile:"+stockCode, func() error { return fetchStockProfile(s, cmd, stockCode) })
+}
 
-	stockCode := cmd.Args[0]
+func fetchStockProfile(s *AppState, cmd command, stockCode string) error {
 	// Ensure this URL points to the overview/profile page
-	profileURL := s.cfg.I3InvestorStockProfileURL + stockCode
+	source := s.sources.Source(config.SourceI3InvestorProfile)
+	profileURL := source.BaseURL() + stockCode
 
 	log.Printf("Fetching stock profile for %s from %s", stockCode, profileURL)
 
-	// --- Step 1: Fetch HTML Content (remains the same) ---
-	client := &http.Client{Timeout: 15 * time.Second}
-	req, err := http.NewRequest("GET", profileURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request for %s: %w", profileURL, err)
-	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-	resp, err := client.Do(req)
+	// --- Step 1: Fetch HTML Content ---
+	resp, err := source.Get(cmd.Context(), profileURL)
 	if err != nil {
-		return fmt.Errorf("failed to fetch URL %s: %w", profileURL, err)
+		return err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("received non-200 status code %d from %s", resp.StatusCode, profileURL)
-	}
 
 	// --- Step 2: Parse HTML using goquery ---
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
@@ -221,21 +310,25 @@ func handlerStockFetchProfile(s *AppState, cmd command) error {
 
 	// --- Step 3: Extract Profile Information ---
 	var companyName, countryCode, sector, subsector string
+	var listingDateStr, website, parValueStr, sharesOutstandingStr string
 
 	// --- Extract Company Name from the main heading first (more reliable) ---
 	// Selector for: <h5 class="mb-0" id="stock-heading" ...> <a ...> <strong>COMPANY NAME</strong> </a> </h5>
 	companyName = strings.TrimSpace(doc.Find("h5#stock-heading a strong").First().Text())
+	// The profile section's h6 (a sibling of an h5 containing "Profile") names the company too
+	var profileName string
+	doc.Find("h5").EachWithBreak(func(i int, h5 *goquery.Selection) bool {
+		if strings.TrimSpace(h5.Text()) == "Profile" {
+			profileName = strings.TrimSpace(h5.NextFiltered("h6").Find("strong").First().Text())
+			return false // Stop searching
+		}
+		return true
+	})
 	if companyName == "" {
-		// Fallback: try the h6 within a potential profile section if the main one fails
-		// This targets an h6 that is a sibling of an h5 containing "Profile"
-		doc.Find("h5").EachWithBreak(func(i int, h5 *goquery.Selection) bool {
-			if strings.TrimSpace(h5.Text()) == "Profile" {
-				companyName = strings.TrimSpace(h5.NextFiltered("h6").Find("strong").First().Text())
-				return false // Stop searching
-			}
-			return true
-		})
+		// Fallback: the profile section's name if the main heading fails
+		companyName = profileName
 	}
+	shortName := stockShortName(companyName, profileName)
 	if companyName == "" {
 		log.Printf("Warning: Could not find company name for %s using primary selectors.", stockCode)
 	}
@@ -256,12 +349,56 @@ func handlerStockFetchProfile(s *AppState, cmd command) error {
 				sector = extractTextAfterLabel(p, "Sector:")
 			} else if strings.Contains(text, "Subsector:") {
 				subsector = extractTextAfterLabel(p, "Subsector:")
+			} else if strings.Contains(text, "Listing Date:") {
+				listingDateStr = extractTextAfterLabel(p, "Listing Date:")
+			} else if strings.Contains(text, "Website:") {
+				// Prefer the link target; the visible text is sometimes truncated
+				website = strings.TrimSpace(p.Find("a").Not(".d-none").First().AttrOr("href", ""))
+				if website == "" {
+					website = extractTextAfterLabel(p, "Website:")
+				}
+			} else if strings.Contains(text, "Par Value:") {
+				parValueStr = extractTextAfterLabel(p, "Par Value:")
+			} else if strings.Contains(text, "Shares Outstanding:") || strings.Contains(text, "Number of Shares:") {
+				label := "Shares Outstanding:"
+				if !strings.Contains(text, label) {
+					label = "Number of Shares:"
+				}
+				sharesOutstandingStr = extractTextAfterLabel(p, label)
 			}
 		})
 	}
 
 	log.Printf("Extracted Profile for %s: Name='%s', Country='%s', Sector='%s', Subsector='%s'",
 		stockCode, companyName, countryCode, sector, subsector)
+	log.Printf("Extracted Details for %s: ListingDate='%s', Website='%s', ParValue='%s', SharesOutstanding='%s'",
+		stockCode, listingDateStr, website, parValueStr, sharesOutstandingStr)
+
+	// Optional fields: a parse failure is logged and the field stored as NULL rather than failing the scrape
+	listingDate := sql.NullTime{}
+	if listingDateStr != "" {
+		if t, err := parseProfileDate(listingDateStr); err != nil {
+			log.Printf("Warning: Could not parse listing date '%s' for %s: %v", listingDateStr, stockCode, err)
+		} else {
+			listingDate = sql.NullTime{Time: t, Valid: true}
+		}
+	}
+	parValue := sql.NullString{}
+	if parValueStr != "" {
+		if v, err := parseProfileNumber(parValueStr); err != nil {
+			log.Printf("Warning: Could not parse par value '%s' for %s: %v", parValueStr, stockCode, err)
+		} else {
+			parValue = sql.NullString{String: fmt.Sprintf("%.4f", v), Valid: true}
+		}
+	}
+	sharesOutstanding := sql.NullInt64{}
+	if sharesOutstandingStr != "" {
+		if v, err := parseProfileNumber(sharesOutstandingStr); err != nil {
+			log.Printf("Warning: Could not parse shares outstanding '%s' for %s: %v", sharesOutstandingStr, stockCode, err)
+		} else {
+			sharesOutstanding = sql.NullInt64{Int64: int64(v), Valid: true}
+		}
+	}
 
 	// Modify the check: Company Name is the most critical piece.
 	// If only company name is found, maybe that's acceptable for an initial insert.
@@ -271,70 +408,289 @@ func handlerStockFetchProfile(s *AppState, cmd command) error {
 	if countryCode == "" && sector == "" && subsector == "" {
 		log.Printf("Warning: Extracted company name '%s' for %s, but other profile details (country, sector, subsector) are missing.", companyName, stockCode)
 	}
+	if countryCode == "" {
+		countryCode = s.cfg.DefaultCountry // The profile source only lists DEFAULT_COUNTRY companies
+	}
 
 	// --- Step 4: Store/Update in Database (companies table) ---
 	// (This part remains the same as your previous working version, using sql.NullString)
+	sectorRaw := sql.NullString{String: sector, Valid: sector != ""}
+	subsectorRaw := sql.NullString{String: subsector, Valid: subsector != ""}
+	canonicalSector, canonicalSubsector := sectorRaw, subsectorRaw
+	if taxonomy, err := loadSectorTaxonomy(cmd.Context(), s); err != nil {
+		log.Printf("Warning: storing the scraped sector of %s as is: %v", stockCode, err)
+	} else {
+		canonicalSector = taxonomy.normalize(sectorKind, sectorRaw)
+		canonicalSubsector = taxonomy.normalize(subsectorKind, subsectorRaw)
+	}
 	params := database.UpsertCompanyParams{
-		StockCode:        stockCode,
-		CompanyName:      companyName, // Should have a value if we passed the check above
-		CountryCode:      sql.NullString{String: countryCode, Valid: countryCode != ""},
-		Sector:           sql.NullString{String: sector, Valid: sector != ""},
-		Subsector:        sql.NullString{String: subsector, Valid: subsector != ""},
-		ListingDate:      sql.NullTime{Valid: false}, // Assuming not scraped yet
-		ProfileSourceUrl: sql.NullString{String: profileURL, Valid: true},
+		StockCode:         stockCode,
+		CompanyName:       companyName, // Should have a value if we passed the check above
+		CountryCode:       sql.NullString{String: countryCode, Valid: countryCode != ""},
+		Sector:            canonicalSector,
+		Subsector:         canonicalSubsector,
+		SectorRaw:         sectorRaw,
+		SubsectorRaw:      subsectorRaw,
+		ListingDate:       listingDate,
+		ProfileSourceUrl:  sql.NullString{String: profileURL, Valid: true},
+		Website:           sql.NullString{String: website, Valid: website != ""},
+		ParValue:          parValue,
+		SharesOutstanding: sharesOutstanding,
+		ShortName:         sql.NullString{String: shortName, Valid: shortName != ""},
 	}
 
-	err = s.db.UpsertCompany(context.Background(), params)
+	err = s.db.UpsertCompany(cmd.Context(), params)
 	if err != nil {
 		return fmt.Errorf("failed to upsert company profile for %s: %w", stockCode, err)
 	}
 
 	log.Printf("Successfully stored/updated profile for stock %s.", stockCode)
 	fmt.Printf("Profile for %s: Name: %s, Country: %s, Sector: %s, Subsector: %s\n",
-		stockCode, companyName, countryCode, sector, subsector)
+		stockCode, companyName, countryCode, canonicalSector.String, canonicalSubsector.String)
+	fmt.Printf("  Listing Date: %s, Website: %s, Par Value: %s, Shares Outstanding: %s\n",
+		listingDateStr, website, parValueStr, sharesOutstandingStr)
 
 	return nil
 }
 
-// Your handlerStockFetchPriceAll can be modified to call this new handler
-// for each stock to populate the companies table initially.
+// stockShortName picks the board name (e.g. "MAYBANK") out of the two names a profile page
+// shows: when the heading and the profile section differ, the shorter one is the board name
+// and the other the registered name. It returns "" when the page shows only one name.
+func stockShortName(heading, profileName string) string {
+	if heading == "" || profileName == "" || strings.EqualFold(heading, profileName) {
+		return ""
+	}
+	if len(profileName) < len(heading) {
+		return profileName
+	}
+	return heading
+}
+
+// parseProfileDate parses the date formats seen on i3investor profile pages (e.g. "19-Feb-1962", "19 Feb 1962").
+func parseProfileDate(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	layouts := []string{"02-Jan-2006", "2-Jan-2006", "02 Jan 2006", "2 Jan 2006", "2006-01-02", "02/01/2006"}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date format")
+}
+
+// parseProfileNumber parses numeric profile values such as "RM 1.00" or "11,935,014,000".
+func parseProfileNumber(raw string) (float64, error) {
+	cleaned := strings.TrimSpace(raw)
+	cleaned = strings.TrimPrefix(cleaned, "RM")
+	cleaned = strings.ReplaceAll(cleaned, ",", "")
+	cleaned = strings.TrimSpace(cleaned)
+	return strconv.ParseFloat(cleaned, 64)
+}
+
+// profileIsFresh reports whether the stored profile for stockCode was scraped within the
+// configured PROFILE_REFRESH_INTERVAL. Missing companies are never considered fresh.
+func profileIsFresh(ctx context.Context, s *AppState, stockCode string) (bool, time.Time, error) {
+	company, err := s.db.GetCompanyByStockCode(ctx, stockCode)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, time.Time{}, nil
+		}
+		return false, time.Time{}, err
+	}
+	if !company.ProfileLastScrapedAt.Valid {
+		return false, time.Time{}, nil
+	}
+	lastScraped := company.ProfileLastScrapedAt.Time
+	return time.Since(lastScraped) < s.cfg.ProfileRefreshInterval, lastScraped, nil
+}
+
+// reportStockFetchError sends a failed scrape within a batch to error reporting, tagged with
+// the stock code and the page URL.
+func reportStockFetchError(cmd command, stockCode, url string, err error) {
+	errreport.CaptureError(cmd.Context(), err, map[string]string{
+		"command":    cmd.Name,
+		"stock_code": stockCode,
+		"url":        url,
+	})
+}
+
+// handlerStockFetchPriceAllAndProfiles fetches prices and profiles for every stock in the config list.
+// Profiles refreshed within PROFILE_REFRESH_INTERVAL are skipped unless --force is given.
+// Usage: stock:fetch:profile_all [--force]
 func handlerStockFetchPriceAllAndProfiles(s *AppState, cmd command) error { // Renamed for clarity
-	if len(cmd.Args) != 0 {
-		return fmt.Errorf("usage: %s (no arguments)", cmd.Name)
+	force := false
+	switch {
+	case len(cmd.Args) == 0:
+	case len(cmd.Args) == 1 && cmd.Args[0] == "--force":
+		force = true
+	default:
+		return fmt.Errorf("usage: %s [--force]", cmd.Name)
 	}
 
-	stockCodes := s.cfg.StockList
+	stockCodes := activeStocks(cmd.Context(), s, trackedStocks(cmd.Context(), s, s.cfg.DefaultCountry))
 	if len(stockCodes) == 0 {
-		log.Println("No stock codes found in configuration to fetch.")
+		log.Println("No stock codes found in configuration or tracked instruments to fetch.")
 		return nil
 	}
 
 	log.Printf("Starting to fetch prices and profiles for %d stocks.", len(stockCodes))
+	run := startFetchRun(s, cmd)
+	cmd = run.attach(cmd)
 
+	var profilesFetched, profilesSkipped, pricesStored int
+	var failures []string
+	var pages stockPageChecks
 	for _, stockCode := range stockCodes {
-		// Fetch Profile
-		profileCmd := command{Name: "stock:fetch:profile", Args: []string{stockCode}}
-		log.Printf("--- Fetching Profile for %s ---", stockCode)
-		if err := handlerStockFetchProfile(s, profileCmd); err != nil {
-			log.Printf("Failed to fetch/store profile for %s: %v", stockCode, err)
-			// Decide if you want to continue to price fetching if profile fails
-		} else {
-			log.Printf("Profile for %s processed.", stockCode)
+		if cmd.Context().Err() != nil {
+			break // Shutting down or timed out
+		}
+		// Fetch Profile, unless it was refreshed recently
+		fresh := false
+		if !force {
+			var lastScraped time.Time
+			var err error
+			fresh, lastScraped, err = profileIsFresh(cmd.Context(), s, stockCode)
+			if err != nil {
+				log.Printf("Failed to check profile freshness for %s, refetching: %v", stockCode, err)
+			} else if fresh {
+				log.Printf("--- Skipping Profile for %s (last scraped %s) ---", stockCode, lastScraped.Format(time.RFC3339))
+				profilesSkipped++
+			}
+		}
+		if !fresh {
+			profileCmd := cmd.subcommand("stock:fetch:profile", stockCode)
+			log.Printf("--- Fetching Profile for %s ---", stockCode)
+			if err := handlerStockFetchProfile(s, profileCmd); err != nil {
+				log.Printf("Failed to fetch/store profile for %s: %v", stockCode, err)
+				failures = append(failures, fmt.Sprintf("profile %s: %v", stockCode, err))
+				reportStockFetchError(profileCmd, stockCode, s.sources.Source(config.SourceI3InvestorProfile).BaseURL()+stockCode, err)
+				// Decide if you want to continue to price fetching if profile fails
+			} else {
+				log.Printf("Profile for %s processed.", stockCode)
+				profilesFetched++
+			}
 		}
 
 		// Fetch Price (your existing logic)
-		priceCmd := command{Name: "stock:fetch:price", Args: []string{stockCode}}
+		priceCmd := cmd.subcommand("stock:fetch:price", stockCode)
 		log.Printf("--- Fetching Price for %s ---", stockCode)
-		if err := handlerStockFetchPrice(s, priceCmd); err != nil {
+		err := handlerStockFetchPrice(s, priceCmd)
+		pages.record(stockCode, err)
+		if err != nil {
 			log.Printf("Failed to fetch/store price for %s: %v", stockCode, err)
+			failures = append(failures, fmt.Sprintf("price %s: %v", stockCode, err))
+			reportStockFetchError(priceCmd, stockCode, s.sources.Source(config.SourceI3Investor).BaseURL()+stockCode, err)
 		} else {
 			log.Printf("Price for %s processed.", stockCode)
+			pricesStored++
 		}
 		log.Println("--- --- ---")
+	}
+	log.Printf("Finished fetching all stock prices and profiles (%d profiles refreshed, %d skipped as fresh).", profilesFetched, profilesSkipped)
+	runErr := cmd.Context().Err()
+	if runErr == nil {
+		pages.apply(cmd.Context(), s)
+	}
+	run.finish(s, fetchStats{
+		SuccessfulFetches: profilesFetched + pricesStored,
+		SuccessfulStores:  profilesFetched + pricesStored,
+		FailedFetches:     len(failures),
+		Errors:            failures,
+	}, runErr)
+	if runErr != nil {
+		return runErr
+	}
+	notifyDataStored(s, cmd.Name, profilesFetched+pricesStored)
+	notifyBatchFailures(s, cmd.Name, failures)
+	runPostFetchJobs(cmd.Context(), s)
+	return nil
+}
 
-		// Optional: Add a small delay to be polite to the server
-		time.Sleep(500 * time.Millisecond) // 0.5 second delay
+// handlerStockRepairDates fixes prices that were filed under the previous day because their
+// date was taken from the UTC clock before 08:00 MYT. Each is moved to its market date, or
+// dropped when a later fetch already stored a price for that day. The returns and volatility
+// of each repaired stock are cleared from its earliest repaired date and recomputed. Without
+// --apply the affected rows are only listed (admin only).
+// Usage: stock:repair:dates [--apply]
+func handlerStockRepairDates(s *AppState, cmd command) error {
+	apply := false
+	for _, arg := range cmd.Args {
+		if arg != "--apply" {
+			return fmt.Errorf("usage: %s [--apply]", cmd.Name)
+		}
+		apply = true
+	}
+	ctx := cmd.Context()
+	rows, err := s.db.ListMisdatedStockPrices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list misdated stock prices: %w", err)
+	}
+	if len(rows) == 0 {
+		fmt.Println("No misdated stock prices found.")
+		return nil
+	}
+	if !apply {
+		for _, row := range rows {
+			fmt.Printf("  %-6s %s -> %s  %s (extracted %s)\n", row.StockCode, row.PriceDate.Format("2006-01-02"),
+				row.PriceDate.AddDate(0, 0, 1).Format("2006-01-02"), row.ClosingPrice, row.ExtractedAt.In(markettime.Location).Format("2006-01-02 15:04 MST"))
+		}
+		fmt.Printf("%d misdated price(s). Run %s --apply to fix them.\n", len(rows), cmd.Name)
+		return nil
+	}
+
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+	// Newest first, so a run of misdated days shifts without colliding with itself
+	moved, dropped := 0, 0
+	earliest := make(map[string]time.Time) // Earliest repaired date of each stock
+	for _, row := range rows {
+		if first, ok := earliest[row.StockCode]; !ok || row.PriceDate.Before(first) {
+			earliest[row.StockCode] = row.PriceDate
+		}
+		n, err := qtx.ShiftStockPriceDate(ctx, row.ID)
+		if err != nil {
+			return fmt.Errorf("failed to move %s price of %s: %w", row.StockCode, row.PriceDate.Format("2006-01-02"), err)
+		}
+		if n > 0 {
+			moved++
+			continue
+		}
+		if err := qtx.DeleteStockPrice(ctx, row.ID); err != nil {
+			return fmt.Errorf("failed to drop %s price of %s: %w", row.StockCode, row.PriceDate.Format("2006-01-02"), err)
+		}
+		dropped++
+	}
+	// The recomputation below only upserts, so returns and volatility on dates that lost their
+	// price would otherwise be left behind
+	for code, from := range earliest {
+		series := seriesKey{Kind: watchlistStock, Code: code}.String()
+		err := qtx.DeleteDailyReturnsFromDate(ctx, database.DeleteDailyReturnsFromDateParams{Series: series, FromDate: from})
+		if err != nil {
+			return fmt.Errorf("failed to clear returns of %s from %s: %w", series, from.Format("2006-01-02"), err)
+		}
+		err = qtx.DeleteRollingVolatilityFromDate(ctx, database.DeleteRollingVolatilityFromDateParams{Series: series, FromDate: from})
+		if err != nil {
+			return fmt.Errorf("failed to clear volatility of %s from %s: %w", series, from.Format("2006-01-02"), err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	refreshStockRanges(ctx, s, "")
+	invalidateResponseCache(s)
+	log.Printf("Repaired stock price dates: %d moved, %d superseded and dropped.", moved, dropped)
+	fmt.Printf("Moved %d price(s) to their market date and dropped %d superseded by a later fetch.\n", moved, dropped)
+
+	// Returns and everything derived from them are keyed by date
+	if _, err := computeDailyReturns(ctx, s, true); err != nil {
+		return fmt.Errorf("failed to recompute daily returns: %w", err)
+	}
+	if _, err := computeRollingVolatility(ctx, s, true); err != nil {
+		return fmt.Errorf("failed to recompute rolling volatility: %w", err)
 	}
-	log.Println("Finished fetching all stock prices and profiles.")
 	return nil
 }
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// bursaStockCodePattern matches a Bursa Malaysia stock code: four digits, optionally followed by
+// a suffix for preference shares, warrants, loan stocks or structured warrants (1155PA, 0138WB,
+// 5099LA, 1155C25).
+var bursaStockCodePattern = regexp.MustCompile(`^[0-9]{4}(?:[A-Z][A-Z0-9]{0,2})?$`)
+
+// bursaCodeHint explains the expected format in errors about rejected stock codes.
+const bursaCodeHint = "Bursa codes are 4 digits with an optional suffix, e.g. 1155, 1155PA or 0138WB"
+
+// normalizeStockCode returns raw in canonical form: trimmed and upper-cased, without the .KL
+// suffix Yahoo Finance uses for Bursa listings, and numeric codes zero-padded to 4 digits
+// ("138" becomes "0138").
+func normalizeStockCode(raw string) string {
+	code := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(raw)), ".KL")
+	if len(code) > 0 && len(code) < 4 && strings.Trim(code, "0123456789") == "" {
+		code = strings.Repeat("0", 4-len(code)) + code
+	}
+	return code
+}
+
+// parseStockCode normalizes raw and accepts it if it has the Bursa format or belongs to a stored
+// company (an index, or a company listed elsewhere and added with company:add). Anything else is
+// rejected before it can key a scrape or a stored row.
+func parseStockCode(ctx context.Context, s *AppState, raw string) (string, error) {
+	code := normalizeStockCode(raw)
+	if bursaStockCodePattern.MatchString(code) {
+		return code, nil
+	}
+	if stockCodePattern.MatchString(code) && stockCodeStored(ctx, s, code) {
+		return code, nil
+	}
+	return "", fmt.Errorf("invalid stock code %q (%s)", raw, bursaCodeHint)
+}
+
+// lookupStockCode normalizes raw and checks that a company with the code is stored, so
+// watchlists, holdings and alert rules cannot be keyed by a typo.
+func lookupStockCode(ctx context.Context, s *AppState, raw string) (string, error) {
+	code := normalizeStockCode(raw)
+	if !stockCodePattern.MatchString(code) {
+		return "", fmt.Errorf("invalid stock code %q (%s)", raw, bursaCodeHint)
+	}
+	if !stockCodeStored(ctx, s, code) {
+		return "", fmt.Errorf("unknown stock code %s (fetch its profile or add it with company:add first)", code)
+	}
+	return code, nil
+}
+
+// stockCodeStored reports whether a company with code is stored. Database errors are logged and
+// the code is accepted, leaving the caller's own query to fail.
+func stockCodeStored(ctx context.Context, s *AppState, code string) bool {
+	_, err := s.db.GetCompanyByStockCode(ctx, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		log.Printf("Error looking up stock code %s: %v", code, err)
+	}
+	return true
+}
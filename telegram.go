@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/auth"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+)
+
+// telegramPollTimeout is how long each getUpdates long-poll waits for a message.
+const telegramPollTimeout = 50 * time.Second
+
+// telegramLinkTokenTTL is how long a telegram:link token can be sent to the bot.
+const telegramLinkTokenTTL = 15 * time.Minute
+
+// runTelegramBot answers bot queries until ctx is cancelled. It returns immediately if no
+// bot token is configured.
+func runTelegramBot(ctx context.Context, wg *sync.WaitGroup, appState *AppState) {
+	defer wg.Done()
+	if appState.telegram == nil {
+		return
+	}
+	log.Println("Telegram bot: polling for messages.")
+
+	var offset int64
+	for {
+		updates, err := appState.telegram.GetUpdates(ctx, offset, telegramPollTimeout)
+		if ctx.Err() != nil {
+			log.Println("Telegram bot stopped.")
+			return
+		}
+		if err != nil {
+			log.Printf("Telegram bot: %v", err)
+			select { // Back off before retrying
+			case <-ctx.Done():
+				log.Println("Telegram bot stopped.")
+				return
+			case <-time.After(10 * time.Second):
+			}
+			continue
+		}
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message == nil || u.Message.Text == "" {
+				continue
+			}
+			var reply string
+			err := runRecovered("Telegram query", func() error {
+				reply = handleTelegramQuery(ctx, appState, u.Message.Chat.ID, u.Message.Text)
+				return nil
+			})
+			if err != nil {
+				reply = "Sorry, something went wrong answering that."
+			}
+			if err := appState.telegram.SendMessage(ctx, u.Message.Chat.ID, reply); err != nil {
+				log.Printf("Telegram bot: failed to reply to chat %d: %v", u.Message.Chat.ID, err)
+			}
+		}
+	}
+}
+
+// handleTelegramQuery answers a bot command such as "/price 1155" or "/fx USD" from the database.
+func handleTelegramQuery(ctx context.Context, s *AppState, chatID int64, text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return telegramHelp()
+	}
+	// Commands may be addressed to the bot in groups, e.g. "/price@MyEconBot"
+	name, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+	args := fields[1:]
+
+	switch name {
+	case "/start":
+		if len(args) != 1 {
+			return telegramHelp() // Sent on opening the bot, without a token
+		}
+		return telegramLinkChat(ctx, s, chatID, args[0])
+	case "/price":
+		if len(args) != 1 {
+			return "Usage: /price <stock code>, e.g. /price 1155"
+		}
+		return telegramLatest(ctx, s, watchlistStock+":"+args[0])
+	case "/fx":
+		if len(args) != 1 {
+			return "Usage: /fx <currency>, e.g. /fx USD"
+		}
+		return telegramLatest(ctx, s, watchlistFx+":"+args[0])
+	default:
+		return telegramHelp()
+	}
+}
+
+// telegramLinkChat links chatID to the user a telegram:link token was issued to. Receiving the
+// token from the chat is what shows the user controls it.
+func telegramLinkChat(ctx context.Context, s *AppState, chatID int64, token string) string {
+	userID, err := s.db.ClaimTelegramLinkToken(ctx, auth.HashToken(token))
+	if err == sql.ErrNoRows {
+		return "That link code is unknown or has expired. Run telegram:link in the CLI for a new one."
+	}
+	if err != nil {
+		log.Printf("Telegram bot: failed to claim link token: %v", err)
+		return "Sorry, something went wrong linking this chat."
+	}
+	err = s.db.SetUserTelegramChatID(ctx, database.SetUserTelegramChatIDParams{
+		TelegramChatID: sql.NullInt64{Int64: chatID, Valid: true},
+		ID:             userID,
+	})
+	if err != nil {
+		log.Printf("Telegram bot: failed to link chat %d: %v", chatID, err)
+		return "Sorry, something went wrong linking this chat."
+	}
+	user, err := s.db.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Printf("Telegram bot: linked chat %d but failed to load user %s: %v", chatID, userID, err)
+		return "Alerts will be sent here."
+	}
+	log.Printf("Telegram bot: linked chat %d to %s.", chatID, user.Username)
+	return fmt.Sprintf("Alerts for %s will be sent here.", user.Username)
+}
+
+func telegramLatest(ctx context.Context, s *AppState, series string) string {
+	key, err := parseSeriesKey(series)
+	if err != nil {
+		return err.Error()
+	}
+	point, err := latestSeriesValue(ctx, s, key)
+	if err == sql.ErrNoRows {
+		return fmt.Sprintf("No data stored for %s.", key.Code)
+	}
+	if err != nil {
+		log.Printf("Telegram bot: failed to load %s: %v", key, err)
+		return "Sorry, something went wrong looking that up."
+	}
+	date := point.Date.Format("2006-01-02")
+	if key.Kind == watchlistFx {
+		return fmt.Sprintf("%s: %.4f MYR per unit (BNM middle rate, %s)", key.Code, point.Value, date)
+	}
+	label := key.Code
+	if company, err := s.db.GetCompanyByStockCode(ctx, key.Code); err == nil {
+		label = fmt.Sprintf("%s (%s)", key.Code, company.CompanyName)
+	}
+	return fmt.Sprintf("%s: closed at %.4f on %s", label, point.Value, date)
+}
+
+func telegramHelp() string {
+	return "Commands:\n" +
+		"/price <stock code> - latest closing price, e.g. /price 1155\n" +
+		"/fx <currency> - latest BNM middle rate, e.g. /fx USD\n\n" +
+		"To receive your alerts here, run telegram:link in the CLI and send the /start message it shows."
+}
+
+// --- Telegram Command Handlers ---
+
+// handlerTelegramLink starts linking the current user's alerts to a Telegram chat. It issues a
+// one-time code; the chat is linked when "/start <code>" is sent to the bot from it, so only a
+// chat the user controls can be linked.
+// Usage: telegram:link
+func handlerTelegramLink(s *AppState, cmd command) error {
+	user := cmd.user
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+	if s.telegram == nil {
+		return fmt.Errorf("no Telegram bot is configured (TELEGRAM_BOT_TOKEN)")
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("failed to generate link code: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	err := s.db.UpsertTelegramLinkToken(cmd.Context(), database.UpsertTelegramLinkTokenParams{
+		UserID:    user.ID,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(telegramLinkTokenTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store link code: %w", err)
+	}
+	fmt.Printf("Within %s, send this message to the bot from the chat that should receive your alerts:\n  /start %s\n", telegramLinkTokenTTL, token)
+	return nil
+}
+
+// handlerTelegramUnlink stops sending the current user's alerts to Telegram.
+// Usage: telegram:unlink
+func handlerTelegramUnlink(s *AppState, cmd command) error {
+	user := cmd.user
+	err := s.db.SetUserTelegramChatID(cmd.Context(), database.SetUserTelegramChatIDParams{
+		TelegramChatID: sql.NullInt64{},
+		ID:             user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unlink Telegram chat: %w", err)
+	}
+	fmt.Println("Telegram alerts disabled.")
+	return nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+)
+
+// trackedStocks returns the stock codes of country (all countries when empty): STOCK_LIST,
+// which lists DEFAULT_COUNTRY stocks, followed by the codes added with tracked:add. Database
+// errors are logged and STOCK_LIST alone is used.
+func trackedStocks(ctx context.Context, s *AppState, country string) []string {
+	var codes []string
+	if country == "" || country == s.cfg.DefaultCountry {
+		codes = slices.Clone(s.cfg.StockList)
+	}
+	items, err := s.db.ListTrackedInstruments(ctx)
+	if err != nil {
+		log.Printf("Error loading tracked instruments, using STOCK_LIST only: %v", err)
+		return codes
+	}
+	for _, item := range items {
+		if item.ItemType != watchlistStock || (country != "" && item.CountryCode != country) {
+			continue
+		}
+		if !slices.Contains(codes, item.Code) {
+			codes = append(codes, item.Code)
+		}
+	}
+	return codes
+}
+
+// normalizeCountry upper-cases a country code, defaulting to DEFAULT_COUNTRY when it is empty.
+func normalizeCountry(s *AppState, country string) (string, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "" {
+		return s.cfg.DefaultCountry, nil
+	}
+	if !countryCodePattern.MatchString(country) {
+		return "", fmt.Errorf("invalid country code %q (use a 2-letter ISO code such as MY or SG)", country)
+	}
+	return country, nil
+}
+
+// trackedCurrencies returns the currencies added with tracked:add. When it is empty,
+// fx:fetch_all stores every currency the provider returns.
+func trackedCurrencies(ctx context.Context, s *AppState) ([]string, error) {
+	items, err := s.db.ListTrackedInstruments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tracked currencies: %w", err)
+	}
+	var codes []string
+	for _, item := range items {
+		if item.ItemType == watchlistFx {
+			codes = append(codes, item.Code)
+		}
+	}
+	return codes, nil
+}
+
+// addTrackedInstrument validates and stores a tracked stock or currency, returning the
+// canonical type and code and whether the instrument was new (stocks in STOCK_LIST never are).
+// country is the stock's market (DEFAULT_COUNTRY when empty); only DEFAULT_COUNTRY stocks are
+// scraped, others are loaded through /api/ingest. Invalid input is returned with an empty
+// type, so callers can tell it from database errors.
+func addTrackedInstrument(ctx context.Context, s *AppState, itemType, code, country, username string) (string, string, bool, error) {
+	itemType, code, err := normalizeWatchlistItem(itemType, code)
+	if err != nil {
+		return "", "", false, err
+	}
+	if country, err = normalizeCountry(s, country); err != nil {
+		return "", "", false, err
+	}
+	if itemType == watchlistFx && country != s.cfg.DefaultCountry {
+		return "", "", false, fmt.Errorf("currencies are quoted against %s and cannot be tracked for %s", baseCurrency, country)
+	}
+	if itemType == watchlistStock {
+		// DEFAULT_COUNTRY stocks are scraped, which creates the company; others must be added with company:add first
+		if country == s.cfg.DefaultCountry {
+			code, err = parseStockCode(ctx, s, code)
+		} else {
+			code, err = lookupStockCode(ctx, s, code)
+		}
+		if err != nil {
+			return "", "", false, err
+		}
+	}
+	if itemType == watchlistStock && country == s.cfg.DefaultCountry && slices.Contains(s.cfg.StockList, code) {
+		return itemType, code, false, nil
+	}
+	n, err := s.db.AddTrackedInstrument(ctx, database.AddTrackedInstrumentParams{
+		ItemType:    itemType,
+		Code:        code,
+		AddedBy:     username,
+		CountryCode: country,
+	})
+	if err != nil {
+		return itemType, code, false, fmt.Errorf("failed to track %s %s: %w", itemType, code, err)
+	}
+	return itemType, code, n > 0, nil
+}
+
+// removeTrackedInstrument stops tracking a stock or currency added with tracked:add and reports
+// whether it was tracked. Codes from STOCK_LIST cannot be removed here. As with
+// addTrackedInstrument, invalid input is returned with an empty type.
+func removeTrackedInstrument(ctx context.Context, s *AppState, itemType, code string) (string, string, bool, error) {
+	itemType, code, err := normalizeWatchlistItem(itemType, code)
+	if err != nil {
+		return "", "", false, err
+	}
+	if itemType == watchlistStock && slices.Contains(s.cfg.StockList, code) {
+		return "", "", false, fmt.Errorf("stock %s is configured in STOCK_LIST; remove it there", code)
+	}
+	n, err := s.db.RemoveTrackedInstrument(ctx, database.RemoveTrackedInstrumentParams{
+		ItemType: itemType,
+		Code:     code,
+	})
+	if err != nil {
+		return itemType, code, false, fmt.Errorf("failed to untrack %s %s: %w", itemType, code, err)
+	}
+	return itemType, code, n > 0, nil
+}
+
+// --- Tracked Instrument Command Handlers ---
+
+// handlerTracked prints the stock codes and currencies covered by batch fetches, optionally
+// only those of one country.
+// Usage: tracked [COUNTRY]
+func handlerTracked(s *AppState, cmd command) error {
+	if len(cmd.Args) > 1 {
+		return fmt.Errorf("usage: %s [COUNTRY]", cmd.Name)
+	}
+	country := ""
+	if len(cmd.Args) == 1 {
+		var err error
+		if country, err = normalizeCountry(s, cmd.Args[0]); err != nil {
+			return err
+		}
+	}
+	if country == "" || country == s.cfg.DefaultCountry {
+		for _, code := range s.cfg.StockList {
+			fmt.Printf("  %-5s %-8s %s (STOCK_LIST)\n", watchlistStock, code, s.cfg.DefaultCountry)
+		}
+	}
+	items, err := s.db.ListTrackedInstruments(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to load tracked instruments: %w", err)
+	}
+	currencies := 0
+	for _, item := range items {
+		if item.ItemType == watchlistFx {
+			currencies++
+		}
+		if country != "" && item.CountryCode != country {
+			continue
+		}
+		fmt.Printf("  %-5s %-8s %s added by %s on %s\n", item.ItemType, item.Code, item.CountryCode, item.AddedBy, item.AddedAt.Format("2006-01-02"))
+	}
+	if currencies == 0 {
+		fmt.Println("No currencies are tracked; fx:fetch_all stores every currency the provider returns.")
+	}
+	return nil
+}
+
+// handlerTrackedAdd adds a stock code or currency to the batch fetches (admin only). Stocks
+// of another country than DEFAULT_COUNTRY are listed, but their prices come from /api/ingest.
+// Usage: tracked:add <stock|fx> <code> [COUNTRY]
+func handlerTrackedAdd(s *AppState, cmd command) error {
+	user := cmd.user
+	if len(cmd.Args) < 2 || len(cmd.Args) > 3 {
+		return fmt.Errorf("usage: %s <stock|fx> <code> [COUNTRY]", cmd.Name)
+	}
+	country := ""
+	if len(cmd.Args) == 3 {
+		country = cmd.Args[2]
+	}
+	itemType, code, added, err := addTrackedInstrument(cmd.Context(), s, cmd.Args[0], cmd.Args[1], country, user.Username)
+	if err != nil {
+		return err
+	}
+	if !added {
+		fmt.Printf("%s %s is already tracked.\n", itemType, code)
+		return nil
+	}
+	log.Printf("User %s started tracking %s %s.", user.Username, itemType, code)
+	fmt.Printf("Now tracking %s %s.\n", itemType, code)
+	return nil
+}
+
+// handlerTrackedRemove removes a stock code or currency added with tracked:add (admin only).
+// Stored prices and rates are kept.
+// Usage: tracked:remove <stock|fx> <code>
+func handlerTrackedRemove(s *AppState, cmd command) error {
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("usage: %s <stock|fx> <code>", cmd.Name)
+	}
+	itemType, code, removed, err := removeTrackedInstrument(cmd.Context(), s, cmd.Args[0], cmd.Args[1])
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("%s %s is not tracked", itemType, code)
+	}
+	fmt.Printf("Stopped tracking %s %s.\n", itemType, code)
+	return nil
+}
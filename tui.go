@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tuiDefaultInterval is how often the dashboard reloads its panels unless --interval is given.
+const tuiDefaultInterval = 5 * time.Second
+
+// tuiRunRows is the number of recent fetch runs shown.
+const tuiRunRows = 8
+
+// tuiLogLines is the number of log lines kept for the log panel while the dashboard runs.
+const tuiLogLines = 200
+
+var (
+	tuiPanelStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+	tuiTitleStyle = lipgloss.NewStyle().Bold(true)
+	tuiUpStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	tuiDownStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	tuiDimStyle   = lipgloss.NewStyle().Faint(true)
+)
+
+// tuiQuote is the latest value of one series and its change from the previous observation.
+type tuiQuote struct {
+	Code      string
+	Value     float64
+	Date      time.Time
+	Change    float64 // Percent
+	HasChange bool
+}
+
+// tuiSnapshot is one reload of every panel.
+type tuiSnapshot struct {
+	FX             []tuiQuote
+	WatchlistTitle string
+	Watchlist      []tuiQuote
+	Runs           []database.FetchRun
+	At             time.Time
+	Errs           []string
+}
+
+type tuiTickMsg time.Time
+
+// tuiModel is the bubbletea model of the dashboard.
+type tuiModel struct {
+	ctx      context.Context
+	s        *AppState
+	user     *database.User // Watchlist owner; tracked stocks are shown without a login
+	interval time.Duration
+	logs     *tuiLogBuffer
+	snap     tuiSnapshot
+	loading  bool
+	width    int
+}
+
+// handlerTUI opens a full-screen terminal dashboard with the latest FX rates, watchlist prices
+// (tracked stocks when not logged in), recent fetch runs and the application log, reloaded every
+// interval. It closes with q, on shutdown or after COMMAND_TIMEOUT.
+// Usage: tui [--interval=DURATION]  (default: 5s)
+func handlerTUI(s *AppState, cmd command) error {
+	interval := tuiDefaultInterval
+	for _, arg := range cmd.Args {
+		value, ok := strings.CutPrefix(arg, "--interval=")
+		d, err := time.ParseDuration(value)
+		if !ok || err != nil || d < time.Second {
+			return fmt.Errorf("usage: %s [--interval=DURATION] (at least 1s)", cmd.Name)
+		}
+		interval = d
+	}
+
+	// Log lines would tear the screen; they go to the log panel and are written out on exit
+	logs := &tuiLogBuffer{}
+	previous := log.Writer()
+	log.SetOutput(logs)
+	defer func() {
+		log.SetOutput(previous)
+		logs.replay(previous)
+	}()
+
+	model := tuiModel{ctx: cmd.Context(), s: s, user: s.currentUser, interval: interval, logs: logs, loading: true}
+	_, err := tea.NewProgram(model, tea.WithContext(cmd.Context()), tea.WithAltScreen()).Run()
+	if errors.Is(err, tea.ErrProgramKilled) {
+		return cmd.Context().Err()
+	}
+	return err
+}
+
+func (m tuiModel) Init() tea.Cmd {
+	return tea.Batch(m.reload(), m.tick())
+}
+
+func (m tuiModel) tick() tea.Cmd {
+	return tea.Tick(m.interval, func(t time.Time) tea.Msg { return tuiTickMsg(t) })
+}
+
+// reload loads a snapshot in the background; a slow database never blocks key handling.
+func (m tuiModel) reload() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(m.ctx, m.interval)
+		defer cancel()
+		return loadTUISnapshot(ctx, m.s, m.user)
+	}
+}
+
+func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "r":
+			if !m.loading {
+				m.loading = true
+				return m, m.reload()
+			}
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	case tuiTickMsg:
+		if m.loading {
+			return m, m.tick() // The previous reload is still running
+		}
+		m.loading = true
+		return m, tea.Batch(m.reload(), m.tick())
+	case tuiSnapshot:
+		m.snap = msg
+		m.loading = false
+	}
+	return m, nil
+}
+
+func (m tuiModel) View() string {
+	half := 40
+	if m.width > 0 {
+		half = max(m.width/2-2, 30)
+	}
+	status := "loading..."
+	if !m.snap.At.IsZero() {
+		status = "updated " + m.snap.At.Format("15:04:05")
+	}
+	header := tuiTitleStyle.Render("Malaysia Econ DB") + tuiDimStyle.Render(fmt.Sprintf("  %s, every %s  (r: reload, q: quit)", status, m.interval))
+
+	fx := tuiPanelStyle.Width(half).Render(tuiQuotePanel("FX (MYR per unit)", m.snap.FX))
+	watch := tuiPanelStyle.Width(half).Render(tuiQuotePanel(m.snap.WatchlistTitle, m.snap.Watchlist))
+	runs := tuiPanelStyle.Width(2*half + 2).Render(tuiRunsPanel(m.snap.Runs))
+	logs := tuiPanelStyle.Width(2*half + 2).Render(tuiTitleStyle.Render("Log") + "\n" + strings.Join(m.logs.last(6), "\n"))
+
+	parts := []string{header, lipgloss.JoinHorizontal(lipgloss.Top, fx, watch), runs, logs}
+	for _, e := range m.snap.Errs {
+		parts = append(parts, tuiDownStyle.Render("Error: "+e))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// tuiQuotePanel renders quotes as code, value, change and date columns.
+func tuiQuotePanel(title string, quotes []tuiQuote) string {
+	var b strings.Builder
+	b.WriteString(tuiTitleStyle.Render(title))
+	if len(quotes) == 0 {
+		b.WriteString("\n" + tuiDimStyle.Render("nothing stored"))
+	}
+	for _, q := range quotes {
+		change := tuiDimStyle.Render("      -")
+		if q.HasChange {
+			style := tuiUpStyle
+			if q.Change < 0 {
+				style = tuiDownStyle
+			}
+			change = style.Render(fmt.Sprintf("%+6.2f%%", q.Change))
+		}
+		fmt.Fprintf(&b, "\n%-8s %12.4f %s  %s", q.Code, q.Value, change, tuiDimStyle.Render(q.Date.Format("2006-01-02")))
+	}
+	return b.String()
+}
+
+// tuiRunsPanel renders the recent fetch runs, newest first.
+func tuiRunsPanel(runs []database.FetchRun) string {
+	var b strings.Builder
+	b.WriteString(tuiTitleStyle.Render("Fetch runs"))
+	if len(runs) == 0 {
+		b.WriteString("\n" + tuiDimStyle.Render("none recorded"))
+	}
+	for _, r := range runs {
+		took := "running " + time.Since(r.StartedAt).Round(time.Second).String()
+		if r.FinishedAt.Valid {
+			took = r.FinishedAt.Time.Sub(r.StartedAt).Round(time.Second).String()
+		}
+		status := r.Status
+		switch r.Status {
+		case fetchRunSucceeded:
+			status = tuiUpStyle.Render(fmt.Sprintf("%-9s", status))
+		case fetchRunFailed, fetchRunPartial:
+			status = tuiDownStyle.Render(fmt.Sprintf("%-9s", status))
+		default:
+			status = fmt.Sprintf("%-9s", status)
+		}
+		fmt.Fprintf(&b, "\n%s  %-26s %s  ok %d/%d  failed %d/%d  %s",
+			r.StartedAt.Local().Format("01-02 15:04"), r.Command, status,
+			r.SuccessfulFetches, r.SuccessfulStores, r.FailedFetches, r.FailedStores, tuiDimStyle.Render(took))
+	}
+	return b.String()
+}
+
+// loadTUISnapshot reads every panel. A panel that fails to load is left empty and its error
+// shown, so one bad query does not blank the dashboard.
+func loadTUISnapshot(ctx context.Context, s *AppState, user *database.User) tuiSnapshot {
+	snap := tuiSnapshot{At: time.Now()}
+	fail := func(format string, args ...any) { snap.Errs = append(snap.Errs, fmt.Sprintf(format, args...)) }
+
+	// Tracked currencies, or every currency with a rate when none are tracked
+	currencies, err := trackedCurrencies(ctx, s)
+	if err != nil {
+		fail("%v", err)
+	} else if len(currencies) == 0 {
+		rows, err := s.db.ListLatestForeignExchangeRates(ctx, s.cfg.FXSession)
+		if err != nil {
+			fail("failed to load FX rates: %v", err)
+		}
+		for _, row := range rows {
+			currencies = append(currencies, row.CurrencyCode)
+		}
+	}
+	for _, code := range currencies {
+		if q, err := loadTUIQuote(ctx, s, seriesKey{Kind: watchlistFx, Code: code}); err != nil {
+			fail("%v", err)
+		} else if q != nil {
+			snap.FX = append(snap.FX, *q)
+		}
+	}
+
+	var keys []seriesKey
+	if user != nil {
+		snap.WatchlistTitle = "Watchlist of " + user.Username
+		items, err := s.db.ListWatchlistItemsByUser(ctx, user.ID)
+		if err != nil {
+			fail("failed to load watchlist: %v", err)
+		}
+		for _, item := range items {
+			keys = append(keys, seriesKey{Kind: item.ItemType, Code: item.Code})
+		}
+	} else {
+		snap.WatchlistTitle = "Tracked stocks (log in for your watchlist)"
+		for _, code := range trackedStocks(ctx, s, "") {
+			keys = append(keys, seriesKey{Kind: watchlistStock, Code: code})
+		}
+	}
+	for _, key := range keys {
+		if q, err := loadTUIQuote(ctx, s, key); err != nil {
+			fail("%v", err)
+		} else if q != nil {
+			snap.Watchlist = append(snap.Watchlist, *q)
+		}
+	}
+
+	snap.Runs, err = s.db.ListFetchRuns(ctx, database.ListFetchRunsParams{MaxResults: tuiRunRows})
+	if err != nil {
+		fail("failed to load fetch runs: %v", err)
+	}
+	return snap
+}
+
+// loadTUIQuote returns the latest value of key and its change from the observation before, or
+// nil when nothing is stored.
+func loadTUIQuote(ctx context.Context, s *AppState, key seriesKey) (*tuiQuote, error) {
+	latest, err := seriesValueOnOrBefore(ctx, s, key, markettime.Today())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", key, err)
+	}
+	q := &tuiQuote{Code: key.Code, Value: latest.Value, Date: latest.Date}
+	prev, err := seriesValueOnOrBefore(ctx, s, key, latest.Date.AddDate(0, 0, -1))
+	if err == nil && prev.Value != 0 {
+		q.Change = (latest.Value/prev.Value - 1) * 100
+		q.HasChange = true
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to load %s: %w", key, err)
+	}
+	return q, nil
+}
+
+// tuiLogBuffer holds the log output written while the dashboard runs.
+type tuiLogBuffer struct {
+	mu      sync.Mutex
+	lines   []string
+	dropped int
+}
+
+func (b *tuiLogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = append(b.lines, strings.TrimRight(string(p), "\n"))
+	if len(b.lines) > tuiLogLines {
+		b.dropped += len(b.lines) - tuiLogLines
+		b.lines = b.lines[len(b.lines)-tuiLogLines:]
+	}
+	return len(p), nil
+}
+
+// last returns the newest n lines.
+func (b *tuiLogBuffer) last(n int) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.lines[max(len(b.lines)-n, 0):]...)
+}
+
+// replay writes the held lines to w once the dashboard has closed.
+func (b *tuiLogBuffer) replay(w io.Writer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.dropped > 0 {
+		fmt.Fprintf(w, "(%d earlier log lines written while the dashboard was open were dropped)\n", b.dropped)
+	}
+	for _, line := range b.lines {
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/auth"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// --- User Command Handlers ---
+
+// handlerRegister creates a new user with a bcrypt-hashed password and logs them in.
+// Usage: register <username> <email> <password>
+func handlerRegister(s *AppState, cmd command) error {
+	if len(cmd.Args) != 3 {
+		return fmt.Errorf("usage: %s <username> <email> <password>", cmd.Name)
+	}
+	username := strings.TrimSpace(cmd.Args[0])
+	email := strings.TrimSpace(cmd.Args[1])
+	password := cmd.Args[2]
+
+	if !strings.Contains(email, "@") {
+		return fmt.Errorf("invalid email address: %s", email)
+	}
+
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	// The first user to register becomes the admin; everyone else starts read-only. The users
+	// table is locked until the insert commits, so two first registrations cannot both see it
+	// empty.
+	tx, err := s.dbConn.BeginTx(cmd.Context(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+	if err := qtx.LockUsers(cmd.Context()); err != nil {
+		return fmt.Errorf("failed to lock users: %w", err)
+	}
+	role := auth.RoleViewer
+	count, err := qtx.CountUsers(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to count users: %w", err)
+	}
+	if count == 0 {
+		role = auth.RoleAdmin
+	}
+
+	user, err := qtx.CreateUser(cmd.Context(), database.CreateUserParams{
+		ID:             uuid.New(),
+		Username:       username,
+		Email:          email,
+		HashedPassword: hashedPassword,
+		CreatedAt:      time.Now().UTC(),
+		Role:           role,
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return fmt.Errorf("username or email already registered")
+		}
+		return fmt.Errorf("failed to create user %s: %w", username, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit user %s: %w", username, err)
+	}
+
+	log.Printf("Registered user %s (%s) with role %s.", user.Username, user.ID, user.Role)
+	recordAudit(cmd.Context(), s, user, auditSourceCLI, "register", "role "+user.Role, "")
+	fmt.Printf("User %s registered as %s.\n", user.Username, user.Role)
+	return startSession(cmd.Context(), s, user)
+}
+
+// handlerLogin verifies a user's password and starts a session for them.
+// Usage: login <username> <password>
+func handlerLogin(s *AppState, cmd command) error {
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("usage: %s <username> <password>", cmd.Name)
+	}
+	username := strings.TrimSpace(cmd.Args[0])
+	password := cmd.Args[1]
+
+	user, err := s.db.GetUserByUsername(cmd.Context(), username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			auth.CheckPasswordHash(password, auth.DummyPasswordHash) // Same bcrypt work as a wrong password
+			recordAudit(cmd.Context(), s, database.User{Username: username}, auditSourceCLI, "login:failed", "unknown user", "")
+			return fmt.Errorf("invalid username or password")
+		}
+		return fmt.Errorf("failed to look up user %s: %w", username, err)
+	}
+	if err := auth.CheckPasswordHash(password, user.HashedPassword); err != nil {
+		recordAudit(cmd.Context(), s, user, auditSourceCLI, "login:failed", "wrong password", "")
+		return fmt.Errorf("invalid username or password")
+	}
+
+	if s.currentUser != nil {
+		endSession(cmd.Context(), s) // Replace any existing session
+	}
+	recordAudit(cmd.Context(), s, user, auditSourceCLI, "login", "", "")
+	return startSession(cmd.Context(), s, user)
+}
+
+// handlerLogout ends the current session.
+// Usage: logout
+func handlerLogout(s *AppState, cmd command) error {
+	user := cmd.user
+	endSession(cmd.Context(), s)
+	recordAudit(cmd.Context(), s, user, auditSourceCLI, "logout", "", "")
+	fmt.Printf("User %s logged out.\n", user.Username)
+	return nil
+}
+
+// handlerWhoami prints the logged in user.
+// Usage: whoami
+func handlerWhoami(s *AppState, cmd command) error {
+	user := cmd.user
+	fmt.Printf("Logged in as %s <%s> (%s)\n", user.Username, user.Email, user.Role)
+	return nil
+}
+
+// handlerGetUsers lists registered users and their email addresses, marking the current one
+// (admin only).
+// Usage: users
+func handlerGetUsers(s *AppState, cmd command) error {
+	users, err := s.db.ListUsers(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to list users: %w", err)
+	}
+	if len(users) == 0 {
+		fmt.Println("No users registered.")
+		return nil
+	}
+	for _, u := range users {
+		marker := " "
+		if s.currentUser != nil && s.currentUser.ID == u.ID {
+			marker = "*"
+		}
+		fmt.Printf("%s %s <%s> [%s]\n", marker, u.Username, u.Email, u.Role)
+	}
+	return nil
+}
+
+// startSession records a new session for user and makes them the current user.
+func startSession(ctx context.Context, s *AppState, user database.User) error {
+	token, err := auth.MakeSessionToken()
+	if err != nil {
+		return err
+	}
+	_, err = s.db.CreateUserSession(ctx, database.CreateUserSessionParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().UTC().Add(s.cfg.SessionTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create session for %s: %w", user.Username, err)
+	}
+
+	s.currentUser = &user
+	s.sessionToken = token
+	log.Printf("User %s logged in; session expires in %s.", user.Username, s.cfg.SessionTTL)
+	fmt.Printf("Logged in as %s.\n", user.Username)
+	return nil
+}
+
+// endSession deletes the current session (if any) and clears the current user.
+func endSession(ctx context.Context, s *AppState) {
+	if s.sessionToken != "" {
+		if err := s.db.DeleteUserSession(ctx, auth.HashToken(s.sessionToken)); err != nil {
+			log.Printf("Error deleting session: %v", err)
+		}
+	}
+	s.currentUser = nil
+	s.sessionToken = ""
+}
+
+// handlerUserRole changes a user's role (admin only).
+// Usage: users:role <username> <admin|editor|viewer>
+func handlerUserRole(s *AppState, cmd command) error {
+	admin := cmd.user
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("usage: %s <username> <%s>", cmd.Name, strings.Join(auth.Roles, "|"))
+	}
+	username := cmd.Args[0]
+	role := strings.ToLower(cmd.Args[1])
+	if !auth.ValidRole(role) {
+		return fmt.Errorf("invalid role %q (use %s)", role, strings.Join(auth.Roles, ", "))
+	}
+	if username == admin.Username && role != auth.RoleAdmin {
+		return fmt.Errorf("refusing to remove your own admin role")
+	}
+
+	user, err := s.db.UpdateUserRole(cmd.Context(), database.UpdateUserRoleParams{
+		Role:     role,
+		Username: username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("user %s not found", username)
+		}
+		return fmt.Errorf("failed to update role for %s: %w", username, err)
+	}
+	log.Printf("User %s changed role of %s to %s.", admin.Username, user.Username, user.Role)
+	fmt.Printf("User %s is now %s.\n", user.Username, user.Role)
+	return nil
+}
+
+// handlerUserDelete removes a user along with their sessions and API keys (admin only).
+// Usage: users:delete <username>
+func handlerUserDelete(s *AppState, cmd command) error {
+	admin := cmd.user
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <username>", cmd.Name)
+	}
+	username := cmd.Args[0]
+	if username == admin.Username {
+		return fmt.Errorf("refusing to delete the logged in user")
+	}
+	n, err := s.db.DeleteUserByUsername(cmd.Context(), username)
+	if err != nil {
+		return fmt.Errorf("failed to delete user %s: %w", username, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("user %s not found", username)
+	}
+	log.Printf("User %s deleted user %s.", admin.Username, username)
+	fmt.Printf("User %s deleted.\n", username)
+	return nil
+}
+
+// handlerAPIKeyCreate issues an API key owned by the current user (admin only).
+// The key is printed once; only its hash is stored.
+// Usage: apikey:create <name> <admin|editor|viewer>
+func handlerAPIKeyCreate(s *AppState, cmd command) error {
+	admin := cmd.user
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("usage: %s <name> <%s>", cmd.Name, strings.Join(auth.Roles, "|"))
+	}
+	name := cmd.Args[0]
+	role := strings.ToLower(cmd.Args[1])
+	if !auth.ValidRole(role) {
+		return fmt.Errorf("invalid role %q (use %s)", role, strings.Join(auth.Roles, ", "))
+	}
+
+	key, err := auth.MakeSessionToken()
+	if err != nil {
+		return err
+	}
+	apiKey, err := s.db.CreateAPIKey(cmd.Context(), database.CreateAPIKeyParams{
+		ID:      uuid.New(),
+		UserID:  admin.ID,
+		Name:    name,
+		KeyHash: auth.HashToken(key),
+		Role:    role,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create API key %s: %w", name, err)
+	}
+	log.Printf("User %s created API key %s (%s) with role %s.", admin.Username, apiKey.Name, apiKey.ID, apiKey.Role)
+	fmt.Printf("API key %s (%s, role %s):\n  %s\nStore it now; it cannot be shown again.\n", apiKey.Name, apiKey.ID, apiKey.Role, key)
+	return nil
+}
+
+// handlerAPIKeyList lists API keys without revealing them (admin only).
+// Usage: apikey:list
+func handlerAPIKeyList(s *AppState, cmd command) error {
+	keys, err := s.db.ListAPIKeys(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to list API keys: %w", err)
+	}
+	if len(keys) == 0 {
+		fmt.Println("No API keys.")
+		return nil
+	}
+	for _, k := range keys {
+		lastUsed := "never"
+		if k.LastUsedAt.Valid {
+			lastUsed = k.LastUsedAt.Time.Format(time.RFC3339)
+		}
+		fmt.Printf("%s  %-20s %-7s last used: %s\n", k.ID, k.Name, k.Role, lastUsed)
+	}
+	return nil
+}
+
+// handlerAPIKeyRevoke deletes an API key by ID (admin only).
+// Usage: apikey:revoke <id>
+func handlerAPIKeyRevoke(s *AppState, cmd command) error {
+	admin := cmd.user
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <id>", cmd.Name)
+	}
+	id, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid API key ID %q: %w", cmd.Args[0], err)
+	}
+	n, err := s.db.DeleteAPIKey(cmd.Context(), id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key %s: %w", id, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("API key %s not found", id)
+	}
+	log.Printf("User %s revoked API key %s.", admin.Username, id)
+	fmt.Printf("API key %s revoked.\n", id)
+	return nil
+}
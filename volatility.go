@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/analytics"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/markettime"
+)
+
+// volatilityWindows are the trailing windows, in trading days, stored in rolling_volatility.
+var volatilityWindows = []int32{20, 60, 250}
+
+// computeRollingVolatility brings rolling_volatility up to date from daily_returns for every
+// series and window. Normally only dates after the last stored value, or from the earliest
+// return recomputed since, are written; with full set everything is recomputed. It returns the number of values stored.
+func computeRollingVolatility(ctx context.Context, s *AppState, full bool) (int, error) {
+	seriesList, err := s.db.ListDailyReturnSeries(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list series with returns: %w", err)
+	}
+
+	stored := 0
+	var failed []string
+	for _, series := range seriesList {
+		n, err := updateRollingVolatility(ctx, s, series, full)
+		if err != nil {
+			log.Printf("Error updating rolling volatility for %s: %v", series, err)
+			failed = append(failed, series)
+		}
+		stored += n
+	}
+	if len(failed) > 0 {
+		return stored, fmt.Errorf("failed to update rolling volatility for %s", strings.Join(failed, ", "))
+	}
+	return stored, nil
+}
+
+// loadDailyReturns returns the stored daily returns of a series in [start, end], oldest first.
+func loadDailyReturns(ctx context.Context, s *AppState, series string, start, end time.Time) ([]analytics.Point, error) {
+	rows, err := s.db.GetDailyReturnsBySeriesAndDateRange(ctx, database.GetDailyReturnsBySeriesAndDateRangeParams{
+		Series:    series,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load daily returns: %w", err)
+	}
+	points := make([]analytics.Point, 0, len(rows))
+	for _, row := range rows {
+		value, err := strconv.ParseFloat(row.PctReturn, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stored return on %s: %w", row.Date.Format("2006-01-02"), err)
+		}
+		points = append(points, analytics.Point{Date: row.Date, Value: value})
+	}
+	return points, nil
+}
+
+// updateRollingVolatility stores the volatility windows of one series in a single transaction.
+func updateRollingVolatility(ctx context.Context, s *AppState, series string, full bool) (int, error) {
+	returns, err := loadDailyReturns(ctx, s, series, returnsEpoch, markettime.Today())
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	stored := 0
+	for _, window := range volatilityWindows {
+		var after time.Time // Zero: store every value
+		if !full {
+			latest, err := qtx.GetLatestRollingVolatilityDate(ctx, database.GetLatestRollingVolatilityDateParams{
+				Series:     series,
+				WindowDays: window,
+			})
+			if err != nil && err != sql.ErrNoRows {
+				return 0, fmt.Errorf("failed to load last stored %d-day volatility: %w", window, err)
+			}
+			after = latest
+			// Returns recomputed behind the last value (backfilled rates) change every window
+			// that covers them, so those are rewritten too
+			recomputed, err := qtx.GetEarliestReturnRecomputedAfterVolatility(ctx, database.GetEarliestReturnRecomputedAfterVolatilityParams{
+				Series:     series,
+				WindowDays: window,
+			})
+			if err != nil && err != sql.ErrNoRows {
+				return 0, fmt.Errorf("failed to load returns recomputed since the %d-day volatility: %w", window, err)
+			}
+			if err == nil && !recomputed.After(after) {
+				after = recomputed.AddDate(0, 0, -1)
+			}
+		}
+
+		for _, v := range analytics.RollingVolatility(returns, int(window)) {
+			if !v.Date.After(after) {
+				continue
+			}
+			err := qtx.UpsertRollingVolatility(ctx, database.UpsertRollingVolatilityParams{
+				Series:     series,
+				WindowDays: window,
+				Date:       v.Date,
+				Volatility: fmt.Sprintf("%.8f", v.Value),
+			})
+			if err != nil {
+				return 0, fmt.Errorf("failed to store %d-day volatility for %s: %w", window, v.Date.Format("2006-01-02"), err)
+			}
+			stored++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit volatility: %w", err)
+	}
+	invalidateResponseCache(s)
+	return stored, nil
+}
+
+// handlerVolatilityCompute updates the derived rolling volatility on demand.
+// Usage: volatility:compute [--full]
+func handlerVolatilityCompute(s *AppState, cmd command) error {
+	full := false
+	switch {
+	case len(cmd.Args) == 0:
+	case len(cmd.Args) == 1 && cmd.Args[0] == "--full":
+		full = true
+	default:
+		return fmt.Errorf("usage: %s [--full]", cmd.Name)
+	}
+	n, err := computeRollingVolatility(cmd.Context(), s, full)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Stored %d rolling volatility values.\n", n)
+	return nil
+}
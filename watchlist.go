@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/google/uuid"
+)
+
+// Watchlist item types
+const (
+	watchlistStock = "stock"
+	watchlistFx    = "fx"
+)
+
+var (
+	stockCodePattern    = regexp.MustCompile(`^[0-9A-Z]{1,20}$`)
+	currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	countryCodePattern  = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// normalizeWatchlistItem validates a watchlist item type and code, returning them in canonical form.
+func normalizeWatchlistItem(itemType, code string) (string, string, error) {
+	itemType = strings.ToLower(strings.TrimSpace(itemType))
+	code = strings.ToUpper(strings.TrimSpace(code))
+	switch itemType {
+	case watchlistStock:
+		code = normalizeStockCode(code)
+		if !stockCodePattern.MatchString(code) {
+			return "", "", fmt.Errorf("invalid stock code %q", code)
+		}
+	case watchlistFx:
+		if !currencyCodePattern.MatchString(code) {
+			return "", "", fmt.Errorf("invalid currency code %q (use a 3-letter ISO code)", code)
+		}
+	default:
+		return "", "", fmt.Errorf("invalid watchlist type %q (use %s or %s)", itemType, watchlistStock, watchlistFx)
+	}
+	return itemType, code, nil
+}
+
+// newWatchlistItem validates a watchlist item being added: as normalizeWatchlistItem, and a
+// stock must also be a stored company.
+func newWatchlistItem(ctx context.Context, s *AppState, itemType, code string) (string, string, error) {
+	itemType, code, err := normalizeWatchlistItem(itemType, code)
+	if err != nil || itemType != watchlistStock {
+		return itemType, code, err
+	}
+	code, err = lookupStockCode(ctx, s, code)
+	return itemType, code, err
+}
+
+// --- Watchlist Command Handlers ---
+
+// handlerWatchlist prints the current user's watchlist.
+// Usage: watchlist
+func handlerWatchlist(s *AppState, cmd command) error {
+	user := cmd.user
+	items, err := s.db.ListWatchlistItemsByUser(cmd.Context(), user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load watchlist: %w", err)
+	}
+	if len(items) == 0 {
+		fmt.Println("Watchlist is empty. Add items with watchlist:add <stock|fx> <code>.")
+		return nil
+	}
+	for _, item := range items {
+		fmt.Printf("  %-5s %s\n", item.ItemType, item.Code)
+	}
+	return nil
+}
+
+// handlerWatchlistAdd adds a stock code or currency to the current user's watchlist.
+// Usage: watchlist:add <stock|fx> <code>
+func handlerWatchlistAdd(s *AppState, cmd command) error {
+	user := cmd.user
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("usage: %s <stock|fx> <code>", cmd.Name)
+	}
+	itemType, code, err := newWatchlistItem(cmd.Context(), s, cmd.Args[0], cmd.Args[1])
+	if err != nil {
+		return err
+	}
+	_, err = s.db.AddWatchlistItem(cmd.Context(), database.AddWatchlistItemParams{
+		ID:       uuid.New(),
+		UserID:   user.ID,
+		ItemType: itemType,
+		Code:     code,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %s %s to watchlist: %w", itemType, code, err)
+	}
+	log.Printf("User %s added %s %s to their watchlist.", user.Username, itemType, code)
+	fmt.Printf("Added %s %s to watchlist.\n", itemType, code)
+	return nil
+}
+
+// handlerWatchlistRemove removes a stock code or currency from the current user's watchlist.
+// Usage: watchlist:remove <stock|fx> <code>
+func handlerWatchlistRemove(s *AppState, cmd command) error {
+	user := cmd.user
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("usage: %s <stock|fx> <code>", cmd.Name)
+	}
+	itemType, code, err := normalizeWatchlistItem(cmd.Args[0], cmd.Args[1])
+	if err != nil {
+		return err
+	}
+	n, err := s.db.DeleteWatchlistItem(cmd.Context(), database.DeleteWatchlistItemParams{
+		UserID:   user.ID,
+		ItemType: itemType,
+		Code:     code,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove %s %s from watchlist: %w", itemType, code, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s %s is not on your watchlist", itemType, code)
+	}
+	log.Printf("User %s removed %s %s from their watchlist.", user.Username, itemType, code)
+	fmt.Printf("Removed %s %s from watchlist.\n", itemType, code)
+	return nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/auth"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/database"
+	"github.com/Ernestlph/Malaysia-Econ-DB/internal/notify"
+	"github.com/google/uuid"
+)
+
+// Webhook event types
+const (
+	webhookDataStored     = "data.stored"
+	webhookAlertTriggered = "alert.triggered"
+	webhookFetchFailed    = "fetch.failed"
+	// Fired for each announcement alert event
+	webhookAnnouncementTriggered = "announcement.triggered"
+)
+
+var webhookEvents = []string{webhookDataStored, webhookAlertTriggered, webhookFetchFailed, webhookAnnouncementTriggered}
+
+// webhookDeliveryTimeout bounds all attempts of a single delivery, including retries.
+const webhookDeliveryTimeout = 5 * time.Minute
+
+// Payload data for data.stored events
+type dataStoredEvent struct {
+	Job     string `json:"job"`
+	Records int    `json:"records"`
+}
+
+// Payload data for fetch.failed events
+type fetchFailedEvent struct {
+	Job      string   `json:"job"`
+	Failures []string `json:"failures"`
+}
+
+// fireWebhookEvent delivers an event to every active webhook subscribed to it. Deliveries run
+// in the background so slow or failing endpoints never hold up the caller; shutdown waits for
+// them through the grace period.
+func fireWebhookEvent(s *AppState, event string, data interface{}) {
+	hooks, err := s.db.ListActiveWebhooksForEvent(s.workCtx, event)
+	if err != nil {
+		log.Printf("Error loading webhooks for %s: %v", event, err)
+		return
+	}
+	for _, hook := range hooks {
+		payload := notify.WebhookPayload{
+			Event:      event,
+			DeliveryID: uuid.NewString(),
+			OccurredAt: time.Now().UTC(),
+			Data:       data,
+		}
+		runBackground(s, func() {
+			ctx, cancel := context.WithTimeout(s.workCtx, webhookDeliveryTimeout)
+			defer cancel()
+			err := runRecovered("webhook delivery", func() error {
+				return s.webhooks.Deliver(ctx, hook.Url, hook.Secret, payload)
+			})
+			if err != nil {
+				log.Printf("Webhook %s: %v", hook.ID, err)
+			}
+		})
+	}
+}
+
+// --- Webhook Command Handlers ---
+
+// handlerWebhookAdd subscribes a URL to one or more events (admin only). A signing secret is
+// generated unless one is given, and printed once.
+// Usage: webhook:add <url> <event[,event...]> [secret]
+func handlerWebhookAdd(s *AppState, cmd command) error {
+	if len(cmd.Args) < 2 || len(cmd.Args) > 3 {
+		return fmt.Errorf("usage: %s <url> <%s>[,...] [secret]", cmd.Name, strings.Join(webhookEvents, "|"))
+	}
+	target, err := url.Parse(cmd.Args[0])
+	if err != nil || (target.Scheme != "https" && target.Scheme != "http") || target.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q (must be http or https)", cmd.Args[0])
+	}
+
+	var events []string
+	for _, e := range strings.Split(cmd.Args[1], ",") {
+		e = strings.TrimSpace(e)
+		valid := false
+		for _, known := range webhookEvents {
+			valid = valid || e == known
+		}
+		if !valid {
+			return fmt.Errorf("unknown event %q (use %s)", e, strings.Join(webhookEvents, ", "))
+		}
+		events = append(events, e)
+	}
+
+	secret := ""
+	if len(cmd.Args) == 3 {
+		secret = cmd.Args[2]
+	} else if secret, err = auth.MakeSessionToken(); err != nil {
+		return err
+	}
+
+	hook, err := s.db.CreateWebhook(cmd.Context(), database.CreateWebhookParams{
+		ID:         uuid.New(),
+		Url:        target.String(),
+		Secret:     secret,
+		EventTypes: events,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	log.Printf("Created webhook %s for %s (%s).", hook.ID, hook.Url, strings.Join(hook.EventTypes, ", "))
+	fmt.Printf("Webhook %s created.\n", hook.ID)
+	if len(cmd.Args) == 2 {
+		fmt.Printf("Signing secret (store it now; it is not shown again):\n  %s\n", secret)
+	}
+	return nil
+}
+
+// handlerWebhookList lists webhook subscriptions without their secrets (admin only).
+// Usage: webhook:list
+func handlerWebhookList(s *AppState, cmd command) error {
+	hooks, err := s.db.ListWebhooks(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	if len(hooks) == 0 {
+		fmt.Println("No webhooks.")
+		return nil
+	}
+	for _, h := range hooks {
+		state := "active"
+		if !h.Active {
+			state = "inactive"
+		}
+		fmt.Printf("%s  %s  [%s] %s\n", h.ID, h.Url, strings.Join(h.EventTypes, ","), state)
+	}
+	return nil
+}
+
+// handlerWebhookRemove deletes a webhook subscription (admin only).
+// Usage: webhook:remove <id>
+func handlerWebhookRemove(s *AppState, cmd command) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <id>", cmd.Name)
+	}
+	id, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid webhook ID %q: %w", cmd.Args[0], err)
+	}
+	n, err := s.db.DeleteWebhook(cmd.Context(), id)
+	if err != nil {
+		return fmt.Errorf("failed to remove webhook %s: %w", id, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("webhook %s not found", id)
+	}
+	fmt.Printf("Webhook %s removed.\n", id)
+	return nil
+}